@@ -119,6 +119,48 @@ type AgendaAPIResponse struct {
 	ByTime   *dbtypes.AgendaVoteChoices `json:"by_time"`
 }
 
+// AgendaVoteHistory is the history of the votes on an agenda and of the stake
+// and block version upgrades by rule change interval (RCI). LockedIn is the
+// height at which the agenda locked in, if it has.
+type AgendaVoteHistory struct {
+	ID            string             `json:"id"`
+	VoteVersion   uint32             `json:"voteversion"`
+	MileStone     *dbtypes.MileStone `json:"milestone"`
+	LockedIn      int64              `json:"lockedin,omitempty"`
+	Quorum        uint32             `json:"quorum"`
+	PassThreshold float64            `json:"pass_threshold"`
+	BlockVersion  uint32             `json:"block_version"`
+	Intervals     []*AgendaRCI       `json:"intervals"`
+}
+
+// AgendaRCI is the vote choices tally for an agenda and the stake and block
+// versions in a rule change interval. Approval is the fraction of the yes and
+// no votes that are yes. StakeUpgrade is the fraction of the votes of at least
+// the agenda's vote version, and BlockUpgrade is the fraction of the blocks of
+// at least the history's block version.
+type AgendaRCI struct {
+	RCI           int64           `json:"rci"`
+	StartHeight   int64           `json:"start_height"`
+	EndHeight     int64           `json:"end_height"`
+	Voting        bool            `json:"voting"`
+	Yes           uint32          `json:"yes"`
+	Abstain       uint32          `json:"abstain"`
+	No            uint32          `json:"no"`
+	Total         uint32          `json:"total"`
+	Approval      float64         `json:"approval"`
+	QuorumMet     bool            `json:"quorum_met"`
+	StakeVersions []*VersionCount `json:"stake_versions"`
+	StakeUpgrade  float64         `json:"stake_upgrade"`
+	BlockVersions []*VersionCount `json:"block_versions"`
+	BlockUpgrade  float64         `json:"block_upgrade"`
+}
+
+// VersionCount is the number of votes or blocks of a version.
+type VersionCount struct {
+	Version uint32 `json:"version"`
+	Count   uint32 `json:"count"`
+}
+
 // TrimmedTx models data to resemble to result of the decoderawtransaction RPC.
 type TrimmedTx struct {
 	TxID     string `json:"txid"`
@@ -706,6 +748,135 @@ type TicketPoolValsAndSizes struct {
 	Size        []uint32  `json:"size"`
 }
 
+// TicketPoolMembership models whether a ticket was in the live ticket pool at
+// a height, and when it entered the pool if it was.
+type TicketPoolMembership struct {
+	Ticket     string `json:"ticket"`
+	Height     int64  `json:"height"`
+	Live       bool   `json:"live"`
+	LiveHeight int64  `json:"live_height,omitempty"`
+}
+
+// TicketPoolChanges models the net change in the live ticket pool from
+// StartHeight to EndHeight. Tickets that both entered and left the pool in the
+// range are in neither list.
+type TicketPoolChanges struct {
+	StartHeight int64    `json:"start_height"`
+	EndHeight   int64    `json:"end_height"`
+	In          []string `json:"in"`
+	Out         []string `json:"out"`
+}
+
+// TicketPoolAges models the age distribution of the live ticket pool at a
+// height. Counts[i] is the number of tickets that had been live for
+// [i*BinSize, (i+1)*BinSize) blocks.
+type TicketPoolAges struct {
+	Height  int64   `json:"height"`
+	BinSize int64   `json:"bin_size"`
+	Size    int64   `json:"size"`
+	Counts  []int64 `json:"counts"`
+}
+
+// VSPStats models the tickets of a voting service provider. LiveTickets are
+// the live tickets committing to one of the FeeAddresses, and the other counts
+// are of the spent tickets that paid one of them. AvgVoteLatency is the mean
+// number of blocks the voted tickets were live before voting. Attribution
+// notes the limits of attributing the tickets by their commitments.
+type VSPStats struct {
+	Name           string   `json:"name"`
+	URL            string   `json:"url,omitempty"`
+	FeeAddresses   []string `json:"fee_addresses"`
+	LiveTickets    int64    `json:"live_tickets"`
+	Votes          int64    `json:"votes"`
+	Misses         int64    `json:"misses"`
+	Expirations    int64    `json:"expirations"`
+	Revocations    int64    `json:"revocations"`
+	MissRate       float64  `json:"miss_rate"`
+	AvgVoteLatency float64  `json:"avg_vote_latency"`
+	Attribution    string   `json:"attribution"`
+}
+
+// StakeRewardSimulation models a backtest of staking a balance of DCR from
+// StartHeight to EndHeight, buying tickets at the historical ticket prices and
+// voting them at the observed mean vote times for the actual vote rewards.
+// Projected is true when the simulation runs beyond the best block, where the
+// ticket price is the expected next stake difficulty. Balances are in DCR, and
+// ReturnPercent is annualized as ASR.
+type StakeRewardSimulation struct {
+	StartingBalance float64               `json:"starting_balance"`
+	Reinvest        string                `json:"reinvest"`
+	StartHeight     int64                 `json:"start_height"`
+	StartTime       int64                 `json:"start_time"`
+	EndHeight       int64                 `json:"end_height"`
+	EndTime         int64                 `json:"end_time"`
+	Projected       bool                  `json:"projected"`
+	FinalBalance    float64               `json:"final_balance"`
+	LiveTickets     float64               `json:"live_tickets"`
+	Rewards         float64               `json:"rewards"`
+	ReturnPercent   float64               `json:"return_percent"`
+	ASR             float64               `json:"asr"`
+	Steps           []*StakeRewardSimStep `json:"steps"`
+}
+
+// StakeRewardSimStep is a ticket purchase ("buy") or vote ("vote") of a stake
+// reward simulation. Balance is the liquid balance plus the price of the live
+// tickets after the step.
+type StakeRewardSimStep struct {
+	Height      int64   `json:"height"`
+	Time        int64   `json:"time"`
+	Action      string  `json:"action"`
+	Tickets     float64 `json:"tickets"`
+	TicketPrice float64 `json:"ticket_price"`
+	VoteReward  float64 `json:"vote_reward,omitempty"`
+	Balance     float64 `json:"balance"`
+	Projected   bool    `json:"projected"`
+}
+
+// TicketSelectionStats models the ticket selection statistics of consecutive
+// windows of WindowSize blocks, oldest first.
+type TicketSelectionStats struct {
+	WindowSize int64                    `json:"window_size"`
+	Windows    []*TicketSelectionWindow `json:"windows"`
+}
+
+// TicketSelectionWindow models how the tickets that left the live pool in the
+// blocks [StartHeight, EndHeight] compare with random selection. Waits are the
+// number of lotteries a ticket was in before it was selected, and the expected
+// values are for a geometric distribution with the window's mean per-ticket
+// SelectionProbability, truncated at the ticket expiry. ChiSquare and PValue
+// are the goodness of fit of the observed wait bins and expiries to those
+// expected.
+type TicketSelectionWindow struct {
+	StartHeight          int64           `json:"start_height"`
+	EndHeight            int64           `json:"end_height"`
+	StartTime            int64           `json:"start_time"`
+	EndTime              int64           `json:"end_time"`
+	MeanPoolSize         float64         `json:"mean_pool_size"`
+	SelectionProbability float64         `json:"selection_probability"`
+	Selected             int64           `json:"selected"`
+	Expired              int64           `json:"expired"`
+	Missed               int64           `json:"missed"`
+	MeanWait             float64         `json:"mean_wait"`
+	ExpectedMeanWait     float64         `json:"expected_mean_wait"`
+	WaitBins             []TicketWaitBin `json:"wait_bins"`
+	ExpectedExpired      float64         `json:"expected_expired"`
+	ChiSquare            float64         `json:"chi_square"`
+	DegreesOfFreedom     int             `json:"degrees_of_freedom"`
+	PValue               float64         `json:"p_value"`
+	ExpiryRate           float64         `json:"expiry_rate"`
+	ExpectedExpiryRate   float64         `json:"expected_expiry_rate"`
+	MissRate             float64         `json:"miss_rate"`
+}
+
+// TicketWaitBin is the observed and expected number of tickets selected after
+// waiting [MinWait, MaxWait] blocks.
+type TicketWaitBin struct {
+	MinWait  int64   `json:"min_wait"`
+	MaxWait  int64   `json:"max_wait"`
+	Observed int64   `json:"observed"`
+	Expected float64 `json:"expected"`
+}
+
 // BlockDataBasic models primary information about a block.
 type BlockDataBasic struct {
 	Height     uint32  `json:"height"`
@@ -778,6 +949,33 @@ type StakeDiff struct {
 	PriceWindowNum   int                               `json:"window_number"`
 }
 
+// StakeDiffForecast models the forecast ticket prices of the stake difficulty
+// windows after the current window of the block at Height. WindowPurchases is
+// the number of tickets purchased so far in the current window. The prices are
+// in DCR.
+type StakeDiffForecast struct {
+	Height          int64                      `json:"height"`
+	CurrentPrice    float64                    `json:"current_price"`
+	WindowPurchases int64                      `json:"window_purchases"`
+	MempoolTickets  int64                      `json:"mempool_tickets"`
+	Windows         []*StakeDiffForecastWindow `json:"windows"`
+}
+
+// StakeDiffForecastWindow models the forecast ticket price of a stake
+// difficulty window as the mean and percentiles of the simulated prices.
+// StartTime is the expected time of the first block of the window.
+type StakeDiffForecastWindow struct {
+	Window      int64   `json:"window"`
+	StartHeight int64   `json:"start_height"`
+	StartTime   int64   `json:"start_time"`
+	Mean        float64 `json:"mean"`
+	P5          float64 `json:"p5"`
+	P25         float64 `json:"p25"`
+	P50         float64 `json:"p50"`
+	P75         float64 `json:"p75"`
+	P95         float64 `json:"p95"`
+}
+
 // StakeInfoExtended models data about the fee, pool and stake difficulty
 type StakeInfoExtended struct {
 	Hash             string                 `json:"hash"`
@@ -18,6 +18,7 @@ import (
 
 	apitypes "github.com/decred/dcrdata/v8/api/types"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/stakedb"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
@@ -113,6 +114,60 @@ type NodeClient interface {
 	GetStakeDifficulty(ctx context.Context) (*chainjson.GetStakeDifficultyResult, error)
 }
 
+// timedNodeClient is a NodeClient that records the latency of each RPC.
+type timedNodeClient struct {
+	NodeClient
+}
+
+func observeRPC(method string, start time.Time) {
+	metrics.ObserveSince(metrics.RPCDuration, method, start)
+}
+
+func (c timedNodeClient) GetBlockCount(ctx context.Context) (int64, error) {
+	defer observeRPC("getblockcount", time.Now())
+	return c.NodeClient.GetBlockCount(ctx)
+}
+
+func (c timedNodeClient) GetBlock(ctx context.Context, blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	defer observeRPC("getblock", time.Now())
+	return c.NodeClient.GetBlock(ctx, blockHash)
+}
+
+func (c timedNodeClient) GetBlockHeaderVerbose(ctx context.Context, hash *chainhash.Hash) (*chainjson.GetBlockHeaderVerboseResult, error) {
+	defer observeRPC("getblockheader", time.Now())
+	return c.NodeClient.GetBlockHeaderVerbose(ctx, hash)
+}
+
+func (c timedNodeClient) GetCoinSupply(ctx context.Context) (dcrutil.Amount, error) {
+	defer observeRPC("getcoinsupply", time.Now())
+	return c.NodeClient.GetCoinSupply(ctx)
+}
+
+func (c timedNodeClient) GetBlockSubsidy(ctx context.Context, height int64, voters uint16) (*chainjson.GetBlockSubsidyResult, error) {
+	defer observeRPC("getblocksubsidy", time.Now())
+	return c.NodeClient.GetBlockSubsidy(ctx, height, voters)
+}
+
+func (c timedNodeClient) GetBlockChainInfo(ctx context.Context) (*chainjson.GetBlockChainInfoResult, error) {
+	defer observeRPC("getblockchaininfo", time.Now())
+	return c.NodeClient.GetBlockChainInfo(ctx)
+}
+
+func (c timedNodeClient) GetConnectionCount(ctx context.Context) (int64, error) {
+	defer observeRPC("getconnectioncount", time.Now())
+	return c.NodeClient.GetConnectionCount(ctx)
+}
+
+func (c timedNodeClient) EstimateStakeDiff(ctx context.Context, tickets *uint32) (*chainjson.EstimateStakeDiffResult, error) {
+	defer observeRPC("estimatestakediff", time.Now())
+	return c.NodeClient.EstimateStakeDiff(ctx, tickets)
+}
+
+func (c timedNodeClient) GetStakeDifficulty(ctx context.Context) (*chainjson.GetStakeDifficultyResult, error) {
+	defer observeRPC("getstakedifficulty", time.Now())
+	return c.NodeClient.GetStakeDifficulty(ctx)
+}
+
 // Collector models a structure for the source of the blockdata
 type Collector struct {
 	mtx          sync.Mutex
@@ -125,7 +180,7 @@ type Collector struct {
 func NewCollector(dcrdChainSvr NodeClient, params *chaincfg.Params,
 	stakeDB *stakedb.StakeDatabase) *Collector {
 	return &Collector{
-		dcrdChainSvr: dcrdChainSvr,
+		dcrdChainSvr: timedNodeClient{dcrdChainSvr},
 		netParams:    params,
 		stakeDB:      stakeDB,
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/wire"
 
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
 
@@ -88,10 +89,12 @@ func (p *chainMonitor) ConnectBlock(header *wire.BlockHeader) error {
 	defer p.reorgLock.Unlock()
 
 	// Collect block data.
+	tCollect := time.Now()
 	msgBlock, blockData, err := p.collect(&hash)
 	if err != nil {
 		return err
 	}
+	metrics.ObserveSince(metrics.BlockStageDuration, "collect", tCollect)
 
 	// Store block data with each saver.
 	for _, s := range p.dataSavers {
@@ -104,6 +107,8 @@ func (p *chainMonitor) ConnectBlock(header *wire.BlockHeader) error {
 			}
 			log.Tracef("(*chainMonitor).ConnectBlock: Completed %s.Store in %v.",
 				reflect.TypeOf(s), time.Since(tStart))
+			metrics.ObserveSince(metrics.BlockStageDuration,
+				"store:"+reflect.TypeOf(s).String(), tStart)
 		}
 	}
 	return err
@@ -69,11 +69,17 @@ var (
 	defaultPoliteiaURL       = "https://proposals.decred.org/"
 	defaultChartsCacheDump   = "chartscache.gob"
 
+	defaultDBBackend        = "postgres"
+	defaultSQLiteFileName   = "dcrdata.sqlite"
 	defaultPGHost           = "127.0.0.1:5432"
 	defaultPGUser           = "dcrdata"
 	defaultPGPass           = ""
 	defaultPGDBName         = "dcrdata"
 	defaultPGQueryTimeout   = 20 * time.Minute
+	defaultPGPartMonths     = 12
+	defaultPGPartVoutRows   = int64(100_000_000)
+	defaultPGSlowQueryTop   = 20
+	defaultPGSlowQueryAddr  = "127.0.0.1:7776"
 	defaultAddrCacheCap     = 1 << 29 // 512 MiB
 	defaultAddrCacheLimit   = 4096
 	defaultAddrCacheUXTOCap = 1 << 29
@@ -105,6 +111,7 @@ type config struct {
 	HTTPProfile  bool   `long:"httpprof" short:"p" description:"Start HTTP profiler." env:"DCRDATA_ENABLE_HTTP_PROFILER"`
 	HTTPProfPath string `long:"httpprofprefix" description:"URL path prefix for the HTTP profiler." env:"DCRDATA_HTTP_PROFILER_PREFIX"`
 	CPUProfile   string `long:"cpuprofile" description:"File for CPU profiling." env:"DCRDATA_CPU_PROFILER_FILE"`
+	Metrics      bool   `long:"metrics" description:"Serve Prometheus metrics on the /metrics path." env:"DCRDATA_ENABLE_METRICS"`
 	UseGops      bool   `short:"g" long:"gops" description:"Run with gops diagnostics agent listening. See github.com/google/gops for more information." env:"DCRDATA_USE_GOPS"`
 	ReloadHTML   bool   `long:"reload-html" description:"Reload HTML templates on every request" env:"DCRDATA_RELOAD_HTML"`
 
@@ -130,6 +137,10 @@ type config struct {
 	AgendasDBFileName string `long:"agendadbfile" description:"Agendas DB file name (default is agendas.db)." env:"DCRDATA_AGENDAS_DB_FILE_NAME"`
 	ProposalsFileName string `long:"proposalsdbfile" description:"Proposals DB file name (default is proposals.db)." env:"DCRDATA_PROPOSALS_DB_FILE_NAME"`
 	PoliteiaURL       string `long:"politeiaurl" description:"Defines the root API politeia URL (defaults to https://proposals.decred.org/)." env:"DCRDATA_POLITEIA_URL"`
+	PoliteiaArchive   string `long:"politeiaarchive" description:"Directory of archived politeia replies to import the proposals from instead of the politeia server at politeiaurl." env:"DCRDATA_POLITEIA_ARCHIVE"`
+
+	// Voting service providers
+	VSPRegistry string `long:"vspregistry" description:"JSON file with the VSPs and their fee addresses, for attributing tickets to VSPs. Disabled by default." env:"DCRDATA_VSP_REGISTRY"`
 
 	// Caching and optimization.
 	AddrCacheCap     int    `long:"addr-cache-cap" description:"Address cache capacity in bytes." env:"DCRDATA_ADDR_CACHE_CAP"`
@@ -139,14 +150,23 @@ type config struct {
 	ChartsCacheDump  string `long:"chartscache" description:"Defines the file name that holds the charts cache data on system exit." env:"DCRDATA_CHARTS_CACHE"`
 
 	// DB backend
+	DBBackend        string        `long:"dbbackend" description:"Main chain database backend: postgres or sqlite. The embedded SQLite backend is intended for testnet, simnet, and other small instances." env:"DCRDATA_DB_BACKEND"`
+	SQLitePath       string        `long:"sqlitepath" description:"Path to the SQLite database file when dbbackend=sqlite. Defaults to dcrdata.sqlite in the network's data directory." env:"DCRDATA_SQLITE_PATH"`
 	PGDBName         string        `long:"pgdbname" description:"PostgreSQL DB name." env:"DCRDATA_PG_DB_NAME"`
 	PGUser           string        `long:"pguser" description:"PostgreSQL DB user." env:"DCRDATA_POSTGRES_USER"`
 	PGPass           string        `long:"pgpass" description:"PostgreSQL DB password." env:"DCRDATA_POSTGRES_PASS"`
 	PGHost           string        `long:"pghost" description:"PostgreSQL server host:port or UNIX socket (e.g. /run/postgresql)." env:"DCRDATA_POSTGRES_HOST_URL"`
+	PGReplicas       []string      `long:"pgreplica" description:"PostgreSQL connection string (e.g. \"host=10.0.0.2 user=dcrdata dbname=dcrdata\" or postgres://dcrdata@10.0.0.2/dcrdata) of a read-only replica of the pgdbname database. Read-only queries are sent to replicas that are not behind the best block. May be specified multiple times." env:"DCRDATA_PG_REPLICAS" env-delim:";"`
 	PGQueryTimeout   time.Duration `short:"T" long:"pgtimeout" description:"Timeout (a time.Duration string) for most PostgreSQL queries used for user initiated queries." env:"DCRDATA_PG_QUERY_TIMEOUT"`
+	PGSlowQuery      time.Duration `long:"pgslowquery" description:"Log PostgreSQL queries that take longer than this duration (e.g. 500ms), and keep a report of the slowest recent queries that is served on /admin/slowqueries of pgslowquerylisten and logged on SIGUSR2. Disabled by default." env:"DCRDATA_PG_SLOW_QUERY"`
+	PGSlowQueryTop   int           `long:"pgslowquerytop" description:"Number of statements in the slow query report." env:"DCRDATA_PG_SLOW_QUERY_TOP"`
+	PGSlowQueryAddr  string        `long:"pgslowquerylisten" description:"Loopback listen address for the slow query report. (default 127.0.0.1:7776)" env:"DCRDATA_PG_SLOW_QUERY_LISTEN"`
 	HidePGConfig     bool          `long:"hidepgconfig" description:"Blocks logging of the PostgreSQL db configuration on system start up." env:"DCRDATA_PG_HIDE_CONFIG"`
 	DropIndexes      bool          `long:"drop-inds" short:"D" description:"Drop all table indexes and exit." env:"DCRDATA_PG_DROP_INDEXES"`
 	PurgeNBestBlocks int           `long:"purge-n-blocks" description:"Purge all data for the N best blocks, using the best block across all DBs if they are out of sync." env:"DCRDATA_PURGE_N_BLOCKS"`
+	PGPartition      bool          `long:"pg-partition" description:"Use range partitioning for the vins, vouts, and addresses tables. Existing unpartitioned tables are migrated on startup, which may take a very long time." env:"DCRDATA_PG_PARTITION"`
+	PGPartMonths     int           `long:"pg-partition-months" description:"Width in months of the block time range of each vins and addresses table partition." env:"DCRDATA_PG_PARTITION_MONTHS"`
+	PGPartVoutRows   int64         `long:"pg-partition-vout-rows" description:"Width of the row ID range of each vouts table partition." env:"DCRDATA_PG_PARTITION_VOUT_ROWS"`
 	SyncAndQuit      bool          `long:"sync-and-quit" description:"Sync to the best block and exit. Do not start the explorer or API." env:"DCRDATA_ENABLE_SYNC_N_QUIT"`
 	ImportSideChains bool          `long:"import-side-chains" description:"(experimental) Enable startup import of side chains retrieved from dcrd via getchaintips." env:"DCRDATA_IMPORT_SIDE_CHAINS"`
 	SyncStatusLimit  int           `long:"sync-status-limit" description:"Sets the number of blocks behind the current best height past which only the syncing status page can be served on the running web server. Value should be greater than 2 but less than 5000." env:"DCRDATA_SYNC_STATUS_LIMIT"`
@@ -160,11 +180,20 @@ type config struct {
 	NoBlockPrefetch  bool   `long:"no-dcrd-block-prefetch" description:"Disable block pre-fetch from dcrd during startup sync." env:"DCRDATA_NO_BLOCK_PREFETCH"`
 
 	// ExchangeBot settings
-	EnableExchangeBot bool   `long:"exchange-monitor" description:"Enable the exchange monitor" env:"DCRDATA_MONITOR_EXCHANGES"`
-	DisabledExchanges string `long:"disable-exchange" description:"Exchanges to disable. See /exchanges/exchanges.go for available exchanges. Use a comma to separate multiple exchanges" env:"DCRDATA_DISABLE_EXCHANGES"`
-	ExchangeCurrency  string `long:"exchange-currency" description:"The default price index. A 3-letter currency code." env:"DCRDATA_EXCHANGE_INDEX"`
-	RateMaster        string `long:"ratemaster" description:"The address of a DCRRates instance. Exchange monitoring will get all data from a DCRRates subscription." env:"DCRDATA_RATE_MASTER"`
-	RateCertificate   string `long:"ratecert" description:"File containing DCRRates TLS certificate file." env:"DCRDATA_RATE_MASTER"`
+	EnableExchangeBot bool          `long:"exchange-monitor" description:"Enable the exchange monitor" env:"DCRDATA_MONITOR_EXCHANGES"`
+	DisabledExchanges string        `long:"disable-exchange" description:"Exchanges to disable. See /exchanges/exchanges.go for available exchanges. Use a comma to separate multiple exchanges" env:"DCRDATA_DISABLE_EXCHANGES"`
+	ExchangeCurrency  string        `long:"exchange-currency" description:"The default price index. A 3-letter currency code." env:"DCRDATA_EXCHANGE_INDEX"`
+	ExchangeHistory   bool          `long:"exchange-history" description:"Record the history of the DCR index price in the database, for the fiat values of transactions at their block times. Requires --exchange-monitor." env:"DCRDATA_EXCHANGE_HISTORY"`
+	ExchangeSpecs     string        `long:"exchange-specs" description:"Path to a JSON file of exchange API specs. The declared exchanges are monitored, replacing any built-in exchange with the same token. See exchanges/sample-exchange-specs.json." env:"DCRDATA_EXCHANGE_SPECS"`
+	ExchangeAlerts    string        `long:"exchange-alerts" description:"Path to a JSON file of alert rules evaluated on the exchange updates, e.g. for large price moves or failing exchanges. See exchanges/sample-exchange-alerts.json. Requires --exchange-monitor." env:"DCRDATA_EXCHANGE_ALERTS"`
+	IndexMethod       string        `long:"exchange-index-method" description:"How the exchange prices are combined into the DCR price index: mean, median, or trimmed (a trimmed mean)." choice:"mean" choice:"median" choice:"trimmed" env:"DCRDATA_EXCHANGE_INDEX_METHOD"`
+	IndexTrim         float64       `long:"exchange-index-trim" description:"The fraction of the total weight discarded from each end of the price range by the trimmed index method. (default 0.2)" env:"DCRDATA_EXCHANGE_INDEX_TRIM"`
+	IndexVolumeWeight bool          `long:"exchange-index-volume-weighted" description:"Weight the exchanges in the DCR price index by their volume, instead of equally." env:"DCRDATA_EXCHANGE_INDEX_VOLUME_WEIGHTED"`
+	IndexMaxWeight    float64       `long:"exchange-index-max-weight" description:"The largest share of the DCR price index weight that any one exchange may have, e.g. 0.4. Zero is no cap." env:"DCRDATA_EXCHANGE_INDEX_MAX_WEIGHT"`
+	IndexMaxDeviation float64       `long:"exchange-index-max-deviation" description:"Exclude exchanges from the DCR price index when their price differs from the median by more than this fraction, e.g. 0.1. Zero disables outlier rejection." env:"DCRDATA_EXCHANGE_INDEX_MAX_DEVIATION"`
+	IndexHalfLife     time.Duration `long:"exchange-index-stale-halflife" description:"Halve an exchange's weight in the DCR price index for every interval (a time.Duration string) since its last update. Zero disables staleness decay." env:"DCRDATA_EXCHANGE_INDEX_STALE_HALFLIFE"`
+	RateMaster        string        `long:"ratemaster" description:"The address of a DCRRates instance. Exchange monitoring will get all data from a DCRRates subscription." env:"DCRDATA_RATE_MASTER"`
+	RateCertificate   string        `long:"ratecert" description:"File containing DCRRates TLS certificate file." env:"DCRDATA_RATE_MASTER"`
 
 	// Links
 	MainnetLink  string `long:"mainnet-link" description:"When dcrdata is on testnet, this address will be used to direct a user to a dcrdata on mainnet when appropriate." env:"DCRDATA_MAINNET_LINK"`
@@ -195,11 +224,16 @@ var (
 		MempoolMinInterval:  defaultMempoolMinInterval,
 		MempoolMaxInterval:  defaultMempoolMaxInterval,
 		MPTriggerTickets:    defaultMPTriggerTickets,
+		DBBackend:           defaultDBBackend,
 		PGDBName:            defaultPGDBName,
 		PGUser:              defaultPGUser,
 		PGPass:              defaultPGPass,
 		PGHost:              defaultPGHost,
 		PGQueryTimeout:      defaultPGQueryTimeout,
+		PGPartMonths:        defaultPGPartMonths,
+		PGPartVoutRows:      defaultPGPartVoutRows,
+		PGSlowQueryTop:      defaultPGSlowQueryTop,
+		PGSlowQueryAddr:     defaultPGSlowQueryAddr,
 		AddrCacheCap:        defaultAddrCacheCap,
 		AddrCacheLimit:      defaultAddrCacheLimit,
 		AddrCacheUXTOCap:    defaultAddrCacheUXTOCap,
@@ -592,6 +626,51 @@ func loadConfig() (*config, error) {
 		return nil, fmt.Errorf("purge-n-blocks must be non-negative")
 	}
 
+	// Validate the DB backend.
+	switch cfg.DBBackend {
+	case "postgres", "sqlite":
+	default:
+		return nil, fmt.Errorf("dbbackend must be postgres or sqlite, not %q", cfg.DBBackend)
+	}
+	if cfg.DBBackend == "sqlite" && (cfg.DropIndexes || cfg.ImportSideChains) {
+		return nil, fmt.Errorf("drop-inds and import-side-chains are not supported with dbbackend=sqlite")
+	}
+	if cfg.DBBackend == "sqlite" && cfg.PGSlowQuery > 0 {
+		return nil, fmt.Errorf("pgslowquery is not supported with dbbackend=sqlite")
+	}
+
+	// The SQLite database file goes in the network's data directory unless
+	// specified.
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = filepath.Join(cfg.DataDir, defaultSQLiteFileName)
+	} else {
+		cfg.SQLitePath = cleanAndExpandPath(cfg.SQLitePath)
+	}
+
+	// Validate table partitioning options.
+	if cfg.PGPartMonths < 1 {
+		return nil, fmt.Errorf("pg-partition-months must be positive")
+	}
+	if cfg.PGPartVoutRows < 1 {
+		return nil, fmt.Errorf("pg-partition-vout-rows must be positive")
+	}
+
+	// Validate slow query log options.
+	if cfg.PGSlowQuery < 0 {
+		return nil, fmt.Errorf("pgslowquery must be non-negative")
+	}
+	if cfg.PGSlowQueryTop < 1 {
+		return nil, fmt.Errorf("pgslowquerytop must be positive")
+	}
+	// The report is not for the public, so it is only served on loopback.
+	host, _, err := net.SplitHostPort(cfg.PGSlowQueryAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pgslowquerylisten %q: %v", cfg.PGSlowQueryAddr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("pgslowquerylisten must be a loopback address, not %q", cfg.PGSlowQueryAddr)
+	}
+
 	// Set the host names and ports to the default if the user does not specify
 	// them.
 	cfg.DcrdServ, err = normalizeNetworkAddress(cfg.DcrdServ, defaultHost, activeNet.JSONRPCClientPort)
@@ -669,6 +748,18 @@ func loadConfig() (*config, error) {
 	cfg.DcrdCert = cleanAndExpandPath(cfg.DcrdCert)
 	cfg.AgendasDBFileName = cleanAndExpandPath(cfg.AgendasDBFileName)
 	cfg.ProposalsFileName = cleanAndExpandPath(cfg.ProposalsFileName)
+	if cfg.VSPRegistry != "" {
+		cfg.VSPRegistry = cleanAndExpandPath(cfg.VSPRegistry)
+	}
+	if cfg.PoliteiaArchive != "" {
+		cfg.PoliteiaArchive = cleanAndExpandPath(cfg.PoliteiaArchive)
+	}
+	if cfg.ExchangeSpecs != "" {
+		cfg.ExchangeSpecs = cleanAndExpandPath(cfg.ExchangeSpecs)
+	}
+	if cfg.ExchangeAlerts != "" {
+		cfg.ExchangeAlerts = cleanAndExpandPath(cfg.ExchangeAlerts)
+	}
 	cfg.RateCertificate = cleanAndExpandPath(cfg.RateCertificate)
 	cfg.ChartsCacheDump = cleanAndExpandPath(cfg.ChartsCacheDump)
 
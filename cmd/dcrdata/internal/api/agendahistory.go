@@ -0,0 +1,142 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+
+	m "github.com/decred/dcrdata/cmd/dcrdata/internal/middleware"
+	apitypes "github.com/decred/dcrdata/v8/api/types"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// getAgendaVoteHistory processes a request for the vote history of an agenda
+// by rule change interval from /agenda/{agendaId}/history.
+func (c *appContext) getAgendaVoteHistory(w http.ResponseWriter, r *http.Request) {
+	agendaID := m.GetAgendaIdCtx(r)
+	if agendaID == "" {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	agenda, err := c.AgendaDB.AgendaInfo(agendaID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	history, err := c.DataSource.AgendaVoteHistory(agendaID)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AgendaVoteHistory timeout error: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the vote history of agenda %s: %v", agendaID, err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, agendaVoteHistory(c.Params, agendaID, agenda.VoteVersion, history),
+		m.GetIndentCtx(r))
+}
+
+// agendaVoteHistory assembles the vote history of the agenda with the provided
+// vote version from the vote tallies and version counts of each rule change
+// interval.
+func agendaVoteHistory(params *chaincfg.Params, agendaID string, voteVersion uint32,
+	history *dbtypes.AgendaVoteHistory) *apitypes.AgendaVoteHistory {
+	svh := params.StakeValidationHeight
+	rciBlocks := int64(params.RuleChangeActivationInterval)
+	milestone := history.MileStone
+
+	intervals := make(map[int64]*apitypes.AgendaRCI)
+	interval := func(rci int64) *apitypes.AgendaRCI {
+		if in, ok := intervals[rci]; ok {
+			return in
+		}
+		start := svh + rci*rciBlocks
+		end := start + rciBlocks - 1
+		if end > history.Height {
+			end = history.Height
+		}
+		in := &apitypes.AgendaRCI{
+			RCI:           rci,
+			StartHeight:   start,
+			EndHeight:     end,
+			Voting:        milestone.VotingStarted > 0 && start >= milestone.VotingStarted && start <= milestone.VotingDone,
+			StakeVersions: []*apitypes.VersionCount{},
+			BlockVersions: []*apitypes.VersionCount{},
+		}
+		intervals[rci] = in
+		return in
+	}
+
+	quorum := params.RuleChangeActivationQuorum
+	for _, v := range history.Votes {
+		in := interval(v.RCI)
+		in.Yes, in.Abstain, in.No, in.Total = v.Yes, v.Abstain, v.No, v.Total
+		if v.Yes+v.No > 0 {
+			in.Approval = float64(v.Yes) / float64(v.Yes+v.No)
+		}
+		in.QuorumMet = v.Yes+v.No >= quorum
+	}
+
+	// The stake versions are upgraded once the votes are of the agenda's
+	// version, and the block versions once the blocks are of the latest
+	// version.
+	var blockVersion uint32
+	for _, b := range history.BlockVersions {
+		if b.Version > blockVersion {
+			blockVersion = b.Version
+		}
+	}
+	stakeTotals := make(map[int64]uint32)
+	for _, c := range history.StakeVersions {
+		in := interval(c.RCI)
+		in.StakeVersions = append(in.StakeVersions, &apitypes.VersionCount{Version: c.Version, Count: c.Count})
+		stakeTotals[c.RCI] += c.Count
+		if c.Version >= voteVersion {
+			in.StakeUpgrade += float64(c.Count)
+		}
+	}
+	blockTotals := make(map[int64]uint32)
+	for _, c := range history.BlockVersions {
+		in := interval(c.RCI)
+		in.BlockVersions = append(in.BlockVersions, &apitypes.VersionCount{Version: c.Version, Count: c.Count})
+		blockTotals[c.RCI] += c.Count
+		if c.Version >= blockVersion {
+			in.BlockUpgrade += float64(c.Count)
+		}
+	}
+
+	resp := &apitypes.AgendaVoteHistory{
+		ID:            agendaID,
+		VoteVersion:   voteVersion,
+		MileStone:     &milestone,
+		Quorum:        quorum,
+		PassThreshold: float64(params.RuleChangeActivationMultiplier) / float64(params.RuleChangeActivationDivisor),
+		BlockVersion:  blockVersion,
+		Intervals:     make([]*apitypes.AgendaRCI, 0, len(intervals)),
+	}
+	switch milestone.Status {
+	case dbtypes.LockedInAgendaStatus, dbtypes.ActivatedAgendaStatus:
+		resp.LockedIn = milestone.VotingDone + 1
+	}
+	for rci, in := range intervals {
+		if total := stakeTotals[rci]; total > 0 {
+			in.StakeUpgrade /= float64(total)
+		}
+		if total := blockTotals[rci]; total > 0 {
+			in.BlockUpgrade /= float64(total)
+		}
+		resp.Intervals = append(resp.Intervals, in)
+	}
+	sort.Slice(resp.Intervals, func(i, j int) bool {
+		return resp.Intervals[i].RCI < resp.Intervals[j].RCI
+	})
+	return resp
+}
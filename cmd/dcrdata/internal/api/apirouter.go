@@ -129,16 +129,22 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			rd.With(app.BlockIndexLatestCtx).Get("/full", app.getTicketPool)
 			rd.With(m.BlockIndexPathCtx).Get("/b/{idx}", app.getTicketPoolInfo)
 			rd.With(m.BlockIndexOrHashPathCtx).Get("/b/{idxorhash}/full", app.getTicketPool)
+			rd.With(m.BlockIndexPathCtx, m.TransactionHashCtx).Get("/b/{idx}/ticket/{txid}", app.getTicketPoolMembership)
+			rd.With(m.BlockIndexPathCtx).Get("/b/{idx}/ages", app.getTicketPoolAges)
 			rd.With(m.BlockIndex0PathCtx, m.BlockIndexPathCtx).Get("/r/{idx0}/{idx}", app.getTicketPoolInfoRange)
+			rd.With(m.BlockIndex0PathCtx, m.BlockIndexPathCtx).Get("/r/{idx0}/{idx}/changes", app.getTicketPoolChanges)
 		})
 		r.Route("/diff", func(rd chi.Router) {
 			rd.Get("/", app.getStakeDiffSummary)
 			rd.Get("/current", app.getStakeDiffCurrent)
 			rd.Get("/estimates", app.getStakeDiffEstimates)
+			rd.Get("/forecast", app.getStakeDiffForecast)
 			rd.With(m.BlockIndexPathCtx).Get("/b/{idx}", app.getStakeDiff)
 			rd.With(m.BlockIndex0PathCtx, m.BlockIndexPathCtx).Get("/r/{idx0}/{idx}", app.getStakeDiffRange)
 		})
 		r.Get("/powerless", app.getPowerlessTickets)
+		r.Get("/selection/stats", app.getTicketSelectionStats)
+		r.Get("/rewards/simulate", app.getStakeRewardSimulation)
 	})
 
 	mux.Route("/tx", func(r chi.Router) {
@@ -204,6 +210,12 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	mux.Route("/treasury", func(r chi.Router) {
 		r.Get("/balance", app.getTreasuryBalance)
 		r.With(m.ChartGroupingCtx).Get("/io/{chartgrouping}", app.getTreasuryIO)
+		r.With(m.TransactionHashCtx).Get("/tspend/{txid}", app.getTreasurySpendTally)
+	})
+
+	mux.Route("/vsp", func(r chi.Router) {
+		r.Get("/", app.getVSPStats)
+		r.With(m.VSPNameCtx).Get("/{vsp}", app.getVSP)
 	})
 
 	// Returns agenda data like; description, name, lockedin activated and other
@@ -215,6 +227,7 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	// Returns the charts data for the respective individual agendas.
 	mux.Route("/agenda", func(r chi.Router) {
 		r.With(m.AgendaIdCtx).Get("/{agendaId}", app.getAgendaData)
+		r.With(m.AgendaIdCtx).Get("/{agendaId}/history", app.getAgendaVoteHistory)
 	})
 
 	mux.Route("/mempool", func(r chi.Router) {
@@ -231,10 +244,15 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 
 	mux.Route("/chart", func(r chi.Router) {
 		// Return default chart data (ticket price)
+		r.Route("/market/aggregate", func(rd chi.Router) {
+			rd.With(m.StickWidthContext).Get("/candlestick/{bin}", app.getAggregateCandlestickChart)
+			rd.Get("/depth", app.getAggregateDepthChart)
+		})
 		r.Route("/market/{token}", func(rd chi.Router) {
 			rd.Use(m.ExchangeTokenContext)
 			rd.With(m.StickWidthContext).Get("/candlestick/{bin}", app.getCandlestickChart)
 			rd.Get("/depth", app.getDepthChart)
+			rd.Get("/trades", app.getMarketTrades)
 		})
 		r.With(m.ChartTypeCtx).Get("/{charttype}", app.ChartTypeData)
 	})
@@ -247,10 +265,13 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 
 	mux.Route("/proposal", func(r chi.Router) {
 		r.With(m.ProposalTokenCtx).Get("/{token}", app.getProposalChartData)
+		r.With(m.ProposalTokenCtx).Get("/{token}/tickets", app.getProposalTickets)
+		r.With(m.ProposalTokenCtx, m.TransactionHashCtx).Get("/{token}/tickets/{txid}", app.getProposalTicketVote)
 	})
 
 	mux.Route("/exchangerate", func(r chi.Router) {
 		r.Get("/", app.getExchangeRates)
+		r.With(m.TimeCtx).Get("/at/{time}", app.getExchangeRateAt)
 	})
 
 	mux.Route("/exchanges", func(r chi.Router) {
@@ -313,6 +334,13 @@ func NewFileRouter(app *appContext, useRealIP bool) fileMux {
 		rd.With(m.AddressPathCtxN(1)).Get("/io/{address}/win", app.addressIoCsvCR)
 	})
 
+	mux.Route("/treasury", func(rd chi.Router) {
+		rd.Use(m.CacheControl(180))
+		rd.With(m.ChartGroupingCtx).Get("/statement/{chartgrouping}", app.treasuryStatementCsvNoCR)
+		rd.With(m.ChartGroupingCtx).Get("/statement/{chartgrouping}/win", app.treasuryStatementCsvCR)
+		rd.With(m.ChartGroupingCtx).Get("/statement/{chartgrouping}/json", app.treasuryStatementJSON)
+	})
+
 	return fileMux{mux}
 }
 
@@ -39,13 +39,24 @@ import (
 	apitypes "github.com/decred/dcrdata/v8/api/types"
 	"github.com/decred/dcrdata/v8/db/cache"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/stakediff"
+	"github.com/decred/dcrdata/v8/treasury"
 	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/vsp"
 )
 
 // maxBlockRangeCount is the maximum number of blocks that can be requested at
 // once.
 const maxBlockRangeCount = 1000
 
+// maxPoolChangesRange is the maximum number of blocks over which the ticket
+// pool changes can be requested.
+const maxPoolChangesRange = 8192
+
+// maxSelectionWindows is the maximum number of ticket selection statistics
+// windows that can be requested.
+const maxSelectionWindows = 52
+
 // DataSource specifies an interface for advanced data collection using the
 // auxiliary DB (e.g. PostgreSQL).
 type DataSource interface {
@@ -53,6 +64,8 @@ type DataSource interface {
 	GetBestBlockHash() (string, error)
 	GetBlockHash(idx int64) (string, error)
 	GetBlockHeight(hash string) (int64, error)
+	BlockTimeByHeight(height int64) (int64, error)
+	BlockHeightByTime(timestamp int64) (int64, error)
 	GetBlockByHash(string) (*wire.MsgBlock, error)
 	SpendingTransaction(fundingTx string, vout uint32) (string, uint32, error)
 	SpendingTransactions(fundingTxID string) ([]string, []uint32, []uint32, error)
@@ -66,9 +79,11 @@ type DataSource interface {
 		chartGroupings dbtypes.TimeBasedGrouping) (*dbtypes.ChartsData, error)
 	TreasuryBalance() (*dbtypes.TreasuryBalance, error)
 	BinnedTreasuryIO(chartGroupings dbtypes.TimeBasedGrouping) (*dbtypes.ChartsData, error)
+	TreasuryStatements(grouping dbtypes.TimeBasedGrouping) ([]*dbtypes.TreasuryStatement, error)
 	TicketPoolVisualization(interval dbtypes.TimeBasedGrouping) (
 		*dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, int64, error)
 	AgendaVotes(agendaID string, chartType int) (*dbtypes.AgendaVoteChoices, error)
+	AgendaVoteHistory(agendaID string) (*dbtypes.AgendaVoteHistory, error)
 	AddressRowsCompact(address string) ([]*dbtypes.AddressRowCompact, error)
 	Height() int64
 	IsDCP0010Active(height int64) bool
@@ -84,6 +99,13 @@ type DataSource interface {
 	GetPoolInfoRange(idx0, idx1 int) []apitypes.TicketPoolInfo
 	GetPoolValAndSizeRange(idx0, idx1 int) ([]float64, []uint32)
 	GetPool(idx int64) ([]string, error)
+	GetPoolMembership(ticket string, idx int64) (*apitypes.TicketPoolMembership, error)
+	GetPoolChanges(idx0, idx int64) (*apitypes.TicketPoolChanges, error)
+	GetPoolAges(idx, binSize int64) (*apitypes.TicketPoolAges, error)
+	TicketSelectionStats(windowSize int64, count int) (*apitypes.TicketSelectionStats, error)
+	VSPTicketSpends(feeAddrs []string) ([]*dbtypes.VSPAddressTickets, error)
+	TicketSpendAddresses(tickets, feeAddrs []string) ([]*dbtypes.TicketSpendAddress, error)
+	MeanVoteAges(start, end, binSize int64) ([]*dbtypes.VoteAgeBin, error)
 	CurrentCoinSupply() *apitypes.CoinSupply
 	GetHeader(idx int) *chainjson.GetBlockHeaderVerboseResult
 	GetBlockHeaderByHash(hash string) (*wire.BlockHeader, error)
@@ -98,6 +120,7 @@ type DataSource interface {
 	GetAllTxOut(txid *chainhash.Hash) []*apitypes.TxOut
 	GetTransactionsForBlockByHash(hash string) *apitypes.BlockTransactions
 	GetStakeDiffEstimates() *apitypes.StakeDiff
+	StakeDiffForecast(windows int) (*apitypes.StakeDiffForecast, error)
 	GetSummary(idx int) *apitypes.BlockDataBasic
 	GetSummaryRange(idx0, idx1 int) []*apitypes.BlockDataBasic
 	GetSummaryRangeStepped(idx0, idx1, step int) []*apitypes.BlockDataBasic
@@ -121,8 +144,11 @@ type appContext struct {
 	DataSource  DataSource
 	Status      *apitypes.Status
 	xcBot       *exchanges.ExchangeBot
+	prices      *exchanges.PriceHistory
 	AgendaDB    *agendas.AgendaDB
 	ProposalsDB *politeia.ProposalsDB
+	TSpends     *treasury.TSpendTracker
+	VSPs        *vsp.Tracker
 	maxCSVAddrs int
 	charts      *cache.ChartData
 }
@@ -134,8 +160,11 @@ type AppContextConfig struct {
 	Params            *chaincfg.Params
 	DataSource        DataSource
 	XcBot             *exchanges.ExchangeBot
+	PriceHistory      *exchanges.PriceHistory
 	AgendasDBInstance *agendas.AgendaDB
 	ProposalsDB       *politeia.ProposalsDB
+	TSpendTracker     *treasury.TSpendTracker
+	VSPTracker        *vsp.Tracker
 	MaxAddrs          int
 	Charts            *cache.ChartData
 	AppVer            string
@@ -158,8 +187,11 @@ func NewContext(cfg *AppContextConfig) *appContext {
 		Params:      cfg.Params,
 		DataSource:  cfg.DataSource,
 		xcBot:       cfg.XcBot,
+		prices:      cfg.PriceHistory,
 		AgendaDB:    cfg.AgendasDBInstance,
 		ProposalsDB: cfg.ProposalsDB,
+		TSpends:     cfg.TSpendTracker,
+		VSPs:        cfg.VSPTracker,
 		Status:      apitypes.NewStatus(uint32(nodeHeight), conns, APIVersion, cfg.AppVer, cfg.Params.Name),
 		maxCSVAddrs: cfg.MaxAddrs,
 		charts:      cfg.Charts,
@@ -1110,6 +1142,31 @@ func (c *appContext) getStakeDiffEstimates(w http.ResponseWriter, r *http.Reques
 	writeJSON(w, stakeDiff.Estimates, m.GetIndentCtx(r))
 }
 
+func (c *appContext) getStakeDiffForecast(w http.ResponseWriter, r *http.Request) {
+	windows := stakediff.MaxWindows
+	if windowsParam := r.URL.Query().Get("windows"); windowsParam != "" {
+		var err error
+		windows, err = strconv.Atoi(windowsParam)
+		if err != nil || windows < 1 || windows > stakediff.MaxWindows {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	forecast, err := c.DataSource.StakeDiffForecast(windows)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("StakeDiffForecast: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to forecast the stake difficulty: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	writeJSON(w, forecast, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getSSTxSummary(w http.ResponseWriter, r *http.Request) {
 	sstxSummary := c.DataSource.GetMempoolSSTxSummary()
 	if sstxSummary == nil {
@@ -1458,6 +1515,107 @@ func (c *appContext) getTicketPoolInfo(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, tpi, m.GetIndentCtx(r))
 }
 
+func (c *appContext) getTicketPoolMembership(w http.ResponseWriter, r *http.Request) {
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	membership, err := c.DataSource.GetPoolMembership(txid.String(), idx)
+	if err != nil {
+		apiLog.Errorf("Unable to check ticket pool membership: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	writeJSON(w, membership, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketPoolAges(w http.ResponseWriter, r *http.Request) {
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	// The default bin size is one day of blocks.
+	binSize := int64(24 * time.Hour / c.Params.TargetTimePerBlock)
+	if binParam := r.URL.Query().Get("bin"); binParam != "" {
+		binSize, err = strconv.ParseInt(binParam, 10, 64)
+		if err != nil || binSize < 1 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ages, err := c.DataSource.GetPoolAges(idx, binSize)
+	if err != nil {
+		apiLog.Errorf("Unable to get ticket pool ages: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	writeJSON(w, ages, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketPoolChanges(w http.ResponseWriter, r *http.Request) {
+	idx0 := m.GetBlockIndex0Ctx(r)
+	if idx0 < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	idx := m.GetBlockIndexCtx(r)
+	if idx < idx0 || idx-idx0 > maxPoolChangesRange {
+		http.Error(w, "invalid range", http.StatusUnprocessableEntity)
+		return
+	}
+
+	changes, err := c.DataSource.GetPoolChanges(int64(idx0), int64(idx))
+	if err != nil {
+		apiLog.Errorf("Unable to get ticket pool changes: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	writeJSON(w, changes, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketSelectionStats(w http.ResponseWriter, r *http.Request) {
+	// The default window is 30 days of blocks.
+	windowSize := int64(30 * 24 * time.Hour / c.Params.TargetTimePerBlock)
+	if windowParam := r.URL.Query().Get("window"); windowParam != "" {
+		var err error
+		windowSize, err = strconv.ParseInt(windowParam, 10, 64)
+		if err != nil || windowSize < 1 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	count := 12
+	if countParam := r.URL.Query().Get("count"); countParam != "" {
+		var err error
+		count, err = strconv.Atoi(countParam)
+		if err != nil || count < 1 || count > maxSelectionWindows {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := c.DataSource.TicketSelectionStats(windowSize, count)
+	if err != nil {
+		apiLog.Errorf("Unable to get ticket selection stats: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	writeJSON(w, stats, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getTicketPoolInfoRange(w http.ResponseWriter, r *http.Request) {
 	if useArray := r.URL.Query().Get("arrays"); useArray != "" {
 		_, err := strconv.ParseBool(useArray)
@@ -1604,6 +1762,143 @@ func (c *appContext) addressIoCsvCR(w http.ResponseWriter, r *http.Request) {
 	c.addressIoCsv(true, w, r)
 }
 
+func (c *appContext) treasuryStatementCsvNoCR(w http.ResponseWriter, r *http.Request) {
+	c.treasuryStatementCsv(false, w, r)
+}
+func (c *appContext) treasuryStatementCsvCR(w http.ResponseWriter, r *http.Request) {
+	c.treasuryStatementCsv(true, w, r)
+}
+
+// Handler for treasury statement CSV file download. Each row is a line item of
+// a period's statement.
+// /download/treasury/statement/{chartgrouping}[/win]
+func (c *appContext) treasuryStatementCsv(crlf bool, w http.ResponseWriter, r *http.Request) {
+	statements, grouping, ok := c.treasuryStatements(w, r)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("treasury-statement-%s-%d-%s.csv", grouping,
+		c.Status.Height(), strconv.FormatInt(time.Now().Unix(), 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filename))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = crlf
+
+	err := writer.Write([]string{"period_start", "period_end", "item", "tx_hash",
+		"address", "value", "fiat_rate", "fiat_value", "fiat_index"})
+	if err != nil {
+		return // too late to write an error code
+	}
+
+	for _, st := range statements {
+		start, end := st.Start.T.Format("2006-01-02"), st.End.T.Format("2006-01-02")
+		line := func(item, txHash, address string, amount int64) error {
+			coin := dcrutil.Amount(amount).ToCoin()
+			// The fiat columns say so when there is no rate for the period,
+			// rather than being left empty.
+			fiatRate, fiatValue, fiatIndex := "unavailable", "unavailable", ""
+			if st.FiatAvailable {
+				fiatRate = strconv.FormatFloat(st.FiatRate, 'f', 2, 64)
+				fiatValue = strconv.FormatFloat(st.FiatRate*coin, 'f', 2, 64)
+				fiatIndex = st.FiatIndex
+			}
+			return writer.Write([]string{start, end, item, txHash, address,
+				strconv.FormatFloat(coin, 'f', -1, 64), fiatRate, fiatValue,
+				fiatIndex})
+		}
+
+		if err = line("opening_balance", "", "", st.OpeningBalance); err != nil {
+			return
+		}
+		if err = line("tbase", "", "", st.TBase); err != nil {
+			return
+		}
+		for _, src := range st.AddSources {
+			if err = line("tadd", "", src.Address, src.Amount); err != nil {
+				return
+			}
+		}
+		for _, p := range st.Payouts {
+			if err = line("tspend", p.TxID, p.Address, -p.Amount); err != nil {
+				return
+			}
+		}
+		if err = line("closing_balance", "", "", st.ClosingBalance); err != nil {
+			return
+		}
+	}
+	writer.Flush()
+}
+
+// Handler for treasury statement JSON file download.
+// /download/treasury/statement/{chartgrouping}/json
+func (c *appContext) treasuryStatementJSON(w http.ResponseWriter, r *http.Request) {
+	statements, grouping, ok := c.treasuryStatements(w, r)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("treasury-statement-%s-%d-%s.json", grouping,
+		c.Status.Height(), strconv.FormatInt(time.Now().Unix(), 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filename))
+	writeJSON(w, statements, m.GetIndentCtx(r))
+}
+
+// treasuryStatements retrieves the treasury statements for the request's chart
+// grouping and values them in fiat. On failure, the error response is written
+// and the boolean is false.
+func (c *appContext) treasuryStatements(w http.ResponseWriter, r *http.Request) ([]*dbtypes.TreasuryStatement, dbtypes.TimeBasedGrouping, bool) {
+	grouping := dbtypes.TimeGroupingFromStr(m.GetChartGroupingCtx(r))
+	if grouping != dbtypes.YearGrouping && grouping != dbtypes.MonthGrouping {
+		http.Error(w, "statements are by year or month", http.StatusUnprocessableEntity)
+		return nil, grouping, false
+	}
+
+	statements, err := c.DataSource.TreasuryStatements(grouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TreasuryStatements: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return nil, grouping, false
+	}
+	if err != nil {
+		apiLog.Errorf("TreasuryStatements: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, grouping, false
+	}
+
+	c.valueTreasuryStatements(statements)
+	return statements, grouping, true
+}
+
+// valueTreasuryStatements sets the fiat rates and values of the statements
+// from the mean rate of each period in the exchange rate history. The
+// statements of periods without a rate in the history, including all
+// statements if the history is disabled, are left unvalued. The tspend payouts
+// are not linked to politeia proposals, since politeia does not record which
+// proposal a payout is for.
+func (c *appContext) valueTreasuryStatements(statements []*dbtypes.TreasuryStatement) {
+	for _, st := range statements {
+		price, err := c.prices.PeriodPrice(st.Start.T, st.End.T)
+		if err != nil {
+			apiLog.Warnf("Unable to get the exchange rate for %v to %v: %v",
+				st.Start.T, st.End.T, err)
+		}
+		if price == nil {
+			continue
+		}
+		rate := price.Value
+		st.FiatAvailable, st.FiatRate, st.FiatIndex = true, rate, price.Index
+		for _, src := range st.AddSources {
+			src.FiatValue = rate * dcrutil.Amount(src.Amount).ToCoin()
+		}
+		for _, p := range st.Payouts {
+			p.FiatValue = rate * dcrutil.Amount(p.Amount).ToCoin()
+		}
+	}
+}
+
 // Handler for address activity CSV file download.
 // /download/address/io/{address}[/win]
 func (c *appContext) addressIoCsv(crlf bool, w http.ResponseWriter, r *http.Request) {
@@ -1647,13 +1942,17 @@ func (c *appContext) addressIoCsv(crlf bool, w http.ResponseWriter, r *http.Requ
 	writer.UseCRLF = crlf
 
 	err = writer.Write([]string{"tx_hash", "direction", "io_index",
-		"valid_mainchain", "value", "time_stamp", "tx_type", "matching_tx_hash"})
+		"valid_mainchain", "value", "time_stamp", "tx_type", "matching_tx_hash",
+		"fiat_value", "fiat_index"})
 	if err != nil {
 		return // too late to write an error code
 	}
 	writer.Flush()
 	wf.Flush()
 
+	// The fiat values are at the price history's price for the interval of
+	// the block time, which is looked up once per interval.
+	prices := make(map[int64]*exchanges.PricePoint)
 	var strValidMainchain, strDirection string
 	for _, r := range rows {
 		if r.ValidMainChain {
@@ -1672,6 +1971,21 @@ func (c *appContext) addressIoCsv(crlf bool, w http.ResponseWriter, r *http.Requ
 			matchingTx = r.MatchingTxHash.String()
 		}
 
+		var fiatValue, fiatIndex string
+		interval := time.Unix(r.TxBlockTime, 0).Truncate(exchanges.PriceInterval).Unix()
+		price, found := prices[interval]
+		if !found {
+			price, err = c.prices.PriceAt(time.Unix(r.TxBlockTime, 0))
+			if err != nil {
+				log.Warnf("Failed to get the exchange rate at %d: %v", r.TxBlockTime, err)
+			}
+			prices[interval] = price
+		}
+		if price != nil {
+			fiatValue = strconv.FormatFloat(dcrutil.Amount(r.Value).ToCoin()*price.Price, 'f', 2, 64)
+			fiatIndex = price.Index
+		}
+
 		err = writer.Write([]string{
 			r.TxHash.String(),
 			strDirection,
@@ -1681,6 +1995,8 @@ func (c *appContext) addressIoCsv(crlf bool, w http.ResponseWriter, r *http.Requ
 			strconv.FormatInt(r.TxBlockTime, 10),
 			txhelpers.TxTypeToString(int(r.TxType)),
 			matchingTx,
+			fiatValue,
+			fiatIndex,
 		})
 		if err != nil {
 			return // too late to write an error code
@@ -1768,6 +2084,82 @@ func (c *appContext) getTreasuryBalance(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, treasuryBalance, m.GetIndentCtx(r))
 }
 
+func (c *appContext) getTreasurySpendTally(w http.ResponseWriter, r *http.Request) {
+	txHash, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+
+	tally, err := c.TSpends.Tally(txHash)
+	if errors.Is(err, treasury.ErrNotTSpend) {
+		http.Error(w, "not a treasury spend", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to tally the votes on tspend %v: %v", txHash, err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, tally, m.GetIndentCtx(r))
+}
+
+// vspStats gets the ticket stats of the registered VSPs, which are computed
+// once per block.
+func (c *appContext) vspStats() ([]*apitypes.VSPStats, error) {
+	return c.VSPs.CachedStats(func() ([]string, []*dbtypes.VSPAddressTickets, error) {
+		height, err := c.DataSource.GetHeight()
+		if err != nil {
+			return nil, nil, err
+		}
+		pool, err := c.DataSource.GetPool(height)
+		if err != nil {
+			return nil, nil, err
+		}
+		spent, err := c.DataSource.VSPTicketSpends(c.VSPs.Registry().FeeAddresses())
+		return pool, spent, err
+	})
+}
+
+func (c *appContext) getVSPStats(w http.ResponseWriter, r *http.Request) {
+	if c.VSPs == nil {
+		http.Error(w, "no VSP registry", http.StatusNotFound)
+		return
+	}
+	stats, err := c.vspStats()
+	if err != nil {
+		apiLog.Errorf("Unable to get VSP stats: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getVSP(w http.ResponseWriter, r *http.Request) {
+	if c.VSPs == nil {
+		http.Error(w, "no VSP registry", http.StatusNotFound)
+		return
+	}
+	name := m.GetVSPNameCtx(r)
+	if _, found := c.VSPs.Registry().ByName(name); !found {
+		http.Error(w, "unknown VSP", http.StatusNotFound)
+		return
+	}
+	stats, err := c.vspStats()
+	if err != nil {
+		apiLog.Errorf("Unable to get VSP stats: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	for _, s := range stats {
+		if s.Name == name {
+			writeJSON(w, s, m.GetIndentCtx(r))
+			return
+		}
+	}
+}
+
 func (c *appContext) getTreasuryIO(w http.ResponseWriter, r *http.Request) {
 	chartGrouping := m.GetChartGroupingCtx(r)
 	if chartGrouping == "" {
@@ -1856,6 +2248,65 @@ func (c *appContext) getDepthChart(w http.ResponseWriter, r *http.Request) {
 	writeJSONBytes(w, chart)
 }
 
+// route: /market/{token}/trades
+func (c *appContext) getMarketTrades(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	token := m.RetrieveExchangeTokenCtx(r)
+	currencyPair, err := c.retrieveCurrencyPair(r)
+	if token == "" || err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	trades, err := c.xcBot.QuickTrades(token, currencyPair)
+	if err != nil {
+		apiLog.Infof("QuickTrades error: %v", err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSONBytes(w, trades)
+}
+
+// route: /market/aggregate/candlestick/{bin}
+func (c *appContext) getAggregateCandlestickChart(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	bin := m.RetrieveStickWidthCtx(r)
+	if bin == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	chart, err := c.xcBot.AggregateSticks(bin)
+	if err != nil {
+		apiLog.Infof("AggregateSticks error: %v", err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSONBytes(w, chart)
+}
+
+// route: /market/aggregate/depth
+func (c *appContext) getAggregateDepthChart(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	chart, err := c.xcBot.AggregateDepth()
+	if err != nil {
+		apiLog.Infof("AggregateDepth error: %v", err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSONBytes(w, chart)
+}
+
 func (c *appContext) getAddressTransactions(w http.ResponseWriter, r *http.Request) {
 	addresses, err := m.GetAddressCtx(r, c.Params)
 	if err != nil || len(addresses) > 1 {
@@ -2009,6 +2460,38 @@ func (c *appContext) getExchangeRates(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, rates, m.GetIndentCtx(r))
 }
 
+// getExchangeRateAt processes a request for the DCR price in the exchange bot's
+// index currency at a time from /exchangerate/at/{time}, where the time is a
+// date formatted as 2006-01-02 or a UNIX timestamp.
+func (c *appContext) getExchangeRateAt(w http.ResponseWriter, r *http.Request) {
+	if c.prices == nil {
+		http.Error(w, "Exchange rate history disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	t, err := parseSimTime(m.GetTimeCtx(r))
+	if err != nil {
+		http.Error(w, "invalid time", http.StatusBadRequest)
+		return
+	}
+
+	price, err := c.prices.PriceAt(time.Unix(t, 0))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("IndexPriceAt timeout error: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the exchange rate at %d: %v", t, err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	if price == nil {
+		http.Error(w, "No exchange rate data available", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, price, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getCurrencyCodes(w http.ResponseWriter, r *http.Request) {
 	if c.xcBot == nil {
 		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
@@ -2103,7 +2586,7 @@ func (c *appContext) retrieveCurrencyPair(r *http.Request) (exchanges.CurrencyPa
 		// Use the DCR-BTC pair for backward compatibility.
 		pair = exchanges.CurrencyPairDCRBTC
 	}
-	if !pair.IsValidDCRPair() {
+	if !pair.IsDCRMarket() {
 		return "", fmt.Errorf("invalid currency pair (%s)", pair)
 	}
 	return pair, nil
@@ -0,0 +1,108 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asdine/storm/v3"
+
+	m "github.com/decred/dcrdata/cmd/dcrdata/internal/middleware"
+	"github.com/decred/dcrdata/gov/v6/politeia"
+	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// proposalTicketVotes gets the ticket votes on the proposal with the token,
+// writing the error response if they are not available.
+func (c *appContext) proposalTicketVotes(w http.ResponseWriter, r *http.Request) (*pitypes.ProposalRecord, *pitypes.ProposalTicketVotes, bool) {
+	if c.ProposalsDB == nil {
+		http.Error(w, "no proposals DB", http.StatusNotFound)
+		return nil, nil, false
+	}
+	token := m.GetProposalTokenCtx(r)
+	proposal, err := c.ProposalsDB.ProposalByToken(token)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil, nil, false
+	}
+	votes, err := c.ProposalsDB.ProposalTicketVotes(token)
+	if errors.Is(err, storm.ErrNotFound) {
+		http.Error(w, "no ticket votes for the proposal", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the ticket votes on proposal %s: %v", token, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return proposal, votes, true
+}
+
+// proposalTickets gets the chain data of the tickets eligible to vote on a
+// proposal. See (*politeia.ProposalsDB).ProposalTickets.
+func (c *appContext) proposalTickets(votes *pitypes.ProposalTicketVotes) (map[string]*pitypes.TicketInfo, error) {
+	var vsps politeia.VSPAttributor
+	if c.VSPs != nil {
+		vsps = c.VSPs
+	}
+	return c.ProposalsDB.ProposalTickets(votes, c.DataSource, vsps)
+}
+
+// getProposalTickets processes a request for the turnout of the vote on a
+// proposal, by ticket age and by VSP, from /proposal/{token}/tickets.
+func (c *appContext) getProposalTickets(w http.ResponseWriter, r *http.Request) {
+	proposal, votes, ok := c.proposalTicketVotes(w, r)
+	if !ok {
+		return
+	}
+
+	tickets, err := c.proposalTickets(votes)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TicketSpendAddresses timeout error: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the tickets of proposal %s: %v", proposal.Token, err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	blocksPerDay := int64(24 * time.Hour / c.Params.TargetTimePerBlock)
+	writeJSON(w, votes.Turnout(int64(proposal.StartBlockHeight), tickets, blocksPerDay),
+		m.GetIndentCtx(r))
+}
+
+// getProposalTicketVote processes a request for how a ticket voted on a
+// proposal from /proposal/{token}/tickets/{txid}.
+func (c *appContext) getProposalTicketVote(w http.ResponseWriter, r *http.Request) {
+	hash, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	proposal, votes, ok := c.proposalTicketVotes(w, r)
+	if !ok {
+		return
+	}
+
+	ticket := hash.String()
+	tickets, err := c.proposalTickets(votes)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TicketSpendAddresses timeout error: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the tickets of proposal %s: %v", proposal.Token, err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, votes.TicketVote(ticket, int64(proposal.StartBlockHeight), tickets[ticket]),
+		m.GetIndentCtx(r))
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/standalone/v2"
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	m "github.com/decred/dcrdata/cmd/dcrdata/internal/middleware"
+	apitypes "github.com/decred/dcrdata/v8/api/types"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/txhelpers"
+)
+
+// maxStakeSimProjection limits how far beyond the best block a stake reward
+// simulation may be projected.
+const maxStakeSimProjection = 2 * 365 * 24 * time.Hour
+
+// Reinvestment policies of the stake reward simulation. With reinvestAll, the
+// whole balance is staked in fractional tickets, as the ASR calculation does.
+// With reinvestWhole, only whole tickets are bought. With reinvestNone, the
+// starting balance is restaked and the rewards are set aside.
+const (
+	reinvestAll   = "all"
+	reinvestWhole = "whole"
+	reinvestNone  = "none"
+)
+
+var errStakeSimStart = errors.New("start time is after the best block")
+
+// parseSimTime parses a date formatted as 2006-01-02, or a UNIX timestamp.
+func parseSimTime(s string) (int64, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Unix(), nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func (c *appContext) getStakeRewardSimulation(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	balance, err := strconv.ParseFloat(q.Get("balance"), 64)
+	if err != nil || balance <= 0 || math.IsInf(balance, 0) {
+		http.Error(w, "invalid balance", http.StatusBadRequest)
+		return
+	}
+	start, err := parseSimTime(q.Get("start"))
+	if err != nil {
+		http.Error(w, "invalid start", http.StatusBadRequest)
+		return
+	}
+	// Simulate a year by default.
+	end := start + 365*24*60*60
+	if endParam := q.Get("end"); endParam != "" {
+		end, err = parseSimTime(endParam)
+		if err != nil || end <= start {
+			http.Error(w, "invalid end", http.StatusBadRequest)
+			return
+		}
+	}
+	reinvest := q.Get("reinvest")
+	switch reinvest {
+	case "":
+		reinvest = reinvestAll
+	case reinvestAll, reinvestWhole, reinvestNone:
+	default:
+		http.Error(w, "invalid reinvest policy", http.StatusBadRequest)
+		return
+	}
+	var project bool
+	if projectParam := q.Get("project"); projectParam != "" {
+		project, err = strconv.ParseBool(projectParam)
+		if err != nil {
+			http.Error(w, "invalid project", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sim, err := c.simulateStakeRewards(balance, reinvest, start, end, project)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("simulateStakeRewards: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, errStakeSimStart) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to simulate stake rewards: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	writeJSON(w, sim, m.GetIndentCtx(r))
+}
+
+// simulateStakeRewards stakes a balance of DCR from the start time to the end
+// time. Tickets are bought at the historical ticket prices, and vote at the
+// mean age of the votes cast around the time they are expected to vote, for
+// the vote reward at the vote height. The returned funds are restaked once
+// they mature. If project is true, the simulation continues beyond the best
+// block at the expected next ticket price and the latest observed vote age.
+func (c *appContext) simulateStakeRewards(balance float64, reinvest string, start, end int64,
+	project bool) (*apitypes.StakeRewardSimulation, error) {
+	tip, err := c.DataSource.GetHeight()
+	if err != nil {
+		return nil, err
+	}
+	tipTime, err := c.DataSource.BlockTimeByHeight(tip)
+	if err != nil {
+		return nil, err
+	}
+	if start > tipTime {
+		return nil, errStakeSimStart
+	}
+	startHeight, err := c.DataSource.BlockHeightByTime(start)
+	if err != nil {
+		return nil, fmt.Errorf("BlockHeightByTime: %w", err)
+	}
+
+	// Beyond the best block, the blocks are expected at the target spacing.
+	targetSecs := int64(c.Params.TargetTimePerBlock / time.Second)
+	blockTime := func(height int64) (int64, error) {
+		if height > tip {
+			return tipTime + (height-tip)*targetSecs, nil
+		}
+		return c.DataSource.BlockTimeByHeight(height)
+	}
+	endHeight := tip
+	if end <= tipTime {
+		endHeight, err = c.DataSource.BlockHeightByTime(end)
+		if err != nil {
+			return nil, fmt.Errorf("BlockHeightByTime: %w", err)
+		}
+	} else if project {
+		maxEnd := tipTime + int64(maxStakeSimProjection/time.Second)
+		if end > maxEnd {
+			end = maxEnd
+		}
+		endHeight = tip + (end-tipTime)/targetSecs
+	}
+
+	histEnd := endHeight
+	if histEnd > tip {
+		histEnd = tip
+	}
+	prices := c.DataSource.GetSDiffRange(int(startHeight), int(histEnd))
+	if int64(len(prices)) != histEnd-startHeight+1 {
+		return nil, fmt.Errorf("failed to retrieve the ticket prices of blocks %d to %d",
+			startHeight, histEnd)
+	}
+	var projectedPrice float64
+	if endHeight > tip {
+		stakeDiff := c.DataSource.GetStakeDiffEstimates()
+		if stakeDiff == nil {
+			return nil, fmt.Errorf("failed to estimate the ticket price")
+		}
+		projectedPrice = stakeDiff.Estimates.Expected
+	}
+
+	// The vote ages are binned by week, and a ticket votes at the mean age of
+	// the votes in the week it is expected to vote, or the latest week before
+	// it with votes.
+	blocksPerDay := int64(24 * time.Hour / c.Params.TargetTimePerBlock)
+	ageBins, err := c.DataSource.MeanVoteAges(startHeight, tip, 7*blocksPerDay)
+	if err != nil {
+		return nil, fmt.Errorf("MeanVoteAges: %w", err)
+	}
+	expectedAge := int64(c.Params.TicketMaturity) + txhelpers.CalcMeanVotingBlocks(c.Params)
+	voteAge := func(height int64) int64 {
+		target := height + expectedAge
+		i := sort.Search(len(ageBins), func(i int) bool {
+			return ageBins[i].Height > target
+		})
+		if i == 0 {
+			return expectedAge
+		}
+		return int64(math.Round(ageBins[i-1].MeanAge))
+	}
+
+	voteReward := func(height int64) float64 {
+		if height > tip {
+			height = tip
+		}
+		ssv := standalone.SSVOriginal
+		if c.DataSource.IsDCP0012Active(height) {
+			ssv = standalone.SSVDCP0012
+		} else if c.DataSource.IsDCP0010Active(height) {
+			ssv = standalone.SSVDCP0010
+		}
+		_, stake, _ := txhelpers.RewardsAtBlock(height, c.Params.TicketsPerBlock, c.Params, ssv)
+		return dcrutil.Amount(stake).ToCoin()
+	}
+
+	sim := &apitypes.StakeRewardSimulation{
+		StartingBalance: balance,
+		Reinvest:        reinvest,
+		StartHeight:     startHeight,
+		EndHeight:       endHeight,
+		Projected:       endHeight > tip,
+		Steps:           []*apitypes.StakeRewardSimStep{},
+	}
+	sim.StartTime, err = blockTime(startHeight)
+	if err != nil {
+		return nil, err
+	}
+	sim.EndTime, err = blockTime(endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	liquid := balance
+	var livePrice float64
+	for height := startHeight; height <= endHeight; {
+		price := projectedPrice
+		if height <= tip {
+			price = prices[height-startHeight]
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("invalid ticket price %f at block %d", price, height)
+		}
+		budget := liquid
+		if reinvest == reinvestNone {
+			budget = math.Min(liquid, balance)
+		}
+		tickets := budget / price
+		if reinvest == reinvestWhole {
+			tickets = math.Floor(tickets)
+		}
+		if tickets == 0 {
+			break
+		}
+		liquid -= tickets * price
+		buyTime, err := blockTime(height)
+		if err != nil {
+			return nil, err
+		}
+		sim.Steps = append(sim.Steps, &apitypes.StakeRewardSimStep{
+			Height:      height,
+			Time:        buyTime,
+			Action:      "buy",
+			Tickets:     tickets,
+			TicketPrice: price,
+			Balance:     liquid + tickets*price,
+			Projected:   height > tip,
+		})
+
+		voteHeight := height + voteAge(height)
+		if voteHeight > endHeight {
+			sim.LiveTickets, livePrice = tickets, price
+			break
+		}
+		reward := voteReward(voteHeight)
+		liquid += tickets * (price + reward)
+		sim.Rewards += tickets * reward
+		voteTime, err := blockTime(voteHeight)
+		if err != nil {
+			return nil, err
+		}
+		sim.Steps = append(sim.Steps, &apitypes.StakeRewardSimStep{
+			Height:      voteHeight,
+			Time:        voteTime,
+			Action:      "vote",
+			Tickets:     tickets,
+			TicketPrice: price,
+			VoteReward:  reward,
+			Balance:     liquid,
+			Projected:   voteHeight > tip,
+		})
+
+		// The vote outputs are spendable after coinbase maturity.
+		height = voteHeight + int64(c.Params.CoinbaseMaturity) + 1
+	}
+
+	sim.FinalBalance = liquid + sim.LiveTickets*livePrice
+	sim.ReturnPercent = 100 * (sim.FinalBalance - balance) / balance
+	if endHeight > startHeight {
+		sim.ASR = sim.ReturnPercent * float64(365*blocksPerDay) / float64(endHeight-startHeight)
+	}
+	return sim, nil
+}
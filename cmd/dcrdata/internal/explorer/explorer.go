@@ -27,13 +27,16 @@ import (
 
 	"github.com/decred/dcrdata/exchanges/v3"
 	"github.com/decred/dcrdata/gov/v6/agendas"
+	"github.com/decred/dcrdata/gov/v6/politeia"
 	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
 	"github.com/decred/dcrdata/v8/blockdata"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/explorer/types"
 	"github.com/decred/dcrdata/v8/mempool"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
+	"github.com/decred/dcrdata/v8/treasury"
 	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/vsp"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -74,6 +77,9 @@ type explorerDataSource interface {
 	SpendingTransactions(fundingTxID string) ([]string, []uint32, []uint32, error)
 	PoolStatusForTicket(txid string) (dbtypes.TicketSpendType, dbtypes.TicketPoolStatus, error)
 	TreasuryBalance() (*dbtypes.TreasuryBalance, error)
+	GetPool(idx int64) ([]string, error)
+	VSPTicketSpends(feeAddrs []string) ([]*dbtypes.VSPAddressTickets, error)
+	TicketSpendAddresses(tickets, feeAddrs []string) ([]*dbtypes.TicketSpendAddress, error)
 	TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtypes.TreasuryTx, error)
 	AddressHistory(address string, N, offset int64, txnType dbtypes.AddrTxnViewType) ([]*dbtypes.AddressRow, *dbtypes.AddressBalance, error)
 	AddressData(address string, N, offset int64, txnType dbtypes.AddrTxnViewType) (*dbtypes.AddressInfo, error)
@@ -106,6 +112,7 @@ type explorerDataSource interface {
 	SendRawTransaction(txhex string) (string, error)
 	GetTransactionByHash(txid string) (*wire.MsgTx, error)
 	GetHeight() (int64, error)
+	GetBestBlockHash() (string, error)
 	TxHeight(txid *chainhash.Hash) (height int64)
 	DCP0010ActivationHeight() int64
 	DCP0011ActivationHeight() int64
@@ -121,6 +128,8 @@ type PoliteiaBackend interface {
 	ProposalsSync() error
 	ProposalsAll(offset, rowsCount int, filterByVoteStatus ...int) ([]*pitypes.ProposalRecord, int, error)
 	ProposalByToken(token string) (*pitypes.ProposalRecord, error)
+	ProposalTicketVotes(token string) (*pitypes.ProposalTicketVotes, error)
+	ProposalTickets(votes *pitypes.ProposalTicketVotes, chain politeia.TicketSource, vsps politeia.VSPAttributor) (map[string]*pitypes.TicketInfo, error)
 }
 
 // agendaBackend implements methods that manage agendas db data.
@@ -208,6 +217,8 @@ type explorerUI struct {
 	chartSource      ChartDataSource
 	agendasSource    agendaBackend
 	voteTracker      *agendas.VoteTracker
+	tspends          *treasury.TSpendTracker
+	vsps             *vsp.Tracker
 	proposals        PoliteiaBackend
 	dbsSyncing       atomic.Value
 	devPrefetch      bool
@@ -219,6 +230,7 @@ type explorerUI struct {
 	NetName          string
 	MeanVotingBlocks int64
 	xcBot            *exchanges.ExchangeBot
+	prices           *exchanges.PriceHistory
 	xcDone           chan struct{}
 	// displaySyncStatusPage indicates if the sync status page is the only web
 	// page that should be accessible during DB synchronization.
@@ -285,8 +297,11 @@ type ExplorerConfig struct {
 	DevPrefetch   bool
 	Viewsfolder   string
 	XcBot         *exchanges.ExchangeBot
+	PriceHistory  *exchanges.PriceHistory
 	AgendasSource agendaBackend
 	Tracker       *agendas.VoteTracker
+	TSpends       *treasury.TSpendTracker
+	VSPs          *vsp.Tracker
 	Proposals     PoliteiaBackend
 	PoliteiaURL   string
 	MainnetLink   string
@@ -306,9 +321,12 @@ func New(cfg *ExplorerConfig) *explorerUI {
 	exp.Version = cfg.AppVersion
 	exp.devPrefetch = cfg.DevPrefetch
 	exp.xcBot = cfg.XcBot
+	exp.prices = cfg.PriceHistory
 	exp.xcDone = make(chan struct{})
 	exp.agendasSource = cfg.AgendasSource
 	exp.voteTracker = cfg.Tracker
+	exp.tspends = cfg.TSpends
+	exp.vsps = cfg.VSPs
 	exp.proposals = cfg.Proposals
 	exp.politeiaURL = cfg.PoliteiaURL
 	explorerLinks.Mainnet = cfg.MainnetLink
@@ -368,7 +386,7 @@ func New(cfg *ExplorerConfig) *explorerUI {
 		"rawtx", "status", "parameters", "agenda", "agendas", "charts",
 		"sidechains", "disapproved", "ticketpool", "visualblocks",
 		"windows", "timelisting", "addresstable", "proposals", "proposal",
-		"market", "insight_root", "attackcost", "treasury", "treasurytable", "verify_message"}
+		"market", "insight_root", "attackcost", "treasury", "treasurytable", "verify_message", "vsps"}
 
 	for _, name := range tmpls {
 		if err := exp.templates.addTemplate(name); err != nil {
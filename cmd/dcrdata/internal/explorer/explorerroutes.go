@@ -27,7 +27,9 @@ import (
 
 	"github.com/decred/dcrdata/exchanges/v3"
 	"github.com/decred/dcrdata/gov/v6/agendas"
+	"github.com/decred/dcrdata/gov/v6/politeia"
 	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
+	apitypes "github.com/decred/dcrdata/v8/api/types"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/explorer/types"
 	"github.com/decred/dcrdata/v8/txhelpers"
@@ -322,6 +324,54 @@ func (exp *explorerUI) DisapprovedBlocks(w http.ResponseWriter, r *http.Request)
 	io.WriteString(w, str) //nolint:errcheck
 }
 
+// VSPs is the page handler for the "/vsps" path.
+func (exp *explorerUI) VSPs(w http.ResponseWriter, r *http.Request) {
+	var stats []*apitypes.VSPStats
+	if exp.vsps != nil {
+		// The stats are computed once per block.
+		var err error
+		stats, err = exp.vsps.CachedStats(func() ([]string, []*dbtypes.VSPAddressTickets, error) {
+			height, err := exp.dataSource.GetHeight()
+			if err != nil {
+				return nil, nil, err
+			}
+			pool, err := exp.dataSource.GetPool(height)
+			if err != nil {
+				return nil, nil, err
+			}
+			spent, err := exp.dataSource.VSPTicketSpends(exp.vsps.Registry().FeeAddresses())
+			return pool, spent, err
+		})
+		if exp.timeoutErrorPage(w, err, "VSPStats") {
+			return
+		}
+		if err != nil {
+			log.Errorf("Unable to get VSP stats: %v", err)
+			exp.StatusPage(w, defaultErrorCode, "failed to retrieve the VSP stats", "", ExpStatusError)
+			return
+		}
+	}
+
+	str, err := exp.templates.exec("vsps", struct {
+		*CommonPageData
+		Enabled bool
+		Data    []*apitypes.VSPStats
+	}{
+		CommonPageData: exp.commonData(r),
+		Enabled:        exp.vsps != nil,
+		Data:           stats,
+	})
+
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str) //nolint:errcheck
+}
+
 // VisualBlocks is the page handler for the "/visualblocks" path.
 func (exp *explorerUI) VisualBlocks(w http.ResponseWriter, r *http.Request) {
 	// Get top N blocks and trim each block to have just the fields required for
@@ -910,8 +960,6 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 			tx.Type = types.CoinbaseTypeStr
 		}
 
-		// TODO: tx.TSpendTally?
-
 		// Retrieve vouts from DB.
 		vouts, err := exp.dataSource.VoutsForTx(dbTx0)
 		if exp.timeoutErrorPage(w, err, "VoutsForTx") {
@@ -1104,6 +1152,15 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// For treasury spends, get the votes in each voting interval.
+	if tx.IsTreasurySpend() && exp.tspends != nil {
+		txHash, _ := chainhash.NewHashFromStr(hash)
+		tx.TSpendTally, err = exp.tspends.Tally(txHash)
+		if err != nil {
+			log.Errorf("Failed to tally the votes on tspend %s: %v", hash, err)
+		}
+	}
+
 	// Details on all the blocks containing this transaction
 	blocks, blockInds, err := exp.dataSource.TransactionBlocks(tx.TxID)
 	if exp.timeoutErrorPage(w, err, "TransactionBlocks") {
@@ -1318,8 +1375,9 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		HighlightInOutID     int64
 		SwapsFound           string
 		Conversions          struct {
-			Total *exchanges.Conversion
-			Fees  *exchanges.Conversion
+			Total       *exchanges.Conversion
+			Fees        *exchanges.Conversion
+			TotalAtTime *exchanges.Conversion
 		}
 	}{
 		CommonPageData:       exp.commonData(r),
@@ -1337,6 +1395,14 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		pageData.Conversions.Total = exp.xcBot.Conversion(tx.Total)
 		pageData.Conversions.Fees = exp.xcBot.Conversion(tx.Fee.ToCoin())
 	}
+	// Get the fiat value of the total at the block time from the price
+	// history, if there is a price for the time.
+	if tx.BlockHeight > 0 {
+		pageData.Conversions.TotalAtTime, err = exp.prices.ConversionAt(tx.Total, tx.Time.T)
+		if err != nil {
+			log.Warnf("Failed to get the exchange rate at %v: %v", tx.Time.T, err)
+		}
+	}
 
 	str, err := exp.templates.exec("tx", pageData)
 	if err != nil {
@@ -1394,10 +1460,10 @@ type TreasuryInfo struct {
 	Limit, Offset int64  // ?n=Limit&start=Offset
 	TxnType       string // ?txntype=TxnType
 
-	// TODO: tadd and tspend can be unconfirmed. tspend for a very long time.
-	// NumUnconfirmed is the number of unconfirmed txns
-	// NumUnconfirmed  int64
-	// UnconfirmedTxns []*dbtypes.TreasuryTx
+	// TSpendTallies are the vote tallies of the tspends that are voting, or
+	// that were decided recently. The unconfirmed tadds and tspends are in the
+	// mempool inventory.
+	TSpendTallies []*dbtypes.TreasurySpendTally
 
 	// Transactions on the current page
 	Transactions    []*dbtypes.TreasuryTx
@@ -1476,6 +1542,10 @@ func (exp *explorerUI) TreasuryPage(w http.ResponseWriter, r *http.Request) {
 		TypeCount:       typeCount,
 	}
 
+	if exp.tspends != nil {
+		treasuryData.TSpendTallies = exp.tspends.TSpends()
+	}
+
 	xcBot := exp.xcBot
 	if xcBot != nil {
 		treasuryData.ConvertedBalance = xcBot.Conversion(math.Round(float64(treasuryBalance.Balance) / 1e8))
@@ -1485,13 +1555,15 @@ func (exp *explorerUI) TreasuryPage(w http.ResponseWriter, r *http.Request) {
 	linkTemplate := fmt.Sprintf("/treasury?start=%%d&n=%d&txntype=%v", limitN, txType)
 	pageData := struct {
 		*CommonPageData
-		Data        *TreasuryInfo
-		FiatBalance *exchanges.Conversion
-		Pages       []pageNumber
-		Mempool     *types.MempoolInfo
+		Data         *TreasuryInfo
+		CRLFDownload bool
+		FiatBalance  *exchanges.Conversion
+		Pages        []pageNumber
+		Mempool      *types.MempoolInfo
 	}{
 		CommonPageData: exp.commonData(r),
 		Data:           treasuryData,
+		CRLFDownload:   strings.Contains(r.UserAgent(), "Windows"),
 		FiatBalance:    exp.xcBot.Conversion(dcrutil.Amount(treasuryBalance.Balance).ToCoin()),
 		Pages:          calcPages(int(typeCount), int(limitN), int(offset), linkTemplate),
 		Mempool:        exp.MempoolInventory(),
@@ -2267,6 +2339,16 @@ func (exp *explorerUI) AgendasPage(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, str)
 }
 
+// proposalTickets gets the chain data of the tickets eligible to vote on a
+// proposal. See (*politeia.ProposalsDB).ProposalTickets.
+func (exp *explorerUI) proposalTickets(votes *pitypes.ProposalTicketVotes) (map[string]*pitypes.TicketInfo, error) {
+	var vsps politeia.VSPAttributor
+	if exp.vsps != nil {
+		vsps = exp.vsps
+	}
+	return exp.proposals.ProposalTickets(votes, exp.dataSource, vsps)
+}
+
 // ProposalPage is the page handler for the "/proposal" path.
 func (exp *explorerUI) ProposalPage(w http.ResponseWriter, r *http.Request) {
 	if exp.proposals == nil {
@@ -2286,6 +2368,36 @@ func (exp *explorerUI) ProposalPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The turnout of the vote is shown once the ticket votes are synced, with
+	// the vote of the ticket that was looked up, if any.
+	ticket := r.URL.Query().Get("ticket")
+	if ticket != "" {
+		if _, err = chainhash.NewHashFromStr(ticket); err != nil {
+			exp.StatusPage(w, defaultErrorCode, "The ticket hash is invalid.", ticket, ExpStatusBadRequest)
+			return
+		}
+	}
+	var turnout *pitypes.ProposalTurnout
+	var ticketVote *pitypes.ProposalTicketVote
+	votes, err := exp.proposals.ProposalTicketVotes(token)
+	if err == nil {
+		var tickets map[string]*pitypes.TicketInfo
+		tickets, err = exp.proposalTickets(votes)
+		if exp.timeoutErrorPage(w, err, "TicketSpendAddresses") {
+			return
+		}
+		if err != nil {
+			log.Errorf("Unable to get the tickets of proposal %s: %v", token, err)
+		} else {
+			start := int64(prop.StartBlockHeight)
+			blocksPerDay := int64(24 * time.Hour / exp.ChainParams.TargetTimePerBlock)
+			turnout = votes.Turnout(start, tickets, blocksPerDay)
+			if ticket != "" {
+				ticketVote = votes.TicketVote(ticket, start, tickets[ticket])
+			}
+		}
+	}
+
 	commonData := exp.commonData(r)
 	str, err := exp.templates.exec("proposal", struct {
 		*CommonPageData
@@ -2293,12 +2405,16 @@ func (exp *explorerUI) ProposalPage(w http.ResponseWriter, r *http.Request) {
 		PoliteiaURL string
 		ShortToken  string
 		Metadata    *pitypes.ProposalMetadata
+		Turnout     *pitypes.ProposalTurnout
+		TicketVote  *pitypes.ProposalTicketVote
 	}{
 		CommonPageData: commonData,
 		Data:           prop,
 		PoliteiaURL:    exp.politeiaURL,
 		ShortToken:     prop.Token[0:7],
 		Metadata:       prop.Metadata(int64(commonData.Tip.Height), int64(exp.ChainParams.TargetTimePerBlock/time.Second)),
+		Turnout:        turnout,
+		TicketVote:     ticketVote,
 	})
 
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/metrics"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 )
 
@@ -117,6 +118,17 @@ func (wsh *WebsocketHub) NumClients() int {
 
 func (wsh *WebsocketHub) setNumClients(n int) {
 	wsh.numClients.Store(n)
+	metrics.WebsocketClients.WithLabelValues("explorer").Set(float64(n))
+}
+
+// setSendQueueDepth records the number of messages queued for all clients.
+// This should only be called from the run loop.
+func (wsh *WebsocketHub) setSendQueueDepth() {
+	var n int
+	for spoke := range wsh.clients {
+		n += len(*spoke)
+	}
+	metrics.WebsocketSendQueue.WithLabelValues("explorer").Set(float64(n))
 }
 
 // RegisterClient registers a websocket connection with the hub, and returns a
@@ -211,6 +223,8 @@ func (wsh *WebsocketHub) run() {
 	defer wsh.unregisterAllClients()
 
 	for {
+		wsh.setSendQueueDepth()
+
 	events:
 		select {
 		case hubMsg := <-wsh.HubRelay:
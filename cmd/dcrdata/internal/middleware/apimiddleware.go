@@ -60,6 +60,8 @@ const (
 	ctxXcToken
 	ctxStickWidth
 	ctxIndent
+	ctxVSPName
+	ctxTime
 )
 
 type DataSource interface {
@@ -202,6 +204,17 @@ func GetProposalTokenCtx(r *http.Request) string {
 	return tp
 }
 
+// GetTimeCtx retrieves the ctxTime data from the request context. If the value
+// is not set, an empty string is returned.
+func GetTimeCtx(r *http.Request) string {
+	t, ok := r.Context().Value(ctxTime).(string)
+	if !ok {
+		apiLog.Trace("time not set")
+		return ""
+	}
+	return t
+}
+
 // GetRawHexTx retrieves the ctxRawHexTx data from the request context. If not
 // set, the return value is an empty string.
 func GetRawHexTx(r *http.Request) (string, error) {
@@ -478,6 +491,17 @@ func GetChartGroupingCtx(r *http.Request) string {
 	return chartType
 }
 
+// GetVSPNameCtx retrieves the ctxVSPName data from the request context.
+// If not set, the return value is an empty string.
+func GetVSPNameCtx(r *http.Request) string {
+	name, ok := r.Context().Value(ctxVSPName).(string)
+	if !ok {
+		apiLog.Trace("VSP name not set")
+		return ""
+	}
+	return name
+}
+
 // GetCountCtx retrieves the ctxCount data ("to") URL path element from the
 // request context. If not set, the return value is 20.
 func GetCountCtx(r *http.Request) int {
@@ -717,6 +741,16 @@ func ProposalTokenCtx(next http.Handler) http.Handler {
 	})
 }
 
+// TimeCtx returns a http.HandlerFunc that embeds the value at the url part
+// {time} into the request context.
+func TimeCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := chi.URLParam(r, "time")
+		ctx := context.WithValue(r.Context(), ctxTime, t)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // BlockHashPathCtx returns a http.HandlerFunc that embeds the value at the url
 // part {blockhash} into the request context.
 func BlockHashPathCtx(next http.Handler) http.Handler {
@@ -813,6 +847,16 @@ func ChartGroupingCtx(next http.Handler) http.Handler {
 	})
 }
 
+// VSPNameCtx returns a http.HandlerFunc that embeds the value at the url part
+// {vsp} into the request context.
+func VSPNameCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ctxVSPName,
+			chi.URLParam(r, "vsp"))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // apiDocs generates a middleware with a "docs" in the context containing a map
 // of the routers handlers, etc.
 func apiDocs(mux *chi.Mux) func(next http.Handler) http.Handler { //nolint
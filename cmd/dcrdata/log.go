@@ -20,6 +20,7 @@ import (
 	notify "github.com/decred/dcrdata/cmd/dcrdata/internal/notification"
 
 	"github.com/decred/dcrdata/db/dcrpg/v8"
+	"github.com/decred/dcrdata/db/dcrsqlite"
 	"github.com/decred/dcrdata/exchanges/v3"
 	"github.com/decred/dcrdata/gov/v6/agendas"
 	"github.com/decred/dcrdata/gov/v6/politeia"
@@ -29,6 +30,8 @@ import (
 	"github.com/decred/dcrdata/v8/pubsub"
 	"github.com/decred/dcrdata/v8/rpcutils"
 	"github.com/decred/dcrdata/v8/stakedb"
+	"github.com/decred/dcrdata/v8/treasury"
+	"github.com/decred/dcrdata/v8/vsp"
 )
 
 // logWriter implements an io.Writer that outputs to both standard output and
@@ -61,6 +64,7 @@ var (
 
 	notifyLog     = backendLog.Logger("NTFN")
 	postgresqlLog = backendLog.Logger("PSQL")
+	sqliteLog     = backendLog.Logger("SQLT")
 	stakedbLog    = backendLog.Logger("SKDB")
 	BlockdataLog  = backendLog.Logger("BLKD")
 	clientLog     = backendLog.Logger("RPCC")
@@ -73,11 +77,14 @@ var (
 	xcBotLog      = backendLog.Logger("XBOT")
 	agendasLog    = backendLog.Logger("AGDB")
 	proposalsLog  = backendLog.Logger("PRDB")
+	treasuryLog   = backendLog.Logger("TRSY")
+	vspLog        = backendLog.Logger("VSPS")
 )
 
 // Initialize package-global logger variables.
 func init() {
 	dcrpg.UseLogger(postgresqlLog)
+	dcrsqlite.UseLogger(sqliteLog)
 	stakedb.UseLogger(stakedbLog)
 	blockdata.UseLogger(BlockdataLog)
 	rpcclient.UseLogger(clientLog)
@@ -92,12 +99,15 @@ func init() {
 	exchanges.UseLogger(xcBotLog)
 	agendas.UseLogger(agendasLog)
 	politeia.UseLogger(proposalsLog)
+	treasury.UseLogger(treasuryLog)
+	vsp.UseLogger(vspLog)
 }
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
 var subsystemLoggers = map[string]slog.Logger{
 	"NTFN": notifyLog,
 	"PSQL": postgresqlLog,
+	"SQLT": sqliteLog,
 	"SKDB": stakedbLog,
 	"BLKD": BlockdataLog,
 	"RPCC": clientLog,
@@ -110,6 +120,8 @@ var subsystemLoggers = map[string]slog.Logger{
 	"XBOT": xcBotLog,
 	"AGDB": agendasLog,
 	"PRDB": proposalsLog,
+	"TRSY": treasuryLog,
+	"VSPS": vspLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
@@ -6,6 +6,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -25,6 +26,7 @@ import (
 	"github.com/decred/dcrd/rpcclient/v8"
 
 	"github.com/decred/dcrdata/db/dcrpg/v8"
+	"github.com/decred/dcrdata/db/dcrsqlite"
 	"github.com/decred/dcrdata/exchanges/v3"
 	"github.com/decred/dcrdata/gov/v6/agendas"
 	politeia "github.com/decred/dcrdata/gov/v6/politeia"
@@ -33,11 +35,14 @@ import (
 	"github.com/decred/dcrdata/v8/db/cache"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/mempool"
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/pubsub"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 	"github.com/decred/dcrdata/v8/rpcutils"
 	"github.com/decred/dcrdata/v8/semver"
 	"github.com/decred/dcrdata/v8/stakedb"
+	"github.com/decred/dcrdata/v8/treasury"
+	"github.com/decred/dcrdata/v8/vsp"
 
 	"github.com/decred/dcrdata/cmd/dcrdata/internal/api"
 	"github.com/decred/dcrdata/cmd/dcrdata/internal/api/insight"
@@ -177,24 +182,6 @@ func _main(ctx context.Context) error {
 
 	// Main chain DB
 	var newPGIndexes, updateAllAddresses bool
-	pgHost, pgPort := cfg.PGHost, ""
-	if !strings.HasPrefix(pgHost, "/") {
-		pgHost, pgPort, err = net.SplitHostPort(cfg.PGHost)
-		if err != nil {
-			return fmt.Errorf("SplitHostPort failed: %v", err)
-		}
-	}
-	dbi := dcrpg.DBInfo{
-		Host:         pgHost,
-		Port:         pgPort,
-		User:         cfg.PGUser,
-		Pass:         cfg.PGPass,
-		DBName:       cfg.PGDBName,
-		QueryTimeout: cfg.PGQueryTimeout,
-	}
-
-	// If using {netname} then replace it with activeNet.Name.
-	dbi.DBName = strings.Replace(dbi.DBName, "{netname}", activeNet.Name, -1)
 
 	// Rough estimate of capacity in rows, using size of struct plus some
 	// for the string buffer of the Address field.
@@ -202,52 +189,118 @@ func _main(ctx context.Context) error {
 	log.Infof("Address cache capacity: %d addresses: ~%.0f MiB tx data (%d items) + %.0f MiB UTXOs",
 		cfg.AddrCacheLimit, float64(cfg.AddrCacheCap)/1024/1024, rowCap, float64(cfg.AddrCacheUXTOCap)/1024/1024)
 
-	// Open and upgrade the database.
-	dbCfg := dcrpg.ChainDBCfg{
-		DBi:                  &dbi,
-		Params:               activeChain,
-		DevPrefetch:          !cfg.NoDevPrefetch,
-		HidePGConfig:         cfg.HidePGConfig,
-		AddrCacheAddrCap:     cfg.AddrCacheLimit,
-		AddrCacheRowCap:      rowCap,
-		AddrCacheUTXOByteCap: cfg.AddrCacheUXTOCap,
-	}
-
 	mpChecker := rpcutils.NewMempoolAddressChecker(dcrdClient, activeChain)
-	chainDB, err := dcrpg.NewChainDB(ctx, &dbCfg,
-		stakeDB, mpChecker, dcrdClient, requestShutdown)
-	if chainDB != nil {
-		defer chainDB.Close()
-	}
-	if err != nil {
-		return fmt.Errorf("Failed to connect to PostgreSQL: %w", err)
-	}
 
-	if cfg.DropIndexes {
-		log.Info("Dropping all table indexing and quitting...")
-		err = chainDB.DeindexAll()
-		requestShutdown()
-		return err
-	}
+	// The ChainDB is stored in PostgreSQL unless the sqlite backend is used.
+	// The few things that only PostgreSQL supports (index management, slow
+	// query reports, side chain import) are skipped with the sqlite backend.
+	var chainDB *dcrpg.ChainDB
+	usePG := cfg.DBBackend != "sqlite"
+
+	switch cfg.DBBackend {
+	case "sqlite":
+		dbCfg := dcrpg.ChainDBCfg{
+			Dialect:              dcrsqlite.NewDialect(cfg.SQLitePath),
+			QueryTimeout:         cfg.PGQueryTimeout,
+			Params:               activeChain,
+			DevPrefetch:          !cfg.NoDevPrefetch,
+			AddrCacheAddrCap:     cfg.AddrCacheLimit,
+			AddrCacheRowCap:      rowCap,
+			AddrCacheUTXOByteCap: cfg.AddrCacheUXTOCap,
+		}
+		chainDB, err = dcrpg.NewChainDB(ctx, &dbCfg,
+			stakeDB, mpChecker, dcrdClient, requestShutdown)
+		if chainDB != nil {
+			defer chainDB.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to open SQLite database: %w", err)
+		}
 
-	// Check for missing indexes.
-	missingIndexes, descs, err := chainDB.MissingIndexes()
-	if err != nil {
-		return err
-	}
+	default:
+		pgHost, pgPort := cfg.PGHost, ""
+		if !strings.HasPrefix(pgHost, "/") {
+			pgHost, pgPort, err = net.SplitHostPort(cfg.PGHost)
+			if err != nil {
+				return fmt.Errorf("SplitHostPort failed: %v", err)
+			}
+		}
+		dbi := dcrpg.DBInfo{
+			Host:         pgHost,
+			Port:         pgPort,
+			User:         cfg.PGUser,
+			Pass:         cfg.PGPass,
+			DBName:       cfg.PGDBName,
+			QueryTimeout: cfg.PGQueryTimeout,
+		}
 
-	// If any indexes are missing, forcibly drop any existing indexes, and
-	// create them all after block sync.
-	if len(missingIndexes) > 0 {
-		newPGIndexes = true
-		updateAllAddresses = true
-		// Warn if this is not a fresh sync.
-		if chainDB.Height() > 0 {
-			log.Warnf("Some table indexes not found!")
-			for im, mi := range missingIndexes {
-				log.Warnf(` - Missing Index "%s": "%s"`, mi, descs[im])
+		// If using {netname} then replace it with activeNet.Name.
+		dbi.DBName = strings.Replace(dbi.DBName, "{netname}", activeNet.Name, -1)
+
+		// Open and upgrade the database.
+		dbCfg := dcrpg.ChainDBCfg{
+			DBi:                  &dbi,
+			Params:               activeChain,
+			DevPrefetch:          !cfg.NoDevPrefetch,
+			HidePGConfig:         cfg.HidePGConfig,
+			AddrCacheAddrCap:     cfg.AddrCacheLimit,
+			AddrCacheRowCap:      rowCap,
+			AddrCacheUTXOByteCap: cfg.AddrCacheUXTOCap,
+			ReplicaDSNs:          cfg.PGReplicas,
+		}
+		if cfg.PGSlowQuery > 0 {
+			dbCfg.SlowQueries = &dcrpg.SlowQueryCfg{
+				Threshold: cfg.PGSlowQuery,
+				TopN:      cfg.PGSlowQueryTop,
+			}
+		}
+		if cfg.PGPartition {
+			dbCfg.Partitioning = &dcrpg.PartitionCfg{
+				Months:   cfg.PGPartMonths,
+				VoutRows: cfg.PGPartVoutRows,
+			}
+		}
+
+		chainDB, err = dcrpg.NewChainDB(ctx, &dbCfg,
+			stakeDB, mpChecker, dcrdClient, requestShutdown)
+		if chainDB != nil {
+			defer chainDB.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to connect to PostgreSQL: %w", err)
+		}
+
+		// Dump the slow query report to the log on SIGUSR2.
+		if cfg.PGSlowQuery > 0 {
+			go slowQueryReportListener(ctx, chainDB.LogSlowQueryReport)
+		}
+
+		if cfg.DropIndexes {
+			log.Info("Dropping all table indexing and quitting...")
+			err = chainDB.DeindexAll()
+			requestShutdown()
+			return err
+		}
+
+		// Check for missing indexes.
+		missingIndexes, descs, err := chainDB.MissingIndexes()
+		if err != nil {
+			return err
+		}
+
+		// If any indexes are missing, forcibly drop any existing indexes, and
+		// create them all after block sync.
+		if len(missingIndexes) > 0 {
+			newPGIndexes = true
+			updateAllAddresses = true
+			// Warn if this is not a fresh sync.
+			if chainDB.Height() > 0 {
+				log.Warnf("Some table indexes not found!")
+				for im, mi := range missingIndexes {
+					log.Warnf(` - Missing Index "%s": "%s"`, mi, descs[im])
+				}
+				log.Warnf("Forcing new index creation and addresses table spending info update.")
 			}
-			log.Warnf("Forcing new index creation and addresses table spending info update.")
 		}
 	}
 
@@ -299,13 +352,13 @@ func _main(ctx context.Context) error {
 
 	if blocksToPurge > 0 {
 		purgeToBlock := chainDBHeight - blocksToPurge
-		log.Infof("Purging PostgreSQL data for the %d best blocks back to %d...", blocksToPurge, purgeToBlock)
+		log.Infof("Purging DB data for the %d best blocks back to %d...", blocksToPurge, purgeToBlock)
 		s, heightDB, err := chainDB.PurgeBestBlocks(blocksToPurge)
 		if err != nil {
-			return fmt.Errorf("failed to purge %d blocks from PostgreSQL: %w", blocksToPurge, err)
+			return fmt.Errorf("failed to purge %d blocks from the DB: %w", blocksToPurge, err)
 		}
 		if s != nil {
-			log.Infof("Successfully purged data for %d blocks from PostgreSQL "+
+			log.Infof("Successfully purged data for %d blocks from the DB "+
 				"(new height = %d):\n%v", s.Blocks, heightDB, s)
 		} // otherwise likely dbtypes.ErrNoResult (heightDB was already -1)
 	}
@@ -313,7 +366,7 @@ func _main(ctx context.Context) error {
 	// Get the last block added to the DB.
 	lastBlockPG, err := chainDB.HeightDB()
 	if err != nil {
-		return fmt.Errorf("Unable to get height from PostgreSQL DB: %v", err)
+		return fmt.Errorf("Unable to get height from the DB: %v", err)
 	}
 
 	// For consistency with StakeDatabase, a non-negative height is needed.
@@ -411,6 +464,7 @@ func _main(ctx context.Context) error {
 
 	// ExchangeBot
 	var xcBot *exchanges.ExchangeBot
+	var priceHistory *exchanges.PriceHistory
 	if cfg.EnableExchangeBot && activeChain.Name != "mainnet" {
 		log.Warnf("disabling exchange monitoring. only available on mainnet")
 		cfg.EnableExchangeBot = false
@@ -420,6 +474,15 @@ func _main(ctx context.Context) error {
 			Index:          cfg.ExchangeCurrency,
 			MasterBot:      cfg.RateMaster,
 			MasterCertFile: cfg.RateCertificate,
+			SpecFile:       cfg.ExchangeSpecs,
+			Methodology: exchanges.IndexMethodology{
+				Method:         cfg.IndexMethod,
+				Trim:           cfg.IndexTrim,
+				VolumeWeighted: cfg.IndexVolumeWeight,
+				MaxWeight:      cfg.IndexMaxWeight,
+				MaxDeviation:   cfg.IndexMaxDeviation,
+				StaleHalfLife:  cfg.IndexHalfLife,
+			},
 		}
 		if cfg.DisabledExchanges != "" {
 			botCfg.Disabled = strings.Split(cfg.DisabledExchanges, ",")
@@ -434,6 +497,18 @@ func _main(ctx context.Context) error {
 				prepend = ", "
 			}
 			log.Infof("ExchangeBot monitoring %s", xcList)
+			for token, xc := range xcBot.Exchanges {
+				if err = metrics.RegisterExchangeFailures(token, xc.Failures); err != nil {
+					log.Warnf("Unable to register the failure count metric of exchange %s: %v", token, err)
+				}
+			}
+			// Record the index price history before starting the bot so that
+			// no updates are missed.
+			if cfg.ExchangeHistory {
+				priceHistory = exchanges.NewPriceHistory(xcBot, &priceStore{chainDB})
+				wg.Add(1)
+				go priceHistory.Run(ctx, &wg)
+			}
 			wg.Add(1)
 			go xcBot.Start(ctx, &wg)
 		}
@@ -452,8 +527,20 @@ func _main(ctx context.Context) error {
 
 	// Creates a new or loads an existing proposals db instance that stores and
 	// retrieves data from politeia and is used by dcrdata.
-	proposalsDB, err := politeia.NewProposalsDB(cfg.PoliteiaURL,
-		filepath.Join(cfg.DataDir, cfg.ProposalsFileName))
+	// The proposals are imported from an archive of politeia replies if one is
+	// configured.
+	proposalsDBPath := filepath.Join(cfg.DataDir, cfg.ProposalsFileName)
+	var proposalsDB *politeia.ProposalsDB
+	if cfg.PoliteiaArchive != "" {
+		var archive *politeia.Archive
+		archive, err = politeia.NewArchive(cfg.PoliteiaArchive)
+		if err != nil {
+			return err
+		}
+		proposalsDB, err = politeia.NewProposalsDBWithSource(archive, proposalsDBPath)
+	} else {
+		proposalsDB, err = politeia.NewProposalsDB(cfg.PoliteiaURL, proposalsDBPath)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create new proposals db instance: %v", err)
 	}
@@ -470,6 +557,64 @@ func _main(ctx context.Context) error {
 		}
 	}
 
+	// Create the pub sub hub.
+	psHub, err := pubsub.NewPubSubHub(chainDB)
+	if err != nil {
+		return fmt.Errorf("failed to create new pubsubhub: %v", err)
+	}
+	defer psHub.StopWebsocketHub()
+
+	// The tspend tracker tallies the votes on the treasury spends in mempool
+	// through their voting windows, and signals new tallies to the pubsubhub.
+	tspendTracker := treasury.NewTSpendTracker(ctx, dcrdClient, activeChain,
+		[]chan<- pstypes.HubMessage{psHub.HubRelay()})
+	if err = tspendTracker.Refresh(); err != nil {
+		log.Warnf("Failed to tally the votes on the treasury spends in mempool: %v", err)
+	}
+
+	// The VSP tracker attributes the live tickets to the VSPs of the registry.
+	var vspTracker *vsp.Tracker
+	if cfg.VSPRegistry != "" {
+		vspRegistry, err := vsp.LoadRegistry(cfg.VSPRegistry, activeChain)
+		if err != nil {
+			return fmt.Errorf("failed to load the VSP registry: %w", err)
+		}
+		vspTracker = vsp.NewTracker(ctx, dcrdClient, activeChain, vspRegistry)
+		log.Infof("Loaded %d VSPs from %s.", len(vspRegistry.VSPs()), cfg.VSPRegistry)
+	}
+
+	// The exchange alerter evaluates the alert rules on the ExchangeBot's
+	// updates, and signals the triggered rules to the pubsubhub.
+	if xcBot != nil && cfg.ExchangeAlerts != "" {
+		alertRules, err := exchanges.LoadAlertRules(cfg.ExchangeAlerts)
+		if err != nil {
+			return fmt.Errorf("failed to load the exchange alert rules: %w", err)
+		}
+		signalAlert := func(alert *exchanges.Alert) {
+			msg := pstypes.HubMessage{Signal: pstypes.SigPriceAlert, Msg: &pstypes.PriceAlert{
+				Rule:      alert.Rule,
+				Kind:      string(alert.Kind),
+				Token:     alert.Token,
+				Market:    string(alert.Market),
+				Value:     alert.Value,
+				Threshold: alert.Threshold,
+				Message:   alert.Message,
+				Stamp:     alert.Stamp,
+			}}
+			go func() {
+				select {
+				case psHub.HubRelay() <- msg:
+				case <-time.After(time.Second * 10):
+					log.Errorf("sigPriceAlert send failed: Timeout waiting for WebsocketHub.")
+				}
+			}()
+		}
+		alerter := exchanges.NewAlerter(xcBot, alertRules, signalAlert)
+		wg.Add(1)
+		go alerter.Run(ctx, &wg)
+		log.Infof("Loaded %d exchange alert rules from %s.", len(alertRules), cfg.ExchangeAlerts)
+	}
+
 	// Create the explorer system.
 	explore := explorer.New(&explorer.ExplorerConfig{
 		DataSource:    chainDB,
@@ -479,8 +624,11 @@ func _main(ctx context.Context) error {
 		DevPrefetch:   !cfg.NoDevPrefetch,
 		Viewsfolder:   "views",
 		XcBot:         xcBot,
+		PriceHistory:  priceHistory,
 		AgendasSource: agendaDB,
 		Tracker:       tracker,
+		TSpends:       tspendTracker,
+		VSPs:          vspTracker,
 		Proposals:     proposalsDB,
 		PoliteiaURL:   cfg.PoliteiaURL,
 		MainnetLink:   cfg.MainnetLink,
@@ -495,14 +643,10 @@ func _main(ctx context.Context) error {
 	explore.UseSIGToReloadTemplates()
 	defer explore.StopWebsocketHub()
 
-	// Create the pub sub hub.
-	psHub, err := pubsub.NewPubSubHub(chainDB)
-	if err != nil {
-		return fmt.Errorf("failed to create new pubsubhub: %v", err)
+	blockDataSavers = append(blockDataSavers, tspendTracker, psHub)
+	if vspTracker != nil {
+		blockDataSavers = append(blockDataSavers, vspTracker)
 	}
-	defer psHub.StopWebsocketHub()
-
-	blockDataSavers = append(blockDataSavers, psHub)
 	mempoolSavers = append(mempoolSavers, psHub) // individual transactions are from mempool monitor
 
 	// Store explorerUI data after pubsubhub.
@@ -637,8 +781,11 @@ func _main(ctx context.Context) error {
 		Params:            activeChain,
 		DataSource:        chainDB,
 		XcBot:             xcBot,
+		PriceHistory:      priceHistory,
 		AgendasDBInstance: agendaDB,
 		ProposalsDB:       proposalsDB,
+		TSpendTracker:     tspendTracker,
+		VSPTracker:        vspTracker,
 		MaxAddrs:          cfg.MaxCSVAddrs,
 		Charts:            charts,
 	})
@@ -724,6 +871,12 @@ func _main(ctx context.Context) error {
 		webMux.Mount(profPath, http.StripPrefix(profPath, http.DefaultServeMux))
 	}
 
+	// Prometheus metrics
+	if cfg.Metrics {
+		log.Infof("Serving Prometheus metrics on path /metrics.")
+		webMux.Handle("/metrics", metrics.Handler())
+	}
+
 	// SyncStatusAPIIntercept returns a json response if the sync status page is
 	// enabled (no the full explorer while syncing).
 	webMux.With(explore.SyncStatusAPIIntercept).Group(func(r chi.Router) {
@@ -779,6 +932,7 @@ func _main(ctx context.Context) error {
 		r.Get("/search", explore.Search)
 		r.Get("/charts", explore.Charts)
 		r.Get("/ticketpool", explore.Ticketpool)
+		r.Get("/vsps", explore.VSPs)
 		r.Get("/market", explore.MarketPage)
 		r.Get("/stats", func(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/", http.StatusPermanentRedirect)
@@ -801,7 +955,22 @@ func _main(ctx context.Context) error {
 	mountAssetPaths("/insight")
 
 	// Start the web server.
-	listenAndServeProto(ctx, &wg, cfg.APIListen, cfg.APIProto, webMux)
+	listenAndServeProto(ctx, &wg, "the explorer and APIs", cfg.APIListen, cfg.APIProto, webMux)
+
+	// The PostgreSQL slow query report is served on its own listener, which is
+	// restricted to a loopback address, rather than on the public web server.
+	if usePG && cfg.PGSlowQuery > 0 {
+		adminMux := http.NewServeMux()
+		adminMux.HandleFunc("/admin/slowqueries", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(chainDB.SlowQueryReport()); err != nil {
+				log.Warnf("Failed to encode the slow query report: %v", err)
+			}
+		})
+		listenAndServeProto(ctx, &wg, "the slow query report", cfg.PGSlowQueryAddr, "http", adminMux)
+	}
 
 	// Last chance to quit before syncing if the web server could not start.
 	if shutdownRequested(ctx) {
@@ -837,7 +1006,7 @@ func _main(ctx context.Context) error {
 			if !errors.Is(err, context.Canceled) {
 				requestShutdown()
 			}
-			log.Errorf("dcrpg.SyncChainDB failed at height %d.", height)
+			log.Errorf("SyncChainDB failed at height %d.", height)
 			return height, err
 		}
 		app.Status.SetHeight(uint32(height))
@@ -856,7 +1025,7 @@ func _main(ctx context.Context) error {
 
 	// Ensure all side chains known by dcrd are also present in the DB and
 	// import them if they are not already there.
-	if cfg.ImportSideChains {
+	if cfg.ImportSideChains && usePG {
 		// First identify the side chain blocks that are missing from the DB.
 		log.Info("Retrieving side chain blocks from dcrd...")
 		sideChainBlocksToStore, nSideChainBlocks, err := chainDB.MissingSideChainBlocks()
@@ -1027,7 +1196,7 @@ func _main(ctx context.Context) error {
 	// Blockchain monitor for the main DB
 	chainDBChainMonitor := chainDB.NewChainMonitor(ctx)
 	if chainDBChainMonitor == nil {
-		return fmt.Errorf("failed to enable dcrpg ChainMonitor")
+		return fmt.Errorf("failed to enable ChainDB ChainMonitor")
 	}
 
 	// Notifications are sequenced by adding groups of notification handlers.
@@ -1075,6 +1244,20 @@ func _main(ctx context.Context) error {
 	// the sync status page not intercepting requests (see SyncStatusLimit).
 	_ = chainDB.FreshenAddressCaches(true, nil) // async treasury queries, no error
 
+	// Attribute the live tickets that were mined before the VSP tracker was
+	// started, which can take some time.
+	if vspTracker != nil {
+		go func() {
+			pool, err := chainDB.GetPool(chainDBHeight)
+			if err == nil {
+				_, err = vspTracker.LiveTickets(pool)
+			}
+			if err != nil {
+				log.Warnf("Failed to attribute the live tickets to VSPs: %v", err)
+			}
+		}()
+	}
+
 	log.Infof("All ready, at height %d.", chainDBHeight)
 	explore.SetDBsSyncing(false) // let explorer.Store do final updates
 	psHub.SetReady(true)         // make the psHub's WebsocketHub ready to send
@@ -1112,7 +1295,7 @@ func connectNodeRPC(cfg *config, ntfnHandlers *rpcclient.NotificationHandlers) (
 		cfg.DcrdCert, cfg.DisableDaemonTLS, true, ntfnHandlers)
 }
 
-func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, listen, proto string, mux http.Handler) {
+func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, what, listen, proto string, mux http.Handler) {
 	// Try to bind web server
 	server := http.Server{
 		Addr:         listen,
@@ -1140,7 +1323,7 @@ func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, listen, proto
 		wg.Done()
 	}()
 
-	log.Infof("Now serving the explorer and APIs on %s://%v/", proto, listen)
+	log.Infof("Now serving %s on %s://%v/", what, proto, listen)
 	// Start the server.
 	go func() {
 		var err error
@@ -0,0 +1,70 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8"
+	"github.com/decred/dcrdata/exchanges/v3"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// priceStore is the exchanges.PriceStore of a ChainDB's exchange rate history.
+type priceStore struct {
+	db *dcrpg.ChainDB
+}
+
+// Ensure priceStore satisfies exchanges.PriceStore.
+var _ exchanges.PriceStore = (*priceStore)(nil)
+
+// StorePrices stores the points as index prices.
+func (s *priceStore) StorePrices(points []*exchanges.PricePoint) error {
+	prices := make([]*dbtypes.IndexPrice, 0, len(points))
+	for _, p := range points {
+		prices = append(prices, &dbtypes.IndexPrice{
+			Currency:   p.Index,
+			Start:      p.Start,
+			Price:      p.Price,
+			Volume:     p.Volume,
+			Backfilled: p.Backfilled,
+		})
+	}
+	return s.db.StoreIndexPrices(prices)
+}
+
+// PriceAt gets the latest index price of the index for an interval starting in
+// the maxAge before t, up to and including t, or nil if there is none.
+func (s *priceStore) PriceAt(index string, t time.Time, maxAge time.Duration) (*exchanges.PricePoint, error) {
+	p, err := s.db.IndexPriceAt(index, t, maxAge)
+	if errors.Is(err, dbtypes.ErrNoResult) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exchanges.PricePoint{
+		Index:      p.Currency,
+		Start:      p.Start,
+		Price:      p.Price,
+		Volume:     p.Volume,
+		Backfilled: p.Backfilled,
+	}, nil
+}
+
+// MeanPrice gets the mean index price of the index over the intervals starting
+// from start up to but not including end. The boolean is false if there are no
+// prices in the period.
+func (s *priceStore) MeanPrice(index string, start, end time.Time) (float64, bool, error) {
+	price, err := s.db.IndexPriceMean(index, start, end)
+	if errors.Is(err, dbtypes.ErrNoResult) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return price, true, nil
+}
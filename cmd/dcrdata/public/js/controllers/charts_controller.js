@@ -17,7 +17,7 @@ let Dygraph // lazy loaded on connect
 const aDay = 86400 * 1000 // in milliseconds
 const aMonth = 30 // in days
 const atomsToDCR = 1e-8
-const windowScales = ['ticket-price', 'pow-difficulty', 'missed-votes']
+const windowScales = ['ticket-price', 'pow-difficulty', 'missed-votes', 'ticket-selection']
 const hybridScales = ['privacy-participation']
 const lineScales = ['ticket-price', 'privacy-participation']
 const modeScales = ['ticket-price']
@@ -233,8 +233,26 @@ function anonymitySetFunc (data) {
 }
 
 function ticketPriceFunc (data) {
-  if (data.t) return zipWindowTvYZ(data.t, data.price, data.count, atomsToDCR)
-  return zipWindowHvYZ(data.price, data.count, data.window, atomsToDCR)
+  const d = data.t
+    ? zipWindowTvYZ(data.t, data.price, data.count, atomsToDCR)
+    : zipWindowHvYZ(data.price, data.count, data.window, atomsToDCR)
+  if (!data.forecast || !d.length) return d
+  // The forecast bands start at the price of the current window.
+  d.forEach(pt => pt.push(null, null, null))
+  const last = d[d.length - 1]
+  last.splice(3, 3, last[1], last[1], last[1])
+  data.forecast.windows.forEach(w => {
+    const x = data.t ? new Date(w.start_time * 1000) : w.start_height
+    d.push([x, null, null, w.p5, w.p50, w.p95])
+  })
+  return d
+}
+
+// ticketPriceVisibility extends the visibility of the price and tickets bought
+// to the forecast bands, which are shown with the price.
+function ticketPriceVisibility (visibility, hasForecast) {
+  if (!hasForecast) return visibility
+  return visibility.concat([visibility[0], visibility[0], visibility[0]])
 }
 
 function poolSizeFunc (data) {
@@ -323,6 +341,29 @@ function missedVotesFunc (data) {
   return zipWindowHvY(data.missed, data.window, 1, data.offset * data.window)
 }
 
+// selectionStatsData arranges the ticket selection stats windows like the chart
+// API data, with each window at its last block.
+function selectionStatsData (stats, axis) {
+  const windows = stats.windows
+  const data = {
+    axis: axis,
+    wait: windows.map(w => w.mean_wait),
+    expectedWait: windows.map(w => w.expected_mean_wait),
+    pValue: windows.map(w => w.p_value),
+    expiryRate: windows.map(w => w.expiry_rate),
+    expectedExpiryRate: windows.map(w => w.expected_expiry_rate),
+    missRate: windows.map(w => w.miss_rate)
+  }
+  if (axis === 'height') data.h = windows.map(w => w.end_height)
+  else data.t = windows.map(w => w.end_time)
+  return data
+}
+
+function ticketSelectionFunc (data) {
+  const xs = data.t ? data.t.map(t => new Date(t * 1000)) : data.h
+  return xs.map((x, i) => [x, data.wait[i], data.expectedWait[i]])
+}
+
 function mapDygraphOptions (data, labelsVal, isDrawPoint, yLabel, labelsMG, labelsMG2) {
   return merge({
     file: data,
@@ -488,20 +529,38 @@ export default class extends Controller {
     const xlabel = data.t ? 'Date' : 'Block Height'
 
     switch (chartName) {
-      case 'ticket-price': // price graph
+      case 'ticket-price': { // price graph
         d = ticketPriceFunc(data)
-        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Price', 'Tickets Bought'], true,
-          'Price (DCR)', true, false))
+        const labels = [xlabel, 'Price', 'Tickets Bought']
+        if (data.forecast) labels.push('Forecast 5%', 'Forecast Median', 'Forecast 95%')
+        assign(gOptions, mapDygraphOptions(d, labels, true, 'Price (DCR)', true, false))
         gOptions.y2label = 'Tickets Bought'
-        gOptions.series = { 'Tickets Bought': { axis: 'y2' } }
+        const band = { strokePattern: [5, 3], color: '#888', drawPoints: false }
+        gOptions.series = {
+          'Tickets Bought': { axis: 'y2' },
+          'Forecast 5%': band,
+          'Forecast Median': assign({}, band, { strokeWidth: 2 }),
+          'Forecast 95%': band
+        }
         this.visibility = [this.ticketsPriceTarget.checked, this.ticketsPurchaseTarget.checked]
-        gOptions.visibility = this.visibility
+        gOptions.visibility = ticketPriceVisibility(this.visibility, !!data.forecast)
         gOptions.axes.y2 = {
           valueRange: [0, windowSize * 20 * 8],
           axisLabelFormatter: (y) => Math.round(y)
         }
-        yFormatter = customYFormatter(y => y.toFixed(8) + ' DCR')
+        const fmtPrice = y => y.toFixed(8) + ' DCR'
+        yFormatter = (div, legendData) => {
+          const [price, , low, median, high] = legendData.series
+          if (price.y != null) {
+            addLegendEntryFmt(div, price, fmtPrice)
+            return
+          }
+          if (!median || median.y == null) return
+          addLegendEntryFmt(div, median, fmtPrice)
+          div.appendChild(legendEntry(`${legendMarker()} 90% range: ${low.y.toFixed(2)} - ${high.y.toFixed(2)} DCR`))
+        }
         break
+      }
 
       case 'ticket-pool-size': // pool size graph
         d = poolSizeFunc(data)
@@ -637,10 +696,35 @@ export default class extends Controller {
         assign(gOptions, mapDygraphOptions(d, [xlabel, 'Missed Votes'], false,
           'Missed Votes per Window', true, false))
         break
+
+      case 'ticket-selection': {
+        d = ticketSelectionFunc(data)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Mean Vote Wait', 'Expected Mean Wait'], true,
+          'Vote Wait (blocks)', true, false))
+        gOptions.series = {
+          'Expected Mean Wait': {
+            strokePattern: [5, 3],
+            strokeWidth: 2,
+            color: '#888'
+          }
+        }
+        const pct = v => (v * 100).toFixed(2) + '%'
+        yFormatter = (div, legendData, i) => {
+          addLegendEntryFmt(div, legendData.series[0], y => y.toFixed(1) + ' blocks')
+          addLegendEntryFmt(div, legendData.series[1], y => y.toFixed(1) + ' blocks')
+          if (i == null || i >= data.pValue.length) return
+          div.appendChild(legendEntry(`${legendMarker()} Fit p-value: ${data.pValue[i].toFixed(3)}`))
+          div.appendChild(legendEntry(`${legendMarker()} Expired: ${pct(data.expiryRate[i])} (expected ${pct(data.expectedExpiryRate[i])})`))
+          div.appendChild(legendEntry(`${legendMarker()} Missed: ${pct(data.missRate[i])}`))
+        }
+        break
+      }
     }
 
     const baseURL = `${this.query.url.protocol}//${this.query.url.host}`
-    this.rawDataURLTarget.textContent = `${baseURL}/api/chart/${chartName}?axis=${this.settings.axis}&bin=${this.settings.bin}`
+    this.rawDataURLTarget.textContent = chartName === 'ticket-selection'
+      ? `${baseURL}/api/stake/selection/stats`
+      : `${baseURL}/api/chart/${chartName}?axis=${this.settings.axis}&bin=${this.settings.bin}`
 
     this.chartsView.plotter_.clear()
     this.chartsView.updateOptions(gOptions, false)
@@ -697,7 +781,20 @@ export default class extends Controller {
       if (!this.settings.axis) this.settings.axis = 'time' // Set the default.
       url += `&axis=${this.settings.axis}`
       this.setActiveOptionBtn(this.settings.axis, this.axisOptionTargets)
-      const chartResponse = await requestJSON(url)
+      let chartResponse
+      if (selection === 'ticket-selection') {
+        chartResponse = selectionStatsData(await requestJSON('/api/stake/selection/stats'), this.settings.axis)
+      } else if (selection === 'ticket-price') {
+        // The chart is drawn without the forecast if it is unavailable.
+        const [priceData, forecast] = await Promise.all([
+          requestJSON(url),
+          requestJSON('/api/stake/diff/forecast').catch(() => null)
+        ])
+        chartResponse = priceData
+        chartResponse.forecast = forecast
+      } else {
+        chartResponse = await requestJSON(url)
+      }
       console.log('got api data', chartResponse, this, selection)
       selectedChart = selection
       this.plotGraph(selection, chartResponse)
@@ -898,7 +995,9 @@ export default class extends Controller {
       default:
         return
     }
-    this.chartsView.updateOptions({ visibility: this.visibility })
+    const hasForecast = this.chartSelectTarget.value === 'ticket-price' &&
+      this.chartsView.getLabels().length > this.visibility.length + 1
+    this.chartsView.updateOptions({ visibility: ticketPriceVisibility(this.visibility, hasForecast) })
     this.settings.visibility = this.visibility.join('-')
     this.query.replace(this.settings)
   }
@@ -37,6 +37,30 @@ var shutdownSignal = make(chan struct{})
 // Conditional compilation is used to also include SIGTERM on Unix.
 var signals = []os.Signal{os.Interrupt}
 
+// slowQueryReportSignals are the signals that log the slow query report.
+// Conditional compilation is used to include SIGUSR2 on Unix. SIGUSR1 is left
+// to the explorer, which reloads its templates on SIGUSR1.
+var slowQueryReportSignals []os.Signal
+
+// slowQueryReportListener calls logReport whenever one of the
+// slowQueryReportSignals is received, until the context is canceled.
+func slowQueryReportListener(ctx context.Context, logReport func()) {
+	if len(slowQueryReportSignals) == 0 {
+		return
+	}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, slowQueryReportSignals...)
+	defer signal.Stop(sigChan)
+	for {
+		select {
+		case <-sigChan:
+			logReport()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // withShutdownCancel creates a copy of a context that is cancelled whenever
 // shutdown is invoked through an interrupt signal or from an JSON-RPC stop
 // request.
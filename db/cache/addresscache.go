@@ -16,6 +16,7 @@ import (
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/metrics"
 )
 
 const (
@@ -647,48 +648,56 @@ func (cm *cacheMetrics) rowHit() {
 	cm.rowMetrics.Lock()
 	cm.rowMetrics.hits++
 	cm.rowMetrics.Unlock()
+	metrics.CacheLookup("address_rows", true)
 }
 
 func (cm *cacheMetrics) rowMiss() {
 	cm.rowMetrics.Lock()
 	cm.rowMetrics.misses++
 	cm.rowMetrics.Unlock()
+	metrics.CacheLookup("address_rows", false)
 }
 
 func (cm *cacheMetrics) utxoHit() {
 	cm.utxoMetrics.Lock()
 	cm.utxoMetrics.hits++
 	cm.utxoMetrics.Unlock()
+	metrics.CacheLookup("address_utxos", true)
 }
 
 func (cm *cacheMetrics) utxoMiss() {
 	cm.utxoMetrics.Lock()
 	cm.utxoMetrics.misses++
 	cm.utxoMetrics.Unlock()
+	metrics.CacheLookup("address_utxos", false)
 }
 
 func (cm *cacheMetrics) balanceHit() {
 	cm.balanceMetrics.Lock()
 	cm.balanceMetrics.hits++
 	cm.balanceMetrics.Unlock()
+	metrics.CacheLookup("address_balance", true)
 }
 
 func (cm *cacheMetrics) balanceMiss() {
 	cm.balanceMetrics.Lock()
 	cm.balanceMetrics.misses++
 	cm.balanceMetrics.Unlock()
+	metrics.CacheLookup("address_balance", false)
 }
 
 func (cm *cacheMetrics) historyHit() {
 	cm.historyMetrics.Lock()
 	cm.historyMetrics.hits++
 	cm.historyMetrics.Unlock()
+	metrics.CacheLookup("address_history", true)
 }
 
 func (cm *cacheMetrics) historyMiss() {
 	cm.historyMetrics.Lock()
 	cm.historyMetrics.misses++
 	cm.historyMetrics.Unlock()
+	metrics.CacheLookup("address_history", false)
 }
 
 // AddressCache maintains a store of address data. Use NewAddressCache to create
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/semver"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
@@ -36,6 +37,21 @@ const (
 	WindMissedVotes = "missed-votes"
 	PercentStaked   = "stake-participation"
 
+	// Names of the built-in chart series.
+	PoolSizeSeries     = "pool-size"
+	PoolValueSeries    = "pool-value"
+	BlockSizeSeries    = "block-size"
+	TxCountSeries      = "tx-count"
+	NewAtomsSeries     = "new-atoms"
+	ChainworkSeries    = "chainwork"
+	FeesSeries         = "fees"
+	TotalMixedSeries   = "total-mixed"
+	AnonymitySetSeries = "anonymity-set"
+	PowDiffSeries      = "pow-diff"
+	TicketPriceSeries  = "ticket-price"
+	StakeCountSeries   = "stake-count"
+	MissedVotesSeries  = "missed-votes"
+
 	// Some chartResponse keys
 	heightKey       = "h"
 	timeKey         = "t"
@@ -58,39 +74,30 @@ const (
 	rateKey         = "rate"
 )
 
-// binLevel specifies the granularity of data.
-type binLevel string
+// BinLevel specifies the granularity of data.
+type BinLevel string
 
-// axisType is used to manage the type of x-axis data on display on the specified
+// AxisType is used to manage the type of x-axis data on display on the specified
 // chart.
-type axisType string
+type AxisType string
 
-// These are the recognized binLevel and axisType values.
+// These are the recognized BinLevel and AxisType values.
 const (
-	DayBin     binLevel = "day"
-	BlockBin   binLevel = "block"
-	WindowBin  binLevel = "window"
-	HeightAxis axisType = "height"
-	TimeAxis   axisType = "time"
+	DayBin     BinLevel = "day"
+	BlockBin   BinLevel = "block"
+	WindowBin  BinLevel = "window"
+	HeightAxis AxisType = "height"
+	TimeAxis   AxisType = "time"
 )
 
-// Check if the chart is window binned.
-func isWindowBin(chart string) bool {
-	switch chart {
-	case POWDifficulty, TicketPrice, WindMissedVotes:
-		return true
-	}
-	return false
-}
-
 // DefaultBinLevel will be used if a bin level is not specified to
 // (*ChartData).Chart (via empty string), or if the provided BinLevel is
 // invalid.
 var DefaultBinLevel = DayBin
 
 // ParseBin will return the matching bin level, else the default bin.
-func ParseBin(bin string) binLevel {
-	switch binLevel(bin) {
+func ParseBin(bin string) BinLevel {
+	switch BinLevel(bin) {
 	case BlockBin:
 		return BlockBin
 	case WindowBin:
@@ -100,8 +107,8 @@ func ParseBin(bin string) binLevel {
 }
 
 // ParseAxis returns the matching axis type, else the default of time axis.
-func ParseAxis(aType string) axisType {
-	switch axisType(aType) {
+func ParseAxis(aType string) AxisType {
+	switch AxisType(aType) {
 	case HeightAxis:
 		return HeightAxis
 	default:
@@ -145,24 +152,24 @@ const UnknownChartErr = ChartError("unknown chart")
 // ignore the bin flag.
 const InvalidBinErr = ChartError("invalid bin")
 
-// An interface for reading and setting the length of datasets.
-type lengther interface {
+// Lengther is an interface for reading and setting the length of datasets.
+type Lengther interface {
 	Length() int
-	Truncate(int) lengther
+	Truncate(int) Lengther
 }
 
-// ChartFloats is a slice of floats. It satisfies the lengther interface, and
+// ChartFloats is a slice of floats. It satisfies the Lengther interface, and
 // provides methods for taking averages or sums of segments.
 type ChartFloats []float64
 
-// Length returns the length of data. Satisfies the lengther interface.
+// Length returns the length of data. Satisfies the Lengther interface.
 func (data ChartFloats) Length() int {
 	return len(data)
 }
 
-// Truncate makes a subset of the underlying dataset. It satisfies the lengther
+// Truncate makes a subset of the underlying dataset. It satisfies the Lengther
 // interface.
-func (data ChartFloats) Truncate(l int) lengther {
+func (data ChartFloats) Truncate(l int) Lengther {
 	return data[:l]
 }
 
@@ -202,18 +209,18 @@ func newChartFloats(size int) ChartFloats {
 	return make([]float64, 0, size)
 }
 
-// ChartUints is a slice of uints. It satisfies the lengther interface, and
+// ChartUints is a slice of uints. It satisfies the Lengther interface, and
 // provides methods for taking averages or sums of segments.
 type ChartUints []uint64
 
-// Length returns the length of data. Satisfies the lengther interface.
+// Length returns the length of data. Satisfies the Lengther interface.
 func (data ChartUints) Length() int {
 	return len(data)
 }
 
-// Truncate makes a subset of the underlying dataset. It satisfies the lengther
+// Truncate makes a subset of the underlying dataset. It satisfies the Lengther
 // interface.
-func (data ChartUints) Truncate(l int) lengther {
+func (data ChartUints) Truncate(l int) Lengther {
 	return data[:l]
 }
 
@@ -253,24 +260,275 @@ func newChartUints(size int) ChartUints {
 	return make(ChartUints, 0, size)
 }
 
+// SeriesType is the data type of a chart series.
+type SeriesType uint8
+
+// These are the recognized SeriesType values. A UintSeries is stored as
+// ChartUints, and a FloatSeries as ChartFloats.
+const (
+	UintSeries SeriesType = iota
+	FloatSeries
+)
+
+// Aggregation specifies how the block data of a series is binned by day.
+type Aggregation string
+
+// These are the recognized Aggregation values. LastAggregation takes the value
+// at the close of the day, from the first block after midnight.
+const (
+	SumAggregation  Aggregation = "sum"
+	AvgAggregation  Aggregation = "avg"
+	LastAggregation Aggregation = "last"
+)
+
+// ChartSeries declares a dataset of ChartData. A series with Bin BlockBin has a
+// value for every block, and is binned by day with its Aggregation. A series
+// with Bin WindowBin has a value for every stake difficulty window. The
+// optional Updater fetches and appends the series' data, and the optional
+// Charts are the ChartMakers for the charts of the series, keyed by chart ID.
+type ChartSeries struct {
+	Name        string
+	Type        SeriesType
+	Bin         BinLevel
+	Aggregation Aggregation
+	Updater     *ChartUpdater
+	Charts      map[string]ChartMaker
+}
+
+// validate checks that the ChartSeries is well-formed.
+func (series *ChartSeries) validate() error {
+	if series.Name == "" {
+		return fmt.Errorf("chart series has no name")
+	}
+	if series.Type != UintSeries && series.Type != FloatSeries {
+		return fmt.Errorf("chart series %q has unknown type %d", series.Name, series.Type)
+	}
+	switch series.Bin {
+	case BlockBin:
+		switch series.Aggregation {
+		case SumAggregation, AvgAggregation, LastAggregation:
+		default:
+			return fmt.Errorf("chart series %q has unknown aggregation %q", series.Name, series.Aggregation)
+		}
+	case WindowBin:
+	default:
+		return fmt.Errorf("chart series %q has unsupported bin %q", series.Name, series.Bin)
+	}
+	if series.Updater != nil && (series.Updater.Fetcher == nil || series.Updater.Appender == nil) {
+		return fmt.Errorf("chart series %q has an incomplete updater", series.Name)
+	}
+	return nil
+}
+
+// aggregate appends the day value of the series for the interval of blocks to
+// the days set.
+func (series *ChartSeries) aggregate(blocks, days *zoomSet, interval [2]int) {
+	if series.Type == FloatSeries {
+		data := blocks.Floats(series.Name)
+		var v float64
+		switch series.Aggregation {
+		case SumAggregation:
+			v = data.Sum(interval[0], interval[1])
+		case AvgAggregation:
+			v = data.Avg(interval[0], interval[1])
+		case LastAggregation:
+			v = data[interval[1]]
+		}
+		days.AppendFloats(series.Name, v)
+		return
+	}
+	data := blocks.Uints(series.Name)
+	var v uint64
+	switch series.Aggregation {
+	case SumAggregation:
+		v = data.Sum(interval[0], interval[1])
+	case AvgAggregation:
+		v = data.Avg(interval[0], interval[1])
+	case LastAggregation:
+		v = data[interval[1]]
+	}
+	days.AppendUints(series.Name, v)
+}
+
+// builtinSeries are the series registered with every ChartData.
+var builtinSeries = []ChartSeries{
+	{
+		Name:        PoolSizeSeries,
+		Bin:         BlockBin,
+		Aggregation: AvgAggregation,
+		Charts:      map[string]ChartMaker{TicketPoolSize: ticketPoolSizeChart},
+	},
+	{
+		Name:        PoolValueSeries,
+		Bin:         BlockBin,
+		Aggregation: AvgAggregation,
+		Charts:      map[string]ChartMaker{TicketPoolValue: poolValueChart},
+	},
+	{
+		Name:        BlockSizeSeries,
+		Bin:         BlockBin,
+		Aggregation: SumAggregation,
+		Charts: map[string]ChartMaker{
+			BlockSize:      blockSizeChart,
+			BlockChainSize: blockchainSizeChart,
+		},
+	},
+	{
+		Name:        TxCountSeries,
+		Bin:         BlockBin,
+		Aggregation: SumAggregation,
+		Charts:      map[string]ChartMaker{TxCount: txCountChart},
+	},
+	{
+		Name:        NewAtomsSeries,
+		Bin:         BlockBin,
+		Aggregation: SumAggregation,
+		Charts: map[string]ChartMaker{
+			CoinSupply:    coinSupplyChart,
+			PercentStaked: stakedCoinsChart,
+		},
+	},
+	{
+		Name:        ChainworkSeries,
+		Bin:         BlockBin,
+		Aggregation: LastAggregation,
+		Charts: map[string]ChartMaker{
+			ChainWork: chainWorkChart,
+			HashRate:  hashRateChart,
+		},
+	},
+	{
+		Name:        FeesSeries,
+		Bin:         BlockBin,
+		Aggregation: SumAggregation,
+		Charts:      map[string]ChartMaker{Fees: feesChart},
+	},
+	{
+		Name:        TotalMixedSeries,
+		Bin:         BlockBin,
+		Aggregation: SumAggregation,
+		Charts:      map[string]ChartMaker{AnonymitySet: anonymitySetChart},
+	},
+	{
+		Name:        AnonymitySetSeries,
+		Bin:         BlockBin,
+		Aggregation: AvgAggregation,
+	},
+	{
+		Name:   PowDiffSeries,
+		Type:   FloatSeries,
+		Bin:    WindowBin,
+		Charts: map[string]ChartMaker{POWDifficulty: powDifficultyChart},
+	},
+	{
+		Name:   TicketPriceSeries,
+		Bin:    WindowBin,
+		Charts: map[string]ChartMaker{TicketPrice: ticketPriceChart},
+	},
+	{
+		Name: StakeCountSeries,
+		Bin:  WindowBin,
+	},
+	{
+		Name:   MissedVotesSeries,
+		Bin:    WindowBin,
+		Charts: map[string]ChartMaker{WindMissedVotes: missedVotesChart},
+	},
+}
+
+// seriesSet holds the data of the registered series of a zoomSet or windowSet,
+// by name. The size is the capacity allocated for a new series.
+type seriesSet struct {
+	size   int
+	uints  map[string]ChartUints
+	floats map[string]ChartFloats
+}
+
+// Uints is the data of the named uint series, or nil if the set has no data for
+// the series.
+func (set *seriesSet) Uints(name string) ChartUints {
+	return set.uints[name]
+}
+
+// Floats is the data of the named float series, or nil if the set has no data
+// for the series.
+func (set *seriesSet) Floats(name string) ChartFloats {
+	return set.floats[name]
+}
+
+// SetUints replaces the data of the named uint series.
+func (set *seriesSet) SetUints(name string, data ChartUints) {
+	if set.uints == nil {
+		set.uints = make(map[string]ChartUints)
+	}
+	set.uints[name] = data
+}
+
+// SetFloats replaces the data of the named float series.
+func (set *seriesSet) SetFloats(name string, data ChartFloats) {
+	if set.floats == nil {
+		set.floats = make(map[string]ChartFloats)
+	}
+	set.floats[name] = data
+}
+
+// AppendUints appends the values to the named uint series.
+func (set *seriesSet) AppendUints(name string, vs ...uint64) {
+	data, found := set.uints[name]
+	if !found {
+		data = newChartUints(set.size)
+	}
+	set.SetUints(name, append(data, vs...))
+}
+
+// AppendFloats appends the values to the named float series.
+func (set *seriesSet) AppendFloats(name string, vs ...float64) {
+	data, found := set.floats[name]
+	if !found {
+		data = newChartFloats(set.size)
+	}
+	set.SetFloats(name, append(data, vs...))
+}
+
+// series is the data of the series as a Lengther.
+func (set *seriesSet) series(series *ChartSeries) Lengther {
+	if series.Type == FloatSeries {
+		return set.Floats(series.Name)
+	}
+	return set.Uints(series.Name)
+}
+
+// setSeries replaces the data of the series, which must be of the series'
+// type.
+func (set *seriesSet) setSeries(series *ChartSeries, data Lengther) {
+	if series.Type == FloatSeries {
+		set.SetFloats(series.Name, data.(ChartFloats))
+		return
+	}
+	set.SetUints(series.Name, data.(ChartUints))
+}
+
+// snip truncates every series to a provided length.
+func (set *seriesSet) snip(length int) {
+	for name, data := range set.uints {
+		set.uints[name] = data.snip(length)
+	}
+	for name, data := range set.floats {
+		set.floats[name] = data.snip(length)
+	}
+}
+
 // zoomSet is a set of binned data. The smallest bin is block-sized. The zoomSet
 // is managed by explorer, and subsequently the database packages. ChartData
 // provides methods for validating the data and handling concurrency. The
 // cacheID is updated anytime new data is added and validated (see
-// Lengthen), typically once per bin duration.
+// Lengthen), typically once per bin duration. The data of the block-binned
+// series is accessed by name through the embedded seriesSet.
 type zoomSet struct {
-	cacheID      uint64
-	Height       ChartUints
-	Time         ChartUints
-	PoolSize     ChartUints
-	PoolValue    ChartUints
-	BlockSize    ChartUints
-	TxCount      ChartUints
-	NewAtoms     ChartUints
-	Chainwork    ChartUints
-	Fees         ChartUints
-	TotalMixed   ChartUints
-	AnonymitySet ChartUints
+	seriesSet
+	cacheID uint64
+	Height  ChartUints
+	Time    ChartUints
 }
 
 // Snip truncates the zoomSet to a provided length.
@@ -280,52 +538,30 @@ func (set *zoomSet) Snip(length int) {
 	}
 	set.Height = set.Height.snip(length)
 	set.Time = set.Time.snip(length)
-	set.PoolSize = set.PoolSize.snip(length)
-	set.PoolValue = set.PoolValue.snip(length)
-	set.BlockSize = set.BlockSize.snip(length)
-	set.TxCount = set.TxCount.snip(length)
-	set.NewAtoms = set.NewAtoms.snip(length)
-	set.Chainwork = set.Chainwork.snip(length)
-	set.Fees = set.Fees.snip(length)
-	set.TotalMixed = set.TotalMixed.snip(length)
-	set.AnonymitySet = set.AnonymitySet.snip(length)
-}
-
-// Constructor for a sized zoomSet for blocks, which has has no Height slice
-// since the height is implicit for block-binned data.
+	set.seriesSet.snip(length)
+}
+
+// Constructor for a sized zoomSet for blocks.
 func newBlockSet(size int) *zoomSet {
 	return &zoomSet{
-		Height:       newChartUints(size),
-		Time:         newChartUints(size),
-		PoolSize:     newChartUints(size),
-		PoolValue:    newChartUints(size),
-		BlockSize:    newChartUints(size),
-		TxCount:      newChartUints(size),
-		NewAtoms:     newChartUints(size),
-		Chainwork:    newChartUints(size),
-		Fees:         newChartUints(size),
-		TotalMixed:   newChartUints(size),
-		AnonymitySet: newChartUints(size),
+		seriesSet: seriesSet{size: size},
+		Height:    newChartUints(size),
+		Time:      newChartUints(size),
 	}
 }
 
 // Constructor for a sized zoomSet for day-binned data.
 func newDaySet(size int) *zoomSet {
-	set := newBlockSet(size)
-	set.Height = newChartUints(size)
-	return set
+	return newBlockSet(size)
 }
 
 // windowSet is for data that only changes at the difficulty change interval,
-// 144 blocks on mainnet. stakeValid defines the number windows before the
-// stake validation height.
+// 144 blocks on mainnet. The data of the window-binned series is accessed by
+// name through the embedded seriesSet.
 type windowSet struct {
-	cacheID     uint64
-	Time        ChartUints
-	PowDiff     ChartFloats
-	TicketPrice ChartUints
-	StakeCount  ChartUints
-	MissedVotes ChartUints
+	seriesSet
+	cacheID uint64
+	Time    ChartUints
 }
 
 // Snip truncates the windowSet to a provided length.
@@ -335,26 +571,22 @@ func (set *windowSet) Snip(length int) {
 	}
 
 	set.Time = set.Time.snip(length)
-	set.PowDiff = set.PowDiff.snip(length)
-	set.TicketPrice = set.TicketPrice.snip(length)
-	set.StakeCount = set.StakeCount.snip(length)
-	set.MissedVotes = set.MissedVotes.snip(length)
+	set.seriesSet.snip(length)
 }
 
 // Constructor for a sized windowSet.
 func newWindowSet(size int) *windowSet {
 	return &windowSet{
-		Time:        newChartUints(size),
-		PowDiff:     newChartFloats(size),
-		TicketPrice: newChartUints(size),
-		StakeCount:  newChartUints(size),
-		MissedVotes: newChartUints(size),
+		seriesSet: seriesSet{size: size},
+		Time:      newChartUints(size),
 	}
 }
 
 // ChartGobject is the storage object for saving to a gob file. ChartData itself
 // has a lot of extraneous fields, and also embeds sync.RWMutex, so is not
-// suitable for gobbing.
+// suitable for gobbing. The built-in series have their own fields, which
+// predate the series registry, and any other series are stored in Uints or
+// Floats by name.
 type ChartGobject struct {
 	Height       ChartUints
 	Time         ChartUints
@@ -372,6 +604,67 @@ type ChartGobject struct {
 	MissedVotes  ChartUints
 	TotalMixed   ChartUints
 	AnonymitySet ChartUints
+	Uints        map[string]ChartUints
+	Floats       map[string]ChartFloats
+	States       map[string][]byte // the ChartUpdater states, by Tag
+}
+
+// fields maps the names of the built-in series to their ChartGobject fields.
+// The series names are registered once per ChartData, so a field always has
+// the type of its series.
+func (gobject *ChartGobject) fields() map[string]interface{} {
+	return map[string]interface{}{
+		PoolSizeSeries:     &gobject.PoolSize,
+		PoolValueSeries:    &gobject.PoolValue,
+		BlockSizeSeries:    &gobject.BlockSize,
+		TxCountSeries:      &gobject.TxCount,
+		NewAtomsSeries:     &gobject.NewAtoms,
+		ChainworkSeries:    &gobject.Chainwork,
+		FeesSeries:         &gobject.Fees,
+		TotalMixedSeries:   &gobject.TotalMixed,
+		AnonymitySetSeries: &gobject.AnonymitySet,
+		PowDiffSeries:      &gobject.PowDiff,
+		TicketPriceSeries:  &gobject.TicketPrice,
+		StakeCountSeries:   &gobject.StakeCount,
+		MissedVotesSeries:  &gobject.MissedVotes,
+	}
+}
+
+// series gets the data of the series from the ChartGobject.
+func (gobject *ChartGobject) series(series *ChartSeries) Lengther {
+	switch field := gobject.fields()[series.Name].(type) {
+	case *ChartUints:
+		return *field
+	case *ChartFloats:
+		return *field
+	}
+	if series.Type == FloatSeries {
+		return gobject.Floats[series.Name]
+	}
+	return gobject.Uints[series.Name]
+}
+
+// setSeries stores the data of the series in the ChartGobject.
+func (gobject *ChartGobject) setSeries(series *ChartSeries, data Lengther) {
+	switch field := gobject.fields()[series.Name].(type) {
+	case *ChartUints:
+		*field = data.(ChartUints)
+		return
+	case *ChartFloats:
+		*field = data.(ChartFloats)
+		return
+	}
+	if series.Type == FloatSeries {
+		if gobject.Floats == nil {
+			gobject.Floats = make(map[string]ChartFloats)
+		}
+		gobject.Floats[series.Name] = data.(ChartFloats)
+		return
+	}
+	if gobject.Uints == nil {
+		gobject.Uints = make(map[string]ChartUints)
+	}
+	gobject.Uints[series.Name] = data.(ChartUints)
 }
 
 // The chart data is cached with the current cacheID of the zoomSet or windowSet.
@@ -384,7 +677,7 @@ type cachedChart struct {
 type chartResponse map[string]interface{}
 
 // A commonly used seed for chartResponse encoding.
-func binAxisSeed(bin binLevel, axis axisType) chartResponse {
+func binAxisSeed(bin BinLevel, axis AxisType) chartResponse {
 	return chartResponse{
 		binKey:  bin,
 		axisKey: axis,
@@ -392,7 +685,7 @@ func binAxisSeed(bin binLevel, axis axisType) chartResponse {
 }
 
 // A generic structure for JSON encoding keyed data sets
-type lengtherMap map[string]lengther
+type lengtherMap map[string]Lengther
 
 // ChartUpdater is a pair of functions for fetching and appending chart data.
 // The two steps are divided so that ChartData can check whether another thread
@@ -405,13 +698,21 @@ type ChartUpdater struct {
 	Fetcher func(*ChartData) (*sql.Rows, func(), error)
 	// The Appender will be run under mutex lock.
 	Appender func(*ChartData, *sql.Rows) error
+	// The optional SaveState and LoadState keep any state of the updater
+	// besides its series, such as one that is slow to rebuild from the
+	// database, in the cache file by Tag. Both are run under mutex lock, and
+	// LoadState after the series are loaded.
+	SaveState func(*ChartData) ([]byte, error)
+	LoadState func(*ChartData, []byte) error
 }
 
 // ChartData is a set of data used for charts. It provides methods for
 // managing data validation and update concurrency, but does not perform any
 // data retrieval and must be used with care to keep the data valid. The Blocks
 // and Windows fields must be updated by (presumably) a database package. The
-// Days data is auto-generated from the Blocks data during Lengthen-ing.
+// Days data is auto-generated from the Blocks data during Lengthen-ing. The
+// datasets other than Height and Time are the registered series (see
+// RegisterSeries).
 type ChartData struct {
 	mtx          sync.RWMutex
 	ctx          context.Context
@@ -420,14 +721,94 @@ type ChartData struct {
 	Blocks       *zoomSet
 	Windows      *windowSet
 	Days         *zoomSet
+	series       map[string]*ChartSeries
+	makers       map[string]chartMaker
 	cacheMtx     sync.RWMutex
 	cache        map[string]*cachedChart
 	updateMtx    sync.Mutex
 	updaters     []ChartUpdater
 }
 
+// chartMaker is a registered ChartMaker. A window-binned chart ignores the
+// requested bin.
+type chartMaker struct {
+	make         ChartMaker
+	windowBinned bool
+}
+
+// RegisterSeries adds a series to the ChartData, along with its ChartUpdater
+// and ChartMakers. Series should be registered before the data is loaded with
+// Load, since data for unregistered series is not read from the cache file. A
+// series that is not in the cache file is filled in by its Updater.
+// Since the block and window data of all series must be the same length, the
+// Updater of a new series is run after the updaters already added, and must
+// append data up to the height of the Blocks or Windows.
+func (charts *ChartData) RegisterSeries(series ChartSeries) error {
+	if err := series.validate(); err != nil {
+		return err
+	}
+	charts.mtx.Lock()
+	if _, found := charts.series[series.Name]; found {
+		charts.mtx.Unlock()
+		return fmt.Errorf("chart series %q is already registered", series.Name)
+	}
+	for chartID := range series.Charts {
+		if _, found := charts.makers[chartID]; found {
+			charts.mtx.Unlock()
+			return fmt.Errorf("chart %q is already registered", chartID)
+		}
+	}
+	charts.addSeries(&series)
+	charts.mtx.Unlock()
+	if series.Updater != nil {
+		charts.AddUpdater(*series.Updater)
+	}
+	return nil
+}
+
+// addSeries adds the series and its ChartMakers. addSeries should be called
+// under the (*ChartData).mtx lock.
+func (charts *ChartData) addSeries(series *ChartSeries) {
+	charts.series[series.Name] = series
+	for chartID, maker := range series.Charts {
+		charts.makers[chartID] = chartMaker{
+			make:         maker,
+			windowBinned: series.Bin == WindowBin,
+		}
+	}
+}
+
+// RegisterChart adds a ChartMaker for a chart that is not specific to a single
+// series. bin is WindowBin for a chart of window-binned series, which ignores
+// the requested bin, or BlockBin for a chart of block-binned series, which is
+// available in the block and day bins.
+func (charts *ChartData) RegisterChart(chartID string, bin BinLevel, maker ChartMaker) error {
+	if bin != BlockBin && bin != WindowBin {
+		return fmt.Errorf("chart %q has unsupported bin %q", chartID, bin)
+	}
+	charts.mtx.Lock()
+	defer charts.mtx.Unlock()
+	if _, found := charts.makers[chartID]; found {
+		return fmt.Errorf("chart %q is already registered", chartID)
+	}
+	charts.makers[chartID] = chartMaker{
+		make:         maker,
+		windowBinned: bin == WindowBin,
+	}
+	return nil
+}
+
+// seriesData is the Blocks or Windows data of the series, depending on its
+// bin.
+func (charts *ChartData) seriesData(series *ChartSeries) *seriesSet {
+	if series.Bin == WindowBin {
+		return &charts.Windows.seriesSet
+	}
+	return &charts.Blocks.seriesSet
+}
+
 // ValidateLengths checks that the length of all arguments is equal.
-func ValidateLengths(lens ...lengther) (int, error) {
+func ValidateLengths(lens ...Lengther) (int, error) {
 	lenLen := len(lens)
 	if lenLen == 0 {
 		return 0, nil
@@ -459,18 +840,60 @@ func midnight(t uint64) (mid uint64) {
 	return
 }
 
+// seriesBehind checks whether the series is a registered series with its own
+// Updater that has less data than the Blocks or Windows, as is the case for a
+// series that is not in the cache file. The Updater appends the missing data,
+// so the series is left out of the data validation until it catches up.
+// seriesBehind should be called under the (*ChartData).mtx lock.
+func (charts *ChartData) seriesBehind(series *ChartSeries) bool {
+	if series.Updater == nil {
+		return false
+	}
+	dataLen := charts.seriesData(series).series(series).Length()
+	if series.Bin == WindowBin {
+		return dataLen < len(charts.Windows.Time)
+	}
+	return dataLen < len(charts.Blocks.Time)
+}
+
+// dayInterval is the range of Blocks indexes of the day at index i of the
+// Days, ending with the index of the first block of the next day.
+func dayInterval(days *zoomSet, i int) [2]int {
+	var start int
+	if i > 0 {
+		start = int(days.Height[i-1]) + 1
+	}
+	return [2]int{start, int(days.Height[i]) + 1}
+}
+
 // Lengthen performs data validation and populates the Days zoomSet. If there is
-// an update to a zoomSet or windowSet, the cacheID will be incremented.
+// an update to a zoomSet or windowSet, the cacheID will be incremented. A series
+// that is behind (see seriesBehind) is not validated or binned by day, and its
+// day data is filled in once it has caught up with the Blocks.
 func (charts *ChartData) Lengthen() error {
 	charts.mtx.Lock()
 	defer charts.mtx.Unlock()
 
 	// Make sure the database has set an equal number of blocks in each data set.
 	blocks := charts.Blocks
-	shortest, err := ValidateLengths(blocks.Height, blocks.Time,
-		blocks.PoolSize, blocks.PoolValue, blocks.BlockSize, blocks.TxCount,
-		blocks.NewAtoms, blocks.Chainwork, blocks.Fees, blocks.TotalMixed,
-		blocks.AnonymitySet)
+	windows := charts.Windows
+	days := charts.Days
+	blockSets := []Lengther{blocks.Height, blocks.Time}
+	windowSets := []Lengther{windows.Time}
+	behind := make(map[string]bool)
+	for name, series := range charts.series {
+		if charts.seriesBehind(series) {
+			log.Debugf("ChartData.Lengthen: series %s is behind", name)
+			behind[name] = true
+			continue
+		}
+		if series.Bin == WindowBin {
+			windowSets = append(windowSets, windows.series(series))
+		} else {
+			blockSets = append(blockSets, blocks.series(series))
+		}
+	}
+	shortest, err := ValidateLengths(blockSets...)
 	if err != nil {
 		log.Warnf("ChartData.Lengthen: block data length mismatch detected. "+
 			"Truncating blocks length to %d", shortest)
@@ -481,9 +904,7 @@ func (charts *ChartData) Lengthen() error {
 		return nil
 	}
 
-	windows := charts.Windows
-	shortest, err = ValidateLengths(windows.Time, windows.PowDiff,
-		windows.TicketPrice, windows.StakeCount, windows.MissedVotes)
+	shortest, err = ValidateLengths(windowSets...)
 	if err != nil {
 		log.Warnf("ChartData.Lengthen: window data length mismatch detected. "+
 			"Truncating windows length to %d", shortest)
@@ -493,7 +914,18 @@ func (charts *ChartData) Lengthen() error {
 		return fmt.Errorf("unexpected zero-length window data")
 	}
 
-	days := charts.Days
+	// Fill in the day data of any series that has caught up with the Blocks
+	// since the days were last binned.
+	var filledDays bool
+	for name, series := range charts.series {
+		if series.Bin != BlockBin || behind[name] {
+			continue
+		}
+		for i := days.series(series).Length(); i < len(days.Height); i++ {
+			series.aggregate(blocks, days, dayInterval(days, i))
+			filledDays = true
+		}
+	}
 
 	// Get the current first and last midnight stamps.
 	end := midnight(blocks.Time[len(blocks.Time)-1])
@@ -540,25 +972,26 @@ func (charts *ChartData) Lengthen() error {
 		}
 
 		for _, interval := range intervals {
-			// For each new day, take an appropriate snapshot. Some sets use sums,
-			// some use averages, and some use the last value of the day.
+			// For each new day, take a snapshot with the aggregation of each series.
+			// Some sets use sums, some use averages, and some use the last value of
+			// the day.
 			days.Height = append(days.Height, uint64(interval[1]-1))
-			days.PoolSize = append(days.PoolSize, blocks.PoolSize.Avg(interval[0], interval[1]))
-			days.PoolValue = append(days.PoolValue, blocks.PoolValue.Avg(interval[0], interval[1]))
-			days.BlockSize = append(days.BlockSize, blocks.BlockSize.Sum(interval[0], interval[1]))
-			days.TxCount = append(days.TxCount, blocks.TxCount.Sum(interval[0], interval[1]))
-			days.NewAtoms = append(days.NewAtoms, blocks.NewAtoms.Sum(interval[0], interval[1]))
-			days.Chainwork = append(days.Chainwork, blocks.Chainwork[interval[1]])
-			days.Fees = append(days.Fees, blocks.Fees.Sum(interval[0], interval[1]))
-			days.TotalMixed = append(days.TotalMixed, blocks.TotalMixed.Sum(interval[0], interval[1]))
-			days.AnonymitySet = append(days.AnonymitySet, blocks.AnonymitySet.Avg(interval[0], interval[1]))
+			for name, series := range charts.series {
+				if series.Bin == BlockBin && !behind[name] {
+					series.aggregate(blocks, days, interval)
+				}
+			}
 		}
 	}
 
 	// Check that all relevant datasets have been updated to the same length.
-	daysLen, err := ValidateLengths(days.Height, days.Time, days.PoolSize,
-		days.PoolValue, days.BlockSize, days.TxCount, days.NewAtoms,
-		days.Chainwork, days.Fees, days.TotalMixed, days.AnonymitySet)
+	daySets := []Lengther{days.Height, days.Time}
+	for name, series := range charts.series {
+		if series.Bin == BlockBin && !behind[name] {
+			daySets = append(daySets, days.series(series))
+		}
+	}
+	daysLen, err := ValidateLengths(daySets...)
 	if err != nil {
 		return fmt.Errorf("day bin: %v", err)
 	} else if daysLen == 0 {
@@ -569,7 +1002,7 @@ func (charts *ChartData) Lengthen() error {
 	defer charts.cacheMtx.Unlock()
 	// The cacheID for day-binned data, only increment the cacheID when entries
 	// were added.
-	if len(intervals) > 0 {
+	if len(intervals) > 0 || filledDays {
 		days.cacheID++
 	}
 	// For blocks and windows, the cacheID is the last timestamp.
@@ -626,6 +1059,9 @@ func (charts *ChartData) writeCacheFile(filePath string) error {
 	defer file.Close()
 
 	encoder := gob.NewEncoder(file)
+	// The updater states must match the series, so wait for any update.
+	charts.updateMtx.Lock()
+	defer charts.updateMtx.Unlock()
 	charts.mtx.RLock()
 	defer charts.mtx.RUnlock()
 	return encoder.Encode(versionedCacheData{cacheVersion.String(), charts.gobject()})
@@ -658,25 +1094,26 @@ func (charts *ChartData) readCacheFile(filePath string) error {
 
 	gobject := data.Data
 
+	charts.updateMtx.Lock()
 	charts.mtx.Lock()
 	charts.Blocks.Height = gobject.Height
 	charts.Blocks.Time = gobject.Time
-	charts.Blocks.PoolSize = gobject.PoolSize
-	charts.Blocks.PoolValue = gobject.PoolValue
-	charts.Blocks.BlockSize = gobject.BlockSize
-	charts.Blocks.TxCount = gobject.TxCount
-	charts.Blocks.NewAtoms = gobject.NewAtoms
-	charts.Blocks.Chainwork = gobject.Chainwork
-	charts.Blocks.Fees = gobject.Fees
-	charts.Blocks.TotalMixed = gobject.TotalMixed
-	charts.Blocks.AnonymitySet = gobject.AnonymitySet
 	charts.Windows.Time = gobject.WindowTime
-	charts.Windows.PowDiff = gobject.PowDiff
-	charts.Windows.TicketPrice = gobject.TicketPrice
-	charts.Windows.StakeCount = gobject.StakeCount
-	charts.Windows.MissedVotes = gobject.MissedVotes
+	for _, series := range charts.series {
+		charts.seriesData(series).setSeries(series, gobject.series(series))
+	}
+	for _, updater := range charts.updaters {
+		state, found := gobject.States[updater.Tag]
+		if updater.LoadState == nil || !found {
+			continue
+		}
+		if err = updater.LoadState(charts, state); err != nil {
+			log.Warnf("Failed to load the state of the charts %s updater: %v", updater.Tag, err)
+		}
+	}
 
 	charts.mtx.Unlock()
+	charts.updateMtx.Unlock()
 
 	err = charts.Lengthen()
 	if err != nil {
@@ -729,24 +1166,29 @@ func (charts *ChartData) TriggerUpdate(_ string, _ uint32) error {
 }
 
 func (charts *ChartData) gobject() *ChartGobject {
-	return &ChartGobject{
-		Height:       charts.Blocks.Height,
-		Time:         charts.Blocks.Time,
-		PoolSize:     charts.Blocks.PoolSize,
-		PoolValue:    charts.Blocks.PoolValue,
-		BlockSize:    charts.Blocks.BlockSize,
-		TxCount:      charts.Blocks.TxCount,
-		NewAtoms:     charts.Blocks.NewAtoms,
-		Chainwork:    charts.Blocks.Chainwork,
-		Fees:         charts.Blocks.Fees,
-		TotalMixed:   charts.Blocks.TotalMixed,
-		AnonymitySet: charts.Blocks.AnonymitySet,
-		WindowTime:   charts.Windows.Time,
-		PowDiff:      charts.Windows.PowDiff,
-		TicketPrice:  charts.Windows.TicketPrice,
-		StakeCount:   charts.Windows.StakeCount,
-		MissedVotes:  charts.Windows.MissedVotes,
+	gobject := &ChartGobject{
+		Height:     charts.Blocks.Height,
+		Time:       charts.Blocks.Time,
+		WindowTime: charts.Windows.Time,
+	}
+	for _, series := range charts.series {
+		gobject.setSeries(series, charts.seriesData(series).series(series))
+	}
+	for _, updater := range charts.updaters {
+		if updater.SaveState == nil {
+			continue
+		}
+		state, err := updater.SaveState(charts)
+		if err != nil {
+			log.Warnf("Failed to save the state of the charts %s updater: %v", updater.Tag, err)
+			continue
+		}
+		if gobject.States == nil {
+			gobject.States = make(map[string][]byte)
+		}
+		gobject.States[updater.Tag] = state
 	}
+	return gobject
 }
 
 // StateID returns a unique (enough) ID associated with the state of the Blocks
@@ -783,64 +1225,68 @@ func (charts *ChartData) Height() int32 {
 	return int32(len(charts.Blocks.Time)) - 1
 }
 
-// FeesTip is the height of the Fees data.
-func (charts *ChartData) FeesTip() int32 {
+// SeriesTip is the height of the data of the named series. For a window-binned
+// series, it is the height of the last block of the last window. SeriesTip is
+// -1 for an unknown series.
+func (charts *ChartData) SeriesTip(name string) int32 {
 	charts.mtx.RLock()
 	defer charts.mtx.RUnlock()
-	return int32(len(charts.Blocks.Fees)) - 1
+	series, found := charts.series[name]
+	if !found {
+		return -1
+	}
+	dataLen := int32(charts.seriesData(series).series(series).Length())
+	if series.Bin == WindowBin {
+		return dataLen*charts.DiffInterval - 1
+	}
+	return dataLen - 1
+}
+
+// FeesTip is the height of the Fees data.
+func (charts *ChartData) FeesTip() int32 {
+	return charts.SeriesTip(FeesSeries)
 }
 
 // TotalMixedTip is the height of the CoinJoin Total Mixed data
 func (charts *ChartData) TotalMixedTip() int32 {
-	charts.mtx.RLock()
-	defer charts.mtx.RUnlock()
-	return int32(len(charts.Blocks.TotalMixed)) - 1
+	return charts.SeriesTip(TotalMixedSeries)
 }
 
 // AnonymitySetTip is the height of the anonymity set
 func (charts *ChartData) AnonymitySetTip() int32 {
-	charts.mtx.RLock()
-	defer charts.mtx.RUnlock()
-	return int32(len(charts.Blocks.AnonymitySet)) - 1
+	return charts.SeriesTip(AnonymitySetSeries)
 }
 
 // AnonymitySet is the last known anonymity set size.
 func (charts *ChartData) AnonymitySet() uint64 {
 	charts.mtx.RLock()
 	defer charts.mtx.RUnlock()
-	count := len(charts.Blocks.AnonymitySet)
+	anonymitySet := charts.Blocks.Uints(AnonymitySetSeries)
+	count := len(anonymitySet)
 	if count == 0 {
 		return 0
 	}
-	return charts.Blocks.AnonymitySet[count-1]
+	return anonymitySet[count-1]
 }
 
 // NewAtomsTip is the height of the NewAtoms data.
 func (charts *ChartData) NewAtomsTip() int32 {
-	charts.mtx.RLock()
-	defer charts.mtx.RUnlock()
-	return int32(len(charts.Blocks.NewAtoms)) - 1
+	return charts.SeriesTip(NewAtomsSeries)
 }
 
 // TicketPriceTip is the height of the TicketPrice data.
 func (charts *ChartData) TicketPriceTip() int32 {
-	charts.mtx.RLock()
-	defer charts.mtx.RUnlock()
-	return int32(len(charts.Windows.TicketPrice))*charts.DiffInterval - 1
+	return charts.SeriesTip(TicketPriceSeries)
 }
 
 // PoolSizeTip is the height of the PoolSize data.
 func (charts *ChartData) PoolSizeTip() int32 {
-	charts.mtx.RLock()
-	defer charts.mtx.RUnlock()
-	return int32(len(charts.Blocks.PoolSize)) - 1
+	return charts.SeriesTip(PoolSizeSeries)
 }
 
 // MissedVotesTip is the height of the MissedVotes data.
 func (charts *ChartData) MissedVotesTip() int32 {
-	charts.mtx.RLock()
-	defer charts.mtx.RUnlock()
-	return int32(len(charts.Windows.MissedVotes))*charts.DiffInterval - 1
+	return charts.SeriesTip(MissedVotesSeries)
 }
 
 // AddUpdater adds a ChartUpdater to the Updaters slice. Updaters are run
@@ -915,27 +1361,36 @@ func NewChartData(ctx context.Context, height uint32, chainParams *chaincfg.Para
 	days := int(time.Since(genesis)/time.Hour/24)*5/4 + 1 // at least one day
 	windows := int(base64Height/chainParams.StakeDiffWindowSize+1) * 5 / 4
 
-	return &ChartData{
+	charts := &ChartData{
 		ctx:          ctx,
 		DiffInterval: int32(chainParams.StakeDiffWindowSize),
 		StartPOS:     int32(chainParams.StakeValidationHeight),
 		Blocks:       newBlockSet(size),
 		Windows:      newWindowSet(windows),
 		Days:         newDaySet(days),
+		series:       make(map[string]*ChartSeries, len(builtinSeries)),
+		makers:       make(map[string]chartMaker),
 		cache:        make(map[string]*cachedChart),
 		updaters:     make([]ChartUpdater, 0),
 	}
+	for i := range builtinSeries {
+		series := builtinSeries[i]
+		charts.addSeries(&series)
+	}
+	// The block time chart is made from the Time alone.
+	charts.makers[DurationBTW] = chartMaker{make: durationBTWChart}
+	return charts
 }
 
 // A cacheKey is used to specify cached data of a given type and BinLevel.
-func cacheKey(chartID string, bin binLevel, axis axisType) string {
+func cacheKey(chartID string, bin BinLevel, axis AxisType) string {
 	// The axis type is only required when bin level is set to DayBin.
 	return chartID + "-" + string(bin) + "-" + string(axis)
 }
 
 // Grabs the cacheID associated with the provided BinLevel. Should
 // be called under at least a (ChartData).cacheMtx.RLock.
-func (charts *ChartData) cacheID(bin binLevel) uint64 {
+func (charts *ChartData) cacheID(bin BinLevel) uint64 {
 	switch bin {
 	case BlockBin:
 		return charts.Blocks.cacheID
@@ -948,7 +1403,7 @@ func (charts *ChartData) cacheID(bin binLevel) uint64 {
 }
 
 // Grab the cached data, if it exists. The cacheID is returned as a convenience.
-func (charts *ChartData) getCache(chartID string, bin binLevel, axis axisType) (data *cachedChart, found bool, cacheID uint64) {
+func (charts *ChartData) getCache(chartID string, bin BinLevel, axis AxisType) (data *cachedChart, found bool, cacheID uint64) {
 	// Ignore zero length since bestHeight would just be set to zero anyway.
 	ck := cacheKey(chartID, bin, axis)
 	charts.cacheMtx.RLock()
@@ -959,7 +1414,7 @@ func (charts *ChartData) getCache(chartID string, bin binLevel, axis axisType) (
 }
 
 // Store the chart associated with the provided type and BinLevel.
-func (charts *ChartData) cacheChart(chartID string, bin binLevel, axis axisType, data []byte) {
+func (charts *ChartData) cacheChart(chartID string, bin BinLevel, axis AxisType, data []byte) {
 	ck := cacheKey(chartID, bin, axis)
 	charts.cacheMtx.Lock()
 	defer charts.cacheMtx.Unlock()
@@ -974,47 +1429,33 @@ func (charts *ChartData) cacheChart(chartID string, bin binLevel, axis axisType,
 
 // ChartMaker is a function that accepts a chart type and BinLevel, and returns
 // a JSON-encoded chartResponse.
-type ChartMaker func(charts *ChartData, bin binLevel, axis axisType) ([]byte, error)
-
-var chartMakers = map[string]ChartMaker{
-	BlockSize:       blockSizeChart,
-	BlockChainSize:  blockchainSizeChart,
-	ChainWork:       chainWorkChart,
-	CoinSupply:      coinSupplyChart,
-	DurationBTW:     durationBTWChart,
-	HashRate:        hashRateChart,
-	POWDifficulty:   powDifficultyChart,
-	TicketPrice:     ticketPriceChart,
-	TxCount:         txCountChart,
-	Fees:            feesChart,
-	AnonymitySet:    anonymitySetChart,
-	TicketPoolSize:  ticketPoolSizeChart,
-	TicketPoolValue: poolValueChart,
-	WindMissedVotes: missedVotesChart,
-	PercentStaked:   stakedCoinsChart,
-}
+type ChartMaker func(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error)
 
 // Chart will return a JSON-encoded chartResponse of the provided chart,
-// binLevel, and axis (TimeAxis, HeightAxis). binString is ignored for
+// BinLevel, and axis (TimeAxis, HeightAxis). binString is ignored for
 // window-binned charts.
 func (charts *ChartData) Chart(chartID, binString, axisString string) ([]byte, error) {
-	if isWindowBin(chartID) {
+	charts.mtx.RLock()
+	maker, hasMaker := charts.makers[chartID]
+	charts.mtx.RUnlock()
+	if !hasMaker {
+		return nil, UnknownChartErr
+	}
+	if maker.windowBinned {
 		binString = string(WindowBin)
 	}
 	bin := ParseBin(binString)
 	axis := ParseAxis(axisString)
 	cache, found, cacheID := charts.getCache(chartID, bin, axis)
-	if found && cache.cacheID == cacheID {
+	hit := found && cache.cacheID == cacheID
+	metrics.CacheLookup("charts", hit)
+	if hit {
 		return cache.data, nil
 	}
-	maker, hasMaker := chartMakers[chartID]
-	if !hasMaker {
-		return nil, UnknownChartErr
-	}
 	// Do the locking here, rather than in encode, so that the helper functions
 	// (accumulate, btw) are run under lock.
 	charts.mtx.RLock()
-	data, err := maker(charts, bin, axis)
+	data, err := maker.make(charts, bin, axis)
 	charts.mtx.RUnlock()
 	if err != nil {
 		return nil, err
@@ -1047,6 +1488,14 @@ func encode(sets lengtherMap, seed chartResponse) ([]byte, error) {
 	return json.Marshal(seed)
 }
 
+// EncodeChart encodes the data sets of a chart, keyed by their names in the
+// JSON, along with the bin and axis of the chart. The data sets are truncated
+// to the length of the shortest. EncodeChart is for the ChartMakers of series
+// registered outside of this package.
+func EncodeChart(sets map[string]Lengther, bin BinLevel, axis AxisType) ([]byte, error) {
+	return encode(sets, binAxisSeed(bin, axis))
+}
+
 // Each point is translated to the sum of all points before and itself.
 func accumulate(data ChartUints) ChartUints {
 	d := make(ChartUints, 0, len(data))
@@ -1109,19 +1558,19 @@ func avgBlockTimes(ticks, blocks ChartUints) (ChartUints, ChartUints) {
 	return times, avgDiffs
 }
 
-func blockSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func blockSizeChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				sizeKey: charts.Blocks.BlockSize,
+				sizeKey: charts.Blocks.Uints(BlockSizeSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Blocks.Time,
-				sizeKey: charts.Blocks.BlockSize,
+				sizeKey: charts.Blocks.Uints(BlockSizeSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1129,31 +1578,31 @@ func blockSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, err
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				sizeKey:   charts.Days.BlockSize,
+				sizeKey:   charts.Days.Uints(BlockSizeSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
-				sizeKey: charts.Days.BlockSize,
+				sizeKey: charts.Days.Uints(BlockSizeSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func blockchainSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func blockchainSizeChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				sizeKey: accumulate(charts.Blocks.BlockSize),
+				sizeKey: accumulate(charts.Blocks.Uints(BlockSizeSeries)),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Blocks.Time,
-				sizeKey: accumulate(charts.Blocks.BlockSize),
+				sizeKey: accumulate(charts.Blocks.Uints(BlockSizeSeries)),
 			}, seed)
 		}
 	case DayBin:
@@ -1161,31 +1610,31 @@ func blockchainSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				sizeKey:   accumulate(charts.Days.BlockSize),
+				sizeKey:   accumulate(charts.Days.Uints(BlockSizeSeries)),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
-				sizeKey: accumulate(charts.Days.BlockSize),
+				sizeKey: accumulate(charts.Days.Uints(BlockSizeSeries)),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func chainWorkChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func chainWorkChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				workKey: charts.Blocks.Chainwork,
+				workKey: charts.Blocks.Uints(ChainworkSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Blocks.Time,
-				workKey: charts.Blocks.Chainwork,
+				workKey: charts.Blocks.Uints(ChainworkSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1193,33 +1642,33 @@ func chainWorkChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, err
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				workKey:   charts.Days.Chainwork,
+				workKey:   charts.Days.Uints(ChainworkSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
-				workKey: charts.Days.Chainwork,
+				workKey: charts.Days.Uints(ChainworkSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func coinSupplyChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func coinSupplyChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				supplyKey:       accumulate(charts.Blocks.NewAtoms),
-				anonymitySetKey: charts.Blocks.AnonymitySet,
+				supplyKey:       accumulate(charts.Blocks.Uints(NewAtomsSeries)),
+				anonymitySetKey: charts.Blocks.Uints(AnonymitySetSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:         charts.Blocks.Time,
-				supplyKey:       accumulate(charts.Blocks.NewAtoms),
-				anonymitySetKey: charts.Blocks.AnonymitySet,
+				supplyKey:       accumulate(charts.Blocks.Uints(NewAtomsSeries)),
+				anonymitySetKey: charts.Blocks.Uints(AnonymitySetSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1227,14 +1676,14 @@ func coinSupplyChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, er
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey:       charts.Days.Height,
-				supplyKey:       accumulate(charts.Days.NewAtoms),
-				anonymitySetKey: charts.Days.AnonymitySet,
+				supplyKey:       accumulate(charts.Days.Uints(NewAtomsSeries)),
+				anonymitySetKey: charts.Days.Uints(AnonymitySetSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:         charts.Days.Time,
-				supplyKey:       accumulate(charts.Days.NewAtoms),
-				anonymitySetKey: charts.Days.AnonymitySet,
+				supplyKey:       accumulate(charts.Days.Uints(NewAtomsSeries)),
+				anonymitySetKey: charts.Days.Uints(AnonymitySetSeries),
 				heightKey:       charts.Days.Height,
 			}, seed)
 		}
@@ -1242,7 +1691,7 @@ func coinSupplyChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, er
 	return nil, InvalidBinErr
 }
 
-func durationBTWChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func durationBTWChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1336,7 +1785,7 @@ func dailyHashrate(time, chainwork ChartUints) (ChartUints, ChartUints) {
 	return times, rates
 }
 
-func hashRateChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func hashRateChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1344,7 +1793,7 @@ func hashRateChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, erro
 			return nil, fmt.Errorf("Not enough blocks to calculate hashrate")
 		}
 		seed[offsetKey] = HashrateAvgLength
-		times, rates := hashrate(charts.Blocks.Time, charts.Blocks.Chainwork)
+		times, rates := hashrate(charts.Blocks.Time, charts.Blocks.Uints(ChainworkSeries))
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
@@ -1361,7 +1810,7 @@ func hashRateChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, erro
 			return nil, fmt.Errorf("Not enough days to calculate hashrate")
 		}
 		seed[offsetKey] = 1
-		times, rates := dailyHashrate(charts.Days.Time, charts.Days.Chainwork)
+		times, rates := dailyHashrate(charts.Days.Time, charts.Days.Uints(ChainworkSeries))
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
@@ -1378,53 +1827,53 @@ func hashRateChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, erro
 	return nil, InvalidBinErr
 }
 
-func powDifficultyChart(charts *ChartData, _ binLevel, axis axisType) ([]byte, error) {
+func powDifficultyChart(charts *ChartData, _ BinLevel, axis AxisType) ([]byte, error) {
 	// Pow Difficulty only has window level bin, so all others are ignored.
 	seed := chartResponse{windowKey: charts.DiffInterval}
 	switch axis {
 	case HeightAxis:
 		return encode(lengtherMap{
-			diffKey: charts.Windows.PowDiff,
+			diffKey: charts.Windows.Floats(PowDiffSeries),
 		}, seed)
 	default:
 		return encode(lengtherMap{
-			diffKey: charts.Windows.PowDiff,
+			diffKey: charts.Windows.Floats(PowDiffSeries),
 			timeKey: charts.Windows.Time,
 		}, seed)
 	}
 }
 
-func ticketPriceChart(charts *ChartData, _ binLevel, axis axisType) ([]byte, error) {
+func ticketPriceChart(charts *ChartData, _ BinLevel, axis AxisType) ([]byte, error) {
 	// Ticket price only has window level bin, so all others are ignored.
 	seed := chartResponse{windowKey: charts.DiffInterval}
 	switch axis {
 	case HeightAxis:
 		return encode(lengtherMap{
-			priceKey: charts.Windows.TicketPrice,
-			countKey: charts.Windows.StakeCount,
+			priceKey: charts.Windows.Uints(TicketPriceSeries),
+			countKey: charts.Windows.Uints(StakeCountSeries),
 		}, seed)
 	default:
 		return encode(lengtherMap{
 			timeKey:  charts.Windows.Time,
-			priceKey: charts.Windows.TicketPrice,
-			countKey: charts.Windows.StakeCount,
+			priceKey: charts.Windows.Uints(TicketPriceSeries),
+			countKey: charts.Windows.Uints(StakeCountSeries),
 		}, seed)
 	}
 }
 
-func txCountChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func txCountChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				countKey: charts.Blocks.TxCount,
+				countKey: charts.Blocks.Uints(TxCountSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:  charts.Blocks.Time,
-				countKey: charts.Blocks.TxCount,
+				countKey: charts.Blocks.Uints(TxCountSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1432,31 +1881,31 @@ func txCountChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				countKey:  charts.Days.TxCount,
+				countKey:  charts.Days.Uints(TxCountSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:  charts.Days.Time,
-				countKey: charts.Days.TxCount,
+				countKey: charts.Days.Uints(TxCountSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func feesChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func feesChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				feesKey: charts.Blocks.Fees,
+				feesKey: charts.Blocks.Uints(FeesSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Blocks.Time,
-				feesKey: charts.Blocks.Fees,
+				feesKey: charts.Blocks.Uints(FeesSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1464,19 +1913,19 @@ func feesChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				feesKey:   charts.Days.Fees,
+				feesKey:   charts.Days.Uints(FeesSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
-				feesKey: charts.Days.Fees,
+				feesKey: charts.Days.Uints(FeesSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func anonymitySetChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func anonymitySetChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1484,12 +1933,12 @@ func anonymitySetChart(charts *ChartData, bin binLevel, axis axisType) ([]byte,
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey:       charts.Blocks.Height,
-				anonymitySetKey: charts.Blocks.TotalMixed,
+				anonymitySetKey: charts.Blocks.Uints(TotalMixedSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:         charts.Blocks.Time,
-				anonymitySetKey: charts.Blocks.TotalMixed,
+				anonymitySetKey: charts.Blocks.Uints(TotalMixedSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1497,31 +1946,31 @@ func anonymitySetChart(charts *ChartData, bin binLevel, axis axisType) ([]byte,
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey:       charts.Days.Height,
-				anonymitySetKey: charts.Days.TotalMixed,
+				anonymitySetKey: charts.Days.Uints(TotalMixedSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:         charts.Days.Time,
-				anonymitySetKey: charts.Days.TotalMixed,
+				anonymitySetKey: charts.Days.Uints(TotalMixedSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func ticketPoolSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func ticketPoolSizeChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				countKey: charts.Blocks.PoolSize,
+				countKey: charts.Blocks.Uints(PoolSizeSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:  charts.Blocks.Time,
-				countKey: charts.Blocks.PoolSize,
+				countKey: charts.Blocks.Uints(PoolSizeSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1529,31 +1978,31 @@ func ticketPoolSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				countKey:  charts.Days.PoolSize,
+				countKey:  charts.Days.Uints(PoolSizeSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:  charts.Days.Time,
-				countKey: charts.Days.PoolSize,
+				countKey: charts.Days.Uints(PoolSizeSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func poolValueChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func poolValueChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				poolValKey: charts.Blocks.PoolValue,
+				poolValKey: charts.Blocks.Uints(PoolValueSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:    charts.Blocks.Time,
-				poolValKey: charts.Blocks.PoolValue,
+				poolValKey: charts.Blocks.Uints(PoolValueSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1561,21 +2010,21 @@ func poolValueChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, err
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey:  charts.Days.Height,
-				poolValKey: charts.Days.PoolValue,
+				poolValKey: charts.Days.Uints(PoolValueSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:    charts.Days.Time,
-				poolValKey: charts.Days.PoolValue,
+				poolValKey: charts.Days.Uints(PoolValueSeries),
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
-func missedVotesChart(charts *ChartData, _ binLevel, axis axisType) ([]byte, error) {
+func missedVotesChart(charts *ChartData, _ BinLevel, axis AxisType) ([]byte, error) {
 	prestakeWindows := int(charts.StartPOS / charts.DiffInterval)
-	if prestakeWindows >= len(charts.Windows.MissedVotes) ||
+	if prestakeWindows >= len(charts.Windows.Uints(MissedVotesSeries)) ||
 		prestakeWindows >= len(charts.Windows.Time) {
 		prestakeWindows = 0
 	}
@@ -1586,31 +2035,31 @@ func missedVotesChart(charts *ChartData, _ binLevel, axis axisType) ([]byte, err
 	switch axis {
 	case HeightAxis:
 		return encode(lengtherMap{
-			missedKey: charts.Windows.MissedVotes[prestakeWindows:],
+			missedKey: charts.Windows.Uints(MissedVotesSeries)[prestakeWindows:],
 		}, seed)
 	default:
 		return encode(lengtherMap{
 			timeKey:   charts.Windows.Time[prestakeWindows:],
-			missedKey: charts.Windows.MissedVotes[prestakeWindows:],
+			missedKey: charts.Windows.Uints(MissedVotesSeries)[prestakeWindows:],
 		}, seed)
 	}
 }
 
-func stakedCoinsChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+func stakedCoinsChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				circulationKey: accumulate(charts.Blocks.NewAtoms),
-				poolValKey:     charts.Blocks.PoolValue,
+				circulationKey: accumulate(charts.Blocks.Uints(NewAtomsSeries)),
+				poolValKey:     charts.Blocks.Uints(PoolValueSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:        charts.Blocks.Time,
-				circulationKey: accumulate(charts.Blocks.NewAtoms),
-				poolValKey:     charts.Blocks.PoolValue,
+				circulationKey: accumulate(charts.Blocks.Uints(NewAtomsSeries)),
+				poolValKey:     charts.Blocks.Uints(PoolValueSeries),
 			}, seed)
 		}
 	case DayBin:
@@ -1618,14 +2067,14 @@ func stakedCoinsChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, e
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey:      charts.Days.Height,
-				circulationKey: accumulate(charts.Days.NewAtoms),
-				poolValKey:     charts.Days.PoolValue,
+				circulationKey: accumulate(charts.Days.Uints(NewAtomsSeries)),
+				poolValKey:     charts.Days.Uints(PoolValueSeries),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:        charts.Days.Time,
-				circulationKey: accumulate(charts.Days.NewAtoms),
-				poolValKey:     charts.Days.PoolValue,
+				circulationKey: accumulate(charts.Days.Uints(NewAtomsSeries)),
+				poolValKey:     charts.Days.Uints(PoolValueSeries),
 			}, seed)
 		}
 	}
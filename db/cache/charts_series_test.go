@@ -0,0 +1,199 @@
+package cache_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrdata/v8/db/cache"
+)
+
+const (
+	aDay                = 86400
+	seriesName, chartID = "test-series", "test-chart"
+)
+
+// seedTimes are the block times of the seeded charts data, which spans four
+// days.
+var seedTimes = cache.ChartUints{1, 2 + aDay, 3 + aDay, 4 + 2*aDay, 5 + 2*aDay, 6 + 3*aDay}
+
+// builtinBlockSeries are the names of the built-in block-binned series.
+var builtinBlockSeries = []string{cache.PoolSizeSeries, cache.PoolValueSeries,
+	cache.BlockSizeSeries, cache.TxCountSeries, cache.NewAtomsSeries,
+	cache.ChainworkSeries, cache.FeesSeries, cache.TotalMixedSeries,
+	cache.AnonymitySetSeries}
+
+// testChart is the ChartMaker of the test series.
+func testChart(charts *cache.ChartData, bin cache.BinLevel, axis cache.AxisType) ([]byte, error) {
+	if bin == cache.DayBin {
+		return cache.EncodeChart(map[string]cache.Lengther{
+			"t":     charts.Days.Time,
+			"count": charts.Days.Uints(seriesName),
+		}, bin, axis)
+	}
+	return cache.EncodeChart(map[string]cache.Lengther{
+		"t":     charts.Blocks.Time,
+		"count": charts.Blocks.Uints(seriesName),
+	}, bin, axis)
+}
+
+// testSeries is a block-binned series with a value of 10 times the height of
+// each block. The LoadState of the updater records the state in loadedState.
+func testSeries(loadedState *string) cache.ChartSeries {
+	return cache.ChartSeries{
+		Name:        seriesName,
+		Bin:         cache.BlockBin,
+		Aggregation: cache.SumAggregation,
+		Updater: &cache.ChartUpdater{
+			Tag: "test",
+			Fetcher: func(*cache.ChartData) (*sql.Rows, func(), error) {
+				return nil, func() {}, nil
+			},
+			Appender: func(charts *cache.ChartData, _ *sql.Rows) error {
+				for h := len(charts.Blocks.Uints(seriesName)); h < len(charts.Blocks.Time); h++ {
+					charts.Blocks.AppendUints(seriesName, 10*uint64(h))
+				}
+				return nil
+			},
+			SaveState: func(charts *cache.ChartData) ([]byte, error) {
+				return []byte(fmt.Sprintf("tip %d", len(charts.Blocks.Uints(seriesName))-1)), nil
+			},
+			LoadState: func(_ *cache.ChartData, state []byte) error {
+				*loadedState = string(state)
+				return nil
+			},
+		},
+		Charts: map[string]cache.ChartMaker{chartID: testChart},
+	}
+}
+
+// seedCharts sets the data of the built-in series.
+func seedCharts(charts *cache.ChartData) {
+	for i, stamp := range seedTimes {
+		charts.Blocks.Height = append(charts.Blocks.Height, uint64(i))
+		charts.Blocks.Time = append(charts.Blocks.Time, stamp)
+		for _, name := range builtinBlockSeries {
+			charts.Blocks.AppendUints(name, uint64(i))
+		}
+	}
+	charts.Windows.Time = cache.ChartUints{0}
+	charts.Windows.SetFloats(cache.PowDiffSeries, cache.ChartFloats{0})
+	for _, name := range []string{cache.TicketPriceSeries, cache.StakeCountSeries, cache.MissedVotesSeries} {
+		charts.Windows.SetUints(name, cache.ChartUints{0})
+	}
+}
+
+// checkTestSeries checks the data and chart of the test series.
+func checkTestSeries(t *testing.T, charts *cache.ChartData) {
+	t.Helper()
+	if !reflect.DeepEqual(charts.Blocks.Uints(seriesName), cache.ChartUints{0, 10, 20, 30, 40, 50}) {
+		t.Fatalf("unexpected block data %v", charts.Blocks.Uints(seriesName))
+	}
+	if !reflect.DeepEqual(charts.Days.Uints(seriesName), cache.ChartUints{0, 30, 70}) {
+		t.Fatalf("unexpected day data %v", charts.Days.Uints(seriesName))
+	}
+	if tip := charts.SeriesTip(seriesName); tip != 5 {
+		t.Fatalf("unexpected series tip %d", tip)
+	}
+	chart, err := charts.Chart(chartID, string(cache.DayBin), string(cache.TimeAxis))
+	if err != nil {
+		t.Fatalf("error getting chart: %v", err)
+	}
+	if string(chart) != `{"axis":"time","bin":"day","count":[0,30,70],"t":[0,86400,172800]}` {
+		t.Fatalf("unexpected chart json %s", string(chart))
+	}
+}
+
+func TestRegisterSeries(t *testing.T) {
+	ctx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	gobPath := filepath.Join(t.TempDir(), "series.gob")
+
+	var loadedState string // the updater state loaded from the cache file
+	newCharts := func() *cache.ChartData {
+		charts := cache.NewChartData(ctx, 0, chaincfg.MainNetParams())
+		if err := charts.RegisterSeries(testSeries(&loadedState)); err != nil {
+			t.Fatalf("RegisterSeries error: %v", err)
+		}
+		return charts
+	}
+	charts := newCharts()
+
+	// The names of series and charts must be unique, and the series must be
+	// well-formed.
+	for _, series := range []cache.ChartSeries{
+		{Name: seriesName, Bin: cache.BlockBin, Aggregation: cache.SumAggregation},
+		{Name: "other", Bin: cache.BlockBin, Aggregation: cache.SumAggregation, Charts: map[string]cache.ChartMaker{cache.BlockSize: testChart}},
+		{Name: "other", Bin: cache.DayBin, Aggregation: cache.SumAggregation},
+		{Name: "other", Bin: cache.BlockBin},
+		{Name: "other", Type: 5, Bin: cache.WindowBin},
+		{Bin: cache.WindowBin},
+	} {
+		if err := charts.RegisterSeries(series); err == nil {
+			t.Fatalf("no error registering invalid series %+v", series)
+		}
+	}
+	if err := charts.RegisterChart(cache.DurationBTW, cache.BlockBin, testChart); err == nil {
+		t.Fatalf("no error registering a duplicate chart")
+	}
+
+	// Seed the built-in series, and let the updater of the new series catch up.
+	seedCharts(charts)
+	if err := charts.Update(); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	checkTestSeries(t, charts)
+
+	// The series is stored in the cache file alongside the built-in series.
+	charts.Dump(gobPath)
+	reloaded := newCharts()
+	if err := reloaded.Load(gobPath); err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	checkTestSeries(t, reloaded)
+	if !reflect.DeepEqual(reloaded.Blocks.Uints(cache.FeesSeries), charts.Blocks.Uints(cache.FeesSeries)) {
+		t.Fatalf("unexpected reloaded fees %v", reloaded.Blocks.Uints(cache.FeesSeries))
+	}
+	if loadedState != "tip 5" {
+		t.Fatalf("unexpected reloaded updater state %q", loadedState)
+	}
+}
+
+// TestLoadNewSeries checks that a series that is not in the cache file is
+// filled in by its updater, rather than the cached data being discarded.
+func TestLoadNewSeries(t *testing.T) {
+	ctx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	gobPath := filepath.Join(t.TempDir(), "old.gob")
+
+	// A cache file from before the series was registered.
+	old := cache.NewChartData(ctx, 0, chaincfg.MainNetParams())
+	seedCharts(old)
+	if err := old.Lengthen(); err != nil {
+		t.Fatalf("Lengthen error: %v", err)
+	}
+	old.Dump(gobPath)
+
+	var loadedState string
+	charts := cache.NewChartData(ctx, 0, chaincfg.MainNetParams())
+	if err := charts.RegisterSeries(testSeries(&loadedState)); err != nil {
+		t.Fatalf("RegisterSeries error: %v", err)
+	}
+	if err := charts.Load(gobPath); err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if !reflect.DeepEqual(charts.Blocks.Time, seedTimes) {
+		t.Fatalf("cached blocks were not kept: %v", charts.Blocks.Time)
+	}
+	if !reflect.DeepEqual(charts.Days.Uints(cache.FeesSeries), old.Days.Uints(cache.FeesSeries)) {
+		t.Fatalf("unexpected day fees %v", charts.Days.Uints(cache.FeesSeries))
+	}
+	checkTestSeries(t, charts)
+	if loadedState != "" {
+		t.Fatalf("unexpected updater state %q", loadedState)
+	}
+}
@@ -50,20 +50,20 @@ func TestChartsCache(t *testing.T) {
 	appendPt := func(t uint64, v uint64) {
 		charts.Blocks.Height = append(charts.Blocks.Height, v)
 		charts.Blocks.Time = append(charts.Blocks.Time, t)
-		charts.Blocks.PoolSize = append(charts.Blocks.PoolSize, v)
-		charts.Blocks.PoolValue = append(charts.Blocks.PoolValue, v)
-		charts.Blocks.BlockSize = append(charts.Blocks.BlockSize, v)
-		charts.Blocks.TxCount = append(charts.Blocks.TxCount, v)
-		charts.Blocks.NewAtoms = append(charts.Blocks.NewAtoms, v)
-		charts.Blocks.Chainwork = append(charts.Blocks.Chainwork, v)
-		charts.Blocks.Fees = append(charts.Blocks.Fees, v)
-		charts.Blocks.TotalMixed = append(charts.Blocks.TotalMixed, v)
-		charts.Blocks.AnonymitySet = append(charts.Blocks.AnonymitySet, v)
+		charts.Blocks.AppendUints(PoolSizeSeries, v)
+		charts.Blocks.AppendUints(PoolValueSeries, v)
+		charts.Blocks.AppendUints(BlockSizeSeries, v)
+		charts.Blocks.AppendUints(TxCountSeries, v)
+		charts.Blocks.AppendUints(NewAtomsSeries, v)
+		charts.Blocks.AppendUints(ChainworkSeries, v)
+		charts.Blocks.AppendUints(FeesSeries, v)
+		charts.Blocks.AppendUints(TotalMixedSeries, v)
+		charts.Blocks.AppendUints(AnonymitySetSeries, v)
 		charts.Windows.Time = ChartUints{0}
-		charts.Windows.PowDiff = ChartFloats{0}
-		charts.Windows.TicketPrice = ChartUints{0}
-		charts.Windows.StakeCount = ChartUints{0}
-		charts.Windows.MissedVotes = ChartUints{0}
+		charts.Windows.SetFloats(PowDiffSeries, ChartFloats{0})
+		charts.Windows.SetUints(TicketPriceSeries, ChartUints{0})
+		charts.Windows.SetUints(StakeCountSeries, ChartUints{0})
+		charts.Windows.SetUints(MissedVotesSeries, ChartUints{0})
 	}
 
 	seedUints := ChartUints{1, 2, 3, 4, 5, 6}
@@ -132,15 +132,15 @@ func TestChartsCache(t *testing.T) {
 
 		comp("Height before read", charts.Blocks.Height, seedUints, false)
 		comp("Time before read", charts.Blocks.Time, seedTimes, false)
-		comp("PoolSize before read", charts.Blocks.PoolSize, seedUints, false)
-		comp("PoolValue before read", charts.Blocks.PoolValue, seedUints, false)
-		comp("BlockSize before read", charts.Blocks.BlockSize, seedUints, false)
-		comp("TxCount before read", charts.Blocks.TxCount, seedUints, false)
-		comp("NewAtoms before read", charts.Blocks.NewAtoms, seedUints, false)
-		comp("Chainwork before read", charts.Blocks.Chainwork, seedUints, false)
-		comp("Fees before read", charts.Blocks.Fees, seedUints, false)
-		comp("TotalMixed before read", charts.Blocks.TotalMixed, seedUints, false)
-		comp("AnonymitySet before read", charts.Blocks.AnonymitySet, seedUints, false)
+		comp("PoolSize before read", charts.Blocks.Uints(PoolSizeSeries), seedUints, false)
+		comp("PoolValue before read", charts.Blocks.Uints(PoolValueSeries), seedUints, false)
+		comp("BlockSize before read", charts.Blocks.Uints(BlockSizeSeries), seedUints, false)
+		comp("TxCount before read", charts.Blocks.Uints(TxCountSeries), seedUints, false)
+		comp("NewAtoms before read", charts.Blocks.Uints(NewAtomsSeries), seedUints, false)
+		comp("Chainwork before read", charts.Blocks.Uints(ChainworkSeries), seedUints, false)
+		comp("Fees before read", charts.Blocks.Uints(FeesSeries), seedUints, false)
+		comp("TotalMixed before read", charts.Blocks.Uints(TotalMixedSeries), seedUints, false)
+		comp("AnonymitySet before read", charts.Blocks.Uints(AnonymitySetSeries), seedUints, false)
 
 		err := charts.readCacheFile(gobPath)
 		if err != nil {
@@ -149,28 +149,28 @@ func TestChartsCache(t *testing.T) {
 
 		comp("Height after read", charts.Blocks.Height, seedUints, true)
 		comp("Time after read", charts.Blocks.Time, seedTimes, true)
-		comp("PoolSize after read", charts.Blocks.PoolSize, seedUints, true)
-		comp("PoolValue after read", charts.Blocks.PoolValue, seedUints, true)
-		comp("BlockSize after read", charts.Blocks.BlockSize, seedUints, true)
-		comp("TxCount after read", charts.Blocks.TxCount, seedUints, true)
-		comp("NewAtoms after read", charts.Blocks.NewAtoms, seedUints, true)
-		comp("Chainwork after read", charts.Blocks.Chainwork, seedUints, true)
-		comp("Fees after read", charts.Blocks.Fees, seedUints, true)
-		comp("TotalMissed after read", charts.Blocks.TotalMixed, seedUints, true)
-		comp("AnonymitySet after read", charts.Blocks.AnonymitySet, seedUints, true)
+		comp("PoolSize after read", charts.Blocks.Uints(PoolSizeSeries), seedUints, true)
+		comp("PoolValue after read", charts.Blocks.Uints(PoolValueSeries), seedUints, true)
+		comp("BlockSize after read", charts.Blocks.Uints(BlockSizeSeries), seedUints, true)
+		comp("TxCount after read", charts.Blocks.Uints(TxCountSeries), seedUints, true)
+		comp("NewAtoms after read", charts.Blocks.Uints(NewAtomsSeries), seedUints, true)
+		comp("Chainwork after read", charts.Blocks.Uints(ChainworkSeries), seedUints, true)
+		comp("Fees after read", charts.Blocks.Uints(FeesSeries), seedUints, true)
+		comp("TotalMissed after read", charts.Blocks.Uints(TotalMixedSeries), seedUints, true)
+		comp("AnonymitySet after read", charts.Blocks.Uints(AnonymitySetSeries), seedUints, true)
 
 		// Lengthen is called during readCacheFile, so Days should be properly calculated
 		comp("Time after Lengthen", charts.Days.Time, ChartUints{0, aDay, 2 * aDay}, true)
-		comp("PoolSize after Lengthen", charts.Days.PoolSize, uintDaysAvg, true)
-		comp("PoolValue after Lengthen", charts.Days.PoolValue, uintDaysAvg, true)
-		comp("BlockSize after Lengthen", charts.Days.BlockSize, uintDaysSum, true)
-		comp("TxCount after Lengthen", charts.Days.TxCount, uintDaysSum, true)
-		comp("NewAtoms after Lengthen", charts.Days.NewAtoms, uintDaysSum, true)
+		comp("PoolSize after Lengthen", charts.Days.Uints(PoolSizeSeries), uintDaysAvg, true)
+		comp("PoolValue after Lengthen", charts.Days.Uints(PoolValueSeries), uintDaysAvg, true)
+		comp("BlockSize after Lengthen", charts.Days.Uints(BlockSizeSeries), uintDaysSum, true)
+		comp("TxCount after Lengthen", charts.Days.Uints(TxCountSeries), uintDaysSum, true)
+		comp("NewAtoms after Lengthen", charts.Days.Uints(NewAtomsSeries), uintDaysSum, true)
 		// Chainwork will just be the last entry from each day
-		comp("Chainwork after Lengthen", charts.Days.Chainwork, ChartUints{2, 4, 6}, true)
-		comp("Fees after Lengthen", charts.Days.Fees, uintDaysSum, true)
-		comp("TotalMixed after Lengthen", charts.Days.TotalMixed, uintDaysSum, true)
-		comp("AnonymitySet after Lengthen", charts.Days.AnonymitySet, uintDaysAvg, true)
+		comp("Chainwork after Lengthen", charts.Days.Uints(ChainworkSeries), ChartUints{2, 4, 6}, true)
+		comp("Fees after Lengthen", charts.Days.Uints(FeesSeries), uintDaysSum, true)
+		comp("TotalMixed after Lengthen", charts.Days.Uints(TotalMixedSeries), uintDaysSum, true)
+		comp("AnonymitySet after Lengthen", charts.Days.Uints(AnonymitySetSeries), uintDaysAvg, true)
 
 		// An additional call to lengthen should not add any data.
 		timeLen := len(charts.Days.Time)
@@ -269,37 +269,28 @@ func TestChartReorg(t *testing.T) {
 	}
 	resetCharts := func() {
 		charts.Windows = &windowSet{
-			cacheID:     0,
-			Time:        newUints(),
-			PowDiff:     newFloats(),
-			TicketPrice: newUints(),
-			StakeCount:  newUints(),
-			MissedVotes: newUints(),
+			cacheID: 0,
+			Time:    newUints(),
+		}
+		charts.Windows.SetFloats(PowDiffSeries, newFloats())
+		for _, name := range []string{TicketPriceSeries, StakeCountSeries, MissedVotesSeries} {
+			charts.Windows.SetUints(name, newUints())
 		}
 		charts.Days = &zoomSet{
-			cacheID:   0,
-			Height:    newUints(),
-			Time:      newUints(),
-			PoolSize:  newUints(),
-			PoolValue: newUints(),
-			BlockSize: newUints(),
-			TxCount:   newUints(),
-			NewAtoms:  newUints(),
-			Chainwork: newUints(),
-			Fees:      newUints(),
+			cacheID: 0,
+			Height:  newUints(),
+			Time:    newUints(),
 		}
 		charts.Blocks = &zoomSet{
-			cacheID:    0,
-			Time:       newUints(),
-			PoolSize:   newUints(),
-			PoolValue:  newUints(),
-			BlockSize:  newUints(),
-			TxCount:    newUints(),
-			NewAtoms:   newUints(),
-			Chainwork:  newUints(),
-			Fees:       newUints(),
-			TotalMixed: newUints(),
+			cacheID: 0,
+			Time:    newUints(),
+		}
+		for _, name := range []string{PoolSizeSeries, PoolValueSeries, BlockSizeSeries,
+			TxCountSeries, NewAtomsSeries, ChainworkSeries, FeesSeries} {
+			charts.Days.SetUints(name, newUints())
+			charts.Blocks.SetUints(name, newUints())
 		}
+		charts.Blocks.SetUints(TotalMixedSeries, newUints())
 	}
 	// this test reorg will replace the entire chain.
 
@@ -0,0 +1,188 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package cache
+
+// Keys for the coin age charts, which are made from the CoinAgeSeries.
+const (
+	CoinDaysDestroyed = "coin-days-destroyed"
+	HODLWaves         = "hodl-waves"
+	Dormancy          = "dormancy"
+
+	// Names of the coin age series, besides the UTXOAgeSeries.
+	CoinDaysDestroyedSeries = "coin-days-destroyed"
+	ValueSpentSeries        = "value-spent"
+
+	// Some chartResponse keys
+	cddKey      = "cdd"
+	dormancyKey = "dormancy"
+
+	// atomsPerCoin is the number of atoms in a DCR.
+	atomsPerCoin = 1e8
+)
+
+// UTXOAgeBands are the upper bounds, in seconds, on the age of the unspent
+// value in each of the UTXOAgeSeries but the last, which has no upper bound.
+var UTXOAgeBands = []uint64{aDay, 7 * aDay, 30 * aDay, 182 * aDay, 365 * aDay, 730 * aDay}
+
+// UTXOAgeSeries are the names of the series of the unspent value, in atoms, in
+// each band of UTXO age.
+var UTXOAgeSeries = []string{"utxo-age-1d", "utxo-age-1w", "utxo-age-1m",
+	"utxo-age-6m", "utxo-age-1y", "utxo-age-2y", "utxo-age-2y+"}
+
+// The chartResponse keys of the UTXOAgeSeries.
+var utxoAgeKeys = []string{"1d", "1w", "1m", "6m", "1y", "2y", "2y+"}
+
+// CoinAgeSeries are the block-binned series of the coin age charts. For every
+// block, the series are the coin-days destroyed by the spent outputs, the value
+// of the spent outputs, and the unspent value in each UTXO age band, as of the
+// block. The updater must append to all of the series, and is registered with
+// the first.
+func CoinAgeSeries(updater ChartUpdater) []ChartSeries {
+	series := []ChartSeries{
+		{
+			Name:        CoinDaysDestroyedSeries,
+			Type:        FloatSeries,
+			Bin:         BlockBin,
+			Aggregation: SumAggregation,
+			Updater:     &updater,
+			Charts: map[string]ChartMaker{
+				CoinDaysDestroyed: coinDaysDestroyedChart,
+				Dormancy:          dormancyChart,
+			},
+		},
+		{
+			Name:        ValueSpentSeries,
+			Bin:         BlockBin,
+			Aggregation: SumAggregation,
+		},
+	}
+	for i, name := range UTXOAgeSeries {
+		ageSeries := ChartSeries{
+			Name:        name,
+			Bin:         BlockBin,
+			Aggregation: LastAggregation,
+		}
+		if i == 0 {
+			ageSeries.Charts = map[string]ChartMaker{HODLWaves: hodlWavesChart}
+		}
+		series = append(series, ageSeries)
+	}
+	return series
+}
+
+// dormancy is the average age, in days, of the value spent in each bin, which
+// is the coin-days destroyed per coin spent.
+func dormancy(cdd ChartFloats, spent ChartUints) ChartFloats {
+	dataLen := len(cdd)
+	if len(spent) < dataLen {
+		dataLen = len(spent)
+	}
+	d := make(ChartFloats, 0, dataLen)
+	for i := 0; i < dataLen; i++ {
+		if spent[i] == 0 {
+			d = append(d, 0)
+			continue
+		}
+		d = append(d, cdd[i]/(float64(spent[i])/atomsPerCoin))
+	}
+	return d
+}
+
+// The UTXO age bands of the zoomSet, keyed for the chartResponse.
+func utxoAgeSets(set *zoomSet) lengtherMap {
+	sets := make(lengtherMap, len(UTXOAgeSeries)+2)
+	for i, name := range UTXOAgeSeries {
+		sets[utxoAgeKeys[i]] = set.Uints(name)
+	}
+	return sets
+}
+
+func coinDaysDestroyedChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
+	seed := binAxisSeed(bin, axis)
+	switch bin {
+	case BlockBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				cddKey: charts.Blocks.Floats(CoinDaysDestroyedSeries),
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Blocks.Time,
+				cddKey:  charts.Blocks.Floats(CoinDaysDestroyedSeries),
+			}, seed)
+		}
+	case DayBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey: charts.Days.Height,
+				cddKey:    charts.Days.Floats(CoinDaysDestroyedSeries),
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Days.Time,
+				cddKey:  charts.Days.Floats(CoinDaysDestroyedSeries),
+			}, seed)
+		}
+	}
+	return nil, InvalidBinErr
+}
+
+func dormancyChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
+	seed := binAxisSeed(bin, axis)
+	switch bin {
+	case BlockBin:
+		d := dormancy(charts.Blocks.Floats(CoinDaysDestroyedSeries), charts.Blocks.Uints(ValueSpentSeries))
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				dormancyKey: d,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey:     charts.Blocks.Time,
+				dormancyKey: d,
+			}, seed)
+		}
+	case DayBin:
+		d := dormancy(charts.Days.Floats(CoinDaysDestroyedSeries), charts.Days.Uints(ValueSpentSeries))
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey:   charts.Days.Height,
+				dormancyKey: d,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey:     charts.Days.Time,
+				dormancyKey: d,
+			}, seed)
+		}
+	}
+	return nil, InvalidBinErr
+}
+
+// hodlWavesChart is the unspent value in each UTXO age band. The band keys are
+// the upper bounds on the age, but for the oldest band, "2y+".
+func hodlWavesChart(charts *ChartData, bin BinLevel, axis AxisType) ([]byte, error) {
+	seed := binAxisSeed(bin, axis)
+	switch bin {
+	case BlockBin:
+		sets := utxoAgeSets(charts.Blocks)
+		if axis != HeightAxis {
+			sets[timeKey] = charts.Blocks.Time
+		}
+		return encode(sets, seed)
+	case DayBin:
+		sets := utxoAgeSets(charts.Days)
+		if axis == HeightAxis {
+			sets[heightKey] = charts.Days.Height
+		} else {
+			sets[timeKey] = charts.Days.Time
+		}
+		return encode(sets, seed)
+	}
+	return nil, InvalidBinErr
+}
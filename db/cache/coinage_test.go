@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+)
+
+func TestDormancy(t *testing.T) {
+	d := dormancy(ChartFloats{0, 20, 3, 9}, ChartUints{0, 10e8, 2e8})
+	if !reflect.DeepEqual(d, ChartFloats{0, 2, 1.5}) {
+		t.Fatalf("unexpected dormancy %v", d)
+	}
+}
+
+func TestCoinAgeSeries(t *testing.T) {
+	charts := NewChartData(context.Background(), 0, chaincfg.MainNetParams())
+	for _, series := range CoinAgeSeries(ChartUpdater{
+		Tag: "test",
+		Fetcher: func(*ChartData) (*sql.Rows, func(), error) {
+			return nil, func() {}, nil
+		},
+		Appender: func(*ChartData, *sql.Rows) error { return nil },
+	}) {
+		if err := charts.RegisterSeries(series); err != nil {
+			t.Fatalf("RegisterSeries error: %v", err)
+		}
+	}
+
+	for i, stamp := range []uint64{1, 2, 2 + aDay} {
+		charts.Blocks.Height = append(charts.Blocks.Height, uint64(i))
+		charts.Blocks.Time = append(charts.Blocks.Time, stamp)
+		charts.Blocks.AppendFloats(CoinDaysDestroyedSeries, 10)
+		charts.Blocks.AppendUints(ValueSpentSeries, 5e8)
+		for j, name := range UTXOAgeSeries {
+			charts.Blocks.AppendUints(name, uint64(i*10+j))
+		}
+	}
+
+	chart, err := charts.Chart(HODLWaves, string(BlockBin), string(HeightAxis))
+	if err != nil {
+		t.Fatalf("error getting HODL waves chart: %v", err)
+	}
+	var waves map[string]interface{}
+	if err := json.Unmarshal(chart, &waves); err != nil {
+		t.Fatalf("error decoding HODL waves chart: %v", err)
+	}
+	for j, key := range utxoAgeKeys {
+		band, ok := waves[key].([]interface{})
+		if !ok || len(band) != 3 || band[2].(float64) != float64(20+j) {
+			t.Fatalf("unexpected %s band %v", key, waves[key])
+		}
+	}
+
+	chart, err = charts.Chart(Dormancy, string(BlockBin), string(TimeAxis))
+	if err != nil {
+		t.Fatalf("error getting dormancy chart: %v", err)
+	}
+	if string(chart) != `{"axis":"time","bin":"block","dormancy":[2,2,2],"t":[1,2,86402]}` {
+		t.Fatalf("unexpected dormancy chart %s", string(chart))
+	}
+}
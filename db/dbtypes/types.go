@@ -506,10 +506,16 @@ func NewTimeDefFromUNIX(t int64) TimeDef {
 // displayed time string is in UTC: "2016-02-08 18:00:00Z". On the other hand,
 // if the time read from the DB is "2016-02-08 12:00:00+6", it does not matter
 // what the server time zone is set to, and the time will still be converted to
-// UTC as "2016-02-08 18:00:00Z".
+// UTC as "2016-02-08 18:00:00Z". An integer UNIX time stamp in seconds, such
+// as computed by the SQLite date and time functions, is also accepted.
 func (t *TimeDef) Scan(src interface{}) error {
-	srcTime, ok := src.(time.Time)
-	if !ok {
+	var srcTime time.Time
+	switch v := src.(type) {
+	case time.Time:
+		srcTime = v
+	case int64:
+		srcTime = time.Unix(v, 0)
+	default:
 		return fmt.Errorf("scanned value not a time.Time")
 	}
 	// Debug:
@@ -960,6 +966,36 @@ type AgendaSummary struct {
 	LockedIn      int64
 }
 
+// AgendaRCIVotes is the tally of the vote choices for an agenda in a rule
+// change interval (RCI). The RCIs are numbered from the stake validation
+// height.
+type AgendaRCIVotes struct {
+	RCI     int64
+	Yes     uint32
+	Abstain uint32
+	No      uint32
+	Total   uint32
+}
+
+// RCIVersionCount is the number of mainchain votes or blocks of a version in a
+// rule change interval.
+type RCIVersionCount struct {
+	RCI     int64
+	Version uint32
+	Count   uint32
+}
+
+// AgendaVoteHistory is the vote choices tally for an agenda and the stake and
+// block versions in each rule change interval from the first with votes on the
+// agenda to the end of its voting, or the best block.
+type AgendaVoteHistory struct {
+	MileStone     MileStone
+	Height        int64
+	Votes         []*AgendaRCIVotes
+	StakeVersions []*RCIVersionCount
+	BlockVersions []*RCIVersionCount
+}
+
 // TreasurySpendVotes summarizes the vote tally for a tspend.
 type TreasurySpendVotes struct {
 	Hash      ChainHash `json:"hash"`
@@ -990,6 +1026,72 @@ type TreasurySpendMetaData struct {
 	Approval         float32
 }
 
+// Treasury spend statuses of a TreasurySpendTally.
+const (
+	TSpendPending  = "pending"  // voting has not started
+	TSpendVoting   = "voting"   // voting, not yet approved
+	TSpendApproved = "approved" // approved, may be mined in the next TVI block
+	TSpendMined    = "mined"
+	TSpendRejected = "rejected" // voting ended without approval
+	TSpendExpired  = "expired"  // approved, but expired before it was mined
+)
+
+// TreasurySpendTally tracks the votes on a tspend through its voting window,
+// by treasury vote interval (TVI), with the quorum and approval requirements
+// of the chain parameters and a projection of the outcome.
+type TreasurySpendTally struct {
+	Hash             string                   `json:"hash"`
+	Amount           int64                    `json:"amount"`
+	Expiry           int64                    `json:"expiry"`
+	VoteStart        int64                    `json:"votestart"`
+	VoteEnd          int64                    `json:"voteend"`
+	Height           int64                    `json:"height"` // block height of the tally
+	MinedHeight      int64                    `json:"minedheight,omitempty"`
+	Status           string                   `json:"status"`
+	YesVotes         int64                    `json:"yesvotes"`
+	NoVotes          int64                    `json:"novotes"`
+	AbstainVotes     int64                    `json:"abstainvotes"`
+	MaxVotes         int64                    `json:"maxvotes"`
+	QuorumVotes      int64                    `json:"quorumvotes"`
+	RequiredYesVotes int64                    `json:"requiredyesvotes"`
+	QuorumAchieved   bool                     `json:"quorumachieved"`
+	Approval         float64                  `json:"approval"` // fraction of yes votes
+	Projection       TreasurySpendProjection  `json:"projection"`
+	Intervals        []*TreasurySpendInterval `json:"intervals"`
+}
+
+// TreasurySpendInterval is the vote tally of a tspend in the blocks of one
+// treasury vote interval. Votes is the number of votes in these blocks, which
+// includes those that abstained on the tspend.
+type TreasurySpendInterval struct {
+	Start        int64 `json:"start"`
+	End          int64 `json:"end"` // last block of the interval
+	Blocks       int64 `json:"blocks"`
+	Votes        int64 `json:"votes"`
+	YesVotes     int64 `json:"yesvotes"`
+	NoVotes      int64 `json:"novotes"`
+	AbstainVotes int64 `json:"abstainvotes"`
+}
+
+// TreasurySpendProjection is the expected final tally of a tspend, assuming
+// the turnout and approval so far continue for the rest of its voting window.
+type TreasurySpendProjection struct {
+	YesVotes       int64 `json:"yesvotes"`
+	NoVotes        int64 `json:"novotes"`
+	QuorumAchieved bool  `json:"quorumachieved"`
+	Approved       bool  `json:"approved"`
+}
+
+// Final indicates if the tspend has a final outcome, i.e. it is no longer
+// voting or waiting to be mined.
+func (t *TreasurySpendTally) Final() bool {
+	switch t.Status {
+	case TSpendMined, TSpendRejected, TSpendExpired:
+		return true
+	}
+	return false
+}
+
 // BlockChainData defines data holding the latest block chain state from the
 // getblockchaininfo rpc endpoint.
 type BlockChainData struct {
@@ -2233,6 +2335,213 @@ type TreasuryBalance struct {
 	Immature       int64 `json:"immature"`
 }
 
+// BlockStakeData is the ticket price, number of tickets purchased, and ticket
+// pool size of a block.
+type BlockStakeData struct {
+	Height     int64
+	Time       TimeDef
+	SBits      int64
+	FreshStake int64
+	PoolSize   int64
+}
+
+// VoteAgeBin is the number and mean age in blocks of the votes in a range of
+// blocks starting at Height.
+type VoteAgeBin struct {
+	Height  int64
+	Votes   int64
+	MeanAge float64
+}
+
+// VSPAddressTickets counts the spent mainchain tickets whose votes or
+// revocations paid a VSP fee address, which the tickets committed to when they
+// were purchased. VoteAgeSum is the total number of blocks from purchase to
+// vote of the voted tickets.
+type VSPAddressTickets struct {
+	Address    string
+	Voted      int64
+	Missed     int64
+	Expired    int64
+	Revoked    int64
+	VoteAgeSum int64
+}
+
+// TicketSpendAddress is a mainchain ticket with its purchase height, price and
+// pool status. FeeAddress is one of the requested fee addresses that was paid
+// by the ticket's vote or revocation, if any.
+type TicketSpendAddress struct {
+	Hash        string
+	BlockHeight int64
+	Price       float64
+	PoolStatus  TicketPoolStatus
+	SpendType   TicketSpendType
+	FeeAddress  string
+}
+
+// IndexPrice is the DCR price in an index currency over an interval of the
+// exchange rate history starting at Start. Backfilled prices were computed from
+// exchange candlesticks rather than recorded by the exchange bot.
+type IndexPrice struct {
+	Currency   string
+	Start      time.Time
+	Price      float64
+	Volume     float64
+	Backfilled bool
+}
+
+// TreasuryStatement is an accounting statement of the treasury for one period
+// of a time grouping. Amounts are in atoms. The fiat rate, in the FiatIndex
+// currency, and the fiat values are the mean exchange rate of the period. They
+// are only set when FiatAvailable, i.e. an exchange rate for the period is
+// known.
+type TreasuryStatement struct {
+	Start          TimeDef                `json:"start"`
+	End            TimeDef                `json:"end"`
+	OpeningBalance int64                  `json:"opening_balance"`
+	TBase          int64                  `json:"tbase"`
+	TBaseCount     int64                  `json:"tbase_count"`
+	Added          int64                  `json:"added"`
+	AddCount       int64                  `json:"add_count"`
+	AddSources     []*TreasuryAddSource   `json:"add_sources"`
+	Spent          int64                  `json:"spent"`
+	SpendCount     int64                  `json:"spend_count"`
+	Payouts        []*TreasurySpendPayout `json:"payouts"`
+	ClosingBalance int64                  `json:"closing_balance"`
+	FiatAvailable  bool                   `json:"fiat_available"`
+	FiatRate       float64                `json:"fiat_rate,omitempty"`
+	FiatIndex      string                 `json:"fiat_index,omitempty"`
+}
+
+// TreasuryAddSource is the amount added to the treasury from one address by
+// tadds during a statement period. When a tadd spends from several addresses,
+// its amount is split between them in proportion to their input values.
+type TreasuryAddSource struct {
+	Address   string  `json:"address"`
+	Amount    int64   `json:"amount"`
+	TxCount   int64   `json:"tx_count"`
+	FiatValue float64 `json:"fiat_value,omitempty"`
+}
+
+// TreasurySpendPayout is one output of a tspend.
+type TreasurySpendPayout struct {
+	TxID        string  `json:"txid"`
+	BlockHeight int64   `json:"block_height"`
+	BlockTime   TimeDef `json:"block_time"`
+	Address     string  `json:"address"`
+	Amount      int64   `json:"amount"`
+	FiatValue   float64 `json:"fiat_value,omitempty"`
+}
+
+// StatementPeriod returns the start and end of the statement period of the
+// given grouping that contains the time t. Only the year and month groupings
+// are valid for treasury statements.
+func StatementPeriod(t time.Time, grouping TimeBasedGrouping) (start, end time.Time, err error) {
+	t = t.UTC()
+	switch grouping {
+	case YearGrouping:
+		start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0), nil
+	case MonthGrouping:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid statement grouping %q", grouping)
+}
+
+// TreasuryAddInput is a previous output spent by a tadd, as used to attribute
+// the added amount to source addresses.
+type TreasuryAddInput struct {
+	TxID      string
+	BlockTime time.Time
+	Amount    int64 // added to the treasury by the tadd
+	Address   string
+	Value     int64 // of the spent output
+}
+
+// NewTreasuryStatements builds the statements from the per-period treasury
+// totals, which must be sorted by start time, and the tadd inputs and tspend
+// payouts of all periods. The opening balance of each period is the closing
+// balance of the previous one.
+func NewTreasuryStatements(totals []*TreasuryStatement, inputs []*TreasuryAddInput,
+	payouts []*TreasurySpendPayout, grouping TimeBasedGrouping) ([]*TreasuryStatement, error) {
+	periods := make(map[int64]*TreasuryStatement, len(totals))
+	var balance int64
+	for _, st := range totals {
+		start, end, err := StatementPeriod(st.Start.T, grouping)
+		if err != nil {
+			return nil, err
+		}
+		st.Start, st.End = NewTimeDef(start), NewTimeDef(end)
+		st.OpeningBalance = balance
+		balance += st.TBase + st.Added - st.Spent
+		st.ClosingBalance = balance
+		periods[start.Unix()] = st
+	}
+
+	periodOf := func(t time.Time) *TreasuryStatement {
+		start, _, _ := StatementPeriod(t, grouping)
+		return periods[start.Unix()]
+	}
+
+	// Split the amount of each tadd between its input addresses.
+	for i := 0; i < len(inputs); {
+		j := i + 1
+		for j < len(inputs) && inputs[j].TxID == inputs[i].TxID {
+			j++
+		}
+		txInputs := inputs[i:j]
+		i = j
+
+		st := periodOf(txInputs[0].BlockTime)
+		if st == nil {
+			continue
+		}
+		var total int64
+		for _, in := range txInputs {
+			total += in.Value
+		}
+		remaining := txInputs[0].Amount
+		for k, in := range txInputs {
+			share := remaining
+			if k < len(txInputs)-1 && total > 0 {
+				share = int64(float64(txInputs[0].Amount) * float64(in.Value) / float64(total))
+			}
+			remaining -= share
+			st.addSource(in.Address, share)
+		}
+	}
+
+	for _, p := range payouts {
+		if st := periodOf(p.BlockTime.T); st != nil {
+			st.Payouts = append(st.Payouts, p)
+		}
+	}
+
+	for _, st := range totals {
+		sort.Slice(st.AddSources, func(i, j int) bool {
+			return st.AddSources[i].Amount > st.AddSources[j].Amount
+		})
+	}
+
+	return totals, nil
+}
+
+// addSource adds an amount from the address to the statement's tadd sources.
+func (st *TreasuryStatement) addSource(address string, amount int64) {
+	for _, src := range st.AddSources {
+		if src.Address == address {
+			src.Amount += amount
+			src.TxCount++
+			return
+		}
+	}
+	st.AddSources = append(st.AddSources, &TreasuryAddSource{
+		Address: address,
+		Amount:  amount,
+		TxCount: 1,
+	})
+}
+
 // AddressTransactions collects the transactions for an address as AddressTx
 // slices.
 type AddressTransactions struct {
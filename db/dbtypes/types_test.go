@@ -182,3 +182,51 @@ func TestChainHashArray2_Value(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTreasuryStatements(t *testing.T) {
+	jan := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+	totals := []*TreasuryStatement{
+		{Start: NewTimeDef(jan), TBase: 1000, Added: 300},
+		{Start: NewTimeDef(feb), TBase: 1000, Spent: 500},
+	}
+	inputs := []*TreasuryAddInput{
+		{TxID: "a", BlockTime: jan.Add(time.Hour), Amount: 300, Address: "Ds1", Value: 100},
+		{TxID: "a", BlockTime: jan.Add(time.Hour), Amount: 300, Address: "Ds2", Value: 200},
+	}
+	payouts := []*TreasurySpendPayout{
+		{TxID: "b", BlockTime: NewTimeDef(feb.Add(time.Hour)), Address: "Ds3", Amount: 500},
+	}
+
+	statements, err := NewTreasuryStatements(totals, inputs, payouts, MonthGrouping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(statements))
+	}
+
+	st := statements[0]
+	if st.OpeningBalance != 0 || st.ClosingBalance != 1300 {
+		t.Errorf("unexpected January balances %d, %d", st.OpeningBalance, st.ClosingBalance)
+	}
+	if !st.End.T.Equal(feb) {
+		t.Errorf("unexpected January end %v", st.End.T)
+	}
+	if len(st.AddSources) != 2 || st.AddSources[0].Address != "Ds2" ||
+		st.AddSources[0].Amount != 200 || st.AddSources[1].Amount != 100 {
+		t.Errorf("unexpected January tadd sources %+v, %+v", st.AddSources[0], st.AddSources[1])
+	}
+
+	st = statements[1]
+	if st.OpeningBalance != 1300 || st.ClosingBalance != 1800 {
+		t.Errorf("unexpected February balances %d, %d", st.OpeningBalance, st.ClosingBalance)
+	}
+	if len(st.Payouts) != 1 || st.Payouts[0].Address != "Ds3" {
+		t.Errorf("unexpected February payouts %v", st.Payouts)
+	}
+
+	if _, err = NewTreasuryStatements(totals, nil, nil, WeekGrouping); err == nil {
+		t.Error("expected an error for the week grouping")
+	}
+}
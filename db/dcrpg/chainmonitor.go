@@ -140,7 +140,7 @@ func (p *ChainMonitor) switchToSideChain(reorgData *txhelpers.ReorgData) (int32,
 // corresponding reorganization of the ChainDB. ReorgHandler satisfies
 // notification.ReorgHandler, and is registered as a handler in main.go.
 func (p *ChainMonitor) ReorgHandler(reorg *txhelpers.ReorgData) error {
-	p.db.InReorg = true // to avoid project fund balance computation
+	p.db.InReorg.Store(true) // to avoid project fund balance computation
 	newHeight, oldHeight := reorg.NewChainHeight, reorg.OldChainHeight
 	newHash, oldHash := reorg.NewChainHead, reorg.OldChainHead
 
@@ -161,7 +161,7 @@ func (p *ChainMonitor) ReorgHandler(reorg *txhelpers.ReorgData) error {
 		return fmt.Errorf("stakeDBTipHash is %d, expected %d", stakeDBTipHash, newHash)
 	}
 
-	p.db.InReorg = false
+	p.db.InReorg.Store(false)
 
 	// Update project fund in cache, but clear NO address cache data since
 	// switchToSideChain maintains the cache via TipToSideChain and StoreBlock.
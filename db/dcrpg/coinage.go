@@ -0,0 +1,301 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrdata/v8/db/cache"
+)
+
+// coinAgeJournalDepth is the number of the most recent blocks that the
+// coinAgeLedger can roll back without being rebuilt.
+const coinAgeJournalDepth = 512
+
+// coinAgeSpend is the value spent from outputs funded at a height.
+type coinAgeSpend struct {
+	fundHeight int64
+	value      int64
+}
+
+// coinAgeBlock is the value funded and spent in a block.
+type coinAgeBlock struct {
+	funded int64
+	spends []coinAgeSpend
+}
+
+// coinAgeLedger tracks the unspent value by the height at which it was funded,
+// to compute the coin age chart series block by block. The value is binned by
+// the cache.UTXOAgeBands. For each band, the bound is the lowest funding height
+// not yet older than the band's upper bound on age, so that the value funded
+// at a height is in the band of the number of bounds above the height. The
+// bounds only advance with time, and the bound of an older band never passes
+// the bound of a younger band. The coinAgeLedger is only used by the coin age
+// chart updater, and is not safe for concurrent use.
+type coinAgeLedger struct {
+	times   []uint64 // block time by height
+	unspent []int64  // unspent value by funding height
+	bounds  []int64
+	bands   []int64
+	// journal is the most recent blocks, the last being at the tip.
+	journal []*coinAgeBlock
+}
+
+// newCoinAgeLedger is the constructor for an empty coinAgeLedger.
+func newCoinAgeLedger() *coinAgeLedger {
+	return &coinAgeLedger{
+		bounds: make([]int64, len(cache.UTXOAgeBands)),
+		bands:  make([]int64, len(cache.UTXOAgeBands)+1),
+	}
+}
+
+// tip is the height of the last block connected to the ledger, or -1 if the
+// ledger is empty.
+func (ledger *coinAgeLedger) tip() int64 {
+	return int64(len(ledger.times)) - 1
+}
+
+// reset empties the ledger.
+func (ledger *coinAgeLedger) reset() {
+	ledger.times = nil
+	ledger.unspent = nil
+	ledger.journal = nil
+	ledger.rebin()
+}
+
+// band is the index of the UTXO age band of the value funded at the height.
+func (ledger *coinAgeLedger) band(fundHeight int64) int {
+	var band int
+	for _, bound := range ledger.bounds {
+		if fundHeight < bound {
+			band++
+		}
+	}
+	return band
+}
+
+// advance moves the value that has aged past the upper bound of a band to the
+// next band, as of the time stamp.
+func (ledger *coinAgeLedger) advance(stamp uint64) {
+	for i, maxAge := range cache.UTXOAgeBands {
+		limit := int64(len(ledger.times))
+		if i > 0 {
+			limit = ledger.bounds[i-1]
+		}
+		for ledger.bounds[i] < limit && ledger.times[ledger.bounds[i]]+maxAge <= stamp {
+			value := ledger.unspent[ledger.bounds[i]]
+			ledger.bands[i] -= value
+			ledger.bands[i+1] += value
+			ledger.bounds[i]++
+		}
+	}
+}
+
+// rebin bins all of the unspent value from scratch, as of the time of the tip.
+func (ledger *coinAgeLedger) rebin() {
+	for i := range ledger.bounds {
+		ledger.bounds[i] = 0
+	}
+	for i := range ledger.bands {
+		ledger.bands[i] = 0
+	}
+	for _, value := range ledger.unspent {
+		ledger.bands[0] += value
+	}
+	if tip := ledger.tip(); tip >= 0 {
+		ledger.advance(ledger.times[tip])
+	}
+}
+
+// coinDays is the value, in atoms, multiplied by the time between the funding
+// and the spending, in days.
+func coinDays(value int64, fundTime, spendTime uint64) float64 {
+	if spendTime <= fundTime {
+		return 0
+	}
+	return float64(value) / 1e8 * float64(spendTime-fundTime) / 86400
+}
+
+// connect adds the next block, which has the time stamp, to the ledger. The
+// coin-days destroyed and the value spent in the block are returned.
+func (ledger *coinAgeLedger) connect(block *coinAgeBlock, stamp uint64) (cdd float64, spent uint64) {
+	ledger.times = append(ledger.times, stamp)
+	ledger.unspent = append(ledger.unspent, block.funded)
+	ledger.bands[0] += block.funded
+	ledger.advance(stamp)
+	for _, spend := range block.spends {
+		ledger.unspent[spend.fundHeight] -= spend.value
+		ledger.bands[ledger.band(spend.fundHeight)] -= spend.value
+		cdd += coinDays(spend.value, ledger.times[spend.fundHeight], stamp)
+		spent += uint64(spend.value)
+	}
+	ledger.journal = append(ledger.journal, block)
+	if len(ledger.journal) > 2*coinAgeJournalDepth {
+		ledger.journal = append([]*coinAgeBlock(nil), ledger.journal[len(ledger.journal)-coinAgeJournalDepth:]...)
+	}
+	return
+}
+
+// rollback disconnects the blocks above the height. If the blocks are not all
+// in the journal, the ledger is not changed, and rollback returns false.
+func (ledger *coinAgeLedger) rollback(height int64) bool {
+	if height < -1 {
+		height = -1
+	}
+	depth := ledger.tip() - height
+	if depth <= 0 {
+		return true
+	}
+	if depth > int64(len(ledger.journal)) {
+		return false
+	}
+	for _, block := range ledger.journal[int64(len(ledger.journal))-depth:] {
+		for _, spend := range block.spends {
+			ledger.unspent[spend.fundHeight] += spend.value
+		}
+	}
+	ledger.journal = ledger.journal[:int64(len(ledger.journal))-depth]
+	ledger.times = ledger.times[:height+1]
+	ledger.unspent = ledger.unspent[:height+1]
+	ledger.rebin()
+	return true
+}
+
+// utxoAges is the unspent value, in atoms, in each UTXO age band.
+func (ledger *coinAgeLedger) utxoAges() []uint64 {
+	ages := make([]uint64, len(ledger.bands))
+	for i, value := range ledger.bands {
+		if value > 0 {
+			ages[i] = uint64(value)
+		}
+	}
+	return ages
+}
+
+// encode serializes the unspent value by funding height and the journal, as
+// varints. The block times are not included, since they are in the charts data.
+func (ledger *coinAgeLedger) encode() []byte {
+	data := make([]byte, 0, 4*len(ledger.unspent))
+	data = binary.AppendUvarint(data, uint64(len(ledger.unspent)))
+	for _, value := range ledger.unspent {
+		data = binary.AppendVarint(data, value)
+	}
+	data = binary.AppendUvarint(data, uint64(len(ledger.journal)))
+	for _, block := range ledger.journal {
+		data = binary.AppendVarint(data, block.funded)
+		data = binary.AppendUvarint(data, uint64(len(block.spends)))
+		for _, spend := range block.spends {
+			data = binary.AppendVarint(data, spend.fundHeight)
+			data = binary.AppendVarint(data, spend.value)
+		}
+	}
+	return data
+}
+
+// decodeCoinAgeLedger deserializes a coinAgeLedger encoded by encode, with the
+// block times by height.
+func decodeCoinAgeLedger(data []byte, times []uint64) (*coinAgeLedger, error) {
+	errInvalid := errors.New("invalid coin age ledger data")
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errInvalid
+		}
+		data = data[n:]
+		return v, nil
+	}
+	varint := func() (int64, error) {
+		v, n := binary.Varint(data)
+		if n <= 0 {
+			return 0, errInvalid
+		}
+		data = data[n:]
+		return v, nil
+	}
+
+	numBlocks, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if numBlocks > uint64(len(times)) {
+		return nil, fmt.Errorf("coin age ledger of %d blocks, but only %d block times",
+			numBlocks, len(times))
+	}
+	ledger := newCoinAgeLedger()
+	ledger.times = append([]uint64(nil), times[:numBlocks]...)
+	ledger.unspent = make([]int64, numBlocks)
+	for i := range ledger.unspent {
+		if ledger.unspent[i], err = varint(); err != nil {
+			return nil, err
+		}
+	}
+	journalLen, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if journalLen > numBlocks {
+		return nil, errInvalid
+	}
+	for i := uint64(0); i < journalLen; i++ {
+		block := new(coinAgeBlock)
+		if block.funded, err = varint(); err != nil {
+			return nil, err
+		}
+		numSpends, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		for j := uint64(0); j < numSpends; j++ {
+			var spend coinAgeSpend
+			if spend.fundHeight, err = varint(); err != nil {
+				return nil, err
+			}
+			if spend.value, err = varint(); err != nil {
+				return nil, err
+			}
+			if spend.fundHeight < 0 || spend.fundHeight >= int64(numBlocks) {
+				return nil, errInvalid
+			}
+			block.spends = append(block.spends, spend)
+		}
+		ledger.journal = append(ledger.journal, block)
+	}
+	if len(data) > 0 {
+		return nil, errInvalid
+	}
+	ledger.rebin()
+	return ledger, nil
+}
+
+// loadCoinAgeLedger decodes a coinAgeLedger encoded by encode, with the block
+// times by height, and rolls it back to the height if it is ahead. The times
+// of the blocks above the height may be missing, as when the blocks were
+// orphaned, since they are not needed once the blocks are rolled back. A ledger
+// that cannot be rolled back to the height is refused.
+func loadCoinAgeLedger(data []byte, times []uint64, height int64) (*coinAgeLedger, error) {
+	numBlocks, n := binary.Uvarint(data)
+	if n > 0 && numBlocks > uint64(len(times)) && int64(len(times)) > height &&
+		numBlocks-uint64(len(times)) <= 2*coinAgeJournalDepth {
+		var last uint64
+		if len(times) > 0 {
+			last = times[len(times)-1]
+		}
+		padded := make([]uint64, numBlocks)
+		for i := copy(padded, times); i < len(padded); i++ {
+			padded[i] = last
+		}
+		times = padded
+	}
+	ledger, err := decodeCoinAgeLedger(data, times)
+	if err != nil {
+		return nil, err
+	}
+	if ledger.tip() > height && !ledger.rollback(height) {
+		return nil, fmt.Errorf("coin age ledger at height %d cannot be rolled back to height %d",
+			ledger.tip(), height)
+	}
+	return ledger, nil
+}
@@ -0,0 +1,168 @@
+package dcrpg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoinAgeLedger(t *testing.T) {
+	const day, coin = 86400, 1e8
+	stamps := []uint64{0, day / 2, 2 * day, 8 * day, 40 * day}
+	blocks := []*coinAgeBlock{
+		{funded: 100 * coin},
+		{funded: 50 * coin},
+		{funded: 10 * coin, spends: []coinAgeSpend{{0, 30 * coin}}},
+		{},
+		{funded: 5 * coin, spends: []coinAgeSpend{{1, 50 * coin}, {4, 1 * coin}}},
+	}
+	connect := func(ledger *coinAgeLedger, through int) {
+		for h := int(ledger.tip()) + 1; h <= through; h++ {
+			ledger.connect(blocks[h], stamps[h])
+		}
+	}
+
+	ledger := newCoinAgeLedger()
+	connect(ledger, 1)
+	if ages := ledger.utxoAges(); !reflect.DeepEqual(ages, []uint64{150 * coin, 0, 0, 0, 0, 0, 0}) {
+		t.Fatalf("unexpected UTXO ages at height 1: %v", ages)
+	}
+
+	cdd, spent := ledger.connect(blocks[2], stamps[2])
+	if cdd != 60 || spent != 30*coin {
+		t.Fatalf("unexpected coin-days destroyed %f and value spent %d at height 2", cdd, spent)
+	}
+	if ages := ledger.utxoAges(); !reflect.DeepEqual(ages, []uint64{10 * coin, 120 * coin, 0, 0, 0, 0, 0}) {
+		t.Fatalf("unexpected UTXO ages at height 2: %v", ages)
+	}
+
+	connect(ledger, 3)
+	if ages := ledger.utxoAges(); !reflect.DeepEqual(ages, []uint64{0, 10 * coin, 120 * coin, 0, 0, 0, 0}) {
+		t.Fatalf("unexpected UTXO ages at height 3: %v", ages)
+	}
+
+	// 50 coins spent after 39.5 days, and 1 coin funded and spent in the block.
+	cdd, spent = ledger.connect(blocks[4], stamps[4])
+	if cdd != 50*39.5 || spent != 51*coin {
+		t.Fatalf("unexpected coin-days destroyed %f and value spent %d at height 4", cdd, spent)
+	}
+	if ages := ledger.utxoAges(); !reflect.DeepEqual(ages, []uint64{4 * coin, 0, 0, 80 * coin, 0, 0, 0}) {
+		t.Fatalf("unexpected UTXO ages at height 4: %v", ages)
+	}
+
+	// Rolling back matches connecting the blocks to a new ledger.
+	for _, height := range []int{2, 0, -1} {
+		if !ledger.rollback(int64(height)) {
+			t.Fatalf("failed to roll back to height %d", height)
+		}
+		if ledger.tip() != int64(height) {
+			t.Fatalf("tip %d after rolling back to height %d", ledger.tip(), height)
+		}
+		fresh := newCoinAgeLedger()
+		connect(fresh, height)
+		if !reflect.DeepEqual(ledger.utxoAges(), fresh.utxoAges()) || !reflect.DeepEqual(append([]int64{}, ledger.unspent...), append([]int64{}, fresh.unspent...)) {
+			t.Fatalf("rolled back ledger %v does not match new ledger %v at height %d",
+				ledger.utxoAges(), fresh.utxoAges(), height)
+		}
+	}
+
+	// A rollback deeper than the journal is refused.
+	for h := 0; h <= 2*coinAgeJournalDepth; h++ {
+		ledger.connect(&coinAgeBlock{funded: coin}, uint64(h)*300)
+	}
+	if ledger.rollback(0) {
+		t.Fatalf("rolled back past the journal")
+	}
+	if ledger.tip() != 2*coinAgeJournalDepth {
+		t.Fatalf("ledger changed by a refused rollback")
+	}
+	if !ledger.rollback(ledger.tip() - coinAgeJournalDepth) {
+		t.Fatalf("failed to roll back through the journal")
+	}
+}
+
+func TestCoinAgeLedgerEncoding(t *testing.T) {
+	const day, coin = 86400, 1e8
+	times := make([]uint64, 40)
+	ledger := newCoinAgeLedger()
+	for h := range times {
+		times[h] = uint64(h) * day
+		block := &coinAgeBlock{funded: 10 * coin}
+		if h >= 5 {
+			block.spends = []coinAgeSpend{{int64(h - 5), 3 * coin}, {int64(h / 2), coin}}
+		}
+		ledger.connect(block, times[h])
+	}
+
+	// The decoded ledger has the same values and can be rolled back.
+	decoded, err := decodeCoinAgeLedger(ledger.encode(), times)
+	if err != nil {
+		t.Fatalf("decodeCoinAgeLedger failed: %v", err)
+	}
+	if decoded.tip() != ledger.tip() || !reflect.DeepEqual(decoded.unspent, ledger.unspent) ||
+		!reflect.DeepEqual(decoded.utxoAges(), ledger.utxoAges()) {
+		t.Fatalf("decoded ledger %v does not match ledger %v", decoded.utxoAges(), ledger.utxoAges())
+	}
+	if !decoded.rollback(20) || !ledger.rollback(20) {
+		t.Fatalf("failed to roll back the ledgers")
+	}
+	if !reflect.DeepEqual(decoded.unspent, ledger.unspent) || !reflect.DeepEqual(decoded.utxoAges(), ledger.utxoAges()) {
+		t.Fatalf("rolled back decoded ledger %v does not match ledger %v", decoded.utxoAges(), ledger.utxoAges())
+	}
+
+	// Data for more blocks than there are times, or truncated data, is refused.
+	data := ledger.encode()
+	if _, err = decodeCoinAgeLedger(data, times[:10]); err == nil {
+		t.Errorf("decoded a ledger without the block times")
+	}
+	if _, err = decodeCoinAgeLedger(data[:len(data)-1], times); err == nil {
+		t.Errorf("decoded truncated ledger data")
+	}
+}
+
+func TestLoadCoinAgeLedger(t *testing.T) {
+	const day, coin = 86400, 1e8
+	times := make([]uint64, 40)
+	ledger := newCoinAgeLedger()
+	for h := range times {
+		times[h] = uint64(h) * day
+		block := &coinAgeBlock{funded: 10 * coin}
+		if h >= 5 {
+			block.spends = []coinAgeSpend{{int64(h - 5), 3 * coin}}
+		}
+		ledger.connect(block, times[h])
+	}
+	data := ledger.encode()
+
+	loaded, err := loadCoinAgeLedger(data, times, ledger.tip())
+	if err != nil {
+		t.Fatalf("loadCoinAgeLedger failed: %v", err)
+	}
+	if loaded.tip() != ledger.tip() || !reflect.DeepEqual(loaded.utxoAges(), ledger.utxoAges()) {
+		t.Fatalf("loaded ledger %v does not match ledger %v", loaded.utxoAges(), ledger.utxoAges())
+	}
+
+	// A ledger ahead of the height is rolled back, without the times of the
+	// blocks above the height.
+	loaded, err = loadCoinAgeLedger(data, times[:21], 20)
+	if err != nil {
+		t.Fatalf("loadCoinAgeLedger failed to roll back: %v", err)
+	}
+	if !ledger.rollback(20) {
+		t.Fatalf("failed to roll back the ledger")
+	}
+	if loaded.tip() != 20 || !reflect.DeepEqual(loaded.unspent, ledger.unspent) ||
+		!reflect.DeepEqual(loaded.utxoAges(), ledger.utxoAges()) {
+		t.Fatalf("rolled back ledger %v does not match ledger %v", loaded.utxoAges(), ledger.utxoAges())
+	}
+
+	// A ledger that cannot be rolled back to the height is refused.
+	deep := newCoinAgeLedger()
+	deepTimes := make([]uint64, 2*coinAgeJournalDepth+10)
+	for h := range deepTimes {
+		deepTimes[h] = uint64(h) * 300
+		deep.connect(&coinAgeBlock{funded: coin}, deepTimes[h])
+	}
+	if _, err = loadCoinAgeLedger(deep.encode(), deepTimes, 0); err == nil {
+		t.Fatalf("loaded a ledger that cannot be rolled back")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"fmt"
 	"strings"
 
-	_ "github.com/lib/pq" // Start the PostgreSQL driver
+	"github.com/lib/pq" // also registers the "postgres" driver
 )
 
 // Connect opens a connection to a PostgreSQL database. The caller is
@@ -17,6 +17,11 @@ import (
 // The input host may be an IP address for TCP connection, or an absolute path
 // to a UNIX domain socket. An empty string should be provided for UNIX sockets.
 func Connect(host, port, user, pass, dbname string) (*sql.DB, error) {
+	return ConnectDSN(connString(host, port, user, pass, dbname))
+}
+
+// connString creates a connection string of key=value settings for Connect.
+func connString(host, port, user, pass, dbname string) string {
 	var psqlInfo string
 	if pass == "" {
 		psqlInfo = fmt.Sprintf("host=%s user=%s dbname=%s sslmode=disable",
@@ -32,10 +37,32 @@ func Connect(host, port, user, pass, dbname string) (*sql.DB, error) {
 		psqlInfo += fmt.Sprintf(" port=%s", port)
 	}
 
-	db, err := sql.Open("postgres", psqlInfo)
+	return psqlInfo
+}
+
+// ConnectDSN opens a connection to a PostgreSQL database given a connection
+// string, which may be either a space-separated list of key=value settings or
+// a postgres:// URL. The caller is responsible for calling Close() on the
+// returned db when finished using it.
+func ConnectDSN(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	return db, db.Ping()
 }
+
+// connectDSN is like ConnectDSN, but if sq is not nil, the slow queries on the
+// connection are recorded by sq.
+func connectDSN(dsn string, sq *slowQueryLog) (*sql.DB, error) {
+	if sq == nil {
+		return ConnectDSN(dsn)
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(&slowQueryConnector{connector, sq})
+	return db, db.Ping()
+}
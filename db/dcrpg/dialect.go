@@ -0,0 +1,201 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/dialect"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// pgDialect is the dialect of a ChainDB's PostgreSQL database.
+var pgDialect = &dialect.Dialect{
+	Name:    "PostgreSQL",
+	Analyze: AnalyzeAllTables,
+	StringArray: func(strs []string) interface{} {
+		return pq.Array(strs)
+	},
+	Int64Array: func(ints []int64) interface{} {
+		return pq.Int64Array(ints)
+	},
+	HashArray: func(hashes []dbtypes.ChainHash) interface{} {
+		return dbtypes.ChainHashArray(hashes)
+	},
+	Statements: dialect.Statements{
+		SelectVinsForAddress:                     internal.SelectVinsForAddress,
+		SelectVoutsForAddress:                    internal.SelectVoutsForAddress,
+		SelectAddressTxns:                        internal.SelectAddressTxns,
+		SelectAddressesMergedSpentCount:          internal.SelectAddressesMergedSpentCount,
+		SelectAddressesMergedFundingCount:        internal.SelectAddressesMergedFundingCount,
+		SelectAddressesMergedCount:               internal.SelectAddressesMergedCount,
+		SelectAddressSpentUnspentCountAndValue:   internal.SelectAddressSpentUnspentCountAndValue,
+		SelectAddressUnspentWithTxn:              internal.SelectAddressUnspentWithTxn,
+		SelectAddressLimitNByAddress:             internal.SelectAddressLimitNByAddress,
+		SelectAddressMergedView:                  internal.SelectAddressMergedView,
+		SelectAddressIDsByFundingOutpoint:        internal.SelectAddressIDsByFundingOutpoint,
+		SetAddressMatchingTxHashForOutpoint:      internal.SetAddressMatchingTxHashForOutpoint,
+		SetAddressMainchainForVoutIDs:            internal.SetAddressMainchainForVoutIDs,
+		SetAddressMainchainForVinIDs:             internal.SetAddressMainchainForVinIDs,
+		MakeAddressRowInsertStatement:            internal.MakeAddressRowInsertStatement,
+		MakeSelectAddressTxTypesByAddress:        internal.MakeSelectAddressTxTypesByAddress,
+		MakeSelectAddressAmountFlowByAddress:     internal.MakeSelectAddressAmountFlowByAddress,
+		SelectBlockByTimeRangeSQL:                internal.SelectBlockByTimeRangeSQL,
+		SelectBlockByTimeRangeSQLNoLimit:         internal.SelectBlockByTimeRangeSQLNoLimit,
+		SelectBlockHashByHeight:                  internal.SelectBlockHashByHeight,
+		SelectBlockHeightByHash:                  internal.SelectBlockHeightByHash,
+		SelectBlockTimeByHeight:                  internal.SelectBlockTimeByHeight,
+		SelectBlockHeightByTime:                  internal.SelectBlockHeightByTime,
+		RetrieveBestBlockHeight:                  internal.RetrieveBestBlockHeight,
+		SelectBlocksTicketsPrice:                 internal.SelectBlocksTicketsPrice,
+		SelectBlockVersionsByRCI:                 internal.SelectBlockVersionsByRCI,
+		SelectBlockStakeData:                     internal.SelectBlockStakeData,
+		SelectWindowsByLimit:                     internal.SelectWindowsByLimit,
+		SelectBlockVoteCount:                     internal.SelectBlockVoteCount,
+		SelectSideChainBlocks:                    internal.SelectSideChainBlocks,
+		SelectBlockStatus:                        internal.SelectBlockStatus,
+		SelectBlockStatuses:                      internal.SelectBlockStatuses,
+		SelectBlockFlags:                         internal.SelectBlockFlags,
+		SelectDisapprovedBlocks:                  internal.SelectDisapprovedBlocks,
+		UpdateLastBlockValid:                     internal.UpdateLastBlockValid,
+		UpdateBlockMainchain:                     internal.UpdateBlockMainchain,
+		InsertBlockPrevNext:                      internal.InsertBlockPrevNext,
+		SelectBlockChainRowIDByHash:              internal.SelectBlockChainRowIDByHash,
+		UpdateBlockNext:                          internal.UpdateBlockNext,
+		UpdateBlockNextByHash:                    internal.UpdateBlockNextByHash,
+		UpdateBlockNextByNextHash:                internal.UpdateBlockNextByNextHash,
+		SelectBlockStats:                         internal.SelectBlockStats,
+		SelectBlockDataByHeight:                  internal.SelectBlockDataByHeight,
+		SelectBlockDataRange:                     internal.SelectBlockDataRange,
+		SelectBlockDataRangeDesc:                 internal.SelectBlockDataRangeDesc,
+		SelectBlockDataRangeWithSkip:             internal.SelectBlockDataRangeWithSkip,
+		SelectBlockDataRangeWithSkipDesc:         internal.SelectBlockDataRangeWithSkipDesc,
+		SelectBlockDataByHash:                    internal.SelectBlockDataByHash,
+		SelectBlockDataBest:                      internal.SelectBlockDataBest,
+		SelectBlockSizeByHeight:                  internal.SelectBlockSizeByHeight,
+		SelectBlockSizeRange:                     internal.SelectBlockSizeRange,
+		SelectSBitsByHeight:                      internal.SelectSBitsByHeight,
+		SelectSBitsByHash:                        internal.SelectSBitsByHash,
+		SelectSBitsRange:                         internal.SelectSBitsRange,
+		SelectDiffByTime:                         internal.SelectDiffByTime,
+		BlockInsertStatement:                     internal.BlockInsertStatement,
+		MakeSelectBlocksTimeListingByLimit:       internal.MakeSelectBlocksTimeListingByLimit,
+		UpsertExchangeRate:                       internal.UpsertExchangeRate,
+		SelectExchangeRateAt:                     internal.SelectExchangeRateAt,
+		SelectExchangeRateMean:                   internal.SelectExchangeRateMean,
+		SelectMetaDBBestBlock:                    internal.SelectMetaDBBestBlock,
+		SetMetaDBBestBlock:                       internal.SetMetaDBBestBlock,
+		SelectMetaDBIbdComplete:                  internal.SelectMetaDBIbdComplete,
+		SetMetaDBIbdComplete:                     internal.SetMetaDBIbdComplete,
+		DeleteAddressesSubQry:                    internal.DeleteAddressesSubQry,
+		DeleteVinsSubQry:                         internal.DeleteVinsSubQry,
+		DeleteVoutsSubQry:                        internal.DeleteVoutsSubQry,
+		DeleteMisses:                             internal.DeleteMisses,
+		DeleteVotes:                              internal.DeleteVotes,
+		DeleteTickets:                            internal.DeleteTickets,
+		DeleteTransactionsSimple:                 internal.DeleteTransactionsSimple,
+		DeleteTreasuryTxns:                       internal.DeleteTreasuryTxns,
+		DeleteSwaps:                              internal.DeleteSwaps,
+		DeleteBlock:                              internal.DeleteBlock,
+		DeleteBlockFromChain:                     internal.DeleteBlockFromChain,
+		SelectTicketIDByHash:                     internal.SelectTicketIDByHash,
+		SelectTicketStatusByHash:                 internal.SelectTicketStatusByHash,
+		SelectTicketInfoByHash:                   internal.SelectTicketInfoByHash,
+		SelectUnspentTickets:                     internal.SelectUnspentTickets,
+		SelectTicketsByPrice:                     internal.SelectTicketsByPrice,
+		SelectTicketSpendTypeByBlock:             internal.SelectTicketSpendTypeByBlock,
+		SetTicketSpendingInfoForTicketDbID:       internal.SetTicketSpendingInfoForTicketDbID,
+		SetTicketPoolStatusForTicketDbID:         internal.SetTicketPoolStatusForTicketDbID,
+		UpdateTicketsMainchainByBlock:            internal.UpdateTicketsMainchainByBlock,
+		SelectVSPTicketSpends:                    internal.SelectVSPTicketSpends,
+		SelectTicketSpendAddresses:               internal.SelectTicketSpendAddresses,
+		SelectMeanVoteAges:                       internal.SelectMeanVoteAges,
+		UpdateVotesMainchainByBlock:              internal.UpdateVotesMainchainByBlock,
+		SelectMissesInBlock:                      internal.SelectMissesInBlock,
+		SelectMissesMainchainForTicket:           internal.SelectMissesMainchainForTicket,
+		SelectMissCountPerBlock:                  internal.SelectMissCountPerBlock,
+		SelectMissCountForBlockRange:             internal.SelectMissCountForBlockRange,
+		SelectAllAgendas:                         internal.SelectAllAgendas,
+		SelectAgendasVotesByTime:                 internal.SelectAgendasVotesByTime,
+		SelectAgendasVotesByHeight:               internal.SelectAgendasVotesByHeight,
+		SelectAgendaVoteTotals:                   internal.SelectAgendaVoteTotals,
+		SelectAgendaVotesByRCI:                   internal.SelectAgendaVotesByRCI,
+		SelectVoteVersionsByRCI:                  internal.SelectVoteVersionsByRCI,
+		MakeTicketInsertStatement:                internal.MakeTicketInsertStatement,
+		MakeVoteInsertStatement:                  internal.MakeVoteInsertStatement,
+		MakeMissInsertStatement:                  internal.MakeMissInsertStatement,
+		MakeAgendaInsertStatement:                internal.MakeAgendaInsertStatement,
+		MakeAgendaVotesInsertStatement:           internal.MakeAgendaVotesInsertStatement,
+		MakeSelectTicketsByPurchaseDate:          internal.MakeSelectTicketsByPurchaseDate,
+		UpsertStats:                              internal.UpsertStats,
+		SelectPoolInfo:                           internal.SelectPoolInfo,
+		SelectPoolStatsAboveHeight:               internal.SelectPoolStatsAboveHeight,
+		SelectPoolInfoByHeight:                   internal.SelectPoolInfoByHeight,
+		SelectPoolInfoRange:                      internal.SelectPoolInfoRange,
+		SelectPoolValSizeRange:                   internal.SelectPoolValSizeRange,
+		InsertContractSpend:                      internal.InsertContractSpend,
+		UpdateTreasuryMainchainByBlock:           internal.UpdateTreasuryMainchainByBlock,
+		SelectTreasuryTxns:                       internal.SelectTreasuryTxns,
+		SelectTypedTreasuryTxns:                  internal.SelectTypedTreasuryTxns,
+		SelectTreasuryBalance:                    internal.SelectTreasuryBalance,
+		SelectTreasuryAddInputs:                  internal.SelectTreasuryAddInputs,
+		SelectTreasurySpendPayouts:               internal.SelectTreasurySpendPayouts,
+		MakeTreasuryInsertStatement:              internal.MakeTreasuryInsertStatement,
+		MakeSelectTreasuryIOStatement:            internal.MakeSelectTreasuryIOStatement,
+		MakeSelectTreasuryStatementTotals:        internal.MakeSelectTreasuryStatementTotals,
+		SelectTxByHash:                           internal.SelectTxByHash,
+		SelectTxsByBlockHash:                     internal.SelectTxsByBlockHash,
+		SelectTxBlockTimeByHash:                  internal.SelectTxBlockTimeByHash,
+		SelectFullTxByHash:                       internal.SelectFullTxByHash,
+		SelectFullTxsByHash:                      internal.SelectFullTxsByHash,
+		SelectTxnsVinsVoutsByBlock:               internal.SelectTxnsVinsVoutsByBlock,
+		SelectRegularTxnsVinsVoutsByBlock:        internal.SelectRegularTxnsVinsVoutsByBlock,
+		SelectTxsBlocks:                          internal.SelectTxsBlocks,
+		UpdateRegularTxnsValidByBlock:            internal.UpdateRegularTxnsValidByBlock,
+		UpdateTxnsMainchainByBlock:               internal.UpdateTxnsMainchainByBlock,
+		SelectTicketsByType:                      internal.SelectTicketsByType,
+		SelectTxnByDbID:                          internal.SelectTxnByDbID,
+		SelectFeesPerBlockAboveHeight:            internal.SelectFeesPerBlockAboveHeight,
+		SelectMixedTotalPerBlock:                 internal.SelectMixedTotalPerBlock,
+		SelectMixedVouts:                         internal.SelectMixedVouts,
+		SelectCoinAgeEvents:                      internal.SelectCoinAgeEvents,
+		MakeTxInsertStatement:                    internal.MakeTxInsertStatement,
+		SelectSpendingTxsByPrevTx:                internal.SelectSpendingTxsByPrevTx,
+		SelectSpendingTxsByPrevTxWithBlockHeight: internal.SelectSpendingTxsByPrevTxWithBlockHeight,
+		SelectSpendingTxByPrevOut:                internal.SelectSpendingTxByPrevOut,
+		SelectFundingOutpointIndxByVinID:         internal.SelectFundingOutpointIndxByVinID,
+		SelectAllVinInfoByID:                     internal.SelectAllVinInfoByID,
+		SelectUTXOs:                              internal.SelectUTXOs,
+		SetIsValidIsMainchainByTxHash:            internal.SetIsValidIsMainchainByTxHash,
+		SetIsMainchainByVinID:                    internal.SetIsMainchainByVinID,
+		SelectCoinSupply:                         internal.SelectCoinSupply,
+		UpdateVoutSpendTxRowID:                   internal.UpdateVoutSpendTxRowID,
+		UpdateVoutsSpendTxRowID:                  internal.UpdateVoutsSpendTxRowID,
+		ResetVoutSpendTxRowIDs:                   internal.ResetVoutSpendTxRowIDs,
+		SelectVoutAddressesByTxOut:               internal.SelectVoutAddressesByTxOut,
+		SelectVoutByID:                           internal.SelectVoutByID,
+		RetrieveVoutValue:                        internal.RetrieveVoutValue,
+		RetrieveVoutValues:                       internal.RetrieveVoutValues,
+		MakeVinInsertStatement:                   internal.MakeVinInsertStatement,
+		MakeVoutInsertStatement:                  internal.MakeVoutInsertStatement,
+	},
+}
+
+// queries are the queries of a ChainDB, which use the statements of its
+// database's dialect.
+type queries struct {
+	*dialect.Dialect
+}
+
+// pgQueries are the queries of a PostgreSQL database, for the code that only
+// supports PostgreSQL, such as the schema upgrades and read replicas.
+var pgQueries = queries{pgDialect}
+
+// postgres indicates whether the database is PostgreSQL. Table partitioning,
+// read replicas, and bulk loading without indexes are only supported for
+// PostgreSQL.
+func (q queries) postgres() bool {
+	return q.Dialect == pgDialect
+}
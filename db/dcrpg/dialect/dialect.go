@@ -0,0 +1,219 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package dialect defines the SQL dialect of a dcrpg.ChainDB's database, which
+// allows a ChainDB to store and query the blockchain data in a database other
+// than PostgreSQL. A database package supplies the schema, the statements, and
+// the connection of its database as a Dialect.
+package dialect
+
+import (
+	"database/sql"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// Dialect is the SQL dialect and connection of a ChainDB's database.
+type Dialect struct {
+	// Name is the name of the database system, for logging.
+	Name string
+	// Open connects to the database, creating its tables or upgrading them to
+	// the current schema as needed. The tables of a new database are set up
+	// for the network. Open is nil for PostgreSQL, which is connected with the
+	// dcrpg.ChainDBCfg's DBInfo.
+	Open func(params *chaincfg.Params) (*sql.DB, error)
+	// Analyze updates the statistics used by the query planner for all
+	// tables. The statistics target may be ignored.
+	Analyze func(db *sql.DB, statisticsTarget int) error
+	// StringArray, Int64Array, and HashArray encode the array arguments of
+	// the statements.
+	StringArray func([]string) interface{}
+	Int64Array  func([]int64) interface{}
+	HashArray   func([]dbtypes.ChainHash) interface{}
+
+	Statements
+}
+
+// Statements are the SQL statements of a Dialect. The functions return the
+// statement for their arguments. A dialect may return the same statement for
+// any value of the checked and partitioned arguments if its inserts always
+// check for conflicts and its tables are not partitioned.
+type Statements struct {
+	// Statements for the addresses table.
+	SelectVinsForAddress                   string
+	SelectVoutsForAddress                  string
+	SelectAddressTxns                      string
+	SelectAddressesMergedSpentCount        string
+	SelectAddressesMergedFundingCount      string
+	SelectAddressesMergedCount             string
+	SelectAddressSpentUnspentCountAndValue string
+	SelectAddressUnspentWithTxn            string
+	SelectAddressLimitNByAddress           string
+	SelectAddressMergedView                string
+	SelectAddressIDsByFundingOutpoint      string
+	SetAddressMatchingTxHashForOutpoint    string
+	SetAddressMainchainForVoutIDs          string
+	SetAddressMainchainForVinIDs           string
+	MakeAddressRowInsertStatement          func(checked, updateOnConflict, partitioned bool) string
+	MakeSelectAddressTxTypesByAddress      func(group string) string
+	MakeSelectAddressAmountFlowByAddress   func(group string) string
+
+	// Statements for the blocks and block_chain tables.
+	SelectBlockByTimeRangeSQL          string
+	SelectBlockByTimeRangeSQLNoLimit   string
+	SelectBlockHashByHeight            string
+	SelectBlockHeightByHash            string
+	SelectBlockTimeByHeight            string
+	SelectBlockHeightByTime            string
+	RetrieveBestBlockHeight            string
+	SelectBlocksTicketsPrice           string
+	SelectBlockVersionsByRCI           string
+	SelectBlockStakeData               string
+	SelectWindowsByLimit               string
+	SelectBlockVoteCount               string
+	SelectSideChainBlocks              string
+	SelectBlockStatus                  string
+	SelectBlockStatuses                string
+	SelectBlockFlags                   string
+	SelectDisapprovedBlocks            string
+	UpdateLastBlockValid               string
+	UpdateBlockMainchain               string
+	InsertBlockPrevNext                string
+	SelectBlockChainRowIDByHash        string
+	UpdateBlockNext                    string
+	UpdateBlockNextByHash              string
+	UpdateBlockNextByNextHash          string
+	SelectBlockStats                   string
+	SelectBlockDataByHeight            string
+	SelectBlockDataRange               string
+	SelectBlockDataRangeDesc           string
+	SelectBlockDataRangeWithSkip       string
+	SelectBlockDataRangeWithSkipDesc   string
+	SelectBlockDataByHash              string
+	SelectBlockDataBest                string
+	SelectBlockSizeByHeight            string
+	SelectBlockSizeRange               string
+	SelectSBitsByHeight                string
+	SelectSBitsByHash                  string
+	SelectSBitsRange                   string
+	SelectDiffByTime                   string
+	BlockInsertStatement               func(checked bool) string
+	MakeSelectBlocksTimeListingByLimit func(group string) string
+
+	// Statements for the exchange_rates table.
+	UpsertExchangeRate     string
+	SelectExchangeRateAt   string
+	SelectExchangeRateMean string
+
+	// Statements for the meta table.
+	SelectMetaDBBestBlock   string
+	SetMetaDBBestBlock      string
+	SelectMetaDBIbdComplete string
+	SetMetaDBIbdComplete    string
+
+	// Statements for the deletion of the data of a block.
+	DeleteAddressesSubQry    string
+	DeleteVinsSubQry         string
+	DeleteVoutsSubQry        string
+	DeleteMisses             string
+	DeleteVotes              string
+	DeleteTickets            string
+	DeleteTransactionsSimple string
+	DeleteTreasuryTxns       string
+	DeleteSwaps              string
+	DeleteBlock              string
+	DeleteBlockFromChain     string
+
+	// Statements for the tickets, votes, misses, agendas, and agenda_votes tables.
+	SelectTicketIDByHash               string
+	SelectTicketStatusByHash           string
+	SelectTicketInfoByHash             string
+	SelectUnspentTickets               string
+	SelectTicketsByPrice               string
+	SelectTicketSpendTypeByBlock       string
+	SetTicketSpendingInfoForTicketDbID string
+	SetTicketPoolStatusForTicketDbID   string
+	UpdateTicketsMainchainByBlock      string
+	SelectVSPTicketSpends              string
+	SelectTicketSpendAddresses         string
+	SelectMeanVoteAges                 string
+	UpdateVotesMainchainByBlock        string
+	SelectMissesInBlock                string
+	SelectMissesMainchainForTicket     string
+	SelectMissCountPerBlock            string
+	SelectMissCountForBlockRange       string
+	SelectAllAgendas                   string
+	SelectAgendasVotesByTime           string
+	SelectAgendasVotesByHeight         string
+	SelectAgendaVoteTotals             string
+	SelectAgendaVotesByRCI             string
+	SelectVoteVersionsByRCI            string
+	MakeTicketInsertStatement          func(checked, updateOnConflict bool) string
+	MakeVoteInsertStatement            func(checked, updateOnConflict bool) string
+	MakeMissInsertStatement            func(checked, updateOnConflict bool) string
+	MakeAgendaInsertStatement          func(checked bool) string
+	MakeAgendaVotesInsertStatement     func(checked bool) string
+	MakeSelectTicketsByPurchaseDate    func(group string) string
+
+	// Statements for the stats table.
+	UpsertStats                string
+	SelectPoolInfo             string
+	SelectPoolStatsAboveHeight string
+	SelectPoolInfoByHeight     string
+	SelectPoolInfoRange        string
+	SelectPoolValSizeRange     string
+
+	// Statements for the swaps table.
+	InsertContractSpend string
+
+	// Statements for the treasury table.
+	UpdateTreasuryMainchainByBlock    string
+	SelectTreasuryTxns                string
+	SelectTypedTreasuryTxns           string
+	SelectTreasuryBalance             string
+	SelectTreasuryAddInputs           string
+	SelectTreasurySpendPayouts        string
+	MakeTreasuryInsertStatement       func(checked, updateOnConflict bool) string
+	MakeSelectTreasuryIOStatement     func(group string) string
+	MakeSelectTreasuryStatementTotals func(group string) string
+
+	// Statements for the transactions table.
+	SelectTxByHash                    string
+	SelectTxsByBlockHash              string
+	SelectTxBlockTimeByHash           string
+	SelectFullTxByHash                string
+	SelectFullTxsByHash               string
+	SelectTxnsVinsVoutsByBlock        string
+	SelectRegularTxnsVinsVoutsByBlock string
+	SelectTxsBlocks                   string
+	UpdateRegularTxnsValidByBlock     string
+	UpdateTxnsMainchainByBlock        string
+	SelectTicketsByType               string
+	SelectTxnByDbID                   string
+	SelectFeesPerBlockAboveHeight     string
+	SelectMixedTotalPerBlock          string
+	SelectMixedVouts                  string
+	SelectCoinAgeEvents               string
+	MakeTxInsertStatement             func(checked, updateOnConflict bool) string
+
+	// Statements for the vins and vouts tables.
+	SelectSpendingTxsByPrevTx                string
+	SelectSpendingTxsByPrevTxWithBlockHeight string
+	SelectSpendingTxByPrevOut                string
+	SelectFundingOutpointIndxByVinID         string
+	SelectAllVinInfoByID                     string
+	SelectUTXOs                              string
+	SetIsValidIsMainchainByTxHash            string
+	SetIsMainchainByVinID                    string
+	SelectCoinSupply                         string
+	UpdateVoutSpendTxRowID                   string
+	UpdateVoutsSpendTxRowID                  string
+	ResetVoutSpendTxRowIDs                   string
+	SelectVoutAddressesByTxOut               string
+	SelectVoutByID                           string
+	RetrieveVoutValue                        string
+	RetrieveVoutValues                       string
+	MakeVinInsertStatement                   func(checked, updateOnConflict, partitioned bool) string
+	MakeVoutInsertStatement                  func(checked, updateOnConflict, partitioned bool) string
+}
@@ -0,0 +1,103 @@
+//go:build pgonline
+
+package dcrpg
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+)
+
+// TestDialectStatements checks that the PostgreSQL dialect sets each of its
+// statements, and that they are valid for the tables and indexes of an
+// unpartitioned and a partitioned database.
+func TestDialectStatements(t *testing.T) {
+	scratch := openScratchDB(t, "dcrdata_dialect_test")
+	if err := CreateTables(scratch, false); err != nil {
+		t.Fatal(err)
+	}
+	pgb := &ChainDB{db: scratch}
+	if err := pgb.IndexAll(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := pgb.IndexTicketsTable(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := pgb.IndexAddressTable(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The vins, vouts and addresses makers take a last flag for the
+	// partitioned tables, so those variants are checked after partitioning.
+	partitionedVariant := func(f reflect.Value, args []reflect.Value) bool {
+		n := f.Type().NumIn()
+		return n == 3 && args[n-1].Bool()
+	}
+	prepareStatements(t, scratch, func(f reflect.Value, args []reflect.Value) bool {
+		return !partitionedVariant(f, args)
+	})
+
+	genesis := chaincfg.MainNetParams().GenesisBlock.Header.Timestamp
+	tp := newTablePartitions(&PartitionCfg{Months: 1, VoutRows: 10}, genesis)
+	if err := partitionTables(scratch, tp); err != nil {
+		t.Fatal(err)
+	}
+	prepareStatements(t, scratch, partitionedVariant)
+}
+
+// prepareStatements prepares each of the dialect's statements, calling the
+// statement makers with each combination of their arguments, and skipping
+// the variants that want rejects.
+func prepareStatements(t *testing.T, db *sql.DB, want func(f reflect.Value, args []reflect.Value) bool) {
+	t.Helper()
+	stmts := reflect.ValueOf(pgDialect.Statements)
+	for i := 0; i < stmts.NumField(); i++ {
+		name, f := stmts.Type().Field(i).Name, stmts.Field(i)
+		if f.IsZero() {
+			t.Errorf("%s is not set", name)
+			continue
+		}
+
+		var variants []string
+		switch f.Kind() {
+		case reflect.String:
+			if want(f, nil) {
+				variants = []string{f.String()}
+			}
+		case reflect.Func:
+			// The makers' arguments are either flags or a time grouping.
+			args := [][]reflect.Value{nil}
+			for j := 0; j < f.Type().NumIn(); j++ {
+				var vals []reflect.Value
+				if f.Type().In(j).Kind() == reflect.String {
+					vals = []reflect.Value{reflect.ValueOf("all"), reflect.ValueOf("day")}
+				} else {
+					vals = []reflect.Value{reflect.ValueOf(false), reflect.ValueOf(true)}
+				}
+				var next [][]reflect.Value
+				for _, a := range args {
+					for _, v := range vals {
+						next = append(next, append(append([]reflect.Value{}, a...), v))
+					}
+				}
+				args = next
+			}
+			for _, a := range args {
+				if want(f, a) {
+					variants = append(variants, f.Call(a)[0].String())
+				}
+			}
+		}
+
+		for _, stmt := range variants {
+			s, err := db.Prepare(stmt)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			s.Close()
+		}
+	}
+}
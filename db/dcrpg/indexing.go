@@ -26,8 +26,18 @@ type deIndexingInfo struct {
 
 // Vins table indexes
 
+// IndexVinTableOnVins creates the index for the vins table over transaction
+// hash, index, and tree. The index is unique unless the table is partitioned.
 func IndexVinTableOnVins(db *sql.DB) (err error) {
-	_, err = db.Exec(internal.IndexVinTableOnVins)
+	partitioned, err := tableIsPartitioned(db, "vins")
+	if err != nil {
+		return err
+	}
+	stmt := internal.IndexVinTableOnVins
+	if partitioned {
+		stmt = internal.IndexVinTableOnVinsPartitioned
+	}
+	_, err = db.Exec(stmt)
 	return
 }
 
@@ -113,9 +123,18 @@ func DeindexBlockTableOnTime(db *sql.DB) (err error) {
 // vouts table indexes
 
 // IndexVoutTableOnTxHashIdx creates the index for the addresses table over
-// transaction hash and index.
+// transaction hash and index. The index is unique unless the table is
+// partitioned.
 func IndexVoutTableOnTxHashIdx(db *sql.DB) (err error) {
-	_, err = db.Exec(internal.IndexVoutTableOnTxHashIdx)
+	partitioned, err := tableIsPartitioned(db, "vouts")
+	if err != nil {
+		return err
+	}
+	stmt := internal.IndexVoutTableOnTxHashIdx
+	if partitioned {
+		stmt = internal.IndexVoutTableOnTxHashIdxPartitioned
+	}
+	_, err = db.Exec(stmt)
 	return
 }
 
@@ -173,9 +192,17 @@ func DeindexAddressTableOnAddress(db *sql.DB) (err error) {
 }
 
 // IndexAddressTableOnVoutID creates the index for the addresses table over
-// vout row ID.
+// vout row ID. The index is unique unless the table is partitioned.
 func IndexAddressTableOnVoutID(db *sql.DB) (err error) {
-	_, err = db.Exec(internal.IndexAddressTableOnVoutID)
+	partitioned, err := tableIsPartitioned(db, "addresses")
+	if err != nil {
+		return err
+	}
+	stmt := internal.IndexAddressTableOnVoutID
+	if partitioned {
+		stmt = internal.IndexAddressTableOnVoutIDPartitioned
+	}
+	_, err = db.Exec(stmt)
 	return
 }
 
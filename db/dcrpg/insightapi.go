@@ -115,9 +115,9 @@ func (pgb *ChainDB) OutpointAddresses(txHash string, voutIndex uint32) ([]string
 	if err != nil {
 		return nil, 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("OutpointAddresses")
 	defer cancel()
-	_, addrs, val, err := retrieveAddressIDsByOutpoint(ctx, pgb.db, ch, voutIndex)
+	_, addrs, val, err := pgb.q.retrieveAddressIDsByOutpoint(ctx, pgb.db, ch, voutIndex)
 	return addrs, val, pgb.replaceCancelError(err)
 }
 
@@ -176,9 +176,9 @@ func makeBlockTransactions(blockVerbose *chainjson.GetBlockVerboseResult) *apity
 // GetBlockHash returns the hash of the block at the specified height. TODO:
 // create GetBlockHashes to return all blocks at a given height.
 func (pgb *ChainDB) GetBlockHash(idx int64) (string, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("GetBlockHash")
 	defer cancel()
-	hash, err := retrieveBlockHash(ctx, pgb.db, idx)
+	hash, err := pgb.q.retrieveBlockHash(ctx, pgb.db, idx)
 	if err != nil {
 		log.Errorf("Unable to get block hash for block number %d: %v", idx, err)
 		return "", pgb.replaceCancelError(err)
@@ -189,9 +189,9 @@ func (pgb *ChainDB) GetBlockHash(idx int64) (string, error) {
 // BlockSummaryTimeRange returns the blocks created within a specified time
 // range (min, max time), up to limit transactions.
 func (pgb *ChainDB) BlockSummaryTimeRange(min, max int64, limit int) ([]dbtypes.BlockDataBasic, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockSummaryTimeRange")
 	defer cancel()
-	blockSummary, err := retrieveBlockSummaryByTimeRange(ctx, pgb.db, min, max, limit)
+	blockSummary, err := pgb.q.retrieveBlockSummaryByTimeRange(ctx, pgb.db, min, max, limit)
 	return blockSummary, pgb.replaceCancelError(err)
 }
 
@@ -247,9 +247,9 @@ func (pgb *ChainDB) AddressUTXO(address string) ([]*dbtypes.AddressTxnOutput, bo
 	}
 
 	// Query the DB for the current UTXO set for this address.
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AddressUTXO")
 	defer cancel()
-	txnOutputs, err := retrieveAddressDbUTXOs(ctx, pgb.db, address)
+	txnOutputs, err := pgb.q.retrieveAddressDbUTXOs(ctx, pgb.db, address)
 	if err != nil {
 		return nil, false, pgb.replaceCancelError(err)
 	}
@@ -277,9 +277,9 @@ func (pgb *ChainDB) SpendDetailsForFundingTx(fundHash string) ([]*apitypes.Spend
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("SpendDetailsForFundingTx")
 	defer cancel()
-	addrRow, err := retrieveSpendingTxsByFundingTxWithBlockHeight(ctx, pgb.db, ch)
+	addrRow, err := pgb.q.retrieveSpendingTxsByFundingTxWithBlockHeight(ctx, pgb.db, ch)
 	if err != nil {
 		return nil, pgb.replaceCancelError(err)
 	}
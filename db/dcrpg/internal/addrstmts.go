@@ -26,6 +26,26 @@ const (
 		tx_type INT4
 	);`
 
+	// CreateAddressTablePartitioned is like CreateAddressTable, but it creates
+	// a table partitioned by range on block_time. The partitions themselves
+	// are not created here.
+	CreateAddressTablePartitioned = `CREATE SEQUENCE IF NOT EXISTS addresses_id_seq;
+	CREATE TABLE IF NOT EXISTS addresses (
+		id INT8 NOT NULL DEFAULT nextval('addresses_id_seq'),
+		address TEXT,
+		tx_hash BYTEA,
+		valid_mainchain BOOLEAN,
+		matching_tx_hash BYTEA,
+		value INT8,
+		block_time TIMESTAMPTZ NOT NULL,
+		is_funding BOOLEAN,
+		tx_vin_vout_index INT4,
+		tx_vin_vout_row_id INT8,
+		tx_type INT4,
+		PRIMARY KEY (id, block_time)
+	) PARTITION BY RANGE (block_time);
+	ALTER SEQUENCE addresses_id_seq OWNED BY addresses.id;`
+
 	// insertAddressRow is the basis for several address insert/upsert
 	// statements.
 	insertAddressRow = `INSERT INTO addresses (address, matching_tx_hash, tx_hash,
@@ -59,11 +79,59 @@ const (
 		WHERE  address = $1 AND is_funding = $8 AND tx_vin_vout_row_id = $5 -- only executed if no INSERT
 		LIMIT  1;`
 
+	// selectAddressRowValues casts the insert arguments so their types are
+	// known when they only appear in the SELECT list of an INSERT.
+	selectAddressRowValues = `SELECT $1::TEXT, $2::BYTEA, $3::BYTEA, $4::INT4, $5::INT8,
+		$6::INT8, $7::TIMESTAMPTZ, $8::BOOLEAN, $9::BOOLEAN, $10::INT4`
+
+	// UpsertAddressRowPartitioned is the UpsertAddressRow counterpart for a
+	// partitioned addresses table, which cannot have the unique index needed
+	// for ON CONFLICT. This is only safe with a single writer.
+	UpsertAddressRowPartitioned = `WITH updated AS (
+			UPDATE addresses SET matching_tx_hash = $2, tx_hash = $3, tx_vin_vout_index = $4,
+				block_time = $7, valid_mainchain = $9
+			WHERE tx_vin_vout_row_id = $5 AND address = $1 AND is_funding = $8
+			RETURNING id
+		), inserting AS (
+			INSERT INTO addresses (address, matching_tx_hash, tx_hash,
+				tx_vin_vout_index, tx_vin_vout_row_id, value, block_time, is_funding, valid_mainchain, tx_type) ` +
+		selectAddressRowValues + `
+			WHERE NOT EXISTS (SELECT 1 FROM updated)
+			RETURNING id
+		)
+		SELECT id FROM updated
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// InsertAddressRowPartitionedDoNothing is the
+	// InsertAddressRowOnConflictDoNothing counterpart for a partitioned
+	// addresses table.
+	InsertAddressRowPartitionedDoNothing = `WITH existing AS (
+			SELECT id FROM addresses
+			WHERE tx_vin_vout_row_id = $5 AND address = $1 AND is_funding = $8
+			LIMIT 1
+		), inserting AS (
+			INSERT INTO addresses (address, matching_tx_hash, tx_hash,
+				tx_vin_vout_index, tx_vin_vout_row_id, value, block_time, is_funding, valid_mainchain, tx_type) ` +
+		selectAddressRowValues + `
+			WHERE NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING id
+		)
+		SELECT id FROM existing
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
 	// IndexAddressTableOnVoutID creates the unique index uix_addresses_vout_id
 	// on (tx_vin_vout_row_id, address, is_funding).
 	IndexAddressTableOnVoutID = `CREATE UNIQUE INDEX IF NOT EXISTS ` + IndexOfAddressTableOnVoutID +
 		` ON addresses(tx_vin_vout_row_id, address, is_funding);`
 	DeindexAddressTableOnVoutID = `DROP INDEX IF EXISTS ` + IndexOfAddressTableOnVoutID + ` CASCADE;`
+	// IndexAddressTableOnVoutIDPartitioned is the non-unique version of
+	// IndexAddressTableOnVoutID for a partitioned addresses table.
+	IndexAddressTableOnVoutIDPartitioned = `CREATE INDEX IF NOT EXISTS ` + IndexOfAddressTableOnVoutID +
+		` ON addresses(tx_vin_vout_row_id, address, is_funding);`
 
 	// IndexBlockTimeOnTableAddress creates a sorted index on block_time, which
 	// accelerates queries with ORDER BY block_time LIMIT n OFFSET m.
@@ -400,11 +468,18 @@ const (
 // constraint. For updateOnConflict=true, an upsert statement will be provided
 // that UPDATEs the conflicting row. For updateOnConflict=false, the statement
 // will either insert or do nothing, and return the inserted (new) or
-// conflicting (unmodified) row id.
-func MakeAddressRowInsertStatement(checked, updateOnConflict bool) string {
+// conflicting (unmodified) row id. For partitioned=true, the statements for a
+// partitioned addresses table are used when checked=true.
+func MakeAddressRowInsertStatement(checked, updateOnConflict, partitioned bool) string {
 	if !checked {
 		return InsertAddressRow
 	}
+	if partitioned {
+		if updateOnConflict {
+			return UpsertAddressRowPartitioned
+		}
+		return InsertAddressRowPartitionedDoNothing
+	}
 	if updateOnConflict {
 		return UpsertAddressRow
 	}
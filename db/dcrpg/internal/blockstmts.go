@@ -107,6 +107,13 @@ const (
 	SelectBlockTimeByHeight = `SELECT time FROM blocks
 		WHERE height = $1 AND is_mainchain = true;`
 
+	// SelectBlockHeightByTime selects the height of the first mainchain block
+	// at or after a time.
+	SelectBlockHeightByTime = `SELECT height FROM blocks
+		WHERE time >= $1 AND is_mainchain = true
+		ORDER BY time
+		LIMIT 1;`
+
 	// RetrieveBestBlockHeightAny = `SELECT id, hash, height FROM blocks
 	// 	ORDER BY height DESC LIMIT 1;`
 	RetrieveBestBlockHeight = `SELECT id, hash, height FROM blocks
@@ -119,6 +126,22 @@ const (
 		WHERE height > $1
 		ORDER BY height;`
 
+	// SelectBlockVersionsByRCI counts the mainchain blocks of each version in
+	// each rule change interval, numbered from the stake validation height ($1)
+	// by the interval length ($2).
+	SelectBlockVersionsByRCI = `SELECT (height - $1) / $2 AS rci, version, count(*)
+		FROM blocks
+		WHERE height >= $3 AND height <= $4 AND is_mainchain
+		GROUP BY rci, version
+		ORDER BY rci, version;`
+
+	// SelectBlockStakeData selects the ticket price, ticket purchases, and
+	// pool size of the mainchain blocks from a height.
+	SelectBlockStakeData = `SELECT height, time, sbits, fresh_stake, pool_size
+		FROM blocks
+		WHERE height >= $1 AND is_mainchain
+		ORDER BY height;`
+
 	SelectWindowsByLimit = `SELECT (height/$1)*$1 AS window_start,
 		MAX(difficulty) AS difficulty,
 		SUM(num_rtx) AS txs,
@@ -135,7 +158,7 @@ const (
 		GROUP BY window_start
 		ORDER BY window_start DESC;`
 
-	SelectBlocksTimeListingByLimit = `SELECT date_trunc($1, time at time zone 'utc') as index_value,
+	selectBlocksTimeListingByLimit = `SELECT %s as index_value,
 		MAX(height),
 		SUM(num_rtx) AS txs,
 		SUM(fresh_stake) AS tickets,
@@ -148,7 +171,7 @@ const (
 		FROM blocks
 		GROUP BY index_value
 		ORDER BY index_value DESC
-		LIMIT $2 OFFSET $3;`
+		LIMIT $1 OFFSET $2;`
 
 	// SelectBlocksPreviousHash = `SELECT previous_hash FROM blocks WHERE hash = $1;`
 
@@ -312,3 +335,9 @@ func BlockInsertStatement(checked bool) string {
 	}
 	return InsertBlockRow
 }
+
+// MakeSelectBlocksTimeListingByLimit returns the selectBlocksTimeListingByLimit
+// query for the given time grouping.
+func MakeSelectBlocksTimeListingByLimit(group string) string {
+	return formatGroupingQuery(selectBlocksTimeListingByLimit, group, "time at time zone 'utc'")
+}
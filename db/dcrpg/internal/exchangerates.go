@@ -0,0 +1,45 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "exchange_rates" table, the history of
+// the DCR price in the index currencies of the exchange bot.
+const (
+	CreateExchangeRatesTable = `CREATE TABLE IF NOT EXISTS exchange_rates (
+		id SERIAL PRIMARY KEY,
+		currency TEXT NOT NULL,
+		start_time TIMESTAMPTZ NOT NULL,
+		price FLOAT8,
+		volume FLOAT8,
+		backfilled BOOLEAN,
+		UNIQUE (currency, start_time)
+	);`
+
+	// UpsertExchangeRate inserts the price of an interval. A recorded price
+	// replaces any price stored for the interval, but a backfilled price is
+	// only inserted if no price is stored for the interval.
+	UpsertExchangeRate = `INSERT INTO exchange_rates (currency, start_time, price, volume, backfilled)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (currency, start_time) DO UPDATE
+		SET price = $3, volume = $4, backfilled = $5
+		WHERE NOT $5;`
+
+	// SelectExchangeRateAt selects the latest price of the currency for an
+	// interval starting after $2 and at or before $3.
+	SelectExchangeRateAt = `SELECT currency, start_time, price, volume, backfilled
+		FROM exchange_rates
+		WHERE currency = $1 AND start_time > $2 AND start_time <= $3
+		ORDER BY start_time DESC
+		LIMIT 1;`
+
+	// SelectExchangeRateMean selects the mean price of the currency over the
+	// intervals starting at or after $2 and before $3, and the number of days
+	// with a price. Each day's prices are averaged first so that the hourly
+	// prices of a day weigh the same as one daily backfilled price.
+	SelectExchangeRateMean = `SELECT COALESCE(AVG(day_price), 0), COUNT(*)
+		FROM (SELECT AVG(price) AS day_price
+			FROM exchange_rates
+			WHERE currency = $1 AND start_time >= $2 AND start_time < $3
+			GROUP BY date_trunc('day', start_time AT TIME ZONE 'UTC')) AS days;`
+)
@@ -36,6 +36,8 @@ const (
 	SetMetaDBBestBlock = `UPDATE meta
 		SET best_block_height = $1, best_block_hash = $2;`
 
+	SelectMetaDBNetName = `SELECT net_name FROM meta;`
+
 	SelectMetaDBIbdComplete = `SELECT ibd_complete FROM meta;`
 
 	SetMetaDBIbdComplete = `UPDATE meta
@@ -0,0 +1,92 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+import "fmt"
+
+// These queries relate to the optional range partitioning of the vins, vouts,
+// and addresses tables.
+const (
+	// SelectTableIsPartitioned checks if the named table is a partitioned
+	// table (relkind 'p') as opposed to a regular table (relkind 'r').
+	SelectTableIsPartitioned = `SELECT relkind = 'p' FROM pg_class
+		WHERE relname = $1 AND relkind IN ('p', 'r');`
+
+	// SelectTimePartitionUpperBound gets the largest upper bound of the
+	// block_time range partitions of a table, or NULL if there are none.
+	SelectTimePartitionUpperBound = `SELECT MAX(upper::TIMESTAMPTZ) FROM (` +
+		`SELECT substring(pg_get_expr(c.relpartbound, c.oid) FROM 'TO \(''(.+)''\)') AS upper
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass) bounds;`
+
+	// SelectIDPartitionUpperBound gets the largest upper bound of the id range
+	// partitions of a table, or NULL if there are none.
+	SelectIDPartitionUpperBound = `SELECT MAX(upper::INT8) FROM (` +
+		`SELECT substring(pg_get_expr(c.relpartbound, c.oid) FROM 'TO \((\d+)\)') AS upper
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass) bounds;`
+
+	// createRangePartition creates a partition of a table for a range of
+	// values FROM (inclusive) TO (exclusive).
+	createRangePartition = `CREATE TABLE IF NOT EXISTS %s PARTITION OF %s
+		FOR VALUES FROM (%s) TO (%s);`
+
+	// renameTable and renamePrimaryKey are used to move an unpartitioned table
+	// aside so that a partitioned table may be created with the same name.
+	renameTable      = `ALTER TABLE %s RENAME TO %s;`
+	renamePrimaryKey = `ALTER TABLE %s RENAME CONSTRAINT %s_pkey TO %s_pkey;`
+
+	// copyTableRows copies all rows from one table to another with the same
+	// columns in the same order.
+	copyTableRows = `INSERT INTO %s SELECT * FROM %s;`
+
+	// countNullBlockTimes counts the rows of a table with a NULL block_time,
+	// which may not be moved into a table partitioned on block_time.
+	countNullBlockTimes = `SELECT COUNT(*) FROM %s WHERE block_time IS NULL;`
+
+	// SelectMaxBlockTime gets the latest block time in the blocks table, or
+	// $1 if the table is empty.
+	SelectMaxBlockTime = `SELECT COALESCE(MAX(time), $1) FROM blocks;`
+
+	// SelectVoutsIDSeq gets the last value of the vouts id sequence, which is
+	// the largest vouts row ID that has been assigned.
+	SelectVoutsIDSeq = `SELECT last_value FROM vouts_id_seq;`
+)
+
+// MakeTimePartitionStatement returns the statement to create a block_time
+// range partition of the named table. The bounds must be formatted timestamps.
+func MakeTimePartitionStatement(table, partition, from, to string) string {
+	return fmt.Sprintf(createRangePartition, partition, table,
+		"'"+from+"'", "'"+to+"'")
+}
+
+// MakeIDPartitionStatement returns the statement to create an id range
+// partition of the named table.
+func MakeIDPartitionStatement(table, partition string, from, to int64) string {
+	return fmt.Sprintf(createRangePartition, partition, table,
+		fmt.Sprint(from), fmt.Sprint(to))
+}
+
+// MakeRenameTableStatements returns the statements to rename a table and its
+// primary key constraint.
+func MakeRenameTableStatements(table, newName string) []string {
+	return []string{
+		fmt.Sprintf(renameTable, table, newName),
+		fmt.Sprintf(renamePrimaryKey, newName, table, newName),
+	}
+}
+
+// MakeCopyTableRowsStatement returns the statement to copy all rows of the
+// table src into the table dst.
+func MakeCopyTableRowsStatement(dst, src string) string {
+	return fmt.Sprintf(copyTableRows, dst, src)
+}
+
+// MakeCountNullBlockTimesStatement returns the statement to count the rows of
+// the named table with a NULL block_time.
+func MakeCountNullBlockTimesStatement(table string) string {
+	return fmt.Sprintf(countNullBlockTimes, table)
+}
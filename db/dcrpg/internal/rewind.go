@@ -51,6 +51,12 @@ const (
 
 	DeleteAddressesSubQry = `DELETE FROM addresses WHERE id IN (` + addressesForBlockHash + `);`
 
+	// DeleteAddressesSubQryPartitioned is like DeleteAddressesSubQry, but it
+	// also limits the rows by the block's time ($2) so that only the
+	// partitions holding the block's data are scanned.
+	DeleteAddressesSubQryPartitioned = `DELETE FROM addresses WHERE block_time = $2 AND id IN (` +
+		addressesForBlockHash + ` AND addresses.block_time = $2);`
+
 	DeleteStakeAddressesFunding = `DELETE FROM addresses
 		USING transactions, blocks
 		WHERE addresses.tx_vin_vout_row_id=ANY(transactions.vin_db_ids)
@@ -102,6 +108,11 @@ const (
 
 	DeleteVinsSubQry = `DELETE FROM vins WHERE id IN (` + vinsForBlockHash + `);`
 
+	// DeleteVinsSubQryPartitioned is like DeleteVinsSubQry, but it also limits
+	// the rows by the block's time ($2) for partition pruning.
+	DeleteVinsSubQryPartitioned = `DELETE FROM vins WHERE block_time = $2 AND id IN (` +
+		vinsForBlockHash + ` AND vins.block_time = $2);`
+
 	// DeleteStakeVins deletes rows of the vins table corresponding to inputs of
 	// the stake transactions (transactions.vin_db_ids) for a block
 	// (blocks.stxDbIDs) specified by its hash (blocks.hash).
@@ -166,6 +177,20 @@ const (
 
 	DeleteVoutsSubQry = `DELETE FROM vouts WHERE id IN (` + voutsForBlockHash + `);`
 
+	// DeleteVoutsSubQryPartitioned is like DeleteVoutsSubQry, but it also
+	// limits the rows to the block's range of vout row IDs ($2 to $3) for
+	// partition pruning.
+	DeleteVoutsSubQryPartitioned = `DELETE FROM vouts WHERE id BETWEEN $2 AND $3 AND id IN (` +
+		voutsForBlockHash + `);`
+
+	// SelectVoutIDRangeForBlock gets the smallest and largest vouts row IDs
+	// of the transactions in a block.
+	SelectVoutIDRangeForBlock = `SELECT MIN(vid), MAX(vid)
+		FROM transactions, UNNEST(vout_db_ids) AS vid
+		WHERE block_hash = $1;`
+
+	SelectBlockTimeByHash = `SELECT time FROM blocks WHERE hash = $1;`
+
 	// DeleteStakeVouts deletes rows of the vouts table corresponding to inputs
 	// of the stake transactions (transactions.vout_db_ids) for a block
 	// (blocks.stxDbIDs) specified by its hash (blocks.hash).
@@ -153,6 +153,49 @@ const (
 		SET is_mainchain=$1
 		WHERE block_hash=$2;`
 
+	// SelectVSPTicketSpends counts the spent mainchain tickets whose votes or
+	// revocations paid one of the addresses, by address, pool status and spend
+	// type. The total blocks from purchase to spend are also selected.
+	SelectVSPTicketSpends = `SELECT address, pool_status, spend_type,
+			COUNT(1), SUM(spend_height - block_height)
+		FROM (
+			SELECT DISTINCT tickets.id, addresses.address, tickets.pool_status,
+				tickets.spend_type, tickets.spend_height, tickets.block_height
+			FROM tickets
+			JOIN transactions ON transactions.id = tickets.spend_tx_db_id
+			JOIN addresses ON addresses.tx_hash = transactions.tx_hash
+				AND addresses.is_funding
+			WHERE tickets.is_mainchain
+				AND addresses.address = ANY($1)
+		) AS spent
+		GROUP BY address, pool_status, spend_type;`
+
+	// SelectTicketSpendAddresses selects the purchase height, price, pool
+	// status and spend type of the mainchain tickets with the hashes, and the
+	// first of the addresses that was paid by each ticket's vote or revocation.
+	SelectTicketSpendAddresses = `SELECT tickets.tx_hash, tickets.block_height,
+			tickets.price, tickets.pool_status, tickets.spend_type,
+			(SELECT addresses.address
+				FROM transactions
+				JOIN addresses ON addresses.tx_hash = transactions.tx_hash
+					AND addresses.is_funding
+				WHERE transactions.id = tickets.spend_tx_db_id
+					AND addresses.address = ANY($2)
+				LIMIT 1)
+		FROM tickets
+		WHERE tickets.tx_hash = ANY($1)
+			AND tickets.is_mainchain;`
+
+	// SelectMeanVoteAges selects the number and mean age in blocks of the
+	// mainchain votes in a range of blocks, binned by vote height.
+	SelectMeanVoteAges = `SELECT spend_height / $3 AS bin, COUNT(1),
+			AVG(spend_height - block_height)::FLOAT8
+		FROM tickets
+		WHERE is_mainchain AND pool_status = 1
+			AND spend_height BETWEEN $1 AND $2
+		GROUP BY bin
+		ORDER BY bin;`
+
 	// votes table
 
 	// CreateVotesTable creates a new table named votes. block_time field is
@@ -424,6 +467,20 @@ const (
 
 	SelectAgendaVoteTotals = `SELECT ` + selectAgendaVotesQuery + `;`
 
+	// SelectAgendaVotesByRCI counts the vote choices in each rule change
+	// interval, numbered from the stake validation height ($7) by the interval
+	// length ($8).
+	SelectAgendaVotesByRCI = `SELECT (votes.height - $7) / $8 AS rci,` +
+		selectAgendaVotesQuery + `GROUP BY rci ORDER BY rci;`
+
+	// SelectVoteVersionsByRCI counts the mainchain votes of each version in
+	// each rule change interval, numbered as for SelectAgendaVotesByRCI.
+	SelectVoteVersionsByRCI = `SELECT (height - $1) / $2 AS rci, version, count(*)
+		FROM votes
+		WHERE height >= $3 AND height <= $4 AND is_mainchain = TRUE
+		GROUP BY rci, version
+		ORDER BY rci, version;`
+
 	selectAgendaVotesQuery = `
 			count(CASE WHEN agenda_votes.agenda_vote_choice = $1 THEN 1 ELSE NULL END) AS yes,
 			count(CASE WHEN agenda_votes.agenda_vote_choice = $2 THEN 1 ELSE NULL END) AS abstain,
@@ -75,6 +75,39 @@ const (
 		GROUP BY timestamp
 		ORDER BY timestamp;`
 
+	selectStatementTotals = `SELECT %s as period,
+		SUM(CASE WHEN tx_type=6 THEN value ELSE 0 END) as tbase,
+		COUNT(CASE WHEN tx_type=6 THEN 1 END) as tbase_count,
+		SUM(CASE WHEN tx_type=4 THEN value ELSE 0 END) as added,
+		COUNT(CASE WHEN tx_type=4 THEN 1 END) as add_count,
+		SUM(CASE WHEN tx_type=5 THEN -value ELSE 0 END) as spent,
+		COUNT(CASE WHEN tx_type=5 THEN 1 END) as spend_count
+		FROM treasury
+		WHERE is_mainchain
+		GROUP BY period
+		ORDER BY period;`
+
+	// SelectTreasuryAddInputs selects the addresses and values of the previous
+	// outputs spent by the mainchain tadds, grouped by tadd.
+	SelectTreasuryAddInputs = `SELECT treasury.tx_hash, treasury.block_time,
+			treasury.value, addresses.address, addresses.value
+		FROM treasury
+		JOIN addresses ON addresses.tx_hash = treasury.tx_hash
+			AND NOT addresses.is_funding
+		WHERE treasury.is_mainchain
+			AND treasury.tx_type = 4
+		ORDER BY treasury.block_height, treasury.tx_hash, addresses.tx_vin_vout_index;`
+
+	// SelectTreasurySpendPayouts selects the outputs of the mainchain tspends.
+	SelectTreasurySpendPayouts = `SELECT treasury.tx_hash, treasury.block_height,
+			treasury.block_time, addresses.address, addresses.value
+		FROM treasury
+		JOIN addresses ON addresses.tx_hash = treasury.tx_hash
+			AND addresses.is_funding
+		WHERE treasury.is_mainchain
+			AND treasury.tx_type = 5
+		ORDER BY treasury.block_height, treasury.tx_hash, addresses.tx_vin_vout_index;`
+
 	// TODO: CreateTreasuryVotesTable
 )
 
@@ -100,3 +133,9 @@ func MakeTreasuryInsertStatement(checked, updateOnConflict bool) string {
 func MakeSelectTreasuryIOStatement(group string) string {
 	return formatGroupingQuery(selectBinnedIO, group, "block_time")
 }
+
+// MakeSelectTreasuryStatementTotals returns the query for the treasury totals
+// of each period of the given time grouping.
+func MakeSelectTreasuryStatementTotals(group string) string {
+	return formatGroupingQuery(selectStatementTotals, group, "block_time")
+}
@@ -214,6 +214,28 @@ const (
 			AND mixed AND value>0
 			AND fund_tx.is_mainchain
 		ORDER BY fund_tx.block_height;`
+
+	// SelectCoinAgeEvents selects the value funded in each mainchain block
+	// after the given height, and the value spent in each of those blocks by
+	// funding height, as (height, fund height, value, spend) rows ordered by
+	// height, with the value funded before the value spent in each block.
+	SelectCoinAgeEvents = `
+		SELECT height, fund_height, SUM(value), spend FROM (
+			SELECT fund_tx.block_height AS height, fund_tx.block_height AS fund_height,
+				vouts.value, FALSE AS spend
+			FROM transactions AS fund_tx
+			JOIN vouts ON vouts.tx_hash=fund_tx.tx_hash
+			WHERE fund_tx.block_height > $1 AND fund_tx.is_mainchain AND vouts.value>0
+			UNION ALL
+			SELECT spend_tx.block_height, fund_tx.block_height, vouts.value, TRUE
+			FROM transactions AS spend_tx
+			JOIN vouts ON vouts.spend_tx_row_id=spend_tx.id
+			JOIN transactions AS fund_tx ON vouts.tx_hash=fund_tx.tx_hash
+			WHERE spend_tx.block_height > $1 AND spend_tx.is_mainchain
+				AND fund_tx.is_mainchain AND vouts.value>0
+		) AS events
+		GROUP BY height, fund_height, spend
+		ORDER BY height, spend;`
 )
 
 /*
@@ -24,6 +24,28 @@ const (
 		tx_type INT4
 	);`
 
+	// CreateVinTablePartitioned is like CreateVinTable, but it creates a table
+	// partitioned by range on block_time. The id sequence is created
+	// separately so that it survives the migration of an existing vins table.
+	// The partitions themselves are not created here.
+	CreateVinTablePartitioned = `CREATE SEQUENCE IF NOT EXISTS vins_id_seq;
+	CREATE TABLE IF NOT EXISTS vins (
+		id INT8 NOT NULL DEFAULT nextval('vins_id_seq'),
+		tx_hash BYTEA,
+		tx_index INT4,
+		tx_tree INT2,
+		is_valid BOOLEAN,
+		is_mainchain BOOLEAN,
+		block_time TIMESTAMPTZ NOT NULL,
+		prev_tx_hash BYTEA,
+		prev_tx_index INT8,
+		prev_tx_tree INT2,
+		value_in INT8,
+		tx_type INT4,
+		PRIMARY KEY (id, block_time)
+	) PARTITION BY RANGE (block_time);
+	ALTER SEQUENCE vins_id_seq OWNED BY vins.id;`
+
 	// insertVinRow is the basis for several vins insert/upsert statements.
 	insertVinRow = `INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
 		value_in, is_valid, is_mainchain, block_time, tx_type) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) `
@@ -56,6 +78,54 @@ const (
 		WHERE  tx_hash = $1 AND tx_index = $2 AND tx_tree = $3 -- only executed if no INSERT
 		LIMIT  1;`
 
+	// A partitioned vins table cannot have a unique index on (tx_hash,
+	// tx_index, tx_tree) since it does not include the partition key, so ON
+	// CONFLICT is not available. The following statements instead look for an
+	// existing row first. This is only safe with a single writer, which is
+	// the case for ChainDB.
+
+	// selectVinRowValues casts the insert arguments so their types are known
+	// when they only appear in the SELECT list of an INSERT.
+	selectVinRowValues = `SELECT $1::BYTEA, $2::INT4, $3::INT2, $4::BYTEA, $5::INT8, $6::INT2,
+		$7::INT8, $8::BOOLEAN, $9::BOOLEAN, $10::TIMESTAMPTZ, $11::INT4`
+
+	// UpsertVinRowPartitioned is the UpsertVinRow counterpart for a
+	// partitioned vins table.
+	UpsertVinRowPartitioned = `WITH updated AS (
+			UPDATE vins SET is_valid = $8, is_mainchain = $9, block_time = $10,
+				prev_tx_hash = $4, prev_tx_index = $5, prev_tx_tree = $6
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			RETURNING id
+		), inserting AS (
+			INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
+				value_in, is_valid, is_mainchain, block_time, tx_type) ` +
+		selectVinRowValues + `
+			WHERE NOT EXISTS (SELECT 1 FROM updated)
+			RETURNING id
+		)
+		SELECT id FROM updated
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// InsertVinRowPartitionedDoNothing is the InsertVinRowOnConflictDoNothing
+	// counterpart for a partitioned vins table.
+	InsertVinRowPartitionedDoNothing = `WITH existing AS (
+			SELECT id FROM vins
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			LIMIT 1
+		), inserting AS (
+			INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
+				value_in, is_valid, is_mainchain, block_time, tx_type) ` +
+		selectVinRowValues + `
+			WHERE NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING id
+		)
+		SELECT id FROM existing
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
 	// DeleteVinsDuplicateRows removes rows that would violate the unique index
 	// uix_vin. This should be run prior to creating the index.
 	DeleteVinsDuplicateRows = `DELETE FROM vins
@@ -82,6 +152,10 @@ const (
 	IndexVinTableOnVins = `CREATE UNIQUE INDEX ` + IndexOfVinsTableOnVin +
 		` ON vins(tx_hash, tx_index, tx_tree);`
 	DeindexVinTableOnVins = `DROP INDEX ` + IndexOfVinsTableOnVin + ` CASCADE;`
+	// IndexVinTableOnVinsPartitioned is the non-unique version of
+	// IndexVinTableOnVins for a partitioned vins table.
+	IndexVinTableOnVinsPartitioned = `CREATE INDEX ` + IndexOfVinsTableOnVin +
+		` ON vins(tx_hash, tx_index, tx_tree);`
 
 	IndexVinTableOnPrevOuts = `CREATE INDEX ` + IndexOfVinsTableOnPrevOut +
 		` ON vins(prev_tx_hash, prev_tx_index);`
@@ -153,6 +227,26 @@ const (
 		spend_tx_row_id INT8
 	);`
 
+	// CreateVoutTablePartitioned is like CreateVoutTable, but it creates a
+	// table partitioned by range on the row id, which increases with block
+	// height since vouts has no block time or height column. The partitions
+	// themselves are not created here.
+	CreateVoutTablePartitioned = `CREATE SEQUENCE IF NOT EXISTS vouts_id_seq;
+	CREATE TABLE IF NOT EXISTS vouts (
+		id INT8 NOT NULL DEFAULT nextval('vouts_id_seq'),
+		tx_hash BYTEA,
+		tx_index INT4,
+		tx_tree INT2,
+		value INT8,
+		version INT2,
+		script_type TEXT,
+		script_addresses TEXT,
+		mixed BOOLEAN DEFAULT FALSE,
+		spend_tx_row_id INT8,
+		PRIMARY KEY (id)
+	) PARTITION BY RANGE (id);
+	ALTER SEQUENCE vouts_id_seq OWNED BY vouts.id;`
+
 	// insertVinRow is the basis for several vout insert/upsert statements.
 	insertVoutRow = `INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
 		version, script_type, script_addresses, mixed)
@@ -193,6 +287,48 @@ const (
 		WHERE  tx_hash = $1 AND tx_index = $2 AND tx_tree = $3 -- only executed if no INSERT
 		LIMIT  1;`
 
+	// selectVoutRowValues casts the insert arguments so their types are known
+	// when they only appear in the SELECT list of an INSERT.
+	selectVoutRowValues = `SELECT $1::BYTEA, $2::INT4, $3::INT2, $4::INT8, $5::INT2,
+		$6::TEXT, $7::TEXT, $8::BOOLEAN`
+
+	// UpsertVoutRowPartitioned is the UpsertVoutRow counterpart for a
+	// partitioned vouts table. See UpsertVinRowPartitioned.
+	UpsertVoutRowPartitioned = `WITH updated AS (
+			UPDATE vouts SET version = $5
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			RETURNING id
+		), inserting AS (
+			INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
+				version, script_type, script_addresses, mixed) ` +
+		selectVoutRowValues + `
+			WHERE NOT EXISTS (SELECT 1 FROM updated)
+			RETURNING id
+		)
+		SELECT id FROM updated
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// InsertVoutRowPartitionedDoNothing is the
+	// InsertVoutRowOnConflictDoNothing counterpart for a partitioned vouts
+	// table.
+	InsertVoutRowPartitionedDoNothing = `WITH existing AS (
+			SELECT id FROM vouts
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			LIMIT 1
+		), inserting AS (
+			INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
+				version, script_type, script_addresses, mixed) ` +
+		selectVoutRowValues + `
+			WHERE NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING id
+		)
+		SELECT id FROM existing
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
 	// DeleteVoutDuplicateRows removes rows that would violate the unique index
 	// uix_vout_txhash_ind. This should be run prior to creating the index.
 	DeleteVoutDuplicateRows = `DELETE FROM vouts
@@ -224,6 +360,10 @@ const (
 	IndexVoutTableOnTxHashIdx = `CREATE UNIQUE INDEX IF NOT EXISTS ` + IndexOfVoutsTableOnTxHashInd +
 		` ON vouts(tx_hash, tx_index, tx_tree) INCLUDE (value);`
 	DeindexVoutTableOnTxHashIdx = `DROP INDEX IF EXISTS ` + IndexOfVoutsTableOnTxHashInd + ` CASCADE;`
+	// IndexVoutTableOnTxHashIdxPartitioned is the non-unique version of
+	// IndexVoutTableOnTxHashIdx for a partitioned vouts table.
+	IndexVoutTableOnTxHashIdxPartitioned = `CREATE INDEX IF NOT EXISTS ` + IndexOfVoutsTableOnTxHashInd +
+		` ON vouts(tx_hash, tx_index, tx_tree) INCLUDE (value);`
 
 	IndexVoutTableOnSpendTxID = `CREATE INDEX IF NOT EXISTS ` + IndexOfVoutsTableOnSpendTxID +
 		` ON vouts(spend_tx_row_id);`
@@ -249,11 +389,18 @@ const (
 // constraint. For updateOnConflict=true, an upsert statement will be provided
 // that UPDATEs the conflicting row. For updateOnConflict=false, the statement
 // will either insert or do nothing, and return the inserted (new) or
-// conflicting (unmodified) row id.
-func MakeVinInsertStatement(checked, updateOnConflict bool) string {
+// conflicting (unmodified) row id. For partitioned=true, the statements for a
+// partitioned vins table are used when checked=true.
+func MakeVinInsertStatement(checked, updateOnConflict, partitioned bool) string {
 	if !checked {
 		return InsertVinRow
 	}
+	if partitioned {
+		if updateOnConflict {
+			return UpsertVinRowPartitioned
+		}
+		return InsertVinRowPartitionedDoNothing
+	}
 	if updateOnConflict {
 		return UpsertVinRow
 	}
@@ -268,11 +415,18 @@ func MakeVinInsertStatement(checked, updateOnConflict bool) string {
 // constraint. For updateOnConflict=true, an upsert statement will be provided
 // that UPDATEs the conflicting row. For updateOnConflict=false, the statement
 // will either insert or do nothing, and return the inserted (new) or
-// conflicting (unmodified) row id.
-func MakeVoutInsertStatement(checked, updateOnConflict bool) string {
+// conflicting (unmodified) row id. For partitioned=true, the statements for a
+// partitioned vouts table are used when checked=true.
+func MakeVoutInsertStatement(checked, updateOnConflict, partitioned bool) string {
 	if !checked {
 		return InsertVoutRow
 	}
+	if partitioned {
+		if updateOnConflict {
+			return UpsertVoutRowPartitioned
+		}
+		return InsertVoutRowPartitionedDoNothing
+	}
 	if updateOnConflict {
 		return UpsertVoutRow
 	}
@@ -0,0 +1,390 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+)
+
+// Declarative range partitioning of the largest tables is optional. The vins
+// and addresses tables are partitioned on block_time, while the vouts table,
+// which has neither a block time nor a height column, is partitioned on its
+// row ID, which increases with block height. Partitions are created ahead of
+// the data that will be stored in them. See (*ChainDB).ensurePartitions.
+
+const (
+	// DefaultPartitionMonths is the default width in months of each vins and
+	// addresses table partition.
+	DefaultPartitionMonths = 12
+
+	// DefaultPartitionVoutRows is the default width in row IDs of each vouts
+	// table partition.
+	DefaultPartitionVoutRows = 100_000_000
+)
+
+// PartitionCfg configures the range partitioning of the vins, vouts, and
+// addresses tables.
+type PartitionCfg struct {
+	// Months is the width in months of each vins and addresses partition.
+	Months int
+	// VoutRows is the width in row IDs of each vouts partition.
+	VoutRows int64
+}
+
+// timePartitionedTables are the tables partitioned by range on block_time.
+var timePartitionedTables = []string{"vins", "addresses"}
+
+// idPartitionedTable is the table partitioned by range on id.
+const idPartitionedTable = "vouts"
+
+// partitionedTableStatements are the CREATE TABLE statements for the tables
+// that support range partitioning, to be used in place of the regular ones
+// from createTableStatements.
+var partitionedTableStatements = map[string]string{
+	"vins":      internal.CreateVinTablePartitioned,
+	"vouts":     internal.CreateVoutTablePartitioned,
+	"addresses": internal.CreateAddressTablePartitioned,
+}
+
+// partitionedTableIndexes are the indexes of each partitionable table that
+// are recreated after migrating the table's data to a partitioned table.
+var partitionedTableIndexes = map[string][]struct {
+	name      string
+	indexFunc func(db *sql.DB) error
+}{
+	"vins": {
+		{internal.IndexOfVinsTableOnVin, IndexVinTableOnVins},
+		{internal.IndexOfVinsTableOnPrevOut, IndexVinTableOnPrevOuts},
+	},
+	"vouts": {
+		{internal.IndexOfVoutsTableOnTxHashInd, IndexVoutTableOnTxHashIdx},
+		{internal.IndexOfVoutsTableOnSpendTxID, IndexVoutTableOnSpendTxID},
+	},
+	"addresses": {
+		{internal.IndexOfAddressTableOnAddress, IndexAddressTableOnAddress},
+		{internal.IndexOfAddressTableOnVoutID, IndexAddressTableOnVoutID},
+		{internal.IndexOfAddressTableOnBlockTime, IndexBlockTimeOnTableAddress},
+		{internal.IndexOfAddressTableOnTx, IndexAddressTableOnTxHash},
+		{internal.IndexOfAddressTableOnMatchingTx, IndexAddressTableOnMatchingTxHash},
+	},
+}
+
+// tableIsPartitioned checks if the named table is a partitioned table. A
+// table that does not exist is not partitioned.
+func tableIsPartitioned(db SqlQueryer, table string) (bool, error) {
+	var partitioned bool
+	err := db.QueryRow(internal.SelectTableIsPartitioned, table).Scan(&partitioned)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return partitioned, err
+}
+
+// tablePartitions tracks the upper bounds of the existing partitions of the
+// partitioned tables so that new partitions may be created as needed without
+// querying the catalog for every block.
+type tablePartitions struct {
+	months   int
+	voutRows int64
+	// start is the lower bound of the first block_time partition.
+	start time.Time
+
+	mtx       sync.Mutex
+	timeUpper map[string]time.Time
+	idUpper   int64
+	loaded    bool
+	// lastVoutID is the largest vouts row ID that may have been assigned,
+	// counted from the vouts id sequence by the outputs of each block since
+	// the sequence was queried. Row IDs taken by rolled back inserts are not
+	// counted, but those few are well within the spare partition. See
+	// ensureBlock.
+	lastVoutID    int64
+	lastVoutKnown bool
+}
+
+// newTablePartitions creates a tablePartitions for the given configuration,
+// substituting the defaults for any unset values. The first block_time
+// partition starts at the beginning of the month of the genesis block.
+func newTablePartitions(cfg *PartitionCfg, genesisTime time.Time) *tablePartitions {
+	tp := &tablePartitions{
+		months:    DefaultPartitionMonths,
+		voutRows:  DefaultPartitionVoutRows,
+		timeUpper: make(map[string]time.Time, len(timePartitionedTables)),
+	}
+	if cfg != nil {
+		if cfg.Months > 0 {
+			tp.months = cfg.Months
+		}
+		if cfg.VoutRows > 0 {
+			tp.voutRows = cfg.VoutRows
+		}
+	}
+	genesisTime = genesisTime.UTC()
+	tp.start = time.Date(genesisTime.Year(), genesisTime.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return tp
+}
+
+// load retrieves the upper bounds of the existing partitions. The caller must
+// hold the mutex.
+func (tp *tablePartitions) load(db SqlQueryer) error {
+	for _, table := range timePartitionedTables {
+		var upper sql.NullTime
+		err := db.QueryRow(internal.SelectTimePartitionUpperBound, table).Scan(&upper)
+		if err != nil {
+			return fmt.Errorf("failed to get %s partition bounds: %w", table, err)
+		}
+		tp.timeUpper[table] = tp.start
+		if upper.Valid {
+			tp.timeUpper[table] = upper.Time.UTC()
+		}
+	}
+
+	var upper sql.NullInt64
+	err := db.QueryRow(internal.SelectIDPartitionUpperBound, idPartitionedTable).Scan(&upper)
+	if err != nil {
+		return fmt.Errorf("failed to get %s partition bounds: %w", idPartitionedTable, err)
+	}
+	tp.idUpper = upper.Int64 // 0 if there are no partitions
+
+	tp.lastVoutKnown = false
+	tp.loaded = true
+	return nil
+}
+
+// reset discards the cached partition bounds so they are retrieved from the
+// database on the next call to ensure. This is required if a DB transaction in
+// which partitions were created is rolled back.
+func (tp *tablePartitions) reset() {
+	tp.mtx.Lock()
+	tp.loaded = false
+	tp.mtx.Unlock()
+}
+
+// ensure creates any partitions needed so that there is at least one full
+// partition beyond the one for data with the given block time and vouts row
+// ID. This spare partition means that a block's rows never lack a partition,
+// even when the block starts a new partition.
+func (tp *tablePartitions) ensure(db SqlExecQueryer, blockTime time.Time, lastVoutID int64) error {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	if !tp.loaded {
+		if err := tp.load(db); err != nil {
+			return err
+		}
+	}
+	tp.lastVoutID, tp.lastVoutKnown = lastVoutID, true
+	return tp.create(db, blockTime, lastVoutID)
+}
+
+// ensureBlock is like ensure, but for a block with the given time and number
+// of outputs, which is the most that the block can advance the vouts row IDs.
+// The vouts id sequence is only queried when the block's rows could need a new
+// vouts partition, so that storing most blocks takes no queries.
+func (tp *tablePartitions) ensureBlock(db SqlExecQueryer, blockTime time.Time, numVouts int64) error {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	if !tp.loaded {
+		if err := tp.load(db); err != nil {
+			return err
+		}
+	}
+	if !tp.lastVoutKnown || tp.lastVoutID+numVouts+tp.voutRows >= tp.idUpper {
+		var lastVoutID int64
+		if err := db.QueryRow(internal.SelectVoutsIDSeq).Scan(&lastVoutID); err != nil {
+			return fmt.Errorf("failed to get the last vouts row ID: %w", err)
+		}
+		tp.lastVoutID, tp.lastVoutKnown = lastVoutID, true
+	}
+	tp.lastVoutID += numVouts
+	return tp.create(db, blockTime, tp.lastVoutID)
+}
+
+// create creates the partitions for ensure. The caller must hold the mutex.
+func (tp *tablePartitions) create(db SqlExecQueryer, blockTime time.Time, lastVoutID int64) error {
+	timeTarget := blockTime.UTC().AddDate(0, tp.months, 0)
+	for _, table := range timePartitionedTables {
+		upper := tp.timeUpper[table]
+		for !upper.After(timeTarget) {
+			next := upper.AddDate(0, tp.months, 0)
+			partition := fmt.Sprintf("%s_p%s", table, upper.Format("200601"))
+			log.Infof("Creating partition %s for block times in [%s, %s).", partition,
+				upper.Format("2006-01-02"), next.Format("2006-01-02"))
+			_, err := db.Exec(internal.MakeTimePartitionStatement(table, partition,
+				upper.Format(time.RFC3339), next.Format(time.RFC3339)))
+			if err != nil {
+				return fmt.Errorf("failed to create partition %s: %w", partition, err)
+			}
+			upper = next
+			tp.timeUpper[table] = upper
+		}
+	}
+
+	idTarget := lastVoutID + tp.voutRows
+	for tp.idUpper <= idTarget {
+		next := tp.idUpper + tp.voutRows
+		partition := fmt.Sprintf("%s_p%d", idPartitionedTable, tp.idUpper)
+		log.Infof("Creating partition %s for row IDs in [%d, %d).", partition,
+			tp.idUpper, next)
+		_, err := db.Exec(internal.MakeIDPartitionStatement(idPartitionedTable,
+			partition, tp.idUpper, next))
+		if err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", partition, err)
+		}
+		tp.idUpper = next
+	}
+
+	return nil
+}
+
+// partitionTables migrates the data of the unpartitioned vins, vouts, and
+// addresses tables into partitioned tables. The migration of all three tables
+// is performed in a single DB transaction so that the tables are never left
+// partially partitioned. The indexes of the old tables are recreated after the
+// data is copied. This may take a very long time for a mainnet database.
+func partitionTables(db *sql.DB, tp *tablePartitions) error {
+	tables := append([]string{idPartitionedTable}, timePartitionedTables...)
+
+	var migrate []string
+	for _, table := range tables {
+		partitioned, err := tableIsPartitioned(db, table)
+		if err != nil {
+			return err
+		}
+		if !partitioned {
+			migrate = append(migrate, table)
+		}
+	}
+	if len(migrate) == 0 {
+		return nil
+	}
+
+	// Note the existing indexes of the tables being migrated. They are dropped
+	// along with the old tables.
+	recreate := make(map[string][]func(db *sql.DB) error, len(migrate))
+	for _, table := range migrate {
+		for _, idx := range partitionedTableIndexes[table] {
+			exists, err := ExistsIndex(db, idx.name)
+			if err != nil {
+				return err
+			}
+			if exists {
+				recreate[table] = append(recreate[table], idx.indexFunc)
+			}
+		}
+	}
+
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin database transaction: %w", err)
+	}
+
+	rollback := func(err error) error {
+		tp.reset()
+		return fmt.Errorf("%w (rollback: %v)", err, dbTx.Rollback())
+	}
+
+	var maxBlockTime time.Time
+	for _, table := range migrate {
+		log.Infof("Moving the %q table aside for partitioning...", table)
+		oldTable := table + "_unpartitioned"
+		for _, stmt := range internal.MakeRenameTableStatements(table, oldTable) {
+			if _, err = dbTx.Exec(stmt); err != nil {
+				return rollback(fmt.Errorf("failed to rename %s: %w", table, err))
+			}
+		}
+		if _, err = dbTx.Exec(partitionedTableStatements[table]); err != nil {
+			return rollback(fmt.Errorf("failed to create partitioned %s: %w", table, err))
+		}
+
+		if table == idPartitionedTable {
+			continue
+		}
+
+		// Rows without a block time cannot be placed in any partition.
+		var numNull int64
+		err = dbTx.QueryRow(internal.MakeCountNullBlockTimesStatement(oldTable)).Scan(&numNull)
+		if err != nil {
+			return rollback(err)
+		}
+		if numNull > 0 {
+			return rollback(fmt.Errorf("%s has %d rows with a NULL block_time", table, numNull))
+		}
+	}
+
+	// Create the partitions for all of the existing data.
+	var lastVoutID int64
+	if err = dbTx.QueryRow(internal.SelectVoutsIDSeq).Scan(&lastVoutID); err != nil {
+		return rollback(fmt.Errorf("failed to get the last vouts row ID: %w", err))
+	}
+	err = dbTx.QueryRow(internal.SelectMaxBlockTime, tp.start).Scan(&maxBlockTime)
+	if err != nil {
+		return rollback(fmt.Errorf("failed to get the best block time: %w", err))
+	}
+	if err = tp.ensure(dbTx, maxBlockTime, lastVoutID); err != nil {
+		return rollback(err)
+	}
+
+	for _, table := range migrate {
+		log.Infof("Copying the %q table rows into partitions. This may take a while...", table)
+		oldTable := table + "_unpartitioned"
+		start := time.Now()
+		res, err := dbTx.Exec(internal.MakeCopyTableRowsStatement(table, oldTable))
+		if err != nil {
+			return rollback(fmt.Errorf("failed to copy %s rows: %w", table, err))
+		}
+		N, _ := res.RowsAffected()
+		log.Infof("Copied %d %s rows in %v.", N, table, time.Since(start))
+		if err = dropTable(dbTx, oldTable); err != nil {
+			return rollback(fmt.Errorf("failed to drop %s: %w", oldTable, err))
+		}
+	}
+
+	if err = dbTx.Commit(); err != nil {
+		tp.reset()
+		return fmt.Errorf("failed to commit partitioned tables: %w", err)
+	}
+
+	for _, table := range migrate {
+		log.Infof("Recreating %d indexes on the partitioned %q table...",
+			len(recreate[table]), table)
+		for _, indexFunc := range recreate[table] {
+			if err = indexFunc(db); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensurePartitions creates any vins, vouts, and addresses table partitions
+// that may be needed to store the block. This is a no-op if the tables are not
+// partitioned.
+func (pgb *ChainDB) ensurePartitions(msgBlock *wire.MsgBlock) error {
+	if pgb.partitions == nil {
+		return nil
+	}
+	var numVouts int64
+	for _, txns := range [][]*wire.MsgTx{msgBlock.Transactions, msgBlock.STransactions} {
+		for _, tx := range txns {
+			numVouts += int64(len(tx.TxOut))
+		}
+	}
+	return pgb.partitions.ensureBlock(pgb.db, msgBlock.Header.Timestamp, numVouts)
+}
+
+// TablesPartitioned indicates if the vins, vouts, and addresses tables are
+// range partitioned.
+func (pgb *ChainDB) TablesPartitioned() bool {
+	return pgb.partitions != nil
+}
@@ -0,0 +1,165 @@
+//go:build pgonline
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/lib/pq"
+)
+
+// partitionTestBlock is a block with one transaction, which has one input
+// and one output, each with a row in the addresses table.
+type partitionTestBlock struct {
+	hash, txHash dbtypes.ChainHash
+	time         time.Time
+}
+
+func newPartitionTestBlock(height byte, blockTime time.Time) *partitionTestBlock {
+	return &partitionTestBlock{
+		hash:   dbtypes.ChainHash{height},
+		txHash: dbtypes.ChainHash{0xff, height},
+		time:   blockTime,
+	}
+}
+
+// storeRows inserts the vins, vouts, and addresses rows of the block with the
+// statements for the given conflict checking, and returns their row IDs.
+func (b *partitionTestBlock) storeRows(t *testing.T, db *sql.DB, checked, updateOnConflict, partitioned bool) (vinID, voutID int64, addrIDs [2]int64) {
+	t.Helper()
+	err := db.QueryRow(internal.MakeVinInsertStatement(checked, updateOnConflict, partitioned),
+		b.txHash, 0, 0, dbtypes.ChainHash{0xee}, 0, 0, int64(1e8), true, true, b.time, 0).Scan(&vinID)
+	if err != nil {
+		t.Fatalf("failed to insert vin: %v", err)
+	}
+	err = db.QueryRow(internal.MakeVoutInsertStatement(checked, updateOnConflict, partitioned),
+		b.txHash, 0, 0, int64(1e8), 0, "pubkeyhash", "Dsaddr", false).Scan(&voutID)
+	if err != nil {
+		t.Fatalf("failed to insert vout: %v", err)
+	}
+	addrStmt := internal.MakeAddressRowInsertStatement(checked, updateOnConflict, partitioned)
+	for i, row := range []struct {
+		rowID     int64
+		isFunding bool
+	}{{voutID, true}, {vinID, false}} {
+		err = db.QueryRow(addrStmt, "Dsaddr", dbtypes.ChainHash{}, b.txHash, 0, row.rowID,
+			int64(1e8), b.time, row.isFunding, true, 0).Scan(&addrIDs[i])
+		if err != nil {
+			t.Fatalf("failed to insert address row: %v", err)
+		}
+	}
+	return
+}
+
+// store inserts the block's rows, and its blocks, block_chain, and
+// transactions rows.
+func (b *partitionTestBlock) store(t *testing.T, db *sql.DB, height int64, partitioned bool) {
+	t.Helper()
+	vinID, voutID, _ := b.storeRows(t, db, partitioned, partitioned, partitioned)
+	var txID, blockID int64
+	err := db.QueryRow(`INSERT INTO transactions (block_hash, block_height, block_time,
+		tx_hash, tree, vin_db_ids, vout_db_ids, is_valid, is_mainchain)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, true, true) RETURNING id;`,
+		b.hash, height, b.time, b.txHash, pq.Array([]int64{vinID}),
+		pq.Array([]int64{voutID})).Scan(&txID)
+	if err != nil {
+		t.Fatalf("failed to insert transaction: %v", err)
+	}
+	err = db.QueryRow(`INSERT INTO blocks (hash, height, time, txDbIDs, stxDbIDs,
+		is_valid, is_mainchain) VALUES ($1, $2, $3, $4, '{}', true, true) RETURNING id;`,
+		b.hash, height, b.time, pq.Array([]int64{txID})).Scan(&blockID)
+	if err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	_, err = db.Exec(internal.InsertBlockPrevNext, blockID, dbtypes.ChainHash{}, b.hash, dbtypes.ChainHash{})
+	if err != nil {
+		t.Fatalf("failed to insert block_chain row: %v", err)
+	}
+}
+
+func TestPartitionTables(t *testing.T) {
+	scratch := openScratchDB(t, "dcrdata_partitions_test")
+	if err := CreateTables(scratch, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexVinTableOnVins(scratch); err != nil {
+		t.Fatal(err)
+	}
+	march := newPartitionTestBlock(1, time.Date(2016, 3, 10, 0, 0, 0, 0, time.UTC))
+	march.store(t, scratch, 1, false)
+
+	// The unpartitioned tables are migrated with their rows and indexes.
+	genesis := chaincfg.MainNetParams().GenesisBlock.Header.Timestamp
+	tp := newTablePartitions(&PartitionCfg{Months: 1, VoutRows: 10}, genesis)
+	if err := partitionTables(scratch, tp); err != nil {
+		t.Fatalf("partitionTables failed: %v", err)
+	}
+	wantRows := map[string]int64{"vins": 1, "vouts": 1, "addresses": 2}
+	for table, want := range wantRows {
+		partitioned, err := tableIsPartitioned(scratch, table)
+		if err != nil || !partitioned {
+			t.Fatalf("%s is not partitioned (%v)", table, err)
+		}
+		if n := countRows(t, scratch, table); n != want {
+			t.Errorf("%s has %d rows after partitioning, expected %d", table, n, want)
+		}
+	}
+	if exists, err := ExistsIndex(scratch, internal.IndexOfVinsTableOnVin); err != nil || !exists {
+		t.Errorf("index %s not recreated (%v)", internal.IndexOfVinsTableOnVin, err)
+	}
+	if err := partitionTables(scratch, tp); err != nil {
+		t.Fatalf("partitionTables failed for partitioned tables: %v", err)
+	}
+
+	// A block in a later month is stored with the partitioned upserts once its
+	// partitions are created.
+	may := newPartitionTestBlock(2, time.Date(2016, 5, 10, 0, 0, 0, 0, time.UTC))
+	if err := tp.ensureBlock(scratch, may.time, 1); err != nil {
+		t.Fatal(err)
+	}
+	may.store(t, scratch, 2, true)
+	for table, want := range wantRows {
+		if n := countRows(t, scratch, table); n != 2*want {
+			t.Errorf("%s has %d rows after storing a block, expected %d", table, n, 2*want)
+		}
+	}
+
+	// Storing the rows again, with or without the update, finds the existing
+	// rows rather than adding rows.
+	vinID, voutID, addrIDs := may.storeRows(t, scratch, true, true, true)
+	for _, updateOnConflict := range []bool{true, false} {
+		vinID2, voutID2, addrIDs2 := may.storeRows(t, scratch, true, updateOnConflict, true)
+		if vinID2 != vinID || voutID2 != voutID || addrIDs2 != addrIDs {
+			t.Errorf("stored rows again as new rows (update on conflict %v)", updateOnConflict)
+		}
+	}
+	for table, want := range wantRows {
+		if n := countRows(t, scratch, table); n != 2*want {
+			t.Errorf("%s has %d rows after storing rows again, expected %d", table, n, 2*want)
+		}
+	}
+
+	// Deleting a block removes just its rows from the partitions.
+	res, err := pgQueries.deleteBlockData(context.Background(), scratch, march.hash, 1)
+	if err != nil {
+		t.Fatalf("deleteBlockData failed: %v", err)
+	}
+	if res.Vins != 1 || res.Vouts != 1 || res.Addresses != 2 || res.Blocks != 1 {
+		t.Errorf("unexpected deletion summary %+v", res)
+	}
+	for table, want := range wantRows {
+		if n := countRows(t, scratch, table); n != want {
+			t.Errorf("%s has %d rows after deleting a block, expected %d", table, n, want)
+		}
+	}
+	var txHash dbtypes.ChainHash
+	if err = scratch.QueryRow(`SELECT tx_hash FROM vins;`).Scan(&txHash); err != nil || txHash != may.txHash {
+		t.Errorf("deleted the wrong vins row (%v)", err)
+	}
+}
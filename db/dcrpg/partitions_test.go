@@ -0,0 +1,156 @@
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+)
+
+func TestNewTablePartitions(t *testing.T) {
+	genesis := time.Date(2016, 2, 8, 18, 0, 0, 0, time.FixedZone("", -5*3600))
+	wantStart := time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		cfg          *PartitionCfg
+		wantMonths   int
+		wantVoutRows int64
+	}{
+		{"nil cfg", nil, DefaultPartitionMonths, DefaultPartitionVoutRows},
+		{"zero cfg", &PartitionCfg{}, DefaultPartitionMonths, DefaultPartitionVoutRows},
+		{"custom", &PartitionCfg{Months: 3, VoutRows: 1000}, 3, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTablePartitions(tt.cfg, genesis)
+			if tp.months != tt.wantMonths {
+				t.Errorf("months = %d, want %d", tp.months, tt.wantMonths)
+			}
+			if tp.voutRows != tt.wantVoutRows {
+				t.Errorf("voutRows = %d, want %d", tp.voutRows, tt.wantVoutRows)
+			}
+			if !tp.start.Equal(wantStart) {
+				t.Errorf("start = %v, want %v", tp.start, wantStart)
+			}
+		})
+	}
+}
+
+// seqConn is a driver.Conn that answers the vouts id sequence query with
+// lastVoutID, and counts the queries and the partitions created.
+type seqConn struct {
+	lastVoutID int64
+	queries    int
+	partitions int
+}
+
+func (c *seqConn) Prepare(query string) (driver.Stmt, error) {
+	return &seqStmt{c, query}, nil
+}
+
+func (c *seqConn) Close() error {
+	return nil
+}
+
+func (c *seqConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *seqConn) Connect(context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *seqConn) Driver() driver.Driver {
+	return nil
+}
+
+type seqStmt struct {
+	conn  *seqConn
+	query string
+}
+
+func (s *seqStmt) Close() error {
+	return nil
+}
+
+func (s *seqStmt) NumInput() int {
+	return -1
+}
+
+func (s *seqStmt) Exec([]driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "PARTITION OF vouts") {
+		s.conn.partitions++
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *seqStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.query != internal.SelectVoutsIDSeq {
+		return nil, errors.New("unexpected query")
+	}
+	s.conn.queries++
+	return &seqRows{value: s.conn.lastVoutID}, nil
+}
+
+type seqRows struct {
+	value int64
+	done  bool
+}
+
+func (r *seqRows) Columns() []string {
+	return []string{"last_value"}
+}
+
+func (r *seqRows) Close() error {
+	return nil
+}
+
+func (r *seqRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0], r.done = r.value, true
+	return nil
+}
+
+func TestEnsureBlockPartitions(t *testing.T) {
+	conn := &seqConn{lastVoutID: 50}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	blockTime := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tp := newTablePartitions(&PartitionCfg{Months: 1, VoutRows: 100}, blockTime)
+	tp.loaded = true
+	for _, table := range timePartitionedTables {
+		tp.timeUpper[table] = blockTime.AddDate(1, 0, 0)
+	}
+	tp.idUpper = 300
+
+	// The sequence is queried for the first block, and not again until a
+	// block could need a new vouts partition.
+	for i := 0; i < 14; i++ {
+		if err := tp.ensureBlock(db, blockTime, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if conn.queries != 1 || conn.partitions != 0 || tp.lastVoutID != 190 {
+		t.Fatalf("got %d queries, %d partitions, last vout ID %d; expected 1, 0, 190",
+			conn.queries, conn.partitions, tp.lastVoutID)
+	}
+
+	conn.lastVoutID = 195
+	if err := tp.ensureBlock(db, blockTime, 10); err != nil {
+		t.Fatal(err)
+	}
+	if conn.queries != 2 || conn.partitions != 1 || tp.idUpper != 400 || tp.lastVoutID != 205 {
+		t.Fatalf("got %d queries, %d partitions, upper bound %d, last vout ID %d; expected 2, 1, 400, 205",
+			conn.queries, conn.partitions, tp.idUpper, tp.lastVoutID)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
@@ -30,6 +31,7 @@ import (
 	"github.com/decred/dcrd/wire"
 	humanize "github.com/dustin/go-humanize"
 
+	"github.com/decred/dcrdata/db/dcrpg/v8/dialect"
 	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
 	apitypes "github.com/decred/dcrdata/v8/api/types"
 	"github.com/decred/dcrdata/v8/blockdata"
@@ -37,8 +39,10 @@ import (
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
 	"github.com/decred/dcrdata/v8/mempool"
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/rpcutils"
 	"github.com/decred/dcrdata/v8/stakedb"
+	"github.com/decred/dcrdata/v8/stakediff"
 	"github.com/decred/dcrdata/v8/trylock"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
@@ -241,11 +245,13 @@ type BlockGetter interface {
 }
 
 // ChainDB provides an interface for storing and manipulating extracted
-// blockchain data in a PostgreSQL database.
+// blockchain data in a PostgreSQL database, or in another database with a SQL
+// dialect.
 type ChainDB struct {
 	ctx                context.Context
 	queryTimeout       time.Duration
 	db                 *sql.DB
+	q                  queries
 	mp                 rpcutils.MempoolAddressChecker
 	chainParams        *chaincfg.Params
 	devAddress         string
@@ -258,11 +264,15 @@ type ChainDB struct {
 	CacheLocks         cacheLocks
 	devPrefetch        bool
 	InBatchSync        bool
-	InReorg            bool
+	InReorg            atomic.Bool
 	tpUpdatePermission map[dbtypes.TimeBasedGrouping]*trylock.Mutex
 	utxoCache          utxoStore
+	partitions         *tablePartitions // nil if the tables are not partitioned
+	replicas           *replicaSet      // nil if there are no read replicas
+	slowQueries        *slowQueryLog    // nil if the slow query log is disabled
 	mixSetDiffsMtx     sync.Mutex
 	mixSetDiffs        map[uint32]int64 // height to value diff
+	coinAges           *coinAgeLedger
 	deployments        *ChainDeployments
 	MPC                *mempool.DataCache
 	// BlockCache stores apitypes.BlockDataBasic and apitypes.StakeInfoExtended
@@ -301,6 +311,21 @@ func (pgb *ChainDB) timeoutError() string {
 	return fmt.Sprintf("%s after %v", dbtypes.TimeoutPrefix, pgb.queryTimeout)
 }
 
+// queryCtx returns a context for the queries of the named ChainDB method that
+// is canceled after the query timeout. The method name is also used by the
+// slow query log. The returned CancelFunc also records the latency of the
+// method's queries.
+func (pgb *ChainDB) queryCtx(method string) (context.Context, context.CancelFunc) {
+	ctx := context.WithValue(pgb.ctx, queryMethodKey{}, method)
+	ctx, cancel := context.WithTimeout(ctx, pgb.queryTimeout)
+	start := time.Now()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { metrics.ObserveSince(metrics.DBQueryDuration, method, start) })
+		cancel()
+	}
+}
+
 // replaceCancelError will replace the generic error strings that can occur when
 // a PG query is canceled (dbtypes.PGCancelError) or a context deadline is
 // exceeded (dbtypes.CtxDeadlineExceeded from context.DeadlineExceeded). It also
@@ -393,6 +418,7 @@ func (pgb *ChainDB) MissingSideChainBlocks() ([]dbtypes.SideChain, int, error) {
 type TicketTxnIDGetter struct {
 	mtx     sync.RWMutex
 	idCache map[dbtypes.ChainHash]uint64
+	q       queries
 	db      *sql.DB
 }
 
@@ -417,7 +443,7 @@ func (t *TicketTxnIDGetter) TxnDbID(txid dbtypes.ChainHash, expire bool) (uint64
 	}
 	// Cache miss. Get the row id by hash from the tickets table.
 	log.Tracef("Cache miss for %s.", txid)
-	return retrieveTicketIDByHashNoCancel(t.db, txid)
+	return t.q.retrieveTicketIDByHashNoCancel(t.db, txid)
 }
 
 // Set stores the (transaction hash, DB row ID) pair a map for future access.
@@ -442,9 +468,10 @@ func (t *TicketTxnIDGetter) SetN(txid []dbtypes.ChainHash, txDbID []uint64) {
 	}
 }
 
-// NewTicketTxnIDGetter constructs a new TicketTxnIDGetter with an empty cache.
-func NewTicketTxnIDGetter(db *sql.DB) *TicketTxnIDGetter {
+// newTicketTxnIDGetter constructs a new TicketTxnIDGetter with an empty cache.
+func newTicketTxnIDGetter(q queries, db *sql.DB) *TicketTxnIDGetter {
 	return &TicketTxnIDGetter{
+		q:       q,
 		db:      db,
 		idCache: make(map[dbtypes.ChainHash]uint64),
 	}
@@ -457,11 +484,34 @@ type DBInfo struct {
 }
 
 type ChainDBCfg struct {
-	DBi                               *DBInfo
+	// DBi is the PostgreSQL database connection information, which is not
+	// used with a Dialect.
+	DBi *DBInfo
+	// Dialect is the SQL dialect and connection of a database other than
+	// PostgreSQL. Table partitioning, read replicas, the slow query log, and
+	// the bulk load of SyncChainDB are only supported for PostgreSQL.
+	Dialect *dialect.Dialect
+	// QueryTimeout is the query timeout with a Dialect. The PostgreSQL query
+	// timeout is the DBi's QueryTimeout.
+	QueryTimeout                      time.Duration
 	Params                            *chaincfg.Params
 	DevPrefetch, HidePGConfig         bool
 	AddrCacheRowCap, AddrCacheAddrCap int
 	AddrCacheUTXOByteCap              int
+	// Partitioning enables range partitioning of the vins, vouts, and
+	// addresses tables. Existing unpartitioned tables are migrated. If nil,
+	// new tables are not partitioned, but existing partitioned tables are
+	// still supported.
+	Partitioning *PartitionCfg
+	// ReplicaDSNs are PostgreSQL connection strings for read-only replicas
+	// of the database. When set, many read-only queries are sent to a
+	// replica that has caught up to the primary's best block.
+	ReplicaDSNs []string
+	// SlowQueries enables the slow query log, which logs the queries on the
+	// primary and replica connections that are slower than a threshold, and
+	// summarizes the recent ones in SlowQueryReport. If nil, queries are not
+	// timed.
+	SlowQueries *SlowQueryCfg
 }
 
 // The minimum required PostgreSQL version in integer format as returned by
@@ -474,121 +524,38 @@ const pgVerNumMin = 11_0000
 // are enabled. See EnableDuplicateCheckOnInsert to change this behavior.
 func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeDatabase,
 	mp rpcutils.MempoolAddressChecker, client *rpcclient.Client, shutdown func()) (*ChainDB, error) {
-	// Connect to the PostgreSQL daemon and return the *sql.DB.
-	dbi := cfg.DBi
-	db, err := Connect(dbi.Host, dbi.Port, dbi.User, dbi.Pass, dbi.DBName)
-	if err != nil {
-		return nil, err
-	}
-
-	// Put the PostgreSQL time zone in UTC.
-	var initTZ string
-	initTZ, err = CheckCurrentTimeZone(db)
-	if err != nil {
-		return nil, err
-	}
-	if initTZ != "UTC" {
-		log.Infof("Switching PostgreSQL time zone to UTC for this session.")
-		if _, err = db.Exec(`SET TIME ZONE UTC`); err != nil {
-			return nil, fmt.Errorf("Failed to set time zone to UTC: %w", err)
-		}
-	}
-
-	pgVersion, pgVerNum, err := retrievePGVersion(db)
-	if err != nil {
-		return nil, err
-	}
-	log.Info(pgVersion)
-	if pgVerNum < pgVerNumMin {
-		return nil, fmt.Errorf("PostgreSQL version %d.%d or greater is required; got %d.%d",
-			pgVerNumMin/10_000, pgVerNumMin%10_000, pgVerNum/10_000, pgVerNum%10_000)
-	}
-
-	// Optionally logs the PostgreSQL configuration.
-	if !cfg.HidePGConfig {
-		perfSettings, err := retrieveSysSettingsPerformance(db)
-		if err != nil {
-			return nil, err
-		}
-		log.Infof("postgres configuration settings:\n%v", perfSettings)
-
-		servSettings, err := retrieveSysSettingsServer(db)
-		if err != nil {
-			return nil, err
-		}
-		log.Infof("postgres server settings:\n%v", servSettings)
-	}
-
-	// Check the synchronous_commit setting.
-	syncCommit, err := retrieveSysSettingSyncCommit(db)
-	if err != nil {
-		return nil, err
-	}
-	if syncCommit != "off" {
-		log.Warnf(`PERFORMANCE ISSUE! The synchronous_commit setting is "%s". `+
-			`Changing it to "off".`, syncCommit)
-		// Turn off synchronous_commit.
-		if err = SetSynchronousCommit(db, "off"); err != nil {
-			return nil, fmt.Errorf("failed to set synchronous_commit: %w", err)
-		}
-		// Verify that the setting was changed.
-		if syncCommit, err = retrieveSysSettingSyncCommit(db); err != nil {
-			return nil, err
-		}
-		if syncCommit != "off" {
-			log.Errorf(`Failed to set synchronous_commit="off". Check PostgreSQL user permissions.`)
-		}
-	}
-
 	params := cfg.Params
 
-	// Perform any necessary database schema upgrades.
-	dbVer, compatAction, err := versionCheck(db)
-	switch err {
-	case nil:
-		if compatAction == OK {
-			// meta table present and no upgrades required
-			log.Infof("DB schema version %v", dbVer)
-			break
-		}
-
-		// Upgrades required
-		if client == nil {
-			return nil, fmt.Errorf("a rpcclient.Client is required for database upgrades")
-		}
-		// Do upgrades required by meta table versioning.
-		log.Infof("DB schema version %v upgrading to version %v", dbVer, targetDatabaseVersion)
-		upgrader := NewUpgrader(ctx, params, db, client, stakeDB)
-		success, err := upgrader.UpgradeDatabase()
-		if err != nil {
-			return nil, fmt.Errorf("failed to upgrade database: %w", err)
+	// Open the database, performing any necessary schema upgrades.
+	q := pgQueries
+	var db *sql.DB
+	var slowQueries *slowQueryLog
+	var queryTimeout time.Duration
+	var err error
+	if cfg.Dialect != nil {
+		if cfg.Partitioning != nil || len(cfg.ReplicaDSNs) > 0 || cfg.SlowQueries != nil {
+			return nil, fmt.Errorf("table partitioning, read replicas, and the "+
+				"slow query log are not supported for %s", cfg.Dialect.Name)
 		}
-		if !success {
-			return nil, fmt.Errorf("failed to upgrade database (upgrade not supported?)")
+		q = queries{cfg.Dialect}
+		if db, err = cfg.Dialect.Open(params); err != nil {
+			return nil, err
 		}
-	case tablesNotFoundErr:
-		// Empty database (no blocks table). Proceed to setupTables.
-		log.Infof(`Empty database "%s". Creating tables...`, dbi.DBName)
-		if err = CreateTables(db); err != nil {
-			return nil, fmt.Errorf("failed to create tables: %w", err)
+		queryTimeout = cfg.QueryTimeout
+	} else {
+		if cfg.SlowQueries != nil {
+			slowQueries = newSlowQueryLog(cfg.SlowQueries)
+			log.Infof("Logging queries slower than %v.", cfg.SlowQueries.Threshold)
 		}
-		err = initMetaData(db, &metaData{
-			netName:         params.Name,
-			currencyNet:     uint32(params.Net),
-			bestBlockHeight: -1,
-			dbVer:           *targetDatabaseVersion,
-		})
+		db, err = openPostgreSQL(ctx, cfg, stakeDB, client, slowQueries)
 		if err != nil {
-			return nil, fmt.Errorf("initMetaData failed: %w", err)
+			return nil, err
 		}
-	case metaNotFoundErr:
-		log.Errorf("Legacy DB versioning found. No upgrade supported. Wipe all data and start fresh.")
-	default:
-		return nil, err
+		queryTimeout = cfg.DBi.QueryTimeout
 	}
 
 	// Get the best block height from the blocks table.
-	bestHeight, bestHash, err := retrieveBestBlock(ctx, db)
+	bestHeight, bestHash, err := q.retrieveBestBlock(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("retrieveBestBlock: %w", err)
 	}
@@ -600,7 +567,7 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 	// table is ahead of the meta table, it is likely that the data for the best
 	// block was not fully inserted into all tables. Purge data back to the meta
 	// table's best block height. Also purge if the hashes do not match.
-	dbHash, dbHeightInit, err := dbBestBlock(ctx, db)
+	dbHash, dbHeightInit, err := q.dbBestBlock(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("dbBestBlock: %w", err)
 	}
@@ -625,11 +592,11 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		log.Warnf("Best block height in meta table (%d) "+
 			"greater than best height in blocks table (%d)!",
 			dbHeightInit, bestHeight)
-		_, bestHeight, bestHash, err = deleteBestBlock(ctx, db)
+		_, bestHeight, bestHash, err = q.deleteBestBlock(ctx, db)
 		if err != nil {
 			return nil, fmt.Errorf("DeleteBestBlock: %w", err)
 		}
-		dbHash, dbHeightInit, err = dbBestBlock(ctx, db)
+		dbHash, dbHeightInit, err = q.dbBestBlock(ctx, db)
 		if err != nil {
 			return nil, fmt.Errorf("dbBestBlock: %w", err)
 		}
@@ -644,7 +611,7 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 
 		// Delete the best block across all tables, updating the best block
 		// in the meta table.
-		_, bestHeight, bestHash, err = deleteBestBlock(ctx, db)
+		_, bestHeight, bestHash, err = q.deleteBestBlock(ctx, db)
 		if err != nil {
 			return nil, fmt.Errorf("DeleteBestBlock: %w", err)
 		}
@@ -654,7 +621,7 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 
 		// Now dbHash must equal bestHash. If not, DeleteBestBlock failed to
 		// update the meta table.
-		dbHash, _, err = dbBestBlock(ctx, db)
+		dbHash, _, err = q.dbBestBlock(ctx, db)
 		if err != nil {
 			return nil, fmt.Errorf("dbBestBlock: %w", err)
 		}
@@ -664,6 +631,47 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		}
 	}
 
+	// Partition the vins, vouts, and addresses tables if requested, and make
+	// sure there are partitions for the next block.
+	var partitioned bool
+	if q.postgres() {
+		if partitioned, err = tableIsPartitioned(db, "vins"); err != nil {
+			return nil, err
+		}
+	}
+	var partitions *tablePartitions
+	if partitioned || cfg.Partitioning != nil {
+		partitions = newTablePartitions(cfg.Partitioning, params.GenesisBlock.Header.Timestamp)
+		if !partitioned {
+			log.Infof("Migrating the vins, vouts, and addresses tables to partitioned tables...")
+			if err = partitionTables(db, partitions); err != nil {
+				return nil, fmt.Errorf("failed to partition tables: %w", err)
+			}
+		}
+		var bestBlockTime time.Time
+		err = db.QueryRow(internal.SelectMaxBlockTime, partitions.start).Scan(&bestBlockTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the best block time: %w", err)
+		}
+		var lastVoutID int64
+		if err = db.QueryRow(internal.SelectVoutsIDSeq).Scan(&lastVoutID); err != nil {
+			return nil, fmt.Errorf("failed to get the last vouts row ID: %w", err)
+		}
+		if err = partitions.ensure(db, bestBlockTime, lastVoutID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Connect to any read replicas.
+	var replicas *replicaSet
+	if len(cfg.ReplicaDSNs) > 0 {
+		replicas, err = connectReplicas(cfg.ReplicaDSNs, params.Name, slowQueries)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Connected to %d PostgreSQL read replica(s).", len(cfg.ReplicaDSNs))
+	}
+
 	// Project fund address of the current network
 	projectFundAddress, err := dbtypes.DevSubsidyAddress(params)
 	if err != nil {
@@ -671,8 +679,8 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 	}
 
 	log.Infof("Pre-loading unspent ticket info for InsertVote optimization.")
-	unspentTicketCache := NewTicketTxnIDGetter(db)
-	unspentTicketDbIDs, unspentTicketHashes, err := retrieveUnspentTickets(ctx, db)
+	unspentTicketCache := newTicketTxnIDGetter(q, db)
+	unspentTicketDbIDs, unspentTicketHashes, err := q.retrieveUnspentTickets(ctx, db)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) && !strings.HasSuffix(err.Error(), "does not exist") {
 		return nil, err
 	}
@@ -689,12 +697,11 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 
 	// If a query timeout is not set (i.e. zero), default to 24 hrs for
 	// essentially no timeout.
-	queryTimeout := dbi.QueryTimeout
 	if queryTimeout <= 0 {
 		queryTimeout = time.Hour
 	}
 
-	log.Infof("Setting PostgreSQL DB statement timeout to %v.", queryTimeout)
+	log.Infof("Setting %s DB statement timeout to %v.", q.Name, queryTimeout)
 
 	bestBlock := &BestBlock{
 		height: bestHeight,
@@ -711,6 +718,7 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		ctx:                ctx,
 		queryTimeout:       queryTimeout,
 		db:                 db,
+		q:                  q,
 		mp:                 mp,
 		chainParams:        params,
 		devAddress:         projectFundAddress,
@@ -724,7 +732,11 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		devPrefetch:        cfg.DevPrefetch,
 		tpUpdatePermission: tpUpdatePermissions,
 		utxoCache:          newUtxoStore(5e4),
+		partitions:         partitions,
+		replicas:           replicas,
+		slowQueries:        slowQueries,
 		mixSetDiffs:        make(map[uint32]int64),
+		coinAges:           newCoinAgeLedger(),
 		deployments:        new(ChainDeployments),
 		MPC:                new(mempool.DataCache),
 		BlockCache:         apitypes.NewAPICache(1e4),
@@ -746,8 +758,132 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 	return chainDB, nil
 }
 
-// Close closes the underlying sql.DB connection to the database.
+// openPostgreSQL connects to the PostgreSQL database described by the config's
+// DBInfo, checks the server's version and settings, and creates the tables or
+// upgrades them as needed.
+func openPostgreSQL(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeDatabase,
+	client *rpcclient.Client, slowQueries *slowQueryLog) (*sql.DB, error) {
+	dbi, params := cfg.DBi, cfg.Params
+
+	// Connect to the PostgreSQL daemon and return the *sql.DB.
+	db, err := connectDSN(connString(dbi.Host, dbi.Port, dbi.User, dbi.Pass, dbi.DBName),
+		slowQueries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Put the PostgreSQL time zone in UTC.
+	var initTZ string
+	initTZ, err = CheckCurrentTimeZone(db)
+	if err != nil {
+		return nil, err
+	}
+	if initTZ != "UTC" {
+		log.Infof("Switching PostgreSQL time zone to UTC for this session.")
+		if _, err = db.Exec(`SET TIME ZONE UTC`); err != nil {
+			return nil, fmt.Errorf("Failed to set time zone to UTC: %w", err)
+		}
+	}
+
+	pgVersion, pgVerNum, err := retrievePGVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	log.Info(pgVersion)
+	if pgVerNum < pgVerNumMin {
+		return nil, fmt.Errorf("PostgreSQL version %d.%d or greater is required; got %d.%d",
+			pgVerNumMin/10_000, pgVerNumMin%10_000, pgVerNum/10_000, pgVerNum%10_000)
+	}
+
+	// Optionally logs the PostgreSQL configuration.
+	if !cfg.HidePGConfig {
+		perfSettings, err := retrieveSysSettingsPerformance(db)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("postgres configuration settings:\n%v", perfSettings)
+
+		servSettings, err := retrieveSysSettingsServer(db)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("postgres server settings:\n%v", servSettings)
+	}
+
+	// Check the synchronous_commit setting.
+	syncCommit, err := retrieveSysSettingSyncCommit(db)
+	if err != nil {
+		return nil, err
+	}
+	if syncCommit != "off" {
+		log.Warnf(`PERFORMANCE ISSUE! The synchronous_commit setting is "%s". `+
+			`Changing it to "off".`, syncCommit)
+		// Turn off synchronous_commit.
+		if err = SetSynchronousCommit(db, "off"); err != nil {
+			return nil, fmt.Errorf("failed to set synchronous_commit: %w", err)
+		}
+		// Verify that the setting was changed.
+		if syncCommit, err = retrieveSysSettingSyncCommit(db); err != nil {
+			return nil, err
+		}
+		if syncCommit != "off" {
+			log.Errorf(`Failed to set synchronous_commit="off". Check PostgreSQL user permissions.`)
+		}
+	}
+
+	// Perform any necessary database schema upgrades.
+	dbVer, compatAction, err := versionCheck(db)
+	switch err {
+	case nil:
+		if compatAction == OK {
+			// meta table present and no upgrades required
+			log.Infof("DB schema version %v", dbVer)
+			break
+		}
+
+		// Upgrades required
+		if client == nil {
+			return nil, fmt.Errorf("a rpcclient.Client is required for database upgrades")
+		}
+		// Do upgrades required by meta table versioning.
+		log.Infof("DB schema version %v upgrading to version %v", dbVer, targetDatabaseVersion)
+		upgrader := NewUpgrader(ctx, params, db, client, stakeDB)
+		success, err := upgrader.UpgradeDatabase()
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade database: %w", err)
+		}
+		if !success {
+			return nil, fmt.Errorf("failed to upgrade database (upgrade not supported?)")
+		}
+	case tablesNotFoundErr:
+		// Empty database (no blocks table). Proceed to setupTables.
+		log.Infof(`Empty database "%s". Creating tables...`, dbi.DBName)
+		if err = CreateTables(db, cfg.Partitioning != nil); err != nil {
+			return nil, fmt.Errorf("failed to create tables: %w", err)
+		}
+		err = initMetaData(db, &metaData{
+			netName:         params.Name,
+			currencyNet:     uint32(params.Net),
+			bestBlockHeight: -1,
+			dbVer:           *targetDatabaseVersion,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("initMetaData failed: %w", err)
+		}
+	case metaNotFoundErr:
+		log.Errorf("Legacy DB versioning found. No upgrade supported. Wipe all data and start fresh.")
+	default:
+		return nil, err
+	}
+	return db, nil
+}
+
+// Close closes the underlying sql.DB connection to the database, and the
+// connections to any read replicas.
 func (pgb *ChainDB) Close() error {
+	if pgb.replicas != nil {
+		pgb.replicas.close()
+	}
 	return pgb.db.Close()
 }
 
@@ -839,27 +975,27 @@ func (pgb *ChainDB) DropTables() {
 
 // SideChainBlocks retrieves all known side chain blocks.
 func (pgb *ChainDB) SideChainBlocks() ([]*dbtypes.BlockStatus, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("SideChainBlocks")
 	defer cancel()
-	scb, err := retrieveSideChainBlocks(ctx, pgb.db)
+	scb, err := pgb.q.retrieveSideChainBlocks(ctx, pgb.readDB())
 	return scb, pgb.replaceCancelError(err)
 }
 
 // SideChainTips retrieves the tip/head block for all known side chains.
 /*
 func (pgb *ChainDB) SideChainTips() ([]*dbtypes.BlockStatus, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("SideChainTips")
 	defer cancel()
-	sct, err := retrieveSideChainTips(ctx, pgb.db)
+	sct, err := retrieveSideChainTips(ctx, pgb.readDB())
 	return sct, pgb.replaceCancelError(err)
 }
 */
 
 // DisapprovedBlocks retrieves all blocks disapproved by stakeholder votes.
 func (pgb *ChainDB) DisapprovedBlocks() ([]*dbtypes.BlockStatus, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("DisapprovedBlocks")
 	defer cancel()
-	disb, err := retrieveDisapprovedBlocks(ctx, pgb.db)
+	disb, err := pgb.q.retrieveDisapprovedBlocks(ctx, pgb.readDB())
 	return disb, pgb.replaceCancelError(err)
 }
 
@@ -870,22 +1006,22 @@ func chainHashFromStr(hash string) (ch dbtypes.ChainHash, err error) {
 
 // BlockStatus retrieves the block chain status of the specified block.
 func (pgb *ChainDB) BlockStatus(hash string) (dbtypes.BlockStatus, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockStatus")
 	defer cancel()
 	ch, err := chainHashFromStr(hash)
 	if err != nil {
 		return dbtypes.BlockStatus{}, err
 	}
-	bs, err := retrieveBlockStatus(ctx, pgb.db, ch)
+	bs, err := pgb.q.retrieveBlockStatus(ctx, pgb.db, ch)
 	return bs, pgb.replaceCancelError(err)
 }
 
 // BlockStatuses retrieves the block chain statuses of all blocks at the given
 // height.
 func (pgb *ChainDB) BlockStatuses(height int64) ([]*dbtypes.BlockStatus, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockStatuses")
 	defer cancel()
-	blocks, err := retrieveBlockStatuses(ctx, pgb.db, height)
+	blocks, err := pgb.q.retrieveBlockStatuses(ctx, pgb.readDB(), height)
 	return blocks, pgb.replaceCancelError(err)
 }
 
@@ -895,13 +1031,13 @@ func (pgb *ChainDB) blockFlags(ctx context.Context, hash string) (bool, bool, er
 	if err != nil {
 		return false, false, err
 	}
-	iv, im, err := retrieveBlockFlags(ctx, pgb.db, ch)
+	iv, im, err := pgb.q.retrieveBlockFlags(ctx, pgb.db, ch)
 	return iv, im, pgb.replaceCancelError(err)
 }
 
 // BlockFlags retrieves the block's isValid and isMainchain flags.
 func (pgb *ChainDB) BlockFlags(hash string) (bool, bool, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockFlags")
 	defer cancel()
 	return pgb.blockFlags(ctx, hash)
 }
@@ -914,7 +1050,7 @@ func (pgb *ChainDB) BlockFlagsNoCancel(hash string) (bool, bool, error) {
 // blockChainDbID gets the row ID of the given block hash in the block_chain
 // table. The cancellation context is used without timeout.
 func (pgb *ChainDB) blockChainDbID(ctx context.Context, ch dbtypes.ChainHash) (dbID uint64, err error) {
-	err = pgb.db.QueryRowContext(ctx, internal.SelectBlockChainRowIDByHash, ch).Scan(&dbID)
+	err = pgb.db.QueryRowContext(ctx, pgb.q.SelectBlockChainRowIDByHash, ch).Scan(&dbID)
 	err = pgb.replaceCancelError(err)
 	return
 }
@@ -989,6 +1125,19 @@ func (pgb *ChainDB) RegisterCharts(charts *cache.ChartData) {
 		Fetcher:  pgb.poolStats,
 		Appender: appendPoolStats,
 	})
+
+	coinAgeSeries := cache.CoinAgeSeries(cache.ChartUpdater{
+		Tag:       "coin ages",
+		Fetcher:   pgb.coinAgeEvents,
+		Appender:  pgb.appendCoinAges, // ChainDB's method since it keeps the ledger.
+		SaveState: pgb.saveCoinAges,
+		LoadState: pgb.loadCoinAges,
+	})
+	for _, series := range coinAgeSeries {
+		if err := charts.RegisterSeries(series); err != nil {
+			log.Errorf("Failed to register the %s chart series: %v", series.Name, err)
+		}
+	}
 }
 
 // TransactionBlocks retrieves the blocks in which the specified transaction
@@ -999,9 +1148,9 @@ func (pgb *ChainDB) TransactionBlocks(txHash string) ([]*dbtypes.BlockStatus, []
 	if err != nil {
 		return nil, nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TransactionBlocks")
 	defer cancel()
-	hashes, heights, inds, valids, mainchains, err := retrieveTxnsBlocks(ctx, pgb.db, ch)
+	hashes, heights, inds, valids, mainchains, err := pgb.q.retrieveTxnsBlocks(ctx, pgb.readDB(), ch)
 	if err != nil {
 		return nil, nil, pgb.replaceCancelError(err)
 	}
@@ -1023,35 +1172,35 @@ func (pgb *ChainDB) TransactionBlocks(txHash string) ([]*dbtypes.BlockStatus, []
 
 // HeightDB retrieves the best block height according to the meta table.
 func (pgb *ChainDB) HeightDB() (int64, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("HeightDB")
 	defer cancel()
-	_, height, err := dbBestBlock(ctx, pgb.db)
+	_, height, err := pgb.q.dbBestBlock(ctx, pgb.db)
 	return height, pgb.replaceCancelError(err)
 }
 
 // HashDB retrieves the best block hash according to the meta table.
 func (pgb *ChainDB) HashDB() (string, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("HashDB")
 	defer cancel()
-	hash, _, err := dbBestBlock(ctx, pgb.db)
+	hash, _, err := pgb.q.dbBestBlock(ctx, pgb.db)
 	return hash.String(), pgb.replaceCancelError(err)
 }
 
 // HeightHashDB retrieves the best block height and hash according to the meta
 // table.
 func (pgb *ChainDB) HeightHashDB() (int64, string, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("HeightHashDB")
 	defer cancel()
-	hash, height, err := dbBestBlock(ctx, pgb.db)
+	hash, height, err := pgb.q.dbBestBlock(ctx, pgb.db)
 	return height, hash.String(), pgb.replaceCancelError(err)
 }
 
 // HeightDBLegacy queries the blocks table for the best block height. When the
 // tables are empty, the returned height will be -1.
 func (pgb *ChainDB) HeightDBLegacy() (int64, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("HeightDBLegacy")
 	defer cancel()
-	bestHeight, _, err := retrieveBestBlockHeight(ctx, pgb.db)
+	bestHeight, _, err := pgb.q.retrieveBestBlockHeight(ctx, pgb.db)
 	height := int64(bestHeight)
 	if errors.Is(err, sql.ErrNoRows) {
 		height = -1
@@ -1061,18 +1210,18 @@ func (pgb *ChainDB) HeightDBLegacy() (int64, error) {
 
 // HashDBLegacy queries the blocks table for the best block's hash.
 func (pgb *ChainDB) HashDBLegacy() (string, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("HashDBLegacy")
 	defer cancel()
-	_, bestHash, err := retrieveBestBlockHeight(ctx, pgb.db)
+	_, bestHash, err := pgb.q.retrieveBestBlockHeight(ctx, pgb.db)
 	return bestHash.String(), pgb.replaceCancelError(err)
 }
 
 // HeightHashDBLegacy queries the blocks table for the best block's height and
 // hash.
 func (pgb *ChainDB) HeightHashDBLegacy() (uint64, string, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("HeightHashDBLegacy")
 	defer cancel()
-	height, hash, err := retrieveBestBlockHeight(ctx, pgb.db)
+	height, hash, err := pgb.q.retrieveBestBlockHeight(ctx, pgb.db)
 	return height, hash.String(), pgb.replaceCancelError(err)
 }
 
@@ -1100,6 +1249,13 @@ func (pgb *ChainDB) GetBestBlockHash() (string, error) {
 	return pgb.BestBlockHashStr(), nil
 }
 
+// heightHash uses the last stored block height and hash.
+func (block *BestBlock) heightHash() (int64, dbtypes.ChainHash) {
+	block.mtx.RLock()
+	defer block.mtx.RUnlock()
+	return block.height, block.hash
+}
+
 // HashStr uses the last stored block hash.
 func (block *BestBlock) HashStr() string {
 	block.mtx.RLock()
@@ -1138,9 +1294,9 @@ func (pgb *ChainDB) BestBlockHashStr() string {
 }
 
 func (pgb *ChainDB) blockHeight(hash dbtypes.ChainHash) (int64, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("blockHeight")
 	defer cancel()
-	height, err := retrieveBlockHeight(ctx, pgb.db, hash)
+	height, err := pgb.q.retrieveBlockHeight(ctx, pgb.db, hash)
 	return height, pgb.replaceCancelError(err)
 }
 
@@ -1156,21 +1312,30 @@ func (pgb *ChainDB) BlockHeight(hash string) (int64, error) {
 // BlockHash queries the DB for the hash of the mainchain block at the given
 // height.
 func (pgb *ChainDB) BlockHash(height int64) (string, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockHash")
 	defer cancel()
-	hash, err := retrieveBlockHash(ctx, pgb.db, height)
+	hash, err := pgb.q.retrieveBlockHash(ctx, pgb.db, height)
 	return hash.String(), pgb.replaceCancelError(err)
 }
 
 // BlockTimeByHeight queries the DB for the time of the mainchain block at the
 // given height.
 func (pgb *ChainDB) BlockTimeByHeight(height int64) (int64, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockTimeByHeight")
 	defer cancel()
-	time, err := retrieveBlockTimeByHeight(ctx, pgb.db, height)
+	time, err := pgb.q.retrieveBlockTimeByHeight(ctx, pgb.readDB(), height)
 	return time.UNIX(), pgb.replaceCancelError(err)
 }
 
+// BlockHeightByTime gets the height of the first mainchain block at or after
+// the time.
+func (pgb *ChainDB) BlockHeightByTime(timestamp int64) (int64, error) {
+	ctx, cancel := pgb.queryCtx("BlockHeightByTime")
+	defer cancel()
+	height, err := pgb.q.retrieveBlockHeightByTime(ctx, pgb.readDB(), timestamp)
+	return height, pgb.replaceCancelError(err)
+}
+
 // VotesInBlock returns the number of votes mined in the block with the
 // specified hash.
 func (pgb *ChainDB) VotesInBlock(hash string) (int16, error) {
@@ -1178,9 +1343,9 @@ func (pgb *ChainDB) VotesInBlock(hash string) (int16, error) {
 	if err != nil {
 		return 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("VotesInBlock")
 	defer cancel()
-	voters, err := retrieveBlockVoteCount(ctx, pgb.db, ch)
+	voters, err := pgb.q.retrieveBlockVoteCount(ctx, pgb.db, ch)
 	if err != nil {
 		err = pgb.replaceCancelError(err)
 		log.Errorf("Unable to get block voter count for hash %s: %v", hash, err)
@@ -1207,9 +1372,9 @@ func (pgb *ChainDB) SpendingTransactions(fundingTxID string) ([]string, []uint32
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("SpendingTransactions")
 	defer cancel()
-	_, spendingTxns, vinInds, voutInds, err := retrieveSpendingTxsByFundingTx(ctx, pgb.db, ch)
+	_, spendingTxns, vinInds, voutInds, err := pgb.q.retrieveSpendingTxsByFundingTx(ctx, pgb.readDB(), ch)
 	txStrs := make([]string, len(spendingTxns))
 	for i := range spendingTxns {
 		txStrs[i] = spendingTxns[i].String()
@@ -1225,9 +1390,9 @@ func (pgb *ChainDB) SpendingTransaction(fundingTxID string, fundingTxVout uint32
 	if err != nil {
 		return "", 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("SpendingTransaction")
 	defer cancel()
-	_, spendingTx, vinInd, err := retrieveSpendingTxByTxOut(ctx, pgb.db, ch, fundingTxVout)
+	_, spendingTx, vinInd, err := pgb.q.retrieveSpendingTxByTxOut(ctx, pgb.readDB(), ch, fundingTxVout)
 	return spendingTx.String(), vinInd, pgb.replaceCancelError(err)
 }
 
@@ -1238,9 +1403,9 @@ func (pgb *ChainDB) BlockTransactions(blockHash string) ([]string, []uint32, []i
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockTransactions")
 	defer cancel()
-	blockTransactions, blockInds, trees, _, err := retrieveTxsByBlockHash(ctx, pgb.db, ch)
+	blockTransactions, blockInds, trees, _, err := pgb.q.retrieveTxsByBlockHash(ctx, pgb.readDB(), ch)
 	txStrs := make([]string, len(blockTransactions))
 	for i := range blockTransactions {
 		txStrs[i] = blockTransactions[i].String()
@@ -1255,9 +1420,9 @@ func (pgb *ChainDB) Transaction(txHash string) ([]*dbtypes.Tx, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("Transaction")
 	defer cancel()
-	_, dbTxs, err := retrieveDbTxsByHash(ctx, pgb.db, ch)
+	_, dbTxs, err := pgb.q.retrieveDbTxsByHash(ctx, pgb.readDB(), ch)
 	return dbTxs, pgb.replaceCancelError(err)
 }
 
@@ -1268,9 +1433,9 @@ func (pgb *ChainDB) BlockMissedVotes(blockHash string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BlockMissedVotes")
 	defer cancel()
-	mv, err := retrieveMissedVotesInBlock(ctx, pgb.db, ch)
+	mv, err := pgb.q.retrieveMissedVotesInBlock(ctx, pgb.readDB(), ch)
 	txStrs := make([]string, len(mv))
 	for i := range mv {
 		txStrs[i] = mv[i].String()
@@ -1281,21 +1446,76 @@ func (pgb *ChainDB) BlockMissedVotes(blockHash string) ([]string, error) {
 // missedVotesForBlockRange retrieves the number of missed votes for the block
 // range specified.
 func (pgb *ChainDB) missedVotesForBlockRange(startHeight, endHeight int64) (int64, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("missedVotesForBlockRange")
 	defer cancel()
-	missed, err := retrieveMissedVotesForBlockRange(ctx, pgb.db, startHeight, endHeight)
+	missed, err := pgb.q.retrieveMissedVotesForBlockRange(ctx, pgb.readDB(), startHeight, endHeight)
 	return missed, pgb.replaceCancelError(err)
 }
 
+// VSPTicketSpends counts the spent mainchain tickets whose votes or
+// revocations paid each of the VSP fee addresses.
+func (pgb *ChainDB) VSPTicketSpends(feeAddrs []string) ([]*dbtypes.VSPAddressTickets, error) {
+	ctx, cancel := pgb.queryCtx("VSPTicketSpends")
+	defer cancel()
+	spends, err := pgb.q.retrieveVSPTicketSpends(ctx, pgb.readDB(), feeAddrs)
+	return spends, pgb.replaceCancelError(err)
+}
+
+// TicketSpendAddresses gets the mainchain tickets with the hashes, with the
+// VSP fee address of feeAddrs paid by their votes or revocations, if any.
+func (pgb *ChainDB) TicketSpendAddresses(tickets, feeAddrs []string) ([]*dbtypes.TicketSpendAddress, error) {
+	ctx, cancel := pgb.queryCtx("TicketSpendAddresses")
+	defer cancel()
+	spends, err := pgb.q.retrieveTicketSpendAddresses(ctx, pgb.readDB(), tickets, feeAddrs)
+	return spends, pgb.replaceCancelError(err)
+}
+
+// StoreIndexPrices stores index prices of the exchange rate history. A price
+// that is not backfilled replaces any price stored for the same currency and
+// interval, while a backfilled price is only stored if there is no such price.
+func (pgb *ChainDB) StoreIndexPrices(prices []*dbtypes.IndexPrice) error {
+	return pgb.q.insertExchangeRates(pgb.db, prices)
+}
+
+// IndexPriceAt gets the latest index price of the currency for an interval
+// starting in the maxAge before t, up to and including t. The error is
+// dbtypes.ErrNoResult if there is no such price.
+func (pgb *ChainDB) IndexPriceAt(currency string, t time.Time, maxAge time.Duration) (*dbtypes.IndexPrice, error) {
+	ctx, cancel := pgb.queryCtx("IndexPriceAt")
+	defer cancel()
+	price, err := pgb.q.retrieveExchangeRateAt(ctx, pgb.readDB(), currency, t, maxAge)
+	return price, pgb.replaceCancelError(err)
+}
+
+// IndexPriceMean gets the mean index price of the currency over the intervals
+// starting from start up to but not including end, with each day that has a
+// price weighted equally. The error is dbtypes.ErrNoResult if there are no
+// prices in the period.
+func (pgb *ChainDB) IndexPriceMean(currency string, start, end time.Time) (float64, error) {
+	ctx, cancel := pgb.queryCtx("IndexPriceMean")
+	defer cancel()
+	price, err := pgb.q.retrieveExchangeRateMean(ctx, pgb.readDB(), currency, start, end)
+	return price, pgb.replaceCancelError(err)
+}
+
+// MeanVoteAges gets the mean ages of the mainchain votes in a range of blocks,
+// in bins of binSize blocks by vote height.
+func (pgb *ChainDB) MeanVoteAges(start, end, binSize int64) ([]*dbtypes.VoteAgeBin, error) {
+	ctx, cancel := pgb.queryCtx("MeanVoteAges")
+	defer cancel()
+	bins, err := pgb.q.retrieveMeanVoteAges(ctx, pgb.readDB(), start, end, binSize)
+	return bins, pgb.replaceCancelError(err)
+}
+
 // TicketMisses retrieves all blocks in which the specified ticket was called to
 // vote but failed to do so (miss). There may be multiple since this consideres
 // side chain blocks. See TicketMiss for a mainchain-only version. If the ticket
 // never missed a vote, the returned error will be dbtypes.ErrNoResult.
 /*
 func (pgb *ChainDB) TicketMisses(ticketHash string) ([]string, []int64, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TicketMisses")
 	defer cancel()
-	blockHashes, blockHeights, err := retrieveMissesForTicket(ctx, pgb.db, ticketHash)
+	blockHashes, blockHeights, err := retrieveMissesForTicket(ctx, pgb.readDB(), ticketHash)
 	return blockHashes, blockHeights, pgb.replaceCancelError(err)
 }
 */
@@ -1308,9 +1528,9 @@ func (pgb *ChainDB) TicketMiss(ticketHash string) (string, int64, error) {
 	if err != nil {
 		return "", 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TicketMiss")
 	defer cancel()
-	blockHash, blockHeight, err := retrieveMissForTicket(ctx, pgb.db, ch)
+	blockHash, blockHeight, err := pgb.q.retrieveMissForTicket(ctx, pgb.readDB(), ch)
 	return blockHash.String(), blockHeight, pgb.replaceCancelError(err)
 }
 
@@ -1321,9 +1541,9 @@ func (pgb *ChainDB) PoolStatusForTicket(txid string) (dbtypes.TicketSpendType, d
 	if err != nil {
 		return 0, 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("PoolStatusForTicket")
 	defer cancel()
-	_, spendType, poolStatus, err := retrieveTicketStatusByHash(ctx, pgb.db, ch)
+	_, spendType, poolStatus, err := pgb.q.retrieveTicketStatusByHash(ctx, pgb.readDB(), ch)
 	return spendType, poolStatus, pgb.replaceCancelError(err)
 }
 
@@ -1334,9 +1554,9 @@ func (pgb *ChainDB) VoutValue(txID string, vout uint32) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("VoutValue")
 	defer cancel()
-	voutValue, err := retrieveVoutValue(ctx, pgb.db, ch, vout)
+	voutValue, err := pgb.q.retrieveVoutValue(ctx, pgb.readDB(), ch, vout)
 	if err != nil {
 		return 0, pgb.replaceCancelError(err)
 	}
@@ -1352,9 +1572,9 @@ func (pgb *ChainDB) VoutValues(txID string) ([]uint64, []uint32, []int8, error)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("VoutValues")
 	defer cancel()
-	voutValues, txInds, txTrees, err := retrieveVoutValues(ctx, pgb.db, ch)
+	voutValues, txInds, txTrees, err := pgb.q.retrieveVoutValues(ctx, pgb.readDB(), ch)
 	if err != nil {
 		return nil, nil, nil, pgb.replaceCancelError(err)
 	}
@@ -1369,16 +1589,16 @@ func (pgb *ChainDB) TransactionBlock(txID string) (string, uint32, int8, error)
 	if err != nil {
 		return "", 0, 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TransactionBlock")
 	defer cancel()
-	_, blockHash, blockInd, tree, err := retrieveTxByHash(ctx, pgb.db, ch)
+	_, blockHash, blockInd, tree, err := pgb.q.retrieveTxByHash(ctx, pgb.readDB(), ch)
 	return blockHash.String(), blockInd, tree, pgb.replaceCancelError(err)
 }
 
 // AgendaVotes fetches the data used to plot a graph of votes cast per day per
 // choice for the provided agenda.
 func (pgb *ChainDB) AgendaVotes(agendaID string, chartType int) (*dbtypes.AgendaVoteChoices, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AgendaVotes")
 	defer cancel()
 
 	chainInfo := pgb.ChainInfo()
@@ -1389,7 +1609,7 @@ func (pgb *ChainDB) AgendaVotes(agendaID string, chartType int) (*dbtypes.Agenda
 		return nil, nil
 	}
 
-	avc, err := retrieveAgendaVoteChoices(ctx, pgb.db, agendaID, chartType,
+	avc, err := pgb.q.retrieveAgendaVoteChoices(ctx, pgb.readDB(), agendaID, chartType,
 		agendaInfo.VotingStarted, agendaInfo.VotingDone)
 	return avc, pgb.replaceCancelError(err)
 }
@@ -1397,7 +1617,7 @@ func (pgb *ChainDB) AgendaVotes(agendaID string, chartType int) (*dbtypes.Agenda
 // AgendasVotesSummary fetches the total vote choices count for the provided
 // agenda.
 func (pgb *ChainDB) AgendasVotesSummary(agendaID string) (summary *dbtypes.AgendaSummary, err error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AgendasVotesSummary")
 	defer cancel()
 
 	chainInfo := pgb.ChainInfo()
@@ -1413,14 +1633,14 @@ func (pgb *ChainDB) AgendasVotesSummary(agendaID string) (summary *dbtypes.Agend
 		LockedIn:      agendaInfo.VotingDone,
 	}
 
-	summary.Yes, summary.Abstain, summary.No, err = retrieveTotalAgendaVotesCount(ctx,
-		pgb.db, agendaID, agendaInfo.VotingStarted, agendaInfo.VotingDone)
+	summary.Yes, summary.Abstain, summary.No, err = pgb.q.retrieveTotalAgendaVotesCount(ctx,
+		pgb.readDB(), agendaID, agendaInfo.VotingStarted, agendaInfo.VotingDone)
 	return
 }
 
 // AgendaVoteCounts returns the vote counts for the agenda as builtin types.
 func (pgb *ChainDB) AgendaVoteCounts(agendaID string) (yes, abstain, no uint32, err error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AgendaVoteCounts")
 	defer cancel()
 
 	chainInfo := pgb.ChainInfo()
@@ -1431,13 +1651,65 @@ func (pgb *ChainDB) AgendaVoteCounts(agendaID string) (yes, abstain, no uint32,
 		return
 	}
 
-	return retrieveTotalAgendaVotesCount(ctx, pgb.db, agendaID,
+	return pgb.q.retrieveTotalAgendaVotesCount(ctx, pgb.readDB(), agendaID,
 		agendaInfo.VotingStarted, agendaInfo.VotingDone)
 }
 
+// AgendaVoteHistory fetches the vote choices tally for the provided agenda and
+// the stake and block versions by rule change interval, from the first
+// interval with votes on the agenda to the end of its voting period, or the
+// best block if voting has not ended.
+func (pgb *ChainDB) AgendaVoteHistory(agendaID string) (*dbtypes.AgendaVoteHistory, error) {
+	ctx, cancel := pgb.queryCtx("AgendaVoteHistory")
+	defer cancel()
+
+	chainInfo := pgb.ChainInfo()
+	agendaInfo, ok := chainInfo.AgendaMileStones[agendaID]
+	if !ok {
+		return nil, fmt.Errorf("unknown agenda %q", agendaID)
+	}
+
+	svh := pgb.chainParams.StakeValidationHeight
+	rciBlocks := int64(pgb.chainParams.RuleChangeActivationInterval)
+	tip := pgb.Height()
+	end := tip
+	if agendaInfo.VotingDone > 0 && agendaInfo.VotingDone < end {
+		end = agendaInfo.VotingDone
+	}
+	history := &dbtypes.AgendaVoteHistory{
+		MileStone: agendaInfo,
+		Height:    tip,
+	}
+	if end < svh {
+		return history, nil
+	}
+
+	db := pgb.readDB()
+	var err error
+	history.Votes, err = pgb.q.retrieveAgendaVotesByRCI(ctx, db, agendaID, svh, rciBlocks, end)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	start := svh + (end-svh)/rciBlocks*rciBlocks
+	if len(history.Votes) > 0 {
+		start = svh + history.Votes[0].RCI*rciBlocks
+	}
+	history.StakeVersions, err = retrieveVersionsByRCI(ctx, db, pgb.q.SelectVoteVersionsByRCI,
+		svh, rciBlocks, start, end)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	history.BlockVersions, err = retrieveVersionsByRCI(ctx, db, pgb.q.SelectBlockVersionsByRCI,
+		svh, rciBlocks, start, end)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return history, nil
+}
+
 // AllAgendas returns all the agendas stored currently.
 func (pgb *ChainDB) AllAgendas() (map[string]dbtypes.MileStone, error) {
-	return retrieveAllAgendas(pgb.db)
+	return pgb.q.retrieveAllAgendas(pgb.db)
 }
 
 // NumAddressIntervals gets the number of unique time intervals for the
@@ -1448,7 +1720,7 @@ func (pgb *ChainDB) NumAddressIntervals(addr string, grouping dbtypes.TimeBasedG
 	if grouping >= dbtypes.NumIntervals {
 		return 0, fmt.Errorf("invalid time grouping %d", grouping)
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("NumAddressIntervals")
 	defer cancel()
 	return retrieveAddressTxsCount(ctx, pgb.db, addr, grouping.String())
 }
@@ -1488,7 +1760,7 @@ func (pgb *ChainDB) AddressMetrics(addr string) (*dbtypes.AddressMetrics, error)
 
 	// Get the time of the block with the first transaction involving the
 	// address (oldest transaction block time).
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AddressMetrics")
 	defer cancel()
 	blockTime, err := retrieveOldestTxBlockTime(ctx, pgb.db, addr)
 	if err != nil {
@@ -1530,7 +1802,7 @@ func (pgb *ChainDB) AddressTransactions(address string, N, offset int64,
 		return nil, fmt.Errorf("unknown AddrTxnViewType %v", txnType)
 	}
 
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AddressTransactions")
 	defer cancel()
 
 	addressRows, err = addrFunc(ctx, pgb.db, address, N, offset)
@@ -1544,11 +1816,11 @@ func (pgb *ChainDB) AddressTransactions(address string, N, offset int64,
 // that may be returned, which is more than 4x the count for the treasury
 // address as of mainnet block 521900.
 func (pgb *ChainDB) AddressTransactionsAll(address string) (addressRows []*dbtypes.AddressRow, err error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AddressTransactionsAll")
 	defer cancel()
 
 	const limit = 3000000
-	addressRows, err = retrieveAddressTxns(ctx, pgb.db, address, limit, 0)
+	addressRows, err = pgb.q.retrieveAddressTxns(ctx, pgb.readDB(), address, limit, 0)
 	// addressRows, err = retrieveAllMainchainAddressTxns(ctx, pgb.db, address)
 	err = pgb.replaceCancelError(err)
 	return
@@ -1559,11 +1831,11 @@ func (pgb *ChainDB) AddressTransactionsAll(address string) (addressRows []*dbtyp
 // presently a hard limit of 3 million rows that may be returned, which is more
 // than 4x the count for the treasury address as of mainnet block 521900.
 func (pgb *ChainDB) AddressTransactionsAllMerged(address string) (addressRows []*dbtypes.AddressRow, err error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AddressTransactionsAllMerged")
 	defer cancel()
 
 	const limit = 3000000
-	addressRows, err = retrieveAddressMergedTxns(ctx, pgb.db, address, limit, 0)
+	addressRows, err = pgb.q.retrieveAddressMergedTxns(ctx, pgb.readDB(), address, limit, 0)
 	// const onlyValidMainchain = true
 	// _, addressRows, err = retrieveAllAddressMergedTxns(ctx, pgb.db, address,
 	// 	onlyValidMainchain)
@@ -1588,9 +1860,9 @@ func (pgb *ChainDB) TicketPoolBlockMaturity() int64 {
 // interval provided and an error value.
 func (pgb *ChainDB) TicketPoolByDateAndInterval(maturityBlock int64,
 	interval dbtypes.TimeBasedGrouping) (*dbtypes.PoolTicketsData, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TicketPoolByDateAndInterval")
 	defer cancel()
-	tpd, err := retrieveTicketsByDate(ctx, pgb.db, maturityBlock, interval.String())
+	tpd, err := pgb.q.retrieveTicketsByDate(ctx, pgb.readDB(), maturityBlock, interval.String())
 	return tpd, pgb.replaceCancelError(err)
 }
 
@@ -1600,9 +1872,9 @@ func (pgb *ChainDB) TicketPoolByDateAndInterval(maturityBlock int64,
 // chainParams.StakeDiffWindowSize block interval the ticket price and the
 // difficulty value is constant.
 func (pgb *ChainDB) PosIntervals(limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("PosIntervals")
 	defer cancel()
-	bgi, err := retrieveWindowBlocks(ctx, pgb.db,
+	bgi, err := pgb.q.retrieveWindowBlocks(ctx, pgb.readDB(),
 		pgb.chainParams.StakeDiffWindowSize, pgb.Height(), limit, offset)
 	return bgi, pgb.replaceCancelError(err)
 }
@@ -1615,9 +1887,9 @@ func (pgb *ChainDB) TimeBasedIntervals(timeGrouping dbtypes.TimeBasedGrouping,
 	if timeGrouping >= dbtypes.NumIntervals {
 		return nil, fmt.Errorf("invalid time grouping %d", timeGrouping)
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TimeBasedIntervals")
 	defer cancel()
-	bgi, err := retrieveTimeBasedBlockListing(ctx, pgb.db, timeGrouping.String(),
+	bgi, err := pgb.q.retrieveTimeBasedBlockListing(ctx, pgb.readDB(), timeGrouping.String(),
 		limit, offset)
 	return bgi, pgb.replaceCancelError(err)
 }
@@ -1744,9 +2016,10 @@ func (pgb *ChainDB) GetTicketInfo(txid string) (*apitypes.TicketInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("GetTicketInfo")
 	defer cancel()
-	spendStatus, poolStatus, purchaseBlock, lotteryBlock, spendTxid, err := retrieveTicketInfoByHash(ctx, pgb.db, ch)
+	db := pgb.readDB()
+	spendStatus, poolStatus, purchaseBlock, lotteryBlock, spendTxid, err := pgb.q.retrieveTicketInfoByHash(ctx, db, ch)
 	if err != nil {
 		return nil, pgb.replaceCancelError(err)
 	}
@@ -1768,7 +2041,7 @@ func (pgb *ChainDB) GetTicketInfo(txid string) (*apitypes.TicketInfo, error) {
 	}
 
 	if poolStatus == dbtypes.PoolStatusMissed {
-		hash, height, err := retrieveMissForTicket(ctx, pgb.db, ch)
+		hash, height, err := pgb.q.retrieveMissForTicket(ctx, db, ch)
 		if err != nil {
 			return nil, pgb.replaceCancelError(err)
 		}
@@ -1824,7 +2097,7 @@ func (pgb *ChainDB) TreasuryBalance() (*dbtypes.TreasuryBalance, error) {
 	_, tipHeight := pgb.BestBlock()
 	maturityHeight := tipHeight - int64(pgb.chainParams.CoinbaseMaturity)
 
-	rows, err := pgb.db.QueryContext(pgb.ctx, internal.SelectTreasuryBalance, maturityHeight)
+	rows, err := pgb.readDB().QueryContext(pgb.ctx, pgb.q.SelectTreasuryBalance, maturityHeight)
 	if err != nil {
 		return nil, err
 	}
@@ -1882,9 +2155,9 @@ func (pgb *ChainDB) TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtyp
 	var err error
 	switch txType {
 	case -1:
-		rows, err = pgb.db.QueryContext(pgb.ctx, internal.SelectTreasuryTxns, n, offset)
+		rows, err = pgb.readDB().QueryContext(pgb.ctx, pgb.q.SelectTreasuryTxns, n, offset)
 	default:
-		rows, err = pgb.db.QueryContext(pgb.ctx, internal.SelectTypedTreasuryTxns, txType, n, offset)
+		rows, err = pgb.readDB().QueryContext(pgb.ctx, pgb.q.SelectTypedTreasuryTxns, txType, n, offset)
 	}
 
 	if err != nil {
@@ -1937,7 +2210,7 @@ func (pgb *ChainDB) FreshenAddressCaches(lazyProjectFund bool, expireAddresses [
 
 	// Do not initiate project fund queries if a reorg is in progress, or
 	// pre-fetch is disabled.
-	if !pgb.devPrefetch || pgb.InReorg {
+	if !pgb.devPrefetch || pgb.InReorg.Load() {
 		return nil
 	}
 
@@ -1965,7 +2238,7 @@ func (pgb *ChainDB) DevBalance() (*dbtypes.AddressBalance, error) {
 		return cachedBalance, nil
 	}
 
-	if !pgb.InReorg {
+	if !pgb.InReorg.Load() {
 		bal, _, err := pgb.AddressBalance(pgb.devAddress)
 		if err != nil {
 			return nil, err
@@ -2020,9 +2293,9 @@ func (pgb *ChainDB) AddressBalance(address string) (bal *dbtypes.AddressBalance,
 	log.Tracef("AddressBalance: cache MISS for %s.", address)
 
 	// Cache is empty or stale, so query the DB.
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("AddressBalance")
 	defer cancel()
-	bal, err = retrieveAddressBalance(ctx, pgb.db, address)
+	bal, err = pgb.q.retrieveAddressBalance(ctx, pgb.readDB(), address)
 	if err != nil {
 		err = pgb.replaceCancelError(err)
 		return
@@ -2154,18 +2427,18 @@ func (pgb *ChainDB) AddressRowsCompact(address string) ([]*dbtypes.AddressRowCom
 // retrieveMergedTxnCount queries the DB for the merged address transaction view
 // row count.
 func (pgb *ChainDB) retrieveMergedTxnCount(addr string, txnView dbtypes.AddrTxnViewType) (int, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("retrieveMergedTxnCount")
 	defer cancel()
 
 	var count int64
 	var err error
 	switch txnView {
 	case dbtypes.AddrMergedTxnDebit:
-		count, err = countMergedSpendingTxns(ctx, pgb.db, addr)
+		count, err = pgb.q.countMergedSpendingTxns(ctx, pgb.readDB(), addr)
 	case dbtypes.AddrMergedTxnCredit:
-		count, err = countMergedFundingTxns(ctx, pgb.db, addr)
+		count, err = pgb.q.countMergedFundingTxns(ctx, pgb.readDB(), addr)
 	case dbtypes.AddrMergedTxn:
-		count, err = countMergedTxns(ctx, pgb.db, addr)
+		count, err = pgb.q.countMergedTxns(ctx, pgb.readDB(), addr)
 	default:
 		return 0, fmt.Errorf("retrieveMergedTxnCount: requested count for non-merged view")
 	}
@@ -2577,9 +2850,9 @@ func (pgb *ChainDB) DbTxByHash(txid string) (*dbtypes.Tx, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("DbTxByHash")
 	defer cancel()
-	_, dbTx, err := retrieveDbTxByHash(ctx, pgb.db, ch)
+	_, dbTx, err := pgb.q.retrieveDbTxByHash(ctx, pgb.db, ch)
 	return dbTx, pgb.replaceCancelError(err)
 }
 
@@ -2587,9 +2860,9 @@ func (pgb *ChainDB) DbTxByHash(txid string) (*dbtypes.Tx, error) {
 // previous outpoint for a transaction input specified by row ID in the vins
 // table, which stores previous outpoints for each vin.
 func (pgb *ChainDB) fundingOutpointIndxByVinID(id uint64) (uint32, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("fundingOutpointIndxByVinID")
 	defer cancel()
-	ind, err := retrieveFundingOutpointIndxByVinID(ctx, pgb.db, id)
+	ind, err := pgb.q.retrieveFundingOutpointIndxByVinID(ctx, pgb.db, id)
 	return ind, pgb.replaceCancelError(err)
 }
 
@@ -2890,7 +3163,7 @@ func (pgb *ChainDB) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBloc
 
 // PurgeBestBlocks deletes all data for the N best blocks in the DB.
 func (pgb *ChainDB) PurgeBestBlocks(N int64) (*dbtypes.DeletionSummary, int64, error) {
-	res, height, _, err := deleteBlocks(pgb.ctx, N, pgb.db)
+	res, height, _, err := pgb.q.deleteBlocks(pgb.ctx, N, pgb.db)
 	if err != nil {
 		return nil, height, pgb.replaceCancelError(err)
 	}
@@ -3011,15 +3284,15 @@ func (pgb *ChainDB) TxHistoryData(address string, addrChart dbtypes.HistoryChart
 
 	timeInterval := chartGroupings.String()
 
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TxHistoryData")
 	defer cancel()
 
 	switch addrChart {
 	case dbtypes.TxsType:
-		cd, err = retrieveTxHistoryByType(ctx, pgb.db, address, timeInterval)
+		cd, err = pgb.q.retrieveTxHistoryByType(ctx, pgb.readDB(), address, timeInterval)
 
 	case dbtypes.AmountFlow:
-		cd, err = retrieveTxHistoryByAmountFlow(ctx, pgb.db, address, timeInterval)
+		cd, err = pgb.q.retrieveTxHistoryByAmountFlow(ctx, pgb.readDB(), address, timeInterval)
 
 	default:
 		cd, err = nil, fmt.Errorf("unknown error occurred")
@@ -3040,26 +3313,38 @@ func (pgb *ChainDB) BinnedTreasuryIO(chartGroupings dbtypes.TimeBasedGrouping) (
 		return nil, fmt.Errorf("invalid time grouping %d", chartGroupings)
 	}
 	timeInterval := chartGroupings.String()
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("BinnedTreasuryIO")
+	defer cancel()
+	return pgb.q.binnedTreasuryIO(ctx, pgb.readDB(), timeInterval)
+}
+
+// TreasuryStatements returns the treasury accounting statements for each
+// period of the year or month grouping, oldest first.
+func (pgb *ChainDB) TreasuryStatements(grouping dbtypes.TimeBasedGrouping) ([]*dbtypes.TreasuryStatement, error) {
+	if grouping != dbtypes.YearGrouping && grouping != dbtypes.MonthGrouping {
+		return nil, fmt.Errorf("invalid statement grouping %q", grouping)
+	}
+	ctx, cancel := pgb.queryCtx("TreasuryStatements")
 	defer cancel()
-	return binnedTreasuryIO(ctx, pgb.db, timeInterval)
+	statements, err := pgb.q.retrieveTreasuryStatements(ctx, pgb.readDB(), grouping)
+	return statements, pgb.replaceCancelError(err)
 }
 
 // TicketsByPrice returns chart data for tickets grouped by price. maturityBlock
 // is used to define when tickets are considered live.
 func (pgb *ChainDB) TicketsByPrice(maturityBlock int64) (*dbtypes.PoolTicketsData, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TicketsByPrice")
 	defer cancel()
-	ptd, err := retrieveTicketByPrice(ctx, pgb.db, maturityBlock)
+	ptd, err := pgb.q.retrieveTicketByPrice(ctx, pgb.readDB(), maturityBlock)
 	return ptd, pgb.replaceCancelError(err)
 }
 
 // TicketsByInputCount returns chart data for tickets grouped by number of
 // inputs.
 func (pgb *ChainDB) TicketsByInputCount() (*dbtypes.PoolTicketsData, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("TicketsByInputCount")
 	defer cancel()
-	ptd, err := retrieveTicketsGroupedByType(ctx, pgb.db)
+	ptd, err := pgb.q.retrieveTicketsGroupedByType(ctx, pgb.readDB())
 	return ptd, pgb.replaceCancelError(err)
 }
 
@@ -3067,9 +3352,9 @@ func (pgb *ChainDB) TicketsByInputCount() (*dbtypes.PoolTicketsData, error) {
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendWindowStats.
 func (pgb *ChainDB) windowStats(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("windowStats")
 
-	rows, err := retrieveWindowStats(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrieveWindowStats(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("windowStats: %w", pgb.replaceCancelError(err))
 	}
@@ -3081,9 +3366,9 @@ func (pgb *ChainDB) windowStats(charts *cache.ChartData) (*sql.Rows, func(), err
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendMissedVotes.
 func (pgb *ChainDB) missedVotesStats(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("missedVotesStats")
 
-	rows, err := retrieveMissedVotes(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrieveMissedVotes(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("missedVotesStats: %w", pgb.replaceCancelError(err))
 	}
@@ -3095,9 +3380,9 @@ func (pgb *ChainDB) missedVotesStats(charts *cache.ChartData) (*sql.Rows, func()
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendChartBlocks.
 func (pgb *ChainDB) chartBlocks(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("chartBlocks")
 
-	rows, err := retrieveChartBlocks(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrieveChartBlocks(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %w", pgb.replaceCancelError(err))
 	}
@@ -3108,9 +3393,9 @@ func (pgb *ChainDB) chartBlocks(charts *cache.ChartData) (*sql.Rows, func(), err
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendCoinSupply.
 func (pgb *ChainDB) coinSupply(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("coinSupply")
 
-	rows, err := retrieveCoinSupply(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrieveCoinSupply(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("coinSupply: %w", pgb.replaceCancelError(err))
 	}
@@ -3122,9 +3407,9 @@ func (pgb *ChainDB) coinSupply(charts *cache.ChartData) (*sql.Rows, func(), erro
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendBlockFees.
 func (pgb *ChainDB) blockFees(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("blockFees")
 
-	rows, err := retrieveBlockFees(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrieveBlockFees(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %w", pgb.replaceCancelError(err))
 	}
@@ -3135,9 +3420,9 @@ func (pgb *ChainDB) blockFees(charts *cache.ChartData) (*sql.Rows, func(), error
 // participation. This is the Fetcher half of a pair that make up a
 // cache.ChartUpdater. The Appender half is appendPrivacyParticipation.
 func (pgb *ChainDB) privacyParticipation(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("privacyParticipation")
 
-	rows, err := retrievePrivacyParticipation(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrievePrivacyParticipation(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("privacyParticipation: %w", pgb.replaceCancelError(err))
 	}
@@ -3152,7 +3437,7 @@ func (pgb *ChainDB) privacyParticipation(charts *cache.ChartData) (*sql.Rows, fu
 // appendAnonymitySet.
 func (pgb *ChainDB) anonymitySet(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	// First check if the necessary data is available in mixSetDiffs.
-	nextDataHeight := uint32(len(charts.Blocks.AnonymitySet))
+	nextDataHeight := uint32(len(charts.Blocks.Uints(cache.AnonymitySetSeries)))
 	targetDataHeight := uint32(len(charts.Blocks.Height) - 1)
 
 	pgb.mixSetDiffsMtx.Lock()
@@ -3174,8 +3459,8 @@ func (pgb *ChainDB) anonymitySet(charts *cache.ChartData) (*sql.Rows, func(), er
 // for outputs funded after bestHeight. To include all blocks including genesis
 // use -1 for bestHeight.
 func (pgb *ChainDB) retrieveAnonymitySet(bestHeight int32) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
-	rows, err := pgb.db.QueryContext(ctx, internal.SelectMixedVouts, bestHeight)
+	ctx, cancel := pgb.queryCtx("retrieveAnonymitySet")
+	rows, err := pgb.readDB().QueryContext(ctx, pgb.q.SelectMixedVouts, bestHeight)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %w", pgb.replaceCancelError(err))
 	}
@@ -3201,7 +3486,7 @@ func (pgb *ChainDB) appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows)
 	// }
 
 	// Update with pgb.mixSetDiffs up to the length of charts.Blocks.Height.
-	nextDataHeight := uint32(len(charts.Blocks.AnonymitySet))
+	nextDataHeight := uint32(len(charts.Blocks.Uints(cache.AnonymitySetSeries)))
 	targetDataHeight := uint32(len(charts.Blocks.Height) - 1)
 
 	pgb.mixSetDiffsMtx.Lock()
@@ -3210,7 +3495,7 @@ func (pgb *ChainDB) appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows)
 	nextSets := make([]uint64, 0, targetDataHeight-nextDataHeight+1)
 	var lastSet int64
 	if nextDataHeight > 0 {
-		lastSet = int64(charts.Blocks.AnonymitySet[nextDataHeight-1])
+		lastSet = int64(charts.Blocks.Uints(cache.AnonymitySetSeries)[nextDataHeight-1])
 	}
 	for h := nextDataHeight; h <= targetDataHeight; h++ {
 		setDiff, found := pgb.mixSetDiffs[h]
@@ -3227,8 +3512,119 @@ func (pgb *ChainDB) appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows)
 		// (anonymitySet) should have already verified that we do.
 	}
 
-	charts.Blocks.AnonymitySet = append(charts.Blocks.AnonymitySet, nextSets...)
+	charts.Blocks.AppendUints(cache.AnonymitySetSeries, nextSets...)
+
+	return nil
+}
+
+// coinAgeEvents fetches the value funded and spent in the blocks after the tip
+// of the coin age ledger. The coin age series are snipped by
+// (*ChartData).ReorgHandler in a reorg, so the ledger is first rolled back to
+// the series, or rebuilt if the blocks to disconnect are no longer in its
+// journal. The ledger is kept in the charts cache, so it is only rebuilt, which
+// means scanning every output since the genesis block, without a cache or after
+// a reorg deeper than the journal. This is the Fetcher half of a pair that make
+// up a cache.ChartUpdater. The Appender half is appendCoinAges.
+func (pgb *ChainDB) coinAgeEvents(charts *cache.ChartData) (*sql.Rows, func(), error) {
+	seriesTip := int64(charts.SeriesTip(cache.CoinDaysDestroyedSeries))
+	if !pgb.coinAges.rollback(seriesTip) {
+		log.Infof("Rebuilding the coin age ledger from the genesis block...")
+		pgb.coinAges.reset()
+	}
+
+	ctx, cancel := pgb.queryCtx("coinAgeEvents")
+	rows, err := pgb.readDB().QueryContext(ctx, pgb.q.SelectCoinAgeEvents, pgb.coinAges.tip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("coinAgeEvents: %w", pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendCoinAges connects the blocks from coinAgeEvents to the coin age ledger
+// up to the height of the charts data, and appends the coin age series of each
+// block that is not already in the series, which is the case for the blocks
+// loaded from the charts cache when the ledger is rebuilt. This is the
+// Appender half of a pair that make up a cache.ChartUpdater.
+func (pgb *ChainDB) appendCoinAges(charts *cache.ChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+
+	ledger := pgb.coinAges
+	blocks := charts.Blocks
+	endHeight := int64(len(blocks.Time)) - 1
+	seriesLen := int64(len(blocks.Floats(cache.CoinDaysDestroyedSeries)))
+
+	// connect connects the block at the next height to the ledger.
+	connect := func(block *coinAgeBlock) {
+		height := ledger.tip() + 1
+		cdd, spent := ledger.connect(block, blocks.Time[height])
+		if height < seriesLen {
+			return
+		}
+		blocks.AppendFloats(cache.CoinDaysDestroyedSeries, cdd)
+		blocks.AppendUints(cache.ValueSpentSeries, spent)
+		for i, value := range ledger.utxoAges() {
+			blocks.AppendUints(cache.UTXOAgeSeries[i], value)
+		}
+	}
+
+	block := new(coinAgeBlock)
+	for rows.Next() {
+		var height, fundHeight, value int64
+		var spend bool
+		if err := rows.Scan(&height, &fundHeight, &value, &spend); err != nil {
+			return err
+		}
+		if height > endHeight {
+			// The rest are for blocks not yet in the charts data.
+			break
+		}
+		if spend && (fundHeight < 0 || fundHeight > height) {
+			return fmt.Errorf("appendCoinAges: value spent at height %d funded at height %d",
+				height, fundHeight)
+		}
+		// Connect the blocks before this one, the first with the events
+		// collected so far.
+		for ledger.tip()+1 < height {
+			connect(block)
+			block = new(coinAgeBlock)
+		}
+		if spend {
+			block.spends = append(block.spends, coinAgeSpend{fundHeight, value})
+		} else {
+			block.funded += value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for ledger.tip() < endHeight {
+		connect(block)
+		block = new(coinAgeBlock)
+	}
+	return nil
+}
+
+// saveCoinAges is the SaveState of the coin age chart updater, which keeps the
+// coin age ledger in the charts cache.
+func (pgb *ChainDB) saveCoinAges(*cache.ChartData) ([]byte, error) {
+	return pgb.coinAges.encode(), nil
+}
 
+// loadCoinAges is the LoadState of the coin age chart updater. A ledger ahead
+// of the loaded coin age series, as when the series were trimmed by a reorg, is
+// rolled back to the tip of the series. The ledger is only loaded if it is
+// then at the tip of the series.
+func (pgb *ChainDB) loadCoinAges(charts *cache.ChartData, state []byte) error {
+	seriesTip := int64(len(charts.Blocks.Floats(cache.CoinDaysDestroyedSeries))) - 1
+	ledger, err := loadCoinAgeLedger(state, charts.Blocks.Time, seriesTip)
+	if err != nil {
+		return err
+	}
+	if ledger.tip() != seriesTip {
+		return fmt.Errorf("coin age ledger at height %d, but the series at %d",
+			ledger.tip(), seriesTip)
+	}
+	pgb.coinAges = ledger
 	return nil
 }
 
@@ -3236,9 +3632,9 @@ func (pgb *ChainDB) appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows)
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendPoolStats.
 func (pgb *ChainDB) poolStats(charts *cache.ChartData) (*sql.Rows, func(), error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("poolStats")
 
-	rows, err := retrievePoolStats(ctx, pgb.db, charts)
+	rows, err := pgb.q.retrievePoolStats(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %w", pgb.replaceCancelError(err))
 	}
@@ -3248,9 +3644,9 @@ func (pgb *ChainDB) poolStats(charts *cache.ChartData) (*sql.Rows, func(), error
 // PowerlessTickets fetches all missed and expired tickets, sorted by revocation
 // status.
 func (pgb *ChainDB) PowerlessTickets() (*apitypes.PowerlessTickets, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("PowerlessTickets")
 	defer cancel()
-	return retrievePowerlessTickets(ctx, pgb.db)
+	return pgb.q.retrievePowerlessTickets(ctx, pgb.readDB())
 }
 
 // SetVinsMainchainByBlock first retrieves for all transactions in the specified
@@ -3267,7 +3663,7 @@ func (pgb *ChainDB) setVinsMainchainByBlock(blockHash dbtypes.ChainHash) (int64,
 	// Get vins DB IDs from the transactions table, for each tx in the block.
 	onlyRegularTxns := false
 	vinDbIDsBlk, voutDbIDsBlk, areMainchain, err :=
-		retrieveTxnsVinsVoutsByBlock(ctx, pgb.db, blockHash, onlyRegularTxns)
+		pgb.q.retrieveTxnsVinsVoutsByBlock(ctx, pgb.db, blockHash, onlyRegularTxns)
 	if err != nil {
 		return 0, nil, nil, fmt.Errorf("unable to retrieve vin data for block %s: %w", blockHash, err)
 	}
@@ -3297,7 +3693,7 @@ func (pgb *ChainDB) setVinsMainchainOneTxn(vinDbIDs dbtypes.UInt64Array,
 
 	// each vin
 	for _, vinDbID := range vinDbIDs {
-		result, err := pgb.db.Exec(internal.SetIsMainchainByVinID,
+		result, err := pgb.db.Exec(pgb.q.SetIsMainchainByVinID,
 			vinDbID, isMainchain)
 		if err != nil {
 			return rowsUpdated, fmt.Errorf("db ID %d not found: %w", vinDbID, err)
@@ -3318,9 +3714,9 @@ func (pgb *ChainDB) setVinsMainchainOneTxn(vinDbIDs dbtypes.UInt64Array,
 // referenced by the transaction dbTx.
 func (pgb *ChainDB) VinsForTx(dbTx *dbtypes.Tx) ([]dbtypes.VinTxProperty, error) {
 	// Retrieve the vins row data.
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("VinsForTx")
 	defer cancel()
-	vins, err := retrieveVinsByIDs(ctx, pgb.db, dbTx.VinDbIds)
+	vins, err := pgb.q.retrieveVinsByIDs(ctx, pgb.readDB(), dbTx.VinDbIds)
 	if err != nil {
 		err = fmt.Errorf("retrieveVinsByIDs: %w", err)
 	}
@@ -3330,9 +3726,9 @@ func (pgb *ChainDB) VinsForTx(dbTx *dbtypes.Tx) ([]dbtypes.VinTxProperty, error)
 // VoutsForTx returns a slice of dbtypes.Vout values for each vout referenced by
 // the transaction dbTx.
 func (pgb *ChainDB) VoutsForTx(dbTx *dbtypes.Tx) ([]dbtypes.Vout, error) {
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("VoutsForTx")
 	defer cancel()
-	vouts, err := retrieveVoutsByIDs(ctx, pgb.db, dbTx.VoutDbIds)
+	vouts, err := pgb.q.retrieveVoutsByIDs(ctx, pgb.readDB(), dbTx.VoutDbIds)
 	return vouts, pgb.replaceCancelError(err)
 }
 
@@ -3346,7 +3742,7 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (tipHashStr string, blocksMo
 		// 1. Block. Set is_mainchain=false on the tip block, return hash of
 		// previous block.
 		now := time.Now()
-		previousHash, err := setMainchainByBlockHash(pgb.db, tipHash, false)
+		previousHash, err := pgb.q.setMainchainByBlockHash(pgb.db, tipHash, false)
 		if err != nil {
 			log.Errorf("Failed to set block %s as a sidechain block: %v",
 				tipHash, err)
@@ -3357,7 +3753,7 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (tipHashStr string, blocksMo
 		// 2. Transactions. Set is_mainchain=false on all transactions in the
 		// tip block, returning only the number of transactions updated.
 		now = time.Now()
-		rowsUpdated, _, err := updateTransactionsMainchain(pgb.db, tipHash, false)
+		rowsUpdated, _, err := pgb.q.updateTransactionsMainchain(pgb.db, tipHash, false)
 		if err != nil {
 			log.Errorf("Failed to set transactions in block %s as sidechain: %v",
 				tipHash, err)
@@ -3390,7 +3786,7 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (tipHashStr string, blocksMo
 		// row IDs, and the funding transactions specified by the vouts DB row
 		// IDs. The IDs come for free via retrieveTxnsVinsVoutsByBlock.
 		now = time.Now()
-		addrs, numAddrSpending, numAddrFunding, err := updateAddressesMainchainByIDs(pgb.db,
+		addrs, numAddrSpending, numAddrFunding, err := pgb.q.updateAddressesMainchainByIDs(pgb.db,
 			vinDbIDsBlk, voutDbIDsBlk, false)
 		if err != nil {
 			log.Errorf("Failed to set addresses rows in block %s as sidechain: %v",
@@ -3404,7 +3800,7 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (tipHashStr string, blocksMo
 
 		// 6. Votes. Sets is_mainchain=false on all votes in the tip block.
 		now = time.Now()
-		rowsUpdated, err = updateVotesMainchain(pgb.db, tipHash, false)
+		rowsUpdated, err = pgb.q.updateVotesMainchain(pgb.db, tipHash, false)
 		if err != nil {
 			log.Errorf("Failed to set votes in block %s as sidechain: %v",
 				tipHash, err)
@@ -3414,7 +3810,7 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (tipHashStr string, blocksMo
 
 		// 7. Tickets. Sets is_mainchain=false on all tickets in the tip block.
 		now = time.Now()
-		rowsUpdated, err = updateTicketsMainchain(pgb.db, tipHash, false)
+		rowsUpdated, err = pgb.q.updateTicketsMainchain(pgb.db, tipHash, false)
 		if err != nil {
 			log.Errorf("Failed to set tickets in block %s as sidechain: %v",
 				tipHash, err)
@@ -3424,7 +3820,7 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (tipHashStr string, blocksMo
 
 		// 8. Treasury. Sets is_mainchain=false on all entries in the tip block.
 		now = time.Now()
-		rowsUpdated, err = updateTreasuryMainchain(pgb.db, tipHash, false)
+		rowsUpdated, err = pgb.q.updateTreasuryMainchain(pgb.db, tipHash, false)
 		if err != nil {
 			log.Errorf("Failed to set tickets in block %s as sidechain: %v",
 				tipHash, err)
@@ -3492,6 +3888,11 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 		}
 	}
 
+	// Create any table partitions needed for this block's data.
+	if err = pgb.ensurePartitions(msgBlock); err != nil {
+		return
+	}
+
 	// Convert the wire.MsgBlock to a dbtypes.Block.
 	dbBlock := dbtypes.MsgBlockToDBBlock(msgBlock, pgb.chainParams, chainWork, winningTickets)
 
@@ -3607,7 +4008,7 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 
 	// Store the block now that it has all if its transaction row IDs.
 	var blockDbID uint64
-	blockDbID, err = insertBlock(pgb.db, dbBlock, isValid, isMainchain, pgb.dupChecks)
+	blockDbID, err = pgb.q.insertBlock(pgb.db, dbBlock, isValid, isMainchain, pgb.dupChecks)
 	if err != nil {
 		log.Error("insertBlock:", err)
 		return
@@ -3617,7 +4018,7 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 	// Insert the block in the block_chain table with the previous block hash
 	// and an empty string for the next block hash, which may be updated when a
 	// new block extends this chain.
-	err = insertBlockPrevNext(pgb.db, blockDbID, &dbBlock.Hash,
+	err = pgb.q.insertBlockPrevNext(pgb.db, blockDbID, &dbBlock.Hash,
 		&dbBlock.PreviousHash, nil)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		log.Error("InsertBlockPrevNext:", err)
@@ -3644,7 +4045,7 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 
 		// Insert the block stats.
 		if tpi != nil {
-			err = insertBlockStats(pgb.db, blockDbID, tpi)
+			err = pgb.q.insertBlockStats(pgb.db, blockDbID, tpi)
 			if err != nil {
 				err = fmt.Errorf("InsertBlockStats: %w", err)
 				return
@@ -3652,7 +4053,7 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 		}
 
 		// Update the best block in the meta table.
-		err = setDBBestBlock(pgb.db, dbBlock.Hash, int64(dbBlock.Height))
+		err = pgb.q.setDBBestBlock(pgb.db, dbBlock.Hash, int64(dbBlock.Height))
 		if err != nil {
 			err = fmt.Errorf("SetDBBestBlock: %w", err)
 			return
@@ -3722,7 +4123,7 @@ func (pgb *ChainDB) updateLastBlock(msgBlock *wire.MsgBlock, isMainchain bool) e
 	}
 
 	// Update the previous block's next block hash in the block_chain table.
-	err := updateBlockNext(pgb.db, lastBlockDbID, blockHash)
+	err := pgb.q.updateBlockNext(pgb.db, lastBlockDbID, blockHash)
 	if err != nil {
 		return fmt.Errorf("updateBlockNext(%v, %v): %w", lastBlockDbID, blockHash, err)
 	}
@@ -3737,7 +4138,7 @@ func (pgb *ChainDB) updateLastBlock(msgBlock *wire.MsgBlock, isMainchain bool) e
 	if !lastIsValid {
 		// Update the is_valid flag in the blocks table.
 		log.Infof("Previous block %s was DISAPPROVED by stakeholders.", lastBlockHash)
-		err := updateLastBlockValid(pgb.db, lastBlockDbID, lastIsValid)
+		err := pgb.q.updateLastBlockValid(pgb.db, lastBlockDbID, lastIsValid)
 		if err != nil {
 			return fmt.Errorf("UpdateLastBlockValid: %w", err)
 		}
@@ -3752,13 +4153,13 @@ func (pgb *ChainDB) updateLastBlock(msgBlock *wire.MsgBlock, isMainchain bool) e
 			"regular transactions in invalidated block %s", voutsUnset, lastBlockHash)
 
 		// Update the is_valid flag for the last block's vins.
-		err = updateLastVins(pgb.db, lastBlockHash, lastIsValid, isMainchain)
+		err = pgb.q.updateLastVins(pgb.db, lastBlockHash, lastIsValid, isMainchain)
 		if err != nil {
 			return fmt.Errorf("UpdateLastVins: %w", err)
 		}
 
 		// Update the is_valid flag for the last block's regular transactions.
-		_, _, err = updateTransactionsValid(pgb.db, lastBlockHash, lastIsValid)
+		_, _, err = pgb.q.updateTransactionsValid(pgb.db, lastBlockHash, lastIsValid)
 		if err != nil {
 			return fmt.Errorf("UpdateTransactionsValid: %w", err)
 		}
@@ -3768,7 +4169,7 @@ func (pgb *ChainDB) updateLastBlock(msgBlock *wire.MsgBlock, isMainchain bool) e
 		//  Update on addresses  (cost=0.00..1012201.53 rows=1 width=181)
 		// 		->  Seq Scan on addresses  (cost=0.00..1012201.53 rows=1 width=181)
 		// 		Filter: ((NOT is_funding) AND (tx_vin_vout_row_id = 13241234))
-		addrs, err := updateLastAddressesValid(pgb.db, lastBlockHash, lastIsValid)
+		addrs, err := pgb.q.updateLastAddressesValid(pgb.db, lastBlockHash, lastIsValid)
 		if err != nil {
 			return fmt.Errorf("UpdateLastAddressesValid: %w", err)
 		}
@@ -3828,7 +4229,7 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 	checked, doUpsert := pgb.dupChecks, updateExistingRecords
 
 	var voutStmt *sql.Stmt
-	voutStmt, err = dbTx.Prepare(internal.MakeVoutInsertStatement(checked, doUpsert))
+	voutStmt, err = dbTx.Prepare(pgb.q.MakeVoutInsertStatement(checked, doUpsert, pgb.TablesPartitioned()))
 	if err != nil {
 		_ = dbTx.Rollback()
 		err = fmt.Errorf("failed to prepare vout insert statement: %w", err)
@@ -3837,7 +4238,7 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 	defer voutStmt.Close()
 
 	var vinStmt *sql.Stmt
-	vinStmt, err = dbTx.Prepare(internal.MakeVinInsertStatement(checked, doUpsert))
+	vinStmt, err = dbTx.Prepare(pgb.q.MakeVinInsertStatement(checked, doUpsert, pgb.TablesPartitioned()))
 	if err != nil {
 		_ = dbTx.Rollback()
 		err = fmt.Errorf("failed to prepare vin insert statement: %w", err)
@@ -3878,7 +4279,7 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 	}
 
 	// Get the tx PK IDs for storage in the blocks, tickets, and votes table.
-	txDbIDs, err = insertTxnsDbTxn(dbTx, txns, pgb.dupChecks, updateExistingRecords)
+	txDbIDs, err = pgb.q.insertTxnsDbTxn(dbTx, txns, pgb.dupChecks, updateExistingRecords)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		err = fmt.Errorf("failure in InsertTxnsDbTxn: %w", err)
 		return
@@ -3931,7 +4332,7 @@ txns:
 			if utxo == nil {
 				log.Tracef("Uncached UTXO %s:%d. Looking it up in the DB.", vin.PrevTxHash, vin.PrevTxIndex)
 				var err error
-				utxo, err = retrieveTxOutData(pgb.db, vin.PrevTxHash, vin.PrevTxIndex, int8(vin.PrevTxTree))
+				utxo, err = pgb.q.retrieveTxOutData(pgb.db, vin.PrevTxHash, vin.PrevTxIndex, int8(vin.PrevTxTree))
 				if utxo == nil || err != nil {
 					log.Warnf("Unable to find load UTXO data for %s:%d. Error: %v",
 						vin.PrevTxHash, vin.PrevTxIndex, err)
@@ -4019,7 +4420,7 @@ txns:
 	// to the new votes, revokes, misses, and expires.
 	if isStake {
 		// Tickets: Insert new (unspent) tickets
-		newTicketDbIDs, newTicketTx, err := insertTickets(pgb.db, dbTransactions, txDbIDs,
+		newTicketDbIDs, newTicketTx, err := pgb.q.insertTickets(pgb.db, dbTransactions, txDbIDs,
 			pgb.dupChecks, updateExistingRecords)
 		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			log.Error("insertTickets:", err)
@@ -4040,7 +4441,7 @@ txns:
 
 		// voteDbIDs, voteTxns, spentTicketHashes, ticketDbIDs, missDbIDs, err := ...
 		var missesHashIDs map[dbtypes.ChainHash]uint64
-		_, _, _, _, missesHashIDs, err = insertVotes(pgb.db, dbTransactions, txDbIDs,
+		_, _, _, _, missesHashIDs, err = pgb.q.insertVotes(pgb.db, dbTransactions, txDbIDs,
 			pgb.unspentTicketCache, msgBlock, pgb.dupChecks, updateExistingRecords,
 			pgb.chainParams, pgb.ChainInfo())
 		if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -4050,7 +4451,7 @@ txns:
 		}
 
 		// Treasury txns.
-		err = insertTreasuryTxns(pgb.db, dbTransactions, pgb.dupChecks, updateExistingRecords)
+		err = pgb.q.insertTreasuryTxns(pgb.db, dbTransactions, pgb.dupChecks, updateExistingRecords)
 		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			log.Error("insertTreasuryTxns:", err)
 			txRes.err = err
@@ -4100,7 +4501,7 @@ txns:
 		}
 
 		// Update tickets table with spending info.
-		_, err = setSpendingForTickets(pgb.db, ticketDbIDs, spendingTxDbIDs,
+		_, err = pgb.q.setSpendingForTickets(pgb.db, ticketDbIDs, spendingTxDbIDs,
 			blockHeights, spendTypes, poolStatuses)
 		if err != nil {
 			log.Error("SetSpendingForTickets:", err)
@@ -4163,7 +4564,7 @@ txns:
 		}
 
 		// Update status of the unspent expired and missed tickets.
-		_, err = setPoolStatusForTickets(pgb.db,
+		_, err = pgb.q.setPoolStatusForTickets(pgb.db,
 			unspentEnMRowIDs, missStatuses)
 		if err != nil {
 			log.Errorf("SetPoolStatusForTicketsByHash: %v", err)
@@ -4185,7 +4586,8 @@ txns:
 
 	// Insert each new funding AddressRow, absent MatchingTxHash (spending txn
 	// since these new address rows are *funding*).
-	_, err = insertAddressRowsDbTx(dbTx, dbAddressRowsFlat, pgb.dupChecks, updateExistingRecords)
+	_, err = pgb.q.insertAddressRowsDbTx(dbTx, dbAddressRowsFlat, pgb.dupChecks,
+		updateExistingRecords, pgb.TablesPartitioned())
 	if err != nil {
 		_ = dbTx.Rollback()
 		log.Error("InsertAddressRows:", err)
@@ -4232,10 +4634,10 @@ txns:
 				log.Tracef("Data for that utxo (%s:%d) wasn't cached! Vouts table will be queried.",
 					vin.PrevTxHash, vin.PrevTxIndex)
 			}
-			fromAddrs, _, voutDbID, mixedVout, err := insertSpendingAddressRow(dbTx,
+			fromAddrs, _, voutDbID, mixedVout, err := pgb.q.insertSpendingAddressRow(dbTx,
 				vin.PrevTxHash, vin.PrevTxIndex, int8(vin.PrevTxTree),
 				spendingTxHash, spendingTxIndex, vinDbID, utxoData, pgb.dupChecks,
-				updateExistingRecords, tx.IsMainchainBlock, tx.IsValid,
+				updateExistingRecords, pgb.TablesPartitioned(), tx.IsMainchainBlock, tx.IsValid,
 				vin.TxType, updateAddressesSpendingInfo, tx.BlockTime)
 			if err != nil {
 				txRes.err = fmt.Errorf("insertSpendingAddressRow: %w + %v (rollback)",
@@ -4266,7 +4668,7 @@ txns:
 		// done via addresses.matching_tx_hash.
 		if tx.IsValid && isMainchain && len(voutDbIDs) > 0 {
 			// Set spend_tx_row_id for each prevout consumed by this txn.
-			err = setSpendingForVouts(dbTx, voutDbIDs, txDbID)
+			err = pgb.q.setSpendingForVouts(dbTx, voutDbIDs, txDbID)
 			if err != nil {
 				txRes.err = fmt.Errorf(`setSpendingForVouts: %w + %v (rollback)`,
 					err, dbTx.Rollback())
@@ -4293,13 +4695,13 @@ txns:
 			continue
 		}
 		for _, red := range swapTxns.Redemptions {
-			err = insertSwap(pgb.db, height, red)
+			err = pgb.q.insertSwap(pgb.db, height, red)
 			if err != nil {
 				log.Errorf("InsertSwap: %v", err)
 			}
 		}
 		for _, ref := range swapTxns.Refunds {
-			err = insertSwap(pgb.db, height, ref)
+			err = pgb.q.insertSwap(pgb.db, height, ref)
 			if err != nil {
 				log.Errorf("InsertSwap: %v", err)
 			}
@@ -4645,7 +5047,7 @@ func (pgb *ChainDB) GetStakeInfoExtendedByHash(hashStr string) *apitypes.StakeIn
 
 	var size, val int64
 	var winners dbtypes.ChainHashArray
-	err = pgb.db.QueryRowContext(pgb.ctx, internal.SelectPoolInfo,
+	err = pgb.db.QueryRowContext(pgb.ctx, pgb.q.SelectPoolInfo,
 		(*dbtypes.ChainHash)(hash)).Scan(&winners, &val, &size)
 	if err != nil {
 		log.Errorf("Error retrieving mainchain block with stats for hash %s: %v", hashStr, err)
@@ -4691,7 +5093,7 @@ func (pgb *ChainDB) GetStakeInfoExtendedByHeight(height int) *apitypes.StakeInfo
 
 // GetPoolInfo retrieves the ticket pool statistics at the specified height.
 func (pgb *ChainDB) GetPoolInfo(idx int) *apitypes.TicketPoolInfo {
-	ticketPoolInfo, err := retrievePoolInfo(pgb.ctx, pgb.db, int64(idx))
+	ticketPoolInfo, err := pgb.q.retrievePoolInfo(pgb.ctx, pgb.db, int64(idx))
 	if err != nil {
 		log.Errorf("Unable to retrieve ticket pool info: %v", err)
 		return nil
@@ -4722,7 +5124,7 @@ func (pgb *ChainDB) GetPoolInfoRange(idx0, idx1 int) []apitypes.TicketPoolInfo {
 		log.Errorf("Unable to retrieve ticket pool info for range [%d, %d], tip=%d", idx0, idx1, tip)
 		return nil
 	}
-	ticketPoolInfos, _, err := retrievePoolInfoRange(pgb.ctx, pgb.db, ind0, ind1)
+	ticketPoolInfos, _, err := pgb.q.retrievePoolInfoRange(pgb.ctx, pgb.db, ind0, ind1)
 	if err != nil {
 		log.Errorf("Unable to retrieve ticket pool info range: %v", err)
 		return nil
@@ -4740,7 +5142,7 @@ func (pgb *ChainDB) GetPoolValAndSizeRange(idx0, idx1 int) ([]float64, []uint32)
 		log.Errorf("Unable to retrieve ticket pool info for range [%d, %d], tip=%d", idx0, idx1, tip)
 		return nil, nil
 	}
-	poolvals, poolsizes, err := retrievePoolValAndSizeRange(pgb.ctx, pgb.db, ind0, ind1)
+	poolvals, poolsizes, err := pgb.q.retrievePoolValAndSizeRange(pgb.ctx, pgb.db, ind0, ind1)
 	if err != nil {
 		log.Errorf("Unable to retrieve ticket value and size range: %v", err)
 		return nil, nil
@@ -4759,7 +5161,7 @@ func (pgb *ChainDB) ChargePoolInfoCache(startHeight int64) error {
 		log.Debug("No pool info to load into cache")
 		return nil
 	}
-	tpis, blockHashes, err := retrievePoolInfoRange(pgb.ctx, pgb.db, startHeight, endHeight)
+	tpis, blockHashes, err := pgb.q.retrievePoolInfoRange(pgb.ctx, pgb.db, startHeight, endHeight)
 	if err != nil {
 		return err
 	}
@@ -4785,6 +5187,108 @@ func (pgb *ChainDB) GetPool(idx int64) ([]string, error) {
 	return hss, nil
 }
 
+// GetPoolMembership checks if the ticket was in the live pool at a given
+// height.
+func (pgb *ChainDB) GetPoolMembership(ticket string, idx int64) (*apitypes.TicketPoolMembership, error) {
+	hash, err := chainhash.NewHashFromStr(ticket)
+	if err != nil {
+		return nil, err
+	}
+	live, liveHeight, err := pgb.stakeDB.PoolDB.TicketLive(*hash, idx)
+	if err != nil {
+		return nil, err
+	}
+	return &apitypes.TicketPoolMembership{
+		Ticket:     ticket,
+		Height:     idx,
+		Live:       live,
+		LiveHeight: liveHeight,
+	}, nil
+}
+
+// GetPoolChanges retrieves the net change in the live pool between two
+// heights.
+func (pgb *ChainDB) GetPoolChanges(idx0, idx int64) (*apitypes.TicketPoolChanges, error) {
+	in, out, err := pgb.stakeDB.PoolDB.PoolChanges(idx0, idx)
+	if err != nil {
+		return nil, err
+	}
+	changes := &apitypes.TicketPoolChanges{
+		StartHeight: idx0,
+		EndHeight:   idx,
+		In:          make([]string, 0, len(in)),
+		Out:         make([]string, 0, len(out)),
+	}
+	for i := range in {
+		changes.In = append(changes.In, in[i].String())
+	}
+	for i := range out {
+		changes.Out = append(changes.Out, out[i].String())
+	}
+	return changes, nil
+}
+
+// GetPoolAges retrieves the age distribution of the live pool at a given
+// height, in bins of binSize blocks.
+func (pgb *ChainDB) GetPoolAges(idx, binSize int64) (*apitypes.TicketPoolAges, error) {
+	counts, err := pgb.stakeDB.PoolDB.PoolAges(idx, binSize)
+	if err != nil {
+		return nil, err
+	}
+	ages := &apitypes.TicketPoolAges{
+		Height:  idx,
+		BinSize: binSize,
+		Counts:  counts,
+	}
+	for _, n := range counts {
+		ages.Size += n
+	}
+	return ages, nil
+}
+
+// TicketSelectionStats gets the ticket selection statistics of the count most
+// recent windows of windowSize blocks that are not before the stake validation
+// height, oldest first.
+func (pgb *ChainDB) TicketSelectionStats(windowSize int64, count int) (*apitypes.TicketSelectionStats, error) {
+	if windowSize < 1 || count < 1 {
+		return nil, fmt.Errorf("invalid window size %d or count %d", windowSize, count)
+	}
+	stats := &apitypes.TicketSelectionStats{
+		WindowSize: windowSize,
+		Windows:    make([]*apitypes.TicketSelectionWindow, 0, count),
+	}
+	svh := pgb.chainParams.StakeValidationHeight
+	for end := int64(pgb.stakeDB.Height()); end >= svh && len(stats.Windows) < count; end -= windowSize {
+		start := end - windowSize + 1
+		if start < svh {
+			start = svh
+		}
+		window, err := pgb.stakeDB.SelectionStats(start, end)
+		if err != nil {
+			return nil, err
+		}
+		window.Missed, err = pgb.missedVotesForBlockRange(start, end)
+		if err != nil {
+			return nil, err
+		}
+		if window.Selected > 0 {
+			window.MissRate = float64(window.Missed) / float64(window.Selected)
+		}
+		if window.StartTime, err = pgb.BlockTimeByHeight(start); err != nil {
+			return nil, err
+		}
+		if window.EndTime, err = pgb.BlockTimeByHeight(end); err != nil {
+			return nil, err
+		}
+		stats.Windows = append(stats.Windows, window)
+	}
+	// Oldest first.
+	for i, j := 0, len(stats.Windows)-1; i < j; i, j = i+1, j-1 {
+		stats.Windows[i], stats.Windows[j] = stats.Windows[j], stats.Windows[i]
+	}
+	return stats, nil
+}
+
 // DCP0010ActivationHeight indicates the height at which the changesubsidysplit
 // agenda will activate, or -1 if it is not determined yet.
 func (pgb *ChainDB) DCP0010ActivationHeight() int64 {
@@ -4930,9 +5434,9 @@ func (pgb *ChainDB) GetBlockHeight(hash string) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	ctx, cancel := pgb.queryCtx("GetBlockHeight")
 	defer cancel()
-	height, err := retrieveBlockHeight(ctx, pgb.db, ch)
+	height, err := pgb.q.retrieveBlockHeight(ctx, pgb.db, ch)
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
 			log.Errorf("Unexpected error retrieving block height for hash %s: %v", hash, err)
@@ -5176,6 +5680,20 @@ func (pgb *ChainDB) GetStakeDiffEstimates() *apitypes.StakeDiff {
 	}
 }
 
+// StakeDiffForecast forecasts the ticket prices of the next windows from the
+// ticket purchases of the recent windows and the tickets in mempool.
+func (pgb *ChainDB) StakeDiffForecast(windows int) (*apitypes.StakeDiffForecast, error) {
+	ctx, cancel := pgb.queryCtx("StakeDiffForecast")
+	defer cancel()
+	start := stakediff.HistoryStart(pgb.chainParams, pgb.Height())
+	blocks, err := pgb.q.retrieveBlockStakeData(ctx, pgb.readDB(), start)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	_, mempoolTickets := pgb.MPC.GetNumTickets()
+	return stakediff.Forecast(pgb.chainParams, blocks, int64(mempoolTickets), windows)
+}
+
 // GetSummary returns the *apitypes.BlockDataBasic for a given block height.
 func (pgb *ChainDB) GetSummary(idx int) *apitypes.BlockDataBasic {
 	blockSummary, err := pgb.BlockSummary(int64(idx))
@@ -5199,7 +5717,7 @@ func (pgb *ChainDB) BlockSummary(ind int64) (*apitypes.BlockDataBasic, error) {
 		// Cache miss necessitates a DB query.
 	}
 
-	bd, err := retrieveBlockSummary(pgb.ctx, pgb.db, ind)
+	bd, err := pgb.q.retrieveBlockSummary(pgb.ctx, pgb.db, ind)
 	if err != nil {
 		return nil, err
 	}
@@ -5230,7 +5748,7 @@ func (pgb *ChainDB) GetSummaryRange(idx0, idx1 int) []*apitypes.BlockDataBasic {
 // BlockSummaryRange returns the *apitypes.BlockDataBasic for a range of block
 // height.
 func (pgb *ChainDB) BlockSummaryRange(idx0, idx1 int64) ([]*apitypes.BlockDataBasic, error) {
-	return retrieveBlockSummaryRange(pgb.ctx, pgb.db, idx0, idx1)
+	return pgb.q.retrieveBlockSummaryRange(pgb.ctx, pgb.readDB(), idx0, idx1)
 }
 
 // GetSummaryRangeStepped returns the []*apitypes.BlockDataBasic for a given
@@ -5248,7 +5766,7 @@ func (pgb *ChainDB) GetSummaryRangeStepped(idx0, idx1, step int) []*apitypes.Blo
 // BlockSummaryRangeStepped returns the []*apitypes.BlockDataBasic for every
 // step'th block in a specified range.
 func (pgb *ChainDB) BlockSummaryRangeStepped(idx0, idx1, step int64) ([]*apitypes.BlockDataBasic, error) {
-	return retrieveBlockSummaryRangeStepped(pgb.ctx, pgb.db, idx0, idx1, step)
+	return pgb.q.retrieveBlockSummaryRangeStepped(pgb.ctx, pgb.readDB(), idx0, idx1, step)
 }
 
 // GetSummaryByHash returns a *apitypes.BlockDataBasic for a given hex-encoded
@@ -5321,7 +5839,7 @@ func (pgb *ChainDB) BlockSummaryByHash(hash string) (*apitypes.BlockDataBasic, e
 	if err != nil {
 		return nil, err
 	}
-	bd, err := retrieveBlockSummaryByHash(pgb.ctx, pgb.db, ch)
+	bd, err := pgb.q.retrieveBlockSummaryByHash(pgb.ctx, pgb.db, ch)
 	if err != nil {
 		return nil, err
 	}
@@ -5404,7 +5922,7 @@ func (pgb *ChainDB) BlockSize(ind int64) (int32, error) {
 			ind, tip)
 	}
 
-	return retrieveBlockSize(pgb.ctx, pgb.db, ind)
+	return pgb.q.retrieveBlockSize(pgb.ctx, pgb.db, ind)
 }
 
 // BlockSizeRange returns an array of block sizes for block range ind0 to ind1
@@ -5414,12 +5932,12 @@ func (pgb *ChainDB) BlockSizeRange(ind0, ind1 int64) ([]int32, error) {
 		return nil, fmt.Errorf("Cannot retrieve block size range [%d,%d], have height %d",
 			ind0, ind1, tip)
 	}
-	return retrieveBlockSizeRange(pgb.ctx, pgb.db, ind0, ind1)
+	return pgb.q.retrieveBlockSizeRange(pgb.ctx, pgb.readDB(), ind0, ind1)
 }
 
 // GetSDiff gets the stake difficulty in DCR for a given block height.
 func (pgb *ChainDB) GetSDiff(idx int) float64 {
-	sdiff, err := retrieveSDiff(pgb.ctx, pgb.db, int64(idx))
+	sdiff, err := pgb.q.retrieveSDiff(pgb.ctx, pgb.db, int64(idx))
 	if err != nil {
 		log.Errorf("Unable to retrieve stake difficulty: %v", err)
 		return -1
@@ -5434,7 +5952,7 @@ func (pgb *ChainDB) GetSBitsByHash(hash string) int64 {
 		log.Errorf("invalid hash %s", hash)
 		return 0
 	}
-	sbits, err := retrieveSBitsByHash(pgb.ctx, pgb.db, ch)
+	sbits, err := pgb.q.retrieveSBitsByHash(pgb.ctx, pgb.readDB(), ch)
 	if err != nil {
 		log.Errorf("Unable to retrieve stake difficulty: %v", err)
 		return -1
@@ -5460,7 +5978,7 @@ func (pgb *ChainDB) SDiffRange(ind0, ind1 int64) ([]float64, error) {
 		return nil, fmt.Errorf("Cannot retrieve sdiff range [%d,%d], have height %d",
 			ind0, ind1, tip)
 	}
-	return retrieveSDiffRange(pgb.ctx, pgb.db, ind0, ind1)
+	return pgb.q.retrieveSDiffRange(pgb.ctx, pgb.readDB(), ind0, ind1)
 }
 
 // GetMempoolSSTxSummary returns the current *apitypes.MempoolTicketFeeInfo.
@@ -5611,9 +6129,10 @@ func (pgb *ChainDB) GetAddressTransactionsRawWithSkip(addr string, count, skip i
 
 	ctx, cancel := context.WithTimeout(pgb.ctx, 10*time.Second)
 	defer cancel()
+	db := pgb.readDB()
 
 	// vins
-	rows, err := pgb.db.QueryContext(ctx, internal.SelectVinsForAddress, addr, count, skip)
+	rows, err := db.QueryContext(ctx, pgb.q.SelectVinsForAddress, addr, count, skip)
 	if err != nil {
 		log.Errorf("GetAddressTransactionsRawWithSkip: SelectVinsForAddress %s: %v", addr, err)
 		return nil
@@ -5653,7 +6172,7 @@ func (pgb *ChainDB) GetAddressTransactionsRawWithSkip(addr string, count, skip i
 	}
 
 	// tx
-	rows, err = pgb.db.QueryContext(ctx, internal.SelectAddressTxns, addr, count, skip)
+	rows, err = db.QueryContext(ctx, pgb.q.SelectAddressTxns, addr, count, skip)
 	if err != nil {
 		log.Errorf("GetAddressTransactionsRawWithSkip: SelectAddressTxns %s: %v", addr, err)
 		return nil
@@ -5719,7 +6238,7 @@ func (pgb *ChainDB) GetAddressTransactionsRawWithSkip(addr string, count, skip i
 	}
 
 	// vouts
-	rows, err = pgb.db.QueryContext(ctx, internal.SelectVoutsForAddress, addr, count, skip)
+	rows, err = db.QueryContext(ctx, pgb.q.SelectVoutsForAddress, addr, count, skip)
 	if err != nil {
 		log.Errorf("GetAddressTransactionsRawWithSkip: SelectVoutsForAddress %s: %v", addr, err)
 		return nil
@@ -6513,7 +7032,7 @@ func (pgb *ChainDB) getTip() (*apitypes.BlockDataBasic, error) {
 	if pgb.tipSummary != nil && pgb.tipSummary.Hash == pgb.BestBlockHashStr() {
 		return pgb.tipSummary, nil
 	}
-	tip, err := retrieveLatestBlockSummary(pgb.ctx, pgb.db)
+	tip, err := pgb.q.retrieveLatestBlockSummary(pgb.ctx, pgb.db)
 	if err != nil {
 		return nil, err
 	}
@@ -6596,7 +7115,7 @@ func (pgb *ChainDB) Difficulty(timestamp int64) float64 {
 		return diff
 	}
 
-	diff, err := retrieveDiff(pgb.ctx, pgb.db, timestamp)
+	diff, err := pgb.q.retrieveDiff(pgb.ctx, pgb.db, timestamp)
 	if err != nil {
 		log.Errorf("Unable to retrieve difficulty: %v", err)
 		return -1
@@ -0,0 +1,55 @@
+//go:build pgonline
+
+package dcrpg
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/decred/dcrdata/v8/testutil/dbconfig"
+)
+
+// openScratchDB creates an empty database on the test server and connects to
+// it, so that tables may be created and migrated without changing the test
+// database. The database is dropped when the test ends.
+func openScratchDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	if _, err := sqlDb.Exec(`DROP DATABASE IF EXISTS ` + name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDb.Exec(`CREATE DATABASE ` + name); err != nil {
+		t.Fatalf("failed to create database %s: %v", name, err)
+	}
+	scratch, err := Connect(dbconfig.PGTestsHost, dbconfig.PGTestsPort,
+		dbconfig.PGTestsUser, dbconfig.PGTestsPass, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		scratch.Close()
+		if _, err := sqlDb.Exec(`DROP DATABASE IF EXISTS ` + name); err != nil {
+			t.Errorf("failed to drop database %s: %v", name, err)
+		}
+	})
+	return scratch
+}
+
+func init() {
+	testBackends = append(testBackends, testBackend{
+		name: "postgres",
+		cfg: func(t *testing.T) *ChainDBCfg {
+			const name = "dcrdata_chaindb_test"
+			openScratchDB(t, name)
+			return &ChainDBCfg{
+				DBi: &DBInfo{
+					Host:   dbconfig.PGTestsHost,
+					Port:   dbconfig.PGTestsPort,
+					User:   dbconfig.PGTestsUser,
+					Pass:   dbconfig.PGTestsPass,
+					DBName: name,
+				},
+				HidePGConfig: true,
+			}
+		},
+	})
+}
@@ -0,0 +1,867 @@
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/db/dcrsqlite"
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/stakedb"
+	"github.com/decred/dcrdata/v8/txhelpers"
+)
+
+// testChain is a ChainDB backed by a fresh database, with a stake database
+// and the synthetic simnet blocks that were stored in it.
+type testChain struct {
+	db      *ChainDB
+	stakeDB *stakedb.StakeDatabase
+	blocks  []*wire.MsgBlock
+	addrs   []stdaddr.Address
+}
+
+func testAddress(t *testing.T, params *chaincfg.Params, i byte) stdaddr.Address {
+	t.Helper()
+	var pkh [20]byte
+	pkh[0], pkh[19] = 0xdc, i
+	addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pkh[:], params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func payTo(t *testing.T, addr stdaddr.Address, value int64) *wire.TxOut {
+	t.Helper()
+	version, script := addr.PaymentScript()
+	return &wire.TxOut{Value: value, Version: version, PkScript: script}
+}
+
+// nextBlock creates a block on top of prev with a coinbase paying to addr and
+// the provided regular transactions.
+func nextBlock(t *testing.T, prev *wire.MsgBlock, addr stdaddr.Address, txns ...*wire.MsgTx) *wire.MsgBlock {
+	t.Helper()
+	height := prev.Header.Height + 1
+	coinbase := wire.NewMsgTx()
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex, wire.TxTreeRegular),
+		Sequence:         wire.MaxTxInSequenceNum,
+		ValueIn:          5e9,
+		BlockHeight:      wire.NullBlockHeight,
+		BlockIndex:       wire.NullBlockIndex,
+		SignatureScript:  []byte{byte(height), byte(height >> 8), 0x51},
+	})
+	coinbase.AddTxOut(payTo(t, addr, 5e9))
+	// The signature script is not part of the transaction hash, so use the
+	// lock time to make each coinbase unique.
+	coinbase.LockTime = height
+
+	header := prev.Header
+	header.PrevBlock = prev.BlockHash()
+	header.Height = height
+	header.Timestamp = prev.Header.Timestamp.Add(5 * time.Minute)
+	header.VoteBits = dcrutil.BlockValid
+	header.Voters = 0
+	header.FreshStake = 0
+	header.Revocations = 0
+
+	block := wire.NewMsgBlock(&header)
+	if err := block.AddTransaction(coinbase); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range txns {
+		if err := block.AddTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return block
+}
+
+// spend creates a transaction spending output 0 of prev, paying value to each
+// of the addresses.
+func spend(t *testing.T, prev *wire.MsgTx, value int64, addrs ...stdaddr.Address) *wire.MsgTx {
+	t.Helper()
+	prevHash := prev.TxHash()
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0, wire.TxTreeRegular),
+		prev.TxOut[0].Value, []byte{0x51}))
+	for _, addr := range addrs {
+		tx.AddTxOut(payTo(t, addr, value))
+	}
+	return tx
+}
+
+// testBackend is a database for a ChainDB under test.
+type testBackend struct {
+	name string
+	// cfg returns a ChainDBCfg for a new, empty database that is removed when
+	// the test ends.
+	cfg func(t *testing.T) *ChainDBCfg
+}
+
+// testBackends are the backends that the ChainDB tests run against. The
+// pgonline build tag adds PostgreSQL.
+var testBackends = []testBackend{{
+	name: "sqlite",
+	cfg: func(t *testing.T) *ChainDBCfg {
+		path := filepath.Join(t.TempDir(), "dcrdata.sqlite")
+		return &ChainDBCfg{Dialect: dcrsqlite.NewDialect(path)}
+	},
+}}
+
+// forEachBackend runs f as a subtest for each of the testBackends, with a
+// ChainDB that stores numBlocks synthetic blocks. See newTestChain.
+func forEachBackend(t *testing.T, numBlocks int, f func(t *testing.T, tc *testChain)) {
+	for _, backend := range testBackends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			f(t, newTestChain(t, backend, numBlocks))
+		})
+	}
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int64 {
+	t.Helper()
+	var n int64
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// newTestChain creates a new ChainDB in the backend and stores the simnet
+// genesis block and numBlocks synthetic blocks. Starting at height 2, each
+// block also spends the coinbase of the previous block to two of the test
+// addresses. Since the stake DB has no node client, numBlocks must be less
+// than the ticket maturity.
+func newTestChain(t *testing.T, backend testBackend, numBlocks int) *testChain {
+	t.Helper()
+
+	params := chaincfg.SimNetParams()
+
+	stakeDB, _, err := stakedb.NewStakeDatabase(nil, params, filepath.Join(t.TempDir(), "stakedb"))
+	if err != nil {
+		t.Fatalf("NewStakeDatabase: %v", err)
+	}
+	t.Cleanup(func() { _ = stakeDB.Close() })
+
+	cfg := backend.cfg(t)
+	cfg.Params = params
+	cfg.AddrCacheRowCap = 1024
+	cfg.AddrCacheAddrCap = 1024
+	cfg.AddrCacheUTXOByteCap = 1 << 16
+	db, err := NewChainDB(context.Background(), cfg, stakeDB, nil, nil, func() {})
+	if err != nil {
+		t.Fatalf("NewChainDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	tc := &testChain{
+		db:      db,
+		stakeDB: stakeDB,
+		addrs:   []stdaddr.Address{testAddress(t, params, 1), testAddress(t, params, 2)},
+	}
+
+	genesis := params.GenesisBlock
+	if _, _, _, err = db.StoreBlock(genesis, true, true, true, true, "00"); err != nil {
+		t.Fatalf("StoreBlock(genesis): %v", err)
+	}
+	tc.blocks = append(tc.blocks, genesis)
+
+	for i := 0; i < numBlocks; i++ {
+		prev := tc.blocks[len(tc.blocks)-1]
+		var txns []*wire.MsgTx
+		if prev.Header.Height > 0 {
+			txns = append(txns, spend(t, prev.Transactions[0], 2e9, tc.addrs...))
+		}
+		block := nextBlock(t, prev, tc.addrs[i%2], txns...)
+		if err = stakeDB.ConnectBlock(dcrutil.NewBlock(block)); err != nil {
+			t.Fatalf("ConnectBlock(%d): %v", block.Header.Height, err)
+		}
+		if _, _, _, err = db.StoreBlock(block, true, true, true, true, "00"); err != nil {
+			t.Fatalf("StoreBlock(%d): %v", block.Header.Height, err)
+		}
+		tc.blocks = append(tc.blocks, block)
+	}
+
+	return tc
+}
+
+func TestNewChainDB(t *testing.T) {
+	forEachBackend(t, 2, func(t *testing.T, tc *testChain) {
+		height, hash, err := tc.db.HeightHashDB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if height != 2 {
+			t.Errorf("expected best block height 2, got %d", height)
+		}
+		if want := tc.blocks[2].BlockHash().String(); hash != want {
+			t.Errorf("expected best block hash %s, got %s", want, hash)
+		}
+
+		for _, table := range []string{"blocks", "transactions", "vins", "vouts",
+			"addresses", "tickets", "votes", "misses", "agendas", "agenda_votes",
+			"treasury", "swaps", "meta", "block_chain", "stats", "exchange_rates"} {
+			// Fails if the table does not exist.
+			countRows(t, tc.db.db, table)
+		}
+	})
+}
+
+func TestBackendInsertSwap(t *testing.T) {
+	forEachBackend(t, 0, func(t *testing.T, tc *testChain) {
+		asd := &txhelpers.AtomicSwapData{
+			ContractTx:       &chainhash.Hash{1, 2},
+			ContractVout:     1,
+			SpendTx:          &chainhash.Hash{3, 4},
+			SpendVin:         2,
+			Value:            1234,
+			ContractAddress:  "Dcasdfasdfasdfasdf",
+			RecipientAddress: "Dcrecipient",
+			RefundAddress:    "Dcrefund",
+			Locktime:         1234567,
+			SecretHash:       [32]byte{5, 6, 7, 8},
+			Secret:           []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			Contract:         []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			IsRefund:         false,
+		}
+		if err := tc.db.q.insertSwap(tc.db.db, 1234, asd); err != nil {
+			t.Fatal(err)
+		}
+
+		// A second spend of the same contract is a new row, while storing the
+		// same spend again updates the existing row.
+		asd.SpendTx = &chainhash.Hash{5, 6}
+		asd.SpendVin = 2
+		asd.Secret = nil
+		for i := 0; i < 2; i++ {
+			if err := tc.db.q.insertSwap(tc.db.db, 1234, asd); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if n := countRows(t, tc.db.db, "swaps"); n != 2 {
+			t.Errorf("expected 2 swap rows, got %d", n)
+		}
+	})
+}
+
+func TestBackendAddressTransactionsAll(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		// address with no transactions.
+		address := testAddress(t, tc.db.chainParams, 99).String()
+		rows, err := tc.db.AddressTransactionsAll(address)
+		if err != nil {
+			t.Errorf("err should have been nil, was: %v", err)
+		}
+		if rows != nil {
+			t.Fatalf("should have been no rows, got %v", rows)
+		}
+
+		height, hash, _ := tc.db.HeightHashDBLegacy()
+		h, _ := chainhash.NewHashFromStr(hash)
+		blockID := cache.NewBlockID(h, int64(height))
+		wasStored := tc.db.AddressCache.StoreRows(address, rows, blockID)
+		if !wasStored {
+			t.Fatalf("Address not stored in cache!")
+		}
+
+		r, bid := tc.db.AddressCache.Rows(address)
+		if bid == nil {
+			t.Errorf("BlockID should not have been nil since this is a cache hit.")
+		}
+		if r == nil || len(r) > 0 {
+			t.Errorf("rows should have been non-nil empty slice, got: %v", r)
+		}
+
+		// Address with coinbase outputs and spends. The first address receives
+		// the coinbases of blocks 1 and 3, spent in blocks 2 and 4, and one
+		// output of the spending transactions in blocks 2, 3 and 4.
+		rows, err = tc.db.AddressTransactionsAll(tc.addrs[0].String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var funding, spending int
+		for _, row := range rows {
+			if row.IsFunding {
+				funding++
+			} else {
+				spending++
+			}
+		}
+		if funding != 5 || spending != 2 {
+			t.Errorf("expected 5 funding and 2 spending rows, got %d and %d",
+				funding, spending)
+		}
+	})
+}
+
+func TestBackendMergeRows(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		address := tc.addrs[0].String()
+
+		rows, err := tc.db.AddressTransactionsAll(address)
+		if err != nil {
+			t.Errorf("err should have been nil, was: %v", err)
+		}
+		if rows == nil {
+			t.Fatalf("should have rows, got none")
+		}
+
+		mergedRows, err := dbtypes.MergeRows(rows)
+		if err != nil {
+			t.Fatalf("MergeRows failed: %v", err)
+		}
+
+		mergedRows0, err := tc.db.AddressTransactionsAllMerged(address)
+		if err != nil {
+			t.Errorf("err should have been nil, was: %v", err)
+		}
+		if mergedRows0 == nil {
+			t.Fatalf("should have rows, got none")
+		}
+
+		if len(mergedRows) != len(mergedRows0) {
+			t.Errorf("len(mergedRows) = %d != len(mergedRows0) = %d",
+				len(mergedRows), len(mergedRows0))
+		}
+	})
+}
+
+func TestAddressBalance(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		bal, _, err := tc.db.AddressBalance(tc.addrs[1].String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Coinbases of blocks 2 and 4, the latter unspent, and one unspent output
+		// of each spending transaction in blocks 2, 3 and 4.
+		if bal.NumSpent != 1 || bal.NumUnspent != 4 {
+			t.Errorf("expected 1 spent and 4 unspent outputs, got %d and %d",
+				bal.NumSpent, bal.NumUnspent)
+		}
+		if want := int64(5e9 + 3*2e9); bal.TotalUnspent != want {
+			t.Errorf("expected total unspent %d, got %d", want, bal.TotalUnspent)
+		}
+	})
+}
+
+func TestBackendRetrieveUTXOs(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		utxos, err := tc.db.q.retrieveUTXOs(context.Background(), tc.db.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The coinbase of block 4 and two outputs of each of the three spending
+		// transactions. The zero-value genesis coinbase output is not a UTXO.
+		if len(utxos) != 7 {
+			t.Errorf("expected 7 utxos, got %d", len(utxos))
+		}
+
+		var totalValue int64
+		for i := range utxos {
+			totalValue += utxos[i].Value
+		}
+		t.Logf("Found %d utxos with a total value of %v", len(utxos),
+			dcrutil.Amount(totalValue))
+	})
+}
+
+func TestBackendUtxoStoreReinit(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		utxos, err := tc.db.q.retrieveUTXOs(context.Background(), tc.db.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uc := newUtxoStore(100)
+		uc.Reinit(utxos)
+		if uc.Size() != len(utxos) {
+			t.Errorf("expected %d utxos in the store, got %d", len(utxos), uc.Size())
+		}
+	})
+}
+
+func TestBackendDeleteBestBlock(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		ctx := context.Background()
+
+		res, height, hash, err := tc.db.q.deleteBestBlock(ctx, tc.db.db)
+		if err != nil {
+			t.Fatalf("Failed to delete best block data: %v", err)
+		}
+		t.Logf("Deletion summary for block %d (%s): %v", height, hash, res)
+
+		if res.Blocks != 1 || res.Transactions != 2 || res.Vins != 2 ||
+			res.Vouts != 3 || res.Addresses != 4 {
+			t.Errorf("unexpected deletion summary: %v", res)
+		}
+
+		// The returned height and hash are for the new best block.
+		if height != 3 || hash != dbtypes.ChainHash(tc.blocks[3].BlockHash()) {
+			t.Errorf("expected best block 3 after deletion, got %d (%v)", height, hash)
+		}
+		height0, hash0, err := tc.db.q.retrieveBestBlockHeight(ctx, tc.db.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(height0) != height || hash0 != hash {
+			t.Errorf("best block in the meta table is %d (%v), expected %d (%v)",
+				height0, hash0, height, hash)
+		}
+	})
+}
+
+func TestBackendDeleteBlocks(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		ctx := context.Background()
+
+		N := int64(3)
+		res, height, _, err := tc.db.q.deleteBlocks(ctx, N, tc.db.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != int(N) {
+			t.Errorf("Expected to delete %d blocks; actually deleted %d.", N, len(res))
+		}
+		if height != 1 {
+			t.Errorf("expected best block 1 after deletion, got %d", height)
+		}
+
+		summary := dbtypes.DeletionSummarySlice(res).Reduce()
+		if summary.Blocks != N {
+			t.Errorf("Expected summary of %d deleted blocks, got %d.", N, summary.Blocks)
+		}
+
+		// Only the genesis and block 1 coinbases remain.
+		for table, want := range map[string]int64{"blocks": 2, "transactions": 2,
+			"vins": 2, "vouts": 2, "addresses": 1} {
+			if n := countRows(t, tc.db.db, table); n != want {
+				t.Errorf("expected %d rows in %s, got %d", want, table, n)
+			}
+		}
+	})
+}
+
+func TestBackendRetrieveTxsByBlockHash(t *testing.T) {
+	forEachBackend(t, 0, func(t *testing.T, tc *testChain) {
+		genesis := tc.blocks[0]
+		block0 := dbtypes.ChainHash(genesis.BlockHash())
+		txs, _, _, blockTimes, err := tc.db.q.retrieveTxsByBlockHash(context.Background(), tc.db.db, block0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txs) != len(genesis.Transactions) {
+			t.Fatalf("expected %d transactions, got %d", len(genesis.Transactions), len(txs))
+		}
+		for i := range blockTimes {
+			v, err := blockTimes[i].Value()
+			if err != nil {
+				t.Error(err)
+			}
+			tT, ok := v.(time.Time)
+			if !ok {
+				t.Errorf("v (%T) not a time.Time", v)
+			}
+			if tT.Unix() != genesis.Header.Timestamp.Unix() {
+				t.Errorf("Incorrect block time: got %d, expected %d",
+					tT.Unix(), genesis.Header.Timestamp.Unix())
+			}
+		}
+	})
+}
+
+func TestTimeBasedIntervals(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		for _, grouping := range []dbtypes.TimeBasedGrouping{dbtypes.DayGrouping,
+			dbtypes.WeekGrouping, dbtypes.MonthGrouping, dbtypes.YearGrouping} {
+			intervals, err := tc.db.TimeBasedIntervals(grouping, 10, 0)
+			if err != nil {
+				t.Fatalf("TimeBasedIntervals(%v): %v", grouping, err)
+			}
+			var txCount uint64
+			for _, in := range intervals {
+				txCount += in.TxCount
+			}
+			if txCount != 8 {
+				t.Errorf("%v: expected 8 transactions, got %d", grouping, txCount)
+			}
+		}
+	})
+}
+
+func TestRegisterCharts(t *testing.T) {
+	// The window charts need at least one full difficulty window.
+	forEachBackend(t, int(chaincfg.SimNetParams().StakeDiffWindowSize)+4, func(t *testing.T, tc *testChain) {
+		charts := cache.NewChartData(context.Background(), 4, tc.db.chainParams)
+		tc.db.RegisterCharts(charts)
+		if err := charts.Update(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestTreasuryStatements(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		blockTime := time.Date(2022, time.April, 15, 0, 0, 0, 0, time.UTC)
+		tbase, tspend, tadd := dbtypes.ChainHash{1}, dbtypes.ChainHash{2}, dbtypes.ChainHash{3}
+		_, err := tc.db.db.Exec(`INSERT INTO treasury (tx_hash, tx_type, value,
+				block_hash, block_height, block_time, is_mainchain)
+			VALUES ($1, 6, 100, $4, 5, $5, $6), ($2, 5, -40, $4, 5, $5, $6),
+				($3, 4, 30, $4, 5, $5, $6);`,
+			tbase, tspend, tadd, dbtypes.ChainHash{4}, blockTime, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tc.db.db.Exec(`INSERT INTO addresses (address, tx_hash,
+				valid_mainchain, value, block_time, is_funding, tx_vin_vout_index, tx_type)
+			VALUES ('payee', $1, $4, 40, $3, $4, 1, 5), ('source', $2, $4, 50, $3, $5, 0, 4);`,
+			tspend, tadd, blockTime, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statements, err := tc.db.TreasuryStatements(dbtypes.MonthGrouping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(statements) != 1 {
+			t.Fatalf("expected 1 statement, got %d", len(statements))
+		}
+		st := statements[0]
+		if st.Start.T.Month() != time.April || st.TBase != 100 || st.Added != 30 ||
+			st.Spent != 40 || st.ClosingBalance != 90 {
+			t.Errorf("unexpected statement %+v", st)
+		}
+		if len(st.AddSources) != 1 || st.AddSources[0].Address != "source" || st.AddSources[0].Amount != 30 {
+			t.Errorf("unexpected tadd sources %v", st.AddSources)
+		}
+		if len(st.Payouts) != 1 || st.Payouts[0].Address != "payee" || st.Payouts[0].Amount != 40 {
+			t.Errorf("unexpected payouts %v", st.Payouts)
+		}
+
+		if _, err = tc.db.TreasuryStatements(dbtypes.WeekGrouping); err == nil {
+			t.Error("expected an error for the week grouping")
+		}
+	})
+}
+
+func TestVSPTicketSpends(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		// Two voted tickets and one missed and revoked ticket pay the fee address,
+		// the first vote twice.
+		vote1, vote2, revoke, other := dbtypes.ChainHash{1}, dbtypes.ChainHash{2}, dbtypes.ChainHash{3}, dbtypes.ChainHash{4}
+		_, err := tc.db.db.Exec(`INSERT INTO transactions (id, tx_hash) VALUES
+			(1001, $1), (1002, $2), (1003, $3), (1004, $4);`, vote1, vote2, revoke, other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tc.db.db.Exec(`INSERT INTO tickets (tx_hash, block_hash, block_height,
+				spend_type, pool_status, is_mainchain, spend_height, spend_tx_db_id)
+			VALUES ($1, $5, 10, 2, 1, $6, 300, 1001), ($2, $5, 20, 2, 1, $6, 400, 1002),
+				($3, $5, 30, 1, 3, $6, 500, 1003), ($4, $5, 40, 2, 1, $6, 600, 1004);`,
+			dbtypes.ChainHash{11}, dbtypes.ChainHash{12}, dbtypes.ChainHash{13},
+			dbtypes.ChainHash{14}, dbtypes.ChainHash{15}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tc.db.db.Exec(`INSERT INTO addresses (address, tx_hash, valid_mainchain,
+				value, block_time, is_funding, tx_vin_vout_index, tx_type)
+			VALUES ('fee', $1, $6, 1, $5, $6, 2, 2), ('fee', $1, $6, 1, $5, $6, 3, 2),
+				('fee', $2, $6, 1, $5, $6, 2, 2), ('fee', $3, $6, 1, $5, $6, 1, 3),
+				('user', $4, $6, 1, $5, $6, 2, 2), ('fee', $4, $6, 1, $5, $7, 0, 2);`,
+			vote1, vote2, revoke, other, time.Date(2022, time.April, 15, 0, 0, 0, 0, time.UTC),
+			true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		spends, err := tc.db.VSPTicketSpends([]string{"fee"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(spends) != 1 {
+			t.Fatalf("expected 1 fee address, got %d", len(spends))
+		}
+		s := spends[0]
+		if s.Address != "fee" || s.Voted != 2 || s.VoteAgeSum != 290+380 ||
+			s.Missed != 1 || s.Expired != 0 || s.Revoked != 1 {
+			t.Errorf("unexpected ticket spends %+v", s)
+		}
+	})
+}
+
+func TestMeanVoteAges(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		_, err := tc.db.db.Exec(`INSERT INTO tickets (tx_hash, block_hash, block_height,
+				spend_type, pool_status, is_mainchain, spend_height)
+			VALUES ($1, $5, 10, 2, 1, $6, 110), ($2, $5, 20, 2, 1, $6, 150),
+				($3, $5, 30, 1, 3, $6, 160), ($4, $5, 40, 2, 1, $6, 240);`,
+			dbtypes.ChainHash{11}, dbtypes.ChainHash{12}, dbtypes.ChainHash{13},
+			dbtypes.ChainHash{14}, dbtypes.ChainHash{15}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bins, err := tc.db.MeanVoteAges(0, 300, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bins) != 2 {
+			t.Fatalf("expected 2 bins, got %d", len(bins))
+		}
+		if b := bins[0]; b.Height != 100 || b.Votes != 2 || b.MeanAge != 115 {
+			t.Errorf("unexpected first bin %+v", b)
+		}
+		if b := bins[1]; b.Height != 200 || b.Votes != 1 || b.MeanAge != 200 {
+			t.Errorf("unexpected second bin %+v", b)
+		}
+	})
+}
+
+func TestBlockStakeData(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		blocks, err := tc.db.q.retrieveBlockStakeData(context.Background(), tc.db.db, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blocks) != 3 {
+			t.Fatalf("expected 3 blocks, got %d", len(blocks))
+		}
+		for i, b := range blocks {
+			header := tc.blocks[i+2].Header
+			if b.Height != int64(header.Height) || b.SBits != header.SBits ||
+				b.PoolSize != int64(header.PoolSize) || b.Time.UNIX() != header.Timestamp.Unix() {
+				t.Errorf("unexpected block %+v", b)
+			}
+		}
+	})
+}
+
+func TestAgendaVoteHistory(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		_, err := tc.db.db.Exec(`INSERT INTO agendas (id, name, status) VALUES (1, 'testagenda', 1);`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		votes := []struct {
+			height  int64
+			version uint32
+			choice  dbtypes.VoteChoice
+		}{
+			{5, 9, dbtypes.Yes}, {8, 10, dbtypes.No}, {12, 10, dbtypes.Yes},
+			{15, 10, dbtypes.Abstain}, {18, 10, dbtypes.Yes}, {25, 10, dbtypes.No},
+		}
+		for i, v := range votes {
+			_, err = tc.db.db.Exec(`INSERT INTO votes (id, height, tx_hash, block_hash,
+					candidate_block_hash, version, is_mainchain)
+				VALUES ($1, $2, $3, $3, $3, $4, $5);`,
+				i+1, v.height, dbtypes.ChainHash{byte(i + 1)}, v.version, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.version < 10 {
+				continue
+			}
+			_, err = tc.db.db.Exec(`INSERT INTO agenda_votes (votes_row_id, agendas_row_id,
+				agenda_vote_choice) VALUES ($1, 1, $2);`, i+1, v.choice)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ctx := context.Background()
+		tallies, err := tc.db.q.retrieveAgendaVotesByRCI(ctx, tc.db.db, "testagenda", 2, 10, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []dbtypes.AgendaRCIVotes{
+			{RCI: 0, No: 1, Total: 1},
+			{RCI: 1, Yes: 2, Abstain: 1, Total: 3},
+		}
+		if len(tallies) != len(want) {
+			t.Fatalf("expected %d intervals, got %d", len(want), len(tallies))
+		}
+		for i, v := range tallies {
+			if *v != want[i] {
+				t.Errorf("interval %d: got %+v, want %+v", i, *v, want[i])
+			}
+		}
+
+		versions, err := retrieveVersionsByRCI(ctx, tc.db.db, tc.db.q.SelectVoteVersionsByRCI, 2, 10, 2, 21)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantVersions := []dbtypes.RCIVersionCount{
+			{RCI: 0, Version: 9, Count: 1}, {RCI: 0, Version: 10, Count: 1},
+			{RCI: 1, Version: 10, Count: 3},
+		}
+		if len(versions) != len(wantVersions) {
+			t.Fatalf("expected %d version counts, got %d", len(wantVersions), len(versions))
+		}
+		for i, c := range versions {
+			if *c != wantVersions[i] {
+				t.Errorf("version count %d: got %+v, want %+v", i, *c, wantVersions[i])
+			}
+		}
+
+		blockVersions, err := retrieveVersionsByRCI(ctx, tc.db.db, tc.db.q.SelectBlockVersionsByRCI, 0, 10, 0, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var numBlocks uint32
+		for _, c := range blockVersions {
+			numBlocks += c.Count
+			if c.RCI != 0 {
+				t.Errorf("unexpected block interval %d", c.RCI)
+			}
+		}
+		if numBlocks != 5 {
+			t.Errorf("expected 5 blocks, got %d", numBlocks)
+		}
+	})
+}
+
+func TestTicketSpendAddresses(t *testing.T) {
+	forEachBackend(t, 4, func(t *testing.T, tc *testChain) {
+		// A voted ticket paid the fee address, a second one did not, and a live
+		// ticket is not yet spent.
+		voted, other, live := dbtypes.ChainHash{11}, dbtypes.ChainHash{12}, dbtypes.ChainHash{13}
+		vote1, vote2 := dbtypes.ChainHash{1}, dbtypes.ChainHash{2}
+		_, err := tc.db.db.Exec(`INSERT INTO transactions (id, tx_hash) VALUES
+			(1001, $1), (1002, $2);`, vote1, vote2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tc.db.db.Exec(`INSERT INTO tickets (tx_hash, block_hash, block_height, price,
+				spend_type, pool_status, is_mainchain, spend_height, spend_tx_db_id)
+			VALUES ($1, $4, 10, 100, 2, 1, $5, 300, 1001), ($2, $4, 20, 110, 2, 1, $5, 400, 1002),
+				($3, $4, 30, 120, 0, 0, $5, NULL, NULL);`,
+			voted, other, live, dbtypes.ChainHash{15}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tc.db.db.Exec(`INSERT INTO addresses (address, tx_hash, valid_mainchain,
+				value, block_time, is_funding, tx_vin_vout_index, tx_type)
+			VALUES ('fee', $1, $4, 1, $3, $4, 2, 2), ('user', $2, $4, 1, $3, $4, 2, 2);`,
+			vote1, vote2, time.Date(2022, time.April, 15, 0, 0, 0, 0, time.UTC), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		missing := dbtypes.ChainHash{14}
+		tickets, err := tc.db.TicketSpendAddresses([]string{voted.String(), other.String(),
+			live.String(), missing.String()}, []string{"fee"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tickets) != 3 {
+			t.Fatalf("expected 3 tickets, got %d", len(tickets))
+		}
+		byHash := make(map[string]*dbtypes.TicketSpendAddress, len(tickets))
+		for _, ticket := range tickets {
+			byHash[ticket.Hash] = ticket
+		}
+		if v := byHash[voted.String()]; v == nil || v.FeeAddress != "fee" || v.BlockHeight != 10 ||
+			v.Price != 100 || v.PoolStatus != dbtypes.PoolStatusVoted {
+			t.Errorf("unexpected voted ticket %+v", v)
+		}
+		if o := byHash[other.String()]; o == nil || o.FeeAddress != "" || o.BlockHeight != 20 {
+			t.Errorf("unexpected ticket %+v", o)
+		}
+		if l := byHash[live.String()]; l == nil || l.FeeAddress != "" || l.PoolStatus != dbtypes.PoolStatusLive {
+			t.Errorf("unexpected live ticket %+v", l)
+		}
+
+		if _, err = tc.db.TicketSpendAddresses([]string{"nothex"}, nil); err == nil {
+			t.Error("expected an error for an invalid ticket hash")
+		}
+	})
+}
+
+func TestIndexPrices(t *testing.T) {
+	forEachBackend(t, 0, func(t *testing.T, tc *testChain) {
+		hour := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+		err := tc.db.StoreIndexPrices([]*dbtypes.IndexPrice{
+			{Currency: "USD", Start: hour, Price: 20, Volume: 1000},
+			{Currency: "USD", Start: hour.Add(time.Hour), Price: 21, Volume: 1000, Backfilled: true},
+			{Currency: "EUR", Start: hour, Price: 18, Volume: 1000},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A backfilled price does not replace a recorded price, but a recorded
+		// price replaces a backfilled price.
+		err = tc.db.StoreIndexPrices([]*dbtypes.IndexPrice{
+			{Currency: "USD", Start: hour, Price: 25, Backfilled: true},
+			{Currency: "USD", Start: hour.Add(time.Hour), Price: 22, Volume: 1100},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			t      time.Time
+			maxAge time.Duration
+			want   float64
+		}{
+			{hour, time.Hour, 20},
+			{hour.Add(59 * time.Minute), time.Hour, 20},
+			{hour.Add(90 * time.Minute), time.Hour, 22},
+			{hour.Add(5 * time.Hour), 24 * time.Hour, 22},
+		}
+		for _, tt := range tests {
+			p, err := tc.db.IndexPriceAt("USD", tt.t, tt.maxAge)
+			if err != nil {
+				t.Fatalf("IndexPriceAt(%v): %v", tt.t, err)
+			}
+			if p.Price != tt.want || p.Backfilled {
+				t.Errorf("IndexPriceAt(%v): got %+v, want price %v", tt.t, p, tt.want)
+			}
+		}
+
+		// A backfilled price does not replace a backfilled price either.
+		later := hour.Add(24 * time.Hour)
+		for _, price := range []float64{30, 31} {
+			err = tc.db.StoreIndexPrices([]*dbtypes.IndexPrice{
+				{Currency: "USD", Start: later, Price: price, Backfilled: true},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if p, err := tc.db.IndexPriceAt("USD", later, time.Hour); err != nil || p.Price != 30 || !p.Backfilled {
+			t.Errorf("IndexPriceAt(%v): got %+v (%v), want backfilled price 30", later, p, err)
+		}
+
+		for _, at := range []time.Time{hour.Add(-time.Second), hour.Add(5 * time.Hour)} {
+			if _, err = tc.db.IndexPriceAt("USD", at, time.Hour); !errors.Is(err, dbtypes.ErrNoResult) {
+				t.Errorf("IndexPriceAt(%v): expected ErrNoResult, got %v", at, err)
+			}
+		}
+
+		// The hourly prices of a day are averaged before the mean of the days.
+		month := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+		if p, err := tc.db.IndexPriceMean("USD", month, month.AddDate(0, 1, 0)); err != nil || p != 25.5 {
+			t.Errorf("IndexPriceMean: got %v (%v), want 25.5", p, err)
+		}
+		if p, err := tc.db.IndexPriceMean("USD", month, later); err != nil || p != 21 {
+			t.Errorf("IndexPriceMean: got %v (%v), want 21", p, err)
+		}
+		if _, err = tc.db.IndexPriceMean("USD", month.AddDate(0, 1, 0), month.AddDate(0, 2, 0)); !errors.Is(err, dbtypes.ErrNoResult) {
+			t.Errorf("IndexPriceMean: expected ErrNoResult, got %v", err)
+		}
+	})
+}
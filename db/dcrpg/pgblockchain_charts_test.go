@@ -19,9 +19,9 @@ func registerDummyFeeAndPoolInfo(charts *cache.ChartData) {
 	dummyAppender := func(charts *cache.ChartData, _ *sql.Rows) error {
 		blocks := charts.Blocks
 		neededLength := len(blocks.Time)
-		blocks.PoolSize = make([]uint64, neededLength)
-		blocks.PoolValue = make([]uint64, neededLength)
-		blocks.Fees = make([]uint64, neededLength)
+		blocks.SetUints(cache.PoolSizeSeries, make([]uint64, neededLength))
+		blocks.SetUints(cache.PoolValueSeries, make([]uint64, neededLength))
+		blocks.SetUints(cache.FeesSeries, make([]uint64, neededLength))
 		return nil
 	}
 
@@ -52,14 +52,17 @@ func TestPgCharts(t *testing.T) {
 	windows := charts.Windows
 
 	validate := func(tag string) {
-		_, err := cache.ValidateLengths(blocks.Time, blocks.PoolSize,
-			blocks.PoolValue, blocks.BlockSize, blocks.TxCount, blocks.NewAtoms,
-			blocks.Chainwork, blocks.Fees)
+		_, err := cache.ValidateLengths(blocks.Time,
+			blocks.Uints(cache.PoolSizeSeries), blocks.Uints(cache.PoolValueSeries),
+			blocks.Uints(cache.BlockSizeSeries), blocks.Uints(cache.TxCountSeries),
+			blocks.Uints(cache.NewAtomsSeries), blocks.Uints(cache.ChainworkSeries),
+			blocks.Uints(cache.FeesSeries))
 		if err != nil {
 			t.Fatalf("%s blocks length validation error: %v", tag, err)
 		}
-		_, err = cache.ValidateLengths(windows.TicketPrice, windows.PowDiff,
-			windows.Time, windows.StakeCount, windows.MissedVotes)
+		_, err = cache.ValidateLengths(windows.Uints(cache.TicketPriceSeries),
+			windows.Floats(cache.PowDiffSeries), windows.Time,
+			windows.Uints(cache.StakeCountSeries), windows.Uints(cache.MissedVotesSeries))
 		if err != nil {
 			t.Fatalf("%s windows length validation error: %v", tag, err)
 		}
@@ -58,9 +58,13 @@ func openDB() (func() error, error) {
 		DBName: dbconfig.PGTestsDBName, // dcrdata_testnet3 for treasury testing
 	}
 	cfg := &ChainDBCfg{
-		dbi,
-		chaincfg.MainNetParams(),
-		true, false, 24, 1024, 1 << 16,
+		DBi:                  dbi,
+		Params:               chaincfg.MainNetParams(),
+		DevPrefetch:          true,
+		HidePGConfig:         false,
+		AddrCacheRowCap:      24,
+		AddrCacheAddrCap:     1024,
+		AddrCacheUTXOByteCap: 1 << 16,
 	}
 	var err error
 	db, err = NewChainDB(context.Background(), cfg, nil, nil, nil, func() {})
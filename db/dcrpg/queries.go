@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/wire"
 
@@ -24,14 +25,13 @@ import (
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/txhelpers"
 	humanize "github.com/dustin/go-humanize"
-	"github.com/lib/pq"
 )
 
 // dbBestBlock retrieves the best block hash and height from the meta table. The
 // error value will never be sql.ErrNoRows; instead with height == -1 indicating
 // no data in the meta table.
-func dbBestBlock(ctx context.Context, db *sql.DB) (hash dbtypes.ChainHash, height int64, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectMetaDBBestBlock).Scan(&height, &hash)
+func (q queries) dbBestBlock(ctx context.Context, db *sql.DB) (hash dbtypes.ChainHash, height int64, err error) {
+	err = db.QueryRowContext(ctx, q.SelectMetaDBBestBlock).Scan(&height, &hash)
 	if err == sql.ErrNoRows {
 		err = nil
 		height = -1
@@ -40,8 +40,8 @@ func dbBestBlock(ctx context.Context, db *sql.DB) (hash dbtypes.ChainHash, heigh
 }
 
 // setDBBestBlock sets the best block hash and height in the meta table.
-func setDBBestBlock(db *sql.DB, hash dbtypes.ChainHash, height int64) error {
-	numRows, err := sqlExec(db, internal.SetMetaDBBestBlock,
+func (q queries) setDBBestBlock(db *sql.DB, hash dbtypes.ChainHash, height int64) error {
+	numRows, err := sqlExec(db, q.SetMetaDBBestBlock,
 		"failed to update best block in meta table: ", height, hash)
 	if err != nil {
 		return err
@@ -55,15 +55,15 @@ func setDBBestBlock(db *sql.DB, hash dbtypes.ChainHash, height int64) error {
 
 // ibdComplete indicates whether initial block download was completed according
 // to the meta.ibd_complete flag.
-func ibdComplete(db *sql.DB) (ibdComplete bool, err error) {
-	err = db.QueryRow(internal.SelectMetaDBIbdComplete).Scan(&ibdComplete)
+func (q queries) ibdComplete(db *sql.DB) (ibdComplete bool, err error) {
+	err = db.QueryRow(q.SelectMetaDBIbdComplete).Scan(&ibdComplete)
 	return
 }
 
 // setIBDComplete set the ibd_complete (Initial Block Download complete) flag in
 // the meta table.
-func setIBDComplete(db SqlExecutor, ibdComplete bool) error {
-	numRows, err := sqlExec(db, internal.SetMetaDBIbdComplete,
+func (q queries) setIBDComplete(db SqlExecutor, ibdComplete bool) error {
+	numRows, err := sqlExec(db, q.SetMetaDBIbdComplete,
 		"failed to update ibd_complete in meta table: ", ibdComplete)
 	if err != nil {
 		return err
@@ -259,7 +259,7 @@ func deleteDuplicateAgendaVotes(db *sql.DB) (int64, error) {
 
 // --- stake (votes, tickets, misses, treasury) tables ---
 
-func insertTreasuryTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords bool) error {
+func (q queries) insertTreasuryTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords bool) error {
 	dbtx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("unable to begin database transaction: %w", err)
@@ -267,7 +267,7 @@ func insertTreasuryTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistin
 
 	// Prepare treasury insert statement, optionally updating a row if it
 	// conflicts with the unique index on (tx_hash, block_hash).
-	stmt, err := dbtx.Prepare(internal.MakeTreasuryInsertStatement(checked, updateExistingRecords))
+	stmt, err := dbtx.Prepare(q.MakeTreasuryInsertStatement(checked, updateExistingRecords))
 	if err != nil {
 		log.Errorf("Ticket INSERT prepare: %v", err)
 		_ = dbtx.Rollback() // try, but we want the Prepare error back
@@ -312,7 +312,7 @@ func insertTreasuryTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistin
 // transactions, extracts the tickets, and inserts the tickets into the
 // database. Outputs are a slice of DB row IDs of the inserted tickets, and an
 // error.
-func insertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked, updateExistingRecords bool) ([]uint64, []*dbtypes.Tx, error) {
+func (q queries) insertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked, updateExistingRecords bool) ([]uint64, []*dbtypes.Tx, error) {
 	dbtx, err := db.Begin()
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to begin database transaction: %w", err)
@@ -320,7 +320,7 @@ func insertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked,
 
 	// Prepare ticket insert statement, optionally updating a row if it conflicts
 	// with the unique index on (tx_hash, block_hash).
-	stmt, err := dbtx.Prepare(internal.MakeTicketInsertStatement(checked, updateExistingRecords))
+	stmt, err := dbtx.Prepare(q.MakeTicketInsertStatement(checked, updateExistingRecords))
 	if err != nil {
 		log.Errorf("Ticket INSERT prepare: %v", err)
 		_ = dbtx.Rollback() // try, but we want the Prepare error back
@@ -406,7 +406,7 @@ func insertTickets(db *sql.DB, dbTxns []*dbtypes.Tx, txDbIDs []uint64, checked,
 // information and references to the agendas and votes tables.
 //
 // Outputs are slices of DB row IDs for the votes and misses, and an error.
-func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *TicketTxnIDGetter,
+func (q queries) insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *TicketTxnIDGetter,
 	msgBlock *MsgBlockPG, checked, updateExistingRecords bool, params *chaincfg.Params,
 	votesMilestones *dbtypes.BlockChainData) ([]uint64, []*dbtypes.Tx, []dbtypes.ChainHash,
 	[]uint64, map[dbtypes.ChainHash]uint64, error) {
@@ -441,7 +441,7 @@ func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 
 	// Prepare vote insert statement, optionally updating a row if it conflicts
 	// with the unique index on (tx_hash, block_hash).
-	voteInsert := internal.MakeVoteInsertStatement(checked, updateExistingRecords)
+	voteInsert := q.MakeVoteInsertStatement(checked, updateExistingRecords)
 	voteStmt, err := dbtx.Prepare(voteInsert)
 	if err != nil {
 		log.Errorf("Votes INSERT prepare: %v", err)
@@ -450,7 +450,7 @@ func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 	}
 
 	// Prepare agenda insert statement.
-	agendaStmt, err := dbtx.Prepare(internal.MakeAgendaInsertStatement(checked))
+	agendaStmt, err := dbtx.Prepare(q.MakeAgendaInsertStatement(checked))
 	if err != nil {
 		log.Errorf("Agendas INSERT prepare: %v", err)
 		_ = voteStmt.Close()
@@ -459,7 +459,7 @@ func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 	}
 
 	// Prepare agenda votes insert statement.
-	agendaVotesInsert := internal.MakeAgendaVotesInsertStatement(checked)
+	agendaVotesInsert := q.MakeAgendaVotesInsertStatement(checked)
 	agendaVotesStmt, err := dbtx.Prepare(agendaVotesInsert)
 	if err != nil {
 		log.Errorf("Agenda Votes INSERT prepare: %v", err)
@@ -487,7 +487,7 @@ func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 	if len(storedAgendas) == 0 {
 		var id int64
 		// Attempt to retrieve agendas from the database.
-		storedAgendas, err = retrieveAllAgendas(db)
+		storedAgendas, err = q.retrieveAllAgendas(db)
 		if err != nil {
 			bail()
 			return nil, nil, nil, nil, nil,
@@ -649,7 +649,7 @@ func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 	if len(misses) > 0 {
 		// Insert misses, optionally updating a row if it conflicts with the
 		// unique index on (ticket_hash, block_hash).
-		stmtMissed, err := dbtx.Prepare(internal.MakeMissInsertStatement(checked, updateExistingRecords))
+		stmtMissed, err := dbtx.Prepare(q.MakeMissInsertStatement(checked, updateExistingRecords))
 		if err != nil {
 			log.Errorf("Miss INSERT prepare: %v", err)
 			_ = dbtx.Rollback() // try, but we want the Prepare error back
@@ -684,9 +684,9 @@ func insertVotes(db *sql.DB, dbTxns []*dbtypes.Tx, _ /*txDbIDs*/ []uint64, fTx *
 
 // retrieveMissedVotesInBlock gets a list of ticket hashes that were called to
 // vote in the given block, but missed their vote.
-func retrieveMissedVotesInBlock(ctx context.Context, db *sql.DB, blockHash dbtypes.ChainHash) (ticketHashes []dbtypes.ChainHash, err error) {
+func (q queries) retrieveMissedVotesInBlock(ctx context.Context, db *sql.DB, blockHash dbtypes.ChainHash) (ticketHashes []dbtypes.ChainHash, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectMissesInBlock, blockHash)
+	rows, err = db.QueryContext(ctx, q.SelectMissesInBlock, blockHash)
 	if err != nil {
 		return nil, err
 	}
@@ -709,14 +709,113 @@ func retrieveMissedVotesInBlock(ctx context.Context, db *sql.DB, blockHash dbtyp
 
 // retrieveMissedVotesForBlockRange retrieves missed votes for the specified
 // block range.
-func retrieveMissedVotesForBlockRange(ctx context.Context, db *sql.DB, startHeight, endHeight int64) (missedVotes int64, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectMissCountForBlockRange, startHeight, endHeight).Scan(&missedVotes)
+func (q queries) retrieveMissedVotesForBlockRange(ctx context.Context, db *sql.DB, startHeight, endHeight int64) (missedVotes int64, err error) {
+	err = db.QueryRowContext(ctx, q.SelectMissCountForBlockRange, startHeight, endHeight).Scan(&missedVotes)
 	if err != nil {
 		return 0, err
 	}
 	return
 }
 
+// retrieveVSPTicketSpends counts the spent mainchain tickets whose votes or
+// revocations paid each of the fee addresses.
+func (q queries) retrieveVSPTicketSpends(ctx context.Context, db *sql.DB, feeAddrs []string) ([]*dbtypes.VSPAddressTickets, error) {
+	rows, err := db.QueryContext(ctx, q.SelectVSPTicketSpends, q.StringArray(feeAddrs))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var spends []*dbtypes.VSPAddressTickets
+	byAddr := make(map[string]*dbtypes.VSPAddressTickets)
+	for rows.Next() {
+		var addr string
+		var poolStatus dbtypes.TicketPoolStatus
+		var spendType dbtypes.TicketSpendType
+		var count, ageSum int64
+		if err = rows.Scan(&addr, &poolStatus, &spendType, &count, &ageSum); err != nil {
+			return nil, err
+		}
+		a, found := byAddr[addr]
+		if !found {
+			a = &dbtypes.VSPAddressTickets{Address: addr}
+			byAddr[addr] = a
+			spends = append(spends, a)
+		}
+		switch poolStatus {
+		case dbtypes.PoolStatusVoted:
+			a.Voted += count
+			a.VoteAgeSum += ageSum
+		case dbtypes.PoolStatusMissed:
+			a.Missed += count
+		case dbtypes.PoolStatusExpired:
+			a.Expired += count
+		}
+		if spendType == dbtypes.TicketRevoked {
+			a.Revoked += count
+		}
+	}
+	return spends, rows.Err()
+}
+
+// retrieveTicketSpendAddresses retrieves the mainchain tickets with the hashes,
+// with the fee address of feeAddrs paid by their votes or revocations. Tickets
+// that are not found are omitted.
+func (q queries) retrieveTicketSpendAddresses(ctx context.Context, db *sql.DB, tickets, feeAddrs []string) ([]*dbtypes.TicketSpendAddress, error) {
+	hashes := make([]dbtypes.ChainHash, 0, len(tickets))
+	for _, ticket := range tickets {
+		hash, err := chainhash.NewHashFromStr(ticket)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, dbtypes.ChainHash(*hash))
+	}
+	rows, err := db.QueryContext(ctx, q.SelectTicketSpendAddresses,
+		q.HashArray(hashes), q.StringArray(feeAddrs))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var spends []*dbtypes.TicketSpendAddress
+	for rows.Next() {
+		var hash dbtypes.ChainHash
+		var feeAddr sql.NullString
+		t := new(dbtypes.TicketSpendAddress)
+		err = rows.Scan(&hash, &t.BlockHeight, &t.Price, &t.PoolStatus, &t.SpendType, &feeAddr)
+		if err != nil {
+			return nil, err
+		}
+		t.Hash = hash.String()
+		t.FeeAddress = feeAddr.String
+		spends = append(spends, t)
+	}
+	return spends, rows.Err()
+}
+
+// retrieveMeanVoteAges retrieves the mean ages of the mainchain votes in a
+// range of blocks, in bins of binSize blocks by vote height. Bins without
+// votes are omitted.
+func (q queries) retrieveMeanVoteAges(ctx context.Context, db *sql.DB, start, end, binSize int64) ([]*dbtypes.VoteAgeBin, error) {
+	rows, err := db.QueryContext(ctx, q.SelectMeanVoteAges, start, end, binSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var bins []*dbtypes.VoteAgeBin
+	for rows.Next() {
+		var bin int64
+		b := new(dbtypes.VoteAgeBin)
+		if err = rows.Scan(&bin, &b.Votes, &b.MeanAge); err != nil {
+			return nil, err
+		}
+		b.Height = bin * binSize
+		bins = append(bins, b)
+	}
+	return bins, rows.Err()
+}
+
 // retrieveMissesForTicket gets all of the blocks in which the ticket was called
 // to place a vote on the previous block. The previous block that would have
 // been validated by the vote is not the block data that is returned.
@@ -750,15 +849,15 @@ func retrieveMissesForTicket(ctx context.Context, db *sql.DB, ticketHash string)
 // retrieveMissForTicket gets the mainchain block in which the ticket was called
 // to place a vote on the previous block. The previous block that would have
 // been validated by the vote is not the block data that is returned.
-func retrieveMissForTicket(ctx context.Context, db *sql.DB, ticketHash dbtypes.ChainHash) (blockHash dbtypes.ChainHash, blockHeight int64, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectMissesMainchainForTicket,
+func (q queries) retrieveMissForTicket(ctx context.Context, db *sql.DB, ticketHash dbtypes.ChainHash) (blockHash dbtypes.ChainHash, blockHeight int64, err error) {
+	err = db.QueryRowContext(ctx, q.SelectMissesMainchainForTicket,
 		ticketHash).Scan(&blockHeight, &blockHash)
 	return
 }
 
 // retrieveAllAgendas returns all the current agendas in the db.
-func retrieveAllAgendas(db *sql.DB) (map[string]dbtypes.MileStone, error) {
-	rows, err := db.Query(internal.SelectAllAgendas)
+func (q queries) retrieveAllAgendas(db *sql.DB) (map[string]dbtypes.MileStone, error) {
+	rows, err := db.Query(q.SelectAllAgendas)
 	if err != nil {
 		return nil, err
 	}
@@ -852,12 +951,12 @@ func retrieveAllVotesDbIDsHeightsTicketDbIDs(ctx context.Context, db *sql.DB) (i
 
 // retrieveWindowBlocks fetches chunks of windows using the limit and offset provided
 // for a window size of chaincfg.Params.StakeDiffWindowSize.
-func retrieveWindowBlocks(ctx context.Context, db *sql.DB, windowSize, currentHeight int64, limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error) {
+func (q queries) retrieveWindowBlocks(ctx context.Context, db *sql.DB, windowSize, currentHeight int64, limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error) {
 	endWindow := currentHeight/windowSize - int64(offset)
 	startWindow := endWindow - int64(limit) + 1
 	startHeight := startWindow * windowSize
 	endHeight := (endWindow+1)*windowSize - 1
-	rows, err := db.QueryContext(ctx, internal.SelectWindowsByLimit, windowSize, startHeight, endHeight)
+	rows, err := db.QueryContext(ctx, q.SelectWindowsByLimit, windowSize, startHeight, endHeight)
 	if err != nil {
 		return nil, fmt.Errorf("retrieveWindowBlocks failed: error: %w", err)
 	}
@@ -905,9 +1004,9 @@ func retrieveWindowBlocks(ctx context.Context, db *sql.DB, windowSize, currentHe
 // retrieveTimeBasedBlockListing fetches blocks in chunks based on their block
 // time using the limit and offset provided. The time-based blocks groupings
 // include but are not limited to day, week, month and year.
-func retrieveTimeBasedBlockListing(ctx context.Context, db *sql.DB, timeInterval string,
+func (q queries) retrieveTimeBasedBlockListing(ctx context.Context, db *sql.DB, timeInterval string,
 	limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectBlocksTimeListingByLimit, timeInterval,
+	rows, err := db.QueryContext(ctx, q.MakeSelectBlocksTimeListingByLimit(timeInterval),
 		limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("retrieveTimeBasedBlockListing failed: error: %w", err)
@@ -950,9 +1049,9 @@ func retrieveTimeBasedBlockListing(ctx context.Context, db *sql.DB, timeInterval
 }
 
 // retrieveUnspentTickets gets all unspent tickets.
-func retrieveUnspentTickets(ctx context.Context, db *sql.DB) (ids []uint64, hashes []dbtypes.ChainHash, err error) {
+func (q queries) retrieveUnspentTickets(ctx context.Context, db *sql.DB) (ids []uint64, hashes []dbtypes.ChainHash, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectUnspentTickets)
+	rows, err = db.QueryContext(ctx, q.SelectUnspentTickets)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -979,28 +1078,28 @@ func retrieveUnspentTickets(ctx context.Context, db *sql.DB) (ids []uint64, hash
 // retrieveTicketIDByHashNoCancel gets the db row ID (primary key) in the
 // tickets table for the given ticket hash. As the name implies, this query
 // should not accept a cancelable context.
-func retrieveTicketIDByHashNoCancel(db *sql.DB, ticketHash dbtypes.ChainHash) (id uint64, err error) {
-	err = db.QueryRow(internal.SelectTicketIDByHash, ticketHash).Scan(&id)
+func (q queries) retrieveTicketIDByHashNoCancel(db *sql.DB, ticketHash dbtypes.ChainHash) (id uint64, err error) {
+	err = db.QueryRow(q.SelectTicketIDByHash, ticketHash).Scan(&id)
 	return
 }
 
 // retrieveTicketStatusByHash gets the spend status and ticket pool status for
 // the given ticket hash.
-func retrieveTicketStatusByHash(ctx context.Context, db *sql.DB, ticketHash dbtypes.ChainHash) (id uint64,
+func (q queries) retrieveTicketStatusByHash(ctx context.Context, db *sql.DB, ticketHash dbtypes.ChainHash) (id uint64,
 	spendStatus dbtypes.TicketSpendType, poolStatus dbtypes.TicketPoolStatus, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectTicketStatusByHash, ticketHash).
+	err = db.QueryRowContext(ctx, q.SelectTicketStatusByHash, ticketHash).
 		Scan(&id, &spendStatus, &poolStatus)
 	return
 }
 
 // retrieveTicketInfoByHash retrieves the ticket spend and pool statuses as well
 // as the purchase and spending block info and spending txid.
-func retrieveTicketInfoByHash(ctx context.Context, db *sql.DB, ticketHash dbtypes.ChainHash) (spendStatus dbtypes.TicketSpendType,
+func (q queries) retrieveTicketInfoByHash(ctx context.Context, db *sql.DB, ticketHash dbtypes.ChainHash) (spendStatus dbtypes.TicketSpendType,
 	poolStatus dbtypes.TicketPoolStatus, purchaseBlock, lotteryBlock *apitypes.TinyBlock, spendTxid dbtypes.ChainHash, err error) {
 	var dbid sql.NullInt64
 	var purchaseHash, spendHash dbtypes.ChainHash
 	var purchaseHeight, spendHeight uint32
-	err = db.QueryRowContext(ctx, internal.SelectTicketInfoByHash, ticketHash).
+	err = db.QueryRowContext(ctx, q.SelectTicketInfoByHash, ticketHash).
 		Scan(&purchaseHash, &purchaseHeight, &spendStatus, &poolStatus, &dbid)
 	if err != nil {
 		return
@@ -1020,7 +1119,7 @@ func retrieveTicketInfoByHash(ctx context.Context, db *sql.DB, ticketHash dbtype
 		return
 	}
 
-	err = db.QueryRowContext(ctx, internal.SelectTxnByDbID, dbid.Int64).
+	err = db.QueryRowContext(ctx, q.SelectTxnByDbID, dbid.Int64).
 		Scan(&spendHash, &spendHeight, &spendTxid)
 
 	if err != nil {
@@ -1082,8 +1181,8 @@ func retrieveTicketIDsByHashes(ctx context.Context, db *sql.DB, ticketHashes []s
 // purchase date. The maturity block is needed to identify immature tickets.
 // The grouping is done using the time-based group names provided e.g. months,
 // days, weeks and years.
-func retrieveTicketsByDate(ctx context.Context, db *sql.DB, maturityBlock int64, groupBy string) (*dbtypes.PoolTicketsData, error) {
-	rows, err := db.QueryContext(ctx, internal.MakeSelectTicketsByPurchaseDate(groupBy), maturityBlock)
+func (q queries) retrieveTicketsByDate(ctx context.Context, db *sql.DB, maturityBlock int64, groupBy string) (*dbtypes.PoolTicketsData, error) {
+	rows, err := db.QueryContext(ctx, q.MakeSelectTicketsByPurchaseDate(groupBy), maturityBlock)
 	if err != nil {
 		return nil, err
 	}
@@ -1092,14 +1191,14 @@ func retrieveTicketsByDate(ctx context.Context, db *sql.DB, maturityBlock int64,
 	tickets := new(dbtypes.PoolTicketsData)
 	for rows.Next() {
 		var immature, live uint64
-		var timestamp time.Time
+		var timestamp dbtypes.TimeDef // UNIX time stamp
 		var price float64
 		err = rows.Scan(&timestamp, &price, &immature, &live)
 		if err != nil {
 			return nil, fmt.Errorf("retrieveTicketsByDate: %w", err)
 		}
 
-		tickets.Time = append(tickets.Time, dbtypes.NewTimeDef(timestamp))
+		tickets.Time = append(tickets.Time, timestamp)
 		tickets.Immature = append(tickets.Immature, immature)
 		tickets.Live = append(tickets.Live, live)
 
@@ -1118,9 +1217,9 @@ func retrieveTicketsByDate(ctx context.Context, db *sql.DB, maturityBlock int64,
 // purchase price. The maturity block is needed to identify immature tickets.
 // The grouping is done using the time-based group names provided e.g. months,
 // days, weeks and years.
-func retrieveTicketByPrice(ctx context.Context, db *sql.DB, maturityBlock int64) (*dbtypes.PoolTicketsData, error) {
+func (q queries) retrieveTicketByPrice(ctx context.Context, db *sql.DB, maturityBlock int64) (*dbtypes.PoolTicketsData, error) {
 	// Create the query statement and retrieve rows
-	rows, err := db.QueryContext(ctx, internal.SelectTicketsByPrice, maturityBlock)
+	rows, err := db.QueryContext(ctx, q.SelectTicketsByPrice, maturityBlock)
 	if err != nil {
 		return nil, err
 	}
@@ -1150,8 +1249,8 @@ func retrieveTicketByPrice(ctx context.Context, db *sql.DB, maturityBlock int64)
 // ticketpool grouped by ticket type (inferred by their output counts). The
 // grouping used here i.e. solo, pooled and tixsplit is just a guessing based on
 // commonly structured ticket purchases.
-func retrieveTicketsGroupedByType(ctx context.Context, db *sql.DB) (*dbtypes.PoolTicketsData, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectTicketsByType)
+func (q queries) retrieveTicketsGroupedByType(ctx context.Context, db *sql.DB) (*dbtypes.PoolTicketsData, error) {
+	rows, err := db.QueryContext(ctx, q.SelectTicketsByType)
 	if err != nil {
 		return nil, err
 	}
@@ -1180,7 +1279,7 @@ func retrieveTicketsGroupedByType(ctx context.Context, db *sql.DB) (*dbtypes.Poo
 
 // setPoolStatusForTickets sets the ticket pool status for the tickets specified
 // by db row ID.
-func setPoolStatusForTickets(db *sql.DB, ticketDbIDs []uint64, poolStatuses []dbtypes.TicketPoolStatus) (int64, error) {
+func (q queries) setPoolStatusForTickets(db *sql.DB, ticketDbIDs []uint64, poolStatuses []dbtypes.TicketPoolStatus) (int64, error) {
 	if len(ticketDbIDs) == 0 {
 		return 0, nil
 	}
@@ -1190,7 +1289,7 @@ func setPoolStatusForTickets(db *sql.DB, ticketDbIDs []uint64, poolStatuses []db
 	}
 
 	var stmt *sql.Stmt
-	stmt, err = dbtx.Prepare(internal.SetTicketPoolStatusForTicketDbID)
+	stmt, err = dbtx.Prepare(q.SetTicketPoolStatusForTicketDbID)
 	if err != nil {
 		// Already up a creek. Just return error from Prepare.
 		_ = dbtx.Rollback()
@@ -1265,7 +1364,7 @@ func SetPoolStatusForTicketsByHash(db *sql.DB, tickets []string,
 // setSpendingForTickets sets the spend type, spend height, spending transaction
 // row IDs (in the table relevant to the spend type), and ticket pool status for
 // the given tickets specified by their db row IDs.
-func setSpendingForTickets(db *sql.DB, ticketDbIDs, spendDbIDs []uint64,
+func (q queries) setSpendingForTickets(db *sql.DB, ticketDbIDs, spendDbIDs []uint64,
 	blockHeights []int64, spendTypes []dbtypes.TicketSpendType,
 	poolStatuses []dbtypes.TicketPoolStatus) (int64, error) {
 	dbtx, err := db.Begin()
@@ -1274,7 +1373,7 @@ func setSpendingForTickets(db *sql.DB, ticketDbIDs, spendDbIDs []uint64,
 	}
 
 	var stmt *sql.Stmt
-	stmt, err = dbtx.Prepare(internal.SetTicketSpendingInfoForTicketDbID)
+	stmt, err = dbtx.Prepare(q.SetTicketSpendingInfoForTicketDbID)
 	if err != nil {
 		// Already up a creek. Just return error from Prepare.
 		_ = dbtx.Rollback()
@@ -1320,9 +1419,9 @@ func InsertAddressRow(db *sql.DB, dbA *dbtypes.AddressRow, dupCheck, updateExist
 // insertAddressRowsDbTx is like InsertAddressRows, except that it takes a
 // sql.Tx. The caller is required to Commit or Rollback the transaction
 // depending on the returned error value.
-func insertAddressRowsDbTx(dbTx *sql.Tx, dbAs []*dbtypes.AddressRow, dupCheck, updateExistingRecords bool) ([]uint64, error) {
+func (q queries) insertAddressRowsDbTx(dbTx *sql.Tx, dbAs []*dbtypes.AddressRow, dupCheck, updateExistingRecords, partitioned bool) ([]uint64, error) {
 	// Prepare the addresses row insert statement.
-	stmt, err := dbTx.Prepare(internal.MakeAddressRowInsertStatement(dupCheck, updateExistingRecords))
+	stmt, err := dbTx.Prepare(q.MakeAddressRowInsertStatement(dupCheck, updateExistingRecords, partitioned))
 	if err != nil {
 		return nil, err
 	}
@@ -1394,7 +1493,7 @@ func retrieveAddressSpent(ctx context.Context, db *sql.DB, address string) (coun
 // for the given address, the total amounts spent and unspent, the number of
 // distinct spending transactions, and the fraction spent to and received from
 // stake-related transactions.
-func retrieveAddressBalance(ctx context.Context, db *sql.DB, address string) (balance *dbtypes.AddressBalance, err error) {
+func (q queries) retrieveAddressBalance(ctx context.Context, db *sql.DB, address string) (balance *dbtypes.AddressBalance, err error) {
 	// Never return nil *AddressBalance.
 	balance = &dbtypes.AddressBalance{Address: address}
 
@@ -1411,7 +1510,7 @@ func retrieveAddressBalance(ctx context.Context, db *sql.DB, address string) (ba
 
 	// Query for spent and unspent totals.
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectAddressSpentUnspentCountAndValue, address)
+	rows, err = db.QueryContext(ctx, q.SelectAddressSpentUnspentCountAndValue, address)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			_ = dbtx.Commit()
@@ -1471,16 +1570,16 @@ func retrieveAddressBalance(ctx context.Context, db *sql.DB, address string) (ba
 	return
 }
 
-func countMergedSpendingTxns(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
-	return countMerged(ctx, db, address, internal.SelectAddressesMergedSpentCount)
+func (q queries) countMergedSpendingTxns(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
+	return countMerged(ctx, db, address, q.SelectAddressesMergedSpentCount)
 }
 
-func countMergedFundingTxns(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
-	return countMerged(ctx, db, address, internal.SelectAddressesMergedFundingCount)
+func (q queries) countMergedFundingTxns(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
+	return countMerged(ctx, db, address, q.SelectAddressesMergedFundingCount)
 }
 
-func countMergedTxns(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
-	return countMerged(ctx, db, address, internal.SelectAddressesMergedCount)
+func (q queries) countMergedTxns(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
+	return countMerged(ctx, db, address, q.SelectAddressesMergedCount)
 }
 
 func countMerged(ctx context.Context, db *sql.DB, address, query string) (count int64, err error) {
@@ -1563,8 +1662,8 @@ func retrieveAddressUTXOs(ctx context.Context, db *sql.DB, address string, curre
 // retrieveAddressDbUTXOs gets the unspent transaction outputs (UTXOs) paying to
 // the specified address as a []*dbtypes.AddressTxnOutput. The input current
 // block height is used to compute confirmations of the located transactions.
-func retrieveAddressDbUTXOs(ctx context.Context, db *sql.DB, address string) ([]*dbtypes.AddressTxnOutput, error) {
-	stmt, err := db.Prepare(internal.SelectAddressUnspentWithTxn)
+func (q queries) retrieveAddressDbUTXOs(ctx context.Context, db *sql.DB, address string) ([]*dbtypes.AddressTxnOutput, error) {
+	stmt, err := db.Prepare(q.SelectAddressUnspentWithTxn)
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -1690,16 +1789,16 @@ func retrieveAllAddressMergedTxns(ctx context.Context, db *sql.DB, address strin
 
 // Regular (non-merged) address transactions queries.
 
-func retrieveAddressTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]*dbtypes.AddressRow, error) {
+func (q queries) retrieveAddressTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]*dbtypes.AddressRow, error) {
 	return retrieveAddressTxnsStmt(ctx, db, address, N, offset,
-		internal.SelectAddressLimitNByAddress, creditDebitQuery)
+		q.SelectAddressLimitNByAddress, creditDebitQuery)
 }
 
 // Merged address transactions queries.
 
-func retrieveAddressMergedTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]*dbtypes.AddressRow, error) {
+func (q queries) retrieveAddressMergedTxns(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]*dbtypes.AddressRow, error) {
 	return retrieveAddressTxnsStmt(ctx, db, address, N, offset,
-		internal.SelectAddressMergedView, mergedQuery)
+		q.SelectAddressMergedView, mergedQuery)
 }
 
 // Address transaction query helpers.
@@ -1808,11 +1907,11 @@ func scanAddressQueryRows(rows *sql.Rows, queryType int) (addressRows []*dbtypes
 
 // retrieveAddressIDsByOutpoint gets all address row IDs, addresses, and values
 // for a given outpoint.
-func retrieveAddressIDsByOutpoint(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash, voutIndex uint32) ([]uint64, []string, int64, error) {
+func (q queries) retrieveAddressIDsByOutpoint(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash, voutIndex uint32) ([]uint64, []string, int64, error) {
 	var ids []uint64
 	var addresses []string
 	var value int64
-	rows, err := db.QueryContext(ctx, internal.SelectAddressIDsByFundingOutpoint, txHash, voutIndex)
+	rows, err := db.QueryContext(ctx, q.SelectAddressIDsByFundingOutpoint, txHash, voutIndex)
 	if err != nil {
 		return nil, nil, 0, err
 	}
@@ -1849,8 +1948,8 @@ func retrieveAddressIDsByOutpoint(ctx context.Context, db *sql.DB, txHash dbtype
 // The time interval is grouping records by week, month, year, day and all.
 // For all time interval, transactions are grouped by the unique
 // timestamps (blocks) available.
-func retrieveTxHistoryByType(ctx context.Context, db *sql.DB, addr, timeInterval string) (*dbtypes.ChartsData, error) {
-	rows, err := db.QueryContext(ctx, internal.MakeSelectAddressTxTypesByAddress(timeInterval),
+func (q queries) retrieveTxHistoryByType(ctx context.Context, db *sql.DB, addr, timeInterval string) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, q.MakeSelectAddressTxTypesByAddress(timeInterval),
 		addr)
 	if err != nil {
 		return nil, err
@@ -1859,7 +1958,7 @@ func retrieveTxHistoryByType(ctx context.Context, db *sql.DB, addr, timeInterval
 
 	items := new(dbtypes.ChartsData)
 	for rows.Next() {
-		var blockTime time.Time
+		var blockTime dbtypes.TimeDef // UNIX time stamp
 		var tickets, votes, revokeTx uint32
 		var sentRtx, receivedRtx uint64
 		err = rows.Scan(&blockTime, &sentRtx, &receivedRtx, &tickets, &votes, &revokeTx)
@@ -1867,7 +1966,7 @@ func retrieveTxHistoryByType(ctx context.Context, db *sql.DB, addr, timeInterval
 			return nil, err
 		}
 
-		items.Time = append(items.Time, dbtypes.NewTimeDef(blockTime))
+		items.Time = append(items.Time, blockTime)
 		items.SentRtx = append(items.SentRtx, sentRtx)
 		items.ReceivedRtx = append(items.ReceivedRtx, receivedRtx)
 		items.Tickets = append(items.Tickets, tickets)
@@ -1886,8 +1985,8 @@ func retrieveTxHistoryByType(ctx context.Context, db *sql.DB, addr, timeInterval
 // the given time interval. The time interval is grouping records by week,
 // month, year, day and all. For all time interval, transactions are grouped by
 // the unique timestamps (blocks) available.
-func retrieveTxHistoryByAmountFlow(ctx context.Context, db *sql.DB, addr, timeInterval string) (*dbtypes.ChartsData, error) {
-	rows, err := db.QueryContext(ctx, internal.MakeSelectAddressAmountFlowByAddress(timeInterval), addr)
+func (q queries) retrieveTxHistoryByAmountFlow(ctx context.Context, db *sql.DB, addr, timeInterval string) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, q.MakeSelectAddressAmountFlowByAddress(timeInterval), addr)
 	if err != nil {
 		return nil, err
 	}
@@ -2021,7 +2120,9 @@ func (al addressList) Value() (driver.Value, error) {
 		return al[0], nil
 	}
 
-	return pq.StringArray(al).Value()
+	// The {a,b} array text format of a TEXT[] column. Decred addresses never
+	// need quoting.
+	return "{" + strings.Join(al, ",") + "}", nil
 }
 
 func (al *addressList) Scan(src interface{}) error {
@@ -2151,15 +2252,15 @@ func retrieveVoutIDByOutpoint(ctx context.Context, db *sql.DB, txHash string, vo
 */
 
 // TEST ONLY REMOVE
-func retrieveVoutValue(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash, voutIndex uint32) (value uint64, err error) {
-	err = db.QueryRowContext(ctx, internal.RetrieveVoutValue, txHash, voutIndex).Scan(&value)
+func (q queries) retrieveVoutValue(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash, voutIndex uint32) (value uint64, err error) {
+	err = db.QueryRowContext(ctx, q.RetrieveVoutValue, txHash, voutIndex).Scan(&value)
 	return
 }
 
 // TEST ONLY REMOVE
-func retrieveVoutValues(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (values []uint64, txInds []uint32, txTrees []int8, err error) {
+func (q queries) retrieveVoutValues(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (values []uint64, txInds []uint32, txTrees []int8, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.RetrieveVoutValues, txHash)
+	rows, err = db.QueryContext(ctx, q.RetrieveVoutValues, txHash)
 	if err != nil {
 		return
 	}
@@ -2234,8 +2335,8 @@ func retrieveFundingOutpointByVinID(ctx context.Context, db *sql.DB, vinDbID uin
 // retrieveFundingOutpointIndxByVinID gets the transaction output index of the
 // previous outpoint for a transaction input specified by row ID in the vins
 // table.
-func retrieveFundingOutpointIndxByVinID(ctx context.Context, db *sql.DB, vinDbID uint64) (idx uint32, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectFundingOutpointIndxByVinID, vinDbID).Scan(&idx)
+func (q queries) retrieveFundingOutpointIndxByVinID(ctx context.Context, db *sql.DB, vinDbID uint64) (idx uint32, err error) {
+	err = db.QueryRowContext(ctx, q.SelectFundingOutpointIndxByVinID, vinDbID).Scan(&idx)
 	return
 }
 
@@ -2274,9 +2375,9 @@ func retrieveSpendingTxByVinID(ctx context.Context, db *sql.DB, vinDbID uint64)
 // previous outpoint specified by funding transaction hash and vout number. This
 // function is called by SpendingTransaction, an important part of the address
 // page loading.
-func retrieveSpendingTxByTxOut(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash,
+func (q queries) retrieveSpendingTxByTxOut(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash,
 	voutIndex uint32) (id uint64, tx dbtypes.ChainHash, vin uint32, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectSpendingTxByPrevOut,
+	err = db.QueryRowContext(ctx, q.SelectSpendingTxByPrevOut,
 		txHash, voutIndex).Scan(&id, &tx, &vin)
 	return
 }
@@ -2285,10 +2386,10 @@ func retrieveSpendingTxByTxOut(ctx context.Context, db *sql.DB, txHash dbtypes.C
 // for the given funding transaction specified by DB row ID. This function is
 // called by SpendingTransactions, an important part of the transaction page
 // loading, among other functions..
-func retrieveSpendingTxsByFundingTx(ctx context.Context, db *sql.DB, fundingTxID dbtypes.ChainHash) (dbIDs []uint64,
+func (q queries) retrieveSpendingTxsByFundingTx(ctx context.Context, db *sql.DB, fundingTxID dbtypes.ChainHash) (dbIDs []uint64,
 	txns []dbtypes.ChainHash, vinInds []uint32, voutInds []uint32, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectSpendingTxsByPrevTx, fundingTxID)
+	rows, err = db.QueryContext(ctx, q.SelectSpendingTxsByPrevTx, fundingTxID)
 	if err != nil {
 		return
 	}
@@ -2316,9 +2417,9 @@ func retrieveSpendingTxsByFundingTx(ctx context.Context, db *sql.DB, fundingTxID
 // retrieveSpendingTxsByFundingTxWithBlockHeight will retrieve all transactions,
 // indexes and block heights funded by a specific transaction. This function is
 // used by the DCR to Insight transaction converter.
-func retrieveSpendingTxsByFundingTxWithBlockHeight(ctx context.Context, db *sql.DB, fundingTxID dbtypes.ChainHash) (aSpendByFunHash []*apitypes.SpendByFundingHash, err error) {
+func (q queries) retrieveSpendingTxsByFundingTxWithBlockHeight(ctx context.Context, db *sql.DB, fundingTxID dbtypes.ChainHash) (aSpendByFunHash []*apitypes.SpendByFundingHash, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectSpendingTxsByPrevTxWithBlockHeight, fundingTxID)
+	rows, err = db.QueryContext(ctx, q.SelectSpendingTxsByPrevTxWithBlockHeight, fundingTxID)
 	if err != nil {
 		return
 	}
@@ -2356,11 +2457,11 @@ func retrieveVinByID(ctx context.Context, db *sql.DB, vinDbID uint64) (prevOutHa
 // retrieveVinsByIDs retrieves vin details for the rows of the vins table
 // specified by the provided row IDs. This function is an important part of the
 // transaction page.
-func retrieveVinsByIDs(ctx context.Context, db *sql.DB, vinDbIDs []uint64) ([]dbtypes.VinTxProperty, error) {
+func (q queries) retrieveVinsByIDs(ctx context.Context, db *sql.DB, vinDbIDs []uint64) ([]dbtypes.VinTxProperty, error) {
 	vins := make([]dbtypes.VinTxProperty, len(vinDbIDs))
 	for i, id := range vinDbIDs {
 		vin := &vins[i]
-		err := db.QueryRowContext(ctx, internal.SelectAllVinInfoByID, id).Scan(&vin.TxID,
+		err := db.QueryRowContext(ctx, q.SelectAllVinInfoByID, id).Scan(&vin.TxID,
 			&vin.TxIndex, &vin.TxTree, &vin.IsValid, &vin.IsMainchain,
 			&vin.Time, &vin.PrevTxHash, &vin.PrevTxIndex, &vin.PrevTxTree,
 			&vin.ValueIn, &vin.TxType)
@@ -2374,7 +2475,7 @@ func retrieveVinsByIDs(ctx context.Context, db *sql.DB, vinDbIDs []uint64) ([]db
 // retrieveVoutsByIDs retrieves vout details for the rows of the vouts table
 // specified by the provided row IDs. This function is an important part of the
 // transaction page.
-func retrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]dbtypes.Vout, error) {
+func (q queries) retrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]dbtypes.Vout, error) {
 	vouts := make([]dbtypes.Vout, len(voutDbIDs))
 	for i, id := range voutDbIDs {
 		vout := &vouts[i]
@@ -2383,7 +2484,7 @@ func retrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]
 		// var reqSigs uint32
 		var addresses addressList
 		var scriptClass dbtypes.ScriptClass // or scan a string and then dbtypes.NewScriptClassFromString(scriptTypeString)
-		err := db.QueryRowContext(ctx, internal.SelectVoutByID, id).Scan(&id0, &vout.TxHash,
+		err := db.QueryRowContext(ctx, q.SelectVoutByID, id).Scan(&id0, &vout.TxHash,
 			&vout.TxIndex, &vout.TxTree, &vout.Value, &vout.Version,
 			/* &vout.ScriptPubKey, &reqSigs, */ &scriptClass, &addresses, &vout.Mixed, &spendTxRowID)
 		if err != nil {
@@ -2410,13 +2511,13 @@ func retrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]
 // }
 
 // retrieveUTXOs gets the entire UTXO set from the vouts and vins tables.
-func retrieveUTXOs(ctx context.Context, db *sql.DB) ([]dbtypes.UTXO, error) {
-	return retrieveUTXOsStmt(ctx, db, internal.SelectUTXOs)
+func (q queries) retrieveUTXOs(ctx context.Context, db *sql.DB) ([]dbtypes.UTXO, error) {
+	return q.retrieveUTXOsStmt(ctx, db, q.SelectUTXOs)
 }
 
 // retrieveUTXOsStmt gets the entire UTXO set from the vouts and vins tables.
-func retrieveUTXOsStmt(ctx context.Context, db *sql.DB, stmt string) ([]dbtypes.UTXO, error) {
-	_, height, err := dbBestBlock(ctx, db)
+func (q queries) retrieveUTXOsStmt(ctx context.Context, db *sql.DB, stmt string) ([]dbtypes.UTXO, error) {
+	_, height, err := q.dbBestBlock(ctx, db)
 	if err != nil {
 		return nil, err
 	}
@@ -2572,11 +2673,11 @@ func SetSpendingForVinDbID(db *sql.DB, vinDbID uint64) (int64, error) {
 // forMainchain=false also permits updating rows that are stake invalidated, but
 // consensus-validated transactions cannot spend outputs from stake-invalidated
 // transactions so the funding tx must not be invalid.
-func setSpendingForFundingOP(db SqlExecutor, fundingTxHash dbtypes.ChainHash, fundingTxVoutIndex uint32,
+func (q queries) setSpendingForFundingOP(db SqlExecutor, fundingTxHash dbtypes.ChainHash, fundingTxVoutIndex uint32,
 	spendingTxHash dbtypes.ChainHash, forMainchain bool) (int64, error) {
 	// Update the matchingTxHash for the funding tx output. matchingTxHash here
 	// is the hash of the funding tx.
-	res, err := db.Exec(internal.SetAddressMatchingTxHashForOutpoint,
+	res, err := db.Exec(q.SetAddressMatchingTxHashForOutpoint,
 		spendingTxHash, fundingTxHash, fundingTxVoutIndex, forMainchain)
 	if err != nil || res == nil {
 		return 0, fmt.Errorf("SetAddressMatchingTxHashForOutpoint: %w", err)
@@ -2585,8 +2686,8 @@ func setSpendingForFundingOP(db SqlExecutor, fundingTxHash dbtypes.ChainHash, fu
 	return res.RowsAffected()
 }
 
-func setSpendingForVout(tx *sql.Tx, fundVoutRowID int64, spendTxRowID uint64) error {
-	res, err := tx.Exec(internal.UpdateVoutSpendTxRowID, spendTxRowID, fundVoutRowID)
+func (q queries) setSpendingForVout(tx *sql.Tx, fundVoutRowID int64, spendTxRowID uint64) error {
+	res, err := tx.Exec(q.UpdateVoutSpendTxRowID, spendTxRowID, fundVoutRowID)
 	if err != nil || res == nil {
 		return err
 	}
@@ -2601,12 +2702,12 @@ func setSpendingForVout(tx *sql.Tx, fundVoutRowID int64, spendTxRowID uint64) er
 	return nil
 }
 
-func setSpendingForVouts(tx *sql.Tx, fundVoutRowIDs []int64, spendTxRowID uint64) error {
+func (q queries) setSpendingForVouts(tx *sql.Tx, fundVoutRowIDs []int64, spendTxRowID uint64) error {
 	if len(fundVoutRowIDs) == 1 {
-		return setSpendingForVout(tx, fundVoutRowIDs[0], spendTxRowID)
+		return q.setSpendingForVout(tx, fundVoutRowIDs[0], spendTxRowID)
 	}
 
-	res, err := tx.Exec(internal.UpdateVoutsSpendTxRowID, spendTxRowID, pq.Int64Array(fundVoutRowIDs))
+	res, err := tx.Exec(q.UpdateVoutsSpendTxRowID, spendTxRowID, q.Int64Array(fundVoutRowIDs))
 	if err != nil || res == nil {
 		return err
 	}
@@ -2621,8 +2722,8 @@ func setSpendingForVouts(tx *sql.Tx, fundVoutRowIDs []int64, spendTxRowID uint64
 	return nil
 }
 
-func resetSpendingForVoutsByTxRowID(tx *sql.Tx, spendingTxRowIDs []int64) (int64, error) {
-	res, err := tx.Exec(internal.ResetVoutSpendTxRowIDs, pq.Int64Array(spendingTxRowIDs))
+func (q queries) resetSpendingForVoutsByTxRowID(tx *sql.Tx, spendingTxRowIDs []int64) (int64, error) {
+	res, err := tx.Exec(q.ResetVoutSpendTxRowIDs, q.Int64Array(spendingTxRowIDs))
 	if err != nil || res == nil {
 		return 0, err
 	}
@@ -2655,10 +2756,10 @@ func InsertSpendingAddressRow(db *sql.DB, fundingTxHash string, fundingTxVoutInd
 }
 */
 
-func retrieveTxOutData(tx SqlQueryer, txid dbtypes.ChainHash, idx uint32, tree int8) (*dbtypes.UTXOData, error) {
+func (q queries) retrieveTxOutData(tx SqlQueryer, txid dbtypes.ChainHash, idx uint32, tree int8) (*dbtypes.UTXOData, error) {
 	var data dbtypes.UTXOData
 	var addrArray string
-	err := tx.QueryRow(internal.SelectVoutAddressesByTxOut, txid, idx, tree).
+	err := tx.QueryRow(q.SelectVoutAddressesByTxOut, txid, idx, tree).
 		Scan(&data.VoutDbID, &addrArray, &data.Value, &data.Mixed)
 	if err != nil {
 		return nil, fmt.Errorf("SelectVoutAddressesByTxOut: %w", err)
@@ -2675,9 +2776,9 @@ func retrieveTxOutData(tx SqlQueryer, txid dbtypes.ChainHash, idx uint32, tree i
 // insertSpendingAddressRow inserts a new row in the addresses table for a new
 // transaction input, and updates the spending information for the addresses
 // table row and vouts table row corresponding to the previous outpoint.
-func insertSpendingAddressRow(tx *sql.Tx, fundingTxHash dbtypes.ChainHash, fundingTxVoutIndex uint32,
+func (q queries) insertSpendingAddressRow(tx *sql.Tx, fundingTxHash dbtypes.ChainHash, fundingTxVoutIndex uint32,
 	fundingTxTree int8, spendingTxHash dbtypes.ChainHash, spendingTxVinIndex uint32, vinDbID uint64,
-	spentUtxoData *dbtypes.UTXOData, checked, updateExisting, mainchain, valid bool, txType int16,
+	spentUtxoData *dbtypes.UTXOData, checked, updateExisting, partitioned, mainchain, valid bool, txType int16,
 	updateFundingRow bool, blockT ...dbtypes.TimeDef) ([]string, int64, int64, bool, error) {
 
 	// Select addresses and value from the matching funding tx output. A maximum
@@ -2690,7 +2791,7 @@ func insertSpendingAddressRow(tx *sql.Tx, fundingTxHash dbtypes.ChainHash, fundi
 	// for the addresses, value, and mixed status.
 	if spentUtxoData == nil {
 		var err error
-		spentUtxoData, err = retrieveTxOutData(tx, fundingTxHash, fundingTxVoutIndex, fundingTxTree)
+		spentUtxoData, err = q.retrieveTxOutData(tx, fundingTxHash, fundingTxVoutIndex, fundingTxTree)
 		if err != nil {
 			if !errors.Is(err, sql.ErrNoRows) {
 				return nil, 0, 0, false, err
@@ -2712,14 +2813,14 @@ func insertSpendingAddressRow(tx *sql.Tx, fundingTxHash dbtypes.ChainHash, fundi
 		blockTime = blockT[0]
 	} else {
 		// Fetch the block time from the tx table.
-		err := tx.QueryRow(internal.SelectTxBlockTimeByHash, spendingTxHash).Scan(&blockTime)
+		err := tx.QueryRow(q.SelectTxBlockTimeByHash, spendingTxHash).Scan(&blockTime)
 		if err != nil {
 			return nil, 0, 0, mixed, fmt.Errorf("SelectTxBlockTimeByHash: %w", err)
 		}
 	}
 
 	// Insert the addresses table row(s) for the spending tx.
-	sqlStmt := internal.MakeAddressRowInsertStatement(checked, updateExisting)
+	sqlStmt := q.MakeAddressRowInsertStatement(checked, updateExisting, partitioned)
 	for i := range addrs {
 		var isFunding bool // spending
 		var rowID uint64
@@ -2736,7 +2837,7 @@ func insertSpendingAddressRow(tx *sql.Tx, fundingTxHash dbtypes.ChainHash, fundi
 		// the spending transaction is side chain, so must be the funding tx to
 		// update it. (Similarly for mainchain, but a mainchain block always has
 		// a parent on the main chain).
-		N, err := setSpendingForFundingOP(tx, fundingTxHash, fundingTxVoutIndex,
+		N, err := q.setSpendingForFundingOP(tx, fundingTxHash, fundingTxVoutIndex,
 			spendingTxHash, mainchain)
 		return addrs, N, voutDbID, mixed, err
 	}
@@ -2753,12 +2854,12 @@ func insertSpendingAddressRow(tx *sql.Tx, fundingTxHash dbtypes.ChainHash, fundi
 // are just for the block. The total length of time over all intervals always
 // spans the locked-in period of the agenda. votingDoneHeight references the
 // height at which the agenda ID voting is considered complete.
-func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string, byType int,
+func (q queries) retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string, byType int,
 	votingStartHeight, votingDoneHeight int64) (*dbtypes.AgendaVoteChoices, error) {
 	// Query with block or day interval size
-	var query = internal.SelectAgendasVotesByTime
+	var query = q.SelectAgendasVotesByTime
 	if byType == 1 {
-		query = internal.SelectAgendasVotesByHeight
+		query = q.SelectAgendasVotesByHeight
 	}
 
 	rows, err := db.QueryContext(ctx, query, dbtypes.Yes, dbtypes.Abstain, dbtypes.No,
@@ -2772,7 +2873,7 @@ func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string,
 	var a, y, n, t uint64
 	totalVotes := new(dbtypes.AgendaVoteChoices)
 	for rows.Next() {
-		var blockTime time.Time
+		var blockTime dbtypes.TimeDef // UNIX time stamp
 		var abstain, yes, no, total, height uint64
 		if byType == 0 {
 			err = rows.Scan(&blockTime, &yes, &abstain, &no, &total)
@@ -2789,7 +2890,7 @@ func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string,
 			y += yes
 			n += no
 			t += total
-			totalVotes.Time = append(totalVotes.Time, dbtypes.NewTimeDef(blockTime))
+			totalVotes.Time = append(totalVotes.Time, blockTime)
 		} else {
 			a = abstain
 			y = yes
@@ -2813,25 +2914,137 @@ func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string,
 // retrieveTotalAgendaVotesCount returns the Cumulative vote choices count for
 // the provided agenda id. votingDoneHeight references the height at which the
 // agenda ID voting is considered complete.
-func retrieveTotalAgendaVotesCount(ctx context.Context, db *sql.DB, agendaID string,
+func (q queries) retrieveTotalAgendaVotesCount(ctx context.Context, db *sql.DB, agendaID string,
 	votingStartHeight, votingDoneHeight int64) (yes, abstain, no uint32, err error) {
 	var total uint32
 
-	err = db.QueryRowContext(ctx, internal.SelectAgendaVoteTotals, dbtypes.Yes,
+	err = db.QueryRowContext(ctx, q.SelectAgendaVoteTotals, dbtypes.Yes,
 		dbtypes.Abstain, dbtypes.No, agendaID, votingStartHeight,
 		votingDoneHeight).Scan(&yes, &abstain, &no, &total)
 
 	return
 }
 
+// retrieveAgendaVotesByRCI returns the vote choices tally for the provided
+// agenda in each rule change interval up to height end. The intervals are
+// numbered from the stake validation height svh.
+func (q queries) retrieveAgendaVotesByRCI(ctx context.Context, db *sql.DB, agendaID string,
+	svh, rciBlocks, end int64) ([]*dbtypes.AgendaRCIVotes, error) {
+	rows, err := db.QueryContext(ctx, q.SelectAgendaVotesByRCI, dbtypes.Yes,
+		dbtypes.Abstain, dbtypes.No, agendaID, svh, end, svh, rciBlocks)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var votes []*dbtypes.AgendaRCIVotes
+	for rows.Next() {
+		v := new(dbtypes.AgendaRCIVotes)
+		if err = rows.Scan(&v.RCI, &v.Yes, &v.Abstain, &v.No, &v.Total); err != nil {
+			return nil, err
+		}
+		votes = append(votes, v)
+	}
+	return votes, rows.Err()
+}
+
+// retrieveVersionsByRCI returns the number of each version in each rule change
+// interval from height start to end for SelectVoteVersionsByRCI or
+// SelectBlockVersionsByRCI.
+func retrieveVersionsByRCI(ctx context.Context, db *sql.DB, query string,
+	svh, rciBlocks, start, end int64) ([]*dbtypes.RCIVersionCount, error) {
+	rows, err := db.QueryContext(ctx, query, svh, rciBlocks, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var counts []*dbtypes.RCIVersionCount
+	for rows.Next() {
+		c := new(dbtypes.RCIVersionCount)
+		if err = rows.Scan(&c.RCI, &c.Version, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// --- exchange_rates table ---
+
+// insertExchangeRates stores the index prices in a DB transaction.
+func (q queries) insertExchangeRates(db *sql.DB, prices []*dbtypes.IndexPrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	dbtx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	stmt, err := dbtx.Prepare(q.UpsertExchangeRate)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return fmt.Errorf("exchange_rates INSERT prepare failed: %w", err)
+	}
+
+	for _, p := range prices {
+		_, err = stmt.Exec(p.Currency, dbtypes.NewTimeDef(p.Start), p.Price,
+			p.Volume, p.Backfilled)
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbtx.Rollback()
+			return fmt.Errorf("failed to insert exchange rate: %w", err)
+		}
+	}
+
+	_ = stmt.Close()
+
+	return dbtx.Commit()
+}
+
+// retrieveExchangeRateAt retrieves the latest price of the currency for an
+// interval starting in the maxAge before t, up to and including t. The error
+// is sql.ErrNoRows if there is no such price.
+func (q queries) retrieveExchangeRateAt(ctx context.Context, db *sql.DB, currency string, t time.Time, maxAge time.Duration) (*dbtypes.IndexPrice, error) {
+	var start dbtypes.TimeDef
+	p := new(dbtypes.IndexPrice)
+	err := db.QueryRowContext(ctx, q.SelectExchangeRateAt, currency,
+		dbtypes.NewTimeDef(t.Add(-maxAge)), dbtypes.NewTimeDef(t)).
+		Scan(&p.Currency, &start, &p.Price, &p.Volume, &p.Backfilled)
+	if err != nil {
+		return nil, err
+	}
+	p.Start = start.T
+	return p, nil
+}
+
+// retrieveExchangeRateMean retrieves the mean price of the currency over the
+// intervals starting from start up to but not including end, weighting each
+// day with a price equally. The error is dbtypes.ErrNoResult if there are no
+// prices in the period.
+func (q queries) retrieveExchangeRateMean(ctx context.Context, db *sql.DB, currency string, start, end time.Time) (float64, error) {
+	var price float64
+	var days int64
+	err := db.QueryRowContext(ctx, q.SelectExchangeRateMean, currency,
+		dbtypes.NewTimeDef(start), dbtypes.NewTimeDef(end)).Scan(&price, &days)
+	if err != nil {
+		return 0, err
+	}
+	if days == 0 {
+		return 0, dbtypes.ErrNoResult
+	}
+	return price, nil
+}
+
 // --- atomic swap tables
 
-func insertSwap(db SqlExecutor, spendHeight int64, swapInfo *txhelpers.AtomicSwapData) error {
+func (q queries) insertSwap(db SqlExecutor, spendHeight int64, swapInfo *txhelpers.AtomicSwapData) error {
 	var secret interface{} // only nil interface stores a NULL, not even nil slice
 	if len(swapInfo.Secret) > 0 {
 		secret = swapInfo.Secret
 	}
-	_, err := db.Exec(internal.InsertContractSpend, (*dbtypes.ChainHash)(swapInfo.ContractTx), swapInfo.ContractVout,
+	_, err := db.Exec(q.InsertContractSpend, (*dbtypes.ChainHash)(swapInfo.ContractTx), swapInfo.ContractVout,
 		(*dbtypes.ChainHash)(swapInfo.SpendTx), swapInfo.SpendVin, spendHeight,
 		swapInfo.ContractAddress, swapInfo.Value,
 		swapInfo.SecretHash[:], secret, swapInfo.Locktime)
@@ -2879,8 +3092,8 @@ func insertTxnsStmt(stmt *sql.Stmt, dbTxns []*dbtypes.Tx) ([]uint64, error) {
 	return ids, nil
 }
 
-func insertTxnsDbTxn(dbTx *sql.Tx, dbTxns []*dbtypes.Tx, checked, updateExistingRecords bool) ([]uint64, error) {
-	stmt, err := dbTx.Prepare(internal.MakeTxInsertStatement(checked, updateExistingRecords))
+func (q queries) insertTxnsDbTxn(dbTx *sql.Tx, dbTxns []*dbtypes.Tx, checked, updateExistingRecords bool) ([]uint64, error) {
+	stmt, err := dbTx.Prepare(q.MakeTxInsertStatement(checked, updateExistingRecords))
 	if err != nil {
 		return nil, err
 	}
@@ -2946,11 +3159,11 @@ func InsertTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistingRecords
 // the given transaction hash. Stake-validated transactions in mainchain blocks
 // are chosen first. This function is used by FillAddressTransactions, an
 // important component of the addresses page.
-func retrieveDbTxByHash(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (id uint64, dbTx *dbtypes.Tx, err error) {
+func (q queries) retrieveDbTxByHash(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (id uint64, dbTx *dbtypes.Tx, err error) {
 	dbTx = new(dbtypes.Tx)
 	vinDbIDs := dbtypes.UInt64Array(dbTx.VinDbIds)
 	voutDbIDs := dbtypes.UInt64Array(dbTx.VoutDbIds)
-	err = db.QueryRowContext(ctx, internal.SelectFullTxByHash, txHash).Scan(&id,
+	err = db.QueryRowContext(ctx, q.SelectFullTxByHash, txHash).Scan(&id,
 		&dbTx.BlockHash, &dbTx.BlockHeight, &dbTx.BlockTime,
 		&dbTx.TxType, &dbTx.Version, &dbTx.Tree, &dbTx.TxID, &dbTx.BlockIndex,
 		&dbTx.Locktime, &dbTx.Expiry, &dbTx.Size, &dbTx.Spent, &dbTx.Sent,
@@ -2985,9 +3198,9 @@ func retrieveFullTxByHash(ctx context.Context, db *sql.DB, txHash string) (id ui
 // retrieveDbTxsByHash retrieves all the rows of the transactions table,
 // including the primary keys/ids, for the given transaction hash. This function
 // is used by the transaction page via ChainDB.Transaction.
-func retrieveDbTxsByHash(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (ids []uint64, dbTxs []*dbtypes.Tx, err error) {
+func (q queries) retrieveDbTxsByHash(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (ids []uint64, dbTxs []*dbtypes.Tx, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectFullTxsByHash, txHash)
+	rows, err = db.QueryContext(ctx, q.SelectFullTxsByHash, txHash)
 	if err != nil {
 		return
 	}
@@ -3057,11 +3270,11 @@ func retrieveTxnsVinsByBlock(ctx context.Context, db *sql.DB, blockHash string)
 // specified block the vin_db_ids and vout_db_ids arrays. This function is used
 // only by UpdateLastAddressesValid and other setting functions, where it should
 // not be subject to a timeout.
-func retrieveTxnsVinsVoutsByBlock(ctx context.Context, db *sql.DB, blockHash dbtypes.ChainHash, onlyRegular bool) (vinDbIDs, voutDbIDs []dbtypes.UInt64Array,
+func (q queries) retrieveTxnsVinsVoutsByBlock(ctx context.Context, db *sql.DB, blockHash dbtypes.ChainHash, onlyRegular bool) (vinDbIDs, voutDbIDs []dbtypes.UInt64Array,
 	areMainchain []bool, err error) {
-	stmt := internal.SelectTxnsVinsVoutsByBlock
+	stmt := q.SelectTxnsVinsVoutsByBlock
 	if onlyRegular {
-		stmt = internal.SelectRegularTxnsVinsVoutsByBlock
+		stmt = q.SelectRegularTxnsVinsVoutsByBlock
 	}
 
 	var rows *sql.Rows
@@ -3088,9 +3301,9 @@ func retrieveTxnsVinsVoutsByBlock(ctx context.Context, db *sql.DB, blockHash dbt
 	return
 }
 
-func retrieveTxByHash(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (id uint64, blockHash dbtypes.ChainHash,
+func (q queries) retrieveTxByHash(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (id uint64, blockHash dbtypes.ChainHash,
 	blockInd uint32, tree int8, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectTxByHash, txHash).Scan(&id, &blockHash, &blockInd, &tree)
+	err = db.QueryRowContext(ctx, q.SelectTxByHash, txHash).Scan(&id, &blockHash, &blockInd, &tree)
 	return
 }
 
@@ -3104,10 +3317,10 @@ func retrieveTxBlockTimeByHash(ctx context.Context, db *sql.DB, txHash string) (
 // retrieveTxsByBlockHash retrieves all transactions in a given block. This is
 // used by update functions, so care should be taken to not timeout in these
 // cases.
-func retrieveTxsByBlockHash(ctx context.Context, db *sql.DB, blockHash dbtypes.ChainHash) (txs []dbtypes.ChainHash,
+func (q queries) retrieveTxsByBlockHash(ctx context.Context, db *sql.DB, blockHash dbtypes.ChainHash) (txs []dbtypes.ChainHash,
 	blockInds []uint32, trees []int8, blockTimes []dbtypes.TimeDef, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectTxsByBlockHash, blockHash)
+	rows, err = db.QueryContext(ctx, q.SelectTxsByBlockHash, blockHash)
 	if err != nil {
 		return
 	}
@@ -3136,10 +3349,10 @@ func retrieveTxsByBlockHash(ctx context.Context, db *sql.DB, blockHash dbtypes.C
 // retrieveTxnsBlocks retrieves for the specified transaction hash the following
 // data for each block containing the transactions: block_hash, block_index,
 // is_valid, is_mainchain.
-func retrieveTxnsBlocks(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (blockHashes []dbtypes.ChainHash,
+func (q queries) retrieveTxnsBlocks(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHash) (blockHashes []dbtypes.ChainHash,
 	blockHeights, blockIndexes []uint32, areValid, areMainchain []bool, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectTxsBlocks, txHash)
+	rows, err = db.QueryContext(ctx, q.SelectTxsBlocks, txHash)
 	if err != nil {
 		return
 	}
@@ -3168,8 +3381,8 @@ func retrieveTxnsBlocks(ctx context.Context, db *sql.DB, txHash dbtypes.ChainHas
 // ----- Historical Charts on /charts page -----
 
 // retrieveChartBlocks sets or updates a few per-block datasets.
-func retrieveChartBlocks(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectBlockStats, charts.Height())
+func (q queries) retrieveChartBlocks(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectBlockStats, charts.Height())
 	if err != nil {
 		return nil, err
 	}
@@ -3215,13 +3428,14 @@ func appendChartBlocks(charts *cache.ChartData, rows *sql.Rows) error {
 			badRow()
 			// Something is wrong, but pretend that no work was done to keep the
 			// datasets sized properly.
-			bigwork = big.NewInt(int64(blocks.Chainwork[len(blocks.Chainwork)-1]))
+			chainwork := blocks.Uints(cache.ChainworkSeries)
+			bigwork = big.NewInt(int64(chainwork[len(chainwork)-1]))
 		}
 		blocks.Height = append(blocks.Height, height)
-		blocks.Chainwork = append(blocks.Chainwork, bigwork.Uint64())
-		blocks.TxCount = append(blocks.TxCount, count)
+		blocks.AppendUints(cache.ChainworkSeries, bigwork.Uint64())
+		blocks.AppendUints(cache.TxCountSeries, count)
 		blocks.Time = append(blocks.Time, uint64(timeDef.T.Unix()))
-		blocks.BlockSize = append(blocks.BlockSize, size)
+		blocks.AppendUints(cache.BlockSizeSeries, size)
 	}
 	if err := rows.Err(); err != nil {
 		return fmt.Errorf("appendChartBlocks: iteration error: %w", err)
@@ -3229,13 +3443,13 @@ func appendChartBlocks(charts *cache.ChartData, rows *sql.Rows) error {
 	if badRows > 0 {
 		log.Errorf("%d rows have invalid chainwork values.", badRows)
 	}
-	chainLen := len(blocks.Chainwork)
+	chainLen := len(blocks.Uints(cache.ChainworkSeries))
 	if rowCount > 0 && uint64(chainLen-1) != height {
 		return fmt.Errorf("appendChartBlocks: height misalignment. last height = %d. data length = %d", height, chainLen)
 	}
-	if len(blocks.Time) != chainLen || len(blocks.TxCount) != chainLen {
+	if len(blocks.Time) != chainLen || len(blocks.Uints(cache.TxCountSeries)) != chainLen {
 		return fmt.Errorf("appendChartBlocks: data length misalignment. len(chainwork) = %d, len(stamps) = %d, len(counts) = %d",
-			chainLen, len(blocks.Time), len(blocks.TxCount))
+			chainLen, len(blocks.Time), len(blocks.Uints(cache.TxCountSeries)))
 	}
 
 	return nil
@@ -3244,8 +3458,8 @@ func appendChartBlocks(charts *cache.ChartData, rows *sql.Rows) error {
 // retrieveWindowStats fetches the ticket-price and pow-difficulty
 // charts data source from the blocks table. These data is fetched at an
 // interval of chaincfg.Params.StakeDiffWindowSize.
-func retrieveWindowStats(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectBlocksTicketsPrice, charts.TicketPriceTip())
+func (q queries) retrieveWindowStats(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectBlocksTicketsPrice, charts.TicketPriceTip())
 	if err != nil {
 		return nil, err
 	}
@@ -3261,7 +3475,7 @@ func appendWindowStats(charts *cache.ChartData, rows *sql.Rows) error {
 
 	windows := charts.Windows
 	windowSize := int(charts.DiffInterval)
-	nextWindowHeight := windowSize * (len(windows.TicketPrice) + 1)
+	nextWindowHeight := windowSize * (len(windows.Uints(cache.TicketPriceSeries)) + 1)
 
 	var price, ticketsCount uint64
 	var timestamp time.Time
@@ -3278,10 +3492,10 @@ func appendWindowStats(charts *cache.ChartData, rows *sql.Rows) error {
 		// data, and reset for the next window.
 		fullWindow := height == nextWindowHeight-1 // e.g. mainnet block 143, 287, etc.
 		if fullWindow {
-			windows.TicketPrice = append(windows.TicketPrice, price)
-			windows.PowDiff = append(windows.PowDiff, difficulty)
+			windows.AppendUints(cache.TicketPriceSeries, price)
+			windows.AppendFloats(cache.PowDiffSeries, difficulty)
 			windows.Time = append(windows.Time, uint64(timestamp.Unix()))
-			windows.StakeCount = append(windows.StakeCount, ticketsCount)
+			windows.AppendUints(cache.StakeCountSeries, ticketsCount)
 
 			// Next sdiff window
 			ticketsCount = 0
@@ -3296,8 +3510,8 @@ func appendWindowStats(charts *cache.ChartData, rows *sql.Rows) error {
 }
 
 // retrieveCoinSupply fetches the coin supply data from the vins table.
-func retrieveCoinSupply(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectCoinSupply, charts.NewAtomsTip())
+func (q queries) retrieveCoinSupply(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectCoinSupply, charts.NewAtomsTip())
 	if err != nil {
 		return nil, err
 	}
@@ -3316,23 +3530,23 @@ func appendCoinSupply(charts *cache.ChartData, rows *sql.Rows) error {
 			return err
 		}
 
-		blocks.NewAtoms = append(blocks.NewAtoms, uint64(value))
+		blocks.AppendUints(cache.NewAtomsSeries, uint64(value))
 	}
 	if err := rows.Err(); err != nil {
 		return err
 	}
 
 	// Set the genesis block to zero because the DB stores it as -1
-	if len(blocks.NewAtoms) > 0 {
-		blocks.NewAtoms[0] = 0
+	if newAtoms := blocks.Uints(cache.NewAtomsSeries); len(newAtoms) > 0 {
+		newAtoms[0] = 0
 	}
 	return nil
 }
 
 // retrieveMissedVotes fetches the missed votes data from the misses and
 // transactions tables.
-func retrieveMissedVotes(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectMissCountPerBlock, charts.MissedVotesTip())
+func (q queries) retrieveMissedVotes(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectMissCountPerBlock, charts.MissedVotesTip())
 	if err != nil {
 		return nil, err
 	}
@@ -3347,7 +3561,7 @@ func appendMissedVotesPerWindow(charts *cache.ChartData, rows *sql.Rows) error {
 
 	windows := charts.Windows
 	windowSize := int(charts.DiffInterval)
-	nextWindowHeight := windowSize * (len(windows.MissedVotes) + 1)
+	nextWindowHeight := windowSize * (len(windows.Uints(cache.MissedVotesSeries)) + 1)
 
 	var windowMisses int
 	for rows.Next() {
@@ -3361,7 +3575,7 @@ func appendMissedVotesPerWindow(charts *cache.ChartData, rows *sql.Rows) error {
 		// windowMisses, and reset for the next window.
 		fullWindow := height == nextWindowHeight-1 // e.g. mainnet block 143, 287, etc.
 		if fullWindow {
-			windows.MissedVotes = append(windows.MissedVotes, uint64(windowMisses))
+			windows.AppendUints(cache.MissedVotesSeries, uint64(windowMisses))
 
 			// Next sdiff window
 			windowMisses = 0
@@ -3378,8 +3592,8 @@ func appendMissedVotesPerWindow(charts *cache.ChartData, rows *sql.Rows) error {
 // retrieveBlockFees retrieves any block fee data that is newer than the data
 // in the provided ChartData. This data is used to plot fees on the /charts page.
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater.
-func retrieveBlockFees(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectFeesPerBlockAboveHeight, charts.FeesTip())
+func (q queries) retrieveBlockFees(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectFeesPerBlockAboveHeight, charts.FeesTip())
 	if err != nil {
 		return nil, err
 	}
@@ -3403,7 +3617,7 @@ func appendBlockFees(charts *cache.ChartData, rows *sql.Rows) error {
 		}
 
 		// Converting to atoms.
-		blocks.Fees = append(blocks.Fees, uint64(fees))
+		blocks.AppendUints(cache.FeesSeries, uint64(fees))
 	}
 	return rows.Err()
 }
@@ -3412,8 +3626,8 @@ func appendBlockFees(charts *cache.ChartData, rows *sql.Rows) error {
 // newer than the data in the provided ChartData. This data is used to plot fees
 // on the /charts page. This is the Fetcher half of a pair that make up a
 // cache.ChartUpdater.
-func retrievePrivacyParticipation(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectMixedTotalPerBlock, charts.TotalMixedTip())
+func (q queries) retrievePrivacyParticipation(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectMixedTotalPerBlock, charts.TotalMixedTip())
 	if err != nil {
 		return nil, err
 	}
@@ -3434,7 +3648,7 @@ func appendPrivacyParticipation(charts *cache.ChartData, rows *sql.Rows) error {
 		}
 
 		// Converting to atoms.
-		charts.Blocks.TotalMixed = append(charts.Blocks.TotalMixed, uint64(totalMixed))
+		charts.Blocks.AppendUints(cache.TotalMixedSeries, uint64(totalMixed))
 	}
 	return rows.Err()
 }
@@ -3444,7 +3658,7 @@ func appendPrivacyParticipation(charts *cache.ChartData, rows *sql.Rows) error {
 // cache.ChartUpdater.
 func appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows) error {
 	blocks := charts.Blocks
-	nextHeight := int64(len(blocks.AnonymitySet))
+	nextHeight := int64(len(blocks.Uints(cache.AnonymitySetSeries)))
 	endHeight := int64(len(blocks.Height) - 1)
 
 	setDiffs := make(map[int64]int64, endHeight-nextHeight+1) // map[height]value_diff
@@ -3479,9 +3693,9 @@ func appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows) error {
 		// next = previous + delta
 		nextAnonSet := setDiffs[h] // setDiffs[h] may be not found (zero) or negative
 		if h > 0 {
-			nextAnonSet += int64(blocks.AnonymitySet[h-1])
+			nextAnonSet += int64(blocks.Uints(cache.AnonymitySetSeries)[h-1])
 		}
-		blocks.AnonymitySet = append(blocks.AnonymitySet, uint64(nextAnonSet))
+		blocks.AppendUints(cache.AnonymitySetSeries, uint64(nextAnonSet))
 	}
 
 	return nil
@@ -3491,8 +3705,8 @@ func appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows) error {
 // charts data needed to plot ticket-pool-size and ticket-pool value charts on
 // the charts page. This is the Fetcher half of a pair that make up a
 // cache.ChartUpdater.
-func retrievePoolStats(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectPoolStatsAboveHeight, charts.PoolSizeTip())
+func (q queries) retrievePoolStats(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, q.SelectPoolStatsAboveHeight, charts.PoolSizeTip())
 	if err != nil {
 		return nil, err
 	}
@@ -3510,16 +3724,16 @@ func appendPoolStats(charts *cache.ChartData, rows *sql.Rows) error {
 			log.Errorf("Unable to scan for TicketPoolInfo fields: %v", err)
 			return err
 		}
-		blocks.PoolSize = append(blocks.PoolSize, psize)
-		blocks.PoolValue = append(blocks.PoolValue, pval)
+		blocks.AppendUints(cache.PoolSizeSeries, psize)
+		blocks.AppendUints(cache.PoolValueSeries, pval)
 	}
 	return rows.Err()
 }
 
 // retrievePowerlessTickets fetches missed or expired tickets sorted by
 // revocation status.
-func retrievePowerlessTickets(ctx context.Context, db *sql.DB) (*apitypes.PowerlessTickets, error) {
-	rows, err := db.QueryContext(ctx, internal.SelectTicketSpendTypeByBlock, -1)
+func (q queries) retrievePowerlessTickets(ctx context.Context, db *sql.DB) (*apitypes.PowerlessTickets, error) {
+	rows, err := db.QueryContext(ctx, q.SelectTicketSpendTypeByBlock, -1)
 	if err != nil {
 		return nil, err
 	}
@@ -3601,8 +3815,8 @@ func retrieveTxPerDay(ctx context.Context, db *sql.DB, timeArr []dbtypes.TimeDef
 // that a unique constraint violation will result in an update instead of
 // attempting to insert a duplicate row. If checked is false and there is a
 // duplicate row, an error will be returned.
-func insertBlock(db *sql.DB, dbBlock *dbtypes.Block, isValid, isMainchain, checked bool) (uint64, error) {
-	insertStatement := internal.BlockInsertStatement(checked)
+func (q queries) insertBlock(db *sql.DB, dbBlock *dbtypes.Block, isValid, isMainchain, checked bool) (uint64, error) {
+	insertStatement := q.BlockInsertStatement(checked)
 	var id uint64
 	err := db.QueryRow(insertStatement,
 		dbBlock.Hash, dbBlock.Height, dbBlock.Size, isValid, isMainchain,
@@ -3631,9 +3845,9 @@ func insertBlock(db *sql.DB, dbBlock *dbtypes.Block, isValid, isMainchain, check
 // }
 
 // insertBlockPrevNext inserts a new row of the block_chain table.
-func insertBlockPrevNext(db *sql.DB, blockDbID uint64,
+func (q queries) insertBlockPrevNext(db *sql.DB, blockDbID uint64,
 	hash, prev, next *dbtypes.ChainHash) error {
-	rows, err := db.Query(internal.InsertBlockPrevNext, blockDbID, prev, hash, next)
+	rows, err := db.Query(q.InsertBlockPrevNext, blockDbID, prev, hash, next)
 	if err != nil {
 		return err
 	}
@@ -3644,24 +3858,24 @@ func insertBlockPrevNext(db *sql.DB, blockDbID uint64,
 }
 
 // insertBlockStats inserts the block stats into the stats table.
-func insertBlockStats(db *sql.DB, blockDbID uint64, tpi *apitypes.TicketPoolInfo) error {
-	_, err := db.Exec(internal.UpsertStats, blockDbID, tpi.Height, tpi.Size, int64(tpi.Value*dcrToAtoms))
+func (q queries) insertBlockStats(db *sql.DB, blockDbID uint64, tpi *apitypes.TicketPoolInfo) error {
+	_, err := db.Exec(q.UpsertStats, blockDbID, tpi.Height, tpi.Size, int64(tpi.Value*dcrToAtoms))
 	return err
 }
 
 // retrieveBestBlockHeight gets the best block height and hash (main chain
 // only). Be sure to check for sql.ErrNoRows.
-func retrieveBestBlockHeight(ctx context.Context, db *sql.DB) (height uint64, hash dbtypes.ChainHash, err error) {
+func (q queries) retrieveBestBlockHeight(ctx context.Context, db *sql.DB) (height uint64, hash dbtypes.ChainHash, err error) {
 	var id uint64 // maybe remove from query
-	err = db.QueryRowContext(ctx, internal.RetrieveBestBlockHeight).Scan(&id, &hash, &height)
+	err = db.QueryRowContext(ctx, q.RetrieveBestBlockHeight).Scan(&id, &hash, &height)
 	return
 }
 
 // retrieveBestBlock gets the best block height and hash (main chain only). If
 // there are no results from the query, the height is -1 and err is nil.
-func retrieveBestBlock(ctx context.Context, db *sql.DB) (height int64, hash dbtypes.ChainHash, err error) {
+func (q queries) retrieveBestBlock(ctx context.Context, db *sql.DB) (height int64, hash dbtypes.ChainHash, err error) {
 	var bbHeight uint64
-	bbHeight, hash, err = retrieveBestBlockHeight(ctx, db)
+	bbHeight, hash, err = q.retrieveBestBlockHeight(ctx, db)
 	height = int64(bbHeight)
 	if err != nil && err == sql.ErrNoRows {
 		height = -1
@@ -3679,28 +3893,56 @@ func retrieveBestBlock(ctx context.Context, db *sql.DB) (height int64, hash dbty
 // retrieveBlockHash retrieves the hash of the block at the given height, if it
 // exists (be sure to check error against sql.ErrNoRows!). WARNING: this returns
 // the most recently added block at this height, but there may be others.
-func retrieveBlockHash(ctx context.Context, db *sql.DB, idx int64) (hash dbtypes.ChainHash, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockHashByHeight, idx).Scan(&hash)
+func (q queries) retrieveBlockHash(ctx context.Context, db *sql.DB, idx int64) (hash dbtypes.ChainHash, err error) {
+	err = db.QueryRowContext(ctx, q.SelectBlockHashByHeight, idx).Scan(&hash)
 	return
 }
 
 // retrieveBlockTimeByHeight retrieves time hash of the main chain block at the
 // given height, if it exists (be sure to check error against sql.ErrNoRows!).
-func retrieveBlockTimeByHeight(ctx context.Context, db *sql.DB, idx int64) (time dbtypes.TimeDef, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockTimeByHeight, idx).Scan(&time)
+func (q queries) retrieveBlockTimeByHeight(ctx context.Context, db *sql.DB, idx int64) (time dbtypes.TimeDef, err error) {
+	err = db.QueryRowContext(ctx, q.SelectBlockTimeByHeight, idx).Scan(&time)
 	return
 }
 
+// retrieveBlockHeightByTime retrieves the height of the first mainchain block
+// at or after the time (be sure to check error against sql.ErrNoRows!).
+func (q queries) retrieveBlockHeightByTime(ctx context.Context, db *sql.DB, timestamp int64) (height int64, err error) {
+	tDef := dbtypes.NewTimeDefFromUNIX(timestamp)
+	err = db.QueryRowContext(ctx, q.SelectBlockHeightByTime, tDef).Scan(&height)
+	return
+}
+
+// retrieveBlockStakeData retrieves the ticket price, ticket purchases, and pool
+// size of the mainchain blocks from a height.
+func (q queries) retrieveBlockStakeData(ctx context.Context, db *sql.DB, start int64) ([]*dbtypes.BlockStakeData, error) {
+	rows, err := db.QueryContext(ctx, q.SelectBlockStakeData, start)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var blocks []*dbtypes.BlockStakeData
+	for rows.Next() {
+		b := new(dbtypes.BlockStakeData)
+		if err = rows.Scan(&b.Height, &b.Time, &b.SBits, &b.FreshStake, &b.PoolSize); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
 // retrieveBlockHeight retrieves the height of the block with the given hash, if
 // it exists (be sure to check error against sql.ErrNoRows!).
-func retrieveBlockHeight(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (height int64, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockHeightByHash, hash).Scan(&height)
+func (q queries) retrieveBlockHeight(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (height int64, err error) {
+	err = db.QueryRowContext(ctx, q.SelectBlockHeightByHash, hash).Scan(&height)
 	return
 }
 
 // retrieveBlockVoteCount gets the number of votes mined in a block.
-func retrieveBlockVoteCount(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (numVotes int16, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockVoteCount, hash).Scan(&numVotes)
+func (q queries) retrieveBlockVoteCount(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (numVotes int16, err error) {
+	err = db.QueryRowContext(ctx, q.SelectBlockVoteCount, hash).Scan(&numVotes)
 	return
 }
 
@@ -3732,9 +3974,9 @@ func retrieveBlocksHashesAll(ctx context.Context, db *sql.DB) ([]string, error)
 
 // retrieveSideChainBlocks retrieves the block chain status for all known side
 // chain blocks.
-func retrieveSideChainBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
+func (q queries) retrieveSideChainBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectSideChainBlocks)
+	rows, err = db.QueryContext(ctx, q.SelectSideChainBlocks)
 	if err != nil {
 		return
 	}
@@ -3783,9 +4025,9 @@ func retrieveSideChainTips(ctx context.Context, db *sql.DB) (blocks []*dbtypes.B
 
 // retrieveDisapprovedBlocks retrieves the block chain status for all blocks
 // that had their regular transactions invalidated by stakeholder disapproval.
-func retrieveDisapprovedBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
+func (q queries) retrieveDisapprovedBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectDisapprovedBlocks)
+	rows, err = db.QueryContext(ctx, q.SelectDisapprovedBlocks)
 	if err != nil {
 		return
 	}
@@ -3807,17 +4049,17 @@ func retrieveDisapprovedBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtyp
 
 // retrieveBlockStatus retrieves the block chain status for the block with the
 // specified hash.
-func retrieveBlockStatus(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (bs dbtypes.BlockStatus, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockStatus, hash).Scan(&bs.IsValid,
+func (q queries) retrieveBlockStatus(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (bs dbtypes.BlockStatus, err error) {
+	err = db.QueryRowContext(ctx, q.SelectBlockStatus, hash).Scan(&bs.IsValid,
 		&bs.IsMainchain, &bs.Height, &bs.PrevHash, &bs.Hash, &bs.NextHash)
 	return
 }
 
 // retrieveBlockStatuses retrieves the block chain statuses of all blocks at
 // the given height.
-func retrieveBlockStatuses(ctx context.Context, db *sql.DB, idx int64) (blocks []*dbtypes.BlockStatus, err error) {
+func (q queries) retrieveBlockStatuses(ctx context.Context, db *sql.DB, idx int64) (blocks []*dbtypes.BlockStatus, err error) {
 	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectBlockStatuses, idx)
+	rows, err = db.QueryContext(ctx, q.SelectBlockStatuses, idx)
 	if err != nil {
 		return
 	}
@@ -3838,8 +4080,8 @@ func retrieveBlockStatuses(ctx context.Context, db *sql.DB, idx int64) (blocks [
 }
 
 // retrieveBlockFlags retrieves the block's is_valid and is_mainchain flags.
-func retrieveBlockFlags(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (isValid bool, isMainchain bool, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockFlags, hash).Scan(&isValid, &isMainchain)
+func (q queries) retrieveBlockFlags(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (isValid bool, isMainchain bool, err error) {
+	err = db.QueryRowContext(ctx, q.SelectBlockFlags, hash).Scan(&isValid, &isMainchain)
 	return
 }
 
@@ -3847,24 +4089,26 @@ func retrieveBlockFlags(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash)
 // the given time range. The limit specifies the number of most recent block
 // summaries to return. A limit of 0 indicates all blocks in the time range
 // should be included.
-func retrieveBlockSummaryByTimeRange(ctx context.Context, db *sql.DB, minTime, maxTime int64, limit int) ([]dbtypes.BlockDataBasic, error) {
+func (q queries) retrieveBlockSummaryByTimeRange(ctx context.Context, db *sql.DB, minTime, maxTime int64, limit int) ([]dbtypes.BlockDataBasic, error) {
 	var blocks []dbtypes.BlockDataBasic
 	var stmt *sql.Stmt
 	var rows *sql.Rows
 	var err error
 
-	// int64 -> time.Time is required to query TIMESTAMPTZ columns.
-	minT := time.Unix(minTime, 0)
-	maxT := time.Unix(maxTime, 0)
+	// int64 -> time.Time is required to query TIMESTAMPTZ columns. The SQLite
+	// TIMESTAMP columns hold UTC time strings, which are compared with the
+	// time.Time arguments as text, so the arguments must also be in UTC.
+	minT := time.Unix(minTime, 0).UTC()
+	maxT := time.Unix(maxTime, 0).UTC()
 
 	if limit == 0 {
-		stmt, err = db.Prepare(internal.SelectBlockByTimeRangeSQLNoLimit)
+		stmt, err = db.Prepare(q.SelectBlockByTimeRangeSQLNoLimit)
 		if err != nil {
 			return nil, err
 		}
 		rows, err = stmt.QueryContext(ctx, minT, maxT)
 	} else {
-		stmt, err = db.Prepare(internal.SelectBlockByTimeRangeSQL)
+		stmt, err = db.Prepare(q.SelectBlockByTimeRangeSQL)
 		if err != nil {
 			return nil, err
 		}
@@ -3905,8 +4149,8 @@ func retrieveBlockSummaryByTimeRange(ctx context.Context, db *sql.DB, minTime, m
 
 // setMainchainByBlockHash is used to set the is_mainchain flag for the given
 // block. This is required to handle a reorganization.
-func setMainchainByBlockHash(db *sql.DB, hash dbtypes.ChainHash, isMainchain bool) (previousHash dbtypes.ChainHash, err error) {
-	err = db.QueryRow(internal.UpdateBlockMainchain, hash, isMainchain).Scan(&previousHash)
+func (q queries) setMainchainByBlockHash(db *sql.DB, hash dbtypes.ChainHash, isMainchain bool) (previousHash dbtypes.ChainHash, err error) {
+	err = db.QueryRow(q.UpdateBlockMainchain, hash, isMainchain).Scan(&previousHash)
 	return
 }
 
@@ -3914,8 +4158,8 @@ func setMainchainByBlockHash(db *sql.DB, hash dbtypes.ChainHash, isMainchain boo
 
 // UpdateTransactionsMainchain sets the is_mainchain column for the transactions
 // in the specified block.
-func updateTransactionsMainchain(db *sql.DB, blockHash dbtypes.ChainHash, isMainchain bool) (int64, []uint64, error) {
-	rows, err := db.Query(internal.UpdateTxnsMainchainByBlock, isMainchain, blockHash)
+func (q queries) updateTransactionsMainchain(db *sql.DB, blockHash dbtypes.ChainHash, isMainchain bool) (int64, []uint64, error) {
+	rows, err := db.Query(q.UpdateTxnsMainchainByBlock, isMainchain, blockHash)
 	if err != nil {
 		return 0, nil, fmt.Errorf("failed to update transactions is_mainchain: %w", err)
 	}
@@ -3940,8 +4184,8 @@ func updateTransactionsMainchain(db *sql.DB, blockHash dbtypes.ChainHash, isMain
 
 // updateTransactionsValid sets the is_valid column of the transactions table
 // for the regular (non-stake) transactions in the specified block.
-func updateTransactionsValid(db *sql.DB, blockHash dbtypes.ChainHash, isValid bool) (int64, []uint64, error) {
-	rows, err := db.Query(internal.UpdateRegularTxnsValidByBlock, isValid, blockHash)
+func (q queries) updateTransactionsValid(db *sql.DB, blockHash dbtypes.ChainHash, isValid bool) (int64, []uint64, error) {
+	rows, err := db.Query(q.UpdateRegularTxnsValidByBlock, isValid, blockHash)
 	if err != nil {
 		return 0, nil, fmt.Errorf("failed to update regular transactions is_valid: %w", err)
 	}
@@ -3966,8 +4210,8 @@ func updateTransactionsValid(db *sql.DB, blockHash dbtypes.ChainHash, isValid bo
 
 // updateVotesMainchain sets the is_mainchain column for the votes in the
 // specified block.
-func updateVotesMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainchain bool) (int64, error) {
-	numRows, err := sqlExec(db, internal.UpdateVotesMainchainByBlock,
+func (q queries) updateVotesMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainchain bool) (int64, error) {
+	numRows, err := sqlExec(db, q.UpdateVotesMainchainByBlock,
 		"failed to update votes is_mainchain: ", isMainchain, blockHash)
 	if err != nil {
 		return 0, err
@@ -3977,8 +4221,8 @@ func updateVotesMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMaincha
 
 // updateTicketsMainchain sets the is_mainchain column for the tickets in the
 // specified block.
-func updateTicketsMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainchain bool) (int64, error) {
-	numRows, err := sqlExec(db, internal.UpdateTicketsMainchainByBlock,
+func (q queries) updateTicketsMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainchain bool) (int64, error) {
+	numRows, err := sqlExec(db, q.UpdateTicketsMainchainByBlock,
 		"failed to update tickets is_mainchain: ", isMainchain, blockHash)
 	if err != nil {
 		return 0, err
@@ -3988,8 +4232,8 @@ func updateTicketsMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainc
 
 // updateTreasuryMainchain sets the is_mainchain column for the entires in the
 // specified block.
-func updateTreasuryMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainchain bool) (int64, error) {
-	numRows, err := sqlExec(db, internal.UpdateTreasuryMainchainByBlock,
+func (q queries) updateTreasuryMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMainchain bool) (int64, error) {
+	numRows, err := sqlExec(db, q.UpdateTreasuryMainchainByBlock,
 		"failed to update treasury txns is_mainchain: ", isMainchain, blockHash)
 	if err != nil {
 		return 0, err
@@ -3997,14 +4241,85 @@ func updateTreasuryMainchain(db SqlExecutor, blockHash dbtypes.ChainHash, isMain
 	return numRows, nil
 }
 
-func binnedTreasuryIO(ctx context.Context, db *sql.DB, timeInterval string) (*dbtypes.ChartsData, error) {
-	rows, err := db.QueryContext(ctx, internal.MakeSelectTreasuryIOStatement(timeInterval))
+func (q queries) binnedTreasuryIO(ctx context.Context, db *sql.DB, timeInterval string) (*dbtypes.ChartsData, error) {
+	rows, err := db.QueryContext(ctx, q.MakeSelectTreasuryIOStatement(timeInterval))
 	if err != nil {
 		return nil, err
 	}
 	return parseRowsSentReceived(rows)
 }
 
+// retrieveTreasuryStatements retrieves the treasury statements for each period
+// of the time grouping.
+func (q queries) retrieveTreasuryStatements(ctx context.Context, db *sql.DB, grouping dbtypes.TimeBasedGrouping) ([]*dbtypes.TreasuryStatement, error) {
+	rows, err := db.QueryContext(ctx, q.MakeSelectTreasuryStatementTotals(grouping.String()))
+	if err != nil {
+		return nil, err
+	}
+	var totals []*dbtypes.TreasuryStatement
+	for rows.Next() {
+		st := new(dbtypes.TreasuryStatement)
+		err = rows.Scan(&st.Start, &st.TBase, &st.TBaseCount, &st.Added,
+			&st.AddCount, &st.Spent, &st.SpendCount)
+		if err != nil {
+			closeRows(rows)
+			return nil, err
+		}
+		totals = append(totals, st)
+	}
+	if err = rows.Err(); err != nil {
+		closeRows(rows)
+		return nil, err
+	}
+	closeRows(rows)
+
+	rows, err = db.QueryContext(ctx, q.SelectTreasuryAddInputs)
+	if err != nil {
+		return nil, err
+	}
+	var inputs []*dbtypes.TreasuryAddInput
+	for rows.Next() {
+		var txHash dbtypes.ChainHash
+		var blockTime dbtypes.TimeDef
+		in := new(dbtypes.TreasuryAddInput)
+		err = rows.Scan(&txHash, &blockTime, &in.Amount, &in.Address, &in.Value)
+		if err != nil {
+			closeRows(rows)
+			return nil, err
+		}
+		in.TxID = txHash.String()
+		in.BlockTime = blockTime.T
+		inputs = append(inputs, in)
+	}
+	if err = rows.Err(); err != nil {
+		closeRows(rows)
+		return nil, err
+	}
+	closeRows(rows)
+
+	rows, err = db.QueryContext(ctx, q.SelectTreasurySpendPayouts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+	var payouts []*dbtypes.TreasurySpendPayout
+	for rows.Next() {
+		var txHash dbtypes.ChainHash
+		p := new(dbtypes.TreasurySpendPayout)
+		err = rows.Scan(&txHash, &p.BlockHeight, &p.BlockTime, &p.Address, &p.Amount)
+		if err != nil {
+			return nil, err
+		}
+		p.TxID = txHash.String()
+		payouts = append(payouts, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dbtypes.NewTreasuryStatements(totals, inputs, payouts, grouping)
+}
+
 func toCoin[T int64 | uint64](amt T) float64 {
 	return float64(amt) / 1e8
 }
@@ -4013,14 +4328,14 @@ func parseRowsSentReceived(rows *sql.Rows) (*dbtypes.ChartsData, error) {
 	defer closeRows(rows)
 	var items = new(dbtypes.ChartsData)
 	for rows.Next() {
-		var blockTime time.Time
+		var blockTime dbtypes.TimeDef // UNIX time stamp
 		var received, sent uint64
 		err := rows.Scan(&blockTime, &received, &sent)
 		if err != nil {
 			return nil, err
 		}
 
-		items.Time = append(items.Time, dbtypes.NewTimeDef(blockTime))
+		items.Time = append(items.Time, blockTime)
 		items.Received = append(items.Received, toCoin(received))
 		items.Sent = append(items.Sent, toCoin(sent))
 		// Net represents the difference between the received and sent amount for a
@@ -4038,7 +4353,7 @@ func parseRowsSentReceived(rows *sql.Rows) (*dbtypes.ChartsData, error) {
 
 // updateAddressesMainchainByIDs sets the valid_mainchain column for the
 // addresses specified by their vin (spending) or vout (funding) row IDs.
-func updateAddressesMainchainByIDs(db SqlExecQueryer, vinsBlk, voutsBlk []dbtypes.UInt64Array,
+func (q queries) updateAddressesMainchainByIDs(db SqlExecQueryer, vinsBlk, voutsBlk []dbtypes.UInt64Array,
 	isValidMainchain bool) (addresses []string, numSpendingRows, numFundingRows int64, err error) {
 	addrs := make(map[string]struct{}, len(vinsBlk)+len(voutsBlk)) // may be over-alloc
 	// Spending/vins: Set valid_mainchain for the is_funding=false addresses
@@ -4046,7 +4361,7 @@ func updateAddressesMainchainByIDs(db SqlExecQueryer, vinsBlk, voutsBlk []dbtype
 	for iTxn := range vinsBlk {
 		for _, vin := range vinsBlk[iTxn] {
 			var address string
-			err = db.QueryRow(internal.SetAddressMainchainForVinIDs, isValidMainchain, vin).Scan(&address)
+			err = db.QueryRow(q.SetAddressMainchainForVinIDs, isValidMainchain, vin).Scan(&address)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) {
 					err = nil
@@ -4064,7 +4379,7 @@ func updateAddressesMainchainByIDs(db SqlExecQueryer, vinsBlk, voutsBlk []dbtype
 	for iTxn := range voutsBlk {
 		for _, vout := range voutsBlk[iTxn] {
 			var address string
-			err = db.QueryRow(internal.SetAddressMainchainForVoutIDs, isValidMainchain, vout).Scan(&address)
+			err = db.QueryRow(q.SetAddressMainchainForVoutIDs, isValidMainchain, vout).Scan(&address)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) {
 					err = nil
@@ -4088,8 +4403,8 @@ func updateAddressesMainchainByIDs(db SqlExecQueryer, vinsBlk, voutsBlk []dbtype
 
 // updateLastBlockValid updates the is_valid column of the block specified by
 // the row id for the blocks table.
-func updateLastBlockValid(db SqlExecutor, blockDbID uint64, isValid bool) error {
-	numRows, err := sqlExec(db, internal.UpdateLastBlockValid,
+func (q queries) updateLastBlockValid(db SqlExecutor, blockDbID uint64, isValid bool) error {
+	numRows, err := sqlExec(db, q.UpdateLastBlockValid,
 		"failed to update last block validity: ", blockDbID, isValid)
 	if err != nil {
 		return err
@@ -4123,18 +4438,18 @@ func clearVoutAllSpendTxRowIDs(db SqlExecutor, transactionsBlockHash dbtypes.Cha
 // updateLastVins updates the is_valid and is_mainchain columns in the vins
 // table for all of the transactions in the block specified by the given block
 // hash.
-func updateLastVins(db *sql.DB, blockHash dbtypes.ChainHash, isValid, isMainchain bool) error {
+func (q queries) updateLastVins(db *sql.DB, blockHash dbtypes.ChainHash, isValid, isMainchain bool) error {
 	// retrieve the hash for every transaction in this block. A context with no
 	// deadline or cancellation function is used since this UpdateLastVins needs
 	// to complete to ensure DB integrity.
-	txs, _, trees, timestamps, err := retrieveTxsByBlockHash(context.Background(), db, blockHash)
+	txs, _, trees, timestamps, err := q.retrieveTxsByBlockHash(context.Background(), db, blockHash)
 	if err != nil {
 		return err
 	}
 
 	for i, txHash := range txs {
 		thisValid := isValid || trees[i] == wire.TxTreeStake
-		n, err := sqlExec(db, internal.SetIsValidIsMainchainByTxHash,
+		n, err := sqlExec(db, q.SetIsValidIsMainchainByTxHash,
 			"failed to update last vins tx validity: ", thisValid, isMainchain,
 			txHash, timestamps[i])
 		if err != nil {
@@ -4152,20 +4467,20 @@ func updateLastVins(db *sql.DB, blockHash dbtypes.ChainHash, isValid, isMainchai
 // updateLastAddressesValid sets valid_mainchain as specified by isValid for
 // addresses table rows pertaining to regular (non-stake) transactions found in
 // the given block.
-func updateLastAddressesValid(db *sql.DB, blockHash dbtypes.ChainHash, isValid bool) ([]string, error) {
+func (q queries) updateLastAddressesValid(db *sql.DB, blockHash dbtypes.ChainHash, isValid bool) ([]string, error) {
 	// The queries in this function should not timeout or (probably) canceled,
 	// so use a background context.
 	ctx := context.Background()
 
 	// Get the row ids of all vins and vouts of regular txns in this block.
 	onlyRegularTxns := true
-	vinDbIDsBlk, voutDbIDsBlk, _, err := retrieveTxnsVinsVoutsByBlock(ctx, db, blockHash, onlyRegularTxns)
+	vinDbIDsBlk, voutDbIDsBlk, _, err := q.retrieveTxnsVinsVoutsByBlock(ctx, db, blockHash, onlyRegularTxns)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve vin data for block %s: %w", blockHash, err)
 	}
 	// Using vins and vouts row ids, update the valid_mainchain column of the
 	// rows of the address table referring to these vins and vouts.
-	addresses, numAddrSpending, numAddrFunding, err := updateAddressesMainchainByIDs(db,
+	addresses, numAddrSpending, numAddrFunding, err := q.updateAddressesMainchainByIDs(db,
 		vinDbIDsBlk, voutDbIDsBlk, isValid)
 	if err != nil {
 		log.Errorf("Failed to set addresses rows in block %s as sidechain: %w", blockHash, err)
@@ -4177,8 +4492,8 @@ func updateLastAddressesValid(db *sql.DB, blockHash dbtypes.ChainHash, isValid b
 
 // updateBlockNext sets the next block's hash for the specified row of the
 // block_chain table specified by DB row ID.
-func updateBlockNext(db SqlExecutor, blockDbID uint64, next dbtypes.ChainHash) error {
-	res, err := db.Exec(internal.UpdateBlockNext, blockDbID, next)
+func (q queries) updateBlockNext(db SqlExecutor, blockDbID uint64, next dbtypes.ChainHash) error {
+	res, err := db.Exec(q.UpdateBlockNext, blockDbID, next)
 	if err != nil {
 		return err
 	}
@@ -4194,8 +4509,8 @@ func updateBlockNext(db SqlExecutor, blockDbID uint64, next dbtypes.ChainHash) e
 
 // updateBlockNextByHash sets the next block's hash for the block in the
 // block_chain table specified by hash.
-func updateBlockNextByHash(db SqlExecutor, this, next dbtypes.ChainHash) error {
-	res, err := db.Exec(internal.UpdateBlockNextByHash, this, next)
+func (q queries) updateBlockNextByHash(db SqlExecutor, this, next dbtypes.ChainHash) error {
+	res, err := db.Exec(q.UpdateBlockNextByHash, this, next)
 	if err != nil {
 		return err
 	}
@@ -4211,8 +4526,8 @@ func updateBlockNextByHash(db SqlExecutor, this, next dbtypes.ChainHash) error {
 
 // updateBlockNextByNextHash sets the next block's hash for the block in the
 // block_chain table with a current next_hash specified by hash.
-func updateBlockNextByNextHash(db SqlExecutor, currentNext, newNext dbtypes.ChainHash) error {
-	res, err := db.Exec(internal.UpdateBlockNextByNextHash, currentNext, newNext)
+func (q queries) updateBlockNextByNextHash(db SqlExecutor, currentNext, newNext dbtypes.ChainHash) error {
+	res, err := db.Exec(q.UpdateBlockNextByNextHash, currentNext, newNext)
 	if err != nil {
 		return err
 	}
@@ -4227,14 +4542,14 @@ func updateBlockNextByNextHash(db SqlExecutor, currentNext, newNext dbtypes.Chai
 }
 
 // retrievePoolInfo returns ticket pool info for block height ind
-func retrievePoolInfo(ctx context.Context, db *sql.DB, ind int64) (*apitypes.TicketPoolInfo, error) {
+func (q queries) retrievePoolInfo(ctx context.Context, db *sql.DB, ind int64) (*apitypes.TicketPoolInfo, error) {
 	tpi := &apitypes.TicketPoolInfo{
 		Height: uint32(ind),
 	}
 	var hash dbtypes.ChainHash // trash?
 	var winners dbtypes.ChainHashArray
 	var val int64
-	err := db.QueryRowContext(ctx, internal.SelectPoolInfoByHeight, ind).Scan(&hash, &tpi.Size,
+	err := db.QueryRowContext(ctx, q.SelectPoolInfoByHeight, ind).Scan(&hash, &tpi.Size,
 		&val, &winners)
 	tpi.Value = toCoin(val)
 	tpi.ValAvg = tpi.Value / float64(tpi.Size)
@@ -4247,7 +4562,7 @@ func retrievePoolInfo(ctx context.Context, db *sql.DB, ind int64) (*apitypes.Tic
 
 // retrievePoolInfoRange returns an array of apitypes.TicketPoolInfo for block
 // range ind0 to ind1 and a non-nil error on success
-func retrievePoolInfoRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]apitypes.TicketPoolInfo, []dbtypes.ChainHash, error) {
+func (q queries) retrievePoolInfoRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]apitypes.TicketPoolInfo, []dbtypes.ChainHash, error) {
 	N := ind1 - ind0 + 1
 	if N == 0 {
 		return []apitypes.TicketPoolInfo{}, []dbtypes.ChainHash{}, nil
@@ -4260,7 +4575,7 @@ func retrievePoolInfoRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([
 	tpis := make([]apitypes.TicketPoolInfo, 0, N)
 	hashes := make([]dbtypes.ChainHash, 0, N)
 
-	stmt, err := db.PrepareContext(ctx, internal.SelectPoolInfoRange)
+	stmt, err := db.PrepareContext(ctx, q.SelectPoolInfoRange)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -4301,7 +4616,7 @@ func retrievePoolInfoRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([
 
 // retrievePoolValAndSizeRange returns an array each of the pool values and
 // sizes for block range ind0 to ind1.
-func retrievePoolValAndSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]float64, []uint32, error) {
+func (q queries) retrievePoolValAndSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]float64, []uint32, error) {
 	N := ind1 - ind0 + 1
 	if N == 0 {
 		return []float64{}, []uint32{}, nil
@@ -4314,7 +4629,7 @@ func retrievePoolValAndSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int
 	poolvals := make([]float64, 0, N)
 	poolsizes := make([]uint32, 0, N)
 
-	stmt, err := db.Prepare(internal.SelectPoolValSizeRange)
+	stmt, err := db.Prepare(q.SelectPoolValSizeRange)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -4350,14 +4665,14 @@ func retrievePoolValAndSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int
 }
 
 // retrieveBlockSummary fetches basic block data for block ind.
-func retrieveBlockSummary(ctx context.Context, db *sql.DB, ind int64) (*apitypes.BlockDataBasic, error) {
+func (q queries) retrieveBlockSummary(ctx context.Context, db *sql.DB, ind int64) (*apitypes.BlockDataBasic, error) {
 	bd := apitypes.NewBlockDataBasic()
 	var winners dbtypes.ChainHashArray
 	var isValid bool
 	var val, sbits int64
 	var hash dbtypes.ChainHash
 	var timestamp dbtypes.TimeDef
-	err := db.QueryRowContext(ctx, internal.SelectBlockDataByHeight, ind).Scan(
+	err := db.QueryRowContext(ctx, q.SelectBlockDataByHeight, ind).Scan(
 		&hash, &bd.Height, &bd.Size, &bd.Difficulty, &sbits, &timestamp,
 		&bd.PoolInfo.Size, &val, &winners, &isValid)
 	if err != nil {
@@ -4377,14 +4692,14 @@ func retrieveBlockSummary(ctx context.Context, db *sql.DB, ind int64) (*apitypes
 }
 
 // retrieveBlockSummaryByHash fetches basic block data for block hash.
-func retrieveBlockSummaryByHash(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (*apitypes.BlockDataBasic, error) {
+func (q queries) retrieveBlockSummaryByHash(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (*apitypes.BlockDataBasic, error) {
 	bd := apitypes.NewBlockDataBasic()
 	var winners dbtypes.ChainHashArray
 	var isMainchain, isValid bool
 	var timestamp dbtypes.TimeDef
 	var val, psize sql.NullInt64 // pool value and size are only stored for mainchain blocks
 	var sbits int64
-	err := db.QueryRowContext(ctx, internal.SelectBlockDataByHash, hash).Scan(
+	err := db.QueryRowContext(ctx, q.SelectBlockDataByHash, hash).Scan(
 		&bd.Height, &bd.Size, &bd.Difficulty, &sbits, &timestamp,
 		&psize, &val, &winners, &isMainchain, &isValid)
 	if err != nil {
@@ -4405,7 +4720,7 @@ func retrieveBlockSummaryByHash(ctx context.Context, db *sql.DB, hash dbtypes.Ch
 
 // retrieveBlockSummaryRange fetches basic block data for the blocks in range
 // (ind0, ind1).
-func retrieveBlockSummaryRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]*apitypes.BlockDataBasic, error) {
+func (q queries) retrieveBlockSummaryRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]*apitypes.BlockDataBasic, error) {
 	var desc bool
 	low, high := ind0, ind1
 	if low > high {
@@ -4416,9 +4731,9 @@ func retrieveBlockSummaryRange(ctx context.Context, db *sql.DB, ind0, ind1 int64
 	if expCount <= 0 {
 		return nil, fmt.Errorf("invalid block range %d-%d", ind0, ind1)
 	}
-	tmpl := internal.SelectBlockDataRange
+	tmpl := q.SelectBlockDataRange
 	if desc {
-		tmpl = internal.SelectBlockDataRangeDesc
+		tmpl = q.SelectBlockDataRangeDesc
 	}
 	rows, err := db.QueryContext(ctx, tmpl, low, high)
 	if err != nil {
@@ -4461,7 +4776,7 @@ func retrieveBlockSummaryRange(ctx context.Context, db *sql.DB, ind0, ind1 int64
 
 // retrieveBlockSummaryRangeStepped fetches basic block data for every step'th
 // block in range (ind0, ind1).
-func retrieveBlockSummaryRangeStepped(ctx context.Context, db *sql.DB, ind0, ind1, step int64) ([]*apitypes.BlockDataBasic, error) {
+func (q queries) retrieveBlockSummaryRangeStepped(ctx context.Context, db *sql.DB, ind0, ind1, step int64) ([]*apitypes.BlockDataBasic, error) {
 	var desc bool
 	stepMod := ind0 % step
 	low, high := ind0, ind1
@@ -4473,9 +4788,9 @@ func retrieveBlockSummaryRangeStepped(ctx context.Context, db *sql.DB, ind0, ind
 	if expCount <= 0 {
 		return nil, fmt.Errorf("invalid block range %d-%d", ind0, ind1)
 	}
-	tmpl := internal.SelectBlockDataRangeWithSkip
+	tmpl := q.SelectBlockDataRangeWithSkip
 	if desc {
-		tmpl = internal.SelectBlockDataRangeWithSkipDesc
+		tmpl = q.SelectBlockDataRangeWithSkipDesc
 	}
 	query := fmt.Sprintf(tmpl, step, stepMod)
 	rows, err := db.QueryContext(ctx, query, low, high)
@@ -4518,9 +4833,9 @@ func retrieveBlockSummaryRangeStepped(ctx context.Context, db *sql.DB, ind0, ind
 }
 
 // retrieveBlockSize return the size of block at height ind.
-func retrieveBlockSize(ctx context.Context, db *sql.DB, ind int64) (int32, error) {
+func (q queries) retrieveBlockSize(ctx context.Context, db *sql.DB, ind int64) (int32, error) {
 	var blockSize int32
-	err := db.QueryRowContext(ctx, internal.SelectBlockSizeByHeight, ind).Scan(&blockSize)
+	err := db.QueryRowContext(ctx, q.SelectBlockSizeByHeight, ind).Scan(&blockSize)
 	if err != nil {
 		return -1, fmt.Errorf("unable to scan for block size: %w", err)
 	}
@@ -4529,7 +4844,7 @@ func retrieveBlockSize(ctx context.Context, db *sql.DB, ind int64) (int32, error
 }
 
 // retrieveBlockSizeRange returns an array of block sizes for block range ind0 to ind1
-func retrieveBlockSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]int32, error) {
+func (q queries) retrieveBlockSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]int32, error) {
 	N := ind1 - ind0 + 1
 	if N == 0 {
 		return []int32{}, nil
@@ -4541,7 +4856,7 @@ func retrieveBlockSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) (
 
 	blockSizes := make([]int32, 0, N)
 
-	stmt, err := db.Prepare(internal.SelectBlockSizeRange)
+	stmt, err := db.Prepare(q.SelectBlockSizeRange)
 	if err != nil {
 		return nil, err
 	}
@@ -4571,23 +4886,23 @@ func retrieveBlockSizeRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) (
 
 // retrieveSDiff returns the stake difficulty for block at the specified chain
 // height.
-func retrieveSDiff(ctx context.Context, db *sql.DB, ind int64) (float64, error) {
+func (q queries) retrieveSDiff(ctx context.Context, db *sql.DB, ind int64) (float64, error) {
 	var sbits int64
-	err := db.QueryRowContext(ctx, internal.SelectSBitsByHeight, ind).Scan(&sbits)
+	err := db.QueryRowContext(ctx, q.SelectSBitsByHeight, ind).Scan(&sbits)
 	return toCoin(sbits), err
 }
 
 // retrieveSBitsByHash returns the stake difficulty in atoms for the specified
 // block.
-func retrieveSBitsByHash(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (int64, error) {
+func (q queries) retrieveSBitsByHash(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash) (int64, error) {
 	var sbits int64
-	err := db.QueryRowContext(ctx, internal.SelectSBitsByHash, hash).Scan(&sbits)
+	err := db.QueryRowContext(ctx, q.SelectSBitsByHash, hash).Scan(&sbits)
 	return sbits, err
 }
 
 // retrieveSDiffRange returns an array of stake difficulties for block range
 // ind0 to ind1.
-func retrieveSDiffRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]float64, error) {
+func (q queries) retrieveSDiffRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]float64, error) {
 	N := ind1 - ind0 + 1
 	if N == 0 {
 		return []float64{}, nil
@@ -4598,7 +4913,7 @@ func retrieveSDiffRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]fl
 	}
 	sdiffs := make([]float64, 0, N)
 
-	stmt, err := db.Prepare(internal.SelectSBitsRange)
+	stmt, err := db.Prepare(q.SelectSBitsRange)
 	if err != nil {
 		return nil, err
 	}
@@ -4627,7 +4942,7 @@ func retrieveSDiffRange(ctx context.Context, db *sql.DB, ind0, ind1 int64) ([]fl
 }
 
 // retrieveLatestBlockSummary returns the block summary for the best block.
-func retrieveLatestBlockSummary(ctx context.Context, db *sql.DB) (*apitypes.BlockDataBasic, error) {
+func (q queries) retrieveLatestBlockSummary(ctx context.Context, db *sql.DB) (*apitypes.BlockDataBasic, error) {
 	bd := apitypes.NewBlockDataBasic()
 
 	var winners dbtypes.ChainHashArray
@@ -4635,7 +4950,7 @@ func retrieveLatestBlockSummary(ctx context.Context, db *sql.DB) (*apitypes.Bloc
 	var isValid bool
 	var val, sbits int64
 	var hash dbtypes.ChainHash
-	err := db.QueryRowContext(ctx, internal.SelectBlockDataBest).Scan(
+	err := db.QueryRowContext(ctx, q.SelectBlockDataBest).Scan(
 		&hash, &bd.Height, &bd.Size, &bd.Difficulty, &sbits, &timestamp,
 		&bd.PoolInfo.Size, &val, &winners, &isValid)
 	if err != nil {
@@ -4655,9 +4970,9 @@ func retrieveLatestBlockSummary(ctx context.Context, db *sql.DB) (*apitypes.Bloc
 
 // retrieveDiff returns the difficulty for the first block mined after the
 // provided UNIX timestamp.
-func retrieveDiff(ctx context.Context, db *sql.DB, timestamp int64) (float64, error) {
+func (q queries) retrieveDiff(ctx context.Context, db *sql.DB, timestamp int64) (float64, error) {
 	var diff float64
 	tDef := dbtypes.NewTimeDefFromUNIX(timestamp)
-	err := db.QueryRowContext(ctx, internal.SelectDiffByTime, tDef).Scan(&diff)
+	err := db.QueryRowContext(ctx, q.SelectDiffByTime, tDef).Scan(&diff)
 	return diff, err
 }
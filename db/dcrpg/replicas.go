@@ -0,0 +1,168 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+const (
+	// replicaCheckInterval is how long a replica's best block, as recorded in
+	// its meta table, is trusted before it is queried again.
+	replicaCheckInterval = 2 * time.Second
+
+	// replicaCheckTimeout is the timeout for the query of a replica's best
+	// block.
+	replicaCheckTimeout = 3 * time.Second
+)
+
+// replicaDB is a connection to a read-only replica of the primary database,
+// with a cached best block used to determine if the replica is behind the
+// primary.
+type replicaDB struct {
+	db *sql.DB
+	id int // for logging, the 1-based index of the replica
+
+	mtx      sync.Mutex
+	height   int64 // -1 until the first check
+	hash     dbtypes.ChainHash
+	err      error
+	checked  time.Time
+	checking bool
+}
+
+// bestBlock returns the cached best block height and hash in the replica's
+// meta table, and any error from the query of the block. It does not wait for
+// the query. If the block was checked more than replicaCheckInterval ago, it is
+// checked again in a new goroutine, unless a check is already in progress, so
+// that a slow or hung replica does not hold up the queries choosing a replica.
+func (r *replicaDB) bestBlock() (int64, dbtypes.ChainHash, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if !r.checking && time.Since(r.checked) >= replicaCheckInterval {
+		r.checking = true
+		go r.checkBestBlock()
+	}
+	return r.height, r.hash, r.err
+}
+
+// checkBestBlock queries the replica's best block and caches the result.
+func (r *replicaDB) checkBestBlock() {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaCheckTimeout)
+	defer cancel()
+	hash, height, err := pgQueries.dbBestBlock(ctx, r.db)
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err != nil {
+		if r.err == nil {
+			log.Warnf("Read replica %d is unavailable: %v", r.id, err)
+		}
+		height = -1
+	} else if r.err != nil {
+		log.Infof("Read replica %d is available again.", r.id)
+	}
+	r.height, r.hash, r.err, r.checked = height, hash, err, time.Now()
+	r.checking = false
+}
+
+// replicaSet is a set of read-only replicas that are used in turn.
+type replicaSet struct {
+	replicas []*replicaDB
+	next     uint32
+}
+
+// connectReplicas connects to the replicas given by the PostgreSQL connection
+// strings, and verifies that each replica is of the same network as the
+// primary database. If sq is not nil, slow queries on the replicas are
+// recorded by sq.
+func connectReplicas(dsns []string, netName string, sq *slowQueryLog) (*replicaSet, error) {
+	rs := &replicaSet{
+		replicas: make([]*replicaDB, 0, len(dsns)),
+	}
+	for i, dsn := range dsns {
+		db, err := connectDSN(dsn, sq)
+		if err != nil {
+			rs.close()
+			return nil, fmt.Errorf("failed to connect to read replica %d: %w", i+1, err)
+		}
+		var replicaNet string
+		if err = db.QueryRow(internal.SelectMetaDBNetName).Scan(&replicaNet); err != nil {
+			db.Close()
+			rs.close()
+			return nil, fmt.Errorf("failed to check the network of read replica %d: %w", i+1, err)
+		}
+		if replicaNet != netName {
+			db.Close()
+			rs.close()
+			return nil, fmt.Errorf("read replica %d is for network %s, not %s",
+				i+1, replicaNet, netName)
+		}
+		rs.replicas = append(rs.replicas, &replicaDB{db: db, id: i + 1, height: -1})
+	}
+	return rs, nil
+}
+
+// pick returns the next replica that is not behind the given best block, or
+// nil if there are none. A replica at the same height is behind unless it has
+// the same best block, since the primary may have reorganized to a block that
+// the replica does not have yet.
+func (rs *replicaSet) pick(bestHeight int64, bestHash dbtypes.ChainHash) *sql.DB {
+	n := uint32(len(rs.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := atomic.AddUint32(&rs.next, 1)
+	for i := uint32(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		height, hash, err := r.bestBlock()
+		if err != nil || height < bestHeight || (height == bestHeight && hash != bestHash) {
+			continue
+		}
+		return r.db
+	}
+	return nil
+}
+
+// close closes the connections to all replicas.
+func (rs *replicaSet) close() {
+	for _, r := range rs.replicas {
+		if err := r.db.Close(); err != nil {
+			log.Errorf("Failed to close read replica %d: %v", r.id, err)
+		}
+	}
+}
+
+// readDB returns the database handle to use for a read-only query. A read
+// replica is used if one is configured that has caught up to the best block
+// of the primary database. Otherwise, the primary database is used. Queries
+// that must observe the results of recent writes, such as those used while
+// storing a block, should use pgb.db directly. A method making more than one
+// query should get the handle once, so that all of its queries are answered
+// by the same database.
+func (pgb *ChainDB) readDB() *sql.DB {
+	if pgb.replicas == nil || pgb.InReorg.Load() {
+		return pgb.db
+	}
+	if db := pgb.replicas.pick(pgb.bestBlock.heightHash()); db != nil {
+		return db
+	}
+	return pgb.db
+}
+
+// NumReplicas returns the number of configured read replicas.
+func (pgb *ChainDB) NumReplicas() int {
+	if pgb.replicas == nil {
+		return 0
+	}
+	return len(pgb.replicas.replicas)
+}
@@ -0,0 +1,91 @@
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// hungConnector is a driver.Connector for a replica that does not respond
+// until released, and then fails.
+type hungConnector struct {
+	release  chan struct{}
+	connects int32
+}
+
+func (c *hungConnector) Connect(context.Context) (driver.Conn, error) {
+	atomic.AddInt32(&c.connects, 1)
+	<-c.release
+	return nil, errors.New("replica unavailable")
+}
+
+func (c *hungConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestReplicaBestHeight(t *testing.T) {
+	connector := &hungConnector{release: make(chan struct{})}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	r := &replicaDB{db: db, id: 1, height: -1}
+	rs := &replicaSet{replicas: []*replicaDB{r}}
+
+	// Callers get the cached height without waiting for the hung replica, and
+	// only one check is made at a time.
+	for i := 0; i < 3; i++ {
+		if height, _, err := r.bestBlock(); height != -1 || err != nil {
+			t.Fatalf("unexpected height %d (%v) before the first check", height, err)
+		}
+		if rs.pick(0, dbtypes.ChainHash{}) != nil {
+			t.Fatalf("picked a replica of unknown height")
+		}
+	}
+	close(connector.release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mtx.Lock()
+		checking := r.checking
+		r.mtx.Unlock()
+		if !checking {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("height check did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&connector.connects); n != 1 {
+		t.Errorf("expected 1 height check, got %d", n)
+	}
+	if height, _, err := r.bestBlock(); height != -1 || err == nil {
+		t.Errorf("expected an error from the failed check, got height %d", height)
+	}
+}
+
+func TestReplicaPick(t *testing.T) {
+	// The replicas' best blocks were just checked, so they are not queried.
+	hash, other := dbtypes.ChainHash{1}, dbtypes.ChainHash{2}
+	newReplica := func(id int, height int64, hash dbtypes.ChainHash) *replicaDB {
+		return &replicaDB{db: new(sql.DB), id: id, height: height, hash: hash, checked: time.Now()}
+	}
+	behind, reorged, caughtUp := newReplica(1, 9, hash), newReplica(2, 10, other), newReplica(3, 10, hash)
+	rs := &replicaSet{replicas: []*replicaDB{behind, reorged, caughtUp}}
+	for i := 0; i < 3; i++ {
+		if db := rs.pick(10, hash); db != caughtUp.db {
+			t.Fatalf("picked a replica that is behind")
+		}
+	}
+	if db := rs.pick(10, dbtypes.ChainHash{3}); db != nil {
+		t.Errorf("picked a replica without the best block")
+	}
+	if db := rs.pick(9, other); db == behind.db {
+		t.Errorf("picked a replica on another chain")
+	}
+}
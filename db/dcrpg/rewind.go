@@ -39,6 +39,7 @@ package dcrpg
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -47,29 +48,29 @@ import (
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 )
 
-func deleteMissesForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteMisses, "failed to delete misses", hash)
+func (q queries) deleteMissesForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteMisses, "failed to delete misses", hash)
 }
 
-func deleteVotesForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteVotes, "failed to delete votes", hash)
+func (q queries) deleteVotesForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteVotes, "failed to delete votes", hash)
 }
 
-func deleteTicketsForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteTickets, "failed to delete tickets", hash)
+func (q queries) deleteTicketsForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteTickets, "failed to delete tickets", hash)
 }
 
-func deleteTreasuryTxnsForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteTreasuryTxns, "failed to delete treasury txns", hash)
+func (q queries) deleteTreasuryTxnsForBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteTreasuryTxns, "failed to delete treasury txns", hash)
 }
 
-func deleteSwapsForBlockHeight(dbTx SqlExecutor, height int64) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteSwaps, "failed to delete swaps", height)
+func (q queries) deleteSwapsForBlockHeight(dbTx SqlExecutor, height int64) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteSwaps, "failed to delete swaps", height)
 }
 
-func deleteTransactionsForBlock(dbTx *sql.Tx, hash dbtypes.ChainHash) (txRowIds []int64, err error) {
+func (q queries) deleteTransactionsForBlock(dbTx *sql.Tx, hash dbtypes.ChainHash) (txRowIds []int64, err error) {
 	var rows *sql.Rows
-	rows, err = dbTx.Query(internal.DeleteTransactionsSimple, hash)
+	rows, err = dbTx.Query(q.DeleteTransactionsSimple, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -94,41 +95,82 @@ func deleteTransactionsForBlock(dbTx *sql.Tx, hash dbtypes.ChainHash) (txRowIds
 // 	return sqlExec(dbTx, internal.DeleteVouts, "failed to delete vouts", hash)
 // }
 
-func deleteVoutsForBlockSubQry(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteVoutsSubQry, "failed to delete vouts", hash)
+func (q queries) deleteVoutsForBlockSubQry(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteVoutsSubQry, "failed to delete vouts", hash)
 }
 
-func deleteVinsForBlockSubQry(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteVinsSubQry, "failed to delete vins", hash)
+func (q queries) deleteVinsForBlockSubQry(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteVinsSubQry, "failed to delete vins", hash)
 }
 
-func deleteAddressesForBlockSubQry(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteAddressesSubQry, "failed to delete addresses", hash)
+func (q queries) deleteAddressesForBlockSubQry(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteAddressesSubQry, "failed to delete addresses", hash)
 }
 
-func deleteBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
-	return sqlExec(dbTx, internal.DeleteBlock, "failed to delete block", hash)
+// blockPartitionKeys are the values of the partition keys of a block's rows
+// in the partitioned vins, vouts, and addresses tables. They are used to limit
+// deletions to the partitions holding the block's data.
+type blockPartitionKeys struct {
+	blockTime            time.Time
+	minVoutID, maxVoutID sql.NullInt64
 }
 
-func deleteBlockFromChain(dbTx *sql.Tx, hash dbtypes.ChainHash) (err error) {
+// retrieveBlockPartitionKeys gets the block time and range of vouts row IDs
+// for the block with the given hash.
+func retrieveBlockPartitionKeys(dbTx SqlQueryer, hash dbtypes.ChainHash) (*blockPartitionKeys, error) {
+	var keys blockPartitionKeys
+	err := dbTx.QueryRow(internal.SelectBlockTimeByHash, hash).Scan(&keys.blockTime)
+	if err != nil {
+		return nil, err
+	}
+	err = dbTx.QueryRow(internal.SelectVoutIDRangeForBlock, hash).Scan(&keys.minVoutID, &keys.maxVoutID)
+	if err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+func deleteVoutsForBlockPartitioned(dbTx SqlExecutor, hash dbtypes.ChainHash, keys *blockPartitionKeys) (rowsDeleted int64, err error) {
+	if !keys.minVoutID.Valid {
+		return 0, nil // no vouts
+	}
+	return sqlExec(dbTx, internal.DeleteVoutsSubQryPartitioned, "failed to delete vouts",
+		hash, keys.minVoutID.Int64, keys.maxVoutID.Int64)
+}
+
+func deleteVinsForBlockPartitioned(dbTx SqlExecutor, hash dbtypes.ChainHash, keys *blockPartitionKeys) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, internal.DeleteVinsSubQryPartitioned, "failed to delete vins",
+		hash, keys.blockTime)
+}
+
+func deleteAddressesForBlockPartitioned(dbTx SqlExecutor, hash dbtypes.ChainHash, keys *blockPartitionKeys) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, internal.DeleteAddressesSubQryPartitioned, "failed to delete addresses",
+		hash, keys.blockTime)
+}
+
+func (q queries) deleteBlock(dbTx SqlExecutor, hash dbtypes.ChainHash) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, q.DeleteBlock, "failed to delete block", hash)
+}
+
+func (q queries) deleteBlockFromChain(dbTx *sql.Tx, hash dbtypes.ChainHash) (err error) {
 	// Delete the row from block_chain where this_hash is the specified hash,
 	// returning the previous block hash in the chain.
 	var prevHash dbtypes.ChainHash
-	err = dbTx.QueryRow(internal.DeleteBlockFromChain, hash).Scan(&prevHash)
+	err = dbTx.QueryRow(q.DeleteBlockFromChain, hash).Scan(&prevHash)
 	if err != nil {
 		// If a row with this_hash was not found, and thus prev_hash is not set,
 		// attempt to locate a row with next_hash set to the hash of this block,
 		// and set it to the empty hash.
 		if err == sql.ErrNoRows {
 			log.Warnf("Block %v not found in block_chain.this_hash column.", hash)
-			err = updateBlockNextByNextHash(dbTx, hash, dbtypes.ChainHash{})
+			err = q.updateBlockNextByNextHash(dbTx, hash, dbtypes.ChainHash{})
 		}
 		return
 	}
 
 	// For any row where next_hash is the prev_hash of the removed row, set
 	// next_hash to and empty string since that block is no longer in the chain.
-	return updateBlockNextByHash(dbTx, prevHash, dbtypes.ChainHash{})
+	return q.updateBlockNextByHash(dbTx, prevHash, dbtypes.ChainHash{})
 }
 
 // retrieveTxsBestBlockMainchain returns the best mainchain block's height from
@@ -147,9 +189,11 @@ func retrieveTxsBestBlockMainchain(ctx context.Context, db *sql.DB) (height int6
 
 // deleteBlockData removes all data for the specified block from every table.
 // Data are removed from tables in the following order: vins, vouts, addresses,
-// transactions, tickets, votes, misses, blocks, block_chain.
+// transactions, tickets, votes, misses, blocks, block_chain. If the vins, vouts,
+// and addresses tables are partitioned, their rows are deleted from only the
+// partitions that may hold the block's data.
 // WARNING: When no indexes are present, these queries are VERY SLOW.
-func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, height int64) (res dbtypes.DeletionSummary, err error) {
+func (q queries) deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, height int64) (res dbtypes.DeletionSummary, err error) {
 	// The data purge is an all or nothing operation (no partial removal of
 	// data), so use a common sql.Tx for all deletions, and Commit in this
 	// function rather after each deletion.
@@ -162,8 +206,35 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 
 	res.Timings = new(dbtypes.DeletionSummary)
 
+	// With partitioned tables, get the block's partition keys. If the block is
+	// not in the blocks table, fall back to searching all partitions. Only
+	// PostgreSQL tables are partitioned.
+	var partKeys *blockPartitionKeys
+	var partitioned bool
+	if q.postgres() {
+		if partitioned, err = tableIsPartitioned(dbTx, "vins"); err != nil {
+			err = fmt.Errorf(`tableIsPartitioned failed with "%v". Rollback: %v`,
+				err, dbTx.Rollback())
+			return
+		}
+	}
+	if partitioned {
+		partKeys, err = retrieveBlockPartitionKeys(dbTx, hash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf(`retrieveBlockPartitionKeys failed with "%v". Rollback: %v`,
+				err, dbTx.Rollback())
+			return
+		}
+		err = nil
+	}
+
 	start := time.Now()
-	if res.Vins, err = deleteVinsForBlockSubQry(dbTx, hash); err != nil {
+	if partKeys != nil {
+		res.Vins, err = deleteVinsForBlockPartitioned(dbTx, hash, partKeys)
+	} else {
+		res.Vins, err = q.deleteVinsForBlockSubQry(dbTx, hash)
+	}
+	if err != nil {
 		err = fmt.Errorf(`deleteVinsForBlockSubQry failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -171,7 +242,12 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Vins = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Vouts, err = deleteVoutsForBlockSubQry(dbTx, hash); err != nil {
+	if partKeys != nil {
+		res.Vouts, err = deleteVoutsForBlockPartitioned(dbTx, hash, partKeys)
+	} else {
+		res.Vouts, err = q.deleteVoutsForBlockSubQry(dbTx, hash)
+	}
+	if err != nil {
 		err = fmt.Errorf(`deleteVoutsForBlockSubQry failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -179,7 +255,12 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Vouts = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Addresses, err = deleteAddressesForBlockSubQry(dbTx, hash); err != nil {
+	if partKeys != nil {
+		res.Addresses, err = deleteAddressesForBlockPartitioned(dbTx, hash, partKeys)
+	} else {
+		res.Addresses, err = q.deleteAddressesForBlockSubQry(dbTx, hash)
+	}
+	if err != nil {
 		err = fmt.Errorf(`deleteAddressesForBlockSubQry failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -191,7 +272,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	// row IDs for a transaction.
 	start = time.Now()
 	var txIDsRemoved []int64
-	if txIDsRemoved, err = deleteTransactionsForBlock(dbTx, hash); err != nil {
+	if txIDsRemoved, err = q.deleteTransactionsForBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteTransactionsForBlock failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -203,7 +284,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	start = time.Now()
 	var voutsReset int64
 	// voutsReset, err = clearVoutAllSpendTxRowIDs(dbTx, hash) // would need to come *before* removing the transactions rows
-	voutsReset, err = resetSpendingForVoutsByTxRowID(dbTx, txIDsRemoved)
+	voutsReset, err = q.resetSpendingForVoutsByTxRowID(dbTx, txIDsRemoved)
 	if err != nil {
 		err = fmt.Errorf(`resetSpendingForVoutsByTxRowID failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
@@ -213,7 +294,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.VoutSpendTxIDs = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Tickets, err = deleteTicketsForBlock(dbTx, hash); err != nil {
+	if res.Tickets, err = q.deleteTicketsForBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteTicketsForBlock failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -221,7 +302,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Tickets = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Votes, err = deleteVotesForBlock(dbTx, hash); err != nil {
+	if res.Votes, err = q.deleteVotesForBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteVotesForBlock failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -229,7 +310,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Votes = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Misses, err = deleteMissesForBlock(dbTx, hash); err != nil {
+	if res.Misses, err = q.deleteMissesForBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteMissesForBlock failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -237,7 +318,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Misses = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Treasury, err = deleteTreasuryTxnsForBlock(dbTx, hash); err != nil {
+	if res.Treasury, err = q.deleteTreasuryTxnsForBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteTreasuryTxnsForBlock failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -245,7 +326,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Treasury = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Swaps, err = deleteSwapsForBlockHeight(dbTx, height); err != nil {
+	if res.Swaps, err = q.deleteSwapsForBlockHeight(dbTx, height); err != nil {
 		err = fmt.Errorf(`deleteSwapsForBlockHeight failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -253,7 +334,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 	res.Timings.Swaps = int64(time.Since(start))
 
 	start = time.Now()
-	if res.Blocks, err = deleteBlock(dbTx, hash); err != nil {
+	if res.Blocks, err = q.deleteBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteBlock failed with "%v". Rollback: %v`,
 			err, dbTx.Rollback())
 		return
@@ -263,7 +344,7 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 			res.Blocks)
 	}
 
-	err = deleteBlockFromChain(dbTx, hash)
+	err = q.deleteBlockFromChain(dbTx, hash)
 	res.Timings.Blocks = int64(time.Since(start))
 	switch err {
 	case sql.ErrNoRows:
@@ -294,39 +375,39 @@ func deleteBlockData(ctx context.Context, db *sql.DB, hash dbtypes.ChainHash, he
 // table via DeleteBlockData. The returned height and hash are for the best
 // block after successful data removal, or the initial best block if removal
 // fails as indicated by a non-nil error value.
-func deleteBestBlock(ctx context.Context, db *sql.DB) (res dbtypes.DeletionSummary, height int64, hash dbtypes.ChainHash, err error) {
-	height, hash, err = retrieveBestBlock(ctx, db)
+func (q queries) deleteBestBlock(ctx context.Context, db *sql.DB) (res dbtypes.DeletionSummary, height int64, hash dbtypes.ChainHash, err error) {
+	height, hash, err = q.retrieveBestBlock(ctx, db)
 	if err != nil {
 		return
 	}
 
-	res, err = deleteBlockData(ctx, db, hash, height)
+	res, err = q.deleteBlockData(ctx, db, hash, height)
 	if err != nil {
 		return
 	}
 
-	height, hash, err = retrieveBestBlock(ctx, db)
+	height, hash, err = q.retrieveBestBlock(ctx, db)
 	if err != nil {
 		return
 	}
 
-	err = setDBBestBlock(db, hash, height)
+	err = q.setDBBestBlock(db, hash, height)
 	return
 }
 
 // deleteBlocks removes all data for the N best blocks in the DB from every
 // table via repeated calls to DeleteBestBlock.
-func deleteBlocks(ctx context.Context, N int64, db *sql.DB) (res []dbtypes.DeletionSummary, height int64, hash dbtypes.ChainHash, err error) {
+func (q queries) deleteBlocks(ctx context.Context, N int64, db *sql.DB) (res []dbtypes.DeletionSummary, height int64, hash dbtypes.ChainHash, err error) {
 	// If N is less than 1, get the current best block height and hash, then
 	// return.
 	if N < 1 {
-		height, hash, err = retrieveBestBlock(ctx, db)
+		height, hash, err = q.retrieveBestBlock(ctx, db)
 		return
 	}
 
 	for i := int64(0); i < N; i++ {
 		var resi dbtypes.DeletionSummary
-		resi, height, hash, err = deleteBestBlock(ctx, db)
+		resi, height, hash, err = q.deleteBestBlock(ctx, db)
 		if err != nil {
 			return
 		}
@@ -0,0 +1,380 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// slowQueryHistory is the number of most recent slow queries that are
+	// summarized by the slow query report.
+	slowQueryHistory = 1000
+
+	// defaultSlowQueryTopN is the number of statements in the slow query report
+	// if SlowQueryCfg.TopN is not set.
+	defaultSlowQueryTopN = 20
+
+	// maxSlowQuerySQLLen is the maximum length of the SQL text of a statement
+	// that is logged and included in the slow query report.
+	maxSlowQuerySQLLen = 240
+)
+
+// SlowQueryCfg configures the slow query log of a ChainDB.
+type SlowQueryCfg struct {
+	// Threshold is the duration above which a query is logged and recorded
+	// for the slow query report.
+	Threshold time.Duration
+	// TopN is the number of statements in the slow query report.
+	TopN int
+}
+
+// SlowQueryStats summarizes the recent slow queries of a statement by a
+// ChainDB method. The statement ID and args fingerprint are hashes of the SQL
+// text and the query arguments.
+type SlowQueryStats struct {
+	Method    string    `json:"method"`
+	Statement string    `json:"statement_id"`
+	SQL       string    `json:"sql"`
+	Count     int       `json:"count"`
+	TotalMs   float64   `json:"total_ms"`
+	MaxMs     float64   `json:"max_ms"`
+	MaxArgs   string    `json:"max_args"` // args fingerprint of the slowest query
+	MaxRows   int64     `json:"max_rows"` // rows of the slowest query
+	Last      time.Time `json:"last"`
+}
+
+// queryMethodKey is the context key for the name of the ChainDB method that
+// issued a query. See queryCtx.
+type queryMethodKey struct{}
+
+func queryMethod(ctx context.Context) string {
+	if method, ok := ctx.Value(queryMethodKey{}).(string); ok {
+		return method
+	}
+	return "-"
+}
+
+// slowQuery is a query that took longer than the slow query threshold.
+type slowQuery struct {
+	method   string
+	stmtID   string
+	argsID   string
+	sql      string
+	duration time.Duration
+	rows     int64
+	time     time.Time
+}
+
+// slowQueryLog logs the queries that are slower than a threshold, and keeps
+// the most recent of them for the slow query report.
+type slowQueryLog struct {
+	threshold time.Duration
+	topN      int
+
+	mtx    sync.Mutex
+	recent []slowQuery // ring buffer of up to slowQueryHistory queries
+	next   int
+}
+
+func newSlowQueryLog(cfg *SlowQueryCfg) *slowQueryLog {
+	topN := cfg.TopN
+	if topN <= 0 {
+		topN = defaultSlowQueryTopN
+	}
+	return &slowQueryLog{
+		threshold: cfg.Threshold,
+		topN:      topN,
+		recent:    make([]slowQuery, 0, slowQueryHistory),
+	}
+}
+
+// record logs and stores the query if it took longer than the threshold.
+func (sq *slowQueryLog) record(ctx context.Context, query string, args []driver.NamedValue,
+	start time.Time, rows int64) {
+	duration := time.Since(start)
+	if duration < sq.threshold {
+		return
+	}
+
+	text := strings.Join(strings.Fields(query), " ")
+	if len(text) > maxSlowQuerySQLLen {
+		text = text[:maxSlowQuerySQLLen] + "..."
+	}
+	q := slowQuery{
+		method:   queryMethod(ctx),
+		stmtID:   fingerprint(query),
+		argsID:   argsFingerprint(args),
+		sql:      text,
+		duration: duration,
+		rows:     rows,
+		time:     time.Now(),
+	}
+	log.Warnf("Slow query (%v, %d rows) by %s, statement %s, args %s: %s",
+		duration, rows, q.method, q.stmtID, q.argsID, q.sql)
+
+	sq.mtx.Lock()
+	defer sq.mtx.Unlock()
+	if len(sq.recent) < slowQueryHistory {
+		sq.recent = append(sq.recent, q)
+		return
+	}
+	sq.recent[sq.next] = q
+	sq.next = (sq.next + 1) % slowQueryHistory
+}
+
+// report summarizes the recent slow queries by method and statement, sorted
+// by total duration, and returns the top N.
+func (sq *slowQueryLog) report() []*SlowQueryStats {
+	sq.mtx.Lock()
+	defer sq.mtx.Unlock()
+
+	type key struct{ method, stmtID string }
+	byStmt := make(map[key]*SlowQueryStats)
+	for i := range sq.recent {
+		q := &sq.recent[i]
+		ms := float64(q.duration) / float64(time.Millisecond)
+		k := key{q.method, q.stmtID}
+		s, found := byStmt[k]
+		if !found {
+			s = &SlowQueryStats{
+				Method:    q.method,
+				Statement: q.stmtID,
+				SQL:       q.sql,
+			}
+			byStmt[k] = s
+		}
+		s.Count++
+		s.TotalMs += ms
+		if ms > s.MaxMs {
+			s.MaxMs, s.MaxArgs, s.MaxRows = ms, q.argsID, q.rows
+		}
+		if q.time.After(s.Last) {
+			s.Last = q.time
+		}
+	}
+
+	stats := make([]*SlowQueryStats, 0, len(byStmt))
+	for _, s := range byStmt {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TotalMs > stats[j].TotalMs
+	})
+	if len(stats) > sq.topN {
+		stats = stats[:sq.topN]
+	}
+	return stats
+}
+
+// fingerprint is a short hash of the SQL text, ignoring whitespace.
+func fingerprint(query string) string {
+	h := fnv.New64a()
+	for _, f := range strings.Fields(query) {
+		h.Write([]byte(f))
+		h.Write([]byte{' '})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// argsFingerprint is a short hash of the query arguments, so that repeated
+// queries with the same arguments may be recognized without logging them.
+func argsFingerprint(args []driver.NamedValue) string {
+	h := fnv.New64a()
+	for _, arg := range args {
+		fmt.Fprintf(h, "%v\x00", arg.Value)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// SlowQueryReport returns a summary of the recent slow queries, grouped by
+// method and statement and sorted by total duration. This is nil if the slow
+// query log is not enabled.
+func (pgb *ChainDB) SlowQueryReport() []*SlowQueryStats {
+	if pgb.slowQueries == nil {
+		return nil
+	}
+	return pgb.slowQueries.report()
+}
+
+// LogSlowQueryReport logs the slow query report, if the slow query log is
+// enabled.
+func (pgb *ChainDB) LogSlowQueryReport() {
+	if pgb.slowQueries == nil {
+		log.Infof("The slow query log is not enabled.")
+		return
+	}
+	stats := pgb.slowQueries.report()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Top %d of the recent queries slower than %v:", len(stats),
+		pgb.slowQueries.threshold)
+	for i, s := range stats {
+		fmt.Fprintf(&sb, "\n%3d. %s, statement %s: %d queries, %.1f ms total, %.1f ms max (%d rows, args %s): %s",
+			i+1, s.Method, s.Statement, s.Count, s.TotalMs, s.MaxMs, s.MaxRows,
+			s.MaxArgs, s.SQL)
+	}
+	log.Info(sb.String())
+}
+
+// Ensure the slow query log's driver types satisfy the driver interfaces used
+// by database/sql.
+var (
+	_ driver.Connector = (*slowQueryConnector)(nil)
+	_ pqConn           = (*slowQueryConn)(nil)
+	_ ctxStmt          = (*slowQueryStmt)(nil)
+	_ driver.Rows      = (*slowQueryRows)(nil)
+)
+
+// slowQueryConnector is a driver.Connector for connections that record their
+// slow queries in a slowQueryLog.
+type slowQueryConnector struct {
+	driver.Connector
+	log *slowQueryLog
+}
+
+// pqConn is the set of driver interfaces implemented by lib/pq's connections
+// that are used by database/sql.
+type pqConn interface {
+	driver.Conn
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// Connect satisfies driver.Connector.
+func (c *slowQueryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pc, ok := conn.(pqConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unsupported driver connection type %T", conn)
+	}
+	return &slowQueryConn{pc, c.log}, nil
+}
+
+// slowQueryConn is a driver connection that records its slow queries in a
+// slowQueryLog, including those of its prepared statements.
+type slowQueryConn struct {
+	pqConn
+	log *slowQueryLog
+}
+
+// QueryContext satisfies driver.QueryerContext. The query is recorded when the
+// returned rows are closed, so that the duration includes reading the rows.
+func (c *slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.pqConn.QueryContext(ctx, query, args)
+	if err != nil {
+		c.log.record(ctx, query, args, start, 0)
+		return nil, err
+	}
+	return &slowQueryRows{rows, func(n int64) { c.log.record(ctx, query, args, start, n) }, 0}, nil
+}
+
+// ExecContext satisfies driver.ExecerContext.
+func (c *slowQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := c.pqConn.ExecContext(ctx, query, args)
+	c.log.record(ctx, query, args, start, rowsAffected(res, err))
+	return res, err
+}
+
+// PrepareContext satisfies driver.ConnPrepareContext.
+func (c *slowQueryConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.pqConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	ctxStmt, ok := stmt.(ctxStmt)
+	if !ok {
+		return stmt, nil
+	}
+	return &slowQueryStmt{ctxStmt, query, c.log}, nil
+}
+
+// ctxStmt is a driver.Stmt that supports contexts.
+type ctxStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+// slowQueryStmt is a prepared statement that records its slow queries in a
+// slowQueryLog.
+type slowQueryStmt struct {
+	ctxStmt
+	query string
+	log   *slowQueryLog
+}
+
+// QueryContext satisfies driver.StmtQueryContext.
+func (s *slowQueryStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.ctxStmt.QueryContext(ctx, args)
+	if err != nil {
+		s.log.record(ctx, s.query, args, start, 0)
+		return nil, err
+	}
+	return &slowQueryRows{rows, func(n int64) { s.log.record(ctx, s.query, args, start, n) }, 0}, nil
+}
+
+// ExecContext satisfies driver.StmtExecContext.
+func (s *slowQueryStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := s.ctxStmt.ExecContext(ctx, args)
+	s.log.record(ctx, s.query, args, start, rowsAffected(res, err))
+	return res, err
+}
+
+// slowQueryRows counts the rows read, and calls done with the count when the
+// rows are closed.
+type slowQueryRows struct {
+	driver.Rows
+	done func(rows int64)
+	n    int64
+}
+
+// Next satisfies driver.Rows.
+func (r *slowQueryRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.n++
+	}
+	return err
+}
+
+// Close satisfies driver.Rows.
+func (r *slowQueryRows) Close() error {
+	err := r.Rows.Close()
+	if r.done != nil {
+		r.done(r.n)
+		r.done = nil
+	}
+	return err
+}
+
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return 0
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package dcrpg
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := fingerprint("SELECT id FROM blocks\n\tWHERE hash = $1;")
+	b := fingerprint("SELECT id  FROM blocks WHERE hash = $1;")
+	if a != b {
+		t.Errorf("fingerprints differ by whitespace: %s != %s", a, b)
+	}
+	if c := fingerprint("SELECT id FROM blocks WHERE height = $1;"); c == a {
+		t.Errorf("fingerprints of different statements are equal: %s", c)
+	}
+
+	args1 := []driver.NamedValue{{Ordinal: 1, Value: "abc"}, {Ordinal: 2, Value: int64(1)}}
+	args2 := []driver.NamedValue{{Ordinal: 1, Value: "abc"}, {Ordinal: 2, Value: int64(2)}}
+	if argsFingerprint(args1) == argsFingerprint(args2) {
+		t.Errorf("fingerprints of different args are equal")
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	sq := newSlowQueryLog(&SlowQueryCfg{Threshold: time.Millisecond, TopN: 2})
+
+	ctxA := context.WithValue(context.Background(), queryMethodKey{}, "AddressHistory")
+	ctxB := context.WithValue(context.Background(), queryMethodKey{}, "BlockSummary")
+	ago := func(d time.Duration) time.Time { return time.Now().Add(-d) }
+
+	// Fast queries are not recorded.
+	sq.record(ctxA, "SELECT 1;", nil, time.Now(), 1)
+	if n := len(sq.report()); n != 0 {
+		t.Fatalf("expected no slow queries, got %d", n)
+	}
+
+	sq.record(ctxA, "SELECT * FROM addresses WHERE address = $1;", nil, ago(30*time.Millisecond), 10)
+	sq.record(ctxA, "SELECT * FROM addresses WHERE address = $1;", nil, ago(50*time.Millisecond), 20)
+	sq.record(ctxB, "SELECT * FROM blocks WHERE hash = $1;", nil, ago(60*time.Millisecond), 1)
+	sq.record(context.Background(), "SELECT * FROM meta;", nil, ago(5*time.Millisecond), 1)
+
+	stats := sq.report()
+	if len(stats) != 2 {
+		t.Fatalf("expected the top 2 statements, got %d", len(stats))
+	}
+	if stats[0].Method != "AddressHistory" || stats[0].Count != 2 || stats[0].MaxRows != 20 {
+		t.Errorf("unexpected first statement: %+v", stats[0])
+	}
+	if stats[0].TotalMs < 80 || stats[0].MaxMs < 50 {
+		t.Errorf("unexpected durations of first statement: %+v", stats[0])
+	}
+	if stats[1].Method != "BlockSummary" || stats[1].Count != 1 {
+		t.Errorf("unexpected second statement: %+v", stats[1])
+	}
+
+	// The oldest queries are replaced when the history is full.
+	for i := 0; i < slowQueryHistory; i++ {
+		sq.record(context.Background(), "SELECT * FROM meta;", nil, ago(2*time.Millisecond), 1)
+	}
+	stats = sq.report()
+	if len(stats) != 1 || stats[0].Method != "-" || stats[0].Count != slowQueryHistory {
+		t.Errorf("unexpected report after history rollover: %+v", stats)
+	}
+}
@@ -28,7 +28,10 @@ const (
 // SyncChainDB stores in the DB all blocks on the main chain available from the
 // RPC client. The table indexes may be force-dropped and recreated by setting
 // newIndexes to true. The quit channel is used to break the sync loop. For
-// example, closing the channel on SIGINT.
+// example, closing the channel on SIGINT. Only PostgreSQL databases are bulk
+// loaded. With another dialect, updateAllAddresses and newIndexes are ignored,
+// the table indexes always exist, and the spending information in the
+// addresses table is updated as each block is stored.
 func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.BlockFetcher,
 	updateAllAddresses, newIndexes bool, updateExplorer chan *chainhash.Hash,
 	barLoad chan *dbtypes.ProgressBarLoad) (int64, error) {
@@ -51,13 +54,17 @@ func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.BlockFetche
 		log.Info("Tables are empty, starting fresh.")
 	}
 
+	if !pgb.q.postgres() {
+		updateAllAddresses, newIndexes = false, false
+	}
+
 	// Remove indexes/constraints before an initial sync or when explicitly
 	// requested to reindex and update spending information in the addresses
 	// table.
-	reindexing := newIndexes || lastBlock == -1
+	reindexing := pgb.q.postgres() && (newIndexes || lastBlock == -1)
 
 	// See if initial sync (initial block download) was previously completed.
-	ibdComplete, err := ibdComplete(pgb.db)
+	ibdComplete, err := pgb.q.ibdComplete(pgb.db)
 	if err != nil {
 		return lastBlock, fmt.Errorf("IBDComplete failed: %w", err)
 	}
@@ -89,7 +96,7 @@ func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.BlockFetche
 
 	// When IBD is not yet completed, force reindexing and update of full
 	// spending info in addresses table after block sync.
-	if !ibdComplete {
+	if !ibdComplete && pgb.q.postgres() {
 		if lastBlock > -1 {
 			log.Warnf("Detected that initial sync was previously started but not completed!")
 		}
@@ -105,7 +112,7 @@ func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.BlockFetche
 
 	if pgb.utxoCache.Size() == 0 { // entries at any height implies it's warmed by previous sync
 		log.Infof("Collecting all UTXO data prior to height %d...", lastBlock+1)
-		utxos, err := retrieveUTXOs(ctx, pgb.db)
+		utxos, err := pgb.q.retrieveUTXOs(ctx, pgb.db)
 		if err != nil {
 			return -1, fmt.Errorf("RetrieveUTXOs: %w", err)
 		}
@@ -149,7 +156,7 @@ func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.BlockFetche
 	// ibd_complete flag to false if it is not already false.
 	if ibdComplete && (reindexing || updateAllAddresses) {
 		// Set meta.ibd_complete = FALSE.
-		if err = setIBDComplete(pgb.db, false); err != nil {
+		if err = pgb.q.setIBDComplete(pgb.db, false); err != nil {
 			return nodeHeight, fmt.Errorf("failed to set meta.ibd_complete: %w", err)
 		}
 	}
@@ -488,13 +495,13 @@ func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.BlockFetche
 	if !analyzed && requireAnalyze {
 		stage++
 		log.Infof("Beginning SYNC STAGE %d of %d (quick database ANALYZE).", stage, stages)
-		if err = AnalyzeAllTables(pgb.db, quickStatsTarget); err != nil {
+		if err = pgb.q.Analyze(pgb.db, quickStatsTarget); err != nil {
 			return nodeHeight, fmt.Errorf("failed to ANALYZE tables: %w", err)
 		}
 	}
 
 	// Set meta.ibd_complete = TRUE.
-	if err = setIBDComplete(pgb.db, true); err != nil {
+	if err = pgb.q.setIBDComplete(pgb.db, true); err != nil {
 		return nodeHeight, fmt.Errorf("failed to set meta.ibd_complete: %w", err)
 	}
 
@@ -29,6 +29,7 @@ var createTableStatements = [][2]string{
 	{"stats", internal.CreateStatsTable},
 	{"treasury", internal.CreateTreasuryTable},
 	{"swaps", internal.CreateAtomicSwapTable},
+	{"exchange_rates", internal.CreateExchangeRatesTable},
 }
 
 func createTableMap() map[string]string {
@@ -146,11 +147,18 @@ func ClearTestingTable(db *sql.DB) error {
 }
 
 // CreateTables creates all tables required by dcrdata if they do not already
-// exist.
-func CreateTables(db *sql.DB) error {
+// exist. If partitioned is true, the vins, vouts, and addresses tables are
+// created as range partitioned tables, but without any partitions.
+func CreateTables(db *sql.DB, partitioned bool) error {
 	// Create all of the data tables.
 	for _, pair := range createTableStatements {
-		err := createTable(db, pair[0], pair[1])
+		stmt := pair[1]
+		if partitioned {
+			if partStmt, ok := partitionedTableStatements[pair[0]]; ok {
+				stmt = partStmt
+			}
+		}
+		err := createTable(db, pair[0], stmt)
 		if err != nil {
 			return err
 		}
@@ -26,7 +26,7 @@ const (
 	// This includes changes such as creating tables, adding/deleting columns,
 	// adding/deleting indexes or any other operations that create, delete, or
 	// modify the definition of any database relation.
-	schemaVersion = 0
+	schemaVersion = 1
 
 	// maintVersion indicates when certain maintenance operations should be
 	// performed for the same compatVersion and schemaVersion. Such operations
@@ -147,7 +147,7 @@ func initMetaData(db *sql.DB, meta *metaData) error {
 	return err
 }
 
-func updateSchemaVersion(db *sql.DB, schema uint32) error { //nolint:unused
+func updateSchemaVersion(db *sql.DB, schema uint32) error {
 	_, err := db.Exec(internal.SetDBSchemaVersion, schema)
 	return err
 }
@@ -242,7 +242,23 @@ func (u *Upgrader) compatVersion2Upgrades(current, target DatabaseVersion) (bool
 	// initSchema := current.schema
 	switch current.schema {
 	case 0:
-		return true, nil // nothing to do
+		// Upgrade to schema v1.
+		err = u.upgradeSchema0to1()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 2.0.0 to 2.1.0: %v", err)
+		}
+		current.schema++
+		if err = updateSchemaVersion(u.db, current.schema); err != nil {
+			return false, fmt.Errorf("failed to update schema version: %v", err)
+		}
+		fallthrough
+
+	case 1:
+		// No further upgrades.
+		return upgradeCheck()
+
+		// Or continue to upgrades for the next schema version.
+		// fallthrough
 
 	/* when there's an upgrade to define:
 	case 0:
@@ -426,9 +442,16 @@ func storeVers(db *sql.DB, dbVer *DatabaseVersion) error { //nolint:unused
 	return fmt.Errorf("failed to update maintenance version: %w", err)
 }
 
-/* define when needed
-func (u *Upgrader) upgradeSchema-to1() error {
+// upgradeSchema0to1 creates the exchange_rates table for the history of the
+// DCR index price.
+func (u *Upgrader) upgradeSchema0to1() error {
 	log.Infof("Performing database upgrade 2.0.0 -> 2.1.0")
+	return CreateTable(u.db, "exchange_rates")
+}
+
+/* define when needed
+func (u *Upgrader) upgradeSchema1to2() error {
+	log.Infof("Performing database upgrade 2.1.0 -> 2.2.0")
 	// describe the actions...
 	return whatever(u.db)
 }
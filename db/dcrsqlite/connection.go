@@ -0,0 +1,41 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrsqlite
+
+import (
+	"database/sql"
+	"net/url"
+
+	_ "modernc.org/sqlite" // Register the SQLite driver
+)
+
+// busyTimeoutMs is how long, in milliseconds, a connection waits for the write
+// lock held by another connection before failing with SQLITE_BUSY.
+const busyTimeoutMs = "10000"
+
+// Connect opens the SQLite database file at the given path, creating it if it
+// does not exist. The caller is responsible for calling Close() on the returned
+// db when finished using it.
+//
+// The database is opened in WAL mode so that readers do not block the writer,
+// and with BEGIN IMMEDIATE transactions so that concurrent writers wait on the
+// busy timeout rather than failing to upgrade a read lock. Time values are
+// written in a format that the SQLite date and time functions understand.
+func Connect(path string) (*sql.DB, error) {
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout("+busyTimeoutMs+")")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "synchronous(NORMAL)")
+	q.Add("_pragma", "foreign_keys(0)")
+	q.Set("_time_format", "sqlite")
+	q.Set("_txlock", "immediate")
+	dsn := "file:" + path + "?" + q.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, db.Ping()
+}
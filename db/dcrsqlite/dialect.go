@@ -0,0 +1,345 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrsqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/dialect"
+	"github.com/decred/dcrdata/db/dcrsqlite/internal"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// NewDialect returns the SQLite dialect for a dcrpg.ChainDB stored in the
+// database file at path.
+func NewDialect(path string) *dialect.Dialect {
+	return &dialect.Dialect{
+		Name: "SQLite",
+		Open: func(params *chaincfg.Params) (*sql.DB, error) {
+			return open(path, params)
+		},
+		Analyze: func(db *sql.DB, _ int) error {
+			return AnalyzeAllTables(db)
+		},
+		StringArray: func(strs []string) interface{} {
+			return jsonStringArray(strs)
+		},
+		Int64Array: func(ints []int64) interface{} {
+			return jsonInt64Array(ints)
+		},
+		HashArray: func(hashes []dbtypes.ChainHash) interface{} {
+			strs := make([]string, len(hashes))
+			for i := range hashes {
+				strs[i] = hashes[i].String()
+			}
+			return jsonStringArray(strs)
+		},
+		Statements: statements,
+	}
+}
+
+// statements are the dialect's statements. The inserts of the blocks and
+// agendas are always upserts, and there are no partitioned tables.
+var statements = dialect.Statements{
+	SelectVinsForAddress:                     internal.SelectVinsForAddress,
+	SelectVoutsForAddress:                    internal.SelectVoutsForAddress,
+	SelectAddressTxns:                        internal.SelectAddressTxns,
+	SelectAddressesMergedSpentCount:          internal.SelectAddressesMergedSpentCount,
+	SelectAddressesMergedFundingCount:        internal.SelectAddressesMergedFundingCount,
+	SelectAddressesMergedCount:               internal.SelectAddressesMergedCount,
+	SelectAddressSpentUnspentCountAndValue:   internal.SelectAddressSpentUnspentCountAndValue,
+	SelectAddressUnspentWithTxn:              internal.SelectAddressUnspentWithTxn,
+	SelectAddressLimitNByAddress:             internal.SelectAddressLimitNByAddress,
+	SelectAddressMergedView:                  internal.SelectAddressMergedView,
+	SelectAddressIDsByFundingOutpoint:        internal.SelectAddressIDsByFundingOutpoint,
+	SetAddressMatchingTxHashForOutpoint:      internal.SetAddressMatchingTxHashForOutpoint,
+	SetAddressMainchainForVoutIDs:            internal.SetAddressMainchainForVoutIDs,
+	SetAddressMainchainForVinIDs:             internal.SetAddressMainchainForVinIDs,
+	MakeAddressRowInsertStatement:            insertWithConflict3(internal.MakeAddressRowInsertStatement),
+	MakeSelectAddressTxTypesByAddress:        internal.MakeSelectAddressTxTypesByAddress,
+	MakeSelectAddressAmountFlowByAddress:     internal.MakeSelectAddressAmountFlowByAddress,
+	SelectBlockByTimeRangeSQL:                internal.SelectBlockByTimeRangeSQL,
+	SelectBlockByTimeRangeSQLNoLimit:         internal.SelectBlockByTimeRangeSQLNoLimit,
+	SelectBlockHashByHeight:                  internal.SelectBlockHashByHeight,
+	SelectBlockHeightByHash:                  internal.SelectBlockHeightByHash,
+	SelectBlockTimeByHeight:                  internal.SelectBlockTimeByHeight,
+	SelectBlockHeightByTime:                  internal.SelectBlockHeightByTime,
+	RetrieveBestBlockHeight:                  internal.RetrieveBestBlockHeight,
+	SelectBlocksTicketsPrice:                 internal.SelectBlocksTicketsPrice,
+	SelectBlockVersionsByRCI:                 internal.SelectBlockVersionsByRCI,
+	SelectBlockStakeData:                     internal.SelectBlockStakeData,
+	SelectWindowsByLimit:                     internal.SelectWindowsByLimit,
+	SelectBlockVoteCount:                     internal.SelectBlockVoteCount,
+	SelectSideChainBlocks:                    internal.SelectSideChainBlocks,
+	SelectBlockStatus:                        internal.SelectBlockStatus,
+	SelectBlockStatuses:                      internal.SelectBlockStatuses,
+	SelectBlockFlags:                         internal.SelectBlockFlags,
+	SelectDisapprovedBlocks:                  internal.SelectDisapprovedBlocks,
+	UpdateLastBlockValid:                     internal.UpdateLastBlockValid,
+	UpdateBlockMainchain:                     internal.UpdateBlockMainchain,
+	InsertBlockPrevNext:                      internal.InsertBlockPrevNext,
+	SelectBlockChainRowIDByHash:              internal.SelectBlockChainRowIDByHash,
+	UpdateBlockNext:                          internal.UpdateBlockNext,
+	UpdateBlockNextByHash:                    internal.UpdateBlockNextByHash,
+	UpdateBlockNextByNextHash:                internal.UpdateBlockNextByNextHash,
+	SelectBlockStats:                         internal.SelectBlockStats,
+	SelectBlockDataByHeight:                  internal.SelectBlockDataByHeight,
+	SelectBlockDataRange:                     internal.SelectBlockDataRange,
+	SelectBlockDataRangeDesc:                 internal.SelectBlockDataRangeDesc,
+	SelectBlockDataRangeWithSkip:             internal.SelectBlockDataRangeWithSkip,
+	SelectBlockDataRangeWithSkipDesc:         internal.SelectBlockDataRangeWithSkipDesc,
+	SelectBlockDataByHash:                    internal.SelectBlockDataByHash,
+	SelectBlockDataBest:                      internal.SelectBlockDataBest,
+	SelectBlockSizeByHeight:                  internal.SelectBlockSizeByHeight,
+	SelectBlockSizeRange:                     internal.SelectBlockSizeRange,
+	SelectSBitsByHeight:                      internal.SelectSBitsByHeight,
+	SelectSBitsByHash:                        internal.SelectSBitsByHash,
+	SelectSBitsRange:                         internal.SelectSBitsRange,
+	SelectDiffByTime:                         internal.SelectDiffByTime,
+	BlockInsertStatement:                     upsert(internal.UpsertBlockRow),
+	MakeSelectBlocksTimeListingByLimit:       internal.MakeSelectBlocksTimeListingByLimit,
+	UpsertExchangeRate:                       internal.UpsertExchangeRate,
+	SelectExchangeRateAt:                     internal.SelectExchangeRateAt,
+	SelectExchangeRateMean:                   internal.SelectExchangeRateMean,
+	SelectMetaDBBestBlock:                    internal.SelectMetaDBBestBlock,
+	SetMetaDBBestBlock:                       internal.SetMetaDBBestBlock,
+	SelectMetaDBIbdComplete:                  internal.SelectMetaDBIbdComplete,
+	SetMetaDBIbdComplete:                     internal.SetMetaDBIbdComplete,
+	DeleteAddressesSubQry:                    internal.DeleteAddressesSubQry,
+	DeleteVinsSubQry:                         internal.DeleteVinsSubQry,
+	DeleteVoutsSubQry:                        internal.DeleteVoutsSubQry,
+	DeleteMisses:                             internal.DeleteMisses,
+	DeleteVotes:                              internal.DeleteVotes,
+	DeleteTickets:                            internal.DeleteTickets,
+	DeleteTransactionsSimple:                 internal.DeleteTransactionsSimple,
+	DeleteTreasuryTxns:                       internal.DeleteTreasuryTxns,
+	DeleteSwaps:                              internal.DeleteSwaps,
+	DeleteBlock:                              internal.DeleteBlock,
+	DeleteBlockFromChain:                     internal.DeleteBlockFromChain,
+	SelectTicketIDByHash:                     internal.SelectTicketIDByHash,
+	SelectTicketStatusByHash:                 internal.SelectTicketStatusByHash,
+	SelectTicketInfoByHash:                   internal.SelectTicketInfoByHash,
+	SelectUnspentTickets:                     internal.SelectUnspentTickets,
+	SelectTicketsByPrice:                     internal.SelectTicketsByPrice,
+	SelectTicketSpendTypeByBlock:             internal.SelectTicketSpendTypeByBlock,
+	SetTicketSpendingInfoForTicketDbID:       internal.SetTicketSpendingInfoForTicketDbID,
+	SetTicketPoolStatusForTicketDbID:         internal.SetTicketPoolStatusForTicketDbID,
+	UpdateTicketsMainchainByBlock:            internal.UpdateTicketsMainchainByBlock,
+	SelectVSPTicketSpends:                    internal.SelectVSPTicketSpends,
+	SelectTicketSpendAddresses:               internal.SelectTicketSpendAddresses,
+	SelectMeanVoteAges:                       internal.SelectMeanVoteAges,
+	UpdateVotesMainchainByBlock:              internal.UpdateVotesMainchainByBlock,
+	SelectMissesInBlock:                      internal.SelectMissesInBlock,
+	SelectMissesMainchainForTicket:           internal.SelectMissesMainchainForTicket,
+	SelectMissCountPerBlock:                  internal.SelectMissCountPerBlock,
+	SelectMissCountForBlockRange:             internal.SelectMissCountForBlockRange,
+	SelectAllAgendas:                         internal.SelectAllAgendas,
+	SelectAgendasVotesByTime:                 internal.SelectAgendasVotesByTime,
+	SelectAgendasVotesByHeight:               internal.SelectAgendasVotesByHeight,
+	SelectAgendaVoteTotals:                   internal.SelectAgendaVoteTotals,
+	SelectAgendaVotesByRCI:                   internal.SelectAgendaVotesByRCI,
+	SelectVoteVersionsByRCI:                  internal.SelectVoteVersionsByRCI,
+	MakeTicketInsertStatement:                insertWithConflict(internal.MakeTicketInsertStatement),
+	MakeVoteInsertStatement:                  insertWithConflict(internal.MakeVoteInsertStatement),
+	MakeMissInsertStatement:                  insertWithConflict(internal.MakeMissInsertStatement),
+	MakeAgendaInsertStatement:                upsert(internal.UpsertAgendaRow),
+	MakeAgendaVotesInsertStatement:           upsert(internal.UpsertAgendaVotesRow),
+	MakeSelectTicketsByPurchaseDate:          internal.MakeSelectTicketsByPurchaseDate,
+	UpsertStats:                              internal.UpsertStats,
+	SelectPoolInfo:                           internal.SelectPoolInfo,
+	SelectPoolStatsAboveHeight:               internal.SelectPoolStatsAboveHeight,
+	SelectPoolInfoByHeight:                   internal.SelectPoolInfoByHeight,
+	SelectPoolInfoRange:                      internal.SelectPoolInfoRange,
+	SelectPoolValSizeRange:                   internal.SelectPoolValSizeRange,
+	InsertContractSpend:                      internal.InsertContractSpend,
+	UpdateTreasuryMainchainByBlock:           internal.UpdateTreasuryMainchainByBlock,
+	SelectTreasuryTxns:                       internal.SelectTreasuryTxns,
+	SelectTypedTreasuryTxns:                  internal.SelectTypedTreasuryTxns,
+	SelectTreasuryBalance:                    internal.SelectTreasuryBalance,
+	SelectTreasuryAddInputs:                  internal.SelectTreasuryAddInputs,
+	SelectTreasurySpendPayouts:               internal.SelectTreasurySpendPayouts,
+	MakeTreasuryInsertStatement:              insertWithConflict(internal.MakeTreasuryInsertStatement),
+	MakeSelectTreasuryIOStatement:            internal.MakeSelectTreasuryIOStatement,
+	MakeSelectTreasuryStatementTotals:        internal.MakeSelectTreasuryStatementTotals,
+	SelectTxByHash:                           internal.SelectTxByHash,
+	SelectTxsByBlockHash:                     internal.SelectTxsByBlockHash,
+	SelectTxBlockTimeByHash:                  internal.SelectTxBlockTimeByHash,
+	SelectFullTxByHash:                       internal.SelectFullTxByHash,
+	SelectFullTxsByHash:                      internal.SelectFullTxsByHash,
+	SelectTxnsVinsVoutsByBlock:               internal.SelectTxnsVinsVoutsByBlock,
+	SelectRegularTxnsVinsVoutsByBlock:        internal.SelectRegularTxnsVinsVoutsByBlock,
+	SelectTxsBlocks:                          internal.SelectTxsBlocks,
+	UpdateRegularTxnsValidByBlock:            internal.UpdateRegularTxnsValidByBlock,
+	UpdateTxnsMainchainByBlock:               internal.UpdateTxnsMainchainByBlock,
+	SelectTicketsByType:                      internal.SelectTicketsByType,
+	SelectTxnByDbID:                          internal.SelectTxnByDbID,
+	SelectFeesPerBlockAboveHeight:            internal.SelectFeesPerBlockAboveHeight,
+	SelectMixedTotalPerBlock:                 internal.SelectMixedTotalPerBlock,
+	SelectMixedVouts:                         internal.SelectMixedVouts,
+	SelectCoinAgeEvents:                      internal.SelectCoinAgeEvents,
+	MakeTxInsertStatement:                    insertWithConflict(internal.MakeTxInsertStatement),
+	SelectSpendingTxsByPrevTx:                internal.SelectSpendingTxsByPrevTx,
+	SelectSpendingTxsByPrevTxWithBlockHeight: internal.SelectSpendingTxsByPrevTxWithBlockHeight,
+	SelectSpendingTxByPrevOut:                internal.SelectSpendingTxByPrevOut,
+	SelectFundingOutpointIndxByVinID:         internal.SelectFundingOutpointIndxByVinID,
+	SelectAllVinInfoByID:                     internal.SelectAllVinInfoByID,
+	SelectUTXOs:                              internal.SelectUTXOs,
+	SetIsValidIsMainchainByTxHash:            internal.SetIsValidIsMainchainByTxHash,
+	SetIsMainchainByVinID:                    internal.SetIsMainchainByVinID,
+	SelectCoinSupply:                         internal.SelectCoinSupply,
+	UpdateVoutSpendTxRowID:                   internal.UpdateVoutSpendTxRowID,
+	UpdateVoutsSpendTxRowID:                  internal.UpdateVoutsSpendTxRowID,
+	ResetVoutSpendTxRowIDs:                   internal.ResetVoutSpendTxRowIDs,
+	SelectVoutAddressesByTxOut:               internal.SelectVoutAddressesByTxOut,
+	SelectVoutByID:                           internal.SelectVoutByID,
+	RetrieveVoutValue:                        internal.RetrieveVoutValue,
+	RetrieveVoutValues:                       internal.RetrieveVoutValues,
+	MakeVinInsertStatement:                   insertWithConflict3(internal.MakeVinInsertStatement),
+	MakeVoutInsertStatement:                  insertWithConflict3(internal.MakeVoutInsertStatement),
+}
+
+// insertWithConflict adapts an insert statement maker to the dialect's, which
+// also selects unchecked inserts. The statements always check for conflicts.
+func insertWithConflict(makeStmt func(updateOnConflict bool) string) func(checked, updateOnConflict bool) string {
+	return func(_, updateOnConflict bool) string {
+		return makeStmt(updateOnConflict)
+	}
+}
+
+// insertWithConflict3 is like insertWithConflict for the statements of tables
+// that the PostgreSQL dialect may partition.
+func insertWithConflict3(makeStmt func(updateOnConflict bool) string) func(checked, updateOnConflict, partitioned bool) string {
+	return func(_, updateOnConflict, _ bool) string {
+		return makeStmt(updateOnConflict)
+	}
+}
+
+// upsert returns a statement maker that always gives the upsert stmt.
+func upsert(stmt string) func(checked bool) string {
+	return func(bool) string {
+		return stmt
+	}
+}
+
+// jsonInt64Array formats the integers as a JSON array for use with json_each.
+func jsonInt64Array(ids []int64) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.FormatInt(id, 10)
+	}
+	return "[" + strings.Join(strs, ",") + "]"
+}
+
+// jsonStringArray formats the strings as a JSON array for use with json_each.
+func jsonStringArray(strs []string) string {
+	b, _ := json.Marshal(strs)
+	return string(b)
+}
+
+// open connects to the database file at path, and creates or upgrades the
+// tables.
+func open(path string, params *chaincfg.Params) (*sql.DB, error) {
+	// Open the SQLite database file and return the *sql.DB.
+	db, err := Connect(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sqliteVersion string
+	if err = db.QueryRow(`SELECT sqlite_version();`).Scan(&sqliteVersion); err != nil {
+		return nil, err
+	}
+	log.Infof("SQLite version %s, database file %s", sqliteVersion, path)
+
+	// Check the database schema version, performing any schema upgrades.
+	dbVer, compatAction, err := versionCheck(db)
+	switch err {
+	case nil:
+		if compatAction == OK {
+			// meta table present and no upgrades required
+			log.Infof("DB schema version %v", dbVer)
+			break
+		}
+		log.Infof("DB schema version %v upgrading to version %v", dbVer, targetDatabaseVersion)
+		if err = upgradeDatabase(db, *dbVer); err != nil {
+			return nil, fmt.Errorf("failed to upgrade database: %w", err)
+		}
+	case tablesNotFoundErr:
+		// Empty database (no blocks table). Proceed to setupTables.
+		log.Infof(`Empty database "%s". Creating tables...`, path)
+		if err = CreateTables(db); err != nil {
+			return nil, fmt.Errorf("failed to create tables: %w", err)
+		}
+		err = initMetaData(db, &metaData{
+			netName:         params.Name,
+			currencyNet:     uint32(params.Net),
+			bestBlockHeight: -1,
+			dbVer:           *targetDatabaseVersion,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("initMetaData failed: %w", err)
+		}
+	case metaNotFoundErr:
+		return nil, fmt.Errorf("the blocks table exists, but the meta table does not")
+	default:
+		return nil, err
+	}
+
+	return db, nil
+}
+
+var (
+	// metaNotFoundErr is the error from versionCheck when the meta table does
+	// not exist.
+	metaNotFoundErr = errors.New("meta table not found")
+
+	// tablesNotFoundErr is the error from versionCheck when any of the tables
+	// do not exist.
+	tablesNotFoundErr = errors.New("tables not found")
+)
+
+// versionCheck attempts to retrieve the database version from the meta table,
+// along with a CompatAction upgrade plan. If any of the regular data tables do
+// not exist, a tablesNotFoundErr error is returned to indicated that the tables
+// do not exist (or are partially created.) If the data tables exist but the
+// meta table does not exist, a metaNotFoundErr error is returned to indicate
+// that the legacy table versioning system is in use.
+func versionCheck(db *sql.DB) (*DatabaseVersion, CompatAction, error) {
+	// Detect an empty database, only checking for the "blocks" table since some
+	// of the tables are created by schema upgrades.
+	exists, err := TableExists(db, "blocks")
+	if err != nil {
+		return nil, Unknown, err
+	}
+	if !exists {
+		return nil, Unknown, tablesNotFoundErr
+	}
+
+	// The meta table stores the database schema version.
+	exists, err = TableExists(db, "meta")
+	if err != nil {
+		return nil, Unknown, err
+	}
+	// If there is no meta table, this could indicate the legacy table
+	// versioning system is still in used. Return the MetaNotFoundErr error.
+	if !exists {
+		return nil, Unknown, metaNotFoundErr
+	}
+
+	// Retrieve the database version from the meta table.
+	dbVer, err := DBVersion(db)
+	if err != nil {
+		return nil, Unknown, fmt.Errorf("DBVersion failure: %w", err)
+	}
+
+	// Return the version, and an upgrade plan to reach targetDatabaseVersion.
+	return &dbVer, dbVer.NeededToReach(targetDatabaseVersion), nil
+}
@@ -0,0 +1,67 @@
+package dcrsqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDialectStatements checks that the dialect sets each of its statements,
+// and that they are valid for the tables created by CreateTables.
+func TestDialectStatements(t *testing.T) {
+	db, err := Connect(filepath.Join(t.TempDir(), "dcrdata.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = CreateTables(db); err != nil {
+		t.Fatal(err)
+	}
+
+	stmts := reflect.ValueOf(NewDialect("").Statements)
+	for i := 0; i < stmts.NumField(); i++ {
+		name, f := stmts.Type().Field(i).Name, stmts.Field(i)
+		if f.IsZero() {
+			t.Errorf("%s is not set", name)
+			continue
+		}
+
+		var variants []string
+		switch f.Kind() {
+		case reflect.String:
+			variants = []string{f.String()}
+		case reflect.Func:
+			// Call the statement makers with each combination of their
+			// arguments, which are either flags or a time grouping.
+			args := [][]reflect.Value{nil}
+			for j := 0; j < f.Type().NumIn(); j++ {
+				var vals []reflect.Value
+				if f.Type().In(j).Kind() == reflect.String {
+					vals = []reflect.Value{reflect.ValueOf("all"), reflect.ValueOf("day")}
+				} else {
+					vals = []reflect.Value{reflect.ValueOf(false), reflect.ValueOf(true)}
+				}
+				var next [][]reflect.Value
+				for _, a := range args {
+					for _, v := range vals {
+						next = append(next, append(append([]reflect.Value{}, a...), v))
+					}
+				}
+				args = next
+			}
+			for _, a := range args {
+				variants = append(variants, f.Call(a)[0].String())
+			}
+		}
+
+		for _, stmt := range variants {
+			s, err := db.Prepare(stmt)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			s.Close()
+		}
+	}
+}
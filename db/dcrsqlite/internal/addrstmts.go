@@ -0,0 +1,185 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "addresses" table.
+const (
+	CreateAddressTable = `CREATE TABLE IF NOT EXISTS addresses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		address TEXT,
+		tx_hash BLOB,
+		valid_mainchain BOOLEAN,
+		matching_tx_hash BLOB, -- the funder if is_funding is FALSE, otherwise any known spender (may be NULL)
+		value INTEGER,
+		block_time TIMESTAMP NOT NULL,
+		is_funding BOOLEAN,
+		tx_vin_vout_index INTEGER, -- vout if is_funding is TRUE, vin if FALSE
+		tx_vin_vout_row_id INTEGER,
+		tx_type INTEGER
+	);`
+
+	// insertAddressRow is the basis for several address insert/upsert
+	// statements.
+	insertAddressRow = `INSERT INTO addresses (address, matching_tx_hash, tx_hash,
+		tx_vin_vout_index, tx_vin_vout_row_id, value, block_time, is_funding, valid_mainchain, tx_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) `
+
+	// UpsertAddressRow is an upsert (insert or update on conflict), returning
+	// the inserted/updated address row id.
+	UpsertAddressRow = insertAddressRow + `ON CONFLICT (tx_vin_vout_row_id, address, is_funding) DO UPDATE
+		SET matching_tx_hash = $2, tx_hash = $3, tx_vin_vout_index = $4,
+		block_time = $7, valid_mainchain = $9 RETURNING id;`
+
+	// InsertAddressRowOnConflictDoNothing inserts an address row, or returns
+	// the id of the existing row that causes the conflict. See
+	// InsertTxRowOnConflictDoNothing.
+	InsertAddressRowOnConflictDoNothing = insertAddressRow + `ON CONFLICT (tx_vin_vout_row_id, address, is_funding) DO UPDATE
+		SET id = id RETURNING id;`
+
+	addressTxnsSubQuery = `SELECT tx_hash
+		FROM addresses
+		WHERE address = $1
+			AND valid_mainchain
+		GROUP BY tx_hash
+		ORDER BY MAX(block_time) DESC
+		LIMIT $2 OFFSET $3`
+
+	SelectVinsForAddress = `SELECT vins.tx_hash, vins.tx_index, vins.prev_tx_hash, vins.prev_tx_index,
+			vins.prev_tx_tree, vins.value_in, prevtxs.block_height, prevtxs.block_index
+		FROM (` + addressTxnsSubQuery + `) atxs
+		JOIN vins ON vins.tx_hash = atxs.tx_hash
+		LEFT JOIN transactions prevtxs ON vins.prev_tx_hash=prevtxs.tx_hash;` // LEFT JOIN because prev_tx_hash may be coinbase
+
+	SelectVoutsForAddress = `SELECT vouts.value, vouts.tx_hash, vouts.tx_index, vouts.version
+		FROM (` + addressTxnsSubQuery + `) atxs
+		JOIN vouts ON vouts.tx_hash = atxs.tx_hash;`
+
+	// SelectAddressTxns uses the num_vin and num_vout columns rather than the
+	// cardinality of the vin_db_ids and vout_db_ids arrays.
+	SelectAddressTxns = `SELECT txs.tx_hash, txs.block_hash, txs.block_height, txs.block_time,
+			txs.version, txs.lock_time, txs.size, txs.tx_type, txs.num_vin, txs.num_vout
+		FROM (` + addressTxnsSubQuery + `) atxs
+		JOIN transactions txs ON txs.tx_hash = atxs.tx_hash
+		WHERE is_valid AND is_mainchain
+		ORDER BY txs.block_time DESC;`
+
+	addrsColumnNames = `id, address, matching_tx_hash, tx_hash, tx_type, valid_mainchain,
+		tx_vin_vout_index, block_time, tx_vin_vout_row_id, value, is_funding`
+
+	SelectAddressesMergedSpentCount = `SELECT COUNT( DISTINCT tx_hash ) FROM addresses
+		WHERE address = $1 AND is_funding = FALSE AND valid_mainchain;`
+
+	SelectAddressesMergedFundingCount = `SELECT COUNT( DISTINCT tx_hash ) FROM addresses
+		WHERE address = $1 AND is_funding = TRUE AND valid_mainchain;`
+
+	SelectAddressesMergedCount = `SELECT COUNT( DISTINCT tx_hash ) FROM addresses
+		WHERE address = $1 AND valid_mainchain;`
+
+	// SelectAddressSpentUnspentCountAndValue gets the number and combined spent
+	// and unspent outpoints for the given address. See the dcrpg statement of
+	// the same name for a description of the output rows.
+	SelectAddressSpentUnspentCountAndValue = `SELECT
+			(tx_type = 0) AS is_regular,
+			COUNT(*) AS count,
+			SUM(value),
+			is_funding,
+			(matching_tx_hash IS NULL) AS all_empty_matching
+		FROM addresses
+		WHERE address = $1 AND valid_mainchain
+		GROUP BY tx_type=0, is_funding,
+			matching_tx_hash IS NULL  -- separate spent and unspent
+		ORDER BY count, is_funding;`
+
+	SelectAddressUnspentWithTxn = `SELECT
+			addresses.address,
+			addresses.tx_hash,
+			addresses.value,
+			transactions.block_height,
+			addresses.block_time,
+			addresses.tx_vin_vout_index
+		FROM addresses
+		JOIN transactions ON
+			addresses.tx_hash = transactions.tx_hash
+		JOIN vouts ON addresses.tx_vin_vout_row_id = vouts.id
+		WHERE addresses.address=$1 AND addresses.is_funding AND addresses.matching_tx_hash IS NULL AND valid_mainchain
+		ORDER BY addresses.block_time DESC;`
+
+	SelectAddressLimitNByAddress = `SELECT ` + addrsColumnNames + ` FROM addresses
+		WHERE address=$1 AND valid_mainchain
+		ORDER BY block_time DESC, tx_hash ASC
+		LIMIT $2 OFFSET $3;`
+
+	SelectAddressMergedViewAll = `SELECT tx_hash, valid_mainchain, block_time, sum(CASE WHEN is_funding = TRUE THEN value ELSE 0 END),
+		sum(CASE WHEN is_funding = FALSE THEN value ELSE 0 END), COUNT(*)
+		FROM addresses
+		WHERE address=$1                                 -- spending and funding transactions
+		GROUP BY tx_hash, valid_mainchain, block_time    -- merging common transactions in same valid mainchain block
+		ORDER BY block_time DESC`
+
+	SelectAddressMergedView = SelectAddressMergedViewAll + ` LIMIT $2 OFFSET $3;`
+
+	SelectAddressIDsByFundingOutpoint = `SELECT id, address, value
+		FROM addresses
+		WHERE tx_hash=$1 AND tx_vin_vout_index=$2 AND is_funding
+		ORDER BY block_time DESC;`
+
+	// selectAddressTxTypesByAddress gets the transaction type histogram for the
+	// given address using block time binning with bin size of block_time.
+	// Regular transactions are grouped into (SentRtx and ReceivedRtx), SSTx
+	// defines tickets, SSGen defines votes, and SSRtx defines revocations.
+	selectAddressTxTypesByAddress = `SELECT %s as timestamp,
+		COUNT(CASE WHEN tx_type = 0 AND is_funding = false THEN 1 ELSE NULL END) as SentRtx,
+		COUNT(CASE WHEN tx_type = 0 AND is_funding = true THEN 1 ELSE NULL END) as ReceivedRtx,
+		COUNT(CASE WHEN tx_type = 1 THEN 1 ELSE NULL END) as SSTx,
+		COUNT(CASE WHEN tx_type = 2 THEN 1 ELSE NULL END) as SSGen,
+		COUNT(CASE WHEN tx_type = 3 THEN 1 ELSE NULL END) as SSRtx
+		FROM addresses
+		WHERE address=$1 AND valid_mainchain
+		GROUP BY timestamp
+		ORDER BY timestamp;`
+
+	selectAddressAmountFlowByAddress = `SELECT %s as timestamp,
+		SUM(CASE WHEN is_funding = TRUE THEN value ELSE 0 END) as received,
+		SUM(CASE WHEN is_funding = FALSE THEN value ELSE 0 END) as sent
+		FROM addresses
+		WHERE address=$1 AND valid_mainchain
+		GROUP BY timestamp
+		ORDER BY timestamp;`
+
+	// UPDATEs/SETs
+
+	// SetAddressMatchingTxHashForOutpoint sets the matching tx hash (a spending
+	// transaction) for the addresses rows corresponding to the specified
+	// outpoint (tx_hash:tx_vin_vout_index), a funding tx row.
+	SetAddressMatchingTxHashForOutpoint = `UPDATE addresses SET matching_tx_hash=$1
+		WHERE tx_hash=$2 AND is_funding AND tx_vin_vout_index=$3 AND valid_mainchain = $4 ` // not terminated with ;
+
+	SetAddressMainchainForVoutIDs = `UPDATE addresses SET valid_mainchain=$1
+		WHERE is_funding = TRUE AND tx_vin_vout_row_id=$2
+		RETURNING address;`
+
+	SetAddressMainchainForVinIDs = `UPDATE addresses SET valid_mainchain=$1
+		WHERE is_funding = FALSE AND tx_vin_vout_row_id=$2
+		RETURNING address;`
+)
+
+// MakeAddressRowInsertStatement returns the appropriate addresses insert
+// statement for the desired conflict handling behavior. See
+// MakeTxInsertStatement.
+func MakeAddressRowInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertAddressRow
+	}
+	return InsertAddressRowOnConflictDoNothing
+}
+
+// MakeSelectAddressTxTypesByAddress returns the selectAddressTxTypesByAddress query
+func MakeSelectAddressTxTypesByAddress(group string) string {
+	return formatGroupingQuery(selectAddressTxTypesByAddress, group, "block_time")
+}
+
+// MakeSelectAddressAmountFlowByAddress returns the selectAddressAmountFlowByAddress query
+func MakeSelectAddressAmountFlowByAddress(group string) string {
+	return formatGroupingQuery(selectAddressAmountFlowByAddress, group, "block_time")
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "blocks" and "block_chain" tables.
+const (
+	CreateBlockTable = `CREATE TABLE IF NOT EXISTS blocks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		hash BLOB NOT NULL,
+		height INTEGER,
+		size INTEGER,
+		is_valid BOOLEAN,
+		is_mainchain BOOLEAN,
+		version INTEGER,
+		numtx INTEGER,
+		num_rtx INTEGER,
+		txDbIDs TEXT, -- {1,2,3} array text as written by dbtypes.UInt64Array
+		num_stx INTEGER,
+		stxDbIDs TEXT,
+		time TIMESTAMP,
+		nonce INTEGER,
+		vote_bits INTEGER,
+		voters INTEGER,
+		fresh_stake INTEGER,
+		revocations INTEGER,
+		pool_size INTEGER,
+		bits INTEGER,
+		sbits INTEGER,
+		difficulty REAL,
+		stake_version INTEGER,
+		previous_hash BLOB,
+		chainwork TEXT,
+		winners TEXT -- {...} bytea array text as written by dbtypes.ChainHashArray
+	);`
+
+	// Block inserts. is_valid refers to blocks that have been validated by
+	// stakeholders (voting on the previous block), while is_mainchain
+	// distinguishes blocks that are on the main chain from those that are on
+	// side chains and/or orphaned.
+
+	// insertBlockRow is the basis for several block insert/upsert statements.
+	insertBlockRow = `INSERT INTO blocks (
+		hash, height, size, is_valid, is_mainchain, version,
+		numtx, num_rtx, txDbIDs, num_stx,  stxDbIDs,
+		time, nonce, vote_bits, voters,
+		fresh_stake, revocations, pool_size, bits, sbits,
+		difficulty, stake_version, previous_hash, chainwork, winners)
+	VALUES ($1, $2, $3, $4, $5, $6,
+		$7, $8, $9, $10, $11,
+		$12, $13, $14, $15,
+		$16, $17, $18, $19, $20,
+		$21, $22, $23, $24, $25) `
+
+	// UpsertBlockRow is an upsert (insert or update on conflict), returning
+	// the inserted/updated block row id.
+	UpsertBlockRow = insertBlockRow + `ON CONFLICT (hash) DO UPDATE
+		SET is_valid = $4, is_mainchain = $5 RETURNING id;`
+
+	SelectBlockByTimeRangeSQL = `SELECT hash, height, size, time, numtx
+		FROM blocks WHERE time BETWEEN $1 and $2 ORDER BY time DESC LIMIT $3;`
+	SelectBlockByTimeRangeSQLNoLimit = `SELECT hash, height, size, time, numtx
+		FROM blocks WHERE time BETWEEN $1 and $2 ORDER BY time DESC;`
+	SelectBlockHashByHeight = `SELECT hash FROM blocks WHERE height = $1 AND is_mainchain = true;`
+	SelectBlockHeightByHash = `SELECT height FROM blocks WHERE hash = $1;`
+
+	SelectBlockTimeByHeight = `SELECT time FROM blocks
+		WHERE height = $1 AND is_mainchain = true;`
+
+	// SelectBlockHeightByTime selects the height of the first mainchain block
+	// at or after a time.
+	SelectBlockHeightByTime = `SELECT height FROM blocks
+		WHERE time >= $1 AND is_mainchain = true
+		ORDER BY time
+		LIMIT 1;`
+
+	RetrieveBestBlockHeight = `SELECT id, hash, height FROM blocks
+		WHERE is_mainchain = true ORDER BY height DESC LIMIT 1;`
+
+	// SelectBlocksTicketsPrice selects the ticket price and difficulty for the
+	// first block in a stake difficulty window.
+	SelectBlocksTicketsPrice = `SELECT sbits, time, difficulty, height, fresh_stake
+		FROM blocks
+		WHERE height > $1
+		ORDER BY height;`
+
+	// SelectBlockVersionsByRCI counts the mainchain blocks of each version in
+	// each rule change interval, numbered from the stake validation height ($1)
+	// by the interval length ($2).
+	SelectBlockVersionsByRCI = `SELECT (height - $1) / $2 AS rci, version, count(*)
+		FROM blocks
+		WHERE height >= $3 AND height <= $4 AND is_mainchain
+		GROUP BY rci, version
+		ORDER BY rci, version;`
+
+	// SelectBlockStakeData selects the ticket price, ticket purchases, and
+	// pool size of the mainchain blocks from a height.
+	SelectBlockStakeData = `SELECT height, time, sbits, fresh_stake, pool_size
+		FROM blocks
+		WHERE height >= $1 AND is_mainchain
+		ORDER BY height;`
+
+	// SelectWindowsByLimit returns the start time of each window as a UNIX
+	// time stamp since MIN(time) has no declared column type.
+	SelectWindowsByLimit = `SELECT (height/$1)*$1 AS window_start,
+		MAX(difficulty) AS difficulty,
+		SUM(num_rtx) AS txs,
+		SUM(fresh_stake) AS tickets,
+		SUM(voters) AS votes,
+		SUM(revocations) AS revocations,
+		SUM(size) AS size,
+		MAX(sbits) AS sbits,
+		unixepoch(MIN(time)) AS time,
+		COUNT(*) AS blocks_count
+		FROM blocks
+		WHERE height BETWEEN $2 AND $3
+			AND is_mainchain
+		GROUP BY window_start
+		ORDER BY window_start DESC;`
+
+	// selectBlocksTimeListingByLimit has a %s verb for the time grouping
+	// expression made by formatGroupingQuery.
+	selectBlocksTimeListingByLimit = `SELECT %s as index_value,
+		MAX(height),
+		SUM(num_rtx) AS txs,
+		SUM(fresh_stake) AS tickets,
+		SUM(voters) AS votes,
+		SUM(revocations) AS revocations,
+		SUM(size) AS size,
+		COUNT(*) AS blocks_count,
+		unixepoch(MIN(time)) AS start_time,
+		unixepoch(MAX(time)) AS end_time
+		FROM blocks
+		GROUP BY index_value
+		ORDER BY index_value DESC
+		LIMIT $1 OFFSET $2;`
+
+	SelectBlockVoteCount = `SELECT voters FROM blocks WHERE hash = $1;`
+
+	SelectSideChainBlocks = `SELECT is_valid, height, previous_hash, hash, block_chain.next_hash
+		FROM blocks
+		JOIN block_chain ON this_hash=hash
+		WHERE is_mainchain = FALSE
+		ORDER BY height DESC;`
+
+	SelectBlockStatus = `SELECT is_valid, is_mainchain, height, previous_hash, hash, block_chain.next_hash
+		FROM blocks
+		JOIN block_chain ON this_hash=hash
+		WHERE hash = $1;`
+
+	SelectBlockStatuses = `SELECT is_valid, is_mainchain, hash
+		FROM blocks
+		WHERE height = $1;`
+
+	SelectBlockFlags = `SELECT is_valid, is_mainchain
+		FROM blocks
+		WHERE hash = $1;`
+
+	SelectDisapprovedBlocks = `SELECT is_mainchain, height, previous_hash, hash, block_chain.next_hash
+		FROM blocks
+		JOIN block_chain ON this_hash=hash
+		WHERE is_valid = FALSE
+		ORDER BY height DESC;`
+
+	// blocks table updates
+
+	UpdateLastBlockValid = `UPDATE blocks SET is_valid = $2 WHERE id = $1;`
+	UpdateBlockMainchain = `UPDATE blocks SET is_mainchain = $2 WHERE hash = $1 RETURNING previous_hash;`
+
+	// CreateBlockPrevNextTable creates a new table named block_chain. The
+	// primary key is not autoincrementing, but rather the row ID of the block
+	// in the blocks table.
+	CreateBlockPrevNextTable = `CREATE TABLE IF NOT EXISTS block_chain (
+		block_db_id INTEGER PRIMARY KEY,
+		prev_hash BLOB NOT NULL,
+		this_hash BLOB UNIQUE NOT NULL,
+		next_hash BLOB
+	);`
+
+	// InsertBlockPrevNext includes the primary key, which should be the row ID
+	// of the corresponding block in the blocks table.
+	InsertBlockPrevNext = `INSERT INTO block_chain (block_db_id, prev_hash, this_hash, next_hash)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (this_hash) DO NOTHING;`
+
+	SelectBlockChainRowIDByHash = `SELECT block_db_id FROM block_chain WHERE this_hash = $1;`
+
+	UpdateBlockNext           = `UPDATE block_chain SET next_hash = $2 WHERE block_db_id = $1;`
+	UpdateBlockNextByHash     = `UPDATE block_chain SET next_hash = $2 WHERE this_hash = $1;`
+	UpdateBlockNextByNextHash = `UPDATE block_chain SET next_hash = $2 WHERE next_hash = $1;`
+
+	SelectBlockStats = `SELECT height, size, time, chainwork, numtx
+		FROM blocks
+		WHERE is_mainchain
+		AND height > $1
+		ORDER BY height;`
+
+	// Get the height data. Because stats is unique on height, the inner join will
+	// filter for mainchain as well.
+	SelectBlockDataByHeight = `
+		SELECT blocks.hash, blocks.height, blocks.size,
+			blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+			stats.pool_val, blocks.winners, blocks.is_valid
+		FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+		WHERE blocks.height = $1;`
+
+	SelectBlockDataRange = `
+		SELECT blocks.hash, blocks.height, blocks.size,
+			blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+			stats.pool_val, blocks.winners, blocks.is_valid
+		FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+		WHERE blocks.height BETWEEN $1 AND $2
+		ORDER BY blocks.height;`
+
+	SelectBlockDataRangeDesc = `
+		SELECT blocks.hash, blocks.height, blocks.size,
+			blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+			stats.pool_val, blocks.winners, blocks.is_valid
+		FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+		WHERE blocks.height BETWEEN $1 AND $2
+		ORDER BY blocks.height DESC;`
+
+	SelectBlockDataRangeWithSkip = `
+		SELECT blocks.hash, blocks.height, blocks.size,
+			blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+			stats.pool_val, blocks.winners, blocks.is_valid
+		FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+		WHERE blocks.height BETWEEN $1 AND $2
+			AND blocks.height %% %d = %d
+		ORDER BY blocks.height;`
+
+	SelectBlockDataRangeWithSkipDesc = `
+		SELECT blocks.hash, blocks.height, blocks.size,
+			blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+			stats.pool_val, blocks.winners, blocks.is_valid
+		FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+		WHERE blocks.height BETWEEN $1 AND $2
+			AND blocks.height %% %d = %d
+		ORDER BY blocks.height DESC;`
+
+	SelectBlockDataByHash = `
+			SELECT blocks.height, blocks.size,
+				blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+				stats.pool_val, blocks.winners, blocks.is_mainchain, blocks.is_valid
+			FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+			WHERE blocks.hash = $1;`
+
+	SelectBlockDataBest = `
+		SELECT blocks.hash, blocks.height, blocks.size,
+			blocks.difficulty, blocks.sbits, blocks.time, stats.pool_size,
+			stats.pool_val, blocks.winners, blocks.is_valid
+		FROM blocks INNER JOIN stats ON blocks.id = stats.blocks_id
+		WHERE blocks.is_mainchain
+		ORDER BY height DESC LIMIT 1;`
+
+	SelectBlockSizeByHeight = `SELECT size
+		FROM blocks
+		WHERE is_mainchain AND height = $1;`
+
+	SelectBlockSizeRange = `SELECT size
+		FROM blocks
+		WHERE is_mainchain
+			AND height BETWEEN $1 AND $2;`
+
+	SelectSBitsByHeight = `SELECT sbits
+		FROM blocks
+		WHERE height = $1 AND is_mainchain;`
+
+	SelectSBitsByHash = `SELECT sbits
+		FROM blocks
+		WHERE hash = $1;`
+
+	SelectSBitsRange = `SELECT sbits
+		FROM blocks
+		WHERE height BETWEEN $1 AND $2;`
+
+	SelectDiffByTime = `SELECT difficulty
+		FROM blocks
+		WHERE time >= $1
+		ORDER BY time
+		LIMIT 1;`
+)
+
+// MakeSelectBlocksTimeListingByLimit returns the selectBlocksTimeListingByLimit
+// query for the given time grouping.
+func MakeSelectBlocksTimeListingByLimit(group string) string {
+	return formatGroupingQuery(selectBlocksTimeListingByLimit, group, "time")
+}
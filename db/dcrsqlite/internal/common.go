@@ -0,0 +1,31 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+import "fmt"
+
+// ZeroHash is the all zeros hash of the previous outpoint of coinbase,
+// stakebase, and treasurybase inputs, as stored in a BLOB column.
+const ZeroHash = `X'0000000000000000000000000000000000000000000000000000000000000000'`
+
+// The time bucket expressions for each time grouping, as a UNIX time stamp.
+// The weeks start on Monday, as with PostgreSQL's date_trunc('week', ...).
+var timeGroupingModifiers = map[string]string{
+	"year":  `'start of year'`,
+	"month": `'start of month'`,
+	"week":  `'start of day', '-6 days', 'weekday 1'`,
+	"day":   `'start of day'`,
+}
+
+// formatGroupingQuery inserts the time bucket expression for the given
+// grouping into the query. SQLite has no date_trunc function, so the date and
+// time modifiers are used to truncate the time. For the "all" grouping, the
+// column's UNIX time stamp is used without truncation.
+func formatGroupingQuery(mainQuery, group, column string) string {
+	mods, ok := timeGroupingModifiers[group]
+	if !ok {
+		return fmt.Sprintf(mainQuery, "unixepoch("+column+")")
+	}
+	return fmt.Sprintf(mainQuery, "unixepoch("+column+", "+mods+")")
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// CreateIndexStatements create the table column indexes. Unlike dcrpg, the
+// indexes are always created with the tables rather than after an initial
+// bulk load, since the ON CONFLICT clauses of the insert statements require
+// the unique indexes. The index names match those used by dcrpg.
+var CreateIndexStatements = []string{
+	// blocks table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_block_hash ON blocks(hash);`,
+	`CREATE INDEX IF NOT EXISTS uix_block_height ON blocks(height);`,
+	`CREATE INDEX IF NOT EXISTS uix_block_time ON blocks(time);`,
+
+	// transactions table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_tx_hashes ON transactions(tx_hash, block_hash);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_tx_block_in ON transactions(block_hash, block_index, tree);`,
+	`CREATE INDEX IF NOT EXISTS ix_tx_block_height ON transactions(block_height);`,
+
+	// vins table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_vin ON vins(tx_hash, tx_index, tx_tree);`,
+	`CREATE INDEX IF NOT EXISTS uix_vin_prevout ON vins(prev_tx_hash, prev_tx_index);`,
+
+	// vouts table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_vout_txhash_ind ON vouts(tx_hash, tx_index, tx_tree);`,
+	`CREATE INDEX IF NOT EXISTS uix_vout_spendtxid_ind ON vouts(spend_tx_row_id);`,
+
+	// addresses table
+	`CREATE INDEX IF NOT EXISTS uix_addresses_address ON addresses(address);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_addresses_vout_id ON addresses(tx_vin_vout_row_id, address, is_funding);`,
+	`CREATE INDEX IF NOT EXISTS block_time_index ON addresses(block_time DESC);`,
+	`CREATE INDEX IF NOT EXISTS uix_addresses_funding_tx ON addresses(tx_hash, tx_vin_vout_index, is_funding);`,
+	`CREATE INDEX IF NOT EXISTS matching_tx_hash_index ON addresses(matching_tx_hash);`,
+
+	// tickets table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_ticket_hashes_index ON tickets(tx_hash, block_hash);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_ticket_ticket_db_id ON tickets(purchase_tx_db_id);`,
+	`CREATE INDEX IF NOT EXISTS uix_tickets_pool_status ON tickets(pool_status);`,
+
+	// votes table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_votes_hashes_index ON votes(tx_hash, block_hash);`,
+	`CREATE INDEX IF NOT EXISTS uix_votes_block_hash ON votes(block_hash);`,
+	`CREATE INDEX IF NOT EXISTS uix_votes_candidate_block ON votes(candidate_block_hash);`,
+	`CREATE INDEX IF NOT EXISTS uix_votes_vote_version ON votes(version);`,
+	`CREATE INDEX IF NOT EXISTS uix_votes_height ON votes(height);`,
+	`CREATE INDEX IF NOT EXISTS uix_votes_block_time ON votes(block_time);`,
+
+	// misses table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_misses_hashes_index ON misses(ticket_hash, block_hash);`,
+
+	// agendas table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_agendas_name ON agendas(name);`,
+
+	// agenda_votes table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_agenda_votes ON agenda_votes(votes_row_id, agendas_row_id);`,
+
+	// treasury table
+	`CREATE UNIQUE INDEX IF NOT EXISTS uix_treasury_tx_hash ON treasury(tx_hash, block_hash);`,
+	`CREATE INDEX IF NOT EXISTS idx_treasury_height ON treasury(block_height DESC);`,
+
+	// swaps table
+	`CREATE INDEX IF NOT EXISTS idx_swaps_height ON swaps(spend_height);`,
+}
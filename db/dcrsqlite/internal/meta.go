@@ -0,0 +1,51 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "meta" table.
+const (
+	CreateMetaTable = `CREATE TABLE IF NOT EXISTS meta (
+		net_name TEXT,
+		currency_net INTEGER PRIMARY KEY,
+		best_block_height INTEGER,
+		best_block_hash BLOB,
+		compatibility_version INTEGER,
+		schema_version INTEGER,
+		maintenance_version INTEGER,
+		ibd_complete BOOLEAN
+	);`
+
+	InitMetaRow = `INSERT INTO meta (
+		net_name, currency_net, best_block_height,
+		compatibility_version, schema_version, maintenance_version,
+		ibd_complete)
+	VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	SelectMetaDBVersions = `SELECT
+		compatibility_version,
+		schema_version,
+		maintenance_version
+	FROM meta;`
+
+	SelectMetaDBBestBlock = `SELECT
+		best_block_height,
+		best_block_hash
+	FROM meta;`
+
+	SetMetaDBBestBlock = `UPDATE meta
+		SET best_block_height = $1, best_block_hash = $2;`
+
+	SelectMetaDBNetName = `SELECT net_name FROM meta;`
+
+	SelectMetaDBIbdComplete = `SELECT ibd_complete FROM meta;`
+
+	SetMetaDBIbdComplete = `UPDATE meta
+		SET ibd_complete = $1;`
+
+	SetDBSchemaVersion = `UPDATE meta
+		SET schema_version = $1;`
+
+	SetDBMaintenanceVersion = `UPDATE meta
+		SET maintenance_version = $1;`
+)
@@ -0,0 +1,77 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the blockchain "rewind" operations involving multiple
+// tables. The transactions of a block are identified by their block_hash
+// rather than by the blocks.txDbIDs and blocks.stxDbIDs arrays, and the vins
+// and vouts row IDs of each transaction are expanded from the vin_db_ids and
+// vout_db_ids arrays with json_each.
+const (
+	// address row deletion by block hash
+
+	addressesForBlockHash = `SELECT addresses.id
+		FROM transactions, json_each(` + vinDbIDs + `) AS vin
+		JOIN addresses ON addresses.tx_vin_vout_row_id = vin.value
+			AND NOT addresses.is_funding
+		WHERE transactions.block_hash = $1
+		UNION ALL
+		SELECT addresses.id
+		FROM transactions, json_each(` + voutDbIDs + `) AS vout
+		JOIN addresses ON addresses.tx_vin_vout_row_id = vout.value
+			AND addresses.is_funding
+		WHERE transactions.block_hash = $1`
+
+	DeleteAddressesSubQry = `DELETE FROM addresses WHERE id IN (` + addressesForBlockHash + `);`
+
+	// vin row deletion by block hash
+
+	vinsForBlockHash = `SELECT vin.value
+		FROM transactions, json_each(` + vinDbIDs + `) AS vin
+		WHERE transactions.block_hash = $1`
+
+	DeleteVinsSubQry = `DELETE FROM vins WHERE id IN (` + vinsForBlockHash + `);`
+
+	// vout row deletion by block hash
+
+	voutsForBlockHash = `SELECT vout.value
+		FROM transactions, json_each(` + voutDbIDs + `) AS vout
+		WHERE transactions.block_hash = $1`
+
+	DeleteVoutsSubQry = `DELETE FROM vouts WHERE id IN (` + voutsForBlockHash + `);`
+
+	DeleteMisses = `DELETE FROM misses
+		WHERE block_hash=$1;`
+
+	DeleteVotes = `DELETE FROM votes
+		WHERE block_hash=$1;`
+
+	DeleteTickets = `DELETE FROM tickets
+		WHERE block_hash=$1;`
+
+	DeleteTransactionsSimple = `DELETE FROM transactions
+		WHERE block_hash=$1
+		RETURNING id;`
+
+	DeleteTreasuryTxns = `DELETE FROM treasury
+		WHERE block_hash=$1;`
+
+	DeleteSwaps = `DELETE FROM swaps
+		WHERE spend_height=$1;`
+
+	DeleteBlock = `DELETE FROM blocks
+		WHERE hash=$1;`
+
+	DeleteBlockFromChain = `DELETE FROM block_chain
+		WHERE this_hash=$1
+		RETURNING prev_hash;`
+)
+
+// The vin_db_ids and vout_db_ids columns of the transactions table, written as
+// {1,2,3} by dbtypes.UInt64Array, as JSON arrays that may be passed to
+// json_each.
+const (
+	vinDbIDs  = `'[' || trim(transactions.vin_db_ids, '{}') || ']'`
+	voutDbIDs = `'[' || trim(transactions.vout_db_ids, '{}') || ']'`
+)
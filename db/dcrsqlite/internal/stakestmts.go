@@ -0,0 +1,371 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the stake tables ("tickets", "votes",
+// "misses", "agendas", and "agenda_votes").
+const (
+	// tickets table
+
+	CreateTicketsTable = `CREATE TABLE IF NOT EXISTS tickets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tx_hash BLOB NOT NULL,
+		block_hash BLOB NOT NULL,
+		block_height INTEGER,
+		purchase_tx_db_id INTEGER,
+		stakesubmission_address TEXT,
+		is_multisig BOOLEAN, -- historically false always, but actually indicates is_p2sh
+		is_split BOOLEAN,
+		num_inputs INTEGER,
+		price REAL,
+		fee REAL,
+		spend_type INTEGER,
+		pool_status INTEGER,
+		is_mainchain BOOLEAN,
+		spend_height INTEGER,
+		spend_tx_db_id INTEGER
+	);`
+
+	// insertTicketRow is the basis for several ticket insert/upsert statements.
+	insertTicketRow = `INSERT INTO tickets (
+		tx_hash, block_hash, block_height, purchase_tx_db_id,
+		stakesubmission_address, is_multisig, is_split,
+		num_inputs, price, fee, spend_type, pool_status,
+		is_mainchain)
+	VALUES (
+		$1, $2, $3,	$4,
+		$5, $6, $7,
+		$8, $9, $10, $11, $12,
+		$13) `
+
+	// UpsertTicketRow is an upsert (insert or update on conflict), returning
+	// the inserted/updated ticket row id. is_mainchain is updated as this might
+	// be a reorganization.
+	UpsertTicketRow = insertTicketRow + `ON CONFLICT (tx_hash, block_hash) DO UPDATE
+		SET is_mainchain = $13 RETURNING id;`
+
+	// InsertTicketRowOnConflictDoNothing inserts a ticket row, or returns the
+	// id of the existing row that causes the conflict. See
+	// InsertTxRowOnConflictDoNothing.
+	InsertTicketRowOnConflictDoNothing = insertTicketRow + `ON CONFLICT (tx_hash, block_hash) DO UPDATE
+		SET id = id RETURNING id;`
+
+	allCols = `id, tx_hash, block_hash, block_height, purchase_tx_db_id,
+		stakesubmission_address, is_multisig, is_split, num_inputs, price, fee, spend_type,
+		pool_status, is_mainchain, spend_height, spend_tx_db_id`
+
+	SelectTicketsInBlock        = `SELECT ` + allCols + ` FROM tickets WHERE block_hash = $1;`
+	SelectTicketsTxDbIDsInBlock = `SELECT purchase_tx_db_id FROM tickets WHERE block_hash = $1;`
+	SelectTicketsForAddress     = `SELECT ` + allCols + ` FROM tickets WHERE stakesubmission_address = $1;`
+
+	forTxHashMainchainFirst    = ` WHERE tx_hash = $1 ORDER BY is_mainchain DESC;`
+	SelectTicketIDHeightByHash = `SELECT id, block_height FROM tickets` + forTxHashMainchainFirst
+	SelectTicketIDByHash       = `SELECT id FROM tickets` + forTxHashMainchainFirst
+	SelectTicketStatusByHash   = `SELECT id, spend_type, pool_status FROM tickets` + forTxHashMainchainFirst
+	SelectTicketInfoByHash     = `SELECT block_hash, block_height, spend_type, pool_status, spend_tx_db_id FROM tickets` + forTxHashMainchainFirst
+
+	SelectUnspentTickets = `SELECT id, tx_hash FROM tickets
+		WHERE spend_type = 0 AND is_mainchain = true;`
+
+	SelectTicketsForPriceAtLeast = `SELECT ` + allCols + ` FROM tickets WHERE price >= $1;`
+	SelectTicketsForPriceAtMost  = `SELECT ` + allCols + ` FROM tickets WHERE price <= $1;`
+
+	SelectTicketsByPrice = `SELECT price,
+		SUM(CASE WHEN tickets.block_height >= $1 THEN 1 ELSE 0 END) as immature,
+		SUM(CASE WHEN tickets.block_height < $1 THEN 1 ELSE 0 END) as live
+		FROM tickets JOIN transactions ON purchase_tx_db_id=transactions.id
+		WHERE pool_status = 0 AND tickets.is_mainchain = TRUE
+		GROUP BY price ORDER BY price;`
+
+	selectTicketsByPurchaseDate = `SELECT %s as timestamp,
+		SUM(price) as price,
+		SUM(CASE WHEN tickets.block_height >= $1 THEN 1 ELSE 0 END) as immature,
+		SUM(CASE WHEN tickets.block_height < $1 THEN 1 ELSE 0 END) as live
+		FROM tickets JOIN transactions ON purchase_tx_db_id=transactions.id
+		WHERE pool_status = 0 AND tickets.is_mainchain = TRUE
+		GROUP BY timestamp ORDER BY timestamp;`
+
+	SelectTicketSpendTypeByBlock = `SELECT block_height, spend_type, price
+		FROM tickets
+		WHERE block_height > $1
+		AND pool_status > 1
+		ORDER BY block_height;`
+
+	// Updates
+
+	SetTicketSpendingInfoForHash = `UPDATE tickets
+		SET spend_type = $5, spend_height = $3, spend_tx_db_id = $4, pool_status = $6
+		WHERE tx_hash = $1 and block_hash = $2;`
+	SetTicketSpendingInfoForTicketDbID = `UPDATE tickets
+		SET spend_type = $4, spend_height = $2, spend_tx_db_id = $3, pool_status = $5
+		WHERE id = $1;`
+	SetTicketSpendingInfoForTxDbID = `UPDATE tickets
+		SET spend_type = $4, spend_height = $2, spend_tx_db_id = $3, pool_status = $5
+		WHERE purchase_tx_db_id = $1;`
+	SetTicketPoolStatusForTicketDbID = `UPDATE tickets SET pool_status = $2 WHERE id = $1;`
+	SetTicketPoolStatusForHash       = `UPDATE tickets SET pool_status = $2 WHERE tx_hash = $1;`
+
+	UpdateTicketsMainchainByBlock = `UPDATE tickets
+		SET is_mainchain=$1
+		WHERE block_hash=$2;`
+
+	// SelectVSPTicketSpends counts the spent mainchain tickets whose votes or
+	// revocations paid one of the addresses, by address, pool status and spend
+	// type. The total blocks from purchase to spend are also selected.
+	SelectVSPTicketSpends = `SELECT address, pool_status, spend_type,
+			COUNT(1), SUM(spend_height - block_height)
+		FROM (
+			SELECT DISTINCT tickets.id, addresses.address, tickets.pool_status,
+				tickets.spend_type, tickets.spend_height, tickets.block_height
+			FROM tickets
+			JOIN transactions ON transactions.id = tickets.spend_tx_db_id
+			JOIN addresses ON addresses.tx_hash = transactions.tx_hash
+				AND addresses.is_funding
+			WHERE tickets.is_mainchain
+				AND addresses.address IN (SELECT value FROM json_each($1))
+		) AS spent
+		GROUP BY address, pool_status, spend_type;`
+
+	// SelectTicketSpendAddresses selects the purchase height, price, pool
+	// status and spend type of the mainchain tickets with the hashes, and the
+	// first of the addresses that was paid by each ticket's vote or revocation.
+	SelectTicketSpendAddresses = `SELECT tickets.tx_hash, tickets.block_height,
+			tickets.price, tickets.pool_status, tickets.spend_type,
+			(SELECT addresses.address
+				FROM transactions
+				JOIN addresses ON addresses.tx_hash = transactions.tx_hash
+					AND addresses.is_funding
+				WHERE transactions.id = tickets.spend_tx_db_id
+					AND addresses.address IN (SELECT value FROM json_each($2))
+				LIMIT 1)
+		FROM tickets
+		WHERE tickets.tx_hash IN (SELECT unhex(value) FROM json_each($1))
+			AND tickets.is_mainchain;`
+
+	// SelectMeanVoteAges selects the number and mean age in blocks of the
+	// mainchain votes in a range of blocks, binned by vote height.
+	SelectMeanVoteAges = `SELECT spend_height / $3 AS bin, COUNT(1),
+			AVG(spend_height - block_height)
+		FROM tickets
+		WHERE is_mainchain AND pool_status = 1
+			AND spend_height BETWEEN $1 AND $2
+		GROUP BY bin
+		ORDER BY bin;`
+
+	// votes table
+
+	// CreateVotesTable creates a new table named votes. block_time field is
+	// needed to plot "Cumulative Vote Choices" agendas chart that plots
+	// cumulative votes count against time over the voting period.
+	CreateVotesTable = `CREATE TABLE IF NOT EXISTS votes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		height INTEGER,
+		tx_hash BLOB NOT NULL,
+		block_hash BLOB NOT NULL,
+		candidate_block_hash BLOB NOT NULL,
+		version INTEGER,
+		vote_bits INTEGER,
+		block_valid BOOLEAN,
+		ticket_hash BLOB,
+		ticket_tx_db_id INTEGER,
+		ticket_price REAL,
+		vote_reward REAL,
+		is_mainchain BOOLEAN,
+		block_time TIMESTAMP
+	);`
+
+	// insertVoteRow is the basis for several vote insert/upsert statements.
+	insertVoteRow = `INSERT INTO votes (
+		height, tx_hash,
+		block_hash, candidate_block_hash,
+		version, vote_bits, block_valid,
+		ticket_hash, ticket_tx_db_id, ticket_price, vote_reward,
+		is_mainchain, block_time)
+	VALUES (
+		$1, $2,
+		$3, $4,
+		$5, $6, $7,
+		$8, $9, $10, $11,
+		$12, $13) `
+
+	// UpsertVoteRow is an upsert (insert or update on conflict), returning the
+	// inserted/updated vote row id. is_mainchain is updated as this might be a
+	// reorganization.
+	UpsertVoteRow = insertVoteRow + `ON CONFLICT (tx_hash, block_hash) DO UPDATE
+		SET is_mainchain = $12 RETURNING id;`
+
+	// InsertVoteRowOnConflictDoNothing inserts a vote row, or returns the id
+	// of the existing row that causes the conflict. See
+	// InsertTxRowOnConflictDoNothing.
+	InsertVoteRowOnConflictDoNothing = insertVoteRow + `ON CONFLICT (tx_hash, block_hash) DO UPDATE
+		SET id = id RETURNING id;`
+
+	UpdateVotesMainchainByBlock = `UPDATE votes
+		SET is_mainchain=$1
+		WHERE block_hash=$2;`
+
+	// misses table
+
+	CreateMissesTable = `CREATE TABLE IF NOT EXISTS misses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		height INTEGER,
+		block_hash BLOB NOT NULL,
+		candidate_block_hash BLOB NOT NULL,
+		ticket_hash BLOB NOT NULL
+	);`
+
+	// insertMissRow is the basis for several miss insert/upsert statements.
+	insertMissRow = `INSERT INTO misses (
+		height, block_hash, candidate_block_hash, ticket_hash)
+	VALUES (
+		$1, $2, $3, $4) `
+
+	// UpsertMissRow is an upsert (insert or update on conflict), returning
+	// the inserted/updated miss row id.
+	UpsertMissRow = insertMissRow + `ON CONFLICT (ticket_hash, block_hash) DO UPDATE
+		SET ticket_hash = $4, block_hash = $2 RETURNING id;`
+
+	// InsertMissRowOnConflictDoNothing inserts a miss row, or returns the id
+	// of the existing row that causes the conflict. See
+	// InsertTxRowOnConflictDoNothing.
+	InsertMissRowOnConflictDoNothing = insertMissRow + `ON CONFLICT (ticket_hash, block_hash) DO UPDATE
+		SET id = id RETURNING id;`
+
+	SelectMissesInBlock = `SELECT ticket_hash FROM misses WHERE block_hash = $1;`
+
+	SelectMissesForTicket = `SELECT height, block_hash FROM misses WHERE ticket_hash = $1;`
+
+	SelectMissesMainchainForTicket = `SELECT misses.height, misses.block_hash
+		FROM misses
+		JOIN blocks ON misses.block_hash=blocks.hash
+		WHERE ticket_hash = $1
+			AND blocks.is_mainchain = TRUE;`
+
+	SelectMissCountPerBlock = `SELECT blocks.height,
+			CASE WHEN misses.block_hash IS NULL THEN 0 ELSE count(*) END
+		FROM blocks
+		LEFT JOIN misses
+		ON misses.block_hash = blocks.hash
+		WHERE blocks.height > $1
+			AND blocks.is_mainchain
+			GROUP BY blocks.hash, blocks.height, misses.block_hash
+			ORDER BY blocks.height;`
+
+	SelectMissCountForBlockRange = `SELECT count(1) FROM misses WHERE height >= $1 AND height <= $2;`
+
+	// agendas table
+
+	CreateAgendasTable = `CREATE TABLE IF NOT EXISTS agendas (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT,
+		status INTEGER,
+		locked_in INTEGER,
+		activated INTEGER,
+		hard_forked INTEGER
+	);`
+
+	// UpsertAgendaRow inserts an agenda or updates the milestones of an
+	// existing agenda.
+	UpsertAgendaRow = `INSERT INTO agendas (name, status, locked_in, activated,
+		hard_forked) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE
+		SET status = $2, locked_in = $3, activated = $4, hard_forked = $5 RETURNING id;`
+
+	SelectAllAgendas = `SELECT id, name, status, locked_in, activated, hard_forked
+		FROM agendas;`
+
+	SelectAgendasLockedIn = `SELECT locked_in FROM agendas WHERE name = $1;`
+
+	SelectAgendasHardForked = `SELECT hard_forked FROM agendas WHERE name = $1;`
+
+	SelectAgendasActivated = `SELECT activated FROM agendas WHERE name = $1;`
+
+	SetVoteMileStoneheights = `UPDATE agendas SET status = $2, locked_in = $3,
+		activated = $4, hard_forked = $5 WHERE id = $1;`
+
+	// agendas votes table
+
+	CreateAgendaVotesTable = `CREATE TABLE IF NOT EXISTS agenda_votes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		votes_row_id INTEGER,
+		agendas_row_id INTEGER,
+		agenda_vote_choice INTEGER
+	);`
+
+	// UpsertAgendaVotesRow inserts a vote choice for an agenda or updates the
+	// choice of an existing row.
+	UpsertAgendaVotesRow = `INSERT INTO agenda_votes (votes_row_id, agendas_row_id,
+		agenda_vote_choice) VALUES ($1, $2, $3)
+		ON CONFLICT (votes_row_id, agendas_row_id) DO UPDATE
+		SET agenda_vote_choice = $3 RETURNING id;`
+
+	// Select
+
+	// SelectAgendasVotesByTime groups the votes by block time, returned as a
+	// UNIX time stamp.
+	SelectAgendasVotesByTime = `SELECT unixepoch(votes.block_time) AS timestamp,` +
+		selectAgendaVotesQuery + `GROUP BY timestamp ORDER BY timestamp;`
+
+	SelectAgendasVotesByHeight = `SELECT votes.height AS height,` +
+		selectAgendaVotesQuery + `GROUP BY height ORDER BY height;`
+
+	SelectAgendaVoteTotals = `SELECT ` + selectAgendaVotesQuery + `;`
+
+	// SelectAgendaVotesByRCI counts the vote choices in each rule change
+	// interval, numbered from the stake validation height ($7) by the interval
+	// length ($8).
+	SelectAgendaVotesByRCI = `SELECT (votes.height - $7) / $8 AS rci,` +
+		selectAgendaVotesQuery + `GROUP BY rci ORDER BY rci;`
+
+	// SelectVoteVersionsByRCI counts the mainchain votes of each version in
+	// each rule change interval, numbered as for SelectAgendaVotesByRCI.
+	SelectVoteVersionsByRCI = `SELECT (height - $1) / $2 AS rci, version, count(*)
+		FROM votes
+		WHERE height >= $3 AND height <= $4 AND is_mainchain = TRUE
+		GROUP BY rci, version
+		ORDER BY rci, version;`
+
+	selectAgendaVotesQuery = `
+			count(CASE WHEN agenda_votes.agenda_vote_choice = $1 THEN 1 ELSE NULL END) AS yes,
+			count(CASE WHEN agenda_votes.agenda_vote_choice = $2 THEN 1 ELSE NULL END) AS abstain,
+			count(CASE WHEN agenda_votes.agenda_vote_choice = $3 THEN 1 ELSE NULL END) AS no,
+			count(*) AS total
+		FROM agenda_votes
+		INNER JOIN votes ON agenda_votes.votes_row_id = votes.id
+		WHERE agenda_votes.agendas_row_id = (SELECT id from agendas WHERE name = $4)
+			AND votes.height >= $5 AND votes.height <= $6
+			AND votes.is_mainchain = TRUE `
+)
+
+// MakeTicketInsertStatement returns the appropriate tickets insert statement
+// for the desired conflict handling behavior. See MakeTxInsertStatement.
+func MakeTicketInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertTicketRow
+	}
+	return InsertTicketRowOnConflictDoNothing
+}
+
+// MakeVoteInsertStatement returns the appropriate votes insert statement for
+// the desired conflict handling behavior. See MakeTxInsertStatement.
+func MakeVoteInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertVoteRow
+	}
+	return InsertVoteRowOnConflictDoNothing
+}
+
+// MakeMissInsertStatement returns the appropriate misses insert statement for
+// the desired conflict handling behavior. See MakeTxInsertStatement.
+func MakeMissInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertMissRow
+	}
+	return InsertMissRowOnConflictDoNothing
+}
+
+// MakeSelectTicketsByPurchaseDate returns the selectTicketsByPurchaseDate query
+func MakeSelectTicketsByPurchaseDate(group string) string {
+	return formatGroupingQuery(selectTicketsByPurchaseDate, group, "transactions.block_time")
+}
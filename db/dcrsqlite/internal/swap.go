@@ -0,0 +1,27 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+const (
+	CreateAtomicSwapTable = `CREATE TABLE IF NOT EXISTS swaps (
+		contract_tx BLOB,
+		contract_vout INTEGER,
+		spend_tx BLOB,
+		spend_vin INTEGER,
+		spend_height INTEGER,
+		p2sh_addr TEXT,
+		value INTEGER,
+		secret_hash BLOB,
+		secret BLOB,        -- NULL for refund
+		lock_time INTEGER,
+		CONSTRAINT spend_tx_in PRIMARY KEY (spend_tx, spend_vin)
+	);`
+
+	InsertContractSpend = `INSERT INTO swaps (contract_tx, contract_vout, spend_tx, spend_vin, spend_height,
+		p2sh_addr, value, secret_hash, secret, lock_time)
+	VALUES ($1, $2, $3, $4, $5,
+		$6, $7, $8, $9, $10)
+	ON CONFLICT (spend_tx, spend_vin)
+		DO UPDATE SET spend_height = $5;`
+)
@@ -0,0 +1,123 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "treasury" table.
+const (
+	CreateTreasuryTable = `CREATE TABLE IF NOT EXISTS treasury (
+		tx_hash BLOB,
+		tx_type INTEGER,
+		value INTEGER,
+		block_hash BLOB,
+		block_height INTEGER,
+		block_time TIMESTAMP NOT NULL,
+		is_mainchain BOOLEAN
+	);`
+
+	UpdateTreasuryMainchainByBlock = `UPDATE treasury
+		SET is_mainchain=$1
+		WHERE block_hash=$2;`
+
+	// insertTreasuryRow is the basis for the treasury insert/upsert statements.
+	insertTreasuryRow = `INSERT INTO treasury (
+		tx_hash, tx_type, value, block_hash, block_height, block_time, is_mainchain)
+	VALUES ($1, $2, $3,	$4, $5, $6, $7) `
+
+	// UpsertTreasuryRow is an upsert (insert or update on conflict), returning
+	// the inserted/updated treasury row id. is_mainchain is updated as this
+	// might be a reorganization.
+	UpsertTreasuryRow = insertTreasuryRow + `ON CONFLICT (tx_hash, block_hash)
+		DO UPDATE SET is_mainchain = $7;`
+
+	// InsertTreasuryRowOnConflictDoNothing allows an INSERT with a DO NOTHING
+	// on conflict with a treasury tnx's unique tx index.
+	InsertTreasuryRowOnConflictDoNothing = insertTreasuryRow + `ON CONFLICT (tx_hash, block_hash)
+		DO NOTHING;`
+
+	SelectTreasuryTxns = `SELECT tx_hash, tx_type, value, block_hash,
+			block_height, block_time, is_mainchain
+		FROM treasury
+		WHERE is_mainchain
+		ORDER BY block_height DESC
+		LIMIT $1 OFFSET $2;`
+
+	SelectTypedTreasuryTxns = `SELECT tx_hash, tx_type, value, block_hash,
+			block_height, block_time, is_mainchain
+		FROM treasury
+		WHERE is_mainchain
+			AND tx_type = $1
+		ORDER BY block_height DESC
+		LIMIT $2 OFFSET $3;`
+
+	SelectTreasuryBalance = `SELECT
+		tx_type,
+		COUNT(CASE WHEN block_height <= $1 THEN 1 END),
+		COUNT(1),
+		SUM(CASE WHEN block_height <= $1 THEN value ELSE 0 END),
+		SUM(value)
+		FROM treasury
+		WHERE is_mainchain
+		GROUP BY tx_type;`
+
+	selectBinnedIO = `SELECT %s as timestamp,
+		SUM(CASE WHEN (tx_type=4 OR tx_type=6) THEN value ELSE 0 END) as received,
+		SUM(CASE WHEN tx_type=5 THEN -value ELSE 0 END) as sent
+		FROM treasury
+		GROUP BY timestamp
+		ORDER BY timestamp;`
+
+	selectStatementTotals = `SELECT %s as period,
+		SUM(CASE WHEN tx_type=6 THEN value ELSE 0 END) as tbase,
+		COUNT(CASE WHEN tx_type=6 THEN 1 END) as tbase_count,
+		SUM(CASE WHEN tx_type=4 THEN value ELSE 0 END) as added,
+		COUNT(CASE WHEN tx_type=4 THEN 1 END) as add_count,
+		SUM(CASE WHEN tx_type=5 THEN -value ELSE 0 END) as spent,
+		COUNT(CASE WHEN tx_type=5 THEN 1 END) as spend_count
+		FROM treasury
+		WHERE is_mainchain
+		GROUP BY period
+		ORDER BY period;`
+
+	// SelectTreasuryAddInputs selects the addresses and values of the previous
+	// outputs spent by the mainchain tadds, grouped by tadd.
+	SelectTreasuryAddInputs = `SELECT treasury.tx_hash, treasury.block_time,
+			treasury.value, addresses.address, addresses.value
+		FROM treasury
+		JOIN addresses ON addresses.tx_hash = treasury.tx_hash
+			AND NOT addresses.is_funding
+		WHERE treasury.is_mainchain
+			AND treasury.tx_type = 4
+		ORDER BY treasury.block_height, treasury.tx_hash, addresses.tx_vin_vout_index;`
+
+	// SelectTreasurySpendPayouts selects the outputs of the mainchain tspends.
+	SelectTreasurySpendPayouts = `SELECT treasury.tx_hash, treasury.block_height,
+			treasury.block_time, addresses.address, addresses.value
+		FROM treasury
+		JOIN addresses ON addresses.tx_hash = treasury.tx_hash
+			AND addresses.is_funding
+		WHERE treasury.is_mainchain
+			AND treasury.tx_type = 5
+		ORDER BY treasury.block_height, treasury.tx_hash, addresses.tx_vin_vout_index;`
+
+	// TODO: CreateTreasuryVotesTable
+)
+
+// MakeTreasuryInsertStatement returns the appropriate treasury insert statement
+// for the desired conflict handling behavior. See MakeTxInsertStatement.
+func MakeTreasuryInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertTreasuryRow
+	}
+	return InsertTreasuryRowOnConflictDoNothing
+}
+
+func MakeSelectTreasuryIOStatement(group string) string {
+	return formatGroupingQuery(selectBinnedIO, group, "block_time")
+}
+
+// MakeSelectTreasuryStatementTotals returns the query for the treasury totals
+// of each period of the given time grouping.
+func MakeSelectTreasuryStatementTotals(group string) string {
+	return formatGroupingQuery(selectStatementTotals, group, "block_time")
+}
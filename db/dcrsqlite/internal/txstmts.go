@@ -0,0 +1,181 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "transactions" table.
+const (
+	CreateTransactionTable = `CREATE TABLE IF NOT EXISTS transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		block_hash BLOB,
+		block_height INTEGER,
+		block_time TIMESTAMP,
+		tx_type INTEGER,
+		version INTEGER,
+		tree INTEGER,
+		tx_hash BLOB,
+		block_index INTEGER,
+		lock_time INTEGER,
+		expiry INTEGER,
+		size INTEGER,
+		spent INTEGER,
+		sent INTEGER,
+		fees INTEGER,
+		mix_count INTEGER,
+		mix_denom INTEGER,
+		num_vin INTEGER,
+		vin_db_ids TEXT,
+		num_vout INTEGER,
+		vout_db_ids TEXT,
+		is_valid BOOLEAN,
+		is_mainchain BOOLEAN
+	);`
+
+	// insertTxRow is the basis for several tx insert/upsert statements.
+	insertTxRow = `INSERT INTO transactions (
+		block_hash, block_height, block_time,
+		tx_type, version, tree, tx_hash, block_index,
+		lock_time, expiry, size, spent, sent, fees,
+		mix_count, mix_denom,
+		num_vin, vin_db_ids, num_vout, vout_db_ids,
+		is_valid, is_mainchain)
+	VALUES (
+		$1, $2, $3,
+		$4, $5, $6, $7, $8,
+		$9, $10, $11, $12, $13, $14,
+		$15, $16,
+		$17, $18, $19, $20,
+		$21, $22) `
+
+	// UpsertTxRow is an upsert (insert or update on conflict), returning the
+	// inserted/updated transaction row id.
+	UpsertTxRow = insertTxRow + `ON CONFLICT (tx_hash, block_hash) DO UPDATE
+		SET is_valid = $21, is_mainchain = $22 RETURNING id;`
+
+	// InsertTxRowOnConflictDoNothing inserts a transaction row, or leaves the
+	// existing row that causes the conflict untouched. SQLite does not return
+	// a row for DO NOTHING, so the no-op update makes RETURNING give the id of
+	// either the inserted row or the existing row.
+	InsertTxRowOnConflictDoNothing = insertTxRow + `ON CONFLICT (tx_hash, block_hash) DO UPDATE
+		SET id = id RETURNING id;`
+
+	SelectTxByHash = `SELECT id, block_hash, block_index, tree
+		FROM transactions
+		WHERE tx_hash = $1
+		ORDER BY is_mainchain DESC, is_valid DESC;`
+	SelectTxsByBlockHash = `SELECT tx_hash, block_index, tree, block_time
+		FROM transactions WHERE block_hash = $1;`
+
+	SelectTxBlockTimeByHash = `SELECT block_time
+		FROM transactions
+		WHERE tx_hash = $1
+		ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC
+		LIMIT 1;`
+
+	SelectFullTxByHash = `SELECT id, block_hash, block_height, block_time,
+			tx_type, version, tree, tx_hash, block_index, lock_time, expiry,
+			size, spent, sent, fees, mix_count, mix_denom, num_vin, vin_db_ids,
+			num_vout, vout_db_ids, is_valid, is_mainchain
+		FROM transactions WHERE tx_hash = $1
+		ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC
+		LIMIT 1;`
+
+	SelectFullTxsByHash = `SELECT id, block_hash, block_height, block_time,
+			tx_type, version, tree, tx_hash, block_index, lock_time, expiry,
+			size, spent, sent, fees, mix_count, mix_denom, num_vin, vin_db_ids,
+			num_vout, vout_db_ids, is_valid, is_mainchain
+		FROM transactions WHERE tx_hash = $1
+		ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC;`
+
+	SelectTxnsVinsVoutsByBlock = `SELECT vin_db_ids, vout_db_ids, is_mainchain
+		FROM transactions WHERE block_hash = $1;`
+
+	SelectRegularTxnsVinsVoutsByBlock = `SELECT vin_db_ids, vout_db_ids, is_mainchain
+		FROM transactions WHERE block_hash = $1 AND tree = 0;`
+
+	SelectTxsBlocks = `SELECT block_height, block_hash, block_index, is_valid, is_mainchain
+		FROM transactions
+		WHERE tx_hash = $1
+		ORDER BY is_valid DESC, is_mainchain DESC, block_height DESC;`
+
+	UpdateRegularTxnsValidByBlock = `UPDATE transactions
+		SET is_valid=$1
+		WHERE block_hash=$2 AND tree=0;`
+
+	UpdateTxnsMainchainByBlock = `UPDATE transactions
+		SET is_mainchain=$1
+		WHERE block_hash=$2
+		RETURNING id;`
+
+	SelectTicketsByType = `SELECT DISTINCT num_vout, COUNT(*)
+		FROM transactions
+		JOIN tickets ON transactions.id=purchase_tx_db_id
+		WHERE pool_status=0
+			AND tickets.is_mainchain
+		GROUP BY num_vout;`
+
+	SelectTxnByDbID = `SELECT block_hash, block_height, tx_hash
+		FROM transactions WHERE id = $1;`
+
+	SelectFeesPerBlockAboveHeight = `
+		SELECT block_height, SUM(fees) AS fees
+		FROM transactions
+		WHERE is_mainchain
+			AND block_height > $1
+		GROUP BY block_height
+		ORDER BY block_height;`
+
+	SelectMixedTotalPerBlock = `
+		SELECT block_height AS block_height,
+			SUM(mix_count * mix_denom) AS total_mixed
+		FROM transactions
+		WHERE is_mainchain
+			AND block_height > $1
+		GROUP BY block_height
+		ORDER BY block_height;`
+
+	SelectMixedVouts = `
+		SELECT vouts.value, fund_tx.block_height, spend_tx.block_height, vouts.tx_tree
+		FROM vouts
+		JOIN transactions AS fund_tx ON vouts.tx_hash=fund_tx.tx_hash
+		LEFT OUTER JOIN transactions AS spend_tx ON spend_tx_row_id=spend_tx.id
+		WHERE (spend_tx.block_height > $1 OR spend_tx.block_height IS NULL)
+			AND mixed AND value>0
+			AND fund_tx.is_mainchain
+		ORDER BY fund_tx.block_height;`
+
+	// SelectCoinAgeEvents selects the value funded in each mainchain block
+	// after the given height, and the value spent in each of those blocks by
+	// funding height, as (height, fund height, value, spend) rows ordered by
+	// height, with the value funded before the value spent in each block.
+	SelectCoinAgeEvents = `
+		SELECT height, fund_height, SUM(value), spend FROM (
+			SELECT fund_tx.block_height AS height, fund_tx.block_height AS fund_height,
+				vouts.value, FALSE AS spend
+			FROM transactions AS fund_tx
+			JOIN vouts ON vouts.tx_hash=fund_tx.tx_hash
+			WHERE fund_tx.block_height > $1 AND fund_tx.is_mainchain AND vouts.value>0
+			UNION ALL
+			SELECT spend_tx.block_height, fund_tx.block_height, vouts.value, TRUE
+			FROM transactions AS spend_tx
+			JOIN vouts ON vouts.spend_tx_row_id=spend_tx.id
+			JOIN transactions AS fund_tx ON vouts.tx_hash=fund_tx.tx_hash
+			WHERE spend_tx.block_height > $1 AND spend_tx.is_mainchain
+				AND fund_tx.is_mainchain AND vouts.value>0
+		) AS events
+		GROUP BY height, fund_height, spend
+		ORDER BY height, spend;`
+)
+
+// MakeTxInsertStatement returns the appropriate transaction insert statement
+// for the desired conflict handling behavior. For updateOnConflict=true, an
+// upsert statement will be provided that UPDATEs the conflicting row. For
+// updateOnConflict=false, the statement will either insert or do nothing, and
+// return the inserted (new) or conflicting (unmodified) row id. Unlike dcrpg,
+// the unique indexes always exist, so there is no unchecked insert.
+func MakeTxInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertTxRow
+	}
+	return InsertTxRowOnConflictDoNothing
+}
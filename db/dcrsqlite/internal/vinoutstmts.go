@@ -0,0 +1,150 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "vins" and "vouts" tables.
+const (
+	// vins
+
+	CreateVinTable = `CREATE TABLE IF NOT EXISTS vins (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tx_hash BLOB,
+		tx_index INTEGER,
+		tx_tree INTEGER,
+		is_valid BOOLEAN,
+		is_mainchain BOOLEAN,
+		block_time TIMESTAMP,
+		prev_tx_hash BLOB,
+		prev_tx_index INTEGER,
+		prev_tx_tree INTEGER,
+		value_in INTEGER,
+		tx_type INTEGER
+	);`
+
+	// insertVinRow is the basis for several vins insert/upsert statements.
+	insertVinRow = `INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
+		value_in, is_valid, is_mainchain, block_time, tx_type) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) `
+
+	// UpsertVinRow is an upsert (insert or update on conflict), returning the
+	// inserted/updated vin row id.
+	UpsertVinRow = insertVinRow + `ON CONFLICT (tx_hash, tx_index, tx_tree) DO UPDATE
+		SET is_valid = $8, is_mainchain = $9, block_time = $10,
+			prev_tx_hash = $4, prev_tx_index = $5, prev_tx_tree = $6
+		RETURNING id;`
+
+	// InsertVinRowOnConflictDoNothing inserts a vin row, or returns the id of
+	// the existing row that causes the conflict. See
+	// InsertTxRowOnConflictDoNothing.
+	InsertVinRowOnConflictDoNothing = insertVinRow + `ON CONFLICT (tx_hash, tx_index, tx_tree) DO UPDATE
+		SET id = id RETURNING id;`
+
+	SelectSpendingTxsByPrevTx                = `SELECT id, tx_hash, tx_index, prev_tx_index FROM vins WHERE prev_tx_hash=$1;`
+	SelectSpendingTxsByPrevTxWithBlockHeight = `SELECT prev_tx_index, vins.tx_hash, vins.tx_index, block_height
+		FROM vins LEFT JOIN transactions ON
+			transactions.tx_hash=vins.tx_hash AND
+			transactions.is_valid AND
+			transactions.is_mainchain
+		WHERE prev_tx_hash=$1 AND vins.is_valid AND vins.is_mainchain;`
+	SelectSpendingTxByPrevOut = `SELECT id, tx_hash, tx_index FROM vins
+		WHERE prev_tx_hash=$1 AND prev_tx_index=$2 ORDER BY is_valid DESC, is_mainchain DESC, block_time DESC;`
+
+	SelectFundingOutpointIndxByVinID = `SELECT prev_tx_index FROM vins WHERE id=$1;`
+	SelectAllVinInfoByID             = `SELECT tx_hash, tx_index, tx_tree, is_valid, is_mainchain, block_time,
+		prev_tx_hash, prev_tx_index, prev_tx_tree, value_in, tx_type FROM vins WHERE id = $1;`
+
+	SelectUTXOs = `SELECT vouts.id, vouts.tx_hash, vouts.tx_index, vouts.script_addresses, vouts.value, vouts.mixed
+		FROM vouts
+		JOIN transactions ON transactions.tx_hash=vouts.tx_hash
+		WHERE vouts.spend_tx_row_id IS NULL AND vouts.value>0
+			AND transactions.is_mainchain AND transactions.is_valid;`
+
+	SetIsValidIsMainchainByTxHash = `UPDATE vins SET is_valid = $1, is_mainchain = $2
+		WHERE tx_hash = $3 AND block_time = $4;`
+	SetIsMainchainByVinID = `UPDATE vins SET is_mainchain = $2
+		WHERE id = $1;`
+
+	// SelectCoinSupply fetches the newly minted atoms per block by filtering
+	// for stakebase, treasurybase, and stake-validated coinbase transactions.
+	SelectCoinSupply = `SELECT vins.block_time, sum(vins.value_in)
+		FROM vins JOIN transactions
+		ON vins.tx_hash = transactions.tx_hash
+		WHERE vins.prev_tx_hash = ` + ZeroHash + `
+		AND transactions.block_height > $1
+		AND vins.is_mainchain AND (vins.is_valid OR vins.tx_tree != 0)
+		AND vins.tx_type IN (0, 2, 6) -- coinbase(regular),ssgen,treasurybase, but NOT tspend
+		GROUP BY vins.block_time, transactions.block_height
+		ORDER BY transactions.block_height;`
+
+	// vouts
+
+	CreateVoutTable = `CREATE TABLE IF NOT EXISTS vouts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tx_hash BLOB,
+		tx_index INTEGER,
+		tx_tree INTEGER,
+		value INTEGER,
+		version INTEGER,
+		script_type TEXT,
+		script_addresses TEXT,
+		mixed BOOLEAN DEFAULT FALSE,
+		spend_tx_row_id INTEGER
+	);`
+
+	// insertVoutRow is the basis for several vout insert/upsert statements.
+	insertVoutRow = `INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
+		version, script_type, script_addresses, mixed)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ` // not with spend_tx_row_id
+
+	// UpsertVoutRow is an upsert (insert or update on conflict), returning the
+	// inserted/updated vout row id.
+	UpsertVoutRow = insertVoutRow + `ON CONFLICT (tx_hash, tx_index, tx_tree) DO UPDATE
+		SET version = $5 RETURNING id;`
+
+	// InsertVoutRowOnConflictDoNothing inserts a vout row, or returns the id
+	// of the existing row that causes the conflict. See
+	// InsertTxRowOnConflictDoNothing.
+	InsertVoutRowOnConflictDoNothing = insertVoutRow + `ON CONFLICT (tx_hash, tx_index, tx_tree) DO UPDATE
+		SET id = id RETURNING id;`
+
+	UpdateVoutSpendTxRowID = `UPDATE vouts SET spend_tx_row_id = $1 WHERE id = $2;`
+
+	// UpdateVoutsSpendTxRowID sets spend_tx_row_id for the vouts with the row
+	// ids in the JSON array $2.
+	UpdateVoutsSpendTxRowID = `UPDATE vouts SET spend_tx_row_id = $1
+		WHERE id IN (SELECT value FROM json_each($2));`
+
+	// ResetVoutSpendTxRowIDs resets spend_tx_row_id for vouts given a JSON
+	// array of transaction row ids. e.g. For rolled-back/purged transactions
+	// that no longer spend the targeted vouts (previous outputs).
+	ResetVoutSpendTxRowIDs = `UPDATE vouts SET spend_tx_row_id = NULL
+		WHERE spend_tx_row_id IN (SELECT value FROM json_each($1));`
+
+	SelectVoutByID = `SELECT id, tx_hash, tx_index, tx_tree, value, version,
+			script_type, script_addresses, mixed, spend_tx_row_id
+		FROM vouts WHERE id=$1;`
+
+	SelectVoutAddressesByTxOut = `SELECT id, script_addresses, value, mixed FROM vouts
+		WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3;`
+
+	RetrieveVoutValue  = `SELECT value FROM vouts WHERE tx_hash=$1 and tx_index=$2;`
+	RetrieveVoutValues = `SELECT value, tx_index, tx_tree FROM vouts WHERE tx_hash=$1;`
+)
+
+// MakeVinInsertStatement returns the appropriate vins insert statement for the
+// desired conflict handling behavior. See MakeTxInsertStatement.
+func MakeVinInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertVinRow
+	}
+	return InsertVinRowOnConflictDoNothing
+}
+
+// MakeVoutInsertStatement returns the appropriate vouts insert statement for
+// the desired conflict handling behavior. See MakeTxInsertStatement.
+func MakeVoutInsertStatement(updateOnConflict bool) string {
+	if updateOnConflict {
+		return UpsertVoutRow
+	}
+	return InsertVoutRowOnConflictDoNothing
+}
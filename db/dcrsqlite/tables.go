@@ -0,0 +1,113 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrsqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/decred/dcrdata/db/dcrsqlite/internal"
+)
+
+var createTableStatements = [][2]string{
+	{"meta", internal.CreateMetaTable},
+	{"blocks", internal.CreateBlockTable},
+	{"transactions", internal.CreateTransactionTable},
+	{"vins", internal.CreateVinTable},
+	{"vouts", internal.CreateVoutTable},
+	{"block_chain", internal.CreateBlockPrevNextTable},
+	{"addresses", internal.CreateAddressTable},
+	{"tickets", internal.CreateTicketsTable},
+	{"votes", internal.CreateVotesTable},
+	{"misses", internal.CreateMissesTable},
+	{"agendas", internal.CreateAgendasTable},
+	{"agenda_votes", internal.CreateAgendaVotesTable},
+	{"stats", internal.CreateStatsTable},
+	{"treasury", internal.CreateTreasuryTable},
+	{"swaps", internal.CreateAtomicSwapTable},
+	{"exchange_rates", internal.CreateExchangeRatesTable},
+}
+
+// TableExists checks if the specified table exists.
+func TableExists(db *sql.DB, tableName string) (bool, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = $1`,
+		tableName)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if e := rows.Close(); e != nil {
+			log.Errorf("Close of Query failed: %v", e)
+		}
+	}()
+	return rows.Next(), rows.Err()
+}
+
+func dropTable(db *sql.DB, tableName string) error {
+	_, err := db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, tableName))
+	return err
+}
+
+// DropTables drops all of the tables internally recognized tables.
+func DropTables(db *sql.DB) {
+	lastIndex := len(createTableStatements) - 1
+	for i := range createTableStatements {
+		pair := createTableStatements[lastIndex-i]
+		tableName := pair[0]
+		log.Infof("DROPPING the %q table.", tableName)
+		if err := dropTable(db, tableName); err != nil {
+			log.Errorf("DROP TABLE %q; failed.", tableName)
+		}
+	}
+}
+
+// AnalyzeAllTables updates the query planner statistics for all tables.
+func AnalyzeAllTables(db *sql.DB) error {
+	_, err := db.Exec(`ANALYZE;`)
+	if err != nil {
+		return fmt.Errorf("failed to ANALYZE all tables: %w", err)
+	}
+	return nil
+}
+
+// CreateTables creates all tables required by dcrdata if they do not already
+// exist. Unlike dcrpg, the indexes are created with the tables. There is no
+// bulk load mode without indexes since a SQLite database is not intended for a
+// mainnet explorer.
+func CreateTables(db *sql.DB) error {
+	for _, pair := range createTableStatements {
+		if err := createTable(db, pair[0], pair[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range internal.CreateIndexStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+	return nil
+}
+
+// createTable creates a table with the given name using the provided SQL
+// statement, if it does not already exist.
+func createTable(db *sql.DB, tableName, stmt string) error {
+	exists, err := TableExists(db, tableName)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		log.Infof(`Creating the "%s" table.`, tableName)
+		_, err = db.Exec(stmt)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Tracef(`Table "%s" exists.`, tableName)
+	}
+
+	return err
+}
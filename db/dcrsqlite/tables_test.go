@@ -0,0 +1,103 @@
+package dcrsqlite
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// internalStatements type checks the internal package and returns its exported
+// string constants by name.
+func internalStatements(t *testing.T) map[string]string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "internal", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, ok := pkgs["internal"]
+	if !ok {
+		t.Fatal("internal package not found")
+	}
+	files := make([]*ast.File, 0, len(pkg.Files))
+	for _, f := range pkg.Files {
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	checked, err := conf.Check("internal", fset, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmts := make(map[string]string)
+	scope := checked.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !c.Exported() || c.Val().Kind() != constant.String {
+			continue
+		}
+		stmts[name] = constant.StringVal(c.Val())
+	}
+	return stmts
+}
+
+// TestPrepareStatements checks that each SQL statement in the internal package
+// is valid for the tables created by CreateTables.
+func TestPrepareStatements(t *testing.T) {
+	db, err := Connect(filepath.Join(t.TempDir(), "dcrdata.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = CreateTables(db); err != nil {
+		t.Fatal(err)
+	}
+
+	stmts := internalStatements(t)
+	names := make([]string, 0, len(stmts))
+	for name := range stmts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var n int
+	for _, name := range names {
+		stmt := stmts[name]
+		// Skip the fragments and format strings that are completed by the
+		// Make* functions.
+		if strings.Contains(stmt, "%") || !isStatement(stmt) {
+			continue
+		}
+		s, err := db.Prepare(stmt)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		s.Close()
+		n++
+	}
+	t.Logf("Prepared %d statements.", n)
+}
+
+func isStatement(stmt string) bool {
+	stmt = strings.ToUpper(strings.TrimSpace(stmt))
+	for _, prefix := range []string{"SELECT", "INSERT", "UPDATE", "DELETE", "WITH", "CREATE", "DROP", "ANALYZE"} {
+		if strings.HasPrefix(stmt, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrsqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/decred/dcrdata/db/dcrsqlite/internal"
+)
+
+// The database schema is versioned in the meta table as follows. Unlike dcrpg,
+// only the schema upgrades that create new tables are automated. Any other
+// schema change requires a rebuild.
+const (
+	// compatVersion indicates DB changes for which a complete DB rebuild is
+	// required.
+	compatVersion = 1
+
+	// schemaVersion pertains to incremental changes to the database schema for
+	// the same compatibility version.
+	schemaVersion = 1
+
+	// maintVersion indicates when certain maintenance operations should be
+	// performed for the same compatVersion and schemaVersion.
+	maintVersion = 0
+)
+
+var (
+	targetDatabaseVersion = &DatabaseVersion{
+		compat: compatVersion,
+		schema: schemaVersion,
+		maint:  maintVersion,
+	}
+)
+
+// DatabaseVersion models a database version.
+type DatabaseVersion struct {
+	compat, schema, maint uint32
+}
+
+// String implements Stringer for DatabaseVersion.
+func (v DatabaseVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.compat, v.schema, v.maint)
+}
+
+// NewDatabaseVersion returns a new DatabaseVersion with the version major.minor.patch
+func NewDatabaseVersion(major, minor, patch uint32) DatabaseVersion {
+	return DatabaseVersion{major, minor, patch}
+}
+
+// DBVersion retrieves the database version from the meta table. See
+// (*DatabaseVersion).NeededToReach for version comparison.
+func DBVersion(db *sql.DB) (ver DatabaseVersion, err error) {
+	err = db.QueryRow(internal.SelectMetaDBVersions).Scan(&ver.compat, &ver.schema, &ver.maint)
+	return
+}
+
+// CompatAction defines the action to be taken once the current and the required
+// table versions are compared.
+type CompatAction int8
+
+// These are the recognized CompatActions for upgrading a database from one
+// version to another.
+const (
+	Rebuild CompatAction = iota
+	Upgrade
+	Maintenance
+	OK
+	TimeTravel
+	Unknown
+)
+
+// NeededToReach describes what action is required for the DatabaseVersion to
+// reach another version provided in the input argument.
+func (v *DatabaseVersion) NeededToReach(other *DatabaseVersion) CompatAction {
+	switch {
+	case v.compat < other.compat:
+		return Rebuild
+	case v.compat > other.compat:
+		return TimeTravel
+	case v.schema < other.schema:
+		return Upgrade
+	case v.schema > other.schema:
+		return TimeTravel
+	case v.maint < other.maint:
+		return Maintenance
+	case v.maint > other.maint:
+		return TimeTravel
+	default:
+		return OK
+	}
+}
+
+// String implements Stringer for CompatAction.
+func (v CompatAction) String() string {
+	actions := map[CompatAction]string{
+		Rebuild:     "rebuild",
+		Upgrade:     "upgrade",
+		Maintenance: "maintenance",
+		TimeTravel:  "time travel",
+		OK:          "ok",
+	}
+	if actionStr, ok := actions[v]; ok {
+		return actionStr
+	}
+	return "unknown"
+}
+
+type metaData struct {
+	netName         string
+	currencyNet     uint32
+	bestBlockHeight int64
+	dbVer           DatabaseVersion
+}
+
+func initMetaData(db *sql.DB, meta *metaData) error {
+	_, err := db.Exec(internal.InitMetaRow, meta.netName, meta.currencyNet,
+		meta.bestBlockHeight,
+		meta.dbVer.compat, meta.dbVer.schema, meta.dbVer.maint,
+		false /* meta.ibdComplete */)
+	return err
+}
+
+func updateSchemaVersion(db *sql.DB, schema uint32) error {
+	_, err := db.Exec(internal.SetDBSchemaVersion, schema)
+	return err
+}
+
+// upgradeDatabase performs the schema upgrades from the current version to
+// targetDatabaseVersion.
+func upgradeDatabase(db *sql.DB, current DatabaseVersion) error {
+	if action := current.NeededToReach(targetDatabaseVersion); action != Upgrade {
+		return fmt.Errorf("DB schema version %v is not compatible with version %v (%v required)",
+			current, targetDatabaseVersion, action)
+	}
+
+	switch current.schema {
+	case 0:
+		// Upgrade to schema v1, which adds the exchange_rates table.
+		log.Infof("Performing database upgrade 1.0.0 -> 1.1.0")
+		err := createTable(db, "exchange_rates", internal.CreateExchangeRatesTable)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade 1.0.0 to 1.1.0: %w", err)
+		}
+		current.schema++
+		if err = updateSchemaVersion(db, current.schema); err != nil {
+			return fmt.Errorf("failed to update schema version: %w", err)
+		}
+		fallthrough
+
+	case 1:
+		// No further upgrades.
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported schema version %d", current.schema)
+	}
+}
@@ -0,0 +1,42 @@
+package dcrsqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+)
+
+func TestUpgradeDatabase(t *testing.T) {
+	db, err := open(filepath.Join(t.TempDir(), "dcrdata.sqlite"), chaincfg.SimNetParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Revert to schema v0, which has no exchange_rates table.
+	if err = dropTable(db, "exchange_rates"); err != nil {
+		t.Fatal(err)
+	}
+	if err = updateSchemaVersion(db, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	ver, action, err := versionCheck(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != Upgrade {
+		t.Fatalf("expected an upgrade from %v, got %v", ver, action)
+	}
+	if err = upgradeDatabase(db, *ver); err != nil {
+		t.Fatal(err)
+	}
+
+	if ver, err := DBVersion(db); err != nil || ver != *targetDatabaseVersion {
+		t.Errorf("expected version %v, got %v (%v)", targetDatabaseVersion, ver, err)
+	}
+	if exists, err := TableExists(db, "exchange_rates"); err != nil || !exists {
+		t.Errorf("exchange_rates table not created (%v)", err)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// aggregateToken is in place of an exchange token in the cache IDs of the
+// charts that are aggregated across exchanges.
+const aggregateToken = "aggregate"
+
+// aggregateDepthResponse is a depth chart of the combined order books of the
+// exchange markets in Sources, with prices in the index currency.
+type aggregateDepthResponse struct {
+	depthResponse
+	Sources map[string][]CurrencyPair `json:"sources"`
+}
+
+// aggregateStickResponse is candlesticks combined across the exchange markets
+// in Sources, with prices in the index currency.
+type aggregateStickResponse struct {
+	candlestickResponse
+	Sources map[string][]CurrencyPair `json:"sources"`
+}
+
+// marketSource is an exchange market's data for an aggregate chart, and the
+// factor that converts its prices to the index currency.
+type marketSource struct {
+	token  string
+	market CurrencyPair
+	state  *ExchangeState
+	factor float64
+}
+
+// aggregateSources is the markets of the exchanges with data no older than
+// RequestExpiry, and with prices that can be converted to the index currency.
+// Must be called under bot.mtx lock.
+func (bot *ExchangeBot) aggregateSources() []*marketSource {
+	oldestValid := time.Now().Add(-bot.RequestExpiry)
+	var sources []*marketSource
+	for token, xcStates := range bot.currentState.DCRExchanges {
+		xc := bot.Exchanges[token]
+		if xc == nil || xc.LastUpdate().Before(oldestValid) {
+			continue
+		}
+		for market, state := range xcStates {
+			factor := bot.currentState.PriceToFiat(1, market)
+			if factor == 0 {
+				continue
+			}
+			sources = append(sources, &marketSource{
+				token:  token,
+				market: market,
+				state:  state,
+				factor: factor,
+			})
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].token == sources[j].token {
+			return sources[i].market < sources[j].market
+		}
+		return sources[i].token < sources[j].token
+	})
+	return sources
+}
+
+// sourceMap lists the markets of the sources by exchange.
+func sourceMap(sources []*marketSource) map[string][]CurrencyPair {
+	m := make(map[string][]CurrencyPair)
+	for _, src := range sources {
+		m[src.token] = append(m[src.token], src.market)
+	}
+	return m
+}
+
+// mergeDepthPoints converts the prices of the points by the factors, and
+// combines them into one side of an order book, sorted best price first.
+// Quantities at the same price are summed.
+func mergeDepthPoints(sides [][]DepthPoint, factors []float64, descending bool) []DepthPoint {
+	quantities := make(map[float64]float64)
+	for i, pts := range sides {
+		for _, pt := range pts {
+			quantities[pt.Price*factors[i]] += pt.Quantity
+		}
+	}
+	merged := make([]DepthPoint, 0, len(quantities))
+	for price, qty := range quantities {
+		merged = append(merged, DepthPoint{Quantity: qty, Price: price})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if descending {
+			return merged[i].Price > merged[j].Price
+		}
+		return merged[i].Price < merged[j].Price
+	})
+	return merged
+}
+
+// mergeDepth combines the order books into one, with the prices of each book
+// multiplied by its factor. The time is that of the oldest book.
+func mergeDepth(books []*DepthData, factors []float64) *DepthData {
+	bids := make([][]DepthPoint, 0, len(books))
+	asks := make([][]DepthPoint, 0, len(books))
+	var stamp int64
+	for _, book := range books {
+		bids = append(bids, book.Bids)
+		asks = append(asks, book.Asks)
+		if stamp == 0 || book.Time < stamp {
+			stamp = book.Time
+		}
+	}
+	return &DepthData{
+		Time: stamp,
+		Bids: mergeDepthPoints(bids, factors, true),
+		Asks: mergeDepthPoints(asks, factors, false),
+	}
+}
+
+// stickBinStart is the start of the candlestick bin of the width that
+// contains the time. Bins as wide as a month are calendar months, and shorter
+// bins are aligned to the UNIX epoch.
+func stickBinStart(t time.Time, width time.Duration) time.Time {
+	t = t.UTC()
+	if width >= candlestickDurations[monthKey] {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(width)
+}
+
+// mergeSticks combines the candlesticks in the same bin of the width, with the
+// prices of each set multiplied by its factor. Candlesticks that start at
+// different times in a bin, as with exchanges that do not align their bins to
+// the epoch, are combined in the bin. The volume is summed, the high and low
+// are the highest high and the lowest low, and the open and close are averaged
+// weighted by volume, or equally if there is no volume.
+func mergeSticks(sets []Candlesticks, factors []float64, width time.Duration) Candlesticks {
+	type accumulator struct {
+		Candlestick
+		n float64
+	}
+	bins := make(map[int64]*accumulator)
+	for i, sticks := range sets {
+		f := factors[i]
+		for _, stick := range sticks {
+			start := stickBinStart(stick.Start, width)
+			acc := bins[start.Unix()]
+			if acc == nil {
+				acc = &accumulator{Candlestick: Candlestick{
+					Start: start,
+					High:  stick.High * f,
+					Low:   stick.Low * f,
+				}}
+				bins[start.Unix()] = acc
+			}
+			acc.Volume += stick.Volume
+			acc.High = math.Max(acc.High, stick.High*f)
+			acc.Low = math.Min(acc.Low, stick.Low*f)
+			acc.n++
+		}
+	}
+	for i, sticks := range sets {
+		f := factors[i]
+		for _, stick := range sticks {
+			acc := bins[stickBinStart(stick.Start, width).Unix()]
+			w := 1 / acc.n
+			if acc.Volume > 0 {
+				w = stick.Volume / acc.Volume
+			}
+			acc.Open += w * stick.Open * f
+			acc.Close += w * stick.Close * f
+		}
+	}
+	merged := make(Candlesticks, 0, len(bins))
+	for _, acc := range bins {
+		merged = append(merged, acc.Candlestick)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Start.Before(merged[j].Start)
+	})
+	return merged
+}
+
+// incrementAggregateCharts invalidates the cached aggregate charts. Must be
+// called under bot.mtx lock.
+func (bot *ExchangeBot) incrementAggregateCharts() {
+	bot.incrementChart(genCacheID(aggregateToken, orderbookKey))
+	for bin := range candlestickDurations {
+		bot.incrementChart(genCacheID(aggregateToken, string(bin)))
+	}
+}
+
+// AggregateSticks returns candlesticks for the bin width combined across all
+// exchange markets with current data, converted to the index currency as
+// described for mergeSticks. The chart is pulled from the cache if appropriate.
+func (bot *ExchangeBot) AggregateSticks(rawBin string) ([]byte, error) {
+	bin := candlestickKey(rawBin)
+	if _, found := candlestickDurations[bin]; !found {
+		return nil, fmt.Errorf("invalid bin %s", rawBin)
+	}
+
+	chartID := genCacheID(aggregateToken, rawBin)
+	data, bestVersion, isGood := bot.fetchFromCache(chartID)
+	if isGood {
+		return data, nil
+	}
+
+	bot.mtx.Lock()
+	defer bot.mtx.Unlock()
+	var sets []Candlesticks
+	var factors []float64
+	var sources []*marketSource
+	for _, src := range bot.aggregateSources() {
+		sticks := src.state.Candlesticks[bin]
+		if len(sticks) == 0 {
+			continue
+		}
+		sets = append(sets, sticks)
+		factors = append(factors, src.factor)
+		sources = append(sources, src)
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("Failed to find candlesticks for bin %s", rawBin)
+	}
+
+	sticks := mergeSticks(sets, factors, bin.duration())
+	expiration := sticks[len(sticks)-1].Start.Add(2 * bin.duration())
+	chart, err := bot.encodeJSON(&aggregateStickResponse{
+		candlestickResponse: candlestickResponse{
+			Index:      bot.Index,
+			Price:      bot.currentState.Price,
+			Sticks:     sticks,
+			Expiration: expiration.Unix(),
+		},
+		Sources: sourceMap(sources),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JSON encode error for aggregate bin %s", rawBin)
+	}
+
+	vChart := &versionedChart{
+		chartID: chartID,
+		dataID:  bestVersion,
+		chart:   chart,
+	}
+
+	bot.versionedCharts[chartID] = vChart
+	return vChart.chart, nil
+}
+
+// AggregateDepth returns a depth chart of the combined order books of all
+// exchange markets with fresh depth data, with prices converted to the index
+// currency. The chart is pulled from the cache if appropriate.
+func (bot *ExchangeBot) AggregateDepth() ([]byte, error) {
+	chartID := genCacheID(aggregateToken, orderbookKey)
+	data, bestVersion, isGood := bot.fetchFromCache(chartID)
+	if isGood {
+		return data, nil
+	}
+
+	bot.mtx.Lock()
+	defer bot.mtx.Unlock()
+	var books []*DepthData
+	var factors []float64
+	var sources []*marketSource
+	for _, src := range bot.aggregateSources() {
+		if src.state.Depth == nil || !src.state.Depth.IsFresh() {
+			continue
+		}
+		books = append(books, src.state.Depth)
+		factors = append(factors, src.factor)
+		sources = append(sources, src)
+	}
+	if len(books) == 0 {
+		return nil, fmt.Errorf("Failed to find fresh depth data")
+	}
+
+	depth := mergeDepth(books, factors)
+	chart, err := bot.encodeJSON(&aggregateDepthResponse{
+		depthResponse: depthResponse{
+			BtcIndex:   bot.Index,
+			Price:      bot.currentState.Price,
+			Data:       depth,
+			Expiration: depth.Time + int64(bot.RequestExpiry.Seconds()),
+		},
+		Sources: sourceMap(sources),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JSON encode error for aggregate depth chart")
+	}
+
+	vChart := &versionedChart{
+		chartID: chartID,
+		dataID:  bestVersion,
+		chart:   chart,
+	}
+
+	bot.versionedCharts[chartID] = vChart
+	return vChart.chart, nil
+}
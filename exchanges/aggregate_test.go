@@ -0,0 +1,48 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeSticks(t *testing.T) {
+	hour := time.Unix(1700000000, 0).UTC().Truncate(time.Hour)
+	sets := []Candlesticks{
+		{
+			{High: 12, Low: 9, Open: 10, Close: 11, Volume: 1, Start: hour},
+			{High: 13, Low: 11, Open: 11, Close: 12, Volume: 1, Start: hour.Add(time.Hour)},
+		},
+		// Bins that start 15 minutes into the hour, in a currency at twice
+		// the index price.
+		{
+			{High: 7, Low: 4, Open: 5, Close: 6, Volume: 3, Start: hour.Add(15 * time.Minute)},
+		},
+	}
+	sticks := mergeSticks(sets, []float64{1, 2}, time.Hour)
+	if len(sticks) != 2 {
+		t.Fatalf("got %d candlesticks, expected 2", len(sticks))
+	}
+	stick := sticks[0]
+	if !stick.Start.Equal(hour) || stick.Volume != 4 || stick.High != 14 || stick.Low != 8 {
+		t.Fatalf("wrong combined candlestick %+v", stick)
+	}
+	if !closeTo(stick.Open, (10+3*10)/4.) || !closeTo(stick.Close, (11+3*12)/4.) {
+		t.Fatalf("wrong combined open and close %+v", stick)
+	}
+	if sticks[1] != sets[0][1] {
+		t.Fatalf("single candlestick changed: %+v", sticks[1])
+	}
+
+	// Month bins are calendar months.
+	feb := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	sticks = mergeSticks([]Candlesticks{{
+		{High: 1, Low: 1, Start: feb},
+		{High: 2, Low: 2, Start: feb.AddDate(0, 1, 0)},
+	}}, []float64{1}, candlestickDurations[monthKey])
+	if len(sticks) != 2 || !sticks[1].Start.Equal(feb.AddDate(0, 1, 0)) {
+		t.Fatalf("wrong month candlesticks %+v", sticks)
+	}
+}
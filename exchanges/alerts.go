@@ -0,0 +1,451 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// AlertKind is the condition watched by an AlertRule.
+type AlertKind string
+
+const (
+	// AlertPriceMove triggers when a price has moved by more than the
+	// threshold fraction within the window. The price is the DCR index price,
+	// or the price of a market if the rule has a token and a market.
+	AlertPriceMove AlertKind = "price_move"
+	// AlertExchangeFailing triggers when an exchange has been failing for
+	// longer than the window.
+	AlertExchangeFailing AlertKind = "exchange_failing"
+	// AlertSpread triggers when the spread of an order book, as a fraction of
+	// the mid-gap price, is wider than the threshold.
+	AlertSpread AlertKind = "spread"
+	// AlertDivergence triggers when the BTC price implied by an exchange's
+	// DCR-BTC and DCR-USDT prices differs from the BTC-USDT index price by
+	// more than the threshold fraction.
+	AlertDivergence AlertKind = "divergence"
+)
+
+const (
+	// alertCheckInterval is how often the rules are evaluated without an
+	// update, so that failing exchanges are noticed.
+	alertCheckInterval = time.Minute
+	// webhookTimeout limits a webhook delivery.
+	webhookTimeout = 10 * time.Second
+)
+
+// AlertRule is a user-defined condition that triggers an Alert. A rule
+// triggers once when its condition becomes true, and can trigger again after
+// the condition has cleared and the cooldown has passed. Rules without a token
+// apply to every exchange, and rules without a market to every market of the
+// exchange, except that a price_move rule needs both or neither.
+type AlertRule struct {
+	// Name identifies the rule in its alerts.
+	Name   string       `json:"name"`
+	Kind   AlertKind    `json:"kind"`
+	Token  string       `json:"token,omitempty"`
+	Market CurrencyPair `json:"market,omitempty"`
+	// Threshold is a fraction for the price_move, spread, and divergence
+	// rules, e.g. 0.05 for 5%. It is not used by the exchange_failing rule.
+	Threshold float64 `json:"threshold,omitempty"`
+	// Window is a duration string, e.g. "15m", required by the price_move and
+	// exchange_failing rules.
+	Window string `json:"window,omitempty"`
+	// Cooldown is the minimum time between alerts from the rule for the same
+	// market, as a duration string. The default is no minimum.
+	Cooldown string `json:"cooldown,omitempty"`
+	// Pubsub is whether the alerts are published to the pubsub clients.
+	Pubsub bool `json:"pubsub,omitempty"`
+	// Webhooks are URLs to which the alerts are POSTed as JSON.
+	Webhooks []string `json:"webhooks,omitempty"`
+
+	window   time.Duration
+	cooldown time.Duration
+}
+
+// Validate checks the rule and parses its durations.
+func (rule *AlertRule) Validate() (err error) {
+	if rule.Name == "" {
+		return fmt.Errorf("no name")
+	}
+	switch rule.Kind {
+	case AlertPriceMove, AlertSpread, AlertDivergence:
+		if rule.Threshold <= 0 {
+			return fmt.Errorf("%s: threshold must be positive", rule.Name)
+		}
+	case AlertExchangeFailing:
+	default:
+		return fmt.Errorf("%s: unknown kind %q", rule.Name, rule.Kind)
+	}
+	if rule.Window != "" {
+		if rule.window, err = time.ParseDuration(rule.Window); err != nil {
+			return fmt.Errorf("%s: invalid window: %w", rule.Name, err)
+		}
+	}
+	if rule.window <= 0 && (rule.Kind == AlertPriceMove || rule.Kind == AlertExchangeFailing) {
+		return fmt.Errorf("%s: window must be positive", rule.Name)
+	}
+	if rule.Cooldown != "" {
+		if rule.cooldown, err = time.ParseDuration(rule.Cooldown); err != nil {
+			return fmt.Errorf("%s: invalid cooldown: %w", rule.Name, err)
+		}
+	}
+	// A price_move rule watches the price of a single market, or the index
+	// price if it has neither.
+	if (rule.Market == "") != (rule.Token == "") && rule.Kind == AlertPriceMove {
+		return fmt.Errorf("%s: token and market must be set together", rule.Name)
+	}
+	if !rule.Pubsub && len(rule.Webhooks) == 0 {
+		return fmt.Errorf("%s: no pubsub or webhooks", rule.Name)
+	}
+	return nil
+}
+
+// LoadAlertRules reads and validates a JSON array of alert rules.
+func LoadAlertRules(path string) ([]*AlertRule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var rules []*AlertRule
+	if err = dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to decode alert rules from %s: %v", path, err)
+	}
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule == nil {
+			return nil, fmt.Errorf("null alert rule in %s", path)
+		}
+		if err = rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid alert rule in %s: %v", path, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate alert rule %s in %s", rule.Name, path)
+		}
+		names[rule.Name] = true
+	}
+	return rules, nil
+}
+
+// Alert is a triggered AlertRule. Value is the measurement that crossed the
+// rule's threshold: the fractional price move, spread, or divergence, or the
+// minutes an exchange has been failing.
+type Alert struct {
+	Rule      string       `json:"rule"`
+	Kind      AlertKind    `json:"kind"`
+	Token     string       `json:"token,omitempty"`
+	Market    CurrencyPair `json:"market,omitempty"`
+	Value     float64      `json:"value"`
+	Threshold float64      `json:"threshold"`
+	Message   string       `json:"message"`
+	Stamp     int64        `json:"stamp"`
+}
+
+// pricePoint is a price sampled by a price_move rule.
+type pricePoint struct {
+	stamp time.Time
+	price float64
+}
+
+// alertTarget is the state of a rule for one exchange market.
+type alertTarget struct {
+	active    bool
+	lastAlert time.Time
+	prices    []pricePoint
+}
+
+// alertSnapshot is what the rules are evaluated against.
+type alertSnapshot struct {
+	stamp time.Time
+	state *ExchangeBotState
+	// failing is how long each exchange has been failing, zero if it is not.
+	failing map[string]time.Duration
+	// btcPrice is the BTC-USDT index price.
+	btcPrice float64
+}
+
+// Alerter evaluates AlertRules on the ExchangeBot's updates, and delivers the
+// Alerts to the pubsub notifier and webhooks of the triggered rules. Make an
+// Alerter with NewAlerter.
+type Alerter struct {
+	bot      *ExchangeBot
+	rules    []*AlertRule
+	notify   func(*Alert)
+	channels *UpdateChannels
+	client   *http.Client
+	started  time.Time
+
+	mtx     sync.Mutex
+	targets map[string]*alertTarget
+}
+
+// NewAlerter creates an Alerter of the bot's updates. The notify function
+// receives the alerts of the rules with Pubsub set, and may be nil if there
+// are none.
+func NewAlerter(bot *ExchangeBot, rules []*AlertRule, notify func(*Alert)) *Alerter {
+	return &Alerter{
+		bot:      bot,
+		rules:    rules,
+		notify:   notify,
+		channels: bot.UpdateChannels(),
+		client:   &http.Client{Timeout: webhookTimeout},
+		started:  time.Now(),
+		targets:  make(map[string]*alertTarget),
+	}
+}
+
+// Run evaluates the rules until the context is canceled or the bot quits.
+func (a *Alerter) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	ticker := time.NewTicker(alertCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.channels.Exchange:
+		case <-a.channels.Index:
+		case <-ticker.C:
+		case <-a.channels.Quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+		for _, alert := range a.evaluate(a.snapshot()) {
+			a.deliver(ctx, alert)
+		}
+	}
+}
+
+// snapshot collects the bot's current state.
+func (a *Alerter) snapshot() *alertSnapshot {
+	now := time.Now()
+	snap := &alertSnapshot{
+		stamp:   now,
+		state:   a.bot.State(),
+		failing: make(map[string]time.Duration),
+	}
+	for token, xc := range a.bot.Exchanges {
+		if !xc.IsFailed() {
+			snap.failing[token] = 0
+			continue
+		}
+		// An exchange that has never updated has been failing since the
+		// Alerter started.
+		since := xc.LastUpdate()
+		if since.Before(a.started) {
+			since = a.started
+		}
+		snap.failing[token] = now.Sub(since)
+	}
+	a.bot.mtx.RLock()
+	if usdtPrice := a.bot.indexPrice(USDTIndex, a.bot.Index); usdtPrice > 0 {
+		snap.btcPrice = a.bot.indexPrice(BTCIndex, a.bot.Index) / usdtPrice
+	}
+	a.bot.mtx.RUnlock()
+	return snap
+}
+
+// evaluate checks the rules against the snapshot, returning the alerts of the
+// rules that have triggered.
+func (a *Alerter) evaluate(snap *alertSnapshot) []*Alert {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	var alerts []*Alert
+	check := func(rule *AlertRule, token string, market CurrencyPair, value float64, triggered bool, msg string) {
+		key := rule.Name + "/" + token + "/" + string(market)
+		target := a.target(key)
+		if !triggered {
+			target.active = false
+			return
+		}
+		if target.active || snap.stamp.Sub(target.lastAlert) < rule.cooldown {
+			return
+		}
+		target.active = true
+		target.lastAlert = snap.stamp
+		alerts = append(alerts, &Alert{
+			Rule:      rule.Name,
+			Kind:      rule.Kind,
+			Token:     token,
+			Market:    market,
+			Value:     value,
+			Threshold: rule.Threshold,
+			Message:   msg,
+			Stamp:     snap.stamp.Unix(),
+		})
+	}
+
+	for _, rule := range a.rules {
+		switch rule.Kind {
+		case AlertPriceMove:
+			if snap.state == nil {
+				continue
+			}
+			price, name := snap.state.Price, "DCR index price"
+			if rule.Token != "" {
+				price, name = rulePrice(snap.state, rule.Token, rule.Market), rule.Token+" "+string(rule.Market)
+			}
+			if price <= 0 {
+				continue
+			}
+			move := a.priceMove(rule, snap.stamp, price)
+			check(rule, rule.Token, rule.Market, move, math.Abs(move) > rule.Threshold,
+				fmt.Sprintf("%s moved %.1f%% in %s", name, move*100, rule.window))
+
+		case AlertExchangeFailing:
+			for token, failing := range snap.failing {
+				if rule.Token != "" && token != rule.Token {
+					continue
+				}
+				check(rule, token, "", failing.Minutes(), failing > rule.window,
+					fmt.Sprintf("%s has been failing for %s", token, failing.Truncate(time.Second)))
+			}
+
+		case AlertSpread:
+			if snap.state == nil {
+				continue
+			}
+			for token, markets := range snap.state.DCRExchanges {
+				if rule.Token != "" && token != rule.Token {
+					continue
+				}
+				for market, xcState := range markets {
+					if rule.Market != "" && market != rule.Market {
+						continue
+					}
+					depth := xcState.Depth
+					if depth == nil || len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+						continue
+					}
+					spread := (depth.Asks[0].Price - depth.Bids[0].Price) / depth.MidGap()
+					check(rule, token, market, spread, spread > rule.Threshold,
+						fmt.Sprintf("%s %s spread is %.2f%%", token, market, spread*100))
+				}
+			}
+
+		case AlertDivergence:
+			if snap.state == nil || snap.btcPrice <= 0 {
+				continue
+			}
+			for token, markets := range snap.state.DCRExchanges {
+				if rule.Token != "" && token != rule.Token {
+					continue
+				}
+				btcState, usdtState := markets[CurrencyPairDCRBTC], markets[CurrencyPairDCRUSDT]
+				if btcState == nil || usdtState == nil || btcState.Price <= 0 {
+					continue
+				}
+				implied := usdtState.Price / btcState.Price
+				divergence := implied/snap.btcPrice - 1
+				check(rule, token, "", divergence, math.Abs(divergence) > rule.Threshold,
+					fmt.Sprintf("%s implied BTC price %.2f USDT is %.1f%% from the index price %.2f USDT",
+						token, implied, divergence*100, snap.btcPrice))
+			}
+		}
+	}
+	return alerts
+}
+
+// target gets the state of a rule for an exchange market, creating it if
+// necessary. The Alerter's mtx must be locked.
+func (a *Alerter) target(key string) *alertTarget {
+	target := a.targets[key]
+	if target == nil {
+		target = new(alertTarget)
+		a.targets[key] = target
+	}
+	return target
+}
+
+// priceMove adds the price to the samples of a price_move rule, and returns
+// the largest move of the price from a sample within the rule's window, as a
+// fraction of the sampled price. The Alerter's mtx must be locked.
+func (a *Alerter) priceMove(rule *AlertRule, stamp time.Time, price float64) float64 {
+	target := a.target(rule.Name + "/prices")
+	cutoff := stamp.Add(-rule.window)
+	var move float64
+	kept := target.prices[:0]
+	for _, pt := range target.prices {
+		if pt.stamp.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, pt)
+		if m := price/pt.price - 1; math.Abs(m) > math.Abs(move) {
+			move = m
+		}
+	}
+	target.prices = append(kept, pricePoint{stamp: stamp, price: price})
+	return move
+}
+
+// rulePrice is the price of the exchange or index market in the state, or
+// zero if there is none.
+func rulePrice(state *ExchangeBotState, token string, market CurrencyPair) float64 {
+	if xcState := state.DCRExchanges[token][market]; xcState != nil {
+		return xcState.Price
+	}
+	if xcState := state.FiatIndices[token][market]; xcState != nil {
+		return xcState.Price
+	}
+	return 0
+}
+
+// deliver sends the alert to the pubsub notifier and the webhooks of its rule.
+func (a *Alerter) deliver(ctx context.Context, alert *Alert) {
+	log.Infof("Alert %s: %s", alert.Rule, alert.Message)
+	var rule *AlertRule
+	for _, r := range a.rules {
+		if r.Name == alert.Rule {
+			rule = r
+			break
+		}
+	}
+	if rule.Pubsub && a.notify != nil {
+		a.notify(alert)
+	}
+	if len(rule.Webhooks) == 0 {
+		return
+	}
+	b, err := json.Marshal(alert)
+	if err != nil {
+		log.Errorf("Failed to encode alert %s: %v", alert.Rule, err)
+		return
+	}
+	for _, url := range rule.Webhooks {
+		go func(url string) {
+			if err := a.postWebhook(ctx, url, b); err != nil {
+				log.Errorf("Failed to deliver alert %s to %s: %v", alert.Rule, url, err)
+			}
+		}(url)
+	}
+}
+
+// postWebhook POSTs the JSON-encoded alert to the URL.
+func (a *Alerter) postWebhook(ctx context.Context, url string, b []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
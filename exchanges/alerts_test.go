@@ -0,0 +1,252 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleAlertFile = "sample-exchange-alerts.json"
+
+func TestAlertRuleValidate(t *testing.T) {
+	newRule := func() *AlertRule {
+		return &AlertRule{Name: "move", Kind: AlertPriceMove, Threshold: 0.05, Window: "15m", Cooldown: "1h", Pubsub: true}
+	}
+	rule := newRule()
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("valid rule error: %v", err)
+	}
+	if rule.window != 15*time.Minute || rule.cooldown != time.Hour {
+		t.Fatalf("wrong durations %s, %s", rule.window, rule.cooldown)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*AlertRule)
+	}{
+		{"no name", func(r *AlertRule) { r.Name = "" }},
+		{"unknown kind", func(r *AlertRule) { r.Kind = "volume" }},
+		{"no threshold", func(r *AlertRule) { r.Threshold = 0 }},
+		{"no window", func(r *AlertRule) { r.Window = "" }},
+		{"bad window", func(r *AlertRule) { r.Window = "15" }},
+		{"bad cooldown", func(r *AlertRule) { r.Cooldown = "soon" }},
+		{"market without token", func(r *AlertRule) { r.Market = CurrencyPairDCRBTC }},
+		{"token without market", func(r *AlertRule) { r.Token = "binance" }},
+		{"no delivery", func(r *AlertRule) { r.Pubsub = false }},
+	}
+	for _, tt := range tests {
+		rule := newRule()
+		tt.modify(rule)
+		if rule.Validate() == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+
+	spread := &AlertRule{Name: "spread", Kind: AlertSpread, Threshold: 0.03, Webhooks: []string{"https://example.com"}}
+	if err := spread.Validate(); err != nil {
+		t.Errorf("spread rule without a window error: %v", err)
+	}
+}
+
+func TestLoadAlertRules(t *testing.T) {
+	rules, err := LoadAlertRules(sampleAlertFile)
+	if err != nil {
+		t.Fatalf("error loading %s: %v", sampleAlertFile, err)
+	}
+	if len(rules) != 5 {
+		t.Fatalf("expected 5 sample rules, got %d", len(rules))
+	}
+
+	dir := t.TempDir()
+	load := func(contents string) error {
+		path := filepath.Join(dir, "alerts.json")
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadAlertRules(path)
+		return err
+	}
+	const rule = `{"name": "down", "kind": "exchange_failing", "window": "30m", "pubsub": true}`
+	if err := load("[" + rule + "," + rule + "]"); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected a duplicate error, got %v", err)
+	}
+	if err := load(`[{"name": "down", "kind": "exchange_failing", "windows": "30m", "pubsub": true}]`); err == nil {
+		t.Errorf("no error for an unknown field")
+	}
+	if err := load(`[{"name": "down", "kind": "exchange_failing", "pubsub": true}]`); err == nil {
+		t.Errorf("no error for an invalid rule")
+	}
+}
+
+// newTestAlerter creates an Alerter of the rules without a bot.
+func newTestAlerter(t *testing.T, rules ...*AlertRule) *Alerter {
+	t.Helper()
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			t.Fatalf("invalid rule %s: %v", rule.Name, err)
+		}
+	}
+	return &Alerter{
+		rules:   rules,
+		client:  http.DefaultClient,
+		targets: make(map[string]*alertTarget),
+	}
+}
+
+func TestAlerterPriceMove(t *testing.T) {
+	a := newTestAlerter(t, &AlertRule{Name: "move", Kind: AlertPriceMove, Threshold: 0.05, Window: "15m", Cooldown: "1h", Pubsub: true})
+	start := time.Unix(1700000000, 0)
+	evaluate := func(minutes int, price float64) []*Alert {
+		return a.evaluate(&alertSnapshot{
+			stamp: start.Add(time.Duration(minutes) * time.Minute),
+			state: &ExchangeBotState{Price: price},
+		})
+	}
+
+	if alerts := evaluate(0, 20); len(alerts) != 0 {
+		t.Fatalf("alert on the first price")
+	}
+	if alerts := evaluate(5, 20.5); len(alerts) != 0 {
+		t.Fatalf("alert on a 2.5%% move")
+	}
+	alerts := evaluate(10, 18.9)
+	if len(alerts) != 1 {
+		t.Fatalf("expected an alert on a 5.5%% drop, got %d", len(alerts))
+	}
+	if alerts[0].Rule != "move" || alerts[0].Value > -0.05 {
+		t.Fatalf("wrong alert %+v", alerts[0])
+	}
+	// The rule does not trigger again while the move is within the window.
+	if alerts := evaluate(12, 18.8); len(alerts) != 0 {
+		t.Fatalf("repeated alert while active")
+	}
+	// The first prices leave the window, clearing the condition, but the
+	// cooldown prevents another alert.
+	if alerts := evaluate(30, 18.8); len(alerts) != 0 {
+		t.Fatalf("alert after the window")
+	}
+	if alerts := evaluate(40, 20); len(alerts) != 0 {
+		t.Fatalf("alert during the cooldown")
+	}
+	evaluate(60, 20)
+	if alerts := evaluate(75, 22); len(alerts) != 1 || alerts[0].Value < 0.05 {
+		t.Fatalf("expected an alert on a 10%% rise after the cooldown, got %v", alerts)
+	}
+}
+
+func TestAlerterExchangeFailing(t *testing.T) {
+	a := newTestAlerter(t, &AlertRule{Name: "down", Kind: AlertExchangeFailing, Window: "30m", Pubsub: true})
+	now := time.Unix(1700000000, 0)
+	evaluate := func(failing map[string]time.Duration) []*Alert {
+		now = now.Add(time.Minute)
+		return a.evaluate(&alertSnapshot{stamp: now, failing: failing})
+	}
+	if alerts := evaluate(map[string]time.Duration{"binance": 10 * time.Minute, "dcrdex": 0}); len(alerts) != 0 {
+		t.Fatalf("alert before the window")
+	}
+	alerts := evaluate(map[string]time.Duration{"binance": 31 * time.Minute, "dcrdex": 0})
+	if len(alerts) != 1 || alerts[0].Token != "binance" || alerts[0].Value != 31 {
+		t.Fatalf("expected an alert for binance, got %v", alerts)
+	}
+	if alerts = evaluate(map[string]time.Duration{"binance": 32 * time.Minute, "dcrdex": 0}); len(alerts) != 0 {
+		t.Fatalf("repeated alert while failing")
+	}
+	evaluate(map[string]time.Duration{"binance": 0, "dcrdex": 0})
+	if alerts = evaluate(map[string]time.Duration{"binance": 45 * time.Minute, "dcrdex": 0}); len(alerts) != 1 {
+		t.Fatalf("expected an alert when failing again, got %d", len(alerts))
+	}
+}
+
+func TestAlerterSpreadAndDivergence(t *testing.T) {
+	a := newTestAlerter(t,
+		&AlertRule{Name: "spread", Kind: AlertSpread, Market: CurrencyPairDCRBTC, Threshold: 0.03, Pubsub: true},
+		&AlertRule{Name: "divergence", Kind: AlertDivergence, Threshold: 0.02, Pubsub: true},
+	)
+	state := &ExchangeBotState{
+		DCRExchanges: map[string]map[CurrencyPair]*ExchangeState{
+			"binance": {
+				CurrencyPairDCRBTC: {
+					BaseState: BaseState{Price: 0.0003},
+					Depth: &DepthData{
+						Bids: []DepthPoint{{Price: 0.000298, Quantity: 10}},
+						Asks: []DepthPoint{{Price: 0.000302, Quantity: 10}},
+					},
+				},
+				CurrencyPairDCRUSDT: {BaseState: BaseState{Price: 18}},
+			},
+			"dcrdex": {
+				CurrencyPairDCRBTC: {
+					BaseState: BaseState{Price: 0.0003},
+					Depth: &DepthData{
+						Bids: []DepthPoint{{Price: 0.00029, Quantity: 10}},
+						Asks: []DepthPoint{{Price: 0.00031, Quantity: 10}},
+					},
+				},
+			},
+		},
+	}
+	// binance's implied BTC price is 60000, 3.4% from the index price.
+	alerts := a.evaluate(&alertSnapshot{stamp: time.Now(), state: state, btcPrice: 58000})
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(alerts))
+	}
+	for _, alert := range alerts {
+		switch alert.Rule {
+		case "spread":
+			if alert.Token != "dcrdex" || alert.Market != CurrencyPairDCRBTC {
+				t.Errorf("wrong spread alert %+v", alert)
+			}
+		case "divergence":
+			if alert.Token != "binance" || alert.Value < 0.034 || alert.Value > 0.035 {
+				t.Errorf("wrong divergence alert %+v", alert)
+			}
+		}
+	}
+}
+
+func TestAlerterDeliver(t *testing.T) {
+	posted := make(chan *Alert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("webhook decode error: %v", err)
+		}
+		posted <- &alert
+	}))
+	defer srv.Close()
+
+	a := newTestAlerter(t,
+		&AlertRule{Name: "hook", Kind: AlertSpread, Threshold: 0.03, Webhooks: []string{srv.URL}},
+		&AlertRule{Name: "pubsub", Kind: AlertSpread, Threshold: 0.03, Pubsub: true},
+	)
+	var notified []*Alert
+	a.notify = func(alert *Alert) {
+		notified = append(notified, alert)
+	}
+
+	a.deliver(context.Background(), &Alert{Rule: "pubsub", Message: "pubsub alert"})
+	if len(notified) != 1 {
+		t.Fatalf("pubsub alert was not notified")
+	}
+	a.deliver(context.Background(), &Alert{Rule: "hook", Message: "webhook alert"})
+	select {
+	case alert := <-posted:
+		if alert.Message != "webhook alert" {
+			t.Fatalf("wrong webhook alert %+v", alert)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("webhook alert was not posted")
+	}
+	if len(notified) != 1 {
+		t.Fatalf("webhook alert was notified")
+	}
+}
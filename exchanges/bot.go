@@ -30,8 +30,17 @@ const (
 	DefaultRequestExpiry = "60m"
 
 	defaultDCRRatesPort = "7778"
+	// masterReplayMargin is subtracted from the time of the last update from
+	// the DCRRates server when asking it for the missed updates, to allow for
+	// clock differences. Replayed updates that were already received are
+	// harmless.
+	masterReplayMargin = time.Minute
 
 	orderbookKey = "depth"
+	tradesKey    = "trades"
+
+	// recentTradesLimit is the most trades listed by RecentTrades.
+	recentTradesLimit = 20
 )
 
 // ExchangeBotConfig is the configuration options for ExchangeBot.
@@ -46,6 +55,13 @@ type ExchangeBotConfig struct {
 	Indent         bool
 	MasterBot      string
 	MasterCertFile string
+	// SpecFile is the path to a JSON file of ExchangeSpecs. The exchanges are
+	// added to the DCR exchanges, replacing any built-in exchange with the same
+	// token.
+	SpecFile string
+	// Methodology is how the exchange prices are combined into the DCR price
+	// index.
+	Methodology IndexMethodology
 }
 
 // ExchangeBot monitors exchanges and processes updates. When an update is
@@ -96,12 +112,14 @@ type ExchangeBot struct {
 }
 
 // ExchangeBotState is the current known state of all exchanges, in a certain
-// base currency, and a volume-averaged price and total volume in DCR.
+// base currency, and the index price and total volume in DCR. IndexWeights is
+// how each exchange contributed to the index price.
 type ExchangeBotState struct {
 	Index        string                                     `json:"index"`
 	BtcPrice     float64                                    `json:"btc_fiat_price"`
 	Price        float64                                    `json:"price"`
 	Volume       float64                                    `json:"volume"`
+	IndexWeights map[string]*IndexWeight                    `json:"index_weights"`
 	DCRExchanges map[string]map[CurrencyPair]*ExchangeState `json:"dcr_exchanges"`
 	// FiatIndices:
 	// TODO: We only really need the BaseState for the fiat indices.
@@ -125,6 +143,11 @@ func copyStates(m map[string]map[CurrencyPair]*ExchangeState) map[string]map[Cur
 func (state ExchangeBotState) copy() *ExchangeBotState {
 	state.DCRExchanges = copyStates(state.DCRExchanges)
 	state.FiatIndices = copyStates(state.FiatIndices)
+	weights := make(map[string]*IndexWeight, len(state.IndexWeights))
+	for token, w := range state.IndexWeights {
+		weights[token] = w
+	}
+	state.IndexWeights = weights
 	return &state
 }
 
@@ -237,6 +260,37 @@ func (state *ExchangeBotState) VolumeOrderedExchanges() []*tokenedExchange {
 	return xcList
 }
 
+// marketTrade is a trade on an exchange market.
+type marketTrade struct {
+	Token string
+	CurrencyPair
+	Trade
+}
+
+// RecentTrades returns the most recent trades across all exchange markets that
+// report their trades, most recent first.
+func (state *ExchangeBotState) RecentTrades() []*marketTrade {
+	var trades []*marketTrade
+	for token, states := range state.DCRExchanges {
+		for pair, state := range states {
+			for _, trade := range state.Trades {
+				trades = append(trades, &marketTrade{
+					Token:        token,
+					CurrencyPair: pair,
+					Trade:        trade,
+				})
+			}
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Stamp > trades[j].Stamp
+	})
+	if len(trades) > recentTradesLimit {
+		trades = trades[:recentTradesLimit]
+	}
+	return trades
+}
+
 // FiatIndices maps currency codes to an asset's exchange rates, e.g
 // Bitcoin-USD etc.
 type FiatIndices map[string]float64
@@ -279,6 +333,12 @@ type depthResponse struct {
 	Expiration int64      `json:"expiration"`
 }
 
+type tradesResponse struct {
+	Index  string  `json:"index"`
+	Price  float64 `json:"price"`
+	Trades []Trade `json:"trades"`
+}
+
 // versionedChart holds a pre-encoded byte slice of a chart's data along with a
 // version number that can be compared for use in caching.
 type versionedChart struct {
@@ -317,6 +377,9 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 	if config.Index == "" {
 		config.Index = DefaultCurrency
 	}
+	if err = config.Methodology.Validate(); err != nil {
+		return nil, err
+	}
 
 	bot := &ExchangeBot{
 		DcrExchanges:    make(map[string]Exchange),
@@ -330,6 +393,7 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 			Index:        config.Index,
 			Price:        0,
 			Volume:       0,
+			IndexWeights: make(map[string]*IndexWeight),
 			DCRExchanges: make(map[string]map[CurrencyPair]*ExchangeState),
 			FiatIndices:  make(map[string]map[CurrencyPair]*ExchangeState),
 		},
@@ -389,6 +453,17 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 		return false
 	}
 
+	specs := make(map[string]func(*http.Client, *BotChannels) (Exchange, error))
+	if config.SpecFile != "" {
+		xcSpecs, err := LoadExchangeSpecs(config.SpecFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, spec := range xcSpecs {
+			specs[spec.Token] = NewSpecExchangeConstructor(spec)
+		}
+	}
+
 	quit := make(chan struct{})
 	bot.quitChans = append(bot.quitChans, quit)
 
@@ -415,6 +490,13 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 	}
 
 	for token, constructor := range DcrExchanges {
+		if specs[token] != nil {
+			continue // replaced by a spec
+		}
+		buildExchange(token, constructor, bot.DcrExchanges)
+	}
+
+	for token, constructor := range specs {
 		buildExchange(token, constructor, bot.DcrExchanges)
 	}
 
@@ -439,7 +521,7 @@ func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 	reconnectionAttempt := 0
 
 	if config.MasterBot != "" {
-		stream, err := bot.connectMasterBot(ctx, 0)
+		stream, err := bot.connectMasterBot(ctx, 0, time.Time{})
 		if err != nil {
 			log.Errorf("Failed to initialize gRPC stream. Falling back to direct connection: %v", err)
 		} else {
@@ -449,6 +531,9 @@ func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 			}
 			// Start a loop to listen for updates from the dcrrates server.
 			go func() {
+				// lastUpdate is when the last update was received, for the
+				// server to replay the missed updates on reconnection.
+				var lastUpdate time.Time
 				for {
 					update, err := stream.Recv()
 					if err != nil {
@@ -461,7 +546,7 @@ func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 						// Try to reconnect every minute until a connection is made.
 						for {
 							reconnectionAttempt++
-							stream, err = bot.connectMasterBot(ctx, delay)
+							stream, err = bot.connectMasterBot(ctx, delay, lastUpdate)
 							if err == nil {
 								break
 							} else {
@@ -479,21 +564,24 @@ func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 						reconnectionAttempt = 0
 						continue
 					}
+					lastUpdate = time.Now()
 					// Send the update through the Exchange so that appropriate
-					// attributes are set.
-					if IsDcrExchange(update.Token) {
+					// attributes are set. Exchanges are looked up in the bot
+					// rather than by token, since exchanges added by spec
+					// have no built-in token.
+					if xc := bot.DcrExchanges[update.Token]; xc != nil {
 						currencyPair, state := exchangeStateFromProto(update)
-						if !currencyPair.IsValidDCRPair() {
+						if !currencyPair.IsDCRMarket() {
 							log.Errorf("Received update for unknown currency pair %s", currencyPair)
 						} else {
-							bot.Exchanges[update.Token].Update(currencyPair, state)
+							xc.Update(currencyPair, state)
 						}
-					} else if IsIndex(update.Token) {
+					} else if xc := bot.IndexExchanges[update.Token]; xc != nil {
 						currencyIndex := CurrencyPair(update.GetCurrencyPair())
 						if !currencyIndex.IsValidIndex() {
 							log.Errorf("Received update for unknown index %s", currencyIndex)
 						} else {
-							bot.Exchanges[update.Token].UpdateIndices(currencyIndex, update.GetIndices())
+							xc.UpdateIndices(currencyIndex, update.GetIndices())
 						}
 					}
 				}
@@ -560,8 +648,9 @@ out:
 	}
 }
 
-// Attempt DCRRates connection after delay.
-func (bot *ExchangeBot) connectMasterBot(ctx context.Context, delay time.Duration) (dcrrates.DCRRates_SubscribeExchangesClient, error) {
+// Attempt DCRRates connection after delay. If since is not zero, the server is
+// asked to replay only the updates since then, allowing for some clock skew.
+func (bot *ExchangeBot) connectMasterBot(ctx context.Context, delay time.Duration, since time.Time) (dcrrates.DCRRates_SubscribeExchangesClient, error) {
 	if bot.masterConnection != nil {
 		bot.masterConnection.Close()
 	}
@@ -580,9 +669,14 @@ func (bot *ExchangeBot) connectMasterBot(ctx context.Context, delay time.Duratio
 	}
 	bot.masterConnection = conn
 	grpcClient := dcrrates.NewDCRRatesClient(conn)
+	var sinceStamp int64
+	if !since.IsZero() {
+		sinceStamp = since.Add(-masterReplayMargin).Unix()
+	}
 	stream, err := grpcClient.SubscribeExchanges(ctx, &dcrrates.ExchangeSubscription{
 		Index:     bot.Index,
 		Exchanges: bot.subscribedExchanges(),
+		Since:     sinceStamp,
 	})
 	if err != nil {
 		return nil, err
@@ -671,7 +765,7 @@ func (bot *ExchangeBot) indicesForCode(code string) map[string]map[CurrencyPair]
 func (bot *ExchangeBot) ConvertedState(code string) (*ExchangeBotState, error) {
 	bot.mtx.RLock()
 	defer bot.mtx.RUnlock()
-	dcrPrice, volume := bot.dcrPriceAndVolume(code)
+	dcrPrice, volume, weights := bot.dcrPriceAndVolume(code)
 	btcPrice := bot.indexPrice(BTCIndex, code)
 	if dcrPrice == 0 || btcPrice == 0 {
 		bot.failed = true
@@ -683,6 +777,7 @@ func (bot *ExchangeBot) ConvertedState(code string) (*ExchangeBotState, error) {
 		Volume:       volume,
 		Price:        dcrPrice,
 		BtcPrice:     dcrPrice,
+		IndexWeights: weights,
 		DCRExchanges: bot.currentState.DCRExchanges,
 		FiatIndices:  bot.indicesForCode(code),
 	}
@@ -726,7 +821,7 @@ func (bot *ExchangeBot) Rates() *ExchangeRates {
 func (bot *ExchangeBot) ConvertedRates(code string) (*ExchangeRates, error) {
 	bot.mtx.RLock()
 	defer bot.mtx.RUnlock()
-	dcrPrice, _ := bot.dcrPriceAndVolume(code)
+	dcrPrice, _, _ := bot.dcrPriceAndVolume(code)
 	btcPrice := bot.indexPrice(BTCIndex, code)
 	if btcPrice == 0 || dcrPrice == 0 {
 		bot.failed = true
@@ -829,6 +924,11 @@ func (bot *ExchangeBot) processState(token, code string, states map[CurrencyPair
 
 	var priceAccumulator, volSum float64
 	for currencyPair, state := range states {
+		// Markets without an index price, such as some of the DEX markets,
+		// are not part of the exchange's price.
+		if !currencyPair.IsValidDCRPair() {
+			continue
+		}
 		volume := 1.0
 		if volumeAveraged {
 			volume = state.Volume
@@ -885,6 +985,10 @@ func (bot *ExchangeBot) updateExchange(update *ExchangeUpdate) error {
 	if update.State.Depth != nil {
 		bot.incrementChart(genCacheID(string(update.CurrencyPair), update.Token, orderbookKey))
 	}
+	if update.State.Trades != nil {
+		bot.incrementChart(genCacheID(string(update.CurrencyPair), update.Token, tradesKey))
+	}
+	bot.incrementAggregateCharts()
 
 	if bot.currentState.DCRExchanges[update.Token] == nil {
 		bot.currentState.DCRExchanges[update.Token] = make(map[CurrencyPair]*ExchangeState)
@@ -915,41 +1019,53 @@ func (bot *ExchangeBot) updateIndices(update *IndexUpdate) error {
 				Stamp: time.Now().Unix(),
 			},
 		}
+		// The aggregate charts are converted to the index currency.
+		bot.incrementAggregateCharts()
 		return bot.updateState()
 	}
 	log.Warnf("Default currency code, %s, not contained in update from %s", bot.Index, update.Token)
 	return nil
 }
 
-// dcrPriceAndVolume calculates and returns dcr price and volume. The returned
-// dcr price is converted to the provided index code. Must be called under
-// bot.mtx lock.
-func (bot *ExchangeBot) dcrPriceAndVolume(code string) (float64, float64) {
-	var dcrPrice, volume, nSources float64
+// dcrPriceAndVolume calculates and returns dcr price and volume, and the
+// weight of each exchange in the price, according to the configured
+// IndexMethodology. The returned dcr price is converted to the provided index
+// code. Must be called under bot.mtx lock.
+func (bot *ExchangeBot) dcrPriceAndVolume(code string) (float64, float64, map[string]*IndexWeight) {
+	weights := make(map[string]*IndexWeight, len(bot.currentState.DCRExchanges))
+	sources := make([]*indexSource, 0, len(bot.currentState.DCRExchanges))
+	oldestValid := time.Now().Add(-bot.RequestExpiry)
 	for token, xcStates := range bot.currentState.DCRExchanges {
-		processedDcrPrice, processedVolume, ok := bot.processState(token, code, xcStates, true)
+		weight := new(IndexWeight)
+		weights[token] = weight
+		lastUpdate := bot.Exchanges[token].LastUpdate()
+		price, volume, ok := bot.processState(token, code, xcStates, true)
 		if !ok {
+			weight.Reason = IndexExcludedNoIndexPrice
+			if lastUpdate.Before(oldestValid) {
+				weight.Reason = IndexExcludedExpired
+			}
 			continue
 		}
-
-		volume += processedVolume
-		if processedDcrPrice != 0 {
-			dcrPrice += processedDcrPrice
-			nSources++
-		}
+		weight.Price, weight.Volume = price, volume
+		sources = append(sources, &indexSource{
+			token:       token,
+			age:         time.Since(lastUpdate),
+			IndexWeight: weight,
+		})
 	}
 
+	dcrPrice, volume := bot.config.Methodology.combine(sources)
 	if dcrPrice == 0 {
-		return 0, 0
+		return 0, 0, weights
 	}
-
-	return dcrPrice / nSources, volume
+	return dcrPrice, volume, weights
 }
 
 // Called from both updateIndices and updateExchange (under mutex lock).
 func (bot *ExchangeBot) updateState() error {
 	btcPrice := bot.indexPrice(BTCIndex, bot.Index)
-	dcrPrice, volume := bot.dcrPriceAndVolume(bot.Index)
+	dcrPrice, volume, weights := bot.dcrPriceAndVolume(bot.Index)
 	if btcPrice == 0 || dcrPrice == 0 {
 		bot.failed = true
 	} else {
@@ -957,6 +1073,7 @@ func (bot *ExchangeBot) updateState() error {
 		bot.currentState.Price = dcrPrice
 		bot.currentState.BtcPrice = btcPrice
 		bot.currentState.Volume = volume
+		bot.currentState.IndexWeights = weights
 	}
 
 	var jsonBytes []byte
@@ -1075,7 +1192,7 @@ func (bot *ExchangeBot) fetchFromCache(chartID string) (data []byte, bestVersion
 // QuickSticks returns the up-to-date candlestick data for the specified
 // exchange and bin width, pulling from the cache if appropriate.
 func (bot *ExchangeBot) QuickSticks(token string, market CurrencyPair, rawBin string) ([]byte, error) {
-	if !market.IsValidDCRPair() {
+	if !market.IsDCRMarket() {
 		return nil, fmt.Errorf("invalid market %s", market)
 	}
 
@@ -1135,7 +1252,7 @@ func (bot *ExchangeBot) QuickSticks(token string, market CurrencyPair, rawBin st
 // QuickDepth returns the up-to-date depth chart data for the specified exchange
 // market, pulling from the cache if appropriate.
 func (bot *ExchangeBot) QuickDepth(token string, market CurrencyPair) (chart []byte, err error) {
-	if !market.IsValidDCRPair() {
+	if !market.IsDCRMarket() {
 		return nil, fmt.Errorf("invalid market %s", market)
 	}
 
@@ -1176,3 +1293,47 @@ func (bot *ExchangeBot) QuickDepth(token string, market CurrencyPair) (chart []b
 	bot.versionedCharts[chartID] = vChart
 	return vChart.chart, nil
 }
+
+// QuickTrades returns the recent trades for the specified exchange market,
+// most recent first, pulling from the cache if appropriate.
+func (bot *ExchangeBot) QuickTrades(token string, market CurrencyPair) ([]byte, error) {
+	if !market.IsDCRMarket() {
+		return nil, fmt.Errorf("invalid market %s", market)
+	}
+
+	chartID := genCacheID(string(market), token, tradesKey)
+	data, bestVersion, isGood := bot.fetchFromCache(chartID)
+	if isGood {
+		return data, nil
+	}
+
+	bot.mtx.Lock()
+	defer bot.mtx.Unlock()
+	xcStates, found := bot.currentState.DCRExchanges[token]
+	if !found {
+		return nil, fmt.Errorf("Failed to find DCR exchange state for %s (Currency Pair: %s)", token, market)
+	}
+
+	state, ok := xcStates[market]
+	if !ok || state.Trades == nil {
+		return nil, fmt.Errorf("Failed to find trades for %s (Currency Pair: %s)", token, market)
+	}
+
+	chart, err := bot.encodeJSON(&tradesResponse{
+		Index:  bot.Index,
+		Price:  bot.currentState.Price,
+		Trades: state.Trades,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JSON encode error for %s trades", token)
+	}
+
+	vChart := &versionedChart{
+		chartID: chartID,
+		dataID:  bestVersion,
+		chart:   chart,
+	}
+
+	bot.versionedCharts[chartID] = vChart
+	return vChart.chart, nil
+}
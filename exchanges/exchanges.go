@@ -80,12 +80,19 @@ func (cp CurrencyPair) IsValidDCRPair() bool {
 	return cp == CurrencyPairDCRBTC || cp == CurrencyPairDCRUSDT
 }
 
+// IsDCRMarket checks whether the currency pair is any DCR-{Asset} market.
+// Unlike with IsValidDCRPair, there may be no index price for the asset, as for
+// some of the DEX markets.
+func (cp CurrencyPair) IsDCRMarket() bool {
+	return strings.HasPrefix(string(cp), "DCR-") && len(cp) > len("DCR-")
+}
+
 func (cp CurrencyPair) IsValidIndex() bool {
 	return cp == BTCIndex || cp == USDTIndex
 }
 
 func (cp CurrencyPair) QuoteAsset() string {
-	if !cp.IsValidDCRPair() {
+	if !cp.IsDCRMarket() {
 		return string(cp)
 	}
 
@@ -394,6 +401,23 @@ type ExchangeState struct {
 	BaseState
 	Depth        *DepthData                      `json:"depth,omitempty"`
 	Candlesticks map[candlestickKey]Candlesticks `json:"candlesticks,omitempty"`
+	// Trades is the recent trade history, most recent first, for exchanges
+	// that provide it.
+	Trades []Trade `json:"trades,omitempty"`
+}
+
+// Trade is a matched trade on an exchange market.
+type Trade struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+	// MakerSell is true if the maker order was a sell, i.e. the taker bought.
+	MakerSell bool  `json:"maker_sell"`
+	Stamp     int64 `json:"timestamp"`
+}
+
+// Time is the time of the trade.
+func (t Trade) Time() time.Time {
+	return time.Unix(t.Stamp, 0).UTC()
 }
 
 // Grab any candlesticks from the top that are not in the receiver. Candlesticks
@@ -502,6 +526,7 @@ type Exchange interface {
 	LastUpdate() time.Time
 	LastFail() time.Time
 	LastTry() time.Time
+	Failures() uint64
 	Refresh()
 	IsFailed() bool
 	Token() string
@@ -528,6 +553,7 @@ type CommonExchange struct {
 	lastUpdate   time.Time
 	lastFail     time.Time
 	lastRequest  time.Time
+	failures     uint64
 	requests     map[CurrencyPair]*requests
 	channels     *BotChannels
 	wsMtx        sync.RWMutex
@@ -572,6 +598,13 @@ func (xc *CommonExchange) LastFail() time.Time {
 	return xc.lastFail
 }
 
+// Failures is the number of failed exchange updates.
+func (xc *CommonExchange) Failures() uint64 {
+	xc.mtx.RLock()
+	defer xc.mtx.RUnlock()
+	return xc.failures
+}
+
 // IsFailed will be true if xc.lastFail > xc.lastUpdate.
 func (xc *CommonExchange) IsFailed() bool {
 	xc.mtx.RLock()
@@ -598,11 +631,12 @@ func (xc *CommonExchange) Token() string {
 	return xc.token
 }
 
-// setLastFail sets the last failure time.
+// setLastFail sets the last failure time and counts the failure.
 func (xc *CommonExchange) setLastFail(t time.Time) {
 	xc.mtx.Lock()
 	defer xc.mtx.Unlock()
 	xc.lastFail = t
+	xc.failures++
 }
 
 // Log the error along with the token and an additional passed identifier.
@@ -829,17 +863,23 @@ func wsOrderBinKeys(book wsOrders) []int64 {
 // Convert the intermediate websocket orderbook to a DepthData. This function
 // should be called under at least an orderMtx.RLock.
 func (xc *CommonExchange) wsDepthSnapshot() *DepthData {
-	askKeys := wsOrderBinKeys(xc.asks)
+	return wsOrdersDepth(xc.buys, xc.asks)
+}
+
+// wsOrdersDepth converts the sides of an intermediate websocket orderbook to a
+// DepthData.
+func wsOrdersDepth(buys, asks wsOrders) *DepthData {
+	askKeys := wsOrderBinKeys(asks)
 	sort.Slice(askKeys, func(i, j int) bool {
 		return askKeys[i] < askKeys[j]
 	})
-	buyKeys := wsOrderBinKeys(xc.buys)
+	buyKeys := wsOrderBinKeys(buys)
 	sort.Slice(buyKeys, func(i, j int) bool {
 		return buyKeys[i] > buyKeys[j]
 	})
 	a := make([]DepthPoint, 0, len(askKeys))
 	for _, bin := range askKeys {
-		pt := xc.asks[bin]
+		pt := asks[bin]
 		a = append(a, DepthPoint{
 			Quantity: pt.volume,
 			Price:    pt.price,
@@ -847,7 +887,7 @@ func (xc *CommonExchange) wsDepthSnapshot() *DepthData {
 	}
 	b := make([]DepthPoint, 0, len(buyKeys))
 	for _, bin := range buyKeys {
-		pt := xc.buys[bin]
+		pt := buys[bin]
 		b = append(b, DepthPoint{
 			Quantity: pt.volume,
 			Price:    pt.price,
@@ -2555,13 +2595,21 @@ func (poloniex *PoloniexExchange) Refresh() {
 // dexDotDecredMsgID is used as an atomic counter for msgjson.Message IDs.
 var dexDotDecredMsgID uint64 = 1
 
-// dexSubscription is the DEX request for the order book feed.
-var dexSubscription = &msgjson.OrderBookSubscription{
-	Base:  42, // BIP44 coin ID for Decred
-	Quote: 0,  // Bitcoin
-}
+const (
+	// dexDCRAssetID is the BIP44 coin ID for Decred. The DEX markets with DCR
+	// as the base asset are tracked.
+	dexDCRAssetID = 42
+	// dexDefaultConversionFactor is the number of atomic units in a
+	// conventional unit for assets that the DEX config doesn't describe.
+	dexDefaultConversionFactor = 1e8
+	// dexRateEncodingFactor is the multiplier of message rates.
+	dexRateEncodingFactor = 1e8
+	// dexTradeHistory is the number of recent trades kept for each market.
+	dexTradeHistory = 100
+)
 
-// DEXConfig is the configuration for the Decred DEX server.
+// DEXConfig is the configuration for the Decred DEX server. Every market with
+// DCR as the base asset that the server lists in its config is tracked.
 type DEXConfig struct {
 	Token    string
 	Host     string
@@ -2578,18 +2626,127 @@ type candleCache struct {
 	key       candlestickKey
 }
 
+// dexMarket is one of a DEX's DCR markets. The order book, last rate and
+// trades are protected by the DecredDEX's orderMtx, and the candle caches by
+// its cacheMtx.
+type dexMarket struct {
+	// name is the DEX's market ID, e.g. dcr_btc.
+	name  string
+	pair  CurrencyPair
+	quote uint32
+	// rateDivisor converts message rates to conventional rates, and
+	// qtyDivisor converts quantities in atoms to DCR.
+	rateDivisor  float64
+	qtyDivisor   float64
+	seq          uint64
+	buys         wsOrders
+	asks         wsOrders
+	ords         map[string]*msgjson.BookOrderNote
+	lastRate     float64
+	trades       []Trade
+	candleCaches map[uint64]*candleCache
+}
+
+func newDEXMarket(name string, pair CurrencyPair, quote uint32, baseFactor, quoteFactor float64) *dexMarket {
+	return &dexMarket{
+		name:         name,
+		pair:         pair,
+		quote:        quote,
+		rateDivisor:  dexRateEncodingFactor * quoteFactor / baseFactor,
+		qtyDivisor:   baseFactor,
+		buys:         make(wsOrders),
+		asks:         make(wsOrders),
+		ords:         make(map[string]*msgjson.BookOrderNote),
+		candleCaches: make(map[uint64]*candleCache),
+	}
+}
+
+// rate converts the message rate to a conventional rate.
+func (mkt *dexMarket) rate(msgRate uint64) float64 {
+	return float64(msgRate) / mkt.rateDivisor
+}
+
+// qty converts the quantity in atoms to DCR.
+func (mkt *dexMarket) qty(atoms uint64) float64 {
+	return float64(atoms) / mkt.qtyDivisor
+}
+
+// clearOrderBook clears the order book.
+func (mkt *dexMarket) clearOrderBook() {
+	mkt.buys = make(wsOrders)
+	mkt.asks = make(wsOrders)
+	mkt.ords = make(map[string]*msgjson.BookOrderNote)
+}
+
+// depth is the market's order book depth.
+func (mkt *dexMarket) depth() *DepthData {
+	return wsOrdersDepth(mkt.buys, mkt.asks)
+}
+
+// side is the side of the book for the order side.
+func (mkt *dexMarket) side(orderSide uint8) wsOrders {
+	if orderSide == msgjson.BuyOrderNum {
+		return mkt.buys
+	}
+	return mkt.asks
+}
+
+// addTrades adds the matches summarized in the epoch report to the trade
+// history.
+func (mkt *dexMarket) addTrades(note *msgjson.EpochReportNote) {
+	stamp := int64(note.EndStamp / 1000)
+	for _, match := range note.MatchSummary {
+		rate, qty := match[0], match[1]
+		makerSell := qty < 0
+		if makerSell {
+			qty = -qty
+		}
+		mkt.trades = append(mkt.trades, Trade{
+			Price:     mkt.rate(uint64(rate)),
+			Quantity:  mkt.qty(uint64(qty)),
+			MakerSell: makerSell,
+			Stamp:     stamp,
+		})
+	}
+	if over := len(mkt.trades) - dexTradeHistory; over > 0 {
+		mkt.trades = append(mkt.trades[:0:0], mkt.trades[over:]...)
+	}
+}
+
+// tradeHistory is a copy of the trades, most recent first.
+func (mkt *dexMarket) tradeHistory() []Trade {
+	if len(mkt.trades) == 0 {
+		return nil
+	}
+	trades := make([]Trade, 0, len(mkt.trades))
+	for i := len(mkt.trades) - 1; i >= 0; i-- {
+		trades = append(trades, mkt.trades[i])
+	}
+	return trades
+}
+
+// dexCurrencyPair is the CurrencyPair for the DCR market with the quote asset
+// symbol, e.g. DCR-BTC for btc, or DCR-USDT for the usdt.polygon token. If the
+// pair is already taken by another market, the full symbol is used.
+func dexCurrencyPair(symbol string, taken map[CurrencyPair]bool) CurrencyPair {
+	pair := CurrencyPair("DCR-" + strings.ToUpper(strings.Split(symbol, ".")[0]))
+	if taken[pair] {
+		pair = CurrencyPair("DCR-" + strings.ToUpper(symbol))
+	}
+	taken[pair] = true
+	return pair
+}
+
 // DecredDEX is a Decred DEX.
 type DecredDEX struct {
 	*CommonExchange
-	ords         map[string]*msgjson.BookOrderNote
-	reqMtx       sync.Mutex
-	reqs         map[uint64]func(*msgjson.Message)
-	cacheMtx     sync.RWMutex
-	candleCaches map[uint64]*candleCache
-	lastRate     float64
-	seq          uint64
-	stamp        int64
-	cfg          *DEXConfig
+	reqMtx   sync.Mutex
+	reqs     map[uint64]func(*msgjson.Message)
+	cacheMtx sync.RWMutex
+	// markets is keyed by market ID, and is protected by the orderMtx.
+	markets map[string]*dexMarket
+	stamp   int64
+	cfg     *DEXConfig
 }
 
 // NewDecredDEXConstructor creates a constructor for a DEX with the provided
@@ -2598,8 +2755,8 @@ func NewDecredDEXConstructor(cfg *DEXConfig) func(*http.Client, *BotChannels) (E
 	return func(client *http.Client, channels *BotChannels) (Exchange, error) {
 		dcr := &DecredDEX{
 			CommonExchange: newCommonExchange(cfg.Token, client, make(map[CurrencyPair]*requests), channels),
-			candleCaches:   make(map[uint64]*candleCache),
 			reqs:           make(map[uint64]func(*msgjson.Message)),
+			markets:        make(map[string]*dexMarket),
 			cfg:            cfg,
 		}
 		go func() {
@@ -2613,11 +2770,11 @@ func NewDecredDEXConstructor(cfg *DEXConfig) func(*http.Client, *BotChannels) (E
 	}
 }
 
-// Refresh grabs a book snapshot and sends the exchange update.
+// Refresh grabs a book snapshot of each market and sends the exchange updates.
 func (dcr *DecredDEX) Refresh() {
 	dcr.LogRequest()
 	// Check for a depth chart from the websocket orderbook.
-	tryHTTP, wsStarting, depth := dcr.wsDepthStatus(dcr.connectWs)
+	tryHTTP, wsStarting, _ := dcr.wsDepthStatus(dcr.connectWs)
 	if tryHTTP {
 		log.Debugf("Failed to get WebSocket depth chart for %s", dcr.cfg.Host)
 		return
@@ -2627,22 +2784,29 @@ func (dcr *DecredDEX) Refresh() {
 		return
 	}
 
+	for _, mkt := range dcr.marketList() {
+		dcr.refreshMarket(mkt)
+	}
+}
+
+// refreshMarket sends the exchange update for the market.
+func (dcr *DecredDEX) refreshMarket(mkt *dexMarket) {
 	candlesticks := make(map[candlestickKey]Candlesticks)
 	var change float64
 	var volume, bestVolDur uint64
 	var aDayMS uint64 = 86400 * 1000
 	// Ugh. I need to export the CandleCache.candles.
-	for binSize, cache := range dcr.candles() {
+	for binSize, cache := range dcr.candles(mkt) {
 		cache.mtx.RLock()
 		wc := cache.WireCandles(dexcandles.CacheSize)
 		sticks := make(Candlesticks, 0, len(wc.EndStamps))
 		for i := range wc.EndStamps {
 			sticks = append(sticks, Candlestick{
-				High:   float64(wc.HighRates[i]) / 1e8,
-				Low:    float64(wc.LowRates[i]) / 1e8,
-				Open:   float64(wc.StartRates[i]) / 1e8,
-				Close:  float64(wc.EndRates[i]) / 1e8,
-				Volume: float64(wc.MatchVolumes[i]) / 1e8,
+				High:   mkt.rate(wc.HighRates[i]),
+				Low:    mkt.rate(wc.LowRates[i]),
+				Open:   mkt.rate(wc.StartRates[i]),
+				Close:  mkt.rate(wc.EndRates[i]),
+				Volume: mkt.qty(wc.MatchVolumes[i]),
 				Start:  time.Unix(int64(wc.StartStamps[i]/1000), 0),
 			})
 		}
@@ -2656,45 +2820,83 @@ func (dcr *DecredDEX) Refresh() {
 		}
 	}
 
-	if dcr.lastRate == 0 {
+	dcr.orderMtx.RLock()
+	lastRate := mkt.lastRate
+	depth := mkt.depth()
+	trades := mkt.tradeHistory()
+	stamp := dcr.stamp
+	dcr.orderMtx.RUnlock()
+
+	if lastRate == 0 {
 		return // no rate, nothing to do.
 	}
 
-	dcr.Update(CurrencyPairDCRBTC, &ExchangeState{
+	dcr.Update(mkt.pair, &ExchangeState{
 		BaseState: BaseState{
-			Price:  dcr.lastRate,
+			Price:  lastRate,
 			Change: change,
-			Volume: float64(volume) / 1e8,
-			Stamp:  dcr.lastStamp(),
+			Volume: mkt.qty(volume),
+			Stamp:  stamp,
 		},
 		Candlesticks: candlesticks,
 		Depth:        depth,
+		Trades:       trades,
 	})
 }
 
-// candles gets a copy of the candleCaches map.
-func (dcr *DecredDEX) candles() map[uint64]*candleCache {
+// marketList is the tracked markets, sorted by market ID.
+func (dcr *DecredDEX) marketList() []*dexMarket {
+	dcr.orderMtx.RLock()
+	defer dcr.orderMtx.RUnlock()
+	return sortedDEXMarkets(dcr.markets)
+}
+
+// sortedDEXMarkets is the markets sorted by market ID.
+func sortedDEXMarkets(markets map[string]*dexMarket) []*dexMarket {
+	mkts := make([]*dexMarket, 0, len(markets))
+	for _, mkt := range markets {
+		mkts = append(mkts, mkt)
+	}
+	sort.Slice(mkts, func(i, j int) bool {
+		return mkts[i].name < mkts[j].name
+	})
+	return mkts
+}
+
+// market is the tracked market with the market ID, or nil if the market is
+// not tracked. market should only be called with the orderMtx locked.
+func (dcr *DecredDEX) market(id string) *dexMarket {
+	mkt := dcr.markets[id]
+	if mkt == nil {
+		log.Warnf("Received notification from %q for untracked market %q", dcr.cfg.Host, id)
+	}
+	return mkt
+}
+
+// candles gets a copy of the market's candleCaches map.
+func (dcr *DecredDEX) candles(mkt *dexMarket) map[uint64]*candleCache {
 	dcr.cacheMtx.RLock()
 	defer dcr.cacheMtx.RUnlock()
-	cs := make(map[uint64]*candleCache, len(dcr.candleCaches))
-	for binSize, cache := range dcr.candleCaches {
+	cs := make(map[uint64]*candleCache, len(mkt.candleCaches))
+	for binSize, cache := range mkt.candleCaches {
 		cs[binSize] = cache
 	}
 	return cs
 }
 
-// clearCandleCache clears the candle cache for the specified bin size.
-func (dcr *DecredDEX) clearCandleCache(binSize uint64) {
+// clearCandleCache clears the market's candle cache for the specified bin
+// size.
+func (dcr *DecredDEX) clearCandleCache(mkt *dexMarket, binSize uint64) {
 	dcr.cacheMtx.Lock()
 	defer dcr.cacheMtx.Unlock()
-	delete(dcr.candleCaches, binSize)
+	delete(mkt.candleCaches, binSize)
 }
 
-// setCandleCache sets the candle cache for the specified bin size.
-func (dcr *DecredDEX) setCandleCache(binSize uint64, cache *candleCache) {
+// setCandleCache sets the market's candle cache for the specified bin size.
+func (dcr *DecredDEX) setCandleCache(mkt *dexMarket, binSize uint64, cache *candleCache) {
 	dcr.cacheMtx.Lock()
 	defer dcr.cacheMtx.Unlock()
-	dcr.candleCaches[binSize] = cache
+	mkt.candleCaches[binSize] = cache
 }
 
 // logRequest stores the response handler for the request ID.
@@ -2725,7 +2927,21 @@ func (dcr *DecredDEX) request(route string, payload interface{}, handler func(*m
 	return msg.ID, nil
 }
 
-// Create a websocket connection and send the orderbook subscription.
+// requestCandles requests the market's candles for the bin size.
+func (dcr *DecredDEX) requestCandles(mkt *dexMarket, key candlestickKey, binSize string) error {
+	_, err := dcr.request(msgjson.CandlesRoute, &msgjson.CandlesRequest{
+		BaseID:     dexDCRAssetID,
+		QuoteID:    mkt.quote,
+		BinSize:    binSize,
+		NumCandles: dexcandles.CacheSize,
+	}, func(msg *msgjson.Message) {
+		dcr.handleCandles(mkt, key, msg)
+	})
+	return err
+}
+
+// Create a websocket connection and request the config. The order book
+// subscriptions are sent for the markets in the config response.
 func (dcr *DecredDEX) connectWs() {
 	// Configure TLS.
 	if len(dcr.cfg.Cert) == 0 {
@@ -2751,18 +2967,12 @@ func (dcr *DecredDEX) connectWs() {
 		return
 	}
 
-	// Get 'config' to get current bin sizes.
+	// Get 'config' to get the markets and current bin sizes.
 	_, err = dcr.request(msgjson.ConfigRoute, nil, dcr.handleConfigResponse)
 	if err != nil {
 		dcr.setWsFail(err)
 		return
 	}
-
-	_, err = dcr.request(msgjson.OrderBookRoute, dexSubscription, dcr.handleSubResponse)
-	if err != nil {
-		dcr.setWsFail(err)
-		return
-	}
 }
 
 // processWsMessage is DecredDEX's WebsocketProcessor. Handles messages of type
@@ -2795,10 +3005,11 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 				dcr.setWsFail(fmt.Errorf("book_order Unmarshal error: %v", err))
 				return
 			}
-			if !dcr.checkSeq(bookOrder.Seq) {
+			mkt := dcr.market(bookOrder.MarketID)
+			if mkt == nil || !dcr.checkSeq(mkt, bookOrder.Seq) {
 				return
 			}
-			dcr.bookOrder(bookOrder)
+			dcr.bookOrder(mkt, bookOrder)
 		case msgjson.UnbookOrderRoute:
 			unbookOrder := new(msgjson.UnbookOrderNote)
 			err := msg.Unmarshal(unbookOrder)
@@ -2806,10 +3017,11 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 				dcr.setWsFail(fmt.Errorf("unbook_order Unmarshal error: %v", err))
 				return
 			}
-			if !dcr.checkSeq(unbookOrder.Seq) {
+			mkt := dcr.market(unbookOrder.MarketID)
+			if mkt == nil || !dcr.checkSeq(mkt, unbookOrder.Seq) {
 				return
 			}
-			dcr.unbookOrder(unbookOrder)
+			dcr.unbookOrder(mkt, unbookOrder)
 		case msgjson.UpdateRemainingRoute:
 			update := new(msgjson.UpdateRemainingNote)
 			err := msg.Unmarshal(update)
@@ -2817,10 +3029,11 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 				dcr.setWsFail(fmt.Errorf("update_remaining Unmarshal error: %v", err))
 				return
 			}
-			if !dcr.checkSeq(update.Seq) {
+			mkt := dcr.market(update.MarketID)
+			if mkt == nil || !dcr.checkSeq(mkt, update.Seq) {
 				return
 			}
-			dcr.updateRemaining(update)
+			dcr.updateRemaining(mkt, update)
 		case msgjson.EpochOrderRoute:
 			// We don't actually track epoch orders, but we need to progress the
 			// sequence.
@@ -2830,7 +3043,9 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 				dcr.setWsFail(fmt.Errorf("epoch_order Unmarshal error: %v", err))
 				return
 			}
-			dcr.checkSeq(note.Seq)
+			if mkt := dcr.market(note.MarketID); mkt != nil {
+				dcr.checkSeq(mkt, note.Seq)
+			}
 			return // Skip wsUpdate. Nothing has changed.
 		case msgjson.SuspensionRoute:
 			note := new(msgjson.TradeSuspension)
@@ -2842,8 +3057,12 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 			if note.Persist {
 				return
 			}
-			dcr.checkSeq(note.Seq)
-			dcr.clearOrderBook()
+			mkt := dcr.market(note.MarketID)
+			if mkt == nil {
+				return
+			}
+			dcr.checkSeq(mkt, note.Seq)
+			mkt.clearOrderBook()
 		case msgjson.EpochReportRoute:
 			note := new(msgjson.EpochReportNote)
 			err := msg.Unmarshal(note)
@@ -2855,11 +3074,16 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 			if note.Candle.EndStamp == 0 {
 				return
 			}
+			mkt := dcr.market(note.MarketID)
+			if mkt == nil {
+				return
+			}
 
-			dcr.lastRate = float64(note.Candle.EndRate) / 1e8
+			mkt.lastRate = mkt.rate(note.Candle.EndRate)
+			mkt.addTrades(note)
 
 			candle := &note.Candle
-			for binSize, cache := range dcr.candles() {
+			for binSize, cache := range dcr.candles(mkt) {
 				cache.mtx.Lock()
 				if cache.lastStamp == note.StartStamp {
 					cache.Add(candle)
@@ -2867,21 +3091,14 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 					cache.mtx.Unlock()
 				} else {
 					// Our candles are out of sync. Get a fresh set.
-					log.Infof("Epoch report out of sync (last stamp %d, note start stamp %d). Requesting new candles.",
-						cache.lastStamp, note.StartStamp)
+					log.Infof("Epoch report out of sync for %s (last stamp %d, note start stamp %d). Requesting new candles.",
+						mkt.name, cache.lastStamp, note.StartStamp)
 					cacheKey := cache.key
 					cache.mtx.Unlock()
-					dcr.clearCandleCache(binSize)
-					_, err := dcr.request(msgjson.CandlesRoute, &msgjson.CandlesRequest{
-						BaseID:     42,
-						QuoteID:    0,
-						BinSize:    (time.Duration(binSize) * time.Millisecond).String(),
-						NumCandles: dexcandles.CacheSize,
-					}, func(msg *msgjson.Message) {
-						dcr.handleCandles(cacheKey, msg)
-					})
+					dcr.clearCandleCache(mkt, binSize)
+					err := dcr.requestCandles(mkt, cacheKey, (time.Duration(binSize) * time.Millisecond).String())
 					if err != nil {
-						dcr.setWsFail(fmt.Errorf("error requesting candles for bin size %d: %w", binSize, err))
+						dcr.setWsFail(fmt.Errorf("error requesting %s candles for bin size %d: %w", mkt.name, binSize, err))
 						break
 					}
 				}
@@ -2891,7 +3108,7 @@ func (dcr *DecredDEX) processWsMessage(raw []byte) {
 	dcr.wsUpdated()
 }
 
-// handleSubResponse handles the response to the order book subscription.
+// handleSubResponse handles the response to an order book subscription.
 func (dcr *DecredDEX) handleSubResponse(msg *msgjson.Message) {
 	ob := new(msgjson.OrderBook)
 	err := msg.UnmarshalResult(ob)
@@ -2899,15 +3116,20 @@ func (dcr *DecredDEX) handleSubResponse(msg *msgjson.Message) {
 		dcr.setWsFail(fmt.Errorf("error unmarshaling orderbook response: %v", err))
 		return
 	}
-	dcr.setOrderBook(ob)
+	mkt := dcr.market(ob.MarketID)
+	if mkt == nil {
+		return
+	}
+	dcr.setOrderBook(mkt, ob)
 }
 
-// handleCandles handles the response for a set of candles from the data API.
-func (dcr *DecredDEX) handleCandles(key candlestickKey, msg *msgjson.Message) {
+// handleCandles handles the response for a set of a market's candles from the
+// data API.
+func (dcr *DecredDEX) handleCandles(mkt *dexMarket, key candlestickKey, msg *msgjson.Message) {
 	wireCandles := new(msgjson.WireCandles)
 	err := msg.UnmarshalResult(wireCandles)
 	if err != nil {
-		log.Errorf("error encountered in candlestick response from DEX at %s: %v", dcr.cfg.Host, err)
+		log.Errorf("error encountered in %s candlestick response from DEX at %s: %v", mkt.name, dcr.cfg.Host, err)
 		return
 	}
 
@@ -2926,10 +3148,11 @@ func (dcr *DecredDEX) handleCandles(key candlestickKey, msg *msgjson.Message) {
 	if len(candles) > 0 {
 		cache.lastStamp = candles[len(candles)-1].EndStamp
 	}
-	dcr.setCandleCache(binSize, cache)
+	dcr.setCandleCache(mkt, binSize, cache)
 }
 
-// handleConfigResponse handles the response for the DEX configuration.
+// handleConfigResponse handles the response for the DEX configuration. The
+// DCR markets are tracked, and their candles and order books requested.
 func (dcr *DecredDEX) handleConfigResponse(msg *msgjson.Message) {
 	cfg := new(msgjson.ConfigResult)
 	err := msg.UnmarshalResult(cfg)
@@ -2937,8 +3160,14 @@ func (dcr *DecredDEX) handleConfigResponse(msg *msgjson.Message) {
 		dcr.setWsFail(fmt.Errorf("error unmarshaling config response: %v", err))
 		return
 	}
+
+	type binSize struct {
+		key    candlestickKey
+		durStr string
+	}
 	// If the server is not of sufficient version to support the data API,
 	// BinSizes will be nil and we won't create any candle caches.
+	var binSizes []binSize
 	for _, durStr := range cfg.BinSizes {
 		dur, err := time.ParseDuration(durStr)
 		if err != nil {
@@ -2957,41 +3186,77 @@ func (dcr *DecredDEX) handleConfigResponse(msg *msgjson.Message) {
 			log.Debugf("Skipping unknown candlestick duration %q", durStr)
 			continue
 		}
+		binSizes = append(binSizes, binSize{key, durStr})
+	}
 
-		_, err = dcr.request(msgjson.CandlesRoute, &msgjson.CandlesRequest{
-			BaseID:     42,
-			QuoteID:    0,
-			BinSize:    durStr,
-			NumCandles: dexcandles.CacheSize,
-		}, func(msg *msgjson.Message) {
-			dcr.handleCandles(key, msg)
-		})
+	symbols := make(map[uint32]string, len(cfg.Assets))
+	factors := make(map[uint32]float64, len(cfg.Assets))
+	for _, asset := range cfg.Assets {
+		symbols[asset.ID] = asset.Symbol
+		if f := asset.UnitInfo.Conventional.ConversionFactor; f > 0 {
+			factors[asset.ID] = float64(f)
+		}
+	}
+	factor := func(assetID uint32) float64 {
+		if f, found := factors[assetID]; found {
+			return f
+		}
+		return dexDefaultConversionFactor
+	}
+
+	markets := make(map[string]*dexMarket)
+	pairs := make(map[CurrencyPair]bool)
+	for _, m := range cfg.Markets {
+		if m.Base != dexDCRAssetID {
+			continue
+		}
+		symbol := symbols[m.Quote]
+		if symbol == "" {
+			symbol = dex.BipIDSymbol(m.Quote)
+		}
+		if symbol == "" {
+			log.Warnf("Skipping %s market %s with unknown quote asset %d", dcr.cfg.Host, m.Name, m.Quote)
+			continue
+		}
+		markets[m.Name] = newDEXMarket(m.Name, dexCurrencyPair(symbol, pairs), m.Quote,
+			factor(dexDCRAssetID), factor(m.Quote))
+	}
+	if len(markets) == 0 {
+		dcr.setWsFail(fmt.Errorf("no DCR markets in dcrdex config response"))
+		return
+	}
+	dcr.markets = markets
+
+	for _, mkt := range sortedDEXMarkets(markets) {
+		for _, bin := range binSizes {
+			if err = dcr.requestCandles(mkt, bin.key, bin.durStr); err != nil {
+				dcr.setWsFail(fmt.Errorf("error requesting %s candles for bin size %s: %w", mkt.name, bin.durStr, err))
+				return
+			}
+		}
+		_, err = dcr.request(msgjson.OrderBookRoute, &msgjson.OrderBookSubscription{
+			Base:  dexDCRAssetID,
+			Quote: mkt.quote,
+		}, dcr.handleSubResponse)
 		if err != nil {
-			dcr.setWsFail(fmt.Errorf("error requesting candles for bin size %s: %w", durStr, err))
+			dcr.setWsFail(err)
 			return
 		}
 	}
 }
 
-// checkSeq verifies that the seq is sequential, and increments the seq counter.
-// checkSeq should only be called with the orderMtx write-locked.
-func (dcr *DecredDEX) checkSeq(seq uint64) bool {
-	if seq != dcr.seq+1 {
-		dcr.setWsFail(fmt.Errorf("incorrect sequence. wanted %d, got %d", dcr.seq+1, seq))
+// checkSeq verifies that the seq is sequential for the market, and increments
+// the market's seq counter. checkSeq should only be called with the orderMtx
+// write-locked.
+func (dcr *DecredDEX) checkSeq(mkt *dexMarket, seq uint64) bool {
+	if seq != mkt.seq+1 {
+		dcr.setWsFail(fmt.Errorf("incorrect sequence for %s. wanted %d, got %d", mkt.name, mkt.seq+1, seq))
 		return false
 	}
-	dcr.seq = seq
+	mkt.seq = seq
 	return true
 }
 
-// clearOrderBook clears the order book. clearOrderBook should only be called
-// with the orderMtx write-locked.
-func (dcr *DecredDEX) clearOrderBook() {
-	dcr.buys = make(wsOrders)
-	dcr.asks = make(wsOrders)
-	dcr.ords = make(map[string]*msgjson.BookOrderNote)
-}
-
 // lastStamp is the unix timestamp of the received response or notification.
 func (dcr *DecredDEX) lastStamp() int64 {
 	dcr.orderMtx.RLock()
@@ -3001,82 +3266,67 @@ func (dcr *DecredDEX) lastStamp() int64 {
 
 // setOrderBook processes the order book data from 'orderbook' request.
 // setOrderBook should only be called with the orderMtx write-locked.
-func (dcr *DecredDEX) setOrderBook(ob *msgjson.OrderBook) {
-	dcr.clearOrderBook()
-	dcr.seq = ob.Seq
-	addToSide := func(side wsOrders, ord *msgjson.BookOrderNote) {
-		bucket := side.order(int64(ord.Rate), float64(ord.Rate)/1e8)
-		bucket.volume += float64(ord.Quantity) / 1e8
-		dcr.ords[ord.OrderID.String()] = ord
-	}
+func (dcr *DecredDEX) setOrderBook(mkt *dexMarket, ob *msgjson.OrderBook) {
+	mkt.clearOrderBook()
+	mkt.seq = ob.Seq
 
 	for _, ord := range ob.Orders {
 		if ord == nil {
 			dcr.setWsFail(fmt.Errorf("nil order encountered"))
 			return
 		}
-		if ord.Side == msgjson.BuyOrderNum {
-			addToSide(dcr.buys, ord)
-		} else {
-			addToSide(dcr.asks, ord)
-		}
+		dcr.bookOrder(mkt, ord)
 	}
 	dcr.wsInitialized()
 
-	depth := dcr.wsDepthSnapshot()
+	depth := mkt.depth()
 
-	if dcr.lastRate == 0 {
+	if mkt.lastRate == 0 {
 		if len(ob.Orders) == 0 {
 			return // don't send rate update if we don't have a valid rate and there are no orders to get a sane midGap.
 		}
 		// Use mid gap as a sane default if the orderbook is not empty.
-		dcr.lastRate = depth.MidGap()
+		mkt.lastRate = depth.MidGap()
 	}
 
-	dcr.Update(CurrencyPairDCRBTC, &ExchangeState{
+	dcr.Update(mkt.pair, &ExchangeState{
 		BaseState: BaseState{
-			Price: dcr.lastRate,
+			Price: mkt.lastRate,
 			// Change:       priceChange, // With candlesticks
 			Stamp: dcr.stamp,
 		},
 		// Candlesticks: candlesticks, // Not yet
-		Depth: depth,
+		Depth:  depth,
+		Trades: mkt.tradeHistory(),
 	})
 }
 
 // bookOrder processes the 'book_order' notification.
 // bookOrder should only be called with the orderMtx write-locked.
-func (dcr *DecredDEX) bookOrder(ord *msgjson.BookOrderNote) {
-	side := dcr.asks
-	if ord.Side == msgjson.BuyOrderNum {
-		side = dcr.buys
-	}
-	bucket := side.order(int64(ord.Rate), float64(ord.Rate)/1e8)
-	bucket.volume += float64(ord.Quantity) / 1e8
-	dcr.ords[ord.OrderID.String()] = ord
+func (dcr *DecredDEX) bookOrder(mkt *dexMarket, ord *msgjson.BookOrderNote) {
+	bucket := mkt.side(ord.Side).order(int64(ord.Rate), mkt.rate(ord.Rate))
+	bucket.volume += mkt.qty(ord.Quantity)
+	mkt.ords[ord.OrderID.String()] = ord
 }
 
 // unbookOrder processes the 'unbook_order' notification.
 // unbookOrder should only be called with the orderMtx write-locked.
-func (dcr *DecredDEX) unbookOrder(note *msgjson.UnbookOrderNote) {
+func (dcr *DecredDEX) unbookOrder(mkt *dexMarket, note *msgjson.UnbookOrderNote) {
 	if len(note.OrderID) == 0 {
 		dcr.setWsFail(fmt.Errorf("received unbook_order notification without an order ID"))
 		return
 	}
 	oid := note.OrderID.String()
-	ord := dcr.ords[oid]
+	ord := mkt.ords[oid]
 	if ord == nil {
 		dcr.setWsFail(fmt.Errorf("no order found to unbook"))
 		return
 	}
-	delete(dcr.ords, oid)
-	side := dcr.asks
-	if ord.Side == msgjson.BuyOrderNum {
-		side = dcr.buys
-	}
+	delete(mkt.ords, oid)
+	side := mkt.side(ord.Side)
 	rateKey := int64(ord.Rate)
-	bucket := side.order(rateKey, float64(ord.Rate)/1e8)
-	bucket.volume -= float64(ord.Quantity) / 1e8
+	bucket := side.order(rateKey, mkt.rate(ord.Rate))
+	bucket.volume -= mkt.qty(ord.Quantity)
 	if bucket.volume < 1e-8 { // Account for floating point imprecision.
 		delete(side, rateKey)
 	}
@@ -3084,27 +3334,24 @@ func (dcr *DecredDEX) unbookOrder(note *msgjson.UnbookOrderNote) {
 
 // updateRemaining processes the 'update_remaining' notification.
 // updateRemaining should only be called with the orderMtx write-locked.
-func (dcr *DecredDEX) updateRemaining(update *msgjson.UpdateRemainingNote) {
+func (dcr *DecredDEX) updateRemaining(mkt *dexMarket, update *msgjson.UpdateRemainingNote) {
 	if len(update.OrderID) == 0 {
 		dcr.setWsFail(fmt.Errorf("received update_remaining notification without an order ID"))
 		return
 	}
 	oid := update.OrderID.String()
-	ord := dcr.ords[oid]
+	ord := mkt.ords[oid]
 	if ord == nil {
-		dcr.setWsFail(fmt.Errorf("order %s from dex.decred.org was not in our book", oid))
+		dcr.setWsFail(fmt.Errorf("order %s from %s was not in our book", oid, dcr.cfg.Host))
 		return
 	}
 
 	diff := ord.Quantity - update.Remaining
 	ord.Quantity = update.Remaining
-	side := dcr.asks
-	if ord.Side == msgjson.BuyOrderNum {
-		side = dcr.buys
-	}
+	side := mkt.side(ord.Side)
 	rateKey := int64(ord.Rate)
-	bucket := side.order(rateKey, float64(ord.Rate)/1e8)
-	bucket.volume -= float64(diff) / 1e8
+	bucket := side.order(rateKey, mkt.rate(ord.Rate))
+	bucket.volume -= mkt.qty(diff)
 	if bucket.volume < 1e-8 {
 		delete(side, rateKey)
 	}
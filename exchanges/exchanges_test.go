@@ -303,9 +303,10 @@ func newTestDex() *DecredDEX {
 			asks: make(wsOrders),
 			buys: make(wsOrders),
 		},
-		ords:         make(map[string]*msgjson.BookOrderNote),
-		candleCaches: make(map[uint64]*candleCache),
-		reqs:         make(map[uint64]func(*msgjson.Message)),
+		reqs: make(map[uint64]func(*msgjson.Message)),
+		markets: map[string]*dexMarket{
+			"dcr_btc": newDEXMarket("dcr_btc", CurrencyPairDCRBTC, 0, 1e8, 1e8),
+		},
 	}
 }
 
@@ -393,9 +394,10 @@ func TestDecredDEX(t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 		dcr.orderMtx.RLock()
 		defer dcr.orderMtx.RUnlock()
-		if len(dcr.asks) != askLen || len(dcr.buys) != buyLen {
+		mkt := dcr.markets[mktID]
+		if len(mkt.asks) != askLen || len(mkt.buys) != buyLen {
 			t.Errorf("unexpected order book lengths (%d, %d). expected (%d, %d)",
-				len(dcr.asks), len(dcr.buys), askLen, buyLen)
+				len(mkt.asks), len(mkt.buys), askLen, buyLen)
 		}
 	}
 
@@ -419,7 +421,9 @@ func TestDecredDEX(t *testing.T) {
 
 	ws.r <- mustEncode(newUpdateRemainingMsg([]byte("3"), 1))
 	checkLengths(3, 3)
-	depths := dcr.wsDepths()
+	dcr.orderMtx.RLock()
+	depths := dcr.markets[mktID].depth()
+	dcr.orderMtx.RUnlock()
 	bestBuy := depths.Bids[0]
 	if eightPtKey(bestBuy.Quantity) != 1 {
 		t.Fatalf("wrong quantity after update_remaining: wanted 0.00000001, got %.8f", bestBuy.Quantity)
@@ -429,7 +433,13 @@ func TestDecredDEX(t *testing.T) {
 	}
 
 	// Send through a config response to trigger the candles request.
-	msg, _ := msgjson.NewResponse(2, &msgjson.ConfigResult{BinSizes: []string{"24h"}}, nil)
+	msg, _ := msgjson.NewResponse(2, &msgjson.ConfigResult{
+		BinSizes: []string{"24h"},
+		Markets: []*msgjson.Market{
+			{Name: mktID, Base: 42, Quote: 0},
+			{Name: "btc_usdt", Base: 0, Quote: 966001},
+		},
+	}, nil)
 	dcr.handleConfigResponse(msg)
 
 	// Get the ID of the request, and prepare a response.
@@ -461,13 +471,59 @@ func TestDecredDEX(t *testing.T) {
 			t.Fatalf("candles never received")
 		}
 		dcr.cacheMtx.RLock()
-		numCaches = len(dcr.candleCaches)
+		numCaches = len(dcr.markets[mktID].candleCaches)
 		dcr.cacheMtx.RUnlock()
 		if numCaches > 0 {
 			break
 		}
 		time.Sleep(time.Millisecond)
 	}
+	if len(dcr.markets) != 1 {
+		t.Fatalf("expected only the DCR market to be tracked, got %d markets", len(dcr.markets))
+	}
+
+	// An epoch report sets the rate and records the matches.
+	msg, _ = msgjson.NewNotification(msgjson.EpochReportRoute, &msgjson.EpochReportNote{
+		MarketID:     mktID,
+		MatchSummary: [][2]int64{{15, 2}, {16, -3}},
+		Candle: msgjson.Candle{
+			StartStamp:  87400000,
+			EndStamp:    87460000,
+			MatchVolume: 5,
+			StartRate:   15,
+			EndRate:     16,
+			HighRate:    16,
+			LowRate:     15,
+		},
+	})
+	ws.r <- mustEncode(msg)
+	time.Sleep(10 * time.Millisecond)
+
+	dcr.orderMtx.RLock()
+	mkt := dcr.markets[mktID]
+	lastRate, trades := mkt.lastRate, mkt.tradeHistory()
+	dcr.orderMtx.RUnlock()
+	if eightPtKey(lastRate) != 16 {
+		t.Fatalf("wrong last rate after epoch report: %.8f", lastRate)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("expected 2 trades, got %d", len(trades))
+	}
+	// Most recent first.
+	if eightPtKey(trades[0].Price) != 16 || eightPtKey(trades[0].Quantity) != 3 || !trades[0].MakerSell {
+		t.Errorf("wrong maker sell trade %+v", trades[0])
+	}
+	if eightPtKey(trades[1].Price) != 15 || eightPtKey(trades[1].Quantity) != 2 || trades[1].MakerSell {
+		t.Errorf("wrong maker buy trade %+v", trades[1])
+	}
+	if trades[0].Stamp != 87460 {
+		t.Errorf("wrong trade stamp %d", trades[0].Stamp)
+	}
+	select {
+	case <-ws.candleID:
+		t.Errorf("candles requested for an epoch report in sync with the cache")
+	default:
+	}
 }
 
 func mustEncode(thing interface{}) []byte {
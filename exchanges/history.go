@@ -0,0 +1,289 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// PriceInterval is the interval of the DCR index prices recorded by a
+	// PriceHistory.
+	PriceInterval = time.Hour
+
+	// maxPriceAge is how long before a time the interval of a stored price may
+	// start for the price to value DCR at that time. Prices older than the
+	// hourly candlesticks are backfilled from daily candlesticks, and a day
+	// allows for gaps in the hourly prices, e.g. while dcrdata was not running.
+	maxPriceAge = 24 * time.Hour
+)
+
+// PricePoint is the DCR price in an index currency over an interval of the
+// price history. A backfilled price is computed from exchange candlesticks,
+// rather than being recorded from the ExchangeBot's index price.
+type PricePoint struct {
+	Index      string    `json:"index"`
+	Start      time.Time `json:"start"`
+	Price      float64   `json:"price"`
+	Volume     float64   `json:"volume"`
+	Backfilled bool      `json:"backfilled"`
+}
+
+// PriceStore is a persistent store of the history of the DCR index price.
+type PriceStore interface {
+	// StorePrices stores the points. A recorded point replaces any point
+	// stored for the same index and start time, but a backfilled point is
+	// only stored if there is no such point.
+	StorePrices(points []*PricePoint) error
+	// PriceAt is the latest point of the index starting in the maxAge before
+	// t, up to and including t, or nil if there is no such point.
+	PriceAt(index string, t time.Time, maxAge time.Duration) (*PricePoint, error)
+	// MeanPrice is the mean price of the index over the points starting from
+	// start up to but not including end, with each day that has a point
+	// weighted equally. The boolean is false if there are no such points.
+	MeanPrice(index string, start, end time.Time) (float64, bool, error)
+}
+
+// PriceHistory records the ExchangeBot's index price in a PriceStore. The price
+// of each PriceInterval is the mean of the bot's volume-weighted index prices
+// during the interval. Prices from before the recording started are backfilled
+// from the DCR-USDT candlesticks of the exchanges. Make a PriceHistory with
+// NewPriceHistory.
+type PriceHistory struct {
+	bot      *ExchangeBot
+	store    PriceStore
+	channels *UpdateChannels
+
+	mtx     sync.Mutex
+	current *PricePoint // the interval being recorded
+	samples int
+
+	// backfilled is the start of the latest backfilled interval stored. It is
+	// only used by the Run goroutine.
+	backfilled time.Time
+}
+
+// NewPriceHistory creates a PriceHistory of the bot's index price. It should
+// be created before the bot is started so that no updates are missed.
+func NewPriceHistory(bot *ExchangeBot, store PriceStore) *PriceHistory {
+	return &PriceHistory{
+		bot:      bot,
+		store:    store,
+		channels: bot.UpdateChannels(),
+	}
+}
+
+// Run records the bot's index price until the context is canceled or the bot
+// quits.
+func (h *PriceHistory) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	for {
+		select {
+		case update := <-h.channels.Exchange:
+			h.sample(time.Now())
+			if update.CurrencyPair == CurrencyPairDCRUSDT && len(update.State.Candlesticks) > 0 {
+				h.backfill()
+			}
+		case <-h.channels.Index:
+			h.sample(time.Now())
+		case <-h.channels.Quit:
+			h.flush()
+			return
+		case <-ctx.Done():
+			h.flush()
+			return
+		}
+	}
+}
+
+// sample adds the bot's current index price to the interval being recorded,
+// storing the recorded price of the previous interval if t is in a new one.
+func (h *PriceHistory) sample(t time.Time) {
+	state := h.bot.State()
+	if h.bot.IsFailed() || state == nil || state.Price == 0 {
+		return
+	}
+	start := t.Truncate(PriceInterval)
+
+	h.mtx.Lock()
+	var done *PricePoint
+	if h.current != nil && !h.current.Start.Equal(start) {
+		done = h.current
+		h.current, h.samples = nil, 0
+	}
+	if h.current == nil {
+		h.current = &PricePoint{Index: state.Index, Start: start}
+	}
+	// The mean price is updated incrementally.
+	h.samples++
+	h.current.Price += (state.Price - h.current.Price) / float64(h.samples)
+	h.current.Volume = state.Volume
+	h.mtx.Unlock()
+
+	if done != nil {
+		h.storePrices([]*PricePoint{done})
+	}
+}
+
+// flush stores the price of the interval being recorded, which may only have
+// been recorded for part of the interval.
+func (h *PriceHistory) flush() {
+	h.mtx.Lock()
+	done := h.current
+	h.current, h.samples = nil, 0
+	h.mtx.Unlock()
+
+	if done != nil {
+		h.storePrices([]*PricePoint{done})
+	}
+}
+
+// storePrices stores the points, logging any error.
+func (h *PriceHistory) storePrices(points []*PricePoint) {
+	if len(points) == 0 {
+		return
+	}
+	if err := h.store.StorePrices(points); err != nil {
+		log.Errorf("Failed to store %d %s prices: %v", len(points), h.bot.Index, err)
+	}
+}
+
+// backfill stores the backfilled prices of the intervals after the latest one
+// already backfilled. The prices of earlier intervals would not be stored
+// anyway, since a backfilled price is only stored for an interval without one.
+func (h *PriceHistory) backfill() {
+	points := h.bot.backfillPrices()
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].Start.After(h.backfilled)
+	})
+	points = points[i:]
+	if len(points) == 0 {
+		return
+	}
+	if err := h.store.StorePrices(points); err != nil {
+		log.Errorf("Failed to store %d backfilled %s prices: %v", len(points), h.bot.Index, err)
+		return
+	}
+	h.backfilled = points[len(points)-1].Start
+}
+
+// PriceAt is the DCR price in the bot's index currency at the time t. If t is
+// in the interval being recorded, the price is the mean price recorded so far.
+// The point is nil if there is no price for the time. PriceAt may be called on
+// a nil PriceHistory, which has no prices.
+func (h *PriceHistory) PriceAt(t time.Time) (*PricePoint, error) {
+	if h == nil {
+		return nil, nil
+	}
+	h.mtx.Lock()
+	if h.current != nil && !t.Before(h.current.Start) {
+		point := *h.current
+		h.mtx.Unlock()
+		return &point, nil
+	}
+	h.mtx.Unlock()
+	return h.store.PriceAt(h.bot.Index, t, maxPriceAge)
+}
+
+// ConversionAt converts the DCR value to the bot's index currency at the price
+// at the time t. The Conversion is nil if there is no price for the time.
+func (h *PriceHistory) ConversionAt(dcrVal float64, t time.Time) (*Conversion, error) {
+	point, err := h.PriceAt(t)
+	if point == nil || err != nil {
+		return nil, err
+	}
+	return &Conversion{
+		Value: point.Price * dcrVal,
+		Index: point.Index,
+	}, nil
+}
+
+// PeriodPrice is the mean DCR price in the bot's index currency over the period
+// from start up to but not including end, as the Conversion of 1 DCR. Each day
+// of the period with a stored price is weighted equally, and the interval being
+// recorded is not included. The Conversion is nil if there are no prices in the
+// period. PeriodPrice may be called on a nil PriceHistory, which has no prices.
+func (h *PriceHistory) PeriodPrice(start, end time.Time) (*Conversion, error) {
+	if h == nil {
+		return nil, nil
+	}
+	price, found, err := h.store.MeanPrice(h.bot.Index, start, end)
+	if !found || err != nil {
+		return nil, err
+	}
+	return &Conversion{
+		Value: price,
+		Index: h.bot.Index,
+	}, nil
+}
+
+// backfillPrices computes index prices from the DCR-USDT candlesticks of the
+// exchanges. The price of an interval is the volume-weighted mean close of the
+// exchanges' candlesticks starting at the same time, converted to the index
+// currency at the current USDT index price. The USDT rate at the time of the
+// interval is not known, so the backfilled prices of an index other than USD
+// are only approximate, increasingly so the older they are. Hourly prices are
+// computed from the hourly candlesticks, and daily prices from the daily
+// candlesticks that start before the first hourly candlestick.
+func (bot *ExchangeBot) backfillPrices() []*PricePoint {
+	bot.mtx.RLock()
+	defer bot.mtx.RUnlock()
+	usdtPrice := bot.indexPrice(USDTIndex, bot.Index)
+	if usdtPrice == 0 {
+		return nil
+	}
+
+	type accumulator struct {
+		closes, volume float64
+	}
+	var points []*PricePoint
+	var firstHour time.Time
+	for _, bin := range []candlestickKey{hourKey, dayKey} {
+		intervals := make(map[int64]*accumulator)
+		for _, pairs := range bot.currentState.DCRExchanges {
+			state := pairs[CurrencyPairDCRUSDT]
+			if state == nil {
+				continue
+			}
+			for _, stick := range state.Candlesticks[bin] {
+				if bin == dayKey && !firstHour.IsZero() && !stick.Start.Before(firstHour) {
+					continue
+				}
+				acc := intervals[stick.Start.Unix()]
+				if acc == nil {
+					acc = new(accumulator)
+					intervals[stick.Start.Unix()] = acc
+				}
+				acc.closes += stick.Close * stick.Volume
+				acc.volume += stick.Volume
+			}
+		}
+		for start, acc := range intervals {
+			if acc.volume == 0 {
+				continue // no trades to price the interval
+			}
+			startTime := time.Unix(start, 0).UTC()
+			points = append(points, &PricePoint{
+				Index:      bot.Index,
+				Start:      startTime,
+				Price:      usdtPrice * acc.closes / acc.volume,
+				Volume:     acc.volume,
+				Backfilled: true,
+			})
+			if bin == hourKey && (firstHour.IsZero() || startTime.Before(firstHour)) {
+				firstHour = startTime
+			}
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Start.Before(points[j].Start)
+	})
+	return points
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// memPriceStore is a PriceStore in memory.
+type memPriceStore struct {
+	points map[string]map[int64]*PricePoint
+	stores int // the number of points passed to StorePrices
+}
+
+func newMemPriceStore() *memPriceStore {
+	return &memPriceStore{points: make(map[string]map[int64]*PricePoint)}
+}
+
+func (s *memPriceStore) StorePrices(points []*PricePoint) error {
+	s.stores += len(points)
+	for _, p := range points {
+		if s.points[p.Index] == nil {
+			s.points[p.Index] = make(map[int64]*PricePoint)
+		}
+		stored := s.points[p.Index][p.Start.Unix()]
+		if stored == nil || !p.Backfilled {
+			pt := *p
+			s.points[p.Index][p.Start.Unix()] = &pt
+		}
+	}
+	return nil
+}
+
+func (s *memPriceStore) PriceAt(index string, t time.Time, maxAge time.Duration) (*PricePoint, error) {
+	var latest *PricePoint
+	for _, p := range s.points[index] {
+		if p.Start.After(t) || !p.Start.After(t.Add(-maxAge)) {
+			continue
+		}
+		if latest == nil || p.Start.After(latest.Start) {
+			latest = p
+		}
+	}
+	return latest, nil
+}
+
+func (s *memPriceStore) MeanPrice(index string, start, end time.Time) (float64, bool, error) {
+	days := make(map[int64][]float64)
+	for _, p := range s.points[index] {
+		if p.Start.Before(start) || !p.Start.Before(end) {
+			continue
+		}
+		day := p.Start.Unix() / 86400
+		days[day] = append(days[day], p.Price)
+	}
+	if len(days) == 0 {
+		return 0, false, nil
+	}
+	var sum float64
+	for _, prices := range days {
+		var daySum float64
+		for _, price := range prices {
+			daySum += price
+		}
+		sum += daySum / float64(len(prices))
+	}
+	return sum / float64(len(days)), true, nil
+}
+
+// newTestPriceBot creates an ExchangeBot with a USD index and the USDT index
+// price, but no exchanges.
+func newTestPriceBot(usdtPrice float64) *ExchangeBot {
+	bot := &ExchangeBot{
+		Index:  "USD",
+		config: &ExchangeBotConfig{Index: "USD"},
+		indexMap: map[string]map[CurrencyPair]FiatIndices{
+			"usdtindex": {USDTIndex: {"USD": usdtPrice}},
+		},
+		currentState: ExchangeBotState{
+			Index:        "USD",
+			DCRExchanges: make(map[string]map[CurrencyPair]*ExchangeState),
+		},
+	}
+	bot.stateCopy = bot.currentState.copy()
+	return bot
+}
+
+func TestBackfillPrices(t *testing.T) {
+	bot := newTestPriceBot(2)
+
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	hour := day.Add(24 * time.Hour)
+	bot.currentState.DCRExchanges["a"] = map[CurrencyPair]*ExchangeState{
+		CurrencyPairDCRUSDT: {Candlesticks: map[candlestickKey]Candlesticks{
+			dayKey: {
+				{Close: 10, Volume: 100, Start: day},
+				{Close: 11, Volume: 100, Start: hour},
+			},
+			hourKey: {
+				{Close: 11, Volume: 1, Start: hour},
+				{Close: 12, Volume: 0, Start: hour.Add(time.Hour)},
+			},
+		}},
+	}
+	bot.currentState.DCRExchanges["b"] = map[CurrencyPair]*ExchangeState{
+		CurrencyPairDCRUSDT: {Candlesticks: map[candlestickKey]Candlesticks{
+			hourKey: {{Close: 14, Volume: 3, Start: hour.Local()}},
+		}},
+		// Other markets are not used.
+		CurrencyPairDCRBTC: {Candlesticks: map[candlestickKey]Candlesticks{
+			hourKey: {{Close: 0.001, Volume: 100, Start: hour}},
+		}},
+	}
+
+	points := bot.backfillPrices()
+	want := []PricePoint{
+		{Index: "USD", Start: day, Price: 20, Volume: 100, Backfilled: true},
+		// (11*1 + 14*3) / 4 * 2
+		{Index: "USD", Start: hour, Price: 26.5, Volume: 4, Backfilled: true},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("expected %d points, got %d", len(want), len(points))
+	}
+	for i, p := range points {
+		if !p.Start.Equal(want[i].Start) || math.Abs(p.Price-want[i].Price) > 1e-9 ||
+			p.Volume != want[i].Volume || !p.Backfilled || p.Index != "USD" {
+			t.Errorf("point %d: got %+v, want %+v", i, *p, want[i])
+		}
+	}
+
+	// Only the intervals after the latest one backfilled are stored again.
+	store := newMemPriceStore()
+	h := NewPriceHistory(bot, store)
+	h.backfill()
+	if store.stores != 2 {
+		t.Fatalf("expected 2 points stored, got %d", store.stores)
+	}
+	bot.currentState.DCRExchanges["b"][CurrencyPairDCRUSDT].Candlesticks[hourKey] = Candlesticks{
+		{Close: 14, Volume: 3, Start: hour},
+		{Close: 15, Volume: 2, Start: hour.Add(2 * time.Hour)},
+	}
+	h.backfill()
+	if store.stores != 3 || store.points["USD"][hour.Add(2*time.Hour).Unix()] == nil {
+		t.Fatalf("expected only the new interval to be stored, got %d points", store.stores)
+	}
+
+	// No prices without the USDT index price.
+	bot.indexMap = map[string]map[CurrencyPair]FiatIndices{}
+	if points = bot.backfillPrices(); len(points) != 0 {
+		t.Errorf("expected no points without a USDT index price, got %d", len(points))
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	bot := newTestPriceBot(1)
+	store := newMemPriceStore()
+	h := NewPriceHistory(bot, store)
+
+	setPrice := func(price float64) {
+		bot.currentState.Price = price
+		bot.currentState.Volume = 1000
+		bot.stateCopy = bot.currentState.copy()
+	}
+
+	hour := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	setPrice(20)
+	h.sample(hour.Add(10 * time.Minute))
+	setPrice(24)
+	h.sample(hour.Add(40 * time.Minute))
+
+	// The interval being recorded is not stored yet.
+	if len(store.points["USD"]) != 0 {
+		t.Fatalf("expected no stored prices, got %d", len(store.points["USD"]))
+	}
+	p, err := h.PriceAt(hour.Add(50 * time.Minute))
+	if err != nil || p == nil || p.Price != 22 {
+		t.Fatalf("unexpected current price %+v (%v)", p, err)
+	}
+
+	// A price in the next interval stores the recorded price.
+	setPrice(30)
+	h.sample(hour.Add(70 * time.Minute))
+	stored := store.points["USD"][hour.Unix()]
+	if stored == nil || stored.Price != 22 || stored.Backfilled {
+		t.Fatalf("unexpected stored price %+v", stored)
+	}
+	c, err := h.ConversionAt(2, hour.Add(30*time.Minute))
+	if err != nil || c == nil || c.Value != 44 || c.Index != "USD" {
+		t.Errorf("unexpected conversion %+v (%v)", c, err)
+	}
+	if p, _ = h.PriceAt(hour.Add(-time.Minute)); p != nil {
+		t.Errorf("expected no price before the history, got %+v", p)
+	}
+
+	// A backfilled price does not replace the recorded price.
+	h.storePrices([]*PricePoint{{Index: "USD", Start: hour, Price: 1, Backfilled: true}})
+	if p, _ = h.PriceAt(hour); p.Price != 22 {
+		t.Errorf("recorded price replaced by a backfilled price %+v", p)
+	}
+
+	// Samples are skipped while the bot is failed.
+	bot.failed = true
+	setPrice(100)
+	h.sample(hour.Add(80 * time.Minute))
+	bot.failed = false
+	h.flush()
+	if stored = store.points["USD"][hour.Add(time.Hour).Unix()]; stored == nil || stored.Price != 30 {
+		t.Errorf("unexpected flushed price %+v", stored)
+	}
+
+	// The period price is the mean of the stored prices in the period.
+	day := hour.Truncate(24 * time.Hour)
+	if c, err = h.PeriodPrice(day, day.AddDate(0, 0, 1)); err != nil || c == nil || c.Value != 26 || c.Index != "USD" {
+		t.Errorf("unexpected period price %+v (%v)", c, err)
+	}
+	if c, err = h.PeriodPrice(day.AddDate(0, 0, 1), day.AddDate(0, 0, 2)); c != nil || err != nil {
+		t.Errorf("expected no period price, got %+v (%v)", c, err)
+	}
+
+	var nilHistory *PriceHistory
+	if p, err = nilHistory.PriceAt(hour); p != nil || err != nil {
+		t.Errorf("expected no price from a nil PriceHistory, got %+v (%v)", p, err)
+	}
+	if c, err = nilHistory.PeriodPrice(day, day.AddDate(0, 1, 0)); c != nil || err != nil {
+		t.Errorf("expected no period price from a nil PriceHistory, got %+v (%v)", c, err)
+	}
+}
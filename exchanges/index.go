@@ -0,0 +1,273 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Methods for combining the exchange prices into the DCR price index.
+const (
+	// IndexMean is a weighted mean of the exchange prices.
+	IndexMean = "mean"
+	// IndexMedian is the weighted median of the exchange prices.
+	IndexMedian = "median"
+	// IndexTrimmedMean is a weighted mean of the exchange prices with a
+	// fraction of the total weight discarded from each end of the price range.
+	IndexTrimmedMean = "trimmed"
+
+	// DefaultIndexTrim is the fraction of weight trimmed from each end by
+	// IndexTrimmedMean when IndexMethodology.Trim is not set.
+	DefaultIndexTrim = 0.2
+)
+
+// Reasons an exchange is not included in the price index.
+const (
+	IndexExcludedExpired      = "expired"
+	IndexExcludedNoIndexPrice = "no index price"
+	IndexExcludedNoVolume     = "no volume"
+	IndexExcludedOutlier      = "outlier"
+	IndexExcludedTrimmed      = "trimmed"
+)
+
+// minOutlierSources is the fewest exchanges for which outliers are rejected.
+// With only two prices, there is no way to tell which one is the outlier.
+const minOutlierSources = 3
+
+// IndexMethodology configures how the DCR price index is computed from the
+// exchange prices. The zero value is an equal-weight mean of the exchanges
+// with recent data.
+type IndexMethodology struct {
+	// Method is one of IndexMean (default), IndexMedian or IndexTrimmedMean.
+	Method string
+	// Trim is the fraction of the total weight discarded from each end by
+	// IndexTrimmedMean, in [0, 0.5).
+	Trim float64
+	// VolumeWeighted weights exchanges by their DCR volume, instead of
+	// equally.
+	VolumeWeighted bool
+	// MaxWeight caps any single exchange's share of the total weight, with
+	// the excess redistributed to the others. Zero is no cap.
+	MaxWeight float64
+	// MaxDeviation excludes exchanges whose price differs from the weighted
+	// median by more than this fraction of the median. Zero disables outlier
+	// rejection.
+	MaxDeviation float64
+	// StaleHalfLife halves an exchange's weight for every StaleHalfLife since
+	// its last update. Zero disables staleness decay.
+	StaleHalfLife time.Duration
+}
+
+// Validate checks the methodology and sets the default trim.
+func (m *IndexMethodology) Validate() error {
+	switch m.Method {
+	case "":
+		m.Method = IndexMean
+	case IndexMean, IndexMedian:
+	case IndexTrimmedMean:
+		if m.Trim == 0 {
+			m.Trim = DefaultIndexTrim
+		}
+	default:
+		return fmt.Errorf("unknown index method %q", m.Method)
+	}
+	if m.Trim < 0 || m.Trim >= 0.5 {
+		return fmt.Errorf("index trim must be in [0, 0.5), got %v", m.Trim)
+	}
+	if m.MaxWeight < 0 || m.MaxWeight > 1 {
+		return fmt.Errorf("index max weight must be in [0, 1], got %v", m.MaxWeight)
+	}
+	if m.MaxDeviation < 0 {
+		return fmt.Errorf("index max deviation cannot be negative, got %v", m.MaxDeviation)
+	}
+	if m.StaleHalfLife < 0 {
+		return fmt.Errorf("index stale half-life cannot be negative, got %v", m.StaleHalfLife)
+	}
+	return nil
+}
+
+// IndexWeight is an exchange's contribution to the DCR price index. Price and
+// Volume are the exchange's volume-averaged price, converted to the index
+// currency, and its total DCR volume. Weight is the exchange's share of the
+// index, and is zero if the exchange is not Included, in which case Reason
+// says why.
+type IndexWeight struct {
+	Price    float64 `json:"price"`
+	Volume   float64 `json:"volume"`
+	Weight   float64 `json:"weight"`
+	Included bool    `json:"included"`
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// indexSource is an exchange price to be combined into the index.
+type indexSource struct {
+	token  string
+	age    time.Duration
+	weight float64
+	*IndexWeight
+}
+
+// weightedMedian is the weighted median of the sources, which must be sorted
+// by price and have weights summing to 1. When the cumulative weight is
+// exactly one half at a price, the midpoint with the next price is used if
+// interpolate is true, otherwise the lower price.
+func weightedMedian(sources []*indexSource, interpolate bool) float64 {
+	const epsilon = 1e-9
+	var cum float64
+	for i, src := range sources {
+		cum += src.weight
+		if cum < 0.5-epsilon {
+			continue
+		}
+		if interpolate && cum < 0.5+epsilon && i+1 < len(sources) {
+			return (src.Price + sources[i+1].Price) / 2
+		}
+		return src.Price
+	}
+	return 0
+}
+
+// normalize scales the weights to sum to 1. Returns false if the weights sum to
+// zero.
+func normalize(sources []*indexSource) bool {
+	var sum float64
+	for _, src := range sources {
+		sum += src.weight
+	}
+	if sum == 0 {
+		return false
+	}
+	for _, src := range sources {
+		src.weight /= sum
+	}
+	return true
+}
+
+// capWeights limits each of the normalized weights to maxWeight, distributing
+// the excess proportionally among the uncapped sources. If there are too few
+// sources for the cap to be met, the weights are made equal.
+func capWeights(sources []*indexSource, maxWeight float64) {
+	if maxWeight == 0 {
+		return
+	}
+	if maxWeight*float64(len(sources)) <= 1 {
+		for _, src := range sources {
+			src.weight = 1 / float64(len(sources))
+		}
+		return
+	}
+	capped := make(map[*indexSource]bool, len(sources))
+	for {
+		// Each uncapped source gets a share of what's left after the capped
+		// sources are given maxWeight.
+		var uncappedWeight float64
+		for _, src := range sources {
+			if !capped[src] {
+				uncappedWeight += src.weight
+			}
+		}
+		remaining := 1 - maxWeight*float64(len(capped))
+		var newCaps bool
+		for _, src := range sources {
+			if !capped[src] && src.weight*remaining/uncappedWeight > maxWeight {
+				capped[src] = true
+				newCaps = true
+			}
+		}
+		if newCaps {
+			continue
+		}
+		for _, src := range sources {
+			if capped[src] {
+				src.weight = maxWeight
+			} else {
+				src.weight *= remaining / uncappedWeight
+			}
+		}
+		return
+	}
+}
+
+// combine computes the index price from the sources, which have a price and
+// volume set, and sets their IndexWeight. Returns the index price and the total
+// volume of the included sources.
+func (m *IndexMethodology) combine(sources []*indexSource) (price, volume float64) {
+	var candidates []*indexSource
+	for _, src := range sources {
+		if src.Price == 0 {
+			src.Reason = IndexExcludedNoVolume
+			continue
+		}
+		src.weight = 1
+		if m.VolumeWeighted {
+			src.weight = src.Volume
+		}
+		if m.StaleHalfLife > 0 && src.age > 0 {
+			src.weight *= math.Pow(0.5, float64(src.age)/float64(m.StaleHalfLife))
+		}
+		candidates = append(candidates, src)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Price == candidates[j].Price {
+			return candidates[i].token < candidates[j].token
+		}
+		return candidates[i].Price < candidates[j].Price
+	})
+	if !normalize(candidates) {
+		return 0, 0
+	}
+
+	if m.MaxDeviation > 0 && len(candidates) >= minOutlierSources {
+		// The reference is an actual exchange price, so that it can't be
+		// between two clusters of prices that would both be rejected.
+		median := weightedMedian(candidates, false)
+		var kept []*indexSource
+		for _, src := range candidates {
+			if math.Abs(src.Price-median) > m.MaxDeviation*median {
+				src.Reason = IndexExcludedOutlier
+				continue
+			}
+			kept = append(kept, src)
+		}
+		candidates = kept
+		normalize(candidates)
+	}
+
+	capWeights(candidates, m.MaxWeight)
+
+	switch m.Method {
+	case IndexMedian:
+		price = weightedMedian(candidates, true)
+	case IndexTrimmedMean:
+		// Each source keeps the part of its weight that lies within
+		// [Trim, 1-Trim] of the cumulative weight.
+		var cum float64
+		for _, src := range candidates {
+			lo, hi := math.Max(cum, m.Trim), math.Min(cum+src.weight, 1-m.Trim)
+			cum += src.weight
+			src.weight = math.Max(hi-lo, 0) / (1 - 2*m.Trim)
+			if src.weight == 0 {
+				src.Reason = IndexExcludedTrimmed
+			}
+		}
+		fallthrough
+	default:
+		for _, src := range candidates {
+			price += src.weight * src.Price
+		}
+	}
+
+	for _, src := range candidates {
+		if src.Reason != "" {
+			continue
+		}
+		src.Weight = src.weight
+		src.Included = true
+		volume += src.Volume
+	}
+	return price, volume
+}
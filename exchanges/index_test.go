@@ -0,0 +1,203 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIndexMethodologyValidate(t *testing.T) {
+	m := IndexMethodology{Method: IndexTrimmedMean}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if m.Trim != DefaultIndexTrim {
+		t.Errorf("default trim not set: %v", m.Trim)
+	}
+	m = IndexMethodology{}
+	if err := m.Validate(); err != nil || m.Method != IndexMean {
+		t.Errorf("zero value not a mean: %q (%v)", m.Method, err)
+	}
+
+	for _, m := range []IndexMethodology{
+		{Method: "mode"},
+		{Method: IndexTrimmedMean, Trim: 0.5},
+		{Trim: -0.1},
+		{MaxWeight: 1.5},
+		{MaxDeviation: -1},
+		{StaleHalfLife: -time.Minute},
+	} {
+		if m.Validate() == nil {
+			t.Errorf("no error for %+v", m)
+		}
+	}
+}
+
+func TestIndexMethodologyCombine(t *testing.T) {
+	type source struct {
+		token         string
+		price, volume float64
+		age           time.Duration
+	}
+	sources := []source{
+		{"a", 10, 100, 0},
+		{"b", 11, 100, 0},
+		{"c", 12, 200, 0},
+		{"d", 13, 600, 0},
+		{"e", 20, 1000, 0}, // an outlier
+		{"f", 0, 0, 0},
+	}
+	type result struct {
+		included bool
+		weight   float64
+		reason   string
+	}
+	tests := []struct {
+		name           string
+		methodology    IndexMethodology
+		sources        []source
+		price, volume  float64
+		expectedWeight map[string]result
+	}{
+		{
+			name:    "mean",
+			sources: sources,
+			price:   13.2,
+			volume:  2000,
+			expectedWeight: map[string]result{
+				"a": {true, 0.2, ""},
+				"e": {true, 0.2, ""},
+				"f": {false, 0, IndexExcludedNoVolume},
+			},
+		},
+		{
+			name:        "volume weighted",
+			methodology: IndexMethodology{VolumeWeighted: true},
+			sources:     sources,
+			price:       (1000 + 1100 + 2400 + 7800 + 20000) / 2000.,
+			volume:      2000,
+			expectedWeight: map[string]result{
+				"a": {true, 0.05, ""},
+				"e": {true, 0.5, ""},
+			},
+		},
+		{
+			name:        "outlier",
+			methodology: IndexMethodology{MaxDeviation: 0.2},
+			sources:     sources,
+			price:       11.5,
+			volume:      1000,
+			expectedWeight: map[string]result{
+				"d": {true, 0.25, ""},
+				"e": {false, 0, IndexExcludedOutlier},
+			},
+		},
+		{
+			name:        "too few for outliers",
+			methodology: IndexMethodology{MaxDeviation: 0.2},
+			sources:     sources[3:5],
+			price:       16.5,
+			volume:      1600,
+		},
+		{
+			name:        "median",
+			methodology: IndexMethodology{Method: IndexMedian},
+			sources:     sources,
+			price:       12,
+			volume:      2000,
+		},
+		{
+			name:        "median midpoint",
+			methodology: IndexMethodology{Method: IndexMedian},
+			sources:     sources[:4],
+			price:       11.5,
+			volume:      1000,
+		},
+		{
+			name:        "trimmed",
+			methodology: IndexMethodology{Method: IndexTrimmedMean, Trim: 0.2},
+			sources:     sources,
+			price:       12,
+			volume:      900,
+			expectedWeight: map[string]result{
+				"a": {false, 0, IndexExcludedTrimmed},
+				"b": {true, 1. / 3, ""},
+				"e": {false, 0, IndexExcludedTrimmed},
+			},
+		},
+		{
+			name:        "capped",
+			methodology: IndexMethodology{VolumeWeighted: true, MaxWeight: 0.35},
+			sources:     sources[:5],
+			// e is capped, and then d once e's excess is redistributed,
+			// leaving 0.3 for a, b and c at 1:1:2.
+			price:  0.075*10 + 0.075*11 + 0.15*12 + 0.35*13 + 0.35*20,
+			volume: 2000,
+			expectedWeight: map[string]result{
+				"a": {true, 0.075, ""},
+				"c": {true, 0.15, ""},
+				"d": {true, 0.35, ""},
+				"e": {true, 0.35, ""},
+			},
+		},
+		{
+			name:        "cap too low",
+			methodology: IndexMethodology{VolumeWeighted: true, MaxWeight: 0.1},
+			sources:     sources[:5],
+			price:       13.2,
+			volume:      2000,
+		},
+		{
+			name:        "stale",
+			methodology: IndexMethodology{StaleHalfLife: time.Hour},
+			sources: []source{
+				{"a", 10, 100, 0},
+				{"b", 16, 100, 2 * time.Hour},
+			},
+			price:  10*0.8 + 16*0.2,
+			volume: 200,
+			expectedWeight: map[string]result{
+				"a": {true, 0.8, ""},
+				"b": {true, 0.2, ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if err := tt.methodology.Validate(); err != nil {
+			t.Fatalf("%s: Validate error: %v", tt.name, err)
+		}
+		weights := make(map[string]*IndexWeight)
+		var indexSources []*indexSource
+		for _, src := range tt.sources {
+			weights[src.token] = &IndexWeight{Price: src.price, Volume: src.volume}
+			indexSources = append(indexSources, &indexSource{
+				token:       src.token,
+				age:         src.age,
+				IndexWeight: weights[src.token],
+			})
+		}
+		price, volume := tt.methodology.combine(indexSources)
+		if !closeTo(price, tt.price) {
+			t.Errorf("%s: wrong price %f. expected %f", tt.name, price, tt.price)
+		}
+		if !closeTo(volume, tt.volume) {
+			t.Errorf("%s: wrong volume %f. expected %f", tt.name, volume, tt.volume)
+		}
+		var weightSum float64
+		for _, w := range weights {
+			weightSum += w.Weight
+		}
+		if !closeTo(weightSum, 1) {
+			t.Errorf("%s: weights sum to %f", tt.name, weightSum)
+		}
+		for token, expected := range tt.expectedWeight {
+			w := weights[token]
+			if w.Included != expected.included || !closeTo(w.Weight, expected.weight) || w.Reason != expected.reason {
+				t.Errorf("%s: %s has weight %+v, expected %+v", tt.name, token, w, expected)
+			}
+		}
+	}
+}
@@ -18,6 +18,7 @@ const (
 	defaultExchangeRefresh = "5m"
 	defaultExchangeExpiry  = "60m"
 	defaultListen          = ":7778"
+	defaultHistorySize     = 2000
 	defaultBtcIndex        = "USD"
 )
 
@@ -31,9 +32,12 @@ type config struct {
 	ConfigPath        string   `short:"c" long:"config" description:"Path to a custom configuration file. (~/.dcrrates/rateserver.conf)" env:"DCRRATES_CONFIG_PATH"`
 	AppDirectory      string   `long:"appdir" description:"Path to application home directory. (~/.dcrrates)" env:"DCRRATES_APPDIR_PATH"`
 	GRPCListen        string   `short:"l" long:"listen" description:"gRPC listen address." env:"DCRRATES_LISTEN"`
+	HTTPListen        string   `long:"httplisten" description:"REST/JSON gateway listen address. The gateway is disabled if not set." env:"DCRRATES_HTTP_LISTEN"`
+	HistorySize       int      `long:"history" description:"Number of recent updates kept for replay to reconnecting clients." env:"DCRRATES_HISTORY"`
 	LogPath           string   `long:"logpath" description:"Directory to log output. ([appdir]/logs/)" env:"DCRRATES_LOG_PATH"`
 	LogLevel          string   `long:"loglevel" description:"Logging level {trace, debug, info, warn, error, critical}" env:"DCRRATES_LOG_LEVEL"`
 	DisabledExchanges string   `long:"disable-exchange" description:"Exchanges to disable. See /exchanges/exchanges.go for available exchanges. Use a comma to separate multiple exchanges" env:"DCRRATES_DISABLE_EXCHANGES"`
+	ExchangeSpecs     string   `long:"exchange-specs" description:"Path to a JSON file of exchange API specs. The declared exchanges replace any built-in exchange with the same token. See /exchanges/sample-exchange-specs.json." env:"DCRRATES_EXCHANGE_SPECS"`
 	ExchangeCurrency  string   `long:"exchange-currency" description:"The default {bitcoin, usdt} price index. A 3-letter currency code." env:"DCRRATES_EXCHANGE_INDEX"`
 	ExchangeRefresh   string   `long:"exchange-refresh" description:"Time between API calls for exchange data. See (ExchangeBotConfig).DataExpiry." env:"DCRRATES_EXCHANGE_REFRESH"`
 	ExchangeExpiry    string   `long:"exchange-expiry" description:"Maximum age before exchange data is discarded. See (ExchangeBotConfig).RequestExpiry." env:"DCRRATES_EXCHANGE_EXPIRY"`
@@ -45,6 +49,7 @@ type config struct {
 var defaultConfig = config{
 	AppDirectory:     DefaultAppDirectory,
 	GRPCListen:       defaultListen,
+	HistorySize:      defaultHistorySize,
 	ExchangeCurrency: defaultBtcIndex,
 	ExchangeRefresh:  defaultExchangeRefresh,
 	ExchangeExpiry:   defaultExchangeExpiry,
@@ -129,6 +134,9 @@ func loadConfig() (*config, error) {
 	} else {
 		cfg.LogPath = cleanAndExpandPath(cfg.LogPath)
 	}
+	if cfg.ExchangeSpecs != "" {
+		cfg.ExchangeSpecs = cleanAndExpandPath(cfg.ExchangeSpecs)
+	}
 
 	return &cfg, nil
 }
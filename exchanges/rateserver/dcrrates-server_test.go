@@ -4,8 +4,15 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/decred/dcrdata/exchanges/v3"
@@ -13,8 +20,8 @@ import (
 )
 
 func TestAddDeleteClient(t *testing.T) {
-	server := NewRateServer("", nil)
-	_, sid := server.addClient(nil, nil)
+	server := NewRateServer("", newBotStub(), 0)
+	_, sid, _ := server.addClient(nil, nil)
 	if len(server.clients) != 1 {
 		t.Fatalf("client length after addClient: %d, expecting 1", len(server.clients))
 	}
@@ -24,53 +31,290 @@ func TestAddDeleteClient(t *testing.T) {
 	}
 }
 
-type clientStub struct {
-	dcrExchanges map[string]map[exchanges.CurrencyPair]*exchanges.ExchangeState
+func TestUpdateHistory(t *testing.T) {
+	h := newUpdateHistory(3)
+	if _, ok := h.since(1); !ok {
+		t.Fatalf("empty history should cover any time")
+	}
+	for i := int64(1); i <= 2; i++ {
+		h.add(&rateUpdate{Received: i})
+	}
+	updates, ok := h.since(1)
+	if !ok || len(updates) != 2 {
+		t.Fatalf("expected 2 updates before wrapping, got %d (ok = %t)", len(updates), ok)
+	}
+	for i := int64(3); i <= 5; i++ {
+		h.add(&rateUpdate{Received: i})
+	}
+	// Updates 1 and 2 have been discarded, so update 3 may have company.
+	if _, ok = h.since(3); ok {
+		t.Fatalf("history should not cover the time of the oldest update")
+	}
+	updates, ok = h.since(4)
+	if !ok || len(updates) != 2 {
+		t.Fatalf("expected 2 updates since 4, got %d (ok = %t)", len(updates), ok)
+	}
+	if updates[0].Received != 4 || updates[1].Received != 5 {
+		t.Fatalf("wrong updates since 4: %d, %d", updates[0].Received, updates[1].Received)
+	}
+
+	if _, ok = newUpdateHistory(0).since(1); ok {
+		t.Fatalf("zero-size history should never replay")
+	}
+}
+
+type botStub struct {
+	state   *exchanges.ExchangeBotState
+	indices map[string]map[exchanges.CurrencyPair]exchanges.FiatIndices
+}
+
+func (bot *botStub) State() *exchanges.ExchangeBotState {
+	return bot.state
+}
+
+func (bot *botStub) Indices(token string) map[exchanges.CurrencyPair]exchanges.FiatIndices {
+	return bot.indices[token]
 }
 
-func (c *clientStub) SendExchangeUpdate(update *dcrrates.ExchangeRateUpdate) error {
-	if c.dcrExchanges == nil {
-		c.dcrExchanges = make(map[string]map[exchanges.CurrencyPair]*exchanges.ExchangeState)
+func newBotStub() *botStub {
+	return &botStub{
+		state: &exchanges.ExchangeBotState{
+			DCRExchanges: map[string]map[exchanges.CurrencyPair]*exchanges.ExchangeState{
+				"binance": {
+					exchanges.CurrencyPairDCRBTC: {BaseState: exchanges.BaseState{Price: 0.0003, Stamp: 10}},
+				},
+			},
+			FiatIndices: map[string]map[exchanges.CurrencyPair]*exchanges.ExchangeState{
+				"coinbase": {
+					exchanges.BTCIndex: {BaseState: exchanges.BaseState{Price: 60000, Stamp: 10}},
+				},
+			},
+		},
+		indices: map[string]map[exchanges.CurrencyPair]exchanges.FiatIndices{
+			"coinbase": {
+				exchanges.BTCIndex: {"USD": 60000},
+			},
+		},
 	}
+}
 
-	if c.dcrExchanges[update.Token] == nil {
-		c.dcrExchanges[update.Token] = make(map[exchanges.CurrencyPair]*exchanges.ExchangeState)
+func TestBroadcast(t *testing.T) {
+	server := NewRateServer("", newBotStub(), 10)
+	sub := &subscriber{updates: make(chan *dcrrates.ExchangeRateUpdate, 1)}
+	server.clients[1] = sub
+	ch := make(chan *rateUpdate, 1)
+	server.streams[2] = ch
+
+	u := &rateUpdate{
+		Token:        "binance",
+		CurrencyPair: exchanges.CurrencyPairDCRBTC,
+		State:        &exchanges.ExchangeState{BaseState: exchanges.BaseState{Price: 0.0004}},
+		Received:     20,
+	}
+	server.broadcast(u)
+	if update := <-sub.updates; update.Price != 0.0004 {
+		t.Fatalf("gRPC client did not receive the update")
+	}
+	if <-ch != u {
+		t.Fatalf("HTTP stream did not receive the update")
+	}
+	if updates, _ := server.history.since(20); len(updates) != 1 {
+		t.Fatalf("update was not added to the history")
 	}
 
-	currencyPair := exchanges.CurrencyPair(update.CurrencyPair)
-	c.dcrExchanges[update.Token][currencyPair] = &exchanges.ExchangeState{
-		BaseState: exchanges.BaseState{
-			Price:      update.GetPrice(),
-			BaseVolume: update.GetBaseVolume(),
-			Volume:     update.GetVolume(),
-			Change:     update.GetChange(),
-			Stamp:      update.GetStamp(),
-		},
+	// A full client or stream is closed and removed.
+	sub.updates <- u.proto()
+	ch <- u
+	server.broadcast(u)
+	if len(server.clients) != 0 {
+		t.Fatalf("full client was not removed")
 	}
+	if len(server.streams) != 0 {
+		t.Fatalf("full stream was not removed")
+	}
+	<-sub.updates
+	if _, ok := <-sub.updates; ok {
+		t.Fatalf("full client was not closed")
+	}
+	<-ch
+	if _, ok := <-ch; ok {
+		t.Fatalf("full stream was not closed")
+	}
+}
 
-	return nil
+type grpcStreamStub struct {
+	ctx     context.Context
+	updates []*dcrrates.ExchangeRateUpdate
+	sent    chan *dcrrates.ExchangeRateUpdate
+	err     error
 }
 
-func (c *clientStub) Stream() GRPCStream {
+func (s *grpcStreamStub) Send(u *dcrrates.ExchangeRateUpdate) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.sent != nil {
+		s.sent <- u
+		return nil
+	}
+	s.updates = append(s.updates, u)
 	return nil
 }
 
-func TestSendStateList(t *testing.T) {
-	updates := make(map[string]map[exchanges.CurrencyPair]*exchanges.ExchangeState)
-	currencyPair := exchanges.CurrencyPairDCRBTC
-	xcToken := "DummyToken"
-	updates[xcToken] = map[exchanges.CurrencyPair]*exchanges.ExchangeState{
-		currencyPair: {},
+func (s *grpcStreamStub) Context() context.Context {
+	return s.ctx
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	server := NewRateServer("", newBotStub(), 10)
+	for i := int64(20); i < 23; i++ {
+		server.history.add(&rateUpdate{
+			Token:        "binance",
+			CurrencyPair: exchanges.CurrencyPairDCRBTC,
+			State:        &exchanges.ExchangeState{BaseState: exchanges.BaseState{Stamp: i}},
+			Received:     i,
+		})
 	}
 
-	client := &clientStub{}
-	err := sendStateList(client, updates)
-	if err != nil {
-		t.Fatalf("Error sending exchange states: %v", err)
+	subscribe := func(since int64) []*dcrrates.ExchangeRateUpdate {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		stream := &grpcStreamStub{ctx: ctx}
+		err := server.ReallySubscribeExchanges(&dcrrates.ExchangeSubscription{Exchanges: []string{"binance", "coinbase"}, Since: since}, stream)
+		if err != nil {
+			t.Fatalf("ReallySubscribeExchanges error: %v", err)
+		}
+		return stream.updates
+	}
+
+	// The updates since the time are replayed, without the full state.
+	updates := subscribe(21)
+	if len(updates) != 2 || updates[0].Stamp != 21 || updates[1].Stamp != 22 {
+		t.Fatalf("wrong replay: %v", updates)
+	}
+
+	// A new client gets the full state, one exchange and one index.
+	if updates = subscribe(0); len(updates) != 2 {
+		t.Fatalf("expected 2 state updates, got %d", len(updates))
+	}
+
+	// A client that cannot be sent its updates is not kept.
+	stream := &grpcStreamStub{ctx: context.Background(), err: errors.New("send failed")}
+	if err := server.ReallySubscribeExchanges(&dcrrates.ExchangeSubscription{Exchanges: []string{"binance"}, Since: 21}, stream); err == nil {
+		t.Fatalf("expected a send error")
+	}
+	if len(server.clients) != 0 {
+		t.Fatalf("client was kept after a send error")
+	}
+
+	// A subscribed client is sent the replay, then the broadcast updates, and
+	// is dropped when it is not keeping up.
+	stream = &grpcStreamStub{ctx: context.Background(), sent: make(chan *dcrrates.ExchangeRateUpdate)}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ReallySubscribeExchanges(&dcrrates.ExchangeSubscription{Exchanges: []string{"binance"}, Since: 22}, stream)
+	}()
+	if u := <-stream.sent; u.Stamp != 22 {
+		t.Fatalf("wrong replay %d", u.Stamp)
+	}
+	u := &rateUpdate{
+		Token:        "binance",
+		CurrencyPair: exchanges.CurrencyPairDCRBTC,
+		State:        &exchanges.ExchangeState{BaseState: exchanges.BaseState{Stamp: 23}},
+		Received:     23,
+	}
+	server.broadcast(u)
+	if u := <-stream.sent; u.Stamp != 23 {
+		t.Fatalf("wrong broadcast update %d", u.Stamp)
+	}
+	// One update is held by the blocked Send, and one more than the buffer is
+	// left waiting.
+	for i := 0; i < clientBuffer+2; i++ {
+		server.broadcast(u)
+	}
+	for {
+		select {
+		case <-stream.sent:
+			continue
+		case err := <-done:
+			if err == nil {
+				t.Fatalf("expected an error for a lagging client")
+			}
+		}
+		break
+	}
+	if len(server.clients) != 0 {
+		t.Fatalf("lagging client was kept")
+	}
+}
+
+func TestGateway(t *testing.T) {
+	server := NewRateServer("", newBotStub(), 10)
+	ts := httptest.NewServer(server.httpHandler())
+	defer ts.Close()
+
+	get := func(path string, thing interface{}) int {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if err = json.NewDecoder(resp.Body).Decode(thing); err != nil {
+				t.Fatalf("error decoding %s: %v", path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var state exchanges.ExchangeBotState
+	if code := get("/state", &state); code != http.StatusOK || len(state.DCRExchanges) != 1 {
+		t.Fatalf("wrong /state response: %d, %v", code, state)
+	}
+	var xc tokenState
+	if code := get("/state/binance", &xc); code != http.StatusOK || xc.Markets[exchanges.CurrencyPairDCRBTC].Price != 0.0003 {
+		t.Fatalf("wrong exchange state response: %d, %v", code, xc)
+	}
+	var index tokenState
+	if code := get("/state/coinbase", &index); code != http.StatusOK || index.Indices[exchanges.BTCIndex]["USD"] != 60000 {
+		t.Fatalf("wrong index state response: %d, %v", code, index)
+	}
+	if code := get("/state/nonexistent", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown token, got %d", code)
 	}
 
-	if client.dcrExchanges[xcToken][currencyPair] == nil {
-		t.Fatalf("expected at least one exchange state for currency pair %s", currencyPair)
+	// The stream replays the history since the Last-Event-ID, then sends new
+	// updates.
+	server.history.add(&rateUpdate{Token: "binance", CurrencyPair: exchanges.CurrencyPairDCRBTC, Received: 20})
+	server.history.add(&rateUpdate{Token: "binance", CurrencyPair: exchanges.CurrencyPairDCRBTC, Received: 21})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/stream", nil)
+	req.Header.Set("Last-Event-ID", "21")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request error: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("wrong stream content type %q", ct)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	nextID := func() string {
+		for scanner.Scan() {
+			if id, found := strings.CutPrefix(scanner.Text(), "id: "); found {
+				return id
+			}
+		}
+		t.Fatalf("stream ended: %v", scanner.Err())
+		return ""
+	}
+	if id := nextID(); id != "21" {
+		t.Fatalf("expected replayed event 21, got %s", id)
+	}
+	server.broadcast(&rateUpdate{Token: "binance", CurrencyPair: exchanges.CurrencyPairDCRBTC, Received: 22})
+	if id := nextID(); id != "22" {
+		t.Fatalf("expected broadcast event 22, got %s", id)
 	}
 }
 
@@ -0,0 +1,214 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrdata/exchanges/v3"
+)
+
+const (
+	// streamBuffer is the number of updates that can be waiting to be written
+	// to an HTTP stream before the stream is closed.
+	streamBuffer = 64
+	// streamKeepAlive is the time between comments written to an idle HTTP
+	// stream, to keep proxies from closing the connection.
+	streamKeepAlive = 30 * time.Second
+)
+
+// tokenState is the state of a single exchange or index. An exchange has
+// Markets, and an index has Indices.
+type tokenState struct {
+	Token   string                                              `json:"token"`
+	Markets map[exchanges.CurrencyPair]*exchanges.ExchangeState `json:"markets,omitempty"`
+	Indices map[exchanges.CurrencyPair]exchanges.FiatIndices    `json:"indices,omitempty"`
+}
+
+// httpHandler is the REST/JSON gateway to the rate server.
+//
+//	/state          the ExchangeBotState
+//	/state/{token}  the state of an exchange or index
+//	/stream         a server-sent event stream of updates
+func (server *RateServer) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", server.handleState)
+	mux.HandleFunc("/state/", server.handleTokenState)
+	mux.HandleFunc("/stream", server.handleStream)
+	return mux
+}
+
+// writeJSON writes the thing as the JSON response.
+func writeJSON(w http.ResponseWriter, thing interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(thing); err != nil {
+		log.Warnf("JSON encode error: %v", err)
+	}
+}
+
+func (server *RateServer) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	state := server.xcBot.State()
+	if state == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, state)
+}
+
+func (server *RateServer) handleTokenState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, "/state/")
+	ts := &tokenState{Token: token}
+	if state := server.xcBot.State(); state != nil {
+		ts.Markets = state.DCRExchanges[token]
+	}
+	if len(ts.Markets) == 0 {
+		ts.Indices = server.xcBot.Indices(token)
+	}
+	if len(ts.Markets) == 0 && len(ts.Indices) == 0 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, ts)
+}
+
+// stateUpdates is the current state as a list of updates, for a new stream.
+func (server *RateServer) stateUpdates() []*rateUpdate {
+	state := server.xcBot.State()
+	if state == nil {
+		return nil
+	}
+	var updates []*rateUpdate
+	for token, xcStates := range state.DCRExchanges {
+		for pair, xcState := range xcStates {
+			updates = append(updates, &rateUpdate{
+				Token:        token,
+				CurrencyPair: pair,
+				State:        xcState,
+				Received:     xcState.Stamp,
+			})
+		}
+	}
+	for token := range state.FiatIndices {
+		for pair, indices := range server.xcBot.Indices(token) {
+			updates = append(updates, &rateUpdate{
+				Token:        token,
+				CurrencyPair: pair,
+				Indices:      indices,
+			})
+		}
+	}
+	return updates
+}
+
+// addStream registers a channel for the updates to an HTTP stream, and gets
+// the updates that the stream starts with: those since the UNIX timestamp if
+// they are all in the history, otherwise the current state. The updates are
+// collected under the clientLock, so that none are missed before the stream
+// is registered.
+func (server *RateServer) addStream(since int64) (chan *rateUpdate, StreamID, []*rateUpdate) {
+	server.clientLock.Lock()
+	defer server.clientLock.Unlock()
+	updates, ok := server.history.since(since)
+	if since == 0 || !ok {
+		updates = server.stateUpdates()
+	}
+	ch := make(chan *rateUpdate, streamBuffer)
+	streamCounter++
+	server.streams[streamCounter] = ch
+	return ch, streamCounter, updates
+}
+
+// deleteStream unregisters the HTTP stream, unless it has already been closed
+// by broadcast.
+func (server *RateServer) deleteStream(sid StreamID) {
+	server.clientLock.Lock()
+	defer server.clientLock.Unlock()
+	delete(server.streams, sid)
+}
+
+// handleStream streams the updates as server-sent events. The stream starts
+// with the current state, or, if a since UNIX timestamp is given as a query
+// parameter or as the Last-Event-ID of a reconnecting EventSource, with the
+// updates since then if they are all in the history. The event ID is the time
+// the update was received by the server.
+func (server *RateServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	sinceStr := r.URL.Query().Get("since")
+	if sinceStr == "" {
+		sinceStr = r.Header.Get("Last-Event-ID")
+	}
+	var since int64
+	if sinceStr != "" {
+		var err error
+		since, err = strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil || since < 0 {
+			http.Error(w, "invalid since timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ch, sid, updates := server.addStream(since)
+	defer server.deleteStream(sid)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent := func(u *rateUpdate) error {
+		b, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: update\ndata: %s\n\n", u.Received, b)
+		return err
+	}
+	for _, u := range updates {
+		if err := writeEvent(u); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case u, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := writeEvent(u); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"sync"
+
+	"github.com/decred/dcrdata/exchanges/v3"
+	dcrrates "github.com/decred/dcrdata/exchanges/v3/ratesproto"
+)
+
+// rateUpdate is an exchange or index update from the ExchangeBot, with the
+// time it was received by the server. Exchange updates have a State, and index
+// updates have Indices.
+type rateUpdate struct {
+	Token        string                   `json:"token"`
+	CurrencyPair exchanges.CurrencyPair   `json:"currency_pair"`
+	State        *exchanges.ExchangeState `json:"state,omitempty"`
+	Indices      exchanges.FiatIndices    `json:"indices,omitempty"`
+	Received     int64                    `json:"received"`
+}
+
+// proto translates the update to the gRPC type.
+func (u *rateUpdate) proto() *dcrrates.ExchangeRateUpdate {
+	if u.State == nil {
+		return &dcrrates.ExchangeRateUpdate{
+			Token:        u.Token,
+			CurrencyPair: string(u.CurrencyPair),
+			Indices:      u.Indices,
+		}
+	}
+	return makeExchangeRateUpdate(&exchanges.ExchangeUpdate{
+		Token:        u.Token,
+		CurrencyPair: u.CurrencyPair,
+		State:        u.State,
+	})
+}
+
+// updateHistory is a bounded ring of the most recent updates, for replay to
+// reconnecting clients.
+type updateHistory struct {
+	mtx     sync.RWMutex
+	updates []*rateUpdate
+	next    int
+	// wrapped is set once the oldest updates start being discarded.
+	wrapped bool
+}
+
+// newUpdateHistory creates an updateHistory that keeps up to size updates. If
+// size is zero, no updates are kept, and there is never a replay.
+func newUpdateHistory(size int) *updateHistory {
+	return &updateHistory{
+		updates: make([]*rateUpdate, size),
+	}
+}
+
+// add records the update, discarding the oldest if the history is full.
+func (h *updateHistory) add(u *rateUpdate) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if len(h.updates) == 0 {
+		return
+	}
+	h.updates[h.next] = u
+	h.next++
+	if h.next == len(h.updates) {
+		h.next = 0
+		h.wrapped = true
+	}
+}
+
+// since returns the updates received at or after the UNIX timestamp, oldest
+// first. If updates from that time may have been discarded, ok is false and
+// the client needs the full state instead.
+func (h *updateHistory) since(stamp int64) (updates []*rateUpdate, ok bool) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	if len(h.updates) == 0 {
+		return nil, false
+	}
+	ordered := h.updates[:h.next]
+	if h.wrapped {
+		// Any discarded update was received no later than the oldest kept.
+		if stamp <= h.updates[h.next].Received {
+			return nil, false
+		}
+		ordered = append(h.updates[h.next:len(h.updates):len(h.updates)], ordered...)
+	}
+	for _, u := range ordered {
+		if u.Received >= stamp {
+			updates = append(updates, u)
+		}
+	}
+	return updates, true
+}
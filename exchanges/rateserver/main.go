@@ -5,18 +5,22 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrdata/exchanges/v3"
 	dcrrates "github.com/decred/dcrdata/exchanges/v3/ratesproto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Default TLS configuration.
@@ -53,7 +57,7 @@ func main() {
 		log.Errorf("TLS certificate and key files must be provided")
 		return
 	}
-	creds, err := openRPCKeyPair(cfg)
+	cert, err := openRPCKeyPair(cfg)
 	if err != nil {
 		log.Errorf("TLS certificate error: %v", err)
 		return
@@ -65,6 +69,7 @@ func main() {
 		DataExpiry:    cfg.ExchangeRefresh,
 		RequestExpiry: cfg.ExchangeExpiry,
 		Index:         cfg.ExchangeCurrency,
+		SpecFile:      cfg.ExchangeSpecs,
 	}
 	if cfg.DisabledExchanges != "" {
 		botCfg.Disabled = strings.Split(cfg.DisabledExchanges, ",")
@@ -89,7 +94,7 @@ func main() {
 	wg.Add(1)
 	go xcBot.Start(ctx, &wg)
 
-	rateServer := NewRateServer(cfg.ExchangeCurrency, xcBot)
+	rateServer := NewRateServer(cfg.ExchangeCurrency, xcBot, cfg.HistorySize)
 
 	// Set up gRPC server.
 	listener, err := net.Listen("tcp", cfg.GRPCListen)
@@ -98,11 +103,37 @@ func main() {
 		shutdown()
 		return
 	}
-	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
 	dcrrates.RegisterDCRRatesServer(grpcServer, rateServer)
 
 	log.Infof("ExchangeBot listening on %s", listener.Addr())
 
+	// Set up the optional REST/JSON gateway, with the same TLS certificate as
+	// the gRPC server.
+	var httpServer *http.Server
+	if cfg.HTTPListen != "" {
+		httpListener, err := net.Listen("tcp", cfg.HTTPListen)
+		if err != nil {
+			log.Errorf("Failed to create net.Listener at %s", cfg.HTTPListen)
+			shutdown()
+			return
+		}
+		httpServer = &http.Server{
+			Handler:           rateServer.httpHandler(),
+			ReadHeaderTimeout: 5 * time.Second,
+			TLSConfig: &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				MinVersion:   tls.VersionTLS12,
+			},
+		}
+		log.Infof("HTTP gateway listening on %s", httpListener.Addr())
+		go func() {
+			if err := httpServer.ServeTLS(httpListener, "", ""); err != nil && err != http.ErrServerClosed {
+				log.Errorf("HTTP gateway error: %v", err)
+			}
+		}()
+	}
+
 	printUpdate := func(token string, pair exchanges.CurrencyPair) {
 		msg := fmt.Sprintf("%s: Update received from %s", pair, token)
 		if !xcBot.IsFailed() {
@@ -111,17 +142,6 @@ func main() {
 		log.Infof(msg)
 	}
 
-	sendUpdate := func(update *dcrrates.ExchangeRateUpdate) {
-		rateServer.clientLock.RLock()
-		for _, client := range rateServer.clients {
-			err := client.SendExchangeUpdate(update)
-			if err != nil {
-				log.Warnf("send error: %v", err)
-			}
-		}
-		rateServer.clientLock.RUnlock()
-	}
-
 	// Start the main loop in a goroutine, shutting down the grpcServer when done.
 	go func() {
 	out:
@@ -131,13 +151,19 @@ func main() {
 				break out
 			case update := <-xcSignals.Exchange:
 				printUpdate(update.Token, update.CurrencyPair)
-				sendUpdate(makeExchangeRateUpdate(update))
+				rateServer.broadcast(&rateUpdate{
+					Token:        update.Token,
+					CurrencyPair: update.CurrencyPair,
+					State:        update.State,
+					Received:     time.Now().Unix(),
+				})
 			case update := <-xcSignals.Index:
 				printUpdate(update.Token, update.CurrencyPair)
-				sendUpdate(&dcrrates.ExchangeRateUpdate{
+				rateServer.broadcast(&rateUpdate{
 					Token:        update.Token,
-					CurrencyPair: string(update.CurrencyPair),
+					CurrencyPair: update.CurrencyPair,
 					Indices:      update.Indices,
+					Received:     time.Now().Unix(),
 				})
 			case <-xcSignals.Quit:
 				log.Infof("ExchangeBot Quit signal received.")
@@ -145,6 +171,9 @@ func main() {
 			}
 		}
 		shutdown()
+		if httpServer != nil {
+			httpServer.Close()
+		}
 		grpcServer.Stop()
 	}()
 
@@ -14,30 +14,30 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/certgen"
-	credentials "google.golang.org/grpc/credentials"
 )
 
 // openRPCKeyPair creates or loads the RPC TLS keypair specified by the
-// application config.
-func openRPCKeyPair(cfg *config) (credentials.TransportCredentials, error) {
+// application config. The keypair is used by both the gRPC server and the
+// REST/JSON gateway.
+func openRPCKeyPair(cfg *config) (tls.Certificate, error) {
 	// Generate a new keypair when the key is missing.
 	_, e := os.Stat(cfg.KeyPath)
 	keyExists := !os.IsNotExist(e)
 	if !keyExists {
 		cert, err := generateRPCKeyPair(cfg.CertificatePath, cfg.KeyPath, cfg.AltDNSNames, certWriter{})
 		if err != nil {
-			return nil, fmt.Errorf("Unable to generate TLS Certificate: %v", err)
+			return tls.Certificate{}, fmt.Errorf("Unable to generate TLS Certificate: %v", err)
 		}
 
 		// The certificate generated by generateRPCKeyPair has a nil Leaf. The x509
 		// certificate must be parsed from the raw bytes to access DNSNames and
 		// IPAddresses.
 		if len(cert.Certificate) == 0 {
-			return nil, fmt.Errorf("No raw certificate data found")
+			return tls.Certificate{}, fmt.Errorf("No raw certificate data found")
 		}
 		x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
 		if err != nil {
-			return nil, fmt.Errorf("Could not parse x509 certificate: %v", err)
+			return tls.Certificate{}, fmt.Errorf("Could not parse x509 certificate: %v", err)
 		}
 		hosts := x509Cert.DNSNames
 		for _, ip := range x509Cert.IPAddresses {
@@ -46,14 +46,10 @@ func openRPCKeyPair(cfg *config) (credentials.TransportCredentials, error) {
 		if len(hosts) > 0 {
 			log.Infof("TLS certificate created for hosts %s", strings.Join(hosts, ", "))
 		}
-		return credentials.NewServerTLSFromCert(&cert), nil
+		return cert, nil
 	}
 
-	cert, err := tls.LoadX509KeyPair(cfg.CertificatePath, cfg.KeyPath)
-	if err != nil {
-		return nil, err
-	}
-	return credentials.NewServerTLSFromCert(&cert), nil
+	return tls.LoadX509KeyPair(cfg.CertificatePath, cfg.KeyPath)
 }
 
 // CertWriter is an interface that wraps either os.WriteFile or a stub for
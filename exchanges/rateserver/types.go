@@ -18,16 +18,30 @@ type StreamID uint
 
 var streamCounter StreamID
 
-// RateServer manages the data sources and client subscriptions.
+// clientBuffer is the number of updates that can be waiting to be sent to a
+// gRPC client before the client is dropped.
+const clientBuffer = 64
+
+// RateServer manages the data sources and client subscriptions. The gRPC
+// clients and the HTTP streams are protected by the clientLock.
 type RateServer struct {
 	index      string
-	xcBot      *exchanges.ExchangeBot
+	xcBot      ExchangeBot
 	clientLock *sync.RWMutex
-	clients    map[StreamID]RateClient
+	clients    map[StreamID]*subscriber
+	streams    map[StreamID]chan *rateUpdate
+	history    *updateHistory
 
 	dcrrates.UnimplementedDCRRatesServer
 }
 
+// ExchangeBot is the part of the *exchanges.ExchangeBot used by the
+// RateServer, an interface to enable testing the server via stubs.
+type ExchangeBot interface {
+	State() *exchanges.ExchangeBotState
+	Indices(token string) map[exchanges.CurrencyPair]exchanges.FiatIndices
+}
+
 // RateClient is an interface for rateClient to enable testing the server via
 // stubs.
 type RateClient interface {
@@ -35,41 +49,79 @@ type RateClient interface {
 	Stream() GRPCStream
 }
 
-// NewRateServer is a constructor for a RateServer.
-func NewRateServer(index string, xcBot *exchanges.ExchangeBot) *RateServer {
+// subscriber is a registered gRPC client and its queue of updates. The queue
+// is drained by the client's own goroutine, so that a slow client does not
+// hold up broadcast.
+type subscriber struct {
+	client  RateClient
+	updates chan *dcrrates.ExchangeRateUpdate
+}
+
+// NewRateServer is a constructor for a RateServer. Up to historySize recent
+// updates are kept for replay to reconnecting clients.
+func NewRateServer(index string, xcBot ExchangeBot, historySize int) *RateServer {
 	return &RateServer{
 		index:      index,
 		clientLock: new(sync.RWMutex),
-		clients:    make(map[StreamID]RateClient),
+		clients:    make(map[StreamID]*subscriber),
+		streams:    make(map[StreamID]chan *rateUpdate),
+		history:    newUpdateHistory(historySize),
 		xcBot:      xcBot,
 	}
 }
 
+// broadcast records the update, and queues it for the subscribed gRPC clients
+// and the HTTP streams. A client or stream that is not keeping up is closed,
+// since a client missing updates would have the wrong state.
+func (server *RateServer) broadcast(u *rateUpdate) {
+	server.history.add(u)
+	update := u.proto()
+	var laggingClients, laggingStreams []StreamID
+	server.clientLock.RLock()
+	for sid, sub := range server.clients {
+		select {
+		case sub.updates <- update:
+		default:
+			laggingClients = append(laggingClients, sid)
+		}
+	}
+	for sid, ch := range server.streams {
+		select {
+		case ch <- u:
+		default:
+			laggingStreams = append(laggingStreams, sid)
+		}
+	}
+	server.clientLock.RUnlock()
+
+	if len(laggingClients) == 0 && len(laggingStreams) == 0 {
+		return
+	}
+	server.clientLock.Lock()
+	defer server.clientLock.Unlock()
+	for _, sid := range laggingClients {
+		// The client may have disconnected in the meantime.
+		if sub, found := server.clients[sid]; found {
+			log.Warnf("gRPC client %d is not keeping up. Closing", sid)
+			close(sub.updates)
+			delete(server.clients, sid)
+		}
+	}
+	for _, sid := range laggingStreams {
+		if ch, found := server.streams[sid]; found {
+			log.Warnf("HTTP stream %d is not keeping up. Closing", sid)
+			close(ch)
+			delete(server.streams, sid)
+		}
+	}
+}
+
 // GRPCStream wraps the grpc.ClientStream.
 type GRPCStream interface {
 	Send(*dcrrates.ExchangeRateUpdate) error
 	Context() context.Context
 }
 
-// sendStateList is a helper for parsing the ExchangeBotState when a new client
-// subscription is received.
-func sendStateList(client RateClient, states map[string]map[exchanges.CurrencyPair]*exchanges.ExchangeState) (err error) {
-	for token, xcStates := range states {
-		for pair, state := range xcStates {
-			err = client.SendExchangeUpdate(makeExchangeRateUpdate(&exchanges.ExchangeUpdate{
-				Token:        token,
-				CurrencyPair: pair,
-				State:        state,
-			}))
-			if err != nil {
-				log.Errorf("SendExchangeUpdate error for %s: %v", token, err)
-				return
-			}
-		}
-	}
-	return
-}
-
 // SubscribeExchanges is a gRPC method defined in dcrrates/dcrrates.proto. It
 // satisfies the DCRRatesServer interface. The gRPC server will call this method
 // when a client subscription is received.
@@ -77,72 +129,85 @@ func (server *RateServer) SubscribeExchanges(hello *dcrrates.ExchangeSubscriptio
 	return server.ReallySubscribeExchanges(hello, stream)
 }
 
-// ReallySubscribeExchanges stores the client and their exchange subscriptions.
-// It waits for the client contexts Done channel to close and deletes the client
-// from the RateServer.clients map.
+// ReallySubscribeExchanges stores the client and their exchange subscriptions,
+// then sends the client its updates until the client's context is done, a send
+// fails, or the client is dropped by broadcast for not keeping up. The client
+// is then deleted from the RateServer.clients map.
 func (server *RateServer) ReallySubscribeExchanges(hello *dcrrates.ExchangeSubscription, stream GRPCStream) (err error) {
 	// For now, require the ExchangeBot clients to have the same base currency.
 	// ToDo: Allow any index.
 	if hello.Index != server.index {
 		return fmt.Errorf("Exchange subscription has wrong index. Given: %s, Required: %s", hello.Index, server.index)
 	}
-	// Save the client for use in the main loop.
-	client, sid := server.addClient(stream, hello)
-
 	// Get the address for an Infof. Seems like peerInfo is always found, but
 	// am checking anyway.
-	peerInfo, peerFound := grpcPeer.FromContext(client.Stream().Context())
+	peerInfo, peerFound := grpcPeer.FromContext(stream.Context())
 	var clientAddr string
 	if peerFound {
 		clientAddr = peerInfo.Addr.String()
 		log.Infof("Client has connected from %s", clientAddr)
 	}
 
-	state := server.xcBot.State()
-	if state != nil {
-		// Send Decred exchanges.
-		err = sendStateList(client, state.DCRExchanges)
-		if err != nil {
+	// Save the client for use in the main loop, and get the updates it starts
+	// with.
+	sub, sid, updates := server.addClient(stream, hello)
+	defer server.deleteClient(sid)
+
+	for _, u := range updates {
+		if err = sub.client.SendExchangeUpdate(u.proto()); err != nil {
+			log.Errorf("Error sending updates to client at %s: %v", clientAddr, err)
 			return err
 		}
-		// Send Bitcoin-fiat indices.
-		for token := range state.FiatIndices {
-			currencyIndexes := server.xcBot.Indices(token)
-			for currencyPair, indices := range currencyIndexes {
-				err = client.SendExchangeUpdate(&dcrrates.ExchangeRateUpdate{
-					Token:        token,
-					Indices:      indices,
-					CurrencyPair: string(currencyPair),
-				})
-				if err != nil {
-					log.Errorf("Error encountered while sending fiat indices to client at %s: %v", clientAddr, err)
-					// Assuming the Done channel will be closed on error, no further iteration
-					// is necessary.
-					break
-				}
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case update, ok := <-sub.updates:
+			if !ok {
+				return fmt.Errorf("client is not keeping up with the updates")
+			}
+			if err = sub.client.SendExchangeUpdate(update); err != nil {
+				log.Errorf("Error sending update to client at %s: %v", clientAddr, err)
+				return err
 			}
+		case <-ctx.Done():
+			log.Infof("Client at %s has disconnected", clientAddr)
+			return nil
 		}
 	}
-
-	// Keep stream alive.
-	<-client.Stream().Context().Done()
-
-	server.deleteClient(sid)
-	log.Infof("Client at %s has disconnected", clientAddr)
-	return
 }
 
-// addClient adds a client to the map and advance the streamCounter.
-func (server *RateServer) addClient(stream GRPCStream, hello *dcrrates.ExchangeSubscription) (RateClient, StreamID) {
+// addClient adds a client to the map and advances the streamCounter, and gets
+// the updates that the client starts with. A reconnecting client gets just the
+// updates it missed, if they are all in the history, otherwise the current
+// state. Like those of addStream, the updates are collected under the
+// clientLock, so that none are missed before the client is registered. The
+// caller sends them before any queued by broadcast.
+func (server *RateServer) addClient(stream GRPCStream, hello *dcrrates.ExchangeSubscription) (*subscriber, StreamID, []*rateUpdate) {
 	server.clientLock.Lock()
 	defer server.clientLock.Unlock()
-	client := NewRateClient(stream, hello.GetExchanges())
+	since := hello.GetSince()
+	updates, ok := server.history.since(since)
+	if since > 0 && ok {
+		log.Infof("Replaying %d updates since %d", len(updates), since)
+	} else {
+		if since > 0 {
+			log.Infof("Update history does not reach back to %d. Sending the full state", since)
+		}
+		updates = server.stateUpdates()
+	}
+	sub := &subscriber{
+		client:  NewRateClient(stream, hello.GetExchanges()),
+		updates: make(chan *dcrrates.ExchangeRateUpdate, clientBuffer),
+	}
 	streamCounter++
-	server.clients[streamCounter] = client
-	return client, streamCounter
+	server.clients[streamCounter] = sub
+	return sub, streamCounter, updates
 }
 
-// deleteClient deletes the client from the map.
+// deleteClient deletes the client from the map, unless it has already been
+// dropped by broadcast.
 func (server *RateServer) deleteClient(sid StreamID) {
 	server.clientLock.Lock()
 	defer server.clientLock.Unlock()
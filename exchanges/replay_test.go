@@ -0,0 +1,922 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"github.com/gorilla/websocket"
+)
+
+// replayFixture is a recording of an exchange's API, loaded from
+// testdata/replay/{token}.json.
+type replayFixture struct {
+	// REST maps the URLs of REST requests to the recorded response bodies.
+	REST map[string]json.RawMessage `json:"rest"`
+	// Results maps the routes of DEX requests to the recorded results. The
+	// results of market requests are keyed by route and the market's asset
+	// IDs, and the bin size for candles, e.g. orderbook/42/0 and
+	// candles/42/0/24h.
+	Results map[string]json.RawMessage `json:"results"`
+	// Stream is the recorded websocket messages, which are sent in order after
+	// the order book subscription. The DEX only sends the messages for the
+	// subscribed market.
+	Stream []json.RawMessage `json:"stream"`
+}
+
+func loadReplayFixture(t *testing.T, token string) *replayFixture {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "replay", token+".json"))
+	if err != nil {
+		t.Fatalf("error reading %s fixture: %v", token, err)
+	}
+	fixture := new(replayFixture)
+	if err = json.Unmarshal(b, fixture); err != nil {
+		t.Fatalf("error decoding %s fixture: %v", token, err)
+	}
+	return fixture
+}
+
+// replayServer is a fake exchange server that replays recorded fixtures. REST
+// requests from a client with the server's transport are answered with the
+// response recorded for the original URL. The Poloniex websocket replays the
+// recorded stream after the order book subscription. The DEX websocket, which
+// is served over TLS, answers requests with the recorded results and replays
+// the recorded stream after the order book subscription.
+type replayServer struct {
+	t        *testing.T
+	fixtures map[string]*replayFixture
+	rest     map[string]json.RawMessage
+	http     *httptest.Server
+	tls      *httptest.Server
+
+	mtx   sync.Mutex
+	conns []*websocket.Conn
+}
+
+// newReplayServer starts a replayServer for the fixtures of the exchanges with
+// the tokens. The Poloniex websocket URL is pointed at the server until the
+// test is done.
+func newReplayServer(t *testing.T, tokens ...string) *replayServer {
+	s := &replayServer{
+		t:        t,
+		fixtures: make(map[string]*replayFixture, len(tokens)),
+		rest:     make(map[string]json.RawMessage),
+	}
+	for _, token := range tokens {
+		fixture := loadReplayFixture(t, token)
+		s.fixtures[token] = fixture
+		for u, body := range fixture.REST {
+			s.rest[u] = body
+		}
+	}
+	s.http = httptest.NewServer(s)
+	s.tls = httptest.NewTLSServer(s)
+
+	poloniexWs := PoloniexURLs.Websocket
+	PoloniexURLs.Websocket = "ws://" + s.http.Listener.Addr().String() + "/poloniex"
+
+	t.Cleanup(func() {
+		PoloniexURLs.Websocket = poloniexWs
+		s.mtx.Lock()
+		for _, conn := range s.conns {
+			conn.Close()
+		}
+		s.mtx.Unlock()
+		s.http.Close()
+		s.tls.Close()
+	})
+	return s
+}
+
+// ServeHTTP satisfies http.Handler.
+func (s *replayServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		switch r.URL.Path {
+		case "/poloniex":
+			s.servePoloniex(w, r)
+		case "/ws":
+			s.serveDEX(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+	u := "https://" + r.Host + r.URL.RequestURI()
+	body, found := s.rest[u]
+	if !found {
+		http.Error(w, "no recorded response for "+u, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// client is an HTTP client for the exchanges' APIs that sends every request
+// to the server.
+func (s *replayServer) client() *http.Client {
+	return &http.Client{Transport: &replayTransport{addr: s.http.Listener.Addr().String()}}
+}
+
+// dexConfig is the configuration for a DEX at the server.
+func (s *replayServer) dexConfig() *DEXConfig {
+	return &DEXConfig{
+		Token:    DexDotDecred,
+		Host:     s.tls.Listener.Addr().String(),
+		Cert:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.tls.Certificate().Raw}),
+		CertHost: "example.com", // in the httptest certificate
+	}
+}
+
+func (s *replayServer) upgrade(w http.ResponseWriter, r *http.Request) *websocket.Conn {
+	conn, err := new(websocket.Upgrader).Upgrade(w, r, nil)
+	if err != nil {
+		s.t.Errorf("websocket upgrade error: %v", err)
+		return nil
+	}
+	s.mtx.Lock()
+	s.conns = append(s.conns, conn)
+	s.mtx.Unlock()
+	return conn
+}
+
+// stream sends the recorded websocket messages of the exchange.
+func (s *replayServer) stream(conn *websocket.Conn, token string) error {
+	for _, msg := range s.fixtures[token].Stream {
+		if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *replayServer) servePoloniex(w http.ResponseWriter, r *http.Request) {
+	conn := s.upgrade(w, r)
+	if conn == nil {
+		return
+	}
+	sub := new(poloniexWsSubscription)
+	if err := conn.ReadJSON(sub); err != nil {
+		return
+	}
+	if *sub != poloniexOrderbookSubscription {
+		s.t.Errorf("unexpected poloniex subscription %+v", sub)
+		return
+	}
+	if err := s.stream(conn, Poloniex); err != nil {
+		return
+	}
+	// Hold the connection until the client disconnects.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// streamDEXMarket sends the recorded DEX notifications for the market of the
+// order book.
+func (s *replayServer) streamDEXMarket(conn *websocket.Conn, book json.RawMessage) error {
+	var ob, note struct {
+		MarketID string `json:"marketid"`
+	}
+	if err := json.Unmarshal(book, &ob); err != nil {
+		return err
+	}
+	for _, b := range s.fixtures[DexDotDecred].Stream {
+		msg, err := msgjson.DecodeMessage(b)
+		if err != nil {
+			return err
+		}
+		if err = msg.Unmarshal(&note); err != nil || note.MarketID != ob.MarketID {
+			continue
+		}
+		if err = conn.WriteMessage(websocket.TextMessage, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *replayServer) serveDEX(w http.ResponseWriter, r *http.Request) {
+	conn := s.upgrade(w, r)
+	if conn == nil {
+		return
+	}
+	fixture := s.fixtures[DexDotDecred]
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg, err := msgjson.DecodeMessage(b)
+		if err != nil {
+			s.t.Errorf("error decoding DEX request: %v", err)
+			return
+		}
+		key := msg.Route
+		switch msg.Route {
+		case msgjson.CandlesRoute:
+			req := new(msgjson.CandlesRequest)
+			if err = msg.Unmarshal(req); err != nil {
+				s.t.Errorf("error decoding DEX candles request: %v", err)
+				return
+			}
+			key += fmt.Sprintf("/%d/%d/%s", req.BaseID, req.QuoteID, req.BinSize)
+		case msgjson.OrderBookRoute:
+			sub := new(msgjson.OrderBookSubscription)
+			if err = msg.Unmarshal(sub); err != nil {
+				s.t.Errorf("error decoding DEX order book subscription: %v", err)
+				return
+			}
+			key += fmt.Sprintf("/%d/%d", sub.Base, sub.Quote)
+		}
+		result, found := fixture.Results[key]
+		if !found {
+			s.t.Errorf("no recorded DEX result for %s", key)
+			return
+		}
+		resp, _ := msgjson.NewResponse(msg.ID, result, nil)
+		if err = conn.WriteJSON(resp); err != nil {
+			return
+		}
+		if msg.Route == msgjson.OrderBookRoute {
+			if err = s.streamDEXMarket(conn, result); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// replayTransport sends requests to the replayServer at addr. The original
+// host is kept as the Host header to look up the recorded response.
+type replayTransport struct {
+	addr string
+}
+
+// RoundTrip satisfies http.RoundTripper.
+func (tr *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = "http"
+	r.URL.Host = tr.addr
+	r.Host = req.URL.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newReplayChannels(t *testing.T) *BotChannels {
+	channels := &BotChannels{
+		index:    make(chan *IndexUpdate, 16),
+		exchange: make(chan *ExchangeUpdate, 16),
+		done:     make(chan struct{}),
+	}
+	t.Cleanup(func() { close(channels.done) })
+	return channels
+}
+
+// drainUpdates returns the exchange updates already sent on the channel.
+func drainUpdates(ch chan *ExchangeUpdate) []*ExchangeUpdate {
+	var updates []*ExchangeUpdate
+	for {
+		select {
+		case update := <-ch:
+			updates = append(updates, update)
+		default:
+			return updates
+		}
+	}
+}
+
+func waitFor(t *testing.T, what string, f func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+type replayExpectation struct {
+	price, volume    float64
+	asks, bids       int
+	bestAsk, bestBid DepthPoint
+	sticks           map[candlestickKey]int
+}
+
+func checkReplayState(t *testing.T, name string, state *ExchangeState, want *replayExpectation) {
+	t.Helper()
+	if !closeTo(state.Price, want.price) || !closeTo(state.Volume, want.volume) {
+		t.Errorf("%s: wrong price and volume %f, %f. expected %f, %f", name,
+			state.Price, state.Volume, want.price, want.volume)
+	}
+	depth := state.Depth
+	if depth == nil {
+		t.Fatalf("%s: no depth data", name)
+	}
+	if len(depth.Asks) != want.asks || len(depth.Bids) != want.bids {
+		t.Fatalf("%s: wrong depth data lengths (%d, %d). expected (%d, %d)", name,
+			len(depth.Asks), len(depth.Bids), want.asks, want.bids)
+	}
+	if !closeTo(depth.Asks[0].Price, want.bestAsk.Price) || !closeTo(depth.Asks[0].Quantity, want.bestAsk.Quantity) {
+		t.Errorf("%s: wrong best ask %+v. expected %+v", name, depth.Asks[0], want.bestAsk)
+	}
+	if !closeTo(depth.Bids[0].Price, want.bestBid.Price) || !closeTo(depth.Bids[0].Quantity, want.bestBid.Quantity) {
+		t.Errorf("%s: wrong best bid %+v. expected %+v", name, depth.Bids[0], want.bestBid)
+	}
+	if len(state.Candlesticks) != len(want.sticks) {
+		t.Errorf("%s: wrong number of candlestick bins %d. expected %d", name, len(state.Candlesticks), len(want.sticks))
+	}
+	for bin, n := range want.sticks {
+		sticks := state.Candlesticks[bin]
+		if len(sticks) != n {
+			t.Errorf("%s: wrong number of %s candlesticks %d. expected %d", name, bin, len(sticks), n)
+			continue
+		}
+		for i := 1; i < len(sticks); i++ {
+			if !sticks[i].Start.After(sticks[i-1].Start) {
+				t.Errorf("%s: %s candlesticks out of order", name, bin)
+			}
+		}
+	}
+}
+
+func TestReplayRefresh(t *testing.T) {
+	s := newReplayServer(t, Binance, Huobi, Mexc)
+
+	tests := []struct {
+		token string
+		new   func(*http.Client, *BotChannels) (Exchange, error)
+		pair  CurrencyPair
+		want  *replayExpectation
+	}{{
+		token: Binance,
+		new:   NewBinance,
+		pair:  CurrencyPairDCRBTC,
+		want: &replayExpectation{
+			price:   0.00025,
+			volume:  1200,
+			asks:    3,
+			bids:    2,
+			bestAsk: DepthPoint{Quantity: 8, Price: 0.0002501},
+			bestBid: DepthPoint{Quantity: 10, Price: 0.0002499},
+			sticks:  map[candlestickKey]int{hourKey: 2, dayKey: 1, monthKey: 1},
+		},
+	}, {
+		token: Binance,
+		new:   NewBinance,
+		pair:  CurrencyPairDCRUSDT,
+		want: &replayExpectation{
+			price:   15.5,
+			volume:  2800,
+			asks:    1,
+			bids:    1,
+			bestAsk: DepthPoint{Quantity: 12, Price: 15.51},
+			bestBid: DepthPoint{Quantity: 20, Price: 15.49},
+			sticks:  map[candlestickKey]int{hourKey: 1, dayKey: 1, monthKey: 1},
+		},
+	}, {
+		token: Huobi,
+		new:   NewHuobi,
+		pair:  CurrencyPairDCRBTC,
+		want: &replayExpectation{
+			price:   0.00026,
+			volume:  400,
+			asks:    3,
+			bids:    2,
+			bestAsk: DepthPoint{Quantity: 2, Price: 0.000261},
+			bestBid: DepthPoint{Quantity: 5, Price: 0.000259},
+			// The monthly candlesticks response has an error status.
+			sticks: map[candlestickKey]int{hourKey: 2, dayKey: 1},
+		},
+	}, {
+		token: Mexc,
+		new:   NewMexc,
+		pair:  CurrencyPairDCRUSDT,
+		want: &replayExpectation{
+			price:   15.2,
+			volume:  1000,
+			asks:    2,
+			bids:    3,
+			bestAsk: DepthPoint{Quantity: 7.1, Price: 15.22},
+			bestBid: DepthPoint{Quantity: 4.5, Price: 15.19},
+			sticks:  map[candlestickKey]int{hourKey: 2, dayKey: 1, monthKey: 1},
+		},
+	}}
+
+	lastHour := time.Unix(1709290800, 0)
+	for _, tt := range tests {
+		name := tt.token + " " + string(tt.pair)
+		channels := newReplayChannels(t)
+		xc, err := tt.new(s.client(), channels)
+		if err != nil {
+			t.Fatalf("%s: constructor error: %v", name, err)
+		}
+		xc.Refresh()
+		if xc.IsFailed() {
+			t.Fatalf("%s: refresh failed", name)
+		}
+		var state *ExchangeState
+		for _, update := range drainUpdates(channels.exchange) {
+			if update.Token != tt.token {
+				t.Errorf("%s: update from %s", name, update.Token)
+			}
+			if update.CurrencyPair == tt.pair {
+				state = update.State
+			}
+		}
+		if state == nil {
+			t.Fatalf("%s: no update", name)
+		}
+		checkReplayState(t, name, state, tt.want)
+		if !state.Candlesticks[hourKey].time().Equal(lastHour) {
+			t.Errorf("%s: wrong last hourly candlestick start %v", name, state.Candlesticks[hourKey].time())
+		}
+	}
+
+	// An exchange fails without a price.
+	channels := newReplayChannels(t)
+	xc, _ := NewPoloniex(s.client(), channels)
+	xc.Refresh()
+	if !xc.IsFailed() {
+		t.Errorf("poloniex refresh without a recorded price did not fail")
+	}
+	if updates := drainUpdates(channels.exchange); len(updates) != 0 {
+		t.Errorf("unexpected updates from failed refresh: %d", len(updates))
+	}
+}
+
+// poloniexSeq is the sequence ID of the last order book update processed.
+func (poloniex *PoloniexExchange) poloniexSeq() int64 {
+	poloniex.orderMtx.RLock()
+	defer poloniex.orderMtx.RUnlock()
+	return poloniex.orderSeq
+}
+
+func TestReplayPoloniex(t *testing.T) {
+	enableTestLog()
+	s := newReplayServer(t, Poloniex)
+	channels := newReplayChannels(t)
+	xc, err := NewPoloniex(s.client(), channels)
+	if err != nil {
+		t.Fatalf("NewPoloniex error: %v", err)
+	}
+	poloniex := xc.(*PoloniexExchange)
+
+	// The first refresh connects the websocket, which sends the order book.
+	poloniex.Refresh()
+	waitFor(t, "poloniex order book updates", func() bool {
+		return poloniex.poloniexSeq() == 1002
+	})
+	if !poloniex.wsListening() {
+		t.Fatalf("poloniex websocket not listening")
+	}
+
+	// The next refresh uses the websocket order book.
+	poloniex.Refresh()
+	updates := drainUpdates(channels.exchange)
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updates))
+	}
+	checkReplayState(t, "poloniex", updates[1].State, &replayExpectation{
+		price:  0.000255,
+		volume: 200,
+		// The initial book has 3 asks and 2 bids. An update removes an ask and
+		// adds a bid.
+		asks:    2,
+		bids:    3,
+		bestAsk: DepthPoint{Quantity: 10.5, Price: 0.000256},
+		bestBid: DepthPoint{Quantity: 7, Price: 0.000255},
+		sticks:  map[candlestickKey]int{halfHourKey: 2, dayKey: 1},
+	})
+	if change := updates[1].State.Change; !closeTo(change, 0.000255-0.000255/1.02) {
+		t.Errorf("wrong poloniex change %f", change)
+	}
+}
+
+// dexSeq is the sequence number of the last order book note processed for
+// the market.
+func (dcr *DecredDEX) dexSeq(mktID string) uint64 {
+	dcr.orderMtx.RLock()
+	defer dcr.orderMtx.RUnlock()
+	if mkt := dcr.markets[mktID]; mkt != nil {
+		return mkt.seq
+	}
+	return 0
+}
+
+// dexUpdates receives n exchange updates, keyed by market.
+func dexUpdates(t *testing.T, channels *BotChannels, n int) map[CurrencyPair]*ExchangeState {
+	t.Helper()
+	states := make(map[CurrencyPair]*ExchangeState, n)
+	for len(states) < n {
+		select {
+		case update := <-channels.exchange:
+			states[update.CurrencyPair] = update.State
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d of %d dcrdex updates", len(states), n)
+		}
+	}
+	return states
+}
+
+func TestReplayDecredDEX(t *testing.T) {
+	enableTestLog()
+	s := newReplayServer(t, DexDotDecred)
+	channels := newReplayChannels(t)
+	xc, _ := NewDecredDEXConstructor(s.dexConfig())(s.client(), channels)
+	dcr := xc.(*DecredDEX)
+
+	// The first refresh connects the websocket, and the order book of each
+	// DCR market is sent with the subscription response.
+	dcr.Refresh()
+	states := dexUpdates(t, channels, 2)
+	// The mid-gap prices of the books.
+	checkReplayState(t, "dcrdex btc book", states[CurrencyPairDCRBTC], &replayExpectation{
+		price:   0.00025,
+		asks:    2,
+		bids:    2,
+		bestAsk: DepthPoint{Quantity: 1.5, Price: 0.000251},
+		bestBid: DepthPoint{Quantity: 2, Price: 0.000249},
+	})
+	checkReplayState(t, "dcrdex eth book", states["DCR-ETH"], &replayExpectation{
+		price:   0.005,
+		asks:    1,
+		bids:    1,
+		bestAsk: DepthPoint{Quantity: 2, Price: 0.00501},
+		bestBid: DepthPoint{Quantity: 1, Price: 0.00499},
+	})
+	if len(dcr.markets) != 2 {
+		t.Fatalf("expected 2 DCR markets, got %d", len(dcr.markets))
+	}
+
+	waitFor(t, "dcrdex order book notes", func() bool {
+		return dcr.dexSeq("dcr_btc") == 14
+	})
+	waitFor(t, "dcrdex candles", func() bool {
+		return len(dcr.candles(dcr.markets["dcr_btc"])) == 2 && len(dcr.candles(dcr.markets["dcr_eth"])) == 2
+	})
+	waitFor(t, "dcrdex epoch report", func() bool {
+		dcr.orderMtx.RLock()
+		defer dcr.orderMtx.RUnlock()
+		return len(dcr.markets["dcr_eth"].trades) == 2
+	})
+
+	// The next refresh uses the websocket order books and candles. The notes
+	// book a bid, update the best bid, and unbook an ask on the BTC market,
+	// and the epoch report sets the ETH market's rate and trades.
+	dcr.Refresh()
+	updates := drainUpdates(channels.exchange)
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updates))
+	}
+	checkReplayState(t, "dcrdex btc", updates[0].State, &replayExpectation{
+		price:   0.00025,
+		asks:    1,
+		bids:    3,
+		bestAsk: DepthPoint{Quantity: 1.5, Price: 0.000251},
+		bestBid: DepthPoint{Quantity: 1, Price: 0.000249},
+		sticks:  map[candlestickKey]int{hourKey: 2, dayKey: 1},
+	})
+	if updates[0].CurrencyPair != CurrencyPairDCRBTC || len(updates[0].State.Trades) != 0 {
+		t.Errorf("wrong btc market update %s with %d trades", updates[0].CurrencyPair, len(updates[0].State.Trades))
+	}
+
+	eth := updates[1]
+	if eth.CurrencyPair != "DCR-ETH" {
+		t.Fatalf("wrong eth market pair %s", eth.CurrencyPair)
+	}
+	checkReplayState(t, "dcrdex eth", eth.State, &replayExpectation{
+		price:   0.00501,
+		asks:    1,
+		bids:    1,
+		bestAsk: DepthPoint{Quantity: 2, Price: 0.00501},
+		bestBid: DepthPoint{Quantity: 1, Price: 0.00499},
+		sticks:  map[candlestickKey]int{hourKey: 1, dayKey: 1},
+	})
+	// The epoch's candle is added to the last candle in the caches.
+	if sticks := eth.State.Candlesticks[hourKey]; !closeTo(sticks[0].Close, 0.00501) {
+		t.Errorf("epoch candle not added to the eth candles: %+v", sticks[0])
+	}
+	// The rates are converted with the ETH conversion factor, and the most
+	// recent trade is first.
+	expectedTrades := []Trade{
+		{Price: 0.00501, Quantity: 0.2, MakerSell: true, Stamp: 1709251206},
+		{Price: 0.005, Quantity: 0.5, Stamp: 1709251206},
+	}
+	if len(eth.State.Trades) != len(expectedTrades) {
+		t.Fatalf("expected %d eth trades, got %d", len(expectedTrades), len(eth.State.Trades))
+	}
+	for i, trade := range eth.State.Trades {
+		want := expectedTrades[i]
+		if !closeTo(trade.Price, want.Price) || !closeTo(trade.Quantity, want.Quantity) ||
+			trade.MakerSell != want.MakerSell || trade.Stamp != want.Stamp {
+			t.Errorf("wrong eth trade %d %+v. expected %+v", i, trade, want)
+		}
+	}
+}
+
+func TestReplayDecredDEXSeqGap(t *testing.T) {
+	enableTestLog()
+	s := newReplayServer(t, DexDotDecred)
+	// Drop the second note from the stream.
+	stream := s.fixtures[DexDotDecred].Stream
+	s.fixtures[DexDotDecred].Stream = append(stream[:1:1], stream[2:]...)
+
+	channels := newReplayChannels(t)
+	xc, _ := NewDecredDEXConstructor(s.dexConfig())(s.client(), channels)
+	dcr := xc.(*DecredDEX)
+	dcr.Refresh()
+
+	waitFor(t, "dcrdex websocket failure", dcr.wsFailed)
+	if seq := dcr.dexSeq("dcr_btc"); seq != 11 {
+		t.Errorf("wrong sequence number after the gap %d. expected 11", seq)
+	}
+	if dcr.wsListening() {
+		t.Errorf("dcrdex websocket listening after a sequence gap")
+	}
+}
+
+// newReplayBot creates an ExchangeBot with the exchanges that have fixtures
+// on the replay server, refreshes them, and processes their updates as the
+// bot's Start loop would. The numbers of exchange and index updates are
+// returned.
+func newReplayBot(t *testing.T, s *replayServer) (bot *ExchangeBot, nExchange, nIndex int) {
+	t.Helper()
+	var disabled []string
+	for token := range Indices {
+		if s.fixtures[token] == nil {
+			disabled = append(disabled, token)
+		}
+	}
+	for token := range DcrExchanges {
+		if s.fixtures[token] == nil {
+			disabled = append(disabled, token)
+		}
+	}
+	bot, err := NewExchangeBot(&ExchangeBotConfig{
+		Disabled: disabled,
+		Index:    "USD",
+	})
+	if err != nil {
+		t.Fatalf("NewExchangeBot error: %v", err)
+	}
+	bot.client.Transport = s.client().Transport
+	t.Cleanup(func() {
+		for _, ch := range bot.quitChans {
+			close(ch)
+		}
+	})
+
+	for _, xc := range bot.Exchanges {
+		xc.Refresh()
+	}
+	if poloniex, ok := bot.Exchanges[Poloniex].(*PoloniexExchange); ok {
+		waitFor(t, "poloniex order book updates", func() bool {
+			return poloniex.poloniexSeq() == 1002
+		})
+		poloniex.Refresh()
+	}
+
+	for {
+		select {
+		case update := <-bot.exchangeChan:
+			if err = bot.updateExchange(update); err != nil {
+				t.Fatalf("updateExchange error: %v", err)
+			}
+			nExchange++
+		case update := <-bot.indexChan:
+			if err = bot.updateIndices(update); err != nil {
+				t.Fatalf("updateIndices error: %v", err)
+			}
+			nIndex++
+		default:
+			return bot, nExchange, nIndex
+		}
+	}
+}
+
+func TestReplayExchangeBot(t *testing.T) {
+	enableTestLog()
+	s := newReplayServer(t, Coinbase, Binance, Huobi, Mexc, Poloniex)
+	bot, nExchange, nIndex := newReplayBot(t, s)
+	// Two markets from binance, one each from huobi and mexc, and the
+	// websocket order book and refresh from poloniex.
+	if nExchange != 6 || nIndex != 2 {
+		t.Fatalf("wrong number of exchange and index updates (%d, %d). expected (6, 2)", nExchange, nIndex)
+	}
+
+	if bot.IsFailed() {
+		t.Fatalf("bot failed")
+	}
+	state := bot.State()
+	if len(state.DCRExchanges) != 4 {
+		t.Errorf("wrong number of exchanges %d. expected 4", len(state.DCRExchanges))
+	}
+	if state.BtcPrice != 60000 {
+		t.Errorf("wrong BTC price %f. expected 60000", state.BtcPrice)
+	}
+	// Each exchange's price is averaged by volume over its markets, and the
+	// exchanges' prices are averaged. At 60000 USD/BTC and 1 USD/USDT,
+	// binance: (15 * 1200 + 15.5 * 2800) / 4000 = 15.35, huobi: 15.6,
+	// mexc: 15.2, poloniex: 15.3.
+	if !closeTo(state.Price, 15.3625) {
+		t.Errorf("wrong price %f. expected 15.3625", state.Price)
+	}
+	if !closeTo(state.Volume, 5600) {
+		t.Errorf("wrong volume %f. expected 5600", state.Volume)
+	}
+	for token, w := range state.IndexWeights {
+		if !w.Included || !closeTo(w.Weight, 0.25) {
+			t.Errorf("%s: wrong index weight %+v", token, w)
+		}
+	}
+
+	// Huobi is more than 1.5% from the median, and is excluded.
+	bot.config.Methodology = IndexMethodology{Method: IndexMedian, MaxDeviation: 0.015}
+	bot.mtx.Lock()
+	err := bot.updateState()
+	bot.mtx.Unlock()
+	if err != nil {
+		t.Fatalf("updateState error: %v", err)
+	}
+	state = bot.State()
+	if !closeTo(state.Price, 15.3) {
+		t.Errorf("wrong median price %f. expected 15.3", state.Price)
+	}
+	if w := state.IndexWeights[Huobi]; w.Included || w.Reason != IndexExcludedOutlier {
+		t.Errorf("huobi not excluded as an outlier: %+v", w)
+	}
+	if !closeTo(state.Volume, 5200) {
+		t.Errorf("wrong volume %f. expected 5200", state.Volume)
+	}
+}
+
+func TestReplayDEXMarkets(t *testing.T) {
+	enableTestLog()
+	s := newReplayServer(t, Coinbase, Binance)
+	bot, _, _ := newReplayBot(t, s)
+
+	// The DEX is connected to the replay server instead of its host, so it's
+	// added to the bot after the others are refreshed.
+	s.fixtures[DexDotDecred] = loadReplayFixture(t, DexDotDecred)
+	channels := newReplayChannels(t)
+	xc, _ := NewDecredDEXConstructor(s.dexConfig())(s.client(), channels)
+	dcr := xc.(*DecredDEX)
+	bot.Exchanges[DexDotDecred] = dcr
+	bot.DcrExchanges[DexDotDecred] = dcr
+	dcr.Refresh()
+	dexUpdates(t, channels, 2)
+	waitFor(t, "dcrdex epoch report", func() bool {
+		dcr.orderMtx.RLock()
+		defer dcr.orderMtx.RUnlock()
+		return len(dcr.markets["dcr_eth"].trades) == 2
+	})
+	dcr.Refresh()
+	for _, update := range drainUpdates(channels.exchange) {
+		if err := bot.updateExchange(update); err != nil {
+			t.Fatalf("updateExchange error: %v", err)
+		}
+	}
+
+	// There is no ETH index, so the DCR-ETH market is left out of the DEX's
+	// price, and the DCR-BTC market has no volume in the last day.
+	state := bot.State()
+	if len(state.DCRExchanges[DexDotDecred]) != 2 {
+		t.Fatalf("wrong number of dcrdex markets %d. expected 2", len(state.DCRExchanges[DexDotDecred]))
+	}
+	if w := state.IndexWeights[DexDotDecred]; w.Included || w.Reason != IndexExcludedNoVolume {
+		t.Errorf("wrong dcrdex index weight %+v", w)
+	}
+	if w := state.IndexWeights[Binance]; !w.Included || w.Weight != 1 {
+		t.Errorf("wrong binance index weight %+v", w)
+	}
+
+	trades := state.RecentTrades()
+	if len(trades) != 2 {
+		t.Fatalf("got %d recent trades, expected 2", len(trades))
+	}
+	if trades[0].Token != DexDotDecred || trades[0].CurrencyPair != "DCR-ETH" || !trades[0].MakerSell {
+		t.Errorf("wrong recent trade %+v", trades[0])
+	}
+
+	b, err := bot.QuickTrades(DexDotDecred, "DCR-ETH")
+	if err != nil {
+		t.Fatalf("QuickTrades error: %v", err)
+	}
+	var resp tradesResponse
+	if err = json.Unmarshal(b, &resp); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if len(resp.Trades) != 2 || !closeTo(resp.Trades[1].Quantity, 0.5) {
+		t.Errorf("wrong trades %+v", resp.Trades)
+	}
+	if _, err = bot.QuickTrades(DexDotDecred, CurrencyPairDCRBTC); err == nil {
+		t.Errorf("no error for a market without trades")
+	}
+	if _, err = bot.QuickDepth(DexDotDecred, "DCR-ETH"); err != nil {
+		t.Errorf("QuickDepth error for the DCR-ETH market: %v", err)
+	}
+	if _, err = bot.QuickDepth(DexDotDecred, "BTC-ETH"); err == nil {
+		t.Errorf("no error for a non-DCR market")
+	}
+
+	// The DCR-ETH market can't be converted to the index currency, so it's
+	// not aggregated.
+	bot.mtx.Lock()
+	sources := bot.aggregateSources()
+	bot.mtx.Unlock()
+	for _, src := range sources {
+		if src.market == "DCR-ETH" {
+			t.Errorf("DCR-ETH market aggregated")
+		}
+	}
+}
+
+func TestReplayAggregateCharts(t *testing.T) {
+	s := newReplayServer(t, Coinbase, Binance, Huobi)
+	bot, _, _ := newReplayBot(t, s)
+
+	b, err := bot.AggregateSticks("1h")
+	if err != nil {
+		t.Fatalf("AggregateSticks error: %v", err)
+	}
+	var sticks aggregateStickResponse
+	if err = json.Unmarshal(b, &sticks); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if len(sticks.Sources[Binance]) != 2 || len(sticks.Sources[Huobi]) != 1 {
+		t.Errorf("wrong candlestick sources %v", sticks.Sources)
+	}
+	if len(sticks.Sticks) != 2 {
+		t.Fatalf("got %d candlesticks, expected 2", len(sticks.Sticks))
+	}
+	// The binance DCR-BTC and DCR-USDT, and huobi DCR-BTC candlesticks of
+	// the last hour are combined at 60000 USD/BTC.
+	stick := sticks.Sticks[1]
+	volume := 48 + 120 + 0.0052
+	closePrice := (48*0.00025*60000 + 120*15.5 + 0.0052*0.00026*60000) / volume
+	if !closeTo(stick.Volume, volume) || !closeTo(stick.Close, closePrice) {
+		t.Errorf("wrong combined candlestick %+v. expected volume %f, close %f", stick, volume, closePrice)
+	}
+	if b2, _ := bot.AggregateSticks("1h"); !bytes.Equal(b, b2) {
+		t.Errorf("aggregate candlesticks not cached")
+	}
+	if _, err = bot.AggregateSticks("1w"); err == nil {
+		t.Errorf("no error for an invalid bin")
+	}
+
+	b, err = bot.AggregateDepth()
+	if err != nil {
+		t.Fatalf("AggregateDepth error: %v", err)
+	}
+	var depth aggregateDepthResponse
+	if err = json.Unmarshal(b, &depth); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	// The recorded huobi order book is not fresh.
+	if len(depth.Sources) != 1 || len(depth.Sources[Binance]) != 2 {
+		t.Errorf("wrong depth sources %v", depth.Sources)
+	}
+	bids, asks := depth.Data.Bids, depth.Data.Asks
+	if len(bids) != 3 || len(asks) != 4 {
+		t.Fatalf("got %d bids and %d asks, expected 3 and 4", len(bids), len(asks))
+	}
+	if bids[0].Price != 15.49 || !closeTo(bids[1].Price, 0.0002499*60000) || asks[0].Quantity != 8 || asks[3].Price != 15.51 {
+		t.Errorf("wrong combined order book %+v", depth.Data)
+	}
+
+	// An index update invalidates the cached charts.
+	err = bot.updateIndices(&IndexUpdate{
+		Token:        Coinbase,
+		CurrencyPair: BTCIndex,
+		Indices:      FiatIndices{"USD": 50000},
+	})
+	if err != nil {
+		t.Fatalf("updateIndices error: %v", err)
+	}
+	b, _ = bot.AggregateDepth()
+	if err = json.Unmarshal(b, &depth); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if !closeTo(depth.Data.Bids[1].Price, 0.0002499*50000) {
+		t.Errorf("cached depth chart not updated: %+v", depth.Data.Bids[1])
+	}
+}
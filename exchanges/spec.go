@@ -0,0 +1,532 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExchangeSpec declares the REST API of a Decred exchange for a SpecExchange,
+// so that an exchange can be added or fixed without a code change. Values in
+// the API responses are located with paths. A path is a dot-separated list of
+// object keys and array indexes, e.g. "tick.close" or "0.4". The empty path is
+// the whole response. Numbers may be JSON numbers or numeric strings. Times may
+// be unix stamps, in seconds or milliseconds, or RFC 3339 strings.
+type ExchangeSpec struct {
+	// Token identifies the exchange. A spec with the token of a built-in
+	// exchange replaces it.
+	Token string `json:"token"`
+	// Headers are added to every request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Status, if set, is checked in every response.
+	Status *StatusSpec `json:"status,omitempty"`
+	// Markets are the exchange's DCR markets.
+	Markets map[CurrencyPair]*MarketSpec `json:"markets"`
+}
+
+// StatusSpec is a status value that successful responses must have.
+type StatusSpec struct {
+	Path string `json:"path"`
+	OK   string `json:"ok"`
+}
+
+// MarketSpec declares the endpoints of a market. In the endpoint URLs,
+// {symbol} is replaced with Symbol, and {interval} with the exchange's
+// candlestick interval.
+type MarketSpec struct {
+	Symbol       string           `json:"symbol"`
+	Price        *PriceSpec       `json:"price"`
+	Depth        *DepthSpec       `json:"depth,omitempty"`
+	Candlesticks *CandlestickSpec `json:"candlesticks,omitempty"`
+}
+
+// PriceSpec declares the price endpoint. At least one of the volumes must be
+// set, and the other is computed from the price. If Change is not set, the
+// change is computed from Open, if set.
+type PriceSpec struct {
+	URL        string `json:"url"`
+	Price      string `json:"price"`
+	Volume     string `json:"volume,omitempty"`     // in DCR
+	BaseVolume string `json:"baseVolume,omitempty"` // in the quote asset
+	Change     string `json:"change,omitempty"`
+	Open       string `json:"open,omitempty"`
+	Stamp      string `json:"stamp,omitempty"`
+}
+
+// DepthSpec declares the order book endpoint. Asks and Bids are the paths of
+// the arrays of points, and Price and Quantity are paths in a point.
+type DepthSpec struct {
+	URL      string `json:"url"`
+	Asks     string `json:"asks"`
+	Bids     string `json:"bids"`
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// CandlestickSpec declares the candlestick endpoint. Intervals maps the bin
+// sizes to the exchange's intervals. Sticks is the path of the array of
+// candlesticks, and the other paths are in a candlestick.
+type CandlestickSpec struct {
+	URL       string                    `json:"url"`
+	Intervals map[candlestickKey]string `json:"intervals"`
+	Sticks    string                    `json:"sticks"`
+	Start     string                    `json:"start"`
+	Open      string                    `json:"open"`
+	High      string                    `json:"high"`
+	Low       string                    `json:"low"`
+	Close     string                    `json:"close"`
+	Volume    string                    `json:"volume"`
+}
+
+// Validate checks that the spec has the required endpoints and paths.
+func (spec *ExchangeSpec) Validate() error {
+	if spec.Token == "" {
+		return fmt.Errorf("no token")
+	}
+	if IsIndex(spec.Token) {
+		return fmt.Errorf("%s is an index token", spec.Token)
+	}
+	if len(spec.Markets) == 0 {
+		return fmt.Errorf("%s: no markets", spec.Token)
+	}
+	if spec.Status != nil && spec.Status.Path == "" {
+		return fmt.Errorf("%s: no status path", spec.Token)
+	}
+	for mkt, m := range spec.Markets {
+		if err := m.validate(mkt); err != nil {
+			return fmt.Errorf("%s: %s: %w", spec.Token, mkt, err)
+		}
+	}
+	return nil
+}
+
+func (m *MarketSpec) validate(mkt CurrencyPair) error {
+	if m == nil {
+		return fmt.Errorf("no market spec")
+	}
+	if !mkt.IsValidDCRPair() {
+		return fmt.Errorf("not a DCR market")
+	}
+	if m.Price == nil || m.Price.URL == "" || m.Price.Price == "" {
+		return fmt.Errorf("no price endpoint")
+	}
+	if m.Price.Volume == "" && m.Price.BaseVolume == "" {
+		return fmt.Errorf("no price volume")
+	}
+	if d := m.Depth; d != nil {
+		if d.URL == "" || d.Asks == "" || d.Bids == "" || d.Price == "" || d.Quantity == "" {
+			return fmt.Errorf("incomplete depth endpoint")
+		}
+	}
+	if c := m.Candlesticks; c != nil {
+		if c.URL == "" || c.Start == "" || c.Open == "" || c.High == "" ||
+			c.Low == "" || c.Close == "" || c.Volume == "" {
+			return fmt.Errorf("incomplete candlesticks endpoint")
+		}
+		if len(c.Intervals) == 0 {
+			return fmt.Errorf("no candlestick intervals")
+		}
+		if len(c.Intervals) > 1 && !strings.Contains(c.URL, "{interval}") {
+			return fmt.Errorf("no {interval} in candlesticks URL")
+		}
+		for bin := range c.Intervals {
+			if _, found := candlestickDurations[bin]; !found {
+				return fmt.Errorf("unknown candlestick bin size %s", bin)
+			}
+		}
+	}
+	return nil
+}
+
+// url fills in the endpoint URL template.
+func (m *MarketSpec) url(template, interval string) string {
+	return strings.NewReplacer("{symbol}", m.Symbol, "{interval}", interval).Replace(template)
+}
+
+// LoadExchangeSpecs reads and validates a JSON array of exchange specs.
+func LoadExchangeSpecs(path string) ([]*ExchangeSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var specs []*ExchangeSpec
+	if err = dec.Decode(&specs); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange specs from %s: %v", path, err)
+	}
+	tokens := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if spec == nil {
+			return nil, fmt.Errorf("null exchange spec in %s", path)
+		}
+		if err = spec.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid exchange spec in %s: %v", path, err)
+		}
+		if tokens[spec.Token] {
+			return nil, fmt.Errorf("duplicate exchange spec for %s in %s", spec.Token, path)
+		}
+		tokens[spec.Token] = true
+	}
+	return specs, nil
+}
+
+// SpecExchange is an exchange with a REST API declared by an ExchangeSpec.
+type SpecExchange struct {
+	*CommonExchange
+	spec *ExchangeSpec
+}
+
+// NewSpecExchangeConstructor creates a constructor for a SpecExchange with the
+// provided spec.
+func NewSpecExchangeConstructor(spec *ExchangeSpec) func(*http.Client, *BotChannels) (Exchange, error) {
+	return func(client *http.Client, channels *BotChannels) (Exchange, error) {
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		newRequest := func(url string) (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range spec.Headers {
+				req.Header.Add(k, v)
+			}
+			return req, nil
+		}
+
+		markets := make([]CurrencyPair, 0, len(spec.Markets))
+		for mkt := range spec.Markets {
+			markets = append(markets, mkt)
+		}
+		reqs := newRequests(markets)
+		var err error
+		for mkt, m := range spec.Markets {
+			reqs[mkt].price, err = newRequest(m.url(m.Price.URL, ""))
+			if err != nil {
+				return nil, err
+			}
+			if m.Depth != nil {
+				reqs[mkt].depth, err = newRequest(m.url(m.Depth.URL, ""))
+				if err != nil {
+					return nil, err
+				}
+			}
+			if m.Candlesticks != nil {
+				for bin, interval := range m.Candlesticks.Intervals {
+					reqs[mkt].candlesticks[bin], err = newRequest(m.url(m.Candlesticks.URL, interval))
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		return &SpecExchange{
+			CommonExchange: newCommonExchange(spec.Token, client, reqs, channels),
+			spec:           spec,
+		}, nil
+	}
+}
+
+// Refresh retrieves and parses API data with the exchange's spec.
+func (xc *SpecExchange) Refresh() {
+	xc.LogRequest()
+	for mkt, requests := range xc.requests {
+		xc.refresh(mkt, requests)
+	}
+}
+
+func (xc *SpecExchange) refresh(mkt CurrencyPair, requests *requests) {
+	m := xc.spec.Markets[mkt]
+	doc, err := xc.fetchSpec(requests.price)
+	if err != nil {
+		xc.fail(fmt.Sprintf("%s: Fetch price", mkt), err)
+		return
+	}
+	baseState, err := m.Price.translate(doc)
+	if err != nil {
+		xc.fail(fmt.Sprintf("%s: Price", mkt), err)
+		return
+	}
+
+	var depth *DepthData
+	if requests.depth != nil {
+		doc, err = xc.fetchSpec(requests.depth)
+		if err == nil {
+			depth, err = m.Depth.translate(doc)
+		}
+		if err != nil {
+			log.Errorf("Error retrieving depth chart data from %s(%s): %v", xc.token, mkt, err)
+		}
+	}
+
+	// Grab the current state to check if candlesticks need updating
+	state := xc.state(mkt)
+
+	candlesticks := map[candlestickKey]Candlesticks{}
+	for bin, req := range requests.candlesticks {
+		oldSticks, found := state.Candlesticks[bin]
+		if !found || oldSticks.needsUpdate(bin) {
+			log.Tracef("Signalling candlestick update for %s, market %s, bin size %s", xc.token, mkt, bin)
+			doc, err = xc.fetchSpec(req)
+			if err != nil {
+				log.Errorf("Error retrieving candlestick data from %s for bin size %s: %v", xc.token, string(bin), err)
+				continue
+			}
+			sticks, err := m.Candlesticks.translate(doc)
+			if err != nil {
+				log.Errorf("Error parsing candlestick data from %s for bin size %s: %v", xc.token, string(bin), err)
+				continue
+			}
+
+			if !found || sticks.time().After(oldSticks.time()) {
+				candlesticks[bin] = sticks
+			}
+		}
+	}
+
+	xc.Update(mkt, &ExchangeState{
+		BaseState:    *baseState,
+		Candlesticks: candlesticks,
+		Depth:        depth,
+	})
+}
+
+// fetchSpec sends the request, decodes the response, and checks the status.
+func (xc *SpecExchange) fetchSpec(request *http.Request) (interface{}, error) {
+	var doc interface{}
+	if err := xc.fetch(request, &doc); err != nil {
+		return nil, err
+	}
+	if status := xc.spec.Status; status != nil {
+		v, err := specValue(doc, status.Path)
+		if err != nil {
+			return nil, err
+		}
+		if s := fmt.Sprint(v); s != status.OK {
+			return nil, fmt.Errorf("expected status %s. received %s", status.OK, s)
+		}
+	}
+	return doc, nil
+}
+
+func (s *PriceSpec) translate(doc interface{}) (*BaseState, error) {
+	price, err := specFloat(doc, s.Price)
+	if err != nil {
+		return nil, err
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("invalid price %f", price)
+	}
+	state := &BaseState{Price: price}
+	if s.Volume != "" {
+		if state.Volume, err = specFloat(doc, s.Volume); err != nil {
+			return nil, err
+		}
+	}
+	if s.BaseVolume != "" {
+		if state.BaseVolume, err = specFloat(doc, s.BaseVolume); err != nil {
+			return nil, err
+		}
+	}
+	if s.Volume == "" {
+		state.Volume = state.BaseVolume / price
+	} else if s.BaseVolume == "" {
+		state.BaseVolume = state.Volume * price
+	}
+	switch {
+	case s.Change != "":
+		if state.Change, err = specFloat(doc, s.Change); err != nil {
+			return nil, err
+		}
+	case s.Open != "":
+		open, err := specFloat(doc, s.Open)
+		if err != nil {
+			return nil, err
+		}
+		state.Change = price - open
+	}
+	if s.Stamp != "" {
+		stamp, err := specTime(doc, s.Stamp)
+		if err != nil {
+			return nil, err
+		}
+		state.Stamp = stamp.Unix()
+	}
+	return state, nil
+}
+
+func (s *DepthSpec) translate(doc interface{}) (*DepthData, error) {
+	asks, err := s.points(doc, s.Asks)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := s.points(doc, s.Bids)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(asks, func(i, j int) bool {
+		return asks[i].Price < asks[j].Price
+	})
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].Price > bids[j].Price
+	})
+	return &DepthData{
+		Time: time.Now().Unix(),
+		Asks: asks,
+		Bids: bids,
+	}, nil
+}
+
+func (s *DepthSpec) points(doc interface{}, path string) ([]DepthPoint, error) {
+	rawPts, err := specArray(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	pts := make([]DepthPoint, 0, len(rawPts))
+	for _, pt := range rawPts {
+		price, err := specFloat(pt, s.Price)
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := specFloat(pt, s.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		pts = append(pts, DepthPoint{
+			Quantity: quantity,
+			Price:    price,
+		})
+	}
+	return pts, nil
+}
+
+func (s *CandlestickSpec) translate(doc interface{}) (Candlesticks, error) {
+	rawSticks, err := specArray(doc, s.Sticks)
+	if err != nil {
+		return nil, err
+	}
+	sticks := make(Candlesticks, 0, len(rawSticks))
+	for _, stick := range rawSticks {
+		start, err := specTime(stick, s.Start)
+		if err != nil {
+			return nil, err
+		}
+		var vals [5]float64
+		for i, path := range []string{s.Open, s.High, s.Low, s.Close, s.Volume} {
+			if vals[i], err = specFloat(stick, path); err != nil {
+				return nil, err
+			}
+		}
+		sticks = append(sticks, Candlestick{
+			High:   vals[1],
+			Low:    vals[2],
+			Open:   vals[0],
+			Close:  vals[3],
+			Volume: vals[4],
+			Start:  start,
+		})
+	}
+	// Some exchanges list the most recent candlestick first.
+	sort.SliceStable(sticks, func(i, j int) bool {
+		return sticks[i].Start.Before(sticks[j].Start)
+	})
+	return sticks, nil
+}
+
+// specValue is the value at the path in the decoded JSON.
+func specValue(doc interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return doc, nil
+	}
+	v := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, found := node[key]
+			if !found {
+				return nil, fmt.Errorf("no %q at %q", key, path)
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("no index %q at %q", key, path)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("no %q at %q. not an object or array", key, path)
+		}
+	}
+	return v, nil
+}
+
+func specArray(doc interface{}, path string) ([]interface{}, error) {
+	v, err := specValue(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array at %q. found %T", path, v)
+	}
+	return arr, nil
+}
+
+func specFloat(doc interface{}, path string) (float64, error) {
+	v, err := specValue(doc, path)
+	if err != nil {
+		return 0, err
+	}
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse float from %s=%q: %v", path, x, err)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("expected a number at %q. found %T", path, v)
+}
+
+// Unix stamps greater than msStampThreshold, which is in the year 5138 in
+// seconds, are in milliseconds.
+const msStampThreshold = 1e11
+
+func specTime(doc interface{}, path string) (time.Time, error) {
+	v, err := specValue(doc, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var stamp float64
+	switch x := v.(type) {
+	case float64:
+		stamp = x
+	case string:
+		if stamp, err = strconv.ParseFloat(x, 64); err != nil {
+			t, err := time.Parse(time.RFC3339, x)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("failed to parse time from %s=%q", path, x)
+			}
+			return t, nil
+		}
+	default:
+		return time.Time{}, fmt.Errorf("expected a time at %q. found %T", path, v)
+	}
+	if stamp > msStampThreshold {
+		return time.Unix(int64(stamp/1e3), 0), nil
+	}
+	return time.Unix(int64(stamp), 0), nil
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleSpecFile = "sample-exchange-specs.json"
+
+func refreshUpdates(t *testing.T, xc Exchange, channels *BotChannels) map[CurrencyPair]*ExchangeState {
+	t.Helper()
+	xc.Refresh()
+	if xc.IsFailed() {
+		t.Fatalf("%s: refresh failed", xc.Token())
+	}
+	states := make(map[CurrencyPair]*ExchangeState)
+	for _, update := range drainUpdates(channels.exchange) {
+		states[update.CurrencyPair] = update.State
+	}
+	return states
+}
+
+// TestSpecExchanges checks that the sample specs, which declare some of the
+// built-in exchanges, replay the recorded APIs the same as the built-ins.
+func TestSpecExchanges(t *testing.T) {
+	specs, err := LoadExchangeSpecs(sampleSpecFile)
+	if err != nil {
+		t.Fatalf("LoadExchangeSpecs error: %v", err)
+	}
+	s := newReplayServer(t, Binance, Huobi, Mexc)
+
+	for _, spec := range specs {
+		channels := newReplayChannels(t)
+		builtIn, err := DcrExchanges[spec.Token](s.client(), channels)
+		if err != nil {
+			t.Fatalf("%s: constructor error: %v", spec.Token, err)
+		}
+		want := refreshUpdates(t, builtIn, channels)
+
+		xc, err := NewSpecExchangeConstructor(spec)(s.client(), channels)
+		if err != nil {
+			t.Fatalf("%s: spec constructor error: %v", spec.Token, err)
+		}
+		if _, ok := xc.(*SpecExchange); !ok || xc.Token() != spec.Token {
+			t.Fatalf("%s: wrong exchange %T %s", spec.Token, xc, xc.Token())
+		}
+		got := refreshUpdates(t, xc, channels)
+
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d markets, expected %d", spec.Token, len(got), len(want))
+		}
+		for mkt, wantState := range want {
+			name := spec.Token + " " + string(mkt)
+			state := got[mkt]
+			if state == nil {
+				t.Errorf("%s: no update", name)
+				continue
+			}
+			if state.BaseState != wantState.BaseState {
+				t.Errorf("%s: got %+v, expected %+v", name, state.BaseState, wantState.BaseState)
+			}
+			if !reflect.DeepEqual(state.Depth.Asks, wantState.Depth.Asks) ||
+				!reflect.DeepEqual(state.Depth.Bids, wantState.Depth.Bids) {
+				t.Errorf("%s: wrong depth data", name)
+			}
+			if len(state.Candlesticks) != len(wantState.Candlesticks) {
+				t.Errorf("%s: got %d candlestick bins, expected %d", name, len(state.Candlesticks), len(wantState.Candlesticks))
+			}
+			for bin, wantSticks := range wantState.Candlesticks {
+				sticks := state.Candlesticks[bin]
+				if len(sticks) != len(wantSticks) {
+					t.Errorf("%s: got %d %s candlesticks, expected %d", name, len(sticks), bin, len(wantSticks))
+					continue
+				}
+				for i, stick := range sticks {
+					wantStick := wantSticks[i]
+					if !stick.Start.Equal(wantStick.Start) {
+						t.Errorf("%s: %s candlestick %d starts at %v, expected %v", name, bin, i, stick.Start, wantStick.Start)
+					}
+					stick.Start, wantStick.Start = time.Time{}, time.Time{}
+					if stick != wantStick {
+						t.Errorf("%s: %s candlestick %d is %+v, expected %+v", name, bin, i, stick, wantStick)
+					}
+				}
+			}
+		}
+	}
+
+	// The status is checked.
+	huobi := specs[1]
+	huobi.Status.OK = "okay"
+	channels := newReplayChannels(t)
+	xc, _ := NewSpecExchangeConstructor(huobi)(s.client(), channels)
+	xc.Refresh()
+	if !xc.IsFailed() {
+		t.Errorf("refresh with a bad status did not fail")
+	}
+}
+
+func TestExchangeSpecValidate(t *testing.T) {
+	newSpec := func() *ExchangeSpec {
+		return &ExchangeSpec{
+			Token: "xc",
+			Markets: map[CurrencyPair]*MarketSpec{
+				CurrencyPairDCRBTC: {
+					Price: &PriceSpec{URL: "https://xc.example/price", Price: "last", Volume: "vol"},
+					Candlesticks: &CandlestickSpec{
+						URL:       "https://xc.example/sticks?interval={interval}",
+						Intervals: map[candlestickKey]string{hourKey: "1h", dayKey: "1d"},
+						Start:     "0", Open: "1", High: "2", Low: "3", Close: "4", Volume: "5",
+					},
+				},
+			},
+		}
+	}
+	if err := newSpec().Validate(); err != nil {
+		t.Fatalf("valid spec error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*ExchangeSpec)
+	}{
+		{"no token", func(s *ExchangeSpec) { s.Token = "" }},
+		{"index token", func(s *ExchangeSpec) { s.Token = Coinbase }},
+		{"index market", func(s *ExchangeSpec) {
+			s.Markets[BTCIndex] = s.Markets[CurrencyPairDCRBTC]
+		}},
+		{"no volume", func(s *ExchangeSpec) { s.Markets[CurrencyPairDCRBTC].Price.Volume = "" }},
+		{"incomplete depth", func(s *ExchangeSpec) {
+			s.Markets[CurrencyPairDCRBTC].Depth = &DepthSpec{URL: "https://xc.example/depth", Asks: "asks"}
+		}},
+		{"unknown bin", func(s *ExchangeSpec) {
+			s.Markets[CurrencyPairDCRBTC].Candlesticks.Intervals["1w"] = "1w"
+		}},
+		{"no interval", func(s *ExchangeSpec) {
+			s.Markets[CurrencyPairDCRBTC].Candlesticks.URL = "https://xc.example/sticks"
+		}},
+	}
+	for _, tt := range tests {
+		spec := newSpec()
+		tt.modify(spec)
+		if spec.Validate() == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestLoadExchangeSpecs(t *testing.T) {
+	dir := t.TempDir()
+	load := func(contents string) error {
+		path := filepath.Join(dir, "specs.json")
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadExchangeSpecs(path)
+		return err
+	}
+	const spec = `{"token": "xc", "markets": {"DCR-BTC": {"price": {"url": "https://xc.example", "price": "p", "volume": "v"}}}}`
+	if err := load("[" + spec + "]"); err != nil {
+		t.Fatalf("LoadExchangeSpecs error: %v", err)
+	}
+	if err := load("[" + spec + "," + spec + "]"); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected a duplicate error, got %v", err)
+	}
+	if err := load(`[{"token": "xc", "market": {}}]`); err == nil {
+		t.Errorf("no error for an unknown field")
+	}
+	if err := load(`[{"token": "xc"}]`); err == nil {
+		t.Errorf("no error for an invalid spec")
+	}
+}
+
+func TestSpecValues(t *testing.T) {
+	doc := map[string]interface{}{
+		"tick": map[string]interface{}{
+			"close": 1.5,
+			"bids":  []interface{}{[]interface{}{"0.25", 3.0}},
+		},
+		"ms":   1709294400123.0,
+		"s":    "1709294400",
+		"date": "2024-03-01T12:00:00Z",
+	}
+	if v, err := specFloat(doc, "tick.close"); err != nil || v != 1.5 {
+		t.Errorf("tick.close: %v (%v)", v, err)
+	}
+	if v, err := specFloat(doc, "tick.bids.0.0"); err != nil || v != 0.25 {
+		t.Errorf("tick.bids.0.0: %v (%v)", v, err)
+	}
+	for _, path := range []string{"tick.open", "tick.bids.1", "tick.bids.x", "tick.close.x", "date"} {
+		if _, err := specFloat(doc, path); err == nil {
+			t.Errorf("%s: no error", path)
+		}
+	}
+	stamp := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for _, path := range []string{"ms", "s", "date"} {
+		if tm, err := specTime(doc, path); err != nil || !tm.Equal(stamp) {
+			t.Errorf("%s: %v (%v)", path, tm, err)
+		}
+	}
+	if arr, err := specArray(doc, "tick.bids"); err != nil || len(arr) != 1 {
+		t.Errorf("tick.bids: %v (%v)", arr, err)
+	}
+	if _, err := specArray(doc, "tick"); err == nil {
+		t.Errorf("tick: no error")
+	}
+}
+
+func TestExchangeBotSpecs(t *testing.T) {
+	bot, err := NewExchangeBot(&ExchangeBotConfig{
+		Disabled: []string{Mexc},
+		SpecFile: sampleSpecFile,
+	})
+	if err != nil {
+		t.Fatalf("NewExchangeBot error: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, ch := range bot.quitChans {
+			close(ch)
+		}
+	})
+	for _, token := range []string{Binance, Huobi} {
+		if _, ok := bot.DcrExchanges[token].(*SpecExchange); !ok {
+			t.Errorf("%s not replaced by the spec: %T", token, bot.DcrExchanges[token])
+		}
+	}
+	if bot.DcrExchanges[Mexc] != nil {
+		t.Errorf("disabled spec exchange was added")
+	}
+	if _, ok := bot.DcrExchanges[Poloniex].(*PoloniexExchange); !ok {
+		t.Errorf("built-in exchange without a spec was not added")
+	}
+
+	if _, err = NewExchangeBot(&ExchangeBotConfig{SpecFile: "nonexistent.json"}); err == nil {
+		t.Errorf("no error for a missing spec file")
+	}
+}
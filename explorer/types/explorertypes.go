@@ -188,6 +188,7 @@ type TxInfo struct {
 	Maturity         int64   // Total number of blocks before mature
 	MaturityTimeTill float64 // Time in hours until mature
 	TSpendMeta       *dbtypes.TreasurySpendMetaData
+	TSpendTally      *dbtypes.TreasurySpendTally // votes by interval
 	TicketInfo
 }
 
@@ -0,0 +1,289 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package politeia
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	commentsv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	recordsv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+// The files of an archive of politeia replies. The inventory file holds the
+// vetted tokens ordered as by the politeia inventory, newest first, and each
+// record has a directory named by its token with the JSON replies for it.
+const (
+	archiveInventory    = "inventory.json"    // []string
+	archiveRecord       = "record.json"       // recordsv1.Record
+	archiveCommentCount = "commentcount.json" // uint32
+	archiveVoteSummary  = "votesummary.json"  // ticketvotev1.Summary
+	archiveVoteDetails  = "votedetails.json"  // ticketvotev1.DetailsReply
+	archiveVoteResults  = "voteresults.json"  // ticketvotev1.ResultsReply
+)
+
+// validToken checks that a record token is hex encoded, as it names the
+// record's directory in an archive.
+func validToken(token string) error {
+	if _, err := hex.DecodeString(token); err != nil || token == "" {
+		return fmt.Errorf("invalid token %q", token)
+	}
+	return nil
+}
+
+// Archive is a Source of politeia records read from a directory of politeia v1
+// JSON replies, as written by a Recorder. It allows proposals to be imported
+// without access to a politeia server.
+type Archive struct {
+	dir string
+}
+
+// NewArchive creates an Archive of the politeia replies in the directory dir.
+func NewArchive(dir string) (*Archive, error) {
+	if _, err := os.Stat(filepath.Join(dir, archiveInventory)); err != nil {
+		return nil, fmt.Errorf("invalid politeia archive: %w", err)
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// read decodes the archive file at the path elements into v.
+func (a *Archive) read(v interface{}, elem ...string) error {
+	b, err := os.ReadFile(filepath.Join(append([]string{a.dir}, elem...)...))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// RecordInventoryOrdered returns a page of the archived tokens. The archive
+// only holds vetted records.
+func (a *Archive) RecordInventoryOrdered(i recordsv1.InventoryOrdered) (*recordsv1.InventoryOrderedReply, error) {
+	reply := &recordsv1.InventoryOrderedReply{Tokens: []string{}}
+	if i.State != recordsv1.RecordStateVetted {
+		return reply, nil
+	}
+	var tokens []string
+	if err := a.read(&tokens, archiveInventory); err != nil {
+		return nil, err
+	}
+	page := i.Page
+	if page == 0 {
+		page = 1
+	}
+	start := int(page-1) * int(recordsv1.InventoryPageSize)
+	if start >= len(tokens) {
+		return reply, nil
+	}
+	end := start + int(recordsv1.InventoryPageSize)
+	if end > len(tokens) {
+		end = len(tokens)
+	}
+	reply.Tokens = tokens[start:end]
+	return reply, nil
+}
+
+// RecordDetails returns the archived record.
+func (a *Archive) RecordDetails(d recordsv1.Details) (*recordsv1.Record, error) {
+	if err := validToken(d.Token); err != nil {
+		return nil, err
+	}
+	var record recordsv1.Record
+	if err := a.read(&record, d.Token, archiveRecord); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// CommentCount returns the archived comments counts of the records. Records
+// without an archived count are omitted.
+func (a *Archive) CommentCount(c commentsv1.Count) (*commentsv1.CountReply, error) {
+	reply := &commentsv1.CountReply{Counts: make(map[string]uint32, len(c.Tokens))}
+	for _, token := range c.Tokens {
+		if err := validToken(token); err != nil {
+			return nil, err
+		}
+		var count uint32
+		err := a.read(&count, token, archiveCommentCount)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reply.Counts[token] = count
+	}
+	return reply, nil
+}
+
+// TicketVoteSummaries returns the archived vote summaries of the records.
+// Records without an archived summary are omitted.
+func (a *Archive) TicketVoteSummaries(s ticketvotev1.Summaries) (*ticketvotev1.SummariesReply, error) {
+	reply := &ticketvotev1.SummariesReply{
+		Summaries: make(map[string]ticketvotev1.Summary, len(s.Tokens)),
+	}
+	for _, token := range s.Tokens {
+		if err := validToken(token); err != nil {
+			return nil, err
+		}
+		var summary ticketvotev1.Summary
+		err := a.read(&summary, token, archiveVoteSummary)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reply.Summaries[token] = summary
+	}
+	return reply, nil
+}
+
+// TicketVoteDetails returns the archived vote details of the record.
+func (a *Archive) TicketVoteDetails(d ticketvotev1.Details) (*ticketvotev1.DetailsReply, error) {
+	if err := validToken(d.Token); err != nil {
+		return nil, err
+	}
+	var details ticketvotev1.DetailsReply
+	if err := a.read(&details, d.Token, archiveVoteDetails); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// TicketVoteResults returns the archived votes of the record, or no votes if
+// none are archived.
+func (a *Archive) TicketVoteResults(r ticketvotev1.Results) (*ticketvotev1.ResultsReply, error) {
+	if err := validToken(r.Token); err != nil {
+		return nil, err
+	}
+	results := ticketvotev1.ResultsReply{Votes: []ticketvotev1.CastVoteDetails{}}
+	err := a.read(&results, r.Token, archiveVoteResults)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// Recorder is a Source that records the replies of another Source in an
+// archive directory, which may be read with an Archive.
+type Recorder struct {
+	src Source
+	dir string
+
+	mtx       sync.Mutex
+	inventory []string
+}
+
+// NewRecorder creates a Recorder of the replies from src to the directory dir.
+func NewRecorder(src Source, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Recorder{src: src, dir: dir}, nil
+}
+
+// write encodes v to the archive file at the path elements.
+func (r *Recorder) write(v interface{}, elem ...string) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(append([]string{r.dir}, elem...)...)
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// RecordInventoryOrdered returns and records a page of the vetted tokens. The
+// first page starts a new inventory.
+func (r *Recorder) RecordInventoryOrdered(i recordsv1.InventoryOrdered) (*recordsv1.InventoryOrderedReply, error) {
+	reply, err := r.src.RecordInventoryOrdered(i)
+	if err != nil || i.State != recordsv1.RecordStateVetted {
+		return reply, err
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if i.Page <= 1 {
+		r.inventory = nil
+	}
+	r.inventory = append(r.inventory, reply.Tokens...)
+	return reply, r.write(r.inventory, archiveInventory)
+}
+
+// RecordDetails returns and records the record.
+func (r *Recorder) RecordDetails(d recordsv1.Details) (*recordsv1.Record, error) {
+	if err := validToken(d.Token); err != nil {
+		return nil, err
+	}
+	record, err := r.src.RecordDetails(d)
+	if err != nil {
+		return nil, err
+	}
+	return record, r.write(record, d.Token, archiveRecord)
+}
+
+// CommentCount returns and records the comments counts of the records.
+func (r *Recorder) CommentCount(c commentsv1.Count) (*commentsv1.CountReply, error) {
+	reply, err := r.src.CommentCount(c)
+	if err != nil {
+		return nil, err
+	}
+	for token, count := range reply.Counts {
+		if err = validToken(token); err != nil {
+			return nil, err
+		}
+		if err = r.write(count, token, archiveCommentCount); err != nil {
+			return nil, err
+		}
+	}
+	return reply, nil
+}
+
+// TicketVoteSummaries returns and records the vote summaries of the records.
+func (r *Recorder) TicketVoteSummaries(s ticketvotev1.Summaries) (*ticketvotev1.SummariesReply, error) {
+	reply, err := r.src.TicketVoteSummaries(s)
+	if err != nil {
+		return nil, err
+	}
+	for token, summary := range reply.Summaries {
+		if err = validToken(token); err != nil {
+			return nil, err
+		}
+		if err = r.write(summary, token, archiveVoteSummary); err != nil {
+			return nil, err
+		}
+	}
+	return reply, nil
+}
+
+// TicketVoteDetails returns and records the vote details of the record.
+func (r *Recorder) TicketVoteDetails(d ticketvotev1.Details) (*ticketvotev1.DetailsReply, error) {
+	if err := validToken(d.Token); err != nil {
+		return nil, err
+	}
+	details, err := r.src.TicketVoteDetails(d)
+	if err != nil {
+		return nil, err
+	}
+	return details, r.write(details, d.Token, archiveVoteDetails)
+}
+
+// TicketVoteResults returns and records the votes of the record.
+func (r *Recorder) TicketVoteResults(res ticketvotev1.Results) (*ticketvotev1.ResultsReply, error) {
+	if err := validToken(res.Token); err != nil {
+		return nil, err
+	}
+	results, err := r.src.TicketVoteResults(res)
+	if err != nil {
+		return nil, err
+	}
+	return results, r.write(results, res.Token, archiveVoteResults)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package politeia
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
+	recordsv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+)
+
+func TestArchiveInventory(t *testing.T) {
+	dir := t.TempDir()
+	tokens := make([]string, 25)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("%016x", i)
+	}
+	b, _ := json.Marshal(tokens)
+	if err := os.WriteFile(filepath.Join(dir, archiveInventory), b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := NewArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &ProposalsDB{client: archive}
+	got, err := db.fetchVettedTokensInventory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, tokens) {
+		t.Errorf("got inventory %v, want %v", got, tokens)
+	}
+
+	reply, err := archive.RecordInventoryOrdered(recordsv1.InventoryOrdered{
+		State: recordsv1.RecordStateUnvetted,
+		Page:  1,
+	})
+	if err != nil || len(reply.Tokens) != 0 {
+		t.Errorf("expected no unvetted tokens, got %v (%v)", reply, err)
+	}
+	if _, err = archive.RecordDetails(recordsv1.Details{Token: "../a1"}); err == nil {
+		t.Error("expected an error for an invalid token")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	archive, err := NewArchive(filepath.Join("testdata", "archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	recorder, err := NewRecorder(archive, filepath.Join(dir, "recorded"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Proposals synced from a recording replay as they were recorded.
+	sync := func(src Source, name string) []*pitypes.ProposalRecord {
+		db, err := NewProposalsDBWithSource(src, filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if err = db.ProposalsSync(); err != nil {
+			t.Fatal(err)
+		}
+		proposals, _, err := db.ProposalsAll(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The storm IDs follow the order in which new proposals are saved,
+		// which is not that of the inventory.
+		for _, p := range proposals {
+			p.ID = 0
+		}
+		return proposals
+	}
+	want := sync(recorder, "recording.db")
+	recorded, err := NewArchive(filepath.Join(dir, "recorded"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sync(recorded, "replay.db"); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed proposals differ from those recorded")
+	}
+	if len(want) != 3 {
+		t.Errorf("expected 3 proposals, got %d", len(want))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// pifixtures serves the politeiawww v1 routes used by dcrdata from an archive
+// of recorded politeia replies, for running dcrdata against a local politeia
+// fixture server with --politeiaurl. With -record, it instead proxies a
+// politeia server and records its replies in the archive.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/decred/dcrdata/gov/v6/politeia"
+)
+
+var (
+	archiveDir = flag.String("archive", "", "archive directory of politeia replies")
+	record     = flag.String("record", "", "root URL of a politeia server to record replies from")
+	listen     = flag.String("listen", "127.0.0.1:4443", "address to listen on")
+)
+
+func run() error {
+	flag.Parse()
+	if *archiveDir == "" {
+		return fmt.Errorf("-archive is required")
+	}
+
+	var src politeia.Source
+	if *record != "" {
+		client, err := politeia.NewClient(*record)
+		if err != nil {
+			return err
+		}
+		src, err = politeia.NewRecorder(client, *archiveDir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Recording replies from %s to %s\n", *record, *archiveDir)
+	} else {
+		archive, err := politeia.NewArchive(*archiveDir)
+		if err != nil {
+			return err
+		}
+		src = archive
+		fmt.Printf("Serving replies from %s\n", *archiveDir)
+	}
+
+	fmt.Printf("Listening on http://%s\n", *listen)
+	return http.ListenAndServe(*listen, politeia.NewServer(src))
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
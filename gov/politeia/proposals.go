@@ -20,7 +20,6 @@ import (
 	commentsv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
 	recordsv1 "github.com/decred/politeia/politeiawww/api/records/v1"
 	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
-	piclient "github.com/decred/politeia/politeiawww/client"
 )
 
 var (
@@ -29,20 +28,22 @@ var (
 	errDef = fmt.Errorf("ProposalDB was not initialized correctly")
 
 	// dbVersion is the current required version of the proposals.db.
-	dbVersion = semver.NewSemver(2, 0, 0)
+	dbVersion = semver.NewSemver(2, 2, 0)
 )
 
 // dbinfo defines the property that holds the db version.
 const dbinfo = "_proposals.db_"
 
 // ProposalsDB defines the object that interacts with the local proposals
-// db, and with decred's politeia server.
+// db, and with a Source of politeia records such as decred's politeia server.
 type ProposalsDB struct {
 	lastSync int64  // atomic
 	updating uint32 // atomic
 	dbP      *storm.DB
-	client   *piclient.Client
+	client   Source
 	APIPath  string
+
+	ticketCache ticketCache
 }
 
 // NewProposalsDB opens an existing database or creates a new a storm DB
@@ -53,6 +54,28 @@ func NewProposalsDB(politeiaURL, dbPath string) (*ProposalsDB, error) {
 	if politeiaURL == "" {
 		return nil, errors.New("missing Politeia URL")
 	}
+
+	pc, err := NewClient(politeiaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proposalDB, err := NewProposalsDBWithSource(pc, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	proposalDB.APIPath = politeiaURL
+
+	return proposalDB, nil
+}
+
+// NewProposalsDBWithSource opens an existing database or creates a new storm
+// DB instance with the provided path, that syncs with the proposals from src.
+func NewProposalsDBWithSource(src Source, dbPath string) (*ProposalsDB, error) {
+	// Validate arguments
+	if src == nil {
+		return nil, errors.New("missing politeia source")
+	}
 	if dbPath == "" {
 		return nil, errors.New("missing db path")
 	}
@@ -75,11 +98,14 @@ func NewProposalsDB(politeiaURL, dbPath string) (*ProposalsDB, error) {
 	}
 
 	if version != dbVersion.String() {
-		// Attempt to delete the ProposalRecord bucket.
-		if err = db.Drop(&pitypes.ProposalRecord{}); err != nil {
-			// If error due bucket not found was returned, ignore it.
-			if !strings.Contains(err.Error(), "not found") {
-				return nil, fmt.Errorf("delete bucket struct failed: %w", err)
+		// Attempt to delete the ProposalRecord and ProposalTicketVotes
+		// buckets.
+		for _, bucket := range []interface{}{&pitypes.ProposalRecord{}, &pitypes.ProposalTicketVotes{}} {
+			if err = db.Drop(bucket); err != nil {
+				// If error due bucket not found was returned, ignore it.
+				if !strings.Contains(err.Error(), "not found") {
+					return nil, fmt.Errorf("delete bucket struct failed: %w", err)
+				}
 			}
 		}
 
@@ -91,15 +117,9 @@ func NewProposalsDB(politeiaURL, dbPath string) (*ProposalsDB, error) {
 		log.Infof("proposals.db version %v was set", dbVersion)
 	}
 
-	pc, err := piclient.New(politeiaURL+"/api", piclient.Opts{})
-	if err != nil {
-		return nil, err
-	}
-
 	proposalDB := &ProposalsDB{
-		dbP:     db,
-		client:  pc,
-		APIPath: politeiaURL,
+		dbP:    db,
+		client: src,
 	}
 
 	return proposalDB, nil
@@ -213,6 +233,23 @@ func (db *ProposalsDB) ProposalByToken(token string) (*pitypes.ProposalRecord, e
 	return db.proposal("Token", token)
 }
 
+// ProposalTicketVotes retrieves the eligible tickets and the ticket votes of
+// the proposal for the given token argument. storm.ErrNotFound is returned if
+// the proposal's votes have not been fetched.
+//
+// Satisfies the PoliteiaBackend interface.
+func (db *ProposalsDB) ProposalTicketVotes(token string) (*pitypes.ProposalTicketVotes, error) {
+	if db == nil || db.dbP == nil {
+		return nil, errDef
+	}
+
+	var votes pitypes.ProposalTicketVotes
+	if err := db.dbP.One("Token", token, &votes); err != nil {
+		return nil, err
+	}
+	return &votes, nil
+}
+
 // fetchProposalsData returns the parsed vetted proposals from politeia
 // API's. It cooks up the data needed to save the proposals in stormdb. It
 // first fetches the proposal details, then comments and then vote summary.
@@ -256,6 +293,10 @@ func (db *ProposalsDB) fetchProposalsData(tokens []string) ([]*pitypes.ProposalR
 			return nil, fmt.Errorf("proposalMetadataDecode err: %w", err)
 		}
 		proposal.Name = pm.Name
+		proposal.Amount = pm.Amount
+		proposal.StartDate = pm.StartDate
+		proposal.EndDate = pm.EndDate
+		proposal.Domain = pm.Domain
 
 		// User metadata
 		um, err := userMetadataDecode(record.Metadata)
@@ -413,14 +454,19 @@ func paginateTokens(tokens []string, pageSize uint32) [][]string {
 }
 
 // fetchTicketVoteResults fetches the vote data for the given proposal token,
-// then builds and returns its parsed chart data.
-func (db *ProposalsDB) fetchTicketVoteResults(token string) (*pitypes.ProposalChartData, error) {
+// then builds and returns its parsed chart data, and the eligible tickets and
+// their votes.
+func (db *ProposalsDB) fetchTicketVoteResults(token string) (*pitypes.ProposalChartData, *pitypes.ProposalTicketVotes, error) {
 	// Fetch ticket votes details to acquire vote bits options info.
 	details, err := db.client.TicketVoteDetails(ticketvotev1.Details{
 		Token: token,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("pi client TicketVoteDetails err: %w", err)
+		return nil, nil, fmt.Errorf("pi client TicketVoteDetails err: %w", err)
+	}
+
+	if details.Vote == nil {
+		return nil, nil, fmt.Errorf("no vote details for proposal %v", token)
 	}
 
 	// Maps the vote bits option to their respective string ID.
@@ -433,7 +479,7 @@ func (db *ProposalsDB) fetchTicketVoteResults(token string) (*pitypes.ProposalCh
 		Token: token,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("pi client TicketVoteResults err: %w", err)
+		return nil, nil, fmt.Errorf("pi client TicketVoteResults err: %w", err)
 	}
 
 	// Parse proposal chart data from the ticket vote results reply and
@@ -443,19 +489,24 @@ func (db *ProposalsDB) fetchTicketVoteResults(token string) (*pitypes.ProposalCh
 		timestamp int64
 	}
 	votes := make([]*voteData, 0, len(tvr.Votes))
+	ticketVotes := &pitypes.ProposalTicketVotes{
+		Token:    token,
+		Eligible: details.Vote.EligibleTickets,
+		Votes:    make([]pitypes.TicketVote, 0, len(tvr.Votes)),
+	}
 	for iv := range tvr.Votes {
 		// Vote bit comes as a hexadecimal number in the format of a string.
 		// Convert it to uint64.
 		bit, err := strconv.ParseUint(tvr.Votes[iv].VoteBit, 16, 64)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Verify vote bit is valid.
 		err = voteBitVerify(details.Vote.Params.Options,
 			details.Vote.Params.Mask, bit)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Parse relevant data.
@@ -473,6 +524,11 @@ func (db *ProposalsDB) fetchTicketVoteResults(token string) (*pitypes.ProposalCh
 		}
 		vd.timestamp = tvr.Votes[iv].Timestamp
 		votes = append(votes, &vd)
+		ticketVotes.Votes = append(ticketVotes.Votes, pitypes.TicketVote{
+			Ticket:    tvr.Votes[iv].Ticket,
+			Choice:    voteOptsMap[bit],
+			Timestamp: vd.timestamp,
+		})
 	}
 	sort.Slice(votes, func(i, j int) bool {
 		return votes[i].timestamp < votes[j].timestamp
@@ -494,7 +550,7 @@ func (db *ProposalsDB) fetchTicketVoteResults(token string) (*pitypes.ProposalCh
 		Yes:  yes,
 		No:   no,
 		Time: times,
-	}, nil
+	}, ticketVotes, nil
 }
 
 // proposalsSave saves the batch proposals data to the db. This is ran when the
@@ -623,11 +679,14 @@ func (db *ProposalsDB) proposalsInProgressUpdate() error {
 			(prop.TotalVotes != proposal.TotalVotes || prop.ChartData == nil) {
 			t0 := time.Now()
 			log.Infof("Fetching vote results for proposal %v (status %v)...", prop.Token, recordsv1.RecordStatuses[prop.Status])
-			voteResults, err := db.fetchTicketVoteResults(prop.Token)
+			voteResults, ticketVotes, err := db.fetchTicketVoteResults(prop.Token)
 			if err != nil {
 				return fmt.Errorf("fetchTicketVoteResults failed with err: %w", err)
 			}
 			proposal.ChartData = voteResults
+			if err = db.dbP.Save(ticketVotes); err != nil {
+				return fmt.Errorf("storm db Save failed with err: %w", err)
+			}
 			log.Infof("Retrieved vote results for proposal %v in %v.", prop.Token, time.Since(t0))
 		}
 
@@ -680,11 +739,14 @@ func (db *ProposalsDB) proposalsVoteResultsUpdate() error {
 	for _, prop := range propsVotingComplete {
 		t0 := time.Now()
 		log.Infof("Fetching vote results for proposal %v (status %v)...", prop.Token, recordsv1.RecordStatuses[prop.Status])
-		voteResults, err := db.fetchTicketVoteResults(prop.Token)
+		voteResults, ticketVotes, err := db.fetchTicketVoteResults(prop.Token)
 		if err != nil {
 			return fmt.Errorf("fetchTicketVoteResults failed with err: %w", err)
 		}
 		prop.ChartData = voteResults
+		if err = db.dbP.Save(ticketVotes); err != nil {
+			return fmt.Errorf("storm db Save failed with err: %w", err)
+		}
 		prop.Synced = true
 
 		err = db.dbP.Update(prop)
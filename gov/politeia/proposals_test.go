@@ -1,8 +1,16 @@
 package politeia
 
 import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/asdine/storm/v3"
+	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
+	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 )
 
 func TestPaginateTokens(t *testing.T) {
@@ -134,3 +142,155 @@ func TestPaginateTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestProposalsSync(t *testing.T) {
+	archive, err := NewArchive(filepath.Join("testdata", "archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sync through the politeiawww client from a fixture server.
+	srv := httptest.NewServer(NewServer(archive))
+	defer srv.Close()
+	db, err := NewProposalsDB(srv.URL, filepath.Join(t.TempDir(), "proposals.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = db.ProposalsSync(); err != nil {
+		t.Fatal(err)
+	}
+	proposals, count, err := db.ProposalsAll(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || len(proposals) != 3 {
+		t.Fatalf("expected 3 proposals, got %d", count)
+	}
+
+	// The proposal being voted on has its votes so far, and the approved
+	// proposal has all of its votes and is fully synced.
+	a, err := db.ProposalByToken("a1a1a1a1a1a1a1a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "Proposal A" || a.VoteStatus != ticketvotev1.VoteStatusStarted ||
+		a.CommentsCount != 3 || a.TotalVotes != 2 || a.ChartData == nil ||
+		len(a.ChartData.Yes) != 2 || a.ChartData.Yes[0] != 1 || a.ChartData.No[1] != 1 {
+		t.Errorf("unexpected proposal %+v", a)
+	}
+	b, err := db.ProposalByToken("b2b2b2b2b2b2b2b2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.VoteStatus != ticketvotev1.VoteStatusApproved || !b.Synced ||
+		b.ChartData == nil || len(b.ChartData.Time) != 3 {
+		t.Errorf("unexpected proposal %+v", b)
+	}
+	c, err := db.ProposalByToken("c3c3c3c3c3c3c3c3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.VoteStatus != ticketvotev1.VoteStatusUnauthorized || c.ChartData != nil {
+		t.Errorf("unexpected proposal %+v", c)
+	}
+
+	// The eligible tickets and the ticket votes are saved with the votes.
+	aVotes, err := db.ProposalTicketVotes("a1a1a1a1a1a1a1a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aVotes.Eligible) != 10 || len(aVotes.Votes) != 2 ||
+		aVotes.Votes[0].Ticket != aVotes.Eligible[0] || aVotes.Votes[0].Choice != ticketvotev1.VoteOptionIDApprove {
+		t.Errorf("unexpected ticket votes %+v", aVotes)
+	}
+	if _, err = db.ProposalTicketVotes("c3c3c3c3c3c3c3c3"); !errors.Is(err, storm.ErrNotFound) {
+		t.Errorf("expected no ticket votes, got %v", err)
+	}
+
+	// A later sync adds the new proposal and updates those in progress.
+	db.client, err = NewArchive(filepath.Join("testdata", "archive-update"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.ProposalsSync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, count, err = db.ProposalsAll(0, 10); err != nil || count != 4 {
+		t.Fatalf("expected 4 proposals, got %d (%v)", count, err)
+	}
+	if _, err = db.ProposalByToken("d4d4d4d4d4d4d4d4"); err != nil {
+		t.Error(err)
+	}
+	a, err = db.ProposalByToken("a1a1a1a1a1a1a1a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.CommentsCount != 4 || a.TotalVotes != 3 || len(a.ChartData.Yes) != 3 {
+		t.Errorf("proposal not updated %+v", a)
+	}
+	if aVotes, err = db.ProposalTicketVotes(a.Token); err != nil || len(aVotes.Votes) != 3 {
+		t.Errorf("ticket votes not updated %+v (%v)", aVotes, err)
+	}
+	c, err = db.ProposalByToken("c3c3c3c3c3c3c3c3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.VoteStatus != ticketvotev1.VoteStatusAuthorized || c.CommentsCount != 1 {
+		t.Errorf("proposal not updated %+v", c)
+	}
+}
+
+func TestProposalTurnout(t *testing.T) {
+	votes := &pitypes.ProposalTicketVotes{
+		Token:    "a1a1a1a1a1a1a1a1",
+		Eligible: []string{"t1", "t2", "t3", "t4", "t5"},
+		Votes: []pitypes.TicketVote{
+			{Ticket: "t1", Choice: ticketvotev1.VoteOptionIDApprove},
+			{Ticket: "t2", Choice: ticketvotev1.VoteOptionIDReject},
+			{Ticket: "t4", Choice: ticketvotev1.VoteOptionIDApprove},
+		},
+	}
+	// With 10 blocks a day, t1 and t2 are under a week old when the vote
+	// starts at block 1000, t3 is 50 days old, and t5 is not known.
+	tickets := map[string]*pitypes.TicketInfo{
+		"t1": {PurchaseHeight: 990, VSP: "one"},
+		"t2": {PurchaseHeight: 950, VSP: "one"},
+		"t3": {PurchaseHeight: 500},
+		"t4": {PurchaseHeight: 100, VSP: "two"},
+	}
+	turnout := votes.Turnout(1000, tickets, 10)
+	if turnout.Eligible != 5 || turnout.Voted != 3 || turnout.Yes != 2 || turnout.No != 1 ||
+		turnout.Turnout != 0.6 {
+		t.Errorf("unexpected turnout %+v", turnout)
+	}
+
+	group := func(g *pitypes.VoteGroup) string {
+		return fmt.Sprintf("%s %d/%d/%d", g.Group, g.Eligible, g.Yes, g.No)
+	}
+	var byAge, byVSP []string
+	for _, g := range turnout.ByAge {
+		byAge = append(byAge, group(g))
+	}
+	for _, g := range turnout.ByVSP {
+		byVSP = append(byVSP, group(g))
+	}
+	wantAge := []string{"< 7 days 2/1/1", "30-90 days 1/0/0", "90+ days 1/1/0", "unknown 1/0/0"}
+	if !reflect.DeepEqual(byAge, wantAge) {
+		t.Errorf("expected age groups %v, got %v", wantAge, byAge)
+	}
+	wantVSP := []string{"one 2/1/1", "none 1/0/0", "two 1/1/0", "unknown 1/0/0"}
+	if !reflect.DeepEqual(byVSP, wantVSP) {
+		t.Errorf("expected VSP groups %v, got %v", wantVSP, byVSP)
+	}
+
+	if v := votes.TicketVote("t4", 1000, tickets["t4"]); !v.Eligible || v.Choice != "yes" || v.Age != 900 {
+		t.Errorf("unexpected ticket vote %+v", v)
+	}
+	if v := votes.TicketVote("t3", 1000, tickets["t3"]); !v.Eligible || v.Choice != "" {
+		t.Errorf("unexpected ticket vote %+v", v)
+	}
+	if v := votes.TicketVote("t6", 1000, nil); v.Eligible {
+		t.Errorf("unexpected ticket vote %+v", v)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package politeia
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	commentsv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	recordsv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	piclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// NewServer creates an http.Handler serving the politeiawww v1 routes used by
+// a ProposalsDB from src, under the /api path. With an Archive, it serves
+// recorded politeia replies, e.g. as a local fixture server for a ProposalsDB
+// created with NewProposalsDB.
+func NewServer(src Source) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api"+recordsv1.APIRoute+recordsv1.RouteInventoryOrdered,
+		serveRoute(src.RecordInventoryOrdered))
+	mux.Handle("/api"+recordsv1.APIRoute+recordsv1.RouteDetails,
+		serveRoute(func(d recordsv1.Details) (*recordsv1.DetailsReply, error) {
+			record, err := src.RecordDetails(d)
+			if err != nil {
+				return nil, err
+			}
+			return &recordsv1.DetailsReply{Record: *record}, nil
+		}))
+	mux.Handle("/api"+commentsv1.APIRoute+commentsv1.RouteCount,
+		serveRoute(src.CommentCount))
+	mux.Handle("/api"+ticketvotev1.APIRoute+ticketvotev1.RouteSummaries,
+		serveRoute(src.TicketVoteSummaries))
+	mux.Handle("/api"+ticketvotev1.APIRoute+ticketvotev1.RouteDetails,
+		serveRoute(src.TicketVoteDetails))
+	mux.Handle("/api"+ticketvotev1.APIRoute+ticketvotev1.RouteResults,
+		serveRoute(src.TicketVoteResults))
+	return mux
+}
+
+// serveRoute creates a handler of the POST requests of a politeiawww route
+// that decodes the request, and encodes the reply from handle.
+func serveRoute[Req, Reply any](handle func(Req) (Reply, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		var req Req
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorReply(w, http.StatusBadRequest, err)
+			return
+		}
+		reply, err := handle(req)
+		if errors.Is(err, os.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			writeErrorReply(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err = json.NewEncoder(w).Encode(reply); err != nil {
+			log.Warnf("Failed to encode a politeia reply: %v", err)
+		}
+	})
+}
+
+// writeErrorReply writes a politeiawww error reply with the error as context.
+func writeErrorReply(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(piclient.ErrorReply{ErrorContext: err.Error()})
+}
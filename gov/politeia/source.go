@@ -0,0 +1,32 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package politeia
+
+import (
+	commentsv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	recordsv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	piclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// Source is a source of politeia records and their ticket votes. It is
+// satisfied by the politeiawww client, by an Archive of recorded politeia
+// replies, and by a Recorder.
+type Source interface {
+	RecordInventoryOrdered(recordsv1.InventoryOrdered) (*recordsv1.InventoryOrderedReply, error)
+	RecordDetails(recordsv1.Details) (*recordsv1.Record, error)
+	CommentCount(commentsv1.Count) (*commentsv1.CountReply, error)
+	TicketVoteSummaries(ticketvotev1.Summaries) (*ticketvotev1.SummariesReply, error)
+	TicketVoteDetails(ticketvotev1.Details) (*ticketvotev1.DetailsReply, error)
+	TicketVoteResults(ticketvotev1.Results) (*ticketvotev1.ResultsReply, error)
+}
+
+// Ensure at compile time that the politeiawww client is a Source.
+var _ Source = (*piclient.Client)(nil)
+
+// NewClient creates a politeiawww client for the politeia server at the root
+// URL politeiaURL.
+func NewClient(politeiaURL string) (Source, error) {
+	return piclient.New(politeiaURL+"/api", piclient.Opts{})
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package politeia
+
+import (
+	"sync"
+
+	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// TicketSource is the chain data of the tickets eligible to vote on a
+// proposal. It is satisfied by the dcrdata DB.
+type TicketSource interface {
+	GetBestBlockHash() (string, error)
+	TicketSpendAddresses(tickets, feeAddrs []string) ([]*dbtypes.TicketSpendAddress, error)
+}
+
+// VSPAttributor attributes tickets to VSPs by name. It is satisfied by the
+// dcrdata VSP tracker.
+type VSPAttributor interface {
+	// FeeAddresses are the fee addresses of all the VSPs.
+	FeeAddresses() []string
+	// VSPName gets the name of the VSP with the fee address.
+	VSPName(feeAddr string) (string, bool)
+	// TicketVSPNames gets the VSP names of the unspent tickets that are
+	// attributed to a VSP, by ticket hash.
+	TicketVSPNames(tickets []string) (map[string]string, error)
+}
+
+// ticketCache is the chain data of the tickets of the proposals, by token, as
+// of the best block with the hash tip.
+type ticketCache struct {
+	mtx     sync.Mutex
+	tip     string
+	tickets map[string]map[string]*pitypes.TicketInfo
+}
+
+// ProposalTickets gets the chain data of the tickets eligible to vote on the
+// proposal, attributing them to VSPs if vsps is not nil. Spent tickets are
+// attributed by the fee address paid by their vote or revocation, and unspent
+// tickets by the fee address they commit to. The tickets of a proposal are
+// only looked up once per best block. The returned tickets must not be
+// modified.
+func (db *ProposalsDB) ProposalTickets(votes *pitypes.ProposalTicketVotes, chain TicketSource, vsps VSPAttributor) (map[string]*pitypes.TicketInfo, error) {
+	if db == nil || db.dbP == nil {
+		return nil, errDef
+	}
+
+	tip, err := chain.GetBestBlockHash()
+	if err != nil {
+		return nil, err
+	}
+	cache := &db.ticketCache
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+	if cache.tip != tip {
+		cache.tip = tip
+		cache.tickets = make(map[string]map[string]*pitypes.TicketInfo)
+	}
+	if infos, found := cache.tickets[votes.Token]; found {
+		return infos, nil
+	}
+
+	infos, err := ticketInfos(votes.Eligible, chain, vsps)
+	if err != nil {
+		return nil, err
+	}
+	cache.tickets[votes.Token] = infos
+	return infos, nil
+}
+
+// ticketInfos gets the chain data of the tickets. See ProposalTickets.
+func ticketInfos(tickets []string, chain TicketSource, vsps VSPAttributor) (map[string]*pitypes.TicketInfo, error) {
+	var feeAddrs []string
+	if vsps != nil {
+		feeAddrs = vsps.FeeAddresses()
+	}
+	spends, err := chain.TicketSpendAddresses(tickets, feeAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]*pitypes.TicketInfo, len(spends))
+	var unspent []string
+	for _, s := range spends {
+		info := &pitypes.TicketInfo{
+			PurchaseHeight: s.BlockHeight,
+			Price:          s.Price,
+			PoolStatus:     s.PoolStatus.String(),
+		}
+		if vsps != nil {
+			if name, found := vsps.VSPName(s.FeeAddress); found {
+				info.VSP = name
+			} else if s.SpendType == dbtypes.TicketUnspent {
+				unspent = append(unspent, s.Hash)
+			}
+		}
+		infos[s.Hash] = info
+	}
+	if len(unspent) > 0 {
+		names, err := vsps.TicketVSPNames(unspent)
+		if err != nil {
+			return nil, err
+		}
+		for ticket, name := range names {
+			infos[ticket].VSP = name
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,101 @@
+package politeia
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+type ticketSourceStub struct {
+	tip    string
+	spends []*dbtypes.TicketSpendAddress
+	calls  int
+}
+
+func (s *ticketSourceStub) GetBestBlockHash() (string, error) {
+	return s.tip, nil
+}
+
+func (s *ticketSourceStub) TicketSpendAddresses(tickets, feeAddrs []string) ([]*dbtypes.TicketSpendAddress, error) {
+	s.calls++
+	return s.spends, nil
+}
+
+type vspsStub struct {
+	lookups int
+}
+
+func (v *vspsStub) FeeAddresses() []string {
+	return []string{"Dsfee"}
+}
+
+func (v *vspsStub) VSPName(feeAddr string) (string, bool) {
+	return "spent-vsp", feeAddr == "Dsfee"
+}
+
+func (v *vspsStub) TicketVSPNames(tickets []string) (map[string]string, error) {
+	v.lookups++
+	return map[string]string{"live": "live-vsp"}, nil
+}
+
+func TestProposalTickets(t *testing.T) {
+	sdb, err := storm.Open(filepath.Join(t.TempDir(), "proposals.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdb.Close()
+	db := &ProposalsDB{dbP: sdb}
+
+	chain := &ticketSourceStub{
+		tip: "tip1",
+		spends: []*dbtypes.TicketSpendAddress{
+			{Hash: "voted", SpendType: dbtypes.TicketVoted, FeeAddress: "Dsfee"},
+			{Hash: "live", SpendType: dbtypes.TicketUnspent},
+			{Hash: "solo", SpendType: dbtypes.TicketVoted},
+		},
+	}
+	vsps := &vspsStub{}
+	votes := &pitypes.ProposalTicketVotes{Token: "abc", Eligible: []string{"voted", "live", "solo"}}
+
+	tickets, err := db.ProposalTickets(votes, chain, vsps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ticket, vsp := range map[string]string{"voted": "spent-vsp", "live": "live-vsp", "solo": ""} {
+		if tickets[ticket].VSP != vsp {
+			t.Errorf("ticket %s attributed to %q, expected %q", ticket, tickets[ticket].VSP, vsp)
+		}
+	}
+
+	// The tickets are only looked up again for another proposal or block.
+	if _, err = db.ProposalTickets(votes, chain, vsps); err != nil {
+		t.Fatal(err)
+	}
+	if chain.calls != 1 || vsps.lookups != 1 {
+		t.Fatalf("tickets looked up %d times and attributed %d times in the same block", chain.calls, vsps.lookups)
+	}
+	other := &pitypes.ProposalTicketVotes{Token: "def", Eligible: votes.Eligible}
+	if _, err = db.ProposalTickets(other, chain, vsps); err != nil {
+		t.Fatal(err)
+	}
+	chain.tip = "tip2"
+	if _, err = db.ProposalTickets(votes, chain, vsps); err != nil {
+		t.Fatal(err)
+	}
+	if chain.calls != 3 {
+		t.Fatalf("tickets looked up %d times, expected 3", chain.calls)
+	}
+
+	// Without VSPs, no tickets are attributed.
+	chain.tip = "tip3"
+	tickets, err = db.ProposalTickets(votes, chain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tickets["voted"].VSP != "" || tickets["live"].VSP != "" {
+		t.Fatalf("tickets attributed without VSPs")
+	}
+}
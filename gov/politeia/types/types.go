@@ -4,6 +4,9 @@
 package types
 
 import (
+	"fmt"
+	"sort"
+
 	recordsv1 "github.com/decred/politeia/politeiawww/api/records/v1"
 	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 )
@@ -23,7 +26,11 @@ type ProposalRecord struct {
 	Username  string                  `json:"username"`
 
 	// Pi metadata
-	Name string `json:"name"`
+	Name      string `json:"name"`
+	Amount    uint64 `json:"amount"`    // funding amount in cents
+	StartDate int64  `json:"startdate"` // funding start date, unix time
+	EndDate   int64  `json:"enddate"`   // estimated funding end date, unix time
+	Domain    string `json:"domain"`
 
 	// User metadata
 	UserID string `json:"userid"`
@@ -65,6 +72,7 @@ type ProposalChartData struct {
 // IsEqual compares data between the two ProposalRecord structs passed.
 func (pi *ProposalRecord) IsEqual(b ProposalRecord) bool {
 	if pi.Token != b.Token || pi.Name != b.Name || pi.State != b.State ||
+		pi.Amount != b.Amount || pi.StartDate != b.StartDate || pi.EndDate != b.EndDate ||
 		pi.Status != b.Status || pi.StatusChangeMsg != b.StatusChangeMsg ||
 		pi.CommentsCount != b.CommentsCount || pi.Timestamp != b.Timestamp ||
 		pi.VoteStatus != b.VoteStatus || pi.TotalVotes != b.TotalVotes ||
@@ -133,3 +141,201 @@ func (pi *ProposalRecord) Metadata(tip, targetBlockTime int64) *ProposalMetadata
 	meta.ProposalStatusDesc = recordsv1.RecordStatuses[pi.Status]
 	return meta
 }
+
+// ProposalTicketVotes holds the tickets that were eligible to vote on a
+// proposal, and the votes they cast. It is saved to stormdb apart from the
+// ProposalRecord, as it holds the full snapshot of the ticket pool.
+type ProposalTicketVotes struct {
+	Token    string       `json:"token" storm:"id"`
+	Eligible []string     `json:"eligible"`
+	Votes    []TicketVote `json:"votes"`
+}
+
+// TicketVote is the vote cast by a ticket on a proposal.
+type TicketVote struct {
+	Ticket    string `json:"ticket"`
+	Choice    string `json:"choice"` // vote option ID, i.e. yes or no
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TicketInfo is the chain data of a ticket needed to group the votes on a
+// proposal.
+type TicketInfo struct {
+	PurchaseHeight int64   `json:"purchase_height"`
+	Price          float64 `json:"price"`
+	PoolStatus     string  `json:"pool_status"`
+	VSP            string  `json:"vsp,omitempty"`
+}
+
+// ProposalTurnout is the turnout of the vote on a proposal as a share of the
+// eligible tickets, and the vote choices of groups of the eligible tickets.
+type ProposalTurnout struct {
+	Token       string       `json:"token"`
+	StartHeight int64        `json:"start_height"`
+	Eligible    int64        `json:"eligible"`
+	Voted       int64        `json:"voted"`
+	Turnout     float64      `json:"turnout"`
+	Yes         int64        `json:"yes"`
+	No          int64        `json:"no"`
+	ByAge       []*VoteGroup `json:"by_age"`
+	ByVSP       []*VoteGroup `json:"by_vsp"`
+}
+
+// VoteGroup counts the eligible tickets of a group and their vote choices.
+type VoteGroup struct {
+	Group    string  `json:"group"`
+	Eligible int64   `json:"eligible"`
+	Yes      int64   `json:"yes"`
+	No       int64   `json:"no"`
+	Turnout  float64 `json:"turnout"`
+}
+
+// ProposalTicketVote is how a ticket voted on a proposal. A ticket that was
+// eligible but did not vote has no choice.
+type ProposalTicketVote struct {
+	Token     string `json:"token"`
+	Ticket    string `json:"ticket"`
+	Eligible  bool   `json:"eligible"`
+	Choice    string `json:"choice,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	// Age is the ticket's age in blocks when the vote started.
+	Age int64 `json:"age"`
+	*TicketInfo
+}
+
+// ticketAgeDays are the upper bounds in days of the ticket age groups, except
+// for the last group of the oldest tickets.
+var ticketAgeDays = []int64{7, 30, 90}
+
+// Group names of the eligible tickets that are not known by the chain data,
+// and that are not attributed to a VSP.
+const (
+	UnknownGroup = "unknown"
+	NoVSPGroup   = "none"
+)
+
+// ageGroup gets the order and name of the age group of a ticket of the age in
+// blocks.
+func ageGroup(age, blocksPerDay int64) (int, string) {
+	var lower int64
+	for i, days := range ticketAgeDays {
+		if age < days*blocksPerDay {
+			if i == 0 {
+				return i, fmt.Sprintf("< %d days", days)
+			}
+			return i, fmt.Sprintf("%d-%d days", lower, days)
+		}
+		lower = days
+	}
+	return len(ticketAgeDays), fmt.Sprintf("%d+ days", lower)
+}
+
+// Turnout tallies the votes on the proposal, whose vote started at the block
+// startHeight, by the age of the eligible tickets when the vote started, and
+// by their VSP. The chain data of the tickets is from tickets, and the
+// eligible tickets that are missing from it are counted in the UnknownGroup.
+func (tv *ProposalTicketVotes) Turnout(startHeight int64, tickets map[string]*TicketInfo,
+	blocksPerDay int64) *ProposalTurnout {
+	choices := make(map[string]string, len(tv.Votes))
+	for _, v := range tv.Votes {
+		choices[v.Ticket] = v.Choice
+	}
+
+	t := &ProposalTurnout{
+		Token:       tv.Token,
+		StartHeight: startHeight,
+		Eligible:    int64(len(tv.Eligible)),
+		ByAge:       []*VoteGroup{},
+		ByVSP:       []*VoteGroup{},
+	}
+	byAge := make(map[string]*VoteGroup)
+	byVSP := make(map[string]*VoteGroup)
+	ageOrder := map[string]int{UnknownGroup: len(ticketAgeDays) + 1}
+	group := func(groups map[string]*VoteGroup, list *[]*VoteGroup, name string) *VoteGroup {
+		g, found := groups[name]
+		if !found {
+			g = &VoteGroup{Group: name}
+			groups[name] = g
+			*list = append(*list, g)
+		}
+		return g
+	}
+	for _, ticket := range tv.Eligible {
+		ageName, vspName := UnknownGroup, UnknownGroup
+		if info := tickets[ticket]; info != nil {
+			var order int
+			order, ageName = ageGroup(startHeight-info.PurchaseHeight, blocksPerDay)
+			ageOrder[ageName] = order
+			vspName = NoVSPGroup
+			if info.VSP != "" {
+				vspName = info.VSP
+			}
+		}
+		choice := choices[ticket]
+		for _, g := range []*VoteGroup{group(byAge, &t.ByAge, ageName), group(byVSP, &t.ByVSP, vspName)} {
+			g.Eligible++
+			switch choice {
+			case ticketvotev1.VoteOptionIDApprove:
+				g.Yes++
+			case ticketvotev1.VoteOptionIDReject:
+				g.No++
+			}
+		}
+		switch choice {
+		case ticketvotev1.VoteOptionIDApprove:
+			t.Yes++
+		case ticketvotev1.VoteOptionIDReject:
+			t.No++
+		}
+	}
+	t.Voted = t.Yes + t.No
+	if t.Eligible > 0 {
+		t.Turnout = float64(t.Voted) / float64(t.Eligible)
+	}
+	for _, groups := range [][]*VoteGroup{t.ByAge, t.ByVSP} {
+		for _, g := range groups {
+			g.Turnout = float64(g.Yes+g.No) / float64(g.Eligible)
+		}
+	}
+
+	// The age groups are ordered from the youngest tickets, and the VSPs by
+	// their eligible tickets.
+	sort.Slice(t.ByAge, func(i, j int) bool {
+		return ageOrder[t.ByAge[i].Group] < ageOrder[t.ByAge[j].Group]
+	})
+	sort.Slice(t.ByVSP, func(i, j int) bool {
+		if t.ByVSP[i].Eligible == t.ByVSP[j].Eligible {
+			return t.ByVSP[i].Group < t.ByVSP[j].Group
+		}
+		return t.ByVSP[i].Eligible > t.ByVSP[j].Eligible
+	})
+	return t
+}
+
+// TicketVote gets how the ticket voted on the proposal, whose vote started at
+// the block startHeight. The ticket's chain data, info, may be nil if it is
+// not known.
+func (tv *ProposalTicketVotes) TicketVote(ticket string, startHeight int64, info *TicketInfo) *ProposalTicketVote {
+	v := &ProposalTicketVote{
+		Token:      tv.Token,
+		Ticket:     ticket,
+		TicketInfo: info,
+	}
+	for _, t := range tv.Eligible {
+		if t == ticket {
+			v.Eligible = true
+			break
+		}
+	}
+	for _, vote := range tv.Votes {
+		if vote.Ticket == ticket {
+			v.Choice = vote.Choice
+			v.Timestamp = vote.Timestamp
+			break
+		}
+	}
+	if info != nil {
+		v.Age = startHeight - info.PurchaseHeight
+	}
+	return v
+}
@@ -17,6 +17,7 @@ import (
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
 
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/metrics"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
@@ -353,6 +354,7 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 		p.inventory.LikelyMineable.Count++
 	}
 	p.inventory.FormattedTotalSize = exptypes.BytesString(uint64(p.inventory.TotalSize))
+	setTxnMetrics(p.inventory)
 	p.inventory.Unlock()
 	p.mtx.RUnlock()
 
@@ -372,6 +374,17 @@ func (p *MempoolMonitor) hubSend(sig pstypes.HubSignal, msg interface{}, timeout
 	}
 }
 
+// setTxnMetrics records the number of transactions of each type in the
+// mempool inventory.
+func setTxnMetrics(inv *exptypes.MempoolInfo) {
+	metrics.MempoolTxns.WithLabelValues("all").Set(float64(inv.NumAll))
+	metrics.MempoolTxns.WithLabelValues("regular").Set(float64(inv.NumRegular))
+	metrics.MempoolTxns.WithLabelValues("tickets").Set(float64(inv.NumTickets))
+	metrics.MempoolTxns.WithLabelValues("votes").Set(float64(inv.NumVotes))
+	metrics.MempoolTxns.WithLabelValues("revocations").Set(float64(inv.NumRevokes))
+	metrics.MempoolTxns.WithLabelValues("tspends").Set(float64(inv.NumTSpends))
+}
+
 // Refresh collects mempool data, resets counters ticket counters and the timer,
 // but does not dispatch the MempoolDataSavers.
 func (p *MempoolMonitor) Refresh() (*StakeData, []exptypes.MempoolTx, *exptypes.MempoolInfo, error) {
@@ -408,6 +421,7 @@ func (p *MempoolMonitor) Refresh() (*StakeData, []exptypes.MempoolTx, *exptypes.
 	}
 	p.inventory = inventory
 	p.txnsStore = txnsStore
+	setTxnMetrics(inventory)
 	p.mtx.Unlock()
 
 	p.addrMap.mtx.Lock()
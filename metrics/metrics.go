@@ -0,0 +1,149 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package metrics defines the Prometheus collectors used to export the runtime
+// health of dcrdata, and the http.Handler that serves them. The collectors are
+// always registered and updated, but they are only exposed if the application
+// mounts Handler.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dcrdata"
+
+// Cache lookup results used as the "result" label of CacheLookups.
+const (
+	Hit  = "hit"
+	Miss = "miss"
+)
+
+var (
+	// BlockStageDuration is the time spent in each stage of processing a new
+	// block, labeled by stage.
+	BlockStageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "block",
+		Name:      "stage_duration_seconds",
+		Help:      "Time spent in each stage of processing a new block.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16), // 1ms - 33s
+	}, []string{"stage"})
+
+	// DBQueryDuration is the latency of the main chain DB queries, labeled by
+	// the ChainDB method.
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of chain DB queries by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16), // 0.5ms - 16s
+	}, []string{"method"})
+
+	// RPCDuration is the latency of dcrd RPCs, labeled by RPC method.
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "dcrd",
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of dcrd RPCs by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14), // 0.5ms - 4s
+	}, []string{"method"})
+
+	// CacheLookups counts lookups in the address, chart and pool info caches,
+	// labeled by cache and result (Hit or Miss).
+	CacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+
+	// WebsocketClients is the number of connected websocket clients, labeled
+	// by hub.
+	WebsocketClients = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "clients",
+		Help:      "Connected websocket clients by hub.",
+	}, []string{"hub"})
+
+	// WebsocketSendQueue is the number of messages queued for the websocket
+	// clients of a hub, sampled after each hub event, labeled by hub.
+	WebsocketSendQueue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "send_queue_depth",
+		Help:      "Messages queued for the websocket clients of a hub.",
+	}, []string{"hub"})
+
+	// MempoolTxns is the number of transactions in mempool, labeled by
+	// transaction type.
+	MempoolTxns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "mempool",
+		Name:      "transactions",
+		Help:      "Transactions in mempool by type.",
+	}, []string{"type"})
+
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		BlockStageDuration,
+		DBQueryDuration,
+		RPCDuration,
+		CacheLookups,
+		WebsocketClients,
+		WebsocketSendQueue,
+		MempoolTxns,
+	)
+}
+
+// Handler returns an http.Handler that serves the registered metrics in the
+// Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RegisterExchangeFailures registers a counter of the failed refreshes of the
+// exchange with the given token. The exchanges module does not import this
+// package, so the count is read from failures when the metrics are gathered.
+// If a counter is already registered for the token, it is kept, and the error
+// is nil.
+func RegisterExchangeFailures(token string, failures func() uint64) error {
+	err := registry.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   "exchange",
+		Name:        "refresh_failures_total",
+		Help:        "Failed exchange refreshes by token.",
+		ConstLabels: prometheus.Labels{"token": token},
+	}, func() float64 { return float64(failures()) }))
+	if errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+		return nil
+	}
+	return err
+}
+
+// ObserveSince records the time elapsed since start in the histogram of hv
+// with the given label value.
+func ObserveSince(hv *prometheus.HistogramVec, label string, start time.Time) {
+	hv.WithLabelValues(label).Observe(time.Since(start).Seconds())
+}
+
+// CacheLookup counts a lookup in the named cache.
+func CacheLookup(cache string, hit bool) {
+	result := Miss
+	if hit {
+		result = Hit
+	}
+	CacheLookups.WithLabelValues(cache, result).Inc()
+}
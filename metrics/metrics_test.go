@@ -0,0 +1,45 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveSince(DBQueryDuration, "BlockHeight", time.Now())
+	CacheLookup("charts", true)
+	CacheLookup("charts", false)
+	CacheLookup("charts", false)
+	if err := RegisterExchangeFailures("binance", func() uint64 { return 3 }); err != nil {
+		t.Fatal(err)
+	}
+	// A second counter for the token is not registered.
+	if err := RegisterExchangeFailures("binance", func() uint64 { return 5 }); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`dcrdata_db_query_duration_seconds_count{method="BlockHeight"} 1`,
+		`dcrdata_cache_lookups_total{cache="charts",result="hit"} 1`,
+		`dcrdata_cache_lookups_total{cache="charts",result="miss"} 2`,
+		`dcrdata_exchange_refresh_failures_total{token="binance"} 3`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output does not contain %q", want)
+		}
+	}
+}
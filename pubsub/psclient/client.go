@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/decred/dcrdata/v8/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
 	pubsub "github.com/decred/dcrdata/v8/pubsub"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
@@ -295,6 +296,12 @@ func (c *Client) receiver() {
 		case *pstypes.AddressMessage:
 			log.Debugf("Message (%s): AddressMessage(address=%s, txHash=%s)",
 				resp.EventId, m.Address, m.TxHash)
+		case *dbtypes.TreasurySpendTally:
+			log.Debugf("Message (%s): TreasurySpendTally(hash=%s, status=%s)",
+				resp.EventId, m.Hash, m.Status)
+		case *pstypes.PriceAlert:
+			log.Debugf("Message (%s): PriceAlert(rule=%s, message=%s)",
+				resp.EventId, m.Rule, m.Message)
 		default:
 			log.Debugf("Message of type %v unhandled.", resp.EventId)
 			continue
@@ -495,6 +502,14 @@ func DecodeMsg(msg *pstypes.WebSocketMessage) (interface{}, error) {
 		var mpshort exptypes.MempoolShort
 		err := json.Unmarshal(msg.Message, &mpshort)
 		return &mpshort, err
+	case "tspend":
+		var tally dbtypes.TreasurySpendTally
+		err := json.Unmarshal(msg.Message, &tally)
+		return &tally, err
+	case "pricealert":
+		var alert pstypes.PriceAlert
+		err := json.Unmarshal(msg.Message, &alert)
+		return &alert, err
 	default:
 		return nil, fmt.Errorf("unrecognized event type")
 	}
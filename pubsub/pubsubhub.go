@@ -508,6 +508,32 @@ loop:
 
 			pushMsg.Message = buff.Bytes()
 
+		case sigTSpend:
+			tally, ok := sig.Msg.(*dbtypes.TreasurySpendTally)
+			if !ok {
+				log.Errorf("sigTSpend did not store a *TreasurySpendTally in Msg.")
+				continue loop
+			}
+			err := enc.Encode(tally)
+			if err != nil {
+				log.Warnf("Encode(TreasurySpendTally) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
+		case sigPriceAlert:
+			alert, ok := sig.Msg.(*pstypes.PriceAlert)
+			if !ok {
+				log.Errorf("sigPriceAlert did not store a *PriceAlert in Msg.")
+				continue loop
+			}
+			err := enc.Encode(alert)
+			if err != nil {
+				log.Warnf("Encode(PriceAlert) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
 		case sigByeNow:
 			pushMsg.Message = []byte(`"The dcrdata server is shutting down. Bye!"`)
 			log.Tracef("Sending %v", string(pushMsg.Message))
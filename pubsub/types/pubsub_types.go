@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/decred/base58"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
 )
 
@@ -85,6 +86,18 @@ func (am AddressMessage) String() string {
 
 type TxList []*exptypes.MempoolTx
 
+// PriceAlert is a triggered exchange alert rule. See exchanges.Alert.
+type PriceAlert struct {
+	Rule      string  `json:"rule"`
+	Kind      string  `json:"kind"`
+	Token     string  `json:"token,omitempty"`
+	Market    string  `json:"market,omitempty"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Message   string  `json:"message"`
+	Stamp     int64   `json:"stamp"`
+}
+
 type HangUp struct{}
 
 type HubSignal int
@@ -105,6 +118,8 @@ const (
 	SigNewTxs
 	SigAddressTx
 	SigSyncStatus
+	SigTSpend
+	SigPriceAlert
 	SigByeNow
 	SigUnknown
 )
@@ -116,6 +131,8 @@ var Subscriptions = map[string]HubSignal{
 	"newtxs":         SigNewTxs,
 	"address":        SigAddressTx,
 	"blockchainSync": SigSyncStatus,
+	"tspend":         SigTSpend,
+	"pricealert":     SigPriceAlert,
 }
 
 // Event type field for an event.
@@ -133,6 +150,8 @@ var eventIDs = map[HubSignal]string{
 	SigNewTxs:           "newtxs",
 	SigAddressTx:        "address",
 	SigSyncStatus:       "blockchainSync",
+	SigTSpend:           "tspend",
+	SigPriceAlert:       "pricealert",
 	SigByeNow:           "bye",
 	SigUnknown:          "unknown",
 }
@@ -203,6 +222,10 @@ func (m HubMessage) IsValid() bool {
 		_, ok = m.Msg.(*exptypes.MempoolTx)
 	case SigNewTxs:
 		_, ok = m.Msg.([]*exptypes.MempoolTx)
+	case SigTSpend:
+		_, ok = m.Msg.(*dbtypes.TreasurySpendTally)
+	case SigPriceAlert:
+		_, ok = m.Msg.(*PriceAlert)
 	}
 
 	return ok
@@ -225,6 +248,12 @@ func (m HubMessage) String() string {
 	case SigNewTxs:
 		txs := m.Msg.([]*exptypes.MempoolTx)
 		sigStr += ":len=" + strconv.Itoa(len(txs))
+	case SigTSpend:
+		tally := m.Msg.(*dbtypes.TreasurySpendTally)
+		sigStr += ":" + tally.Hash
+	case SigPriceAlert:
+		alert := m.Msg.(*PriceAlert)
+		sigStr += ":" + alert.Rule
 	}
 
 	return sigStr
@@ -3,6 +3,7 @@ package types
 import (
 	"testing"
 
+	"github.com/decred/dcrdata/v8/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
 )
 
@@ -56,6 +57,16 @@ func TestHubMessage_String(t *testing.T) {
 			HubMessage{Signal: SigNewTxs, Msg: []*exptypes.MempoolTx{{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}}},
 			"newtxs:len=1",
 		},
+		{
+			"ok tspend",
+			HubMessage{Signal: SigTSpend, Msg: &dbtypes.TreasurySpendTally{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}},
+			"tspend:4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7",
+		},
+		{
+			"ok pricealert",
+			HubMessage{Signal: SigPriceAlert, Msg: &PriceAlert{Rule: "index-move"}},
+			"pricealert:index-move",
+		},
 		{
 			"wrong Msg type newtx",
 			HubMessage{Signal: SigNewTx, Msg: exptypes.MempoolTx{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}},
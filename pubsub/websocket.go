@@ -10,7 +10,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/decred/dcrdata/v8/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/metrics"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 )
 
@@ -45,6 +47,8 @@ var (
 	sigNewTxs           = pstypes.SigNewTxs
 	sigAddressTx        = pstypes.SigAddressTx
 	sigSyncStatus       = pstypes.SigSyncStatus
+	sigTSpend           = pstypes.SigTSpend
+	sigPriceAlert       = pstypes.SigPriceAlert
 	sigByeNow           = pstypes.SigByeNow
 )
 
@@ -252,6 +256,17 @@ func (wsh *WebsocketHub) NumClients() int {
 
 func (wsh *WebsocketHub) setNumClients(n int) {
 	wsh.numClients.Store(n)
+	metrics.WebsocketClients.WithLabelValues("pubsub").Set(float64(n))
+}
+
+// setSendQueueDepth records the number of messages queued for all clients.
+// This should only be called from the run loop.
+func (wsh *WebsocketHub) setSendQueueDepth() {
+	var n int
+	for spoke := range wsh.clients {
+		n += len(*spoke)
+	}
+	metrics.WebsocketSendQueue.WithLabelValues("pubsub").Set(float64(n))
 }
 
 // registerClient should only be called from the run loop.
@@ -410,6 +425,8 @@ func (wsh *WebsocketHub) Run() {
 	}
 
 	for {
+		wsh.setSendQueueDepth()
+
 		//events:
 		select {
 		case hubMsg, ok := <-wsh.HubRelay:
@@ -475,6 +492,22 @@ func (wsh *WebsocketHub) Run() {
 				continue // break events
 			case sigSyncStatus:
 				// TODO
+			case sigTSpend:
+				tally, ok := hubMsg.Msg.(*dbtypes.TreasurySpendTally)
+				if !ok || tally == nil {
+					log.Errorf("sigTSpend did not store a *TreasurySpendTally in Msg.")
+					continue
+				}
+				log.Debugf("Signaling the vote tally of tspend %s to %d websocket clients.",
+					tally.Hash, clientsCount)
+			case sigPriceAlert:
+				alert, ok := hubMsg.Msg.(*pstypes.PriceAlert)
+				if !ok || alert == nil {
+					log.Errorf("sigPriceAlert did not store a *PriceAlert in Msg.")
+					continue
+				}
+				log.Debugf("Signaling price alert %s to %d websocket clients.",
+					alert.Rule, clientsCount)
 			case sigByeNow:
 				log.Infof("Warning all %d clients of impending hang-up.", len(wsh.clients))
 				// Broadcast "bye" to all clients (not a subscription).
@@ -7,11 +7,14 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
 	"github.com/decred/dcrd/rpcclient/v8"
 	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v8/metrics"
 )
 
 const (
@@ -117,6 +120,7 @@ func (p *BlockPrefetchClient) GetBlockData(ctx context.Context, hash *chainhash.
 	// retarget.
 	if p.current.hash == *hash /*p.haveBlockHash(*hash)*/ {
 		p.hits++
+		metrics.CacheLookup("prefetch", true)
 		// Return the requested block.
 		defer p.Unlock()
 		return p.current.msgBlock, p.current.headerResult, nil
@@ -130,11 +134,13 @@ func (p *BlockPrefetchClient) GetBlockData(ctx context.Context, hash *chainhash.
 		msgBlock, headerResult := p.next.msgBlock, p.next.headerResult
 		go retargetAndUnlock(p.next.headerResult.NextHash)
 		p.hits++
+		metrics.CacheLookup("prefetch", true)
 		// Return the requested block.
 		return msgBlock, headerResult, nil
 	}
 
 	p.misses++
+	metrics.CacheLookup("prefetch", false)
 
 	// Immediately retrieve msgBlock and header verbose result while fetcher is
 	// blocked by the Mutex.
@@ -185,6 +191,7 @@ func (p *BlockPrefetchClient) GetBlockHeaderVerbose(ctx context.Context, hash *c
 
 // GetBlockHash is a passthrough to the client.
 func (p *BlockPrefetchClient) GetBlockHash(ctx context.Context, blockHeight int64) (*chainhash.Hash, error) {
+	defer observeRPC("getblockhash", time.Now())
 	return p.f.GetBlockHash(ctx, blockHeight)
 }
 
@@ -201,15 +208,19 @@ func (p *BlockPrefetchClient) storeNext(msgBlock *wire.MsgBlock, headerResult *c
 }
 
 func (p *BlockPrefetchClient) retrieveBlockAndHeaderResult(ctx context.Context, hash *chainhash.Hash) (*wire.MsgBlock, *chainjson.GetBlockHeaderVerboseResult, error) {
+	start := time.Now()
 	msgBlock, err := p.f.GetBlock(ctx, hash)
 	if err != nil {
 		return nil, nil, err
 	}
+	observeRPC("getblock", start)
 
+	start = time.Now()
 	headerResult, err := p.f.GetBlockHeaderVerbose(ctx, hash)
 	if err != nil {
 		return nil, nil, err
 	}
+	observeRPC("getblockheader", start)
 
 	return msgBlock, headerResult, nil
 }
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
@@ -19,6 +20,7 @@ import (
 	"github.com/decred/dcrd/rpcclient/v8"
 	"github.com/decred/dcrd/wire"
 
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/semver"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
@@ -64,11 +66,43 @@ func (cl *AsyncTxClient) GetRawTransactionPromise(ctx context.Context, txHash *c
 
 var _ txhelpers.TransactionPromiseGetter = (*AsyncTxClient)(nil)
 
+// GetRawMempoolVerbose is a passthrough to the client that records the RPC
+// latency.
+func (cl *AsyncTxClient) GetRawMempoolVerbose(ctx context.Context, txType chainjson.GetRawMempoolTxTypeCmd) (map[string]chainjson.GetRawMempoolVerboseResult, error) {
+	defer observeRPC("getrawmempool", time.Now())
+	return cl.Client.GetRawMempoolVerbose(ctx, txType)
+}
+
+// GetRawTransaction is a passthrough to the client that records the RPC
+// latency.
+func (cl *AsyncTxClient) GetRawTransaction(ctx context.Context, txHash *chainhash.Hash) (*dcrutil.Tx, error) {
+	defer observeRPC("getrawtransaction", time.Now())
+	return cl.Client.GetRawTransaction(ctx, txHash)
+}
+
+// GetRawTransactionVerbose is a passthrough to the client that records the RPC
+// latency.
+func (cl *AsyncTxClient) GetRawTransactionVerbose(ctx context.Context, txHash *chainhash.Hash) (*chainjson.TxRawResult, error) {
+	defer observeRPC("getrawtransaction", time.Now())
+	return cl.Client.GetRawTransactionVerbose(ctx, txHash)
+}
+
+// GetBestBlock is a passthrough to the client that records the RPC latency.
+func (cl *AsyncTxClient) GetBestBlock(ctx context.Context) (*chainhash.Hash, int64, error) {
+	defer observeRPC("getbestblock", time.Now())
+	return cl.Client.GetBestBlock(ctx)
+}
+
 // NewAsyncTxClient creates an AsyncTxClient from a rpcclient.Client.
 func NewAsyncTxClient(c *rpcclient.Client) *AsyncTxClient {
 	return &AsyncTxClient{c}
 }
 
+// observeRPC records the latency of the named dcrd RPC started at start.
+func observeRPC(method string, start time.Time) {
+	metrics.ObserveSince(metrics.RPCDuration, method, start)
+}
+
 // Any of the following dcrd RPC API versions are deemed compatible with
 // dcrdata.
 var compatibleChainServerAPIs = []semver.Semver{
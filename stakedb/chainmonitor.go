@@ -8,10 +8,12 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
 
@@ -40,10 +42,12 @@ func (p *ChainMonitor) ConnectBlock(header *wire.BlockHeader) (err error) {
 	defer p.mtx.Unlock()
 
 	// Extend main chain
+	tStart := time.Now()
 	block, err := p.db.ConnectBlockHash(&hash)
 	if err != nil {
 		return err
 	}
+	metrics.ObserveSince(metrics.BlockStageDuration, "stakedb", tStart)
 
 	log.Infof("Connected block %d to stake DB.", block.Height())
 	return nil
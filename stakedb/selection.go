@@ -0,0 +1,225 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package stakedb
+
+import (
+	"fmt"
+	"math"
+
+	apitypes "github.com/decred/dcrdata/v8/api/types"
+)
+
+// waitBins is the number of equal probability bins of the vote wait
+// distribution used for the goodness of fit.
+const waitBins = 10
+
+// SelectionStats computes the ticket selection statistics of the blocks
+// [start, end] with the chain's parameters. See TicketPool.SelectionStats.
+func (db *StakeDatabase) SelectionStats(start, end int64) (*apitypes.TicketSelectionWindow, error) {
+	return db.PoolDB.SelectionStats(start, end, int64(db.params.TicketsPerBlock),
+		int64(db.params.TicketExpiry), db.params.StakeValidationHeight)
+}
+
+// SelectionStats compares the tickets that left the live pool in the blocks
+// [start, end] with random selection. The first ticketsPerBlock tickets leaving
+// the pool in a block from the stake validation height svh onward are that
+// block's winners, and the rest expired. A ticket's wait is the number of
+// lotteries it was in, so a ticket that entered the pool at height e and was
+// selected at h waited h-e blocks. The expected waits are geometric with the
+// mean per-ticket selection probability of the window, truncated at expiry.
+func (tp *TicketPool) SelectionStats(start, end, ticketsPerBlock, expiry, svh int64) (*apitypes.TicketSelectionWindow, error) {
+	if start < 1 || start > end {
+		return nil, fmt.Errorf("invalid height range [%d, %d]", start, end)
+	}
+
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	if end > tp.tip {
+		return nil, fmt.Errorf("block height %d is not connected yet, tip is %d", end, tp.tip)
+	}
+	pool, err := tp.poolWithEntries(start - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &apitypes.TicketSelectionWindow{
+		StartHeight: start,
+		EndHeight:   end,
+	}
+	var lotteries, poolSizes int64
+	var probSum float64
+	var waits []int64
+	for h := start; h <= end; h++ {
+		diff := &tp.diffs[h-1]
+		if h >= svh && len(pool) > 0 {
+			lotteries++
+			poolSizes += int64(len(pool))
+			probSum += math.Min(float64(ticketsPerBlock)/float64(len(pool)), 1)
+
+			winners := diff.Out
+			if int64(len(winners)) > ticketsPerBlock {
+				winners = winners[:ticketsPerBlock]
+			}
+			for _, ticket := range winners {
+				entry, ok := pool[ticket]
+				if !ok {
+					continue
+				}
+				// There are no lotteries before the stake validation height.
+				if entry < svh-1 {
+					entry = svh - 1
+				}
+				waits = append(waits, h-entry)
+			}
+			stats.Expired += int64(len(diff.Out) - len(winners))
+		}
+		applySnapshotDiff(pool, diff, h)
+	}
+	if lotteries == 0 {
+		return stats, nil
+	}
+
+	stats.Selected = int64(len(waits))
+	stats.MeanPoolSize = float64(poolSizes) / float64(lotteries)
+	p := probSum / float64(lotteries)
+	stats.SelectionProbability = p
+
+	// cdf is the probability of a ticket being selected within k lotteries.
+	q := 1 - p
+	cdf := func(k int64) float64 {
+		return 1 - math.Pow(q, float64(k))
+	}
+	selectProb := cdf(expiry)
+	expireProb := 1 - selectProb
+	stats.ExpectedMeanWait = (1/p - math.Pow(q, float64(expiry))*(1/p+float64(expiry))) / selectProb
+
+	// Bin the waits into bins of roughly equal probability.
+	left := stats.Selected + stats.Expired
+	lo := int64(1)
+	for i := 1; i <= waitBins && lo <= expiry; i++ {
+		hi := expiry
+		if i < waitBins {
+			target := selectProb * float64(i) / waitBins
+			hi = int64(math.Ceil(math.Log1p(-target) / math.Log1p(-p)))
+			if hi < lo {
+				hi = lo
+			} else if hi > expiry {
+				hi = expiry
+			}
+		}
+		stats.WaitBins = append(stats.WaitBins, apitypes.TicketWaitBin{
+			MinWait:  lo,
+			MaxWait:  hi,
+			Expected: float64(left) * (cdf(hi) - cdf(lo-1)),
+		})
+		lo = hi + 1
+	}
+
+	var waitSum int64
+	for _, wait := range waits {
+		waitSum += wait
+		bin := len(stats.WaitBins) - 1
+		for i := range stats.WaitBins {
+			if wait <= stats.WaitBins[i].MaxWait {
+				bin = i
+				break
+			}
+		}
+		stats.WaitBins[bin].Observed++
+	}
+	if stats.Selected > 0 {
+		stats.MeanWait = float64(waitSum) / float64(stats.Selected)
+	}
+
+	stats.ExpectedExpired = float64(left) * expireProb
+	stats.ExpectedExpiryRate = expireProb
+	if left > 0 {
+		stats.ExpiryRate = float64(stats.Expired) / float64(left)
+	}
+
+	// The expiries are the last category of the goodness of fit.
+	categories := len(stats.WaitBins)
+	if stats.ExpectedExpired > 0 {
+		categories++
+		stats.ChiSquare = chiSquareTerm(float64(stats.Expired), stats.ExpectedExpired)
+	}
+	for _, bin := range stats.WaitBins {
+		stats.ChiSquare += chiSquareTerm(float64(bin.Observed), bin.Expected)
+	}
+	stats.DegreesOfFreedom = categories - 1
+	if left > 0 && stats.DegreesOfFreedom > 0 {
+		stats.PValue = chiSquarePValue(stats.ChiSquare, stats.DegreesOfFreedom)
+	}
+
+	return stats, nil
+}
+
+func chiSquareTerm(observed, expected float64) float64 {
+	if expected <= 0 {
+		return 0
+	}
+	d := observed - expected
+	return d * d / expected
+}
+
+// chiSquarePValue is the probability of a chi-square statistic of at least x
+// with dof degrees of freedom.
+func chiSquarePValue(x float64, dof int) float64 {
+	return gammaQ(float64(dof)/2, x/2)
+}
+
+// gammaQ is the regularized upper incomplete gamma function Q(a, x), evaluated
+// by its series for x < a+1 and by its continued fraction otherwise.
+func gammaQ(a, x float64) float64 {
+	const (
+		maxIter = 500
+		eps     = 1e-14
+		tiny    = 1e-300
+	)
+	if x <= 0 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	scale := math.Exp(-x + a*math.Log(x) - lga)
+
+	if x < a+1 {
+		ap, del := a, 1/a
+		sum := del
+		for i := 0; i < maxIter; i++ {
+			ap++
+			del *= x / ap
+			sum += del
+			if math.Abs(del) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		return 1 - sum*scale
+	}
+
+	// Modified Lentz's method.
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i <= maxIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return scale * h
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/decred/dcrd/wire"
 
 	apitypes "github.com/decred/dcrdata/v8/api/types"
+	"github.com/decred/dcrdata/v8/metrics"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
 
@@ -54,6 +55,7 @@ func (c *PoolInfoCache) Get(hash chainhash.Hash) (*apitypes.TicketPoolInfo, bool
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
 	tpi, ok := c.poolInfo[hash]
+	metrics.CacheLookup("poolinfo", ok)
 	return tpi, ok
 }
 
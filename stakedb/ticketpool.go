@@ -33,8 +33,33 @@ type TicketPool struct {
 	diffs  []PoolDiff
 	pool   map[chainhash.Hash]struct{}
 	diffDB *badger.DB
+	// There is a pool snapshot stored in diffDB at every multiple of
+	// poolSnapshotInterval up to the tip, taken as blocks are connected.
+	// snapshots caches the recently used ones, and snapshotOrder is their use
+	// order, least recent first. snapMtx guards both, so that the history
+	// queries only need to read lock mtx.
+	snapMtx       sync.Mutex
+	snapshots     map[int64]poolSnapshot
+	snapshotOrder []int64
 }
 
+// poolSnapshot is the live ticket pool at a height, mapping each ticket to the
+// height at which it entered the pool.
+type poolSnapshot map[chainhash.Hash]int64
+
+const (
+	// poolSnapshotInterval is the spacing in blocks of the pool snapshots,
+	// bounding the diffs replayed by a history query.
+	poolSnapshotInterval = 4096
+	// maxPoolSnapshots is the most pool snapshots kept in memory.
+	maxPoolSnapshots = 16
+)
+
+// snapshotKeyPrefix prefixes the keys of the pool snapshots in the diff DB. The
+// snapshots have the same user metadata as the version key, so they are not
+// loaded as diffs.
+var snapshotKeyPrefix = []byte("snapshot")
+
 // PoolDiff represents the tickets going in and out of the live ticket pool from
 // one height to the next.
 type PoolDiff struct {
@@ -316,12 +341,17 @@ func NewTicketPool(dataDir, dbSubDir string) (tp *TicketPool, err error) {
 	}
 
 	// Construct TicketPool with loaded diffs and diff DB
-	return &TicketPool{
-		pool:   make(map[chainhash.Hash]struct{}),
-		diffs:  poolDiffs,
-		tip:    int64(len(poolDiffs)), // number of blocks connected over genesis
-		diffDB: db,
-	}, nil
+	tp = &TicketPool{
+		pool:      make(map[chainhash.Hash]struct{}),
+		diffs:     poolDiffs,
+		tip:       int64(len(poolDiffs)), // number of blocks connected over genesis
+		diffDB:    db,
+		snapshots: make(map[int64]poolSnapshot),
+	}
+	if err = tp.buildSnapshots(); err != nil {
+		return nil, fmt.Errorf("failed to build pool snapshots: %w", err)
+	}
+	return tp, nil
 }
 
 // LoadAllPoolDiffs loads all found ticket pool diffs from badger DB.
@@ -534,6 +564,12 @@ func (tp *TicketPool) trim() (int64, PoolDiff) {
 		return tp.tip, PoolDiff{}
 	}
 	tp.tip--
+	// The snapshot at the trimmed height was taken with the trimmed diff.
+	if trimmed := tp.tip + 1; trimmed%poolSnapshotInterval == 0 {
+		if err := tp.dropSnapshot(trimmed); err != nil {
+			log.Errorf("Failed to delete the pool snapshot at %d: %v", trimmed, err)
+		}
+	}
 	newMaxCursor := tp.maxCursor()
 	if tp.cursor > newMaxCursor {
 		if err := tp.retreatTo(newMaxCursor); err != nil {
@@ -654,7 +690,10 @@ func (tp *TicketPool) Append(diff *PoolDiff, height int64) error {
 	tp.mtx.Lock()
 	defer tp.mtx.Unlock()
 	tp.append(diff)
-	return tp.storeDiff(diff, height)
+	if err := tp.storeDiff(diff, height); err != nil {
+		return err
+	}
+	return tp.takeSnapshot()
 }
 
 // AppendAndAdvancePool functions like Append, except that after growing the
@@ -669,6 +708,9 @@ func (tp *TicketPool) AppendAndAdvancePool(diff *PoolDiff, height int64) error {
 	if err := tp.storeDiff(diff, height); err != nil {
 		return err
 	}
+	if err := tp.takeSnapshot(); err != nil {
+		return err
+	}
 	return tp.advance()
 }
 
@@ -842,3 +884,358 @@ func (tp *TicketPool) applyDiff(in, out []chainhash.Hash) {
 		log.Warnf("pool shrank by %d instead of %d", initsize-endsize, len(out))
 	}
 }
+
+// snapshotKey is the diff DB key of the pool snapshot at the height.
+func snapshotKey(height int64) []byte {
+	key := make([]byte, 0, len(snapshotKeyPrefix)+8)
+	key = append(key, snapshotKeyPrefix...)
+	return binary.BigEndian.AppendUint64(key, uint64(height))
+}
+
+// encodeSnapshot serializes a poolSnapshot as each ticket hash followed by the
+// 8-byte height at which the ticket entered the pool.
+func encodeSnapshot(snap poolSnapshot) []byte {
+	data := make([]byte, 0, len(snap)*(chainhash.HashSize+8))
+	for ticket, entry := range snap {
+		data = append(data, ticket[:]...)
+		data = binary.BigEndian.AppendUint64(data, uint64(entry))
+	}
+	return data
+}
+
+// decodeSnapshot deserializes a poolSnapshot encoded by encodeSnapshot.
+func decodeSnapshot(data []byte) (poolSnapshot, error) {
+	const entryLen = chainhash.HashSize + 8
+	if len(data)%entryLen != 0 {
+		return nil, fmt.Errorf("invalid snapshot length %d", len(data))
+	}
+	snap := make(poolSnapshot, len(data)/entryLen)
+	for ; len(data) > 0; data = data[entryLen:] {
+		var ticket chainhash.Hash
+		copy(ticket[:], data)
+		snap[ticket] = int64(binary.BigEndian.Uint64(data[chainhash.HashSize:]))
+	}
+	return snap, nil
+}
+
+// storeSnapshot stores the pool snapshot at the height in the diff DB.
+func (tp *TicketPool) storeSnapshot(height int64, snap poolSnapshot) error {
+	return tp.diffDB.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry(snapshotKey(height), encodeSnapshot(snap)).WithMeta(1))
+	})
+}
+
+// loadSnapshot loads the pool snapshot at the height from the diff DB.
+func (tp *TicketPool) loadSnapshot(height int64) (snap poolSnapshot, err error) {
+	err = tp.diffDB.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(snapshotKey(height))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(v []byte) error {
+			snap, err = decodeSnapshot(v)
+			return err
+		})
+	})
+	return
+}
+
+// storedSnapshotHeights lists the heights of the pool snapshots in the diff DB.
+func (tp *TicketPool) storedSnapshotHeights() ([]int64, error) {
+	var heights []int64
+	err := tp.diffDB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = snapshotKeyPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().Key()
+			if len(key) != len(snapshotKeyPrefix)+8 {
+				return fmt.Errorf("invalid snapshot key %x", key)
+			}
+			heights = append(heights, int64(binary.BigEndian.Uint64(key[len(snapshotKeyPrefix):])))
+		}
+		return nil
+	})
+	return heights, err
+}
+
+// buildSnapshots makes the pool snapshots in the diff DB match the diffs,
+// building the missing ones up to the tip in a single pass over the diffs from
+// the highest snapshot below them, and deleting any above the tip. This is
+// only slow the first time, when all of the snapshots are built.
+func (tp *TicketPool) buildSnapshots() error {
+	heights, err := tp.storedSnapshotHeights()
+	if err != nil {
+		return err
+	}
+	stored := make(map[int64]bool, len(heights))
+	for _, h := range heights {
+		if h > tp.tip || h%poolSnapshotInterval != 0 {
+			if err = tp.dropSnapshot(h); err != nil {
+				return err
+			}
+			continue
+		}
+		stored[h] = true
+	}
+	missing := tp.tip/poolSnapshotInterval - int64(len(stored))
+	if missing == 0 {
+		return nil
+	}
+	log.Infof("Building %d ticket pool snapshots...", missing)
+
+	// snap is the pool at snapHeight, loaded when a snapshot is missing.
+	var snap poolSnapshot
+	var snapHeight int64
+	for h := int64(poolSnapshotInterval); h <= tp.tip; h += poolSnapshotInterval {
+		if stored[h] {
+			snap, snapHeight = nil, h
+			continue
+		}
+		if snap == nil {
+			prev, err := tp.snapshot(snapHeight)
+			if err != nil {
+				return err
+			}
+			snap = make(poolSnapshot, len(prev))
+			for ticket, entry := range prev {
+				snap[ticket] = entry
+			}
+		}
+		for i := snapHeight; i < h; i++ {
+			applySnapshotDiff(snap, &tp.diffs[i], i+1)
+		}
+		if err = tp.storeSnapshot(h, snap); err != nil {
+			return err
+		}
+		snapHeight = h
+	}
+	log.Infof("Built %d ticket pool snapshots.", missing)
+	return nil
+}
+
+// takeSnapshot stores the pool snapshot at the tip if the tip is a multiple of
+// poolSnapshotInterval. The snapshot is built from the previous one, so only
+// the diffs of one interval are applied.
+func (tp *TicketPool) takeSnapshot() error {
+	if tp.tip%poolSnapshotInterval != 0 {
+		return nil
+	}
+	prev, err := tp.snapshot(tp.tip - poolSnapshotInterval)
+	if err != nil {
+		return err
+	}
+	snap := make(poolSnapshot, len(prev))
+	for ticket, entry := range prev {
+		snap[ticket] = entry
+	}
+	for i := tp.tip - poolSnapshotInterval; i < tp.tip; i++ {
+		applySnapshotDiff(snap, &tp.diffs[i], i+1)
+	}
+	if err = tp.storeSnapshot(tp.tip, snap); err != nil {
+		return err
+	}
+
+	tp.snapMtx.Lock()
+	tp.cacheSnapshot(tp.tip, snap)
+	tp.snapMtx.Unlock()
+	return nil
+}
+
+// dropSnapshot removes any pool snapshot at the height from the cache and the
+// diff DB.
+func (tp *TicketPool) dropSnapshot(height int64) error {
+	tp.snapMtx.Lock()
+	if _, ok := tp.snapshots[height]; ok {
+		delete(tp.snapshots, height)
+		tp.snapshotOrder = removeHeight(tp.snapshotOrder, height)
+	}
+	tp.snapMtx.Unlock()
+	return tp.diffDB.Update(func(txn *badger.Txn) error {
+		return txn.Delete(snapshotKey(height))
+	})
+}
+
+// removeHeight removes the height from the heights.
+func removeHeight(heights []int64, height int64) []int64 {
+	for i, h := range heights {
+		if h == height {
+			return append(heights[:i], heights[i+1:]...)
+		}
+	}
+	return heights
+}
+
+// cacheSnapshot adds the snapshot at the height to the cache as the most
+// recently used one, evicting the least recently used one if the cache is
+// full. snapMtx must be locked.
+func (tp *TicketPool) cacheSnapshot(height int64, snap poolSnapshot) {
+	if _, ok := tp.snapshots[height]; ok {
+		tp.snapshotOrder = removeHeight(tp.snapshotOrder, height)
+	} else if len(tp.snapshotOrder) == maxPoolSnapshots {
+		delete(tp.snapshots, tp.snapshotOrder[0])
+		tp.snapshotOrder = tp.snapshotOrder[1:]
+	}
+	tp.snapshots[height] = snap
+	tp.snapshotOrder = append(tp.snapshotOrder, height)
+}
+
+// snapshot returns the pool snapshot at the height, which must be a multiple
+// of poolSnapshotInterval not above the tip, from the cache or the diff DB.
+// The returned snapshot must not be modified.
+func (tp *TicketPool) snapshot(height int64) (poolSnapshot, error) {
+	if height == 0 {
+		return poolSnapshot{}, nil
+	}
+	tp.snapMtx.Lock()
+	defer tp.snapMtx.Unlock()
+	snap, ok := tp.snapshots[height]
+	if !ok {
+		var err error
+		if snap, err = tp.loadSnapshot(height); err != nil {
+			return nil, fmt.Errorf("failed to load the pool snapshot at %d: %w", height, err)
+		}
+	}
+	tp.cacheSnapshot(height, snap)
+	return snap, nil
+}
+
+// snapshotAt returns the pool snapshot at the largest multiple of
+// poolSnapshotInterval that is not above height, and the snapshot's height.
+// The returned snapshot must not be modified.
+func (tp *TicketPool) snapshotAt(height int64) (poolSnapshot, int64, error) {
+	target := height / poolSnapshotInterval * poolSnapshotInterval
+	snap, err := tp.snapshot(target)
+	return snap, target, err
+}
+
+// applySnapshotDiff applies the diff of the block at the height to the
+// snapshot.
+func applySnapshotDiff(snap poolSnapshot, diff *PoolDiff, height int64) {
+	for i := range diff.In {
+		snap[diff.In[i]] = height
+	}
+	for i := range diff.Out {
+		delete(snap, diff.Out[i])
+	}
+}
+
+// poolWithEntries returns the pool at the height, mapping each ticket to the
+// height at which it entered the pool.
+func (tp *TicketPool) poolWithEntries(height int64) (poolSnapshot, error) {
+	if height < 0 || height > tp.tip {
+		return nil, fmt.Errorf("block height %d is not connected yet, tip is %d", height, tp.tip)
+	}
+	snap, snapHeight, err := tp.snapshotAt(height)
+	if err != nil {
+		return nil, err
+	}
+	pool := make(poolSnapshot, len(snap))
+	for ticket, entry := range snap {
+		pool[ticket] = entry
+	}
+	for i := snapHeight; i < height; i++ {
+		applySnapshotDiff(pool, &tp.diffs[i], i+1)
+	}
+	return pool, nil
+}
+
+// TicketLive checks if the ticket was in the live pool at the height. If it
+// was, the height at which the ticket entered the pool is also returned.
+func (tp *TicketPool) TicketLive(ticket chainhash.Hash, height int64) (bool, int64, error) {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	if height < 0 || height > tp.tip {
+		return false, 0, fmt.Errorf("block height %d is not connected yet, tip is %d", height, tp.tip)
+	}
+	snap, snapHeight, err := tp.snapshotAt(height)
+	if err != nil {
+		return false, 0, err
+	}
+	entry, live := snap[ticket]
+	// Only the diffs after the snapshot need to be searched for the ticket.
+	for i := snapHeight; i < height; i++ {
+		diff := &tp.diffs[i]
+		for j := range diff.In {
+			if diff.In[j] == ticket {
+				entry, live = i+1, true
+			}
+		}
+		for j := range diff.Out {
+			if diff.Out[j] == ticket {
+				entry, live = 0, false
+			}
+		}
+	}
+	return live, entry, nil
+}
+
+// PoolChanges gets the net change in the live pool from height from to height
+// to: the tickets in the pool at to that were not at from, and the tickets in
+// the pool at from that are not at to. Tickets that both entered and left the
+// pool between the heights are in neither.
+func (tp *TicketPool) PoolChanges(from, to int64) (in, out []chainhash.Hash, err error) {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	if from < 0 || from > to {
+		return nil, nil, fmt.Errorf("invalid height range [%d, %d]", from, to)
+	}
+	if to > tp.tip {
+		return nil, nil, fmt.Errorf("block height %d is not connected yet, tip is %d", to, tp.tip)
+	}
+
+	// A ticket enters and leaves the pool at most once, so the tickets that
+	// entered the pool are removed when they leave again.
+	entered := make(map[chainhash.Hash]struct{})
+	for i := from; i < to; i++ {
+		diff := &tp.diffs[i]
+		for j := range diff.In {
+			entered[diff.In[j]] = struct{}{}
+			in = append(in, diff.In[j])
+		}
+		for j := range diff.Out {
+			if _, ok := entered[diff.Out[j]]; ok {
+				delete(entered, diff.Out[j])
+				continue
+			}
+			out = append(out, diff.Out[j])
+		}
+	}
+	netIn := in[:0]
+	for _, ticket := range in {
+		if _, ok := entered[ticket]; ok {
+			netIn = append(netIn, ticket)
+		}
+	}
+	return netIn, out, nil
+}
+
+// PoolAges gets the age distribution of the live pool at the height. The
+// count of tickets that had been in the pool for [i*binSize, (i+1)*binSize)
+// blocks is at index i.
+func (tp *TicketPool) PoolAges(height, binSize int64) ([]int64, error) {
+	if binSize < 1 {
+		return nil, fmt.Errorf("invalid bin size %d", binSize)
+	}
+
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	pool, err := tp.poolWithEntries(height)
+	if err != nil {
+		return nil, err
+	}
+	var counts []int64
+	for _, entry := range pool {
+		bin := (height - entry) / binSize
+		for int64(len(counts)) <= bin {
+			counts = append(counts, 0)
+		}
+		counts[bin]++
+	}
+	return counts, nil
+}
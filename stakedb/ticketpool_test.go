@@ -3,6 +3,8 @@ package stakedb
 import (
 	"crypto/rand"
 	"fmt"
+	"math"
+	mrand "math/rand"
 	"os"
 	"testing"
 
@@ -441,3 +443,277 @@ func TestTicketPoolPersistent(t *testing.T) {
 	t.Logf("Testing reference v1 DB at %v", dbFolderRefV1)
 	testDBDir(dbFolderRefV1)
 }
+
+// newTestTicketPool creates a TicketPool in the directory and appends the
+// diffs, so that the pool snapshots are taken as when blocks are connected.
+func newTestTicketPool(t *testing.T, dir string, diffs []PoolDiff) *TicketPool {
+	t.Helper()
+	p, err := NewTicketPool(dir, "pool")
+	if err != nil {
+		t.Fatalf("NewTicketPool failed: %v", err)
+	}
+	for i := range diffs {
+		if err = p.Append(&diffs[i], int64(i+1)); err != nil {
+			p.Close()
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	return p
+}
+
+// TestTicketPoolHistory checks the history queries, which use the pool
+// snapshots, against the pools reconstructed by Pool.
+func TestTicketPoolHistory(t *testing.T) {
+	// Build diffs for 3 snapshot intervals, with each ticket leaving the pool
+	// some blocks after it enters.
+	const tip = 3*poolSnapshotInterval + 100
+	diffs := make([]PoolDiff, tip)
+	entries := make(map[chainhash.Hash]int64)
+	for i := range diffs {
+		diffs[i].In = randomHashSlice(5)
+		for _, ticket := range diffs[i].In {
+			entries[ticket] = int64(i + 1)
+			if leave := i + 50 + len(entries)%500; leave < tip {
+				diffs[leave].Out = append(diffs[leave].Out, ticket)
+			}
+		}
+	}
+	dir := t.TempDir()
+	p := newTestTicketPool(t, dir, diffs)
+	defer func() { p.Close() }()
+
+	heights, err := p.storedSnapshotHeights()
+	if err != nil || len(heights) != 3 {
+		t.Fatalf("stored snapshots at %v (%v), expected 3", heights, err)
+	}
+
+	for _, height := range []int64{0, 100, poolSnapshotInterval, 2*poolSnapshotInterval + 7, 20, tip} {
+		pool, err := p.Pool(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		live := make(map[chainhash.Hash]struct{}, len(pool))
+		for _, ticket := range pool {
+			live[ticket] = struct{}{}
+			isLive, entry, err := p.TicketLive(ticket, height)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !isLive || entry != entries[ticket] {
+				t.Fatalf("ticket %v at height %d: live %v, entry %d, expected entry %d",
+					ticket, height, isLive, entry, entries[ticket])
+			}
+		}
+		if height > 0 {
+			isLive, _, _ := p.TicketLive(diffs[0].In[0], height)
+			_, expected := live[diffs[0].In[0]]
+			if isLive != expected {
+				t.Errorf("first ticket at height %d: live %v, expected %v", height, isLive, expected)
+			}
+		}
+
+		ages, err := p.PoolAges(height, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int64
+		for _, n := range ages {
+			count += n
+		}
+		if count != int64(len(pool)) {
+			t.Errorf("pool ages at height %d count %d tickets, expected %d", height, count, len(pool))
+		}
+	}
+
+	if len(p.snapshots) == 0 || len(p.snapshots) > maxPoolSnapshots {
+		t.Errorf("unexpected number of snapshots %d", len(p.snapshots))
+	}
+
+	// The pool changes between two heights transform the first pool into the
+	// second.
+	from, to := int64(poolSnapshotInterval-10), int64(poolSnapshotInterval+300)
+	poolFrom, _ := p.Pool(from)
+	poolTo, _ := p.Pool(to)
+	in, out, err := p.PoolChanges(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := make(map[chainhash.Hash]struct{}, len(poolFrom))
+	for _, ticket := range poolFrom {
+		pool[ticket] = struct{}{}
+	}
+	for _, ticket := range out {
+		if _, ok := pool[ticket]; !ok {
+			t.Fatalf("ticket %v left the pool but was not in it", ticket)
+		}
+		delete(pool, ticket)
+	}
+	for _, ticket := range in {
+		pool[ticket] = struct{}{}
+	}
+	if len(pool) != len(poolTo) {
+		t.Fatalf("pool size after changes %d, expected %d", len(pool), len(poolTo))
+	}
+	for _, ticket := range poolTo {
+		if _, ok := pool[ticket]; !ok {
+			t.Fatalf("ticket %v missing from the pool after changes", ticket)
+		}
+	}
+
+	// A reopened pool uses the stored snapshots, and builds any missing one.
+	ages, err := p.PoolAges(3*poolSnapshotInterval, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.dropSnapshot(2 * poolSnapshotInterval); err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+	p, err = NewTicketPool(dir, "pool")
+	if err != nil {
+		t.Fatalf("NewTicketPool failed: %v", err)
+	}
+	if heights, err = p.storedSnapshotHeights(); err != nil || len(heights) != 3 {
+		t.Fatalf("stored snapshots at %v (%v), expected 3", heights, err)
+	}
+	ages2, err := p.PoolAges(3*poolSnapshotInterval, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ages2) != fmt.Sprint(ages) {
+		t.Errorf("pool ages %v after reopening, expected %v", ages2, ages)
+	}
+
+	// Trimming drops the snapshots above the new tip.
+	for p.Tip() > 2*poolSnapshotInterval {
+		p.Trim()
+	}
+	for h := range p.snapshots {
+		if h > p.Tip() {
+			t.Errorf("snapshot at %d above tip %d", h, p.Tip())
+		}
+	}
+	if heights, err = p.storedSnapshotHeights(); err != nil || len(heights) != 2 {
+		t.Errorf("stored snapshots at %v (%v) after trimming, expected 2", heights, err)
+	}
+}
+
+func TestGammaQ(t *testing.T) {
+	// Critical values of the chi-square distribution at the 5% level.
+	tests := []struct {
+		x   float64
+		dof int
+	}{
+		{3.841, 1},
+		{11.070, 5},
+		{18.307, 10},
+		{19.675, 11},
+	}
+	for _, tt := range tests {
+		if p := chiSquarePValue(tt.x, tt.dof); math.Abs(p-0.05) > 1e-4 {
+			t.Errorf("p-value of %v with %d degrees of freedom is %v, expected 0.05", tt.x, tt.dof, p)
+		}
+	}
+}
+
+// TestTicketPoolSelectionStats checks the selection statistics of pools with
+// random and with oldest-first ticket selection.
+func TestTicketPoolSelectionStats(t *testing.T) {
+	const (
+		tip             = 4000
+		ticketsPerBlock = 5
+		expiry          = 300
+		svh             = 2
+	)
+	makePool := func(pick func(live []chainhash.Hash) int) *TicketPool {
+		rnd := mrand.New(mrand.NewSource(1))
+		diffs := make([]PoolDiff, tip)
+		entries := make(map[chainhash.Hash]int64)
+		var live []chainhash.Hash
+		for i := range diffs {
+			h := int64(i + 1)
+			if h >= svh {
+				for j := 0; j < ticketsPerBlock; j++ {
+					k := pick(live)
+					if k < 0 {
+						k = rnd.Intn(len(live))
+					}
+					diffs[i].Out = append(diffs[i].Out, live[k])
+					live = append(live[:k], live[k+1:]...)
+				}
+				// The tickets that lost expiry lotteries expire.
+				n := 0
+				for _, ticket := range live {
+					entry := entries[ticket]
+					if entry < svh-1 {
+						entry = svh - 1
+					}
+					if h-entry >= expiry {
+						diffs[i].Out = append(diffs[i].Out, ticket)
+						continue
+					}
+					live[n] = ticket
+					n++
+				}
+				live = live[:n]
+			}
+			in := ticketsPerBlock
+			if i == 0 {
+				in = 500
+			}
+			diffs[i].In = randomHashSlice(in)
+			for _, ticket := range diffs[i].In {
+				entries[ticket] = h
+			}
+			live = append(live, diffs[i].In...)
+		}
+		return &TicketPool{
+			diffs:     diffs,
+			tip:       tip,
+			pool:      make(map[chainhash.Hash]struct{}),
+			snapshots: make(map[int64]poolSnapshot),
+		}
+	}
+
+	random := makePool(func([]chainhash.Hash) int { return -1 })
+	stats, err := random.SelectionStats(1000, tip, ticketsPerBlock, expiry, svh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Selected != ticketsPerBlock*(tip-999) {
+		t.Errorf("%d tickets selected, expected %d", stats.Selected, ticketsPerBlock*(tip-999))
+	}
+	var binned int64
+	for _, bin := range stats.WaitBins {
+		binned += bin.Observed
+	}
+	if binned != stats.Selected {
+		t.Errorf("%d tickets in wait bins, expected %d", binned, stats.Selected)
+	}
+	if stats.DegreesOfFreedom != len(stats.WaitBins) {
+		t.Errorf("%d degrees of freedom for %d bins", stats.DegreesOfFreedom, len(stats.WaitBins))
+	}
+	if stats.PValue < 0.001 {
+		t.Errorf("random selection rejected, chi-square %v, p-value %v", stats.ChiSquare, stats.PValue)
+	}
+	if math.Abs(stats.MeanWait-stats.ExpectedMeanWait)/stats.ExpectedMeanWait > 0.05 {
+		t.Errorf("mean wait %v, expected %v", stats.MeanWait, stats.ExpectedMeanWait)
+	}
+
+	// Selecting the oldest tickets first is far from random.
+	oldest := makePool(func([]chainhash.Hash) int { return 0 })
+	stats, err = oldest.SelectionStats(1000, tip, ticketsPerBlock, expiry, svh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.PValue > 1e-6 {
+		t.Errorf("oldest-first selection accepted, chi-square %v, p-value %v", stats.ChiSquare, stats.PValue)
+	}
+
+	if _, err = random.SelectionStats(0, 10, ticketsPerBlock, expiry, svh); err == nil {
+		t.Error("expected an error for a range starting at genesis")
+	}
+	if _, err = random.SelectionStats(10, tip+1, ticketsPerBlock, expiry, svh); err == nil {
+		t.Error("expected an error for a range beyond the tip")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package stakediff forecasts the stake difficulty (ticket price) of the next
+// stake difficulty windows, by simulating the ticket purchases of the rest of
+// the current window and of the following windows from the purchases of the
+// recent windows.
+package stakediff
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	apitypes "github.com/decred/dcrdata/v8/api/types"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+const (
+	// HistoryWindows is the number of past windows that the ticket purchases
+	// are sampled from.
+	HistoryWindows = 32
+
+	// MaxWindows is the most windows that may be forecast.
+	MaxWindows = 3
+
+	// numPaths is the number of simulated purchase histories.
+	numPaths = 2000
+)
+
+// HistoryStart is the height of the first block needed to forecast the stake
+// difficulty after the block at height tip.
+func HistoryStart(params *chaincfg.Params, tip int64) int64 {
+	windowStart := tip / params.StakeDiffWindowSize * params.StakeDiffWindowSize
+	start := windowStart - HistoryWindows*params.StakeDiffWindowSize -
+		int64(params.TicketMaturity) - 1
+	if start < 0 {
+		return 0
+	}
+	return start
+}
+
+// NextStakeDiff computes the stake difficulty of the next window per DCP0001
+// from the current stake difficulty and the sizes of the pool including the
+// immature tickets at the ends of the previous and current windows. The
+// maximum relative to the coin supply is not applied.
+func NextStakeDiff(params *chaincfg.Params, curDiff, prevPoolSizeAll, curPoolSizeAll int64) int64 {
+	if prevPoolSizeAll <= 0 {
+		if curDiff < params.MinimumStakeDiff {
+			return params.MinimumStakeDiff
+		}
+		return curDiff
+	}
+	// nextDiff = curDiff * curPoolSizeAll^2 / (prevPoolSizeAll * targetPoolSizeAll)
+	targetPoolSizeAll := int64(params.TicketsPerBlock) *
+		(int64(params.TicketPoolSize) + int64(params.TicketMaturity))
+	curPoolSizeAllBig := big.NewInt(curPoolSizeAll)
+	nextDiffBig := big.NewInt(curDiff)
+	nextDiffBig.Mul(nextDiffBig, curPoolSizeAllBig)
+	nextDiffBig.Mul(nextDiffBig, curPoolSizeAllBig)
+	nextDiffBig.Div(nextDiffBig, big.NewInt(prevPoolSizeAll))
+	nextDiffBig.Div(nextDiffBig, big.NewInt(targetPoolSizeAll))
+	nextDiff := nextDiffBig.Int64()
+	if nextDiff < params.MinimumStakeDiff {
+		return params.MinimumStakeDiff
+	}
+	return nextDiff
+}
+
+// windowPurchases are the tickets purchased in a past window up to and after
+// the position of the best block in the current window.
+type windowPurchases struct {
+	early, late int64
+}
+
+// Forecast forecasts the ticket prices of the next windows after the block at
+// the end of blocks, which are consecutive mainchain blocks from HistoryStart.
+//
+// The purchases of the rest of the current window are the purchases so far
+// scaled by the ratio of the later to the earlier purchases of a random past
+// window, and at least the mempoolTickets. The purchases of each following
+// window are those of a random past window. The tickets leave the pool at the
+// votes per block plus the mean rate of expiry of the past windows.
+func Forecast(params *chaincfg.Params, blocks []*dbtypes.BlockStakeData, mempoolTickets int64,
+	windows int) (*apitypes.StakeDiffForecast, error) {
+	if windows < 1 || windows > MaxWindows {
+		return nil, fmt.Errorf("invalid number of windows %d", windows)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no blocks")
+	}
+	first, tipBlock := blocks[0].Height, blocks[len(blocks)-1]
+	tip := tipBlock.Height
+	if tip-first+1 != int64(len(blocks)) {
+		return nil, fmt.Errorf("blocks %d to %d are not consecutive", first, tip)
+	}
+
+	windowSize := params.StakeDiffWindowSize
+	maturity := int64(params.TicketMaturity)
+	votesPerBlock := float64(params.TicketsPerBlock)
+	maxPerBlock := int64(params.MaxFreshStakePerBlock)
+	windowStart := tip / windowSize * windowSize
+	pos := tip - windowStart
+	remaining := windowSize - 1 - pos
+
+	fresh := func(height int64) int64 {
+		return blocks[height-first].FreshStake
+	}
+	// poolSizeAll is the pool size plus the immature tickets at a height.
+	poolSizeAll := func(height int64) int64 {
+		size := blocks[height-first].PoolSize
+		for h := height - maturity + 1; h <= height; h++ {
+			size += fresh(h)
+		}
+		return size
+	}
+
+	// The past windows must be preceded by the blocks needed for the pool size
+	// at their ends.
+	histStart := windowStart - HistoryWindows*windowSize
+	for histStart-1-maturity+1 < first {
+		histStart += windowSize
+	}
+	if histStart >= windowStart {
+		return nil, fmt.Errorf("insufficient history to forecast after block %d", tip)
+	}
+	var history []windowPurchases
+	for start := histStart; start < windowStart; start += windowSize {
+		var w windowPurchases
+		for h := start; h < start+windowSize; h++ {
+			if h-start <= pos {
+				w.early += fresh(h)
+			} else {
+				w.late += fresh(h)
+			}
+		}
+		history = append(history, w)
+	}
+
+	// The expired tickets are those that left the pool other than by the
+	// votes.
+	var histFresh int64
+	for h := histStart; h <= tip; h++ {
+		histFresh += fresh(h)
+	}
+	histBlocks := float64(tip - histStart + 1)
+	expired := float64(histFresh) - votesPerBlock*histBlocks -
+		float64(poolSizeAll(tip)-poolSizeAll(histStart-1))
+	expiryRate := math.Max(expired/histBlocks, 0)
+	outflow := votesPerBlock + expiryRate
+
+	var windowSoFar int64
+	for h := windowStart; h <= tip; h++ {
+		windowSoFar += fresh(h)
+	}
+	curDiff := tipBlock.SBits
+	curPoolSizeAll := poolSizeAll(tip)
+	prevPoolSizeAll := poolSizeAll(windowStart - 1)
+
+	// Simulate the purchases, seeded by the height for reproducible forecasts.
+	rng := rand.New(rand.NewSource(tip))
+	prices := make([][]float64, windows)
+	for i := range prices {
+		prices[i] = make([]float64, numPaths)
+	}
+	for p := 0; p < numPaths; p++ {
+		var bought float64
+		if remaining > 0 {
+			w := history[rng.Intn(len(history))]
+			if w.early > 0 {
+				bought = float64(windowSoFar) * float64(w.late) / float64(w.early)
+			} else {
+				bought = float64(w.late)
+			}
+			bought = math.Max(bought, float64(mempoolTickets))
+			bought = math.Min(bought, float64(maxPerBlock*remaining))
+		}
+		prevAll := prevPoolSizeAll
+		all := float64(curPoolSizeAll) + bought - outflow*float64(remaining)
+		diff := NextStakeDiff(params, curDiff, prevAll, int64(math.Round(all)))
+		prices[0][p] = dcrutil.Amount(diff).ToCoin()
+		for i := 1; i < windows; i++ {
+			w := history[rng.Intn(len(history))]
+			prevAll = int64(math.Round(all))
+			all += float64(w.early+w.late) - outflow*float64(windowSize)
+			diff = NextStakeDiff(params, diff, prevAll, int64(math.Round(all)))
+			prices[i][p] = dcrutil.Amount(diff).ToCoin()
+		}
+	}
+
+	forecast := &apitypes.StakeDiffForecast{
+		Height:          tip,
+		CurrentPrice:    dcrutil.Amount(curDiff).ToCoin(),
+		WindowPurchases: windowSoFar,
+		MempoolTickets:  mempoolTickets,
+		Windows:         make([]*apitypes.StakeDiffForecastWindow, 0, windows),
+	}
+	blockTime := int64(params.TargetTimePerBlock / time.Second)
+	for i, ps := range prices {
+		sort.Float64s(ps)
+		var sum float64
+		for _, price := range ps {
+			sum += price
+		}
+		percentile := func(q float64) float64 {
+			return ps[int(math.Round(q*float64(len(ps)-1)))]
+		}
+		startHeight := windowStart + int64(i+1)*windowSize
+		forecast.Windows = append(forecast.Windows, &apitypes.StakeDiffForecastWindow{
+			Window:      startHeight / windowSize,
+			StartHeight: startHeight,
+			StartTime:   tipBlock.Time.UNIX() + (startHeight-tip)*blockTime,
+			Mean:        sum / float64(len(ps)),
+			P5:          percentile(0.05),
+			P25:         percentile(0.25),
+			P50:         percentile(0.5),
+			P75:         percentile(0.75),
+			P95:         percentile(0.95),
+		})
+	}
+	return forecast, nil
+}
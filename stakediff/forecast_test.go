@@ -0,0 +1,107 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package stakediff
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+func TestNextStakeDiff(t *testing.T) {
+	params := chaincfg.SimNetParams()
+	// The simnet target pool size including the immature tickets is 400.
+	tests := []struct {
+		name                  string
+		curDiff, prevAll, all int64
+		want                  int64
+	}{
+		{"at target", 1e8, 400, 400, 1e8},
+		{"growing above target", 1e8, 400, 440, 1.21e8},
+		{"shrinking to target", 1e8, 440, 400, 90909090},
+		{"minimum", 30000, 400, 200, params.MinimumStakeDiff},
+		{"no previous pool", 1e8, 0, 400, 1e8},
+	}
+	for _, tt := range tests {
+		if got := NextStakeDiff(params, tt.curDiff, tt.prevAll, tt.all); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// testBlocks makes the simnet blocks to forecast after the block at tip, with
+// the ticket purchases of each block from buy.
+func testBlocks(params *chaincfg.Params, tip int64, buy func(height int64) int64) []*dbtypes.BlockStakeData {
+	var blocks []*dbtypes.BlockStakeData
+	for h := HistoryStart(params, tip); h <= tip; h++ {
+		blocks = append(blocks, &dbtypes.BlockStakeData{
+			Height:     h,
+			Time:       dbtypes.NewTimeDefFromUNIX(1e9 + h*60),
+			SBits:      1e8,
+			FreshStake: buy(h),
+			PoolSize:   320,
+		})
+	}
+	return blocks
+}
+
+func TestForecast(t *testing.T) {
+	params := chaincfg.SimNetParams()
+	const tip = 1003
+
+	// With the pool at its target and as many tickets bought as voted, the
+	// price holds.
+	steady := testBlocks(params, tip, func(int64) int64 { return 5 })
+	forecast, err := Forecast(params, steady, 0, MaxWindows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forecast.WindowPurchases != 20 || len(forecast.Windows) != MaxWindows {
+		t.Fatalf("unexpected forecast %+v", forecast)
+	}
+	for i, w := range forecast.Windows {
+		if w.StartHeight != 1008+int64(i)*8 || w.Window != 126+int64(i) {
+			t.Errorf("unexpected window %d start %d", w.Window, w.StartHeight)
+		}
+		if w.P5 != 1 || w.P50 != 1 || w.P95 != 1 || w.Mean != 1 {
+			t.Errorf("window %d: expected a steady price of 1 DCR, got %+v", w.Window, w)
+		}
+	}
+
+	// A full mempool fills the rest of the window, raising the next price.
+	forecast, err = Forecast(params, steady, 200, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := forecast.Windows[0]; w.P5 <= 1 || w.P5 != w.P95 {
+		t.Errorf("expected a certain price increase, got %+v", w)
+	}
+
+	// Randomly varying purchases spread the forecast.
+	rng := rand.New(rand.NewSource(1))
+	varied := testBlocks(params, tip, func(int64) int64 { return rng.Int63n(11) })
+	forecast, err = Forecast(params, varied, 0, MaxWindows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range forecast.Windows {
+		if !(w.P5 <= w.P25 && w.P25 <= w.P50 && w.P50 <= w.P75 && w.P75 <= w.P95) {
+			t.Errorf("window %d: percentiles out of order %+v", w.Window, w)
+		}
+	}
+	last := forecast.Windows[MaxWindows-1]
+	if last.P95-last.P5 <= forecast.Windows[0].P95-forecast.Windows[0].P5 {
+		t.Errorf("expected the forecast to widen over the windows")
+	}
+
+	if _, err = Forecast(params, steady[len(steady)-20:], 0, 1); err == nil {
+		t.Error("expected an error for insufficient history")
+	}
+	if _, err = Forecast(params, steady, 0, MaxWindows+1); err == nil {
+		t.Error("expected an error for too many windows")
+	}
+}
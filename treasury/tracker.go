@@ -0,0 +1,524 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package treasury tracks the votes on treasury spend (tspend) transactions
+// through their voting windows.
+package treasury
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v5"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v8/blockdata"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
+	"github.com/decred/dcrdata/v8/txhelpers"
+)
+
+// ErrNotTSpend is returned by TSpendTracker.Tally for a transaction that is
+// not a treasury spend.
+var ErrNotTSpend = errors.New("not a treasury spend")
+
+// maxLookups is the most results of Tally kept for transactions that are not
+// tracked.
+const maxLookups = 1000
+
+// NodeClient is the interface of the dcrd RPC client used by the
+// TSpendTracker. It is satisfied by *rpcclient.Client.
+type NodeClient interface {
+	GetBestBlock(ctx context.Context) (*chainhash.Hash, int64, error)
+	GetBlockHash(ctx context.Context, blockHeight int64) (*chainhash.Hash, error)
+	GetRawTransactionVerbose(ctx context.Context, txHash *chainhash.Hash) (*chainjson.TxRawResult, error)
+	GetStakeVersions(ctx context.Context, hash string, count int32) (*chainjson.GetStakeVersionsResult, error)
+	GetTreasurySpendVotes(ctx context.Context, block *chainhash.Hash, tspends []*chainhash.Hash) (*chainjson.GetTreasurySpendVotesResult, error)
+}
+
+// tspend is the tracking state of a treasury spend.
+type tspend struct {
+	hash        chainhash.Hash
+	amount      int64
+	expiry      int64
+	voteStart   int64
+	voteEnd     int64
+	minedHeight int64
+	// closed are the tallies of the intervals that are complete.
+	closed []*dbtypes.TreasurySpendInterval
+	tally  *dbtypes.TreasurySpendTally
+}
+
+// lookup is the result of tallying a transaction that is not tracked, which
+// Tally keeps until the next block.
+type lookup struct {
+	tally *dbtypes.TreasurySpendTally
+	err   error
+}
+
+// TSpendTracker tracks the tspends in mempool through their voting windows,
+// tallying the votes in each treasury vote interval (TVI), until they are
+// mined or expire. The tallies are updated with each new block, and a
+// pstypes.SigTSpend signal with the new tally of each tspend that is voting or
+// just got its final outcome is sent to the signal channels. Tspends with a
+// final outcome are kept for one voting window after their expiry.
+type TSpendTracker struct {
+	ctx        context.Context
+	node       NodeClient
+	params     *chaincfg.Params
+	signalOuts []chan<- pstypes.HubMessage
+
+	// updateMtx serializes the updates, which make RPCs, so that mtx is only
+	// locked to publish their results.
+	updateMtx sync.Mutex
+	tip       int64
+	tspends   map[chainhash.Hash]*tspend
+
+	mtx     sync.RWMutex
+	height  int64 // the tip of the published tallies
+	tallies map[chainhash.Hash]*dbtypes.TreasurySpendTally
+	lookups map[chainhash.Hash]*lookup
+}
+
+// NewTSpendTracker constructs a TSpendTracker. Call Refresh to start tracking
+// the tspends in mempool, and use the TSpendTracker as a
+// blockdata.BlockDataSaver to keep the tallies up-to-date.
+func NewTSpendTracker(ctx context.Context, node NodeClient, params *chaincfg.Params,
+	signalOuts []chan<- pstypes.HubMessage) *TSpendTracker {
+	return &TSpendTracker{
+		ctx:        ctx,
+		node:       node,
+		params:     params,
+		signalOuts: signalOuts,
+		tspends:    make(map[chainhash.Hash]*tspend),
+		tallies:    make(map[chainhash.Hash]*dbtypes.TreasurySpendTally),
+		lookups:    make(map[chainhash.Hash]*lookup),
+	}
+}
+
+// Refresh updates the tallies at the best block.
+func (t *TSpendTracker) Refresh() error {
+	hash, height, err := t.node.GetBestBlock(t.ctx)
+	if err != nil {
+		return fmt.Errorf("GetBestBlock: %w", err)
+	}
+
+	t.updateMtx.Lock()
+	defer t.updateMtx.Unlock()
+	return t.update(hash, height, nil)
+}
+
+// Store updates the tallies with a new block, satisfying
+// blockdata.BlockDataSaver.
+func (t *TSpendTracker) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	hash := msgBlock.BlockHash()
+	var mined []chainhash.Hash
+	for _, stx := range msgBlock.STransactions {
+		if stake.IsTSpend(stx) {
+			mined = append(mined, stx.TxHash())
+		}
+	}
+
+	t.updateMtx.Lock()
+	defer t.updateMtx.Unlock()
+	return t.update(&hash, int64(msgBlock.Header.Height), mined)
+}
+
+// Tally returns the vote tally of a tspend. A tspend that is not being tracked,
+// such as one that was mined before the tracker was started, is tallied on
+// request, without holding up the updates. The result for a transaction that
+// is not tracked, including an error such as ErrNotTSpend, is kept until the
+// next block. The returned tally must not be modified.
+func (t *TSpendTracker) Tally(hash *chainhash.Hash) (*dbtypes.TreasurySpendTally, error) {
+	t.mtx.RLock()
+	tally, l := t.tallies[*hash], t.lookups[*hash]
+	t.mtx.RUnlock()
+	if tally != nil {
+		return tally, nil
+	}
+	if l != nil {
+		return l.tally, l.err
+	}
+
+	_, tip, err := t.node.GetBestBlock(t.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetBestBlock: %w", err)
+	}
+	l = new(lookup)
+	var ts *tspend
+	if ts, l.err = t.fetch(hash); l.err == nil {
+		l.err = t.tallyVotes(ts, tip, nil)
+		l.tally = ts.tally
+	}
+
+	// The result is only kept if no block was connected in the meantime.
+	t.mtx.Lock()
+	if t.height == tip {
+		if len(t.lookups) >= maxLookups {
+			t.lookups = make(map[chainhash.Hash]*lookup)
+		}
+		t.lookups[*hash] = l
+	}
+	t.mtx.Unlock()
+	return l.tally, l.err
+}
+
+// TSpends returns the tallies of the tracked tspends, the most recent first.
+// The returned tallies must not be modified.
+func (t *TSpendTracker) TSpends() []*dbtypes.TreasurySpendTally {
+	t.mtx.RLock()
+	tallies := make([]*dbtypes.TreasurySpendTally, 0, len(t.tallies))
+	for _, tally := range t.tallies {
+		tallies = append(tallies, tally)
+	}
+	t.mtx.RUnlock()
+
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Expiry == tallies[j].Expiry {
+			return tallies[i].Hash < tallies[j].Hash
+		}
+		return tallies[i].Expiry > tallies[j].Expiry
+	})
+	return tallies
+}
+
+// update tracks the tspends in mempool and the tspends mined in the block, and
+// updates the tallies at the block. updateMtx must be locked.
+func (t *TSpendTracker) update(blockHash *chainhash.Hash, height int64, mined []chainhash.Hash) error {
+	if height <= t.tip {
+		t.rewind(height)
+	}
+	t.tip = height
+
+	for i := range mined {
+		ts, err := t.track(&mined[i])
+		if err != nil {
+			log.Errorf("Failed to track mined tspend %v: %v", mined[i], err)
+			continue
+		}
+		ts.minedHeight = height
+		ts.tally = nil
+	}
+
+	// Without a list of tspends, gettreasuryspendvotes reports those in
+	// mempool.
+	res, err := t.node.GetTreasurySpendVotes(t.ctx, blockHash, nil)
+	if err != nil {
+		err = fmt.Errorf("GetTreasurySpendVotes: %w", err)
+	} else {
+		for i := range res.Votes {
+			hash, err := chainhash.NewHashFromStr(res.Votes[i].Hash)
+			if err != nil {
+				log.Errorf("Invalid tspend hash %q: %v", res.Votes[i].Hash, err)
+				continue
+			}
+			if _, err = t.track(hash); err != nil {
+				log.Errorf("Failed to track tspend %v: %v", hash, err)
+			}
+		}
+	}
+
+	retention := int64(t.params.TreasuryVoteInterval * t.params.TreasuryVoteIntervalMultiplier)
+	var updated []*dbtypes.TreasurySpendTally
+	var pruned []chainhash.Hash
+	for hash, ts := range t.tspends {
+		if ts.tally != nil && ts.tally.Final() {
+			if height > ts.expiry+retention {
+				pruned = append(pruned, hash)
+			}
+			continue
+		}
+		if err := t.tallyVotes(ts, height, blockHash); err != nil {
+			log.Errorf("Failed to tally the votes on tspend %v: %v", hash, err)
+			continue
+		}
+		updated = append(updated, ts.tally)
+	}
+
+	t.mtx.Lock()
+	t.height = height
+	t.lookups = make(map[chainhash.Hash]*lookup)
+	for _, tally := range updated {
+		hash, _ := chainhash.NewHashFromStr(tally.Hash)
+		t.tallies[*hash] = tally
+	}
+	for i := range pruned {
+		delete(t.tspends, pruned[i])
+		delete(t.tallies, pruned[i])
+	}
+	t.mtx.Unlock()
+
+	for _, tally := range updated {
+		t.signal(tally)
+	}
+	if len(updated) > 0 {
+		log.Debugf("Updated the vote tallies of %d tspends at height %d.", len(updated), height)
+	}
+
+	return err
+}
+
+// rewind forgets the votes and mined tspends in the blocks at and above height,
+// which were disconnected in a reorg.
+func (t *TSpendTracker) rewind(height int64) {
+	for _, ts := range t.tspends {
+		if ts.minedHeight >= height {
+			ts.minedHeight = 0
+		}
+		n := 0
+		for _, iv := range ts.closed {
+			if iv.End < height {
+				n++
+			}
+		}
+		ts.closed = ts.closed[:n]
+		ts.tally = nil
+	}
+}
+
+// track starts tracking the tspend if it is not already tracked. updateMtx
+// must be locked.
+func (t *TSpendTracker) track(hash *chainhash.Hash) (*tspend, error) {
+	if ts, found := t.tspends[*hash]; found {
+		return ts, nil
+	}
+	ts, err := t.fetch(hash)
+	if err != nil {
+		return nil, err
+	}
+	t.tspends[*hash] = ts
+	log.Debugf("Tracking tspend %v, voting from %d to %d.", hash, ts.voteStart, ts.voteEnd)
+	return ts, nil
+}
+
+// fetch gets the amount and voting window of the tspend from the node.
+func (t *TSpendTracker) fetch(hash *chainhash.Hash) (*tspend, error) {
+	txRaw, err := t.node.GetRawTransactionVerbose(t.ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("GetRawTransactionVerbose: %w", err)
+	}
+	msgTx, err := txhelpers.MsgTxFromHex(txRaw.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction %v: %w", hash, err)
+	}
+	if !stake.IsTSpend(msgTx) {
+		return nil, fmt.Errorf("%v: %w", hash, ErrNotTSpend)
+	}
+
+	res, err := t.node.GetTreasurySpendVotes(t.ctx, nil, []*chainhash.Hash{hash})
+	if err != nil {
+		return nil, fmt.Errorf("GetTreasurySpendVotes: %w", err)
+	}
+	if len(res.Votes) != 1 {
+		return nil, fmt.Errorf("expected 1 tally, got %d", len(res.Votes))
+	}
+
+	return &tspend{
+		hash:        *hash,
+		amount:      int64(txhelpers.TotalOutFromMsgTx(msgTx)),
+		expiry:      res.Votes[0].Expiry,
+		voteStart:   res.Votes[0].VoteStart,
+		voteEnd:     res.Votes[0].VoteEnd,
+		minedHeight: txRaw.BlockHeight,
+	}, nil
+}
+
+// tallyVotes tallies the votes on the tspend by interval, up to the tip or the
+// block before the tspend was mined, and sets its tally. The tallies of the
+// complete intervals are kept. tipHash may be nil if it is not known.
+func (t *TSpendTracker) tallyVotes(ts *tspend, tip int64, tipHash *chainhash.Hash) error {
+	// Votes are counted in the blocks of the voting window before the block
+	// that mines the tspend, which must be a TVI block up to voteEnd.
+	last := tip
+	if ts.minedHeight > 0 && ts.minedHeight <= last {
+		last = ts.minedHeight - 1
+	}
+	if last >= ts.voteEnd {
+		last = ts.voteEnd - 1
+	}
+
+	intervals := make([]*dbtypes.TreasurySpendInterval, len(ts.closed),
+		t.params.TreasuryVoteIntervalMultiplier)
+	copy(intervals, ts.closed)
+	var yes, no int64
+	for _, iv := range intervals {
+		yes += iv.YesVotes
+		no += iv.NoVotes
+	}
+
+	tvi := int64(t.params.TreasuryVoteInterval)
+	for start := ts.voteStart + int64(len(intervals))*tvi; start <= last; start += tvi {
+		end := start + tvi - 1
+		ivLast := end
+		if last < end {
+			ivLast = last
+		}
+
+		blockHash := tipHash
+		if ivLast != tip || tipHash == nil {
+			var err error
+			blockHash, err = t.node.GetBlockHash(t.ctx, ivLast)
+			if err != nil {
+				return fmt.Errorf("GetBlockHash(%d): %w", ivLast, err)
+			}
+		}
+
+		iv, err := t.intervalVotes(&ts.hash, blockHash, start, ivLast, yes, no)
+		if err != nil {
+			return err
+		}
+		iv.End = end
+		yes += iv.YesVotes
+		no += iv.NoVotes
+
+		intervals = append(intervals, iv)
+		if ivLast == end {
+			ts.closed = append(ts.closed, iv)
+		}
+	}
+
+	ts.tally = newTally(t.params, ts, tip, intervals)
+	return nil
+}
+
+// intervalVotes tallies the votes on the tspend in the blocks from start up to
+// the block with the given hash at height last. The yes and no votes before
+// start are subtracted from the votes counted by dcrd since the start of the
+// voting window.
+func (t *TSpendTracker) intervalVotes(tspendHash, blockHash *chainhash.Hash,
+	start, last, prevYes, prevNo int64) (*dbtypes.TreasurySpendInterval, error) {
+	res, err := t.node.GetTreasurySpendVotes(t.ctx, blockHash, []*chainhash.Hash{tspendHash})
+	if err != nil {
+		return nil, fmt.Errorf("GetTreasurySpendVotes: %w", err)
+	}
+	if len(res.Votes) != 1 {
+		return nil, fmt.Errorf("expected 1 tally, got %d", len(res.Votes))
+	}
+
+	blocks := last - start + 1
+	sv, err := t.node.GetStakeVersions(t.ctx, blockHash.String(), int32(blocks))
+	if err != nil {
+		return nil, fmt.Errorf("GetStakeVersions: %w", err)
+	}
+	var votes int64
+	for i := range sv.StakeVersions {
+		votes += int64(len(sv.StakeVersions[i].Votes))
+	}
+
+	iv := &dbtypes.TreasurySpendInterval{
+		Start:    start,
+		Blocks:   blocks,
+		Votes:    votes,
+		YesVotes: res.Votes[0].YesVotes - prevYes,
+		NoVotes:  res.Votes[0].NoVotes - prevNo,
+	}
+	if abstain := votes - iv.YesVotes - iv.NoVotes; abstain > 0 {
+		iv.AbstainVotes = abstain
+	}
+	return iv, nil
+}
+
+// signal sends a pstypes.SigTSpend signal with the tally to the signal
+// channels, without blocking the caller.
+func (t *TSpendTracker) signal(tally *dbtypes.TreasurySpendTally) {
+	for _, sigOut := range t.signalOuts {
+		go func(sigOut chan<- pstypes.HubMessage) {
+			select {
+			case sigOut <- pstypes.HubMessage{Signal: pstypes.SigTSpend, Msg: tally}:
+			case <-time.After(time.Second * 10):
+				log.Errorf("sigTSpend send failed: Timeout waiting for WebsocketHub.")
+			}
+		}(sigOut)
+	}
+}
+
+// requiredYesVotes is the number of yes votes required for the approval of a
+// tspend, given the votes cast and the maximum number of votes remaining in
+// its voting window.
+func requiredYesVotes(params *chaincfg.Params, votesCast, maxRemainingVotes int64) int64 {
+	return (votesCast + maxRemainingVotes) * int64(params.TreasuryVoteRequiredMultiplier) /
+		int64(params.TreasuryVoteRequiredDivisor)
+}
+
+// newTally computes the tally of the tspend at the tip height from the tallies
+// of its intervals, applying the quorum and approval rules of the chain
+// parameters.
+func newTally(params *chaincfg.Params, ts *tspend, tip int64,
+	intervals []*dbtypes.TreasurySpendInterval) *dbtypes.TreasurySpendTally {
+	tally := &dbtypes.TreasurySpendTally{
+		Hash:        ts.hash.String(),
+		Amount:      ts.amount,
+		Expiry:      ts.expiry,
+		VoteStart:   ts.voteStart,
+		VoteEnd:     ts.voteEnd,
+		Height:      tip,
+		MinedHeight: ts.minedHeight,
+		Intervals:   intervals,
+	}
+
+	last := ts.voteStart - 1 // the last block with counted votes
+	var votes int64
+	for _, iv := range intervals {
+		tally.YesVotes += iv.YesVotes
+		tally.NoVotes += iv.NoVotes
+		tally.AbstainVotes += iv.AbstainVotes
+		votes += iv.Votes
+		last = iv.Start + iv.Blocks - 1
+	}
+
+	// These are the rules that dcrd applies when the tspend is mined in the
+	// block after last.
+	ticketsPerBlock := int64(params.TicketsPerBlock)
+	tally.MaxVotes = ticketsPerBlock * (ts.voteEnd - ts.voteStart)
+	tally.QuorumVotes = tally.MaxVotes * int64(params.TreasuryVoteQuorumMultiplier) /
+		int64(params.TreasuryVoteQuorumDivisor)
+	votesCast := tally.YesVotes + tally.NoVotes
+	tally.QuorumAchieved = votesCast >= tally.QuorumVotes
+	if votesCast > 0 {
+		tally.Approval = float64(tally.YesVotes) / float64(votesCast)
+	}
+	remainingBlocks := ts.voteEnd - (last + 1)
+	tally.RequiredYesVotes = requiredYesVotes(params, votesCast, remainingBlocks*ticketsPerBlock)
+	approved := tally.QuorumAchieved && tally.YesVotes >= tally.RequiredYesVotes
+
+	switch {
+	case ts.minedHeight > 0:
+		tally.Status = dbtypes.TSpendMined
+	case tip < ts.voteStart:
+		tally.Status = dbtypes.TSpendPending
+	case approved && tip >= ts.expiry:
+		tally.Status = dbtypes.TSpendExpired
+	case approved:
+		tally.Status = dbtypes.TSpendApproved
+	case tip >= ts.voteEnd:
+		tally.Status = dbtypes.TSpendRejected
+	default:
+		tally.Status = dbtypes.TSpendVoting
+	}
+
+	// Project the votes in the rest of the voting window at the mean votes
+	// per block, turnout and approval so far.
+	proj := &tally.Projection
+	proj.YesVotes, proj.NoVotes = tally.YesVotes, tally.NoVotes
+	if !tally.Final() && remainingBlocks > 0 && votesCast > 0 {
+		blocks := last + 1 - ts.voteStart
+		remainingVotes := float64(remainingBlocks*votes) / float64(blocks)
+		remainingCast := remainingVotes * float64(votesCast) / float64(votes)
+		proj.YesVotes += int64(math.Round(remainingCast * tally.Approval))
+		proj.NoVotes += int64(math.Round(remainingCast * (1 - tally.Approval)))
+	}
+	projCast := proj.YesVotes + proj.NoVotes
+	proj.QuorumAchieved = projCast >= tally.QuorumVotes
+	proj.Approved = approved || tally.Status == dbtypes.TSpendMined ||
+		(proj.QuorumAchieved && proj.YesVotes >= requiredYesVotes(params, projCast, 0))
+
+	return tally
+}
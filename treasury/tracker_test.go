@@ -0,0 +1,354 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package treasury
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
+	"github.com/decred/dcrd/txscript/v4"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
+)
+
+// The voting window of the test tspend on mainnet, where the TVI is 288 blocks
+// and the window is 12 TVIs.
+const (
+	testVoteStart = 2880
+	testVoteEnd   = testVoteStart + 288*12
+	testExpiry    = testVoteEnd + 2
+)
+
+func testTSpendTx() *wire.MsgTx {
+	sigScript := make([]byte, 0, 100)
+	sigScript = append(sigScript, txscript.OP_DATA_64)
+	sigScript = append(sigScript, bytes.Repeat([]byte{1}, 64)...)
+	sigScript = append(sigScript, txscript.OP_DATA_33, 0x02)
+	sigScript = append(sigScript, bytes.Repeat([]byte{2}, 32)...)
+	sigScript = append(sigScript, txscript.OP_TSPEND)
+
+	nullData := append([]byte{txscript.OP_RETURN, txscript.OP_DATA_32}, bytes.Repeat([]byte{3}, 32)...)
+	p2pkh := []byte{txscript.OP_TGEN, txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20}
+	p2pkh = append(p2pkh, bytes.Repeat([]byte{4}, 20)...)
+	p2pkh = append(p2pkh, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+
+	tx := wire.NewMsgTx()
+	tx.Version = wire.TxVersionTreasury
+	tx.Expiry = testExpiry
+	tx.AddTxIn(&wire.TxIn{SignatureScript: sigScript})
+	tx.AddTxOut(wire.NewTxOut(0, nullData))
+	tx.AddTxOut(wire.NewTxOut(1e10, p2pkh))
+	return tx
+}
+
+func testBlockHash(height int64) *chainhash.Hash {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(height))
+	hash := chainhash.HashH(b[:])
+	return &hash
+}
+
+// testNode is a NodeClient with a tspend that gets 3 yes votes and 1 no vote
+// in each block of its voting window, with 5 votes per block. The tspend is
+// reported in mempool unless it is hidden.
+type testNode struct {
+	tip       int64
+	hidden    bool
+	tspend    *wire.MsgTx
+	regular   *wire.MsgTx
+	heights   map[chainhash.Hash]int64
+	tallyRPCs int
+	txRPCs    int
+}
+
+func newTestNode(tip int64) *testNode {
+	n := &testNode{
+		tip:     tip,
+		tspend:  testTSpendTx(),
+		regular: wire.NewMsgTx(),
+		heights: make(map[chainhash.Hash]int64),
+	}
+	n.regular.AddTxOut(wire.NewTxOut(1, []byte{txscript.OP_TRUE}))
+	return n
+}
+
+func (n *testNode) blockHash(height int64) *chainhash.Hash {
+	hash := testBlockHash(height)
+	n.heights[*hash] = height
+	return hash
+}
+
+func (n *testNode) GetBestBlock(context.Context) (*chainhash.Hash, int64, error) {
+	return n.blockHash(n.tip), n.tip, nil
+}
+
+func (n *testNode) GetBlockHash(_ context.Context, height int64) (*chainhash.Hash, error) {
+	return n.blockHash(height), nil
+}
+
+func (n *testNode) GetRawTransactionVerbose(_ context.Context, txHash *chainhash.Hash) (*chainjson.TxRawResult, error) {
+	n.txRPCs++
+	tx := n.regular
+	if *txHash == n.tspend.TxHash() {
+		tx = n.tspend
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return &chainjson.TxRawResult{Hex: hex.EncodeToString(buf.Bytes())}, nil
+}
+
+func (n *testNode) GetStakeVersions(_ context.Context, hash string, count int32) (*chainjson.GetStakeVersionsResult, error) {
+	svs := make([]chainjson.StakeVersions, count)
+	for i := range svs {
+		svs[i].Votes = make([]chainjson.VersionBits, 5)
+	}
+	return &chainjson.GetStakeVersionsResult{StakeVersions: svs}, nil
+}
+
+func (n *testNode) GetTreasurySpendVotes(_ context.Context, block *chainhash.Hash,
+	tspends []*chainhash.Hash) (*chainjson.GetTreasurySpendVotesResult, error) {
+	votes := chainjson.TreasurySpendVotes{
+		Hash:      n.tspend.TxHash().String(),
+		Expiry:    testExpiry,
+		VoteStart: testVoteStart,
+		VoteEnd:   testVoteEnd,
+	}
+	if tspends != nil {
+		if *tspends[0] != n.tspend.TxHash() {
+			return nil, errors.New("not a tspend")
+		}
+		height := n.tip
+		if block != nil {
+			height = n.heights[*block]
+		}
+		if blocks := height - testVoteStart + 1; blocks > 0 {
+			votes.YesVotes, votes.NoVotes = 3*blocks, blocks
+		}
+	} else {
+		n.tallyRPCs = 0
+		if n.hidden {
+			return &chainjson.GetTreasurySpendVotesResult{}, nil
+		}
+	}
+	n.tallyRPCs++
+	return &chainjson.GetTreasurySpendVotesResult{Votes: []chainjson.TreasurySpendVotes{votes}}, nil
+}
+
+func (n *testNode) block(height int64, mined bool) *wire.MsgBlock {
+	n.tip = height
+	n.blockHash(height)
+	msgBlock := &wire.MsgBlock{Header: wire.BlockHeader{Height: uint32(height)}}
+	if mined {
+		msgBlock.STransactions = []*wire.MsgTx{n.tspend}
+	}
+	// The test blocks are identified by height.
+	n.heights[msgBlock.BlockHash()] = height
+	return msgBlock
+}
+
+func TestTSpendTracker(t *testing.T) {
+	node := newTestNode(testVoteStart - 1)
+	sigs := make(chan pstypes.HubMessage, 1)
+	tracker := NewTSpendTracker(context.Background(), node, chaincfg.MainNetParams(),
+		[]chan<- pstypes.HubMessage{sigs})
+	hash := node.tspend.TxHash()
+
+	if err := tracker.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	tallies := tracker.TSpends()
+	if len(tallies) != 1 || tallies[0].Status != dbtypes.TSpendPending || len(tallies[0].Intervals) != 0 {
+		t.Fatalf("expected one pending tspend, got %+v", tallies)
+	}
+	if tallies[0].Amount != 1e10 || tallies[0].MaxVotes != 5*288*12 || tallies[0].QuorumVotes != 5*288*12/5 {
+		t.Errorf("unexpected tally: %+v", tallies[0])
+	}
+	<-sigs
+
+	// A complete interval and 13 blocks of the next.
+	if err := tracker.Store(nil, node.block(testVoteStart+300, false)); err != nil {
+		t.Fatal(err)
+	}
+	tally, err := tracker.Tally(&hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tally.Status != dbtypes.TSpendVoting || len(tally.Intervals) != 2 {
+		t.Fatalf("expected a voting tspend with 2 intervals, got %+v", tally)
+	}
+	want := dbtypes.TreasurySpendInterval{Start: testVoteStart, End: testVoteStart + 287,
+		Blocks: 288, Votes: 1440, YesVotes: 864, NoVotes: 288, AbstainVotes: 288}
+	if *tally.Intervals[0] != want {
+		t.Errorf("unexpected first interval: %+v", tally.Intervals[0])
+	}
+	want = dbtypes.TreasurySpendInterval{Start: testVoteStart + 288, End: testVoteStart + 575,
+		Blocks: 13, Votes: 65, YesVotes: 39, NoVotes: 13, AbstainVotes: 13}
+	if *tally.Intervals[1] != want {
+		t.Errorf("unexpected second interval: %+v", tally.Intervals[1])
+	}
+	if tally.YesVotes != 903 || tally.NoVotes != 301 || tally.AbstainVotes != 301 {
+		t.Errorf("unexpected votes: %+v", tally)
+	}
+	// 3155 blocks remain, with up to 15775 votes.
+	if tally.QuorumAchieved || tally.RequiredYesVotes != (1204+15775)*3/5 {
+		t.Errorf("unexpected requirements: %+v", tally)
+	}
+	// At 5 votes per block, 80% turnout and 75% approval.
+	wantProj := dbtypes.TreasurySpendProjection{YesVotes: 903 + 9465, NoVotes: 301 + 3155,
+		QuorumAchieved: true, Approved: true}
+	if tally.Projection != wantProj {
+		t.Errorf("unexpected projection: %+v", tally.Projection)
+	}
+	if sig := <-sigs; sig.Signal != pstypes.SigTSpend || sig.Msg != tally {
+		t.Errorf("unexpected signal: %v", sig)
+	}
+
+	// Only the current interval is tallied again.
+	if err = tracker.Store(nil, node.block(testVoteStart+301, false)); err != nil {
+		t.Fatal(err)
+	}
+	if node.tallyRPCs != 2 {
+		t.Errorf("expected 2 gettreasuryspendvotes RPCs, got %d", node.tallyRPCs)
+	}
+	<-sigs
+
+	// Mined in the TVI block after the second interval.
+	if err = tracker.Store(nil, node.block(testVoteStart+576, true)); err != nil {
+		t.Fatal(err)
+	}
+	tally, _ = tracker.Tally(&hash)
+	if tally.Status != dbtypes.TSpendMined || tally.MinedHeight != testVoteStart+576 ||
+		tally.YesVotes != 3*576 || !tally.Projection.Approved {
+		t.Errorf("unexpected tally of the mined tspend: %+v", tally)
+	}
+	<-sigs
+
+	// The mined tspend is not updated, until it is reorganized out.
+	if err = tracker.Store(nil, node.block(testVoteStart+577, false)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sig := <-sigs:
+		t.Errorf("unexpected signal for the mined tspend: %v", sig)
+	default:
+	}
+	if err = tracker.Store(nil, node.block(testVoteStart+576, false)); err != nil {
+		t.Fatal(err)
+	}
+	tally, _ = tracker.Tally(&hash)
+	if tally.Status != dbtypes.TSpendVoting || tally.MinedHeight != 0 || len(tally.Intervals) != 3 {
+		t.Errorf("unexpected tally after the reorg: %+v", tally)
+	}
+	<-sigs
+
+	regular := node.regular.TxHash()
+	if _, err = tracker.Tally(&regular); !errors.Is(err, ErrNotTSpend) {
+		t.Errorf("expected ErrNotTSpend, got %v", err)
+	}
+}
+
+// TestTallyLookups checks that the results of tallying the transactions that
+// are not tracked are kept until the next block.
+func TestTallyLookups(t *testing.T) {
+	node := newTestNode(testVoteStart + 10)
+	node.hidden = true
+	tracker := NewTSpendTracker(context.Background(), node, chaincfg.MainNetParams(), nil)
+	if err := tracker.Store(nil, node.block(testVoteStart+10, false)); err != nil {
+		t.Fatal(err)
+	}
+	hash, regular := node.tspend.TxHash(), node.regular.TxHash()
+
+	node.txRPCs = 0
+	for i := 0; i < 2; i++ {
+		if _, err := tracker.Tally(&regular); !errors.Is(err, ErrNotTSpend) {
+			t.Fatalf("expected ErrNotTSpend, got %v", err)
+		}
+		tally, err := tracker.Tally(&hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tally.Status != dbtypes.TSpendVoting || tally.YesVotes != 33 {
+			t.Fatalf("unexpected tally: %+v", tally)
+		}
+	}
+	if node.txRPCs != 2 {
+		t.Errorf("expected 2 getrawtransaction RPCs, got %d", node.txRPCs)
+	}
+	if tallies := tracker.TSpends(); len(tallies) != 0 {
+		t.Errorf("expected no tracked tspends, got %d", len(tallies))
+	}
+
+	// The results are forgotten at the next block.
+	if err := tracker.Store(nil, node.block(testVoteStart+11, false)); err != nil {
+		t.Fatal(err)
+	}
+	node.txRPCs = 0
+	if _, err := tracker.Tally(&regular); !errors.Is(err, ErrNotTSpend) {
+		t.Fatalf("expected ErrNotTSpend, got %v", err)
+	}
+	if node.txRPCs != 1 {
+		t.Errorf("expected 1 getrawtransaction RPC, got %d", node.txRPCs)
+	}
+}
+
+func TestNewTallyStatus(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	ts := &tspend{
+		expiry:    testExpiry,
+		voteStart: testVoteStart,
+		voteEnd:   testVoteEnd,
+	}
+	// Votes in all intervals of the window.
+	intervals := func(yes, no int64) []*dbtypes.TreasurySpendInterval {
+		ivs := make([]*dbtypes.TreasurySpendInterval, 12)
+		for i := range ivs {
+			start := testVoteStart + int64(i)*288
+			ivs[i] = &dbtypes.TreasurySpendInterval{Start: start, End: start + 287,
+				Blocks: 288, Votes: 1440, YesVotes: yes, NoVotes: no}
+		}
+		return ivs
+	}
+
+	tests := []struct {
+		name      string
+		tip       int64
+		yes, no   int64
+		status    string
+		approved  bool
+		projected bool
+	}{
+		{"rejected without quorum", testVoteEnd, 100, 100, dbtypes.TSpendRejected, false, false},
+		{"rejected", testVoteEnd, 300, 300, dbtypes.TSpendRejected, false, false},
+		{"approved", testVoteEnd, 1000, 100, dbtypes.TSpendApproved, true, true},
+		{"expired", testExpiry, 1000, 100, dbtypes.TSpendExpired, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := newTally(params, ts, tt.tip, intervals(tt.yes, tt.no))
+			if tally.Status != tt.status {
+				t.Errorf("expected status %s, got %s", tt.status, tally.Status)
+			}
+			if tally.Final() != (tt.status != dbtypes.TSpendApproved) {
+				t.Errorf("unexpected Final() for status %s", tally.Status)
+			}
+			if tally.RequiredYesVotes != (tt.yes+tt.no)*12*3/5 {
+				t.Errorf("unexpected required yes votes %d", tally.RequiredYesVotes)
+			}
+			if tally.Projection.YesVotes != tally.YesVotes || tally.Projection.Approved != tt.projected {
+				t.Errorf("unexpected projection %+v", tally.Projection)
+			}
+		})
+	}
+}
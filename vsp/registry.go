@@ -0,0 +1,100 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package vsp attributes tickets to voting service providers (VSPs) by the fee
+// addresses that their purchases commit to, and summarizes the tickets of each
+// VSP.
+package vsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+)
+
+// VSP is a voting service provider and the fee addresses that its tickets
+// commit to.
+type VSP struct {
+	Name         string   `json:"name"`
+	URL          string   `json:"url,omitempty"`
+	FeeAddresses []string `json:"fee_addresses"`
+}
+
+// Registry is a set of VSPs, indexed by name and by fee address.
+type Registry struct {
+	vsps   []*VSP
+	byName map[string]*VSP
+	byAddr map[string]*VSP
+}
+
+// LoadRegistry loads a Registry from a JSON file holding an array of VSPs.
+func LoadRegistry(path string, params *chaincfg.Params) (*Registry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var vsps []*VSP
+	if err = json.Unmarshal(b, &vsps); err != nil {
+		return nil, fmt.Errorf("failed to decode VSP registry %s: %w", path, err)
+	}
+	return NewRegistry(vsps, params)
+}
+
+// NewRegistry constructs a Registry. The VSP names must be unique, and the fee
+// addresses must be valid for the network and not be shared by VSPs.
+func NewRegistry(vsps []*VSP, params *chaincfg.Params) (*Registry, error) {
+	r := &Registry{
+		vsps:   vsps,
+		byName: make(map[string]*VSP, len(vsps)),
+		byAddr: make(map[string]*VSP),
+	}
+	for _, v := range vsps {
+		if v.Name == "" {
+			return nil, fmt.Errorf("VSP with no name")
+		}
+		if _, found := r.byName[v.Name]; found {
+			return nil, fmt.Errorf("duplicate VSP %q", v.Name)
+		}
+		r.byName[v.Name] = v
+		for _, addr := range v.FeeAddresses {
+			if _, err := stdaddr.DecodeAddress(addr, params); err != nil {
+				return nil, fmt.Errorf("invalid fee address %s of VSP %q: %w", addr, v.Name, err)
+			}
+			if other, found := r.byAddr[addr]; found {
+				return nil, fmt.Errorf("fee address %s of VSP %q is also a fee address of %q",
+					addr, v.Name, other.Name)
+			}
+			r.byAddr[addr] = v
+		}
+	}
+	return r, nil
+}
+
+// VSPs returns the VSPs in the order they were registered.
+func (r *Registry) VSPs() []*VSP {
+	return r.vsps
+}
+
+// ByName gets a VSP by name.
+func (r *Registry) ByName(name string) (*VSP, bool) {
+	v, found := r.byName[name]
+	return v, found
+}
+
+// ByAddress gets the VSP with the fee address.
+func (r *Registry) ByAddress(addr string) (*VSP, bool) {
+	v, found := r.byAddr[addr]
+	return v, found
+}
+
+// FeeAddresses returns the fee addresses of all of the VSPs.
+func (r *Registry) FeeAddresses() []string {
+	addrs := make([]string, 0, len(r.byAddr))
+	for _, v := range r.vsps {
+		addrs = append(addrs, v.FeeAddresses...)
+	}
+	return addrs
+}
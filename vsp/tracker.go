@@ -0,0 +1,278 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package vsp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/decred/dcrd/blockchain/stake/v5"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/wire"
+
+	apitypes "github.com/decred/dcrdata/v8/api/types"
+	"github.com/decred/dcrdata/v8/blockdata"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// Attribution describes how the tickets are attributed to the VSPs in the
+// VSPStats. A VSP that is paid by a transaction separate from the ticket
+// purchase, as with vspd, leaves no link to the ticket on chain.
+const Attribution = "Tickets are attributed by the fee addresses that their purchases commit to. " +
+	"The tickets of VSPs that are paid by a separate fee transaction, such as vspd, are not attributed."
+
+// NodeClient is the interface of the dcrd RPC client used by the Tracker. It
+// is satisfied by *rpcclient.Client.
+type NodeClient interface {
+	GetRawTransaction(ctx context.Context, txHash *chainhash.Hash) (*dcrutil.Tx, error)
+}
+
+// Tracker attributes the unspent tickets to the VSPs of a Registry. The
+// ticket commitments are not stored in the DB, so the tickets are attributed
+// as they are mined, and the live tickets that were mined before the Tracker
+// was started are fetched from the node as needed. Spent tickets are
+// forgotten, since they can be attributed by the outputs of their votes and
+// revocations.
+type Tracker struct {
+	ctx      context.Context
+	node     NodeClient
+	params   *chaincfg.Params
+	registry *Registry
+
+	// updateMtx serializes the RPCs for unattributed tickets.
+	updateMtx sync.Mutex
+
+	mtx sync.RWMutex
+	// tickets maps the unspent tickets to their VSP, which is nil for the
+	// tickets of no registered VSP.
+	tickets map[chainhash.Hash]*VSP
+	// blocks counts the blocks seen by Store.
+	blocks uint64
+
+	// statsMtx serializes CachedStats, which keeps the stats computed since
+	// the last block.
+	statsMtx    sync.Mutex
+	stats       []*apitypes.VSPStats
+	statsBlocks uint64
+}
+
+// NewTracker constructs a Tracker. Use the Tracker as a
+// blockdata.BlockDataSaver to attribute the tickets in new blocks.
+func NewTracker(ctx context.Context, node NodeClient, params *chaincfg.Params, registry *Registry) *Tracker {
+	return &Tracker{
+		ctx:      ctx,
+		node:     node,
+		params:   params,
+		registry: registry,
+		tickets:  make(map[chainhash.Hash]*VSP),
+	}
+}
+
+// Registry returns the Tracker's Registry.
+func (t *Tracker) Registry() *Registry {
+	return t.registry
+}
+
+// TicketVSP gets the VSP of a ticket purchase by its commitment outputs, or
+// nil if it commits to no fee address.
+func (t *Tracker) TicketVSP(msgTx *wire.MsgTx) *VSP {
+	// The commitments are the odd outputs.
+	for i := 1; i < len(msgTx.TxOut); i += 2 {
+		addr, err := stake.AddrFromSStxPkScrCommitment(msgTx.TxOut[i].PkScript, t.params)
+		if err != nil {
+			continue
+		}
+		if v, found := t.registry.ByAddress(addr.String()); found {
+			return v
+		}
+	}
+	return nil
+}
+
+// Store attributes the tickets purchased in a block, and forgets the tickets
+// spent in it, satisfying blockdata.BlockDataSaver.
+func (t *Tracker) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.blocks++
+	for _, stx := range msgBlock.STransactions {
+		switch {
+		case stake.IsSStx(stx):
+			t.tickets[stx.TxHash()] = t.TicketVSP(stx)
+		case stake.IsSSGen(stx):
+			// The ticket is spent by the second input of a vote.
+			delete(t.tickets, stx.TxIn[1].PreviousOutPoint.Hash)
+		case stake.IsSSRtx(stx):
+			delete(t.tickets, stx.TxIn[0].PreviousOutPoint.Hash)
+		}
+	}
+	return nil
+}
+
+// TicketVSPs attributes the unspent tickets to the VSPs, and gets the VSP of
+// each ticket that is attributed to one. The tickets that were not seen by
+// Store are fetched from the node, which may take some time the first time
+// the live pool is attributed. Spent tickets should not be attributed, as they
+// are only forgotten when Store sees them spent.
+func (t *Tracker) TicketVSPs(tickets []string) (map[string]*VSP, error) {
+	t.updateMtx.Lock()
+	defer t.updateMtx.Unlock()
+
+	hashes := make([]chainhash.Hash, 0, len(tickets))
+	var missing []chainhash.Hash
+	t.mtx.RLock()
+	for _, ticket := range tickets {
+		hash, err := chainhash.NewHashFromStr(ticket)
+		if err != nil {
+			t.mtx.RUnlock()
+			return nil, err
+		}
+		hashes = append(hashes, *hash)
+		if _, found := t.tickets[*hash]; !found {
+			missing = append(missing, *hash)
+		}
+	}
+	t.mtx.RUnlock()
+
+	if len(missing) > 0 {
+		log.Debugf("Attributing %d live tickets to VSPs.", len(missing))
+	}
+	for i := range missing {
+		tx, err := t.node.GetRawTransaction(t.ctx, &missing[i])
+		if err != nil {
+			return nil, fmt.Errorf("GetRawTransaction(%v): %w", missing[i], err)
+		}
+		v := t.TicketVSP(tx.MsgTx())
+		t.mtx.Lock()
+		t.tickets[missing[i]] = v
+		t.mtx.Unlock()
+	}
+
+	vsps := make(map[string]*VSP)
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	for i := range hashes {
+		if v := t.tickets[hashes[i]]; v != nil {
+			vsps[tickets[i]] = v
+		}
+	}
+	return vsps, nil
+}
+
+// LiveTickets counts the tickets of the pool attributed to each VSP, by name.
+// See TicketVSPs.
+func (t *Tracker) LiveTickets(pool []string) (map[string]int64, error) {
+	vsps, err := t.TicketVSPs(pool)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64)
+	for _, v := range vsps {
+		counts[v.Name]++
+	}
+	return counts, nil
+}
+
+// FeeAddresses are the fee addresses of the VSPs of the Registry.
+func (t *Tracker) FeeAddresses() []string {
+	return t.registry.FeeAddresses()
+}
+
+// VSPName gets the name of the VSP of the Registry with the fee address.
+func (t *Tracker) VSPName(feeAddr string) (string, bool) {
+	v, found := t.registry.ByAddress(feeAddr)
+	if !found {
+		return "", false
+	}
+	return v.Name, true
+}
+
+// TicketVSPNames is like TicketVSPs, but gets the VSP names.
+func (t *Tracker) TicketVSPNames(tickets []string) (map[string]string, error) {
+	vsps, err := t.TicketVSPs(tickets)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(vsps))
+	for ticket, v := range vsps {
+		names[ticket] = v.Name
+	}
+	return names, nil
+}
+
+// Stats summarizes the tickets of each VSP, from the live pool and the counts
+// of the spent tickets by fee address.
+func (t *Tracker) Stats(pool []string, spent []*dbtypes.VSPAddressTickets) ([]*apitypes.VSPStats, error) {
+	live, err := t.LiveTickets(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	vsps := t.registry.VSPs()
+	stats := make([]*apitypes.VSPStats, 0, len(vsps))
+	byName := make(map[string]*apitypes.VSPStats, len(vsps))
+	voteAges := make(map[string]int64, len(vsps))
+	for _, v := range vsps {
+		s := &apitypes.VSPStats{
+			Name:         v.Name,
+			URL:          v.URL,
+			FeeAddresses: v.FeeAddresses,
+			LiveTickets:  live[v.Name],
+			Attribution:  Attribution,
+		}
+		stats = append(stats, s)
+		byName[v.Name] = s
+	}
+	for _, a := range spent {
+		v, found := t.registry.ByAddress(a.Address)
+		if !found {
+			continue
+		}
+		s := byName[v.Name]
+		s.Votes += a.Voted
+		s.Misses += a.Missed
+		s.Expirations += a.Expired
+		s.Revocations += a.Revoked
+		voteAges[v.Name] += a.VoteAgeSum
+	}
+	for _, s := range stats {
+		if s.Votes+s.Misses > 0 {
+			s.MissRate = float64(s.Misses) / float64(s.Votes+s.Misses)
+		}
+		if s.Votes > 0 {
+			// Tickets are live once they mature.
+			s.AvgVoteLatency = float64(voteAges[s.Name])/float64(s.Votes) -
+				float64(t.params.TicketMaturity)
+		}
+	}
+	return stats, nil
+}
+
+// CachedStats returns the Stats of the ticket pool and the spent tickets from
+// source, which are only computed once per block seen by Store. The returned
+// stats must not be modified.
+func (t *Tracker) CachedStats(source func() (pool []string, spent []*dbtypes.VSPAddressTickets, err error)) ([]*apitypes.VSPStats, error) {
+	t.statsMtx.Lock()
+	defer t.statsMtx.Unlock()
+	t.mtx.RLock()
+	blocks := t.blocks
+	t.mtx.RUnlock()
+	if t.stats != nil && t.statsBlocks == blocks {
+		return t.stats, nil
+	}
+
+	pool, spent, err := source()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := t.Stats(pool, spent)
+	if err != nil {
+		return nil, err
+	}
+	t.stats, t.statsBlocks = stats, blocks
+	return stats, nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package vsp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+var testParams = chaincfg.MainNetParams()
+
+func testAddress(t *testing.T, i byte) stdaddr.StakeAddress {
+	t.Helper()
+	var pkHash [20]byte
+	pkHash[0] = i
+	addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pkHash[:], testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// testTicket is a ticket purchase with one input, committing to the address.
+func testTicket(t *testing.T, voter, commit stdaddr.StakeAddress, i uint32) *wire.MsgTx {
+	t.Helper()
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, i, wire.TxTreeRegular), 1e8, nil))
+	ver, script := voter.VotingRightsScript()
+	tx.AddTxOut(&wire.TxOut{Value: 1e8, Version: ver, PkScript: script})
+	_, script = commit.RewardCommitmentScript(1e8, 0, 1e6)
+	tx.AddTxOut(&wire.TxOut{PkScript: script})
+	ver, script = commit.StakeChangeScript()
+	tx.AddTxOut(&wire.TxOut{Version: ver, PkScript: script})
+	return tx
+}
+
+type testNode map[chainhash.Hash]*wire.MsgTx
+
+func (n testNode) GetRawTransaction(_ context.Context, txHash *chainhash.Hash) (*dcrutil.Tx, error) {
+	tx, found := n[*txHash]
+	if !found {
+		return nil, errors.New("no such transaction")
+	}
+	return dcrutil.NewTx(tx), nil
+}
+
+func TestLoadRegistry(t *testing.T) {
+	fee1, fee2 := testAddress(t, 1).String(), testAddress(t, 2).String()
+	path := filepath.Join(t.TempDir(), "vsps.json")
+	registry := `[{"name": "one", "url": "https://one.example", "fee_addresses": ["` + fee1 + `"]},
+		{"name": "two", "fee_addresses": ["` + fee2 + `"]}]`
+	if err := os.WriteFile(path, []byte(registry), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadRegistry(path, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.VSPs()) != 2 || len(r.FeeAddresses()) != 2 {
+		t.Fatalf("unexpected registry %v", r.VSPs())
+	}
+	if v, found := r.ByAddress(fee2); !found || v.Name != "two" {
+		t.Errorf("fee address %s not attributed to two", fee2)
+	}
+	if v, found := r.ByName("one"); !found || v.URL != "https://one.example" {
+		t.Errorf("VSP one not found")
+	}
+
+	for _, vsps := range [][]*VSP{
+		{{Name: "one"}, {Name: "one"}},
+		{{Name: "one", FeeAddresses: []string{fee1}}, {Name: "two", FeeAddresses: []string{fee1}}},
+		{{Name: "one", FeeAddresses: []string{"notanaddress"}}},
+		{{FeeAddresses: []string{fee1}}},
+	} {
+		if _, err = NewRegistry(vsps, testParams); err == nil {
+			t.Errorf("expected an error for registry %v", vsps)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	fee := testAddress(t, 1)
+	r, err := NewRegistry([]*VSP{{Name: "one", FeeAddresses: []string{fee.String()}}}, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := testAddress(t, 9)
+
+	// One VSP ticket is mined while the Tracker is running, and the other is
+	// fetched from the node.
+	mined, old, solo := testTicket(t, user, fee, 0), testTicket(t, user, fee, 1), testTicket(t, user, user, 2)
+	node := testNode{old.TxHash(): old, solo.TxHash(): solo}
+	tracker := NewTracker(context.Background(), node, testParams, r)
+	if v := tracker.TicketVSP(mined); v == nil || v.Name != "one" {
+		t.Fatalf("ticket not attributed to VSP one")
+	}
+	if v := tracker.TicketVSP(solo); v != nil {
+		t.Fatalf("solo ticket attributed to VSP %s", v.Name)
+	}
+
+	block := &wire.MsgBlock{STransactions: []*wire.MsgTx{mined}}
+	if err = tracker.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	pool := []string{mined.TxHash().String(), old.TxHash().String(), solo.TxHash().String()}
+	spent := []*dbtypes.VSPAddressTickets{{
+		Address:    fee.String(),
+		Voted:      4,
+		Missed:     1,
+		Revoked:    1,
+		VoteAgeSum: 4 * (int64(testParams.TicketMaturity) + 100),
+	}, {
+		Address: user.String(),
+		Voted:   10,
+	}}
+	stats, err := tracker.Stats(pool, spent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected stats of 1 VSP, got %d", len(stats))
+	}
+	s := stats[0]
+	if s.LiveTickets != 2 || s.Votes != 4 || s.Misses != 1 || s.Revocations != 1 ||
+		s.MissRate != 0.2 || s.AvgVoteLatency != 100 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// Only the VSP tickets are attributed.
+	vsps, err := tracker.TicketVSPs(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vsps) != 2 || vsps[old.TxHash().String()] == nil || vsps[solo.TxHash().String()] != nil {
+		t.Errorf("unexpected ticket VSPs %v", vsps)
+	}
+
+	// Tickets the node does not know are an error.
+	if _, err = tracker.LiveTickets([]string{chainhash.Hash{1}.String()}); err == nil {
+		t.Error("expected an error for an unknown ticket")
+	}
+}
+
+func TestCachedStats(t *testing.T) {
+	fee := testAddress(t, 1)
+	r, err := NewRegistry([]*VSP{{Name: "one", FeeAddresses: []string{fee.String()}}}, testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket := testTicket(t, testAddress(t, 9), fee, 0)
+	tracker := NewTracker(context.Background(), testNode{}, testParams, r)
+	if err = tracker.Store(nil, &wire.MsgBlock{STransactions: []*wire.MsgTx{ticket}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	source := func() ([]string, []*dbtypes.VSPAddressTickets, error) {
+		calls++
+		return []string{ticket.TxHash().String()}, nil, nil
+	}
+	for i := 0; i < 2; i++ {
+		stats, err := tracker.CachedStats(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 1 || stats[0].LiveTickets != 1 || stats[0].Attribution != Attribution {
+			t.Fatalf("unexpected stats %+v", stats[0])
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the stats to be computed once, got %d", calls)
+	}
+
+	// The stats are computed again after a block, and errors are not kept.
+	if err = tracker.Store(nil, &wire.MsgBlock{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tracker.CachedStats(func() ([]string, []*dbtypes.VSPAddressTickets, error) {
+		return nil, nil, errors.New("no pool")
+	}); err == nil {
+		t.Fatal("expected an error from the source")
+	}
+	if _, err = tracker.CachedStats(source); err != nil || calls != 2 {
+		t.Errorf("expected the stats to be computed again, got %d calls (%v)", calls, err)
+	}
+}